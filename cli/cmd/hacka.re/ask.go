@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/dryrun"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// AskCommand sends one prompt and prints the reply, optionally saving it to a
+// file as it streams in
+func AskCommand(args []string) {
+	askFlags := flag.NewFlagSet("ask", flag.ExitOnError)
+	outFile := askFlags.String("out", "", "Also write the reply to this file")
+	appendOut := askFlags.Bool("append", false, "Append to --out instead of replacing it")
+	stream := askFlags.Bool("stream", false, "Stream the reply to the terminal and --out as it is generated")
+	model := askFlags.String("model", "", "Model to use instead of the configured one")
+	system := askFlags.String("system", "", "System prompt to use instead of the configured one")
+	session := askFlags.String("session", "", "Share link to use instead of the saved configuration")
+	dryRun := askFlags.Bool("dry-run", false, "Print the request payload with token estimates instead of sending it")
+	sampling := registerSamplingFlags(askFlags)
+	out := output.RegisterFlags(askFlags)
+	askFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s ask [options] PROMPT\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Send one prompt and print the reply. PROMPT - reads it from stdin.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		askFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  %s ask \"Explain CVE-2024-3094\"\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s ask --out answer.md --stream \"Write a threat model for our VPN\"\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  git diff | %s ask --model gpt-4o -\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s ask --deterministic --json \"Classify this log line: ...\"\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s ask --dry-run --system \"$(cat prompt.md)\" \"Summarise the findings\"\n\n", os.Args[0])
+	}
+	if err := askFlags.Parse(args); err != nil || askFlags.NArg() == 0 {
+		askFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	prompt := strings.Join(askFlags.Args(), " ")
+	if prompt == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			os.Exit(out.Fail(fmt.Errorf("failed to read prompt: %w", err)))
+		}
+		prompt = string(data)
+	}
+	if strings.TrimSpace(prompt) == "" {
+		os.Exit(out.Fail(failure.Usage(errors.New("the prompt is empty"))))
+	}
+
+	cfg, err := loadBridgeConfig(*session)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	if *model != "" {
+		cfg.Model = *model
+	}
+	if *system != "" {
+		cfg.SystemPrompt = *system
+	}
+	cfg.StreamResponse = *stream
+	sampling.apply(cfg, out)
+
+	var messages []api.Message
+	if cfg.SystemPrompt != "" {
+		messages = append(messages, api.Message{Role: "system", Content: cfg.SystemPrompt})
+	}
+	messages = append(messages, api.Message{Role: "user", Content: prompt})
+
+	if *dryRun {
+		report, err := dryrun.Build(api.NewClient(cfg), messages, *stream)
+		if err != nil {
+			os.Exit(out.Fail(err))
+		}
+		out.Write(os.Stdout, "dry-run", report, report.Write)
+		return
+	}
+
+	var file *output.SyncFile
+	if *outFile != "" {
+		if file, err = output.CreateSyncFile(*outFile, *appendOut, output.DefaultSyncInterval); err != nil {
+			os.Exit(out.Fail(failure.Config(fmt.Errorf("failed to open output file: %w", err))))
+		}
+	}
+
+	// When the reply goes to a file, a closed terminal (SIGHUP) doesn't stop
+	// it; otherwise it ends the request like Ctrl+C
+	signals := []os.Signal{os.Interrupt, syscall.SIGTERM}
+	if file != nil {
+		signal.Ignore(syscall.SIGHUP)
+	} else {
+		signals = append(signals, syscall.SIGHUP)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), signals...)
+	defer stop()
+
+	showReply := !out.JSON && !out.Quiet
+	var callback api.StreamCallback
+	if *stream {
+		callback = func(chunk string) error {
+			if file != nil {
+				if _, err := io.WriteString(file, chunk); err != nil {
+					return fmt.Errorf("failed to write %s: %w", file.Name(), err)
+				}
+			}
+			if showReply {
+				// The terminal may be gone; keep streaming to the file
+				io.WriteString(os.Stdout, chunk)
+			}
+			return nil
+		}
+	}
+
+	response, err := api.NewClient(cfg).SendChatCompletionContext(ctx, messages, callback)
+	content := ""
+	if response != nil && len(response.Choices) > 0 {
+		content = response.Choices[0].Message.Content
+	}
+	if err == nil && !*stream && file != nil {
+		_, err = io.WriteString(file, content)
+	}
+	if file != nil {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write %s: %w", file.Name(), closeErr)
+		}
+	}
+	if showReply && *stream {
+		fmt.Println()
+	}
+	if err != nil {
+		if file != nil && *stream {
+			out.Infof("The reply received so far is in %s", file.Name())
+		}
+		os.Exit(out.Fail(err))
+	}
+
+	answer := output.Answer{
+		Model:            cfg.Model,
+		Content:          content,
+		Seed:             cfg.Seed,
+		PromptTokens:     usage.EstimateTokens(cfg.SystemPrompt + prompt),
+		CompletionTokens: usage.EstimateTokens(content),
+	}
+	cachedTokens := 0
+	if response != nil {
+		if response.Usage.TotalTokens > 0 {
+			answer.PromptTokens = response.Usage.PromptTokens
+			answer.CompletionTokens = response.Usage.CompletionTokens
+		}
+		if len(response.Choices) > 0 {
+			answer.FinishReason = response.Choices[0].FinishReason
+		}
+		answer.SystemFingerprint = response.SystemFingerprint
+		cachedTokens = response.CachedTokens()
+	}
+	usage.NewTracker(usage.DefaultPath()).RecordCached(cfg.Model, answer.PromptTokens, cachedTokens, answer.CompletionTokens)
+	if file != nil {
+		answer.File = file.Name()
+	}
+
+	out.Write(os.Stdout, "answer", answer, func(w io.Writer) {
+		if !*stream {
+			fmt.Fprintln(w, content)
+		}
+	})
+	if file != nil {
+		out.Infof("Saved the reply to %s", file.Name())
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/bridge"
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/funcdir"
+	"github.com/hacka-re/cli/internal/jsruntime"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/toolpolicy"
+	"github.com/hacka-re/cli/internal/utils"
+)
+
+// BridgeCommand answers messages in a Slack or Discord channel with the configured model
+func BridgeCommand(args []string) {
+	if len(args) == 0 || (args[0] != "slack" && args[0] != "discord") {
+		showBridgeHelp()
+		os.Exit(failure.ExitConfig)
+	}
+	platformName := args[0]
+
+	bridgeFlags := flag.NewFlagSet("bridge "+platformName, flag.ExitOnError)
+	channel := bridgeFlags.String("channel", "", "Channel ID to bridge (required)")
+	token := bridgeFlags.String("token", "", "Bot token (default: $SLACK_BOT_TOKEN or $DISCORD_BOT_TOKEN)")
+	session := bridgeFlags.String("session", "", "Share link to use instead of the saved configuration")
+	approvers := bridgeFlags.String("approvers", "", "Comma-separated user IDs allowed to approve tool calls (required with functions unless --yolo)")
+	rate := bridgeFlags.Int("rate", bridge.DefaultUserRate, "Messages per user per minute (-1 for no limit)")
+	yolo := bridgeFlags.Bool("yolo", false, "Run tool calls without asking for approval")
+	bridgeFlags.Usage = func() { showBridgeHelp(); bridgeFlags.PrintDefaults() }
+	if err := bridgeFlags.Parse(args[1:]); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	if *token == "" {
+		*token = os.Getenv(strings.ToUpper(platformName) + "_BOT_TOKEN")
+	}
+	if *channel == "" || *token == "" {
+		fmt.Fprintf(os.Stderr, "Error: --channel and a bot token are required\n\n")
+		bridgeFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	cfg, err := loadBridgeConfig(*session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", failure.Message(err))
+		os.Exit(failure.ExitCode(err))
+	}
+
+	client := api.NewClient(cfg)
+	var tools bridge.ToolExecutor
+	registry := jsruntime.NewRegistry()
+	if err := jsruntime.LoadSharedFunctions(registry, cfg.Functions); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := jsruntime.LoadEnabledDefaults(registry, cfg.DefaultFunctions); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	warnf := func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+	watcher, watching := loadFunctionsDir(registry, warnf)
+	if registry.Size() > 0 || watching {
+		client.SetTools(registry.APITools())
+		tools = registry
+	}
+
+	var platform bridge.Platform
+	if platformName == "slack" {
+		platform = bridge.NewSlack(*token, *channel)
+	} else {
+		platform = bridge.NewDiscord(*token, *channel)
+	}
+
+	opts := bridge.Options{
+		SystemPrompt: cfg.SystemPrompt,
+		UserRate:     *rate,
+		AutoApprove:  *yolo || cfg.YoloMode,
+		ToolLimiter:  loadToolLimiter(warnf),
+	}
+	for _, id := range strings.Split(*approvers, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			opts.Approvers = append(opts.Approvers, id)
+		}
+	}
+	if tools != nil && !opts.AutoApprove && len(opts.Approvers) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: --approvers is required when functions are available, unless --yolo runs them without approval\n\n")
+		bridgeFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if watching {
+		// Edits to the functions directory change the tools of the next reply
+		go watcher.Run(ctx, funcdir.PollInterval, func(change funcdir.Change) {
+			client.SetTools(registry.APITools())
+			fmt.Fprintf(os.Stderr, "Functions reloaded: %s\n", change)
+		})
+	}
+
+	fmt.Printf("Bridging %s channel %s to %s (%d tools). Press Ctrl+C to stop.\n",
+		platform.Name(), *channel, cfg.Model, registry.Size())
+	if err := bridge.New(platform, client, tools, opts).Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitNetwork)
+	}
+}
+
+// loadFunctionsDir registers the functions of the functions directory,
+// warning about files that don't load; it reports whether the directory
+// exists and is worth watching
+func loadFunctionsDir(registry *jsruntime.Registry, warnf func(format string, args ...interface{})) (*funcdir.Watcher, bool) {
+	watcher := funcdir.NewWatcher(funcdir.Dir(), registry)
+	if _, err := os.Stat(watcher.Dir()); err != nil {
+		return watcher, false
+	}
+	change, err := watcher.Scan()
+	if err != nil {
+		warnf("Warning: %v", err)
+		return watcher, false
+	}
+	for _, e := range change.Errors {
+		warnf("Warning: %s: %s", filepath.Join(watcher.Dir(), e.File), e.Error)
+	}
+	return watcher, true
+}
+
+// loadToolLimiter applies the tool policy file to tool calls. A policy that
+// doesn't load is reported and nothing is limited.
+func loadToolLimiter(warnf func(format string, args ...interface{})) *toolpolicy.Limiter {
+	policy, err := toolpolicy.Load(toolpolicy.Path())
+	if err != nil {
+		warnf("Warning: %v", err)
+	}
+	return toolpolicy.NewLimiter(policy)
+}
+
+// loadBridgeConfig reads the session link (or the environment's) if given, else the saved config
+func loadBridgeConfig(sessionLink string) (*config.Config, error) {
+	if sessionLink == "" {
+		envSession, err := share.GetSessionFromEnvironment()
+		if err != nil {
+			return nil, failure.Config(err)
+		}
+		sessionLink = envSession
+	}
+
+	if sessionLink == "" {
+		cfg, err := config.LoadFromFile(config.GetConfigPath())
+		if err != nil {
+			return nil, failure.Config(fmt.Errorf("no saved configuration; run 'hacka.re' to set one up or pass --session: %w", err))
+		}
+		return cfg, nil
+	}
+
+	password, err := utils.GetPassword("Enter password for session: ")
+	if err != nil {
+		return nil, err
+	}
+	shared, err := share.ParseURL(sessionLink, password)
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.NewConfig()
+	cfg.LoadFromSharedConfig(shared)
+	return cfg, nil
+}
+
+func showBridgeHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s bridge slack|discord --channel ID [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Answer messages in a Slack or Discord channel with the configured model.\n")
+	fmt.Fprintf(os.Stderr, "Each new message starts a thread with its own conversation; tool calls\n")
+	fmt.Fprintf(os.Stderr, "wait for a ✅ (approve) or ❌ (decline) reaction from one of --approvers.\n\n")
+	fmt.Fprintf(os.Stderr, "Examples:\n")
+	fmt.Fprintf(os.Stderr, "  SLACK_BOT_TOKEN=xoxb-... %s bridge slack --channel C0123456789 --approvers U012AB3CD\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  DISCORD_BOT_TOKEN=... %s bridge discord --channel 123456789012345678 --approvers 42\n\n", os.Args[0])
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/app"
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/integration"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/templates"
+	"github.com/hacka-re/cli/internal/transcript"
+	"github.com/hacka-re/cli/internal/utils"
+)
+
+// ChatCommand handles the chat subcommand
+func ChatCommand(args []string) {
+	if len(args) > 0 && args[0] == "import" {
+		ChatImportCommand(args[1:])
+		return
+	}
+
+	// Create a new flagset for the chat command
+	chatFlags := flag.NewFlagSet("chat", flag.ExitOnError)
+	
+	// Define flags
+	chatFlags.Bool("debug", false, "Enable debug logging to /tmp/hacka_debug.log")  // Already handled in main
+	chatFlags.Bool("d", false, "Enable debug logging (short form)")  // Already handled in main
+	kiosk := chatFlags.Bool("kiosk", false, "Read-only demo mode: no settings changes, function editing or sharing")
+	templateName := chatFlags.String("template", "", "Start from a conversation template: "+strings.Join(templates.Names(), ", "))
+	help := chatFlags.Bool("help", false, "Show help message")
+	helpShort := chatFlags.Bool("h", false, "Show help message (short form)")
+	
+	// Custom usage
+	chatFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s chat [OPTIONS] [URL|FRAGMENT|DATA]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Start an interactive chat session with AI models\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -d, --debug           Enable debug logging to /tmp/hacka_debug.log\n")
+		fmt.Fprintf(os.Stderr, "      --kiosk           Read-only demo of the saved configuration or session\n")
+		fmt.Fprintf(os.Stderr, "      --template NAME   Start from a template (%s)\n", strings.Join(templates.Names(), ", "))
+		fmt.Fprintf(os.Stderr, "  -h, --help            Show this help message\n\n")
+		fmt.Fprintf(os.Stderr, "Arguments:\n")
+		fmt.Fprintf(os.Stderr, "  URL          Full hacka.re URL to load session from\n")
+		fmt.Fprintf(os.Stderr, "  FRAGMENT     Fragment with prefix (gpt=...)\n")
+		fmt.Fprintf(os.Stderr, "  DATA         Just the encrypted data (eyJlbmM...)\n\n")
+		fmt.Fprintf(os.Stderr, "Environment Variables:\n")
+		fmt.Fprintf(os.Stderr, "  HACKARE_LINK          Session link (synonymous with HACKARE_SESSION/CONFIG)\n")
+		fmt.Fprintf(os.Stderr, "  HACKARE_SESSION       Session link (synonymous with HACKARE_LINK/CONFIG)\n")
+		fmt.Fprintf(os.Stderr, "  HACKARE_CONFIG        Session link (synonymous with HACKARE_LINK/SESSION)\n\n")
+		fmt.Fprintf(os.Stderr, "Note: HACKARE_LINK, HACKARE_SESSION, and HACKARE_CONFIG are synonymous.\n")
+		fmt.Fprintf(os.Stderr, "      They all accept the same formats as command line arguments.\n")
+		fmt.Fprintf(os.Stderr, "      Only ONE should be set - setting multiple will cause an error.\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  %s chat                                # Start with saved config\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s chat \"gpt=eyJlbmM...\"              # Load session from fragment\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s chat --template threat-model        # Start a threat modelling session\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  HACKARE_SESSION=\"gpt=...\" %s chat     # Load session from env\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nNote: If no configuration exists, you'll be prompted to set it up first.\n")
+	}
+	
+	// Parse flags
+	if err := chatFlags.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	
+	// Show help if requested
+	if *help || *helpShort {
+		chatFlags.Usage()
+		os.Exit(0)
+	}
+	
+	kioskMode = *kiosk
+
+	if *templateName != "" {
+		tmpl, err := templates.Get(*templateName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(failure.ExitConfig)
+		}
+		chatTemplate = &tmpl
+	}
+
+	// Get non-flag arguments
+	remainingArgs := chatFlags.Args()
+	
+	// Start the chat session
+	startChatWithArgs(remainingArgs, nil)
+}
+
+// chatTemplate is the template chosen with chat --template, if any
+var chatTemplate *templates.Template
+
+// applyTemplate uses the system prompt and sampling of tmpl for this session,
+// enables its default functions and returns history opened with its first message
+func applyTemplate(cfg *config.Config, tmpl *templates.Template, history []api.Message) []api.Message {
+	cfg.SessionSystemPrompt = tmpl.SystemPrompt
+	cfg.SessionSampling = tmpl.Sampling
+	if len(tmpl.Functions) > 0 && cfg.DefaultFunctions == nil {
+		cfg.DefaultFunctions = make(map[string]bool)
+	}
+	for _, id := range tmpl.Functions {
+		cfg.DefaultFunctions[id] = true
+	}
+	return append(history, api.Message{Role: "assistant", Content: tmpl.Opening})
+}
+
+// ChatImportCommand continues a conversation exported from another chat tool
+func ChatImportCommand(args []string) {
+	importFlags := flag.NewFlagSet("chat import", flag.ExitOnError)
+	list := importFlags.Bool("list", false, "List the conversations in the file and exit")
+	pick := importFlags.Int("pick", 1, "Conversation to continue, as numbered by --list (newest is 1)")
+	importFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s chat import [--list] [--pick N] FILE\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Continue a conversation from another tool. FILE may be:\n")
+		fmt.Fprintf(os.Stderr, "  - a ChatGPT data export (.zip) or its conversations.json\n")
+		fmt.Fprintf(os.Stderr, "  - OpenAI-style message JSON ([{\"role\": ..., \"content\": ...}])\n")
+		fmt.Fprintf(os.Stderr, "  - an ollama Modelfile saved with /save, or ~/.ollama/history\n")
+		fmt.Fprintf(os.Stderr, "  - a markdown transcript with User/Assistant headings or prefixes\n\n")
+		importFlags.PrintDefaults()
+	}
+	importFlags.Parse(args)
+
+	if importFlags.NArg() != 1 {
+		importFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	conversations, err := transcript.ImportFile(importFlags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error importing %s: %v\n", importFlags.Arg(0), err)
+		os.Exit(failure.ExitConfig)
+	}
+
+	if *list {
+		for i, conversation := range conversations {
+			date := ""
+			if !conversation.CreatedAt.IsZero() {
+				date = conversation.CreatedAt.Format("2006-01-02")
+			}
+			fmt.Printf("%4d  %-10s %3d msgs  %s\n", i+1, date, len(conversation.Messages), conversation.Title)
+		}
+		return
+	}
+
+	if *pick < 1 || *pick > len(conversations) {
+		fmt.Fprintf(os.Stderr, "Error: --pick must be between 1 and %d\n", len(conversations))
+		os.Exit(failure.ExitConfig)
+	}
+	conversation := conversations[*pick-1]
+	fmt.Printf("Imported \"%s\" (%d messages from %s)\n", conversation.Title, len(conversation.Messages), conversation.Source)
+
+	startChatWithArgs(nil, conversation.Messages)
+}
+
+// startChatWithArgs starts a chat session, optionally loading config from URL
+// and continuing from earlier messages
+func startChatWithArgs(args []string, history []api.Message) {
+	var cfg *config.Config
+
+	// Check for session from environment first, then command line
+	var sessionLink string
+	var sessionSource string
+
+	// Check environment variables for session
+	envSession, err := share.GetSessionFromEnvironment()
+	if err != nil {
+		// Multiple session env vars defined - this is an error
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+
+	// Determine session source: command line takes precedence over environment
+	if len(args) > 0 {
+		sessionLink = args[0]
+		sessionSource = "command line"
+	} else if envSession != "" {
+		sessionLink = envSession
+		envVar, _ := share.GetEnvironmentSessionSource()
+		sessionSource = fmt.Sprintf("environment variable %s", envVar)
+	}
+
+	// Check if we have a session link to process
+	if sessionLink != "" {
+		// Parse the session link
+		fmt.Printf("Loading session from %s...\n", sessionSource)
+
+		// Ask for password
+		password, err := utils.GetPassword("Enter password for session: ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading password: %v\n", err)
+			os.Exit(failure.ExitCode(err))
+		}
+
+		// Parse the URL
+		sharedConfig, err := share.ParseURL(sessionLink, password)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing session: %s\n", failure.Message(err))
+			os.Exit(failure.ExitCode(err))
+		}
+
+		// Load into config
+		cfg = config.NewConfig()
+		cfg.LoadFromSharedConfig(sharedConfig)
+
+		fmt.Println("✓ Session loaded successfully!")
+	} else {
+		// Try to load existing configuration
+		var err error
+		cfg, err = config.LoadFromFile(config.GetConfigPath())
+		if err != nil && kioskMode {
+			fmt.Fprintf(os.Stderr, "Error: kiosk mode needs a saved configuration or a session link\n")
+			os.Exit(failure.ExitConfig)
+		}
+		if err != nil {
+			// No existing config, create new one or show settings
+			fmt.Println("No configuration found. Please configure API settings first.")
+			cfg = config.NewConfig()
+
+			// Launch TUI for configuration
+			if err := integration.LaunchTUI(cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error launching TUI: %v\n", err)
+				return
+			}
+
+			// Ask if they want to continue to chat
+			fmt.Print("\nConfiguration saved. Start chat session? (y/n): ")
+			var response string
+			fmt.Scanln(&response)
+
+			if response != "y" && response != "yes" {
+				fmt.Println("Goodbye!")
+				return
+			}
+		}
+	}
+	
+	cfg.Kiosk = kioskMode
+	if chatTemplate != nil {
+		if cfg.Kiosk || cfg.LockedByLink {
+			fmt.Fprintf(os.Stderr, "Error: templates change the system prompt, which this configuration doesn't allow\n")
+			os.Exit(failure.ExitConfig)
+		}
+		history = applyTemplate(cfg, chatTemplate, history)
+	}
+
+	// Validate configuration before starting chat
+	if cfg.APIKey == "" {
+		fmt.Println("Error: API key is required for chat session")
+		fmt.Println("Please run 'hacka.re' to configure settings")
+		os.Exit(failure.ExitConfig)
+	}
+	
+	if cfg.BaseURL == "" {
+		fmt.Println("Error: Base URL is required for chat session")
+		fmt.Println("Please run 'hacka.re' to configure settings")
+		os.Exit(failure.ExitConfig)
+	}
+	
+	// Start the enhanced chat session with slash commands
+	if err := app.StartChatWithHistory(cfg, history); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in chat session: %v\n", err)
+		os.Exit(failure.ExitCode(err))
+	}
+}
\ No newline at end of file
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/reputation"
+)
+
+// CheckURLCommand looks up the reputation of URLs, domains and IP addresses
+func CheckURLCommand(args []string) {
+	checkFlags := flag.NewFlagSet("check-url", flag.ExitOnError)
+	backends := checkFlags.String("backend", "", "Comma-separated services to ask (virustotal, urlscan; default: all with keys)")
+	failOn := checkFlags.String("fail-on", "", "Exit with code 1 if a verdict is at least this severe (suspicious or malicious)")
+	out := output.RegisterFlags(checkFlags)
+	checkFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s check-url [--backend NAMES] [--fail-on VERDICT] [--json|--quiet] TARGET...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Ask VirusTotal and urlscan.io what they know about URLs, domains and IP addresses.\n")
+		fmt.Fprintf(os.Stderr, "Only earlier analyses are looked up; nothing is submitted for scanning.\n\n")
+		checkFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nVirusTotal needs an API key: set virusTotalApiKey in the config file or\n")
+		fmt.Fprintf(os.Stderr, "$HACKARE_VIRUSTOTAL_API_KEY. An urlscan.io key ($HACKARE_URLSCAN_API_KEY) is optional.\n")
+	}
+	if err := checkFlags.Parse(args); err != nil || checkFlags.NArg() == 0 {
+		checkFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	var threshold reputation.Verdict
+	if *failOn != "" {
+		v, err := reputation.ParseVerdict(*failOn)
+		if err != nil || v == reputation.Unknown || v == reputation.Harmless {
+			os.Exit(out.Fail(failure.Usage(fmt.Errorf("--fail-on must be suspicious or malicious, not %q", *failOn))))
+		}
+		threshold = v
+	}
+
+	checker := reputation.Default()
+	if *backends != "" {
+		selected, err := checker.Select(strings.Split(*backends, ","))
+		if err != nil {
+			os.Exit(out.Fail(failure.Config(err)))
+		}
+		checker = selected
+	}
+
+	reports := []*reputation.Report{}
+	for _, target := range checkFlags.Args() {
+		ctx, cancel := context.WithTimeout(context.Background(), reputation.RequestTimeout)
+		report, err := checker.Check(ctx, target)
+		cancel()
+		if err != nil {
+			if errors.Is(err, reputation.ErrOffline) {
+				err = failure.Config(err)
+			}
+			os.Exit(out.Fail(fmt.Errorf("%s: %w", target, err)))
+		}
+		reports = append(reports, report)
+	}
+
+	out.Write(os.Stdout, "reputation", reports, func(w io.Writer) {
+		for i, report := range reports {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			writeReputationReport(w, report)
+		}
+	})
+
+	if threshold != "" {
+		for _, report := range reports {
+			if report.Verdict.AtLeast(threshold) {
+				os.Exit(failure.ExitError)
+			}
+		}
+	}
+}
+
+func writeReputationReport(w io.Writer, report *reputation.Report) {
+	fmt.Fprintf(w, "%s (%s): %s\n", report.Target.Value, report.Target.Kind, strings.ToUpper(string(report.Verdict)))
+	for _, result := range report.Results {
+		if result.Error != "" {
+			fmt.Fprintf(w, "  %-10s  error: %s\n", result.Backend, result.Error)
+			continue
+		}
+		line := fmt.Sprintf("  %-10s  %s", result.Backend, result.Verdict)
+		if result.Engines > 0 {
+			line += fmt.Sprintf(", %d/%d engines", result.Detections, result.Engines)
+		}
+		if len(result.Categories) > 0 {
+			line += " [" + strings.Join(result.Categories, ", ") + "]"
+		}
+		if result.Analyzed != "" {
+			line += ", analyzed " + result.Analyzed
+		}
+		fmt.Fprintln(w, line)
+		if result.Link != "" {
+			fmt.Fprintf(w, "              %s\n", result.Link)
+		}
+	}
+}
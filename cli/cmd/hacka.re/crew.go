@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/crew"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/jsruntime"
+	"github.com/hacka-re/cli/internal/output"
+)
+
+// CrewCommand runs several agents with their own roles on one task
+func CrewCommand(args []string) {
+	if len(args) == 0 {
+		showCrewHelp()
+		os.Exit(failure.ExitConfig)
+	}
+	switch args[0] {
+	case "run":
+		crewRun(args[1:])
+	case "init":
+		crewInit(args[1:])
+	case "help", "-h", "--help":
+		showCrewHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown crew command: %s\n\n", args[0])
+		showCrewHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showCrewHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s crew <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Run named agents that take turns on a task (researcher → writer → reviewer).\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  init [FILE]          Write an example %s\n", crew.DefaultFile)
+	fmt.Fprintf(os.Stderr, "  run [options] TASK   Run the crew on a task file (- for stdin)\n\n")
+	fmt.Fprintf(os.Stderr, "Examples:\n")
+	fmt.Fprintf(os.Stderr, "  %s crew init\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s crew run --transcript report-crew.md task.md\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  echo 'Summarise CVE-2024-3094' | %s crew run --max-cost 0.10 -\n\n", os.Args[0])
+}
+
+func crewInit(args []string) {
+	path := crew.DefaultFile
+	if len(args) > 0 {
+		path = args[0]
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(crew.Example); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+	fmt.Printf("Wrote %s\n", path)
+}
+
+func crewRun(args []string) {
+	runFlags := flag.NewFlagSet("crew run", flag.ExitOnError)
+	agentsFile := runFlags.String("agents", crew.DefaultFile, "Agents file")
+	transcriptFile := runFlags.String("transcript", "", "Also write the transcript as markdown to this file")
+	session := runFlags.String("session", "", "Share link to use instead of the saved configuration")
+	maxRounds := runFlags.Int("max-rounds", 0, "Override budget.max_rounds")
+	maxTokens := runFlags.Int("max-tokens", 0, "Override budget.max_tokens")
+	maxCost := runFlags.Float64("max-cost", 0, "Override budget.max_cost (USD)")
+	yolo := runFlags.Bool("yolo", false, "Run tool calls without asking")
+	sampling := registerSamplingFlags(runFlags)
+	out := output.RegisterFlags(runFlags)
+	runFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s crew run [options] TASK\n\n", os.Args[0])
+		runFlags.PrintDefaults()
+	}
+	if err := runFlags.Parse(args); err != nil || runFlags.NArg() != 1 {
+		runFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	task, err := readTask(runFlags.Arg(0))
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	definition, err := crew.Load(*agentsFile)
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	if *maxRounds > 0 {
+		definition.Budget.MaxRounds = *maxRounds
+	}
+	if *maxTokens > 0 {
+		definition.Budget.MaxTokens = *maxTokens
+	}
+	if *maxCost > 0 {
+		definition.Budget.MaxCost = *maxCost
+	}
+
+	cfg, err := loadBridgeConfig(*session)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	var agentModels []string
+	for _, agent := range definition.Agents {
+		agentModels = append(agentModels, agentConfig(cfg, agent).Model)
+	}
+	sampling.apply(cfg, out, agentModels...)
+
+	registry := jsruntime.NewRegistry()
+	if err := jsruntime.LoadSharedFunctions(registry, cfg.Functions); err != nil {
+		out.Infof("Warning: %v", err)
+	}
+	if err := jsruntime.LoadEnabledDefaults(registry, cfg.DefaultFunctions); err != nil {
+		out.Infof("Warning: %v", err)
+	}
+	loadFunctionsDir(registry, out.Infof)
+	runner := &crew.Runner{
+		Crew:    definition,
+		Clients: map[string]crew.Completer{},
+		Models:  map[string]string{},
+		Tools:   registry,
+	}
+	for _, agent := range definition.Agents {
+		agentCfg := agentConfig(cfg, agent)
+		if *sampling.deterministic {
+			agentCfg.MakeDeterministic()
+		}
+		client := api.NewClient(agentCfg)
+		client.SetTools(agentTools(registry.APITools(), agent.Tools))
+		runner.Clients[agent.Name] = client
+		runner.Models[agent.Name] = agentCfg.Model
+	}
+	if !*yolo && !cfg.YoloMode {
+		runner.Approve = toolApprover(runFlags.Arg(0) == "-")
+	}
+	runner.ToolLimiter = loadToolLimiter(out.Infof)
+	runner.OnQueued = func(agent, tool, reason string) {
+		out.Infof("%s: tool %s is queued: %s", agent, tool, reason)
+	}
+	if !out.JSON && !out.Quiet {
+		runner.OnTurn = printTurn
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out.Infof("Running %d agents: %s", len(definition.Agents), strings.Join(definition.Order(), " → "))
+	transcript, runErr := runner.Run(ctx, task)
+
+	if *transcriptFile != "" && len(transcript.Turns) > 0 {
+		if err := writeCrewTranscript(*transcriptFile, transcript, definition); err != nil {
+			out.Infof("Warning: %v", err)
+		}
+	}
+	if runErr != nil {
+		os.Exit(out.Fail(runErr))
+	}
+
+	out.Write(os.Stdout, "crew", transcript, func(w io.Writer) {
+		fmt.Fprintf(w, "\n════ Result (%s", transcript.Stopped)
+		if transcript.Reason != "" {
+			fmt.Fprintf(w, ": %s", transcript.Reason)
+		}
+		fmt.Fprintf(w, ", %d turns, %d tokens, $%.4f) ════\n\n%s\n",
+			len(transcript.Turns), transcript.Tokens, transcript.Cost, transcript.Result(definition))
+	})
+}
+
+// readTask reads the task from a file, or stdin for "-"
+func readTask(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read task: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("the task is empty")
+	}
+	return string(data), nil
+}
+
+// agentConfig applies an agent's model, provider and sampling to a copy of the configuration
+func agentConfig(cfg *config.Config, agent crew.Agent) *config.Config {
+	agentCfg := *cfg
+	if agent.Model != "" {
+		agentCfg.Model = agent.Model
+	}
+	if agent.Provider != "" {
+		agentCfg.Provider = config.Provider(agent.Provider)
+		agentCfg.BaseURL = config.GetProviderBaseURL(agentCfg.Provider)
+		if config.IsLocalProvider(agentCfg.Provider) {
+			agentCfg.APIKey = ""
+		}
+	}
+	if agent.BaseURL != "" {
+		agentCfg.BaseURL = agent.BaseURL
+	}
+	agentCfg.SystemPrompt = ""
+	agentCfg.SessionSampling = agent.Override
+	return &agentCfg
+}
+
+// agentTools returns the tools an agent is allowed to call
+func agentTools(tools []api.Tool, allowed []string) []api.Tool {
+	var selected []api.Tool
+	for _, tool := range tools {
+		for _, name := range allowed {
+			if tool.Function.Name == name {
+				selected = append(selected, tool)
+			}
+		}
+	}
+	return selected
+}
+
+// toolApprover asks on the terminal before each tool call. When the task came
+// from stdin there is no one to ask, so tools are declined.
+func toolApprover(taskFromStdin bool) func(agent, tool, arguments string) bool {
+	reader := bufio.NewReader(os.Stdin)
+	return func(agent, tool, arguments string) bool {
+		if taskFromStdin {
+			fmt.Fprintf(os.Stderr, "Declined %s for %s: approval needs a terminal (use --yolo)\n", tool, agent)
+			return false
+		}
+		fmt.Fprintf(os.Stderr, "\n%s wants to run %s with %s\nRun it? [y/N] ", agent, tool, arguments)
+		answer, _ := reader.ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes"
+	}
+}
+
+// printTurn is the live transcript view
+func printTurn(turn crew.Turn) {
+	fmt.Printf("\n\033[1m── %s\033[0m \033[90m(%s · round %d", turn.Agent, turn.Model, turn.Round)
+	if len(turn.ToolCalls) > 0 {
+		fmt.Printf(" · tools: %s", strings.Join(turn.ToolCalls, ", "))
+	}
+	fmt.Printf(")\033[0m\n%s\n\033[90m[%d tokens, $%.4f]\033[0m\n",
+		turn.Content, turn.PromptTokens+turn.CompletionTokens, turn.Cost)
+}
+
+// writeCrewTranscript saves the transcript as markdown
+func writeCrewTranscript(path string, transcript *crew.Transcript, definition *crew.Crew) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	defer file.Close()
+	return transcript.WriteMarkdown(file, definition)
+}
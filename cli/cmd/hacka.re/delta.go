@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/utils"
+	"github.com/hacka-re/cli/pkg/sharelink"
+)
+
+// DeltaCommand shares only what changed from a named base configuration
+func DeltaCommand(args []string) {
+	if len(args) == 0 {
+		showDeltaHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	switch args[0] {
+	case "base":
+		deltaBase(args[1:])
+	case "bases":
+		deltaBases(args[1:])
+	case "create":
+		deltaCreate(args[1:])
+	case "apply":
+		deltaApply(args[1:])
+	case "help", "-h", "--help":
+		showDeltaHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown delta command: %s\n\n", args[0])
+		showDeltaHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showDeltaHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s delta <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Share only what changed from a base configuration the team already has.\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  base NAME [LINK]      Save the current config, or LINK's, as base NAME\n")
+	fmt.Fprintf(os.Stderr, "  bases                 List the saved bases\n")
+	fmt.Fprintf(os.Stderr, "  create NAME           Create a link with what the current config changes from NAME\n")
+	fmt.Fprintf(os.Stderr, "  apply [--yes] LINK    Preview a delta link and merge it into the current config\n\n")
+	fmt.Fprintf(os.Stderr, "Bases are kept in %s, without API keys. A delta link never\n", share.BasesDir())
+	fmt.Fprintf(os.Stderr, "carries an API key; the recipient's own key and other settings are kept.\n")
+}
+
+// currentSharedConfig returns the saved configuration as a share link would carry it
+func currentSharedConfig() (*config.Config, *share.SharedConfig, error) {
+	cfg, err := config.LoadFromFile(config.GetConfigPath())
+	if err != nil {
+		return nil, nil, failure.Config(err)
+	}
+	return cfg, cfg.ToSharedConfig(), nil
+}
+
+func deltaBase(args []string) {
+	baseFlags := flag.NewFlagSet("delta base", flag.ExitOnError)
+	password := baseFlags.String("password", "", "Password of LINK; prompted for when empty")
+	out := output.RegisterFlags(baseFlags)
+	baseFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s delta base NAME [LINK] [options]\n\n", os.Args[0])
+		baseFlags.PrintDefaults()
+	}
+	positional := parseInterspersed(baseFlags, args)
+	if len(positional) < 1 || len(positional) > 2 {
+		baseFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	var base *share.SharedConfig
+	var err error
+	if len(positional) == 2 {
+		base, err = decryptLink(positional[1], *password, "Enter password: ")
+	} else {
+		_, base, err = currentSharedConfig()
+	}
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	if base.Delta != nil {
+		os.Exit(out.Fail(failure.Usage(errors.New("a delta link can't be a base; apply it, then save the result"))))
+	}
+	name := positional[0]
+	if err := share.SaveBase(share.BasesDir(), name, base); err != nil {
+		os.Exit(out.Fail(failure.Usage(err)))
+	}
+
+	fingerprint := sharelink.Fingerprint(base)
+	out.Write(os.Stdout, "deltaBase", struct {
+		Name        string `json:"name"`
+		Fingerprint string `json:"fingerprint"`
+	}{name, fingerprint}, func(w io.Writer) {
+		fmt.Fprintf(w, "Saved base %s (%s)\n", name, fingerprint)
+	})
+}
+
+func deltaBases(args []string) {
+	basesFlags := flag.NewFlagSet("delta bases", flag.ExitOnError)
+	out := output.RegisterFlags(basesFlags)
+	if err := basesFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	type baseInfo struct {
+		Name        string `json:"name"`
+		Fingerprint string `json:"fingerprint"`
+	}
+	dir := share.BasesDir()
+	names, err := share.ListBases(dir)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	bases := []baseInfo{}
+	for _, name := range names {
+		base, err := share.LoadBase(dir, name)
+		if err != nil {
+			out.Infof("Skipping %s: %v", name, err)
+			continue
+		}
+		bases = append(bases, baseInfo{name, sharelink.Fingerprint(base)})
+	}
+	out.Write(os.Stdout, "deltaBases", bases, func(w io.Writer) {
+		if len(bases) == 0 {
+			fmt.Fprintf(w, "No bases saved; save one with: %s delta base NAME\n", os.Args[0])
+			return
+		}
+		for _, base := range bases {
+			fmt.Fprintf(w, "%-24s %s\n", base.Name, base.Fingerprint)
+		}
+	})
+}
+
+func deltaCreate(args []string) {
+	createFlags := flag.NewFlagSet("delta create", flag.ExitOnError)
+	password := createFlags.String("password", "", "Password of the link; prompted for when empty")
+	out := output.RegisterFlags(createFlags)
+	createFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s delta create NAME [options]\n\n", os.Args[0])
+		createFlags.PrintDefaults()
+	}
+	positional := parseInterspersed(createFlags, args)
+	if len(positional) != 1 {
+		createFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	name := positional[0]
+	base, err := share.LoadBase(share.BasesDir(), name)
+	if errors.Is(err, share.ErrNoBase) {
+		err = failure.Usage(err)
+	}
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	_, current, err := currentSharedConfig()
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	delta := sharelink.MakeDelta(name, base, current)
+	if delta.Empty() {
+		os.Exit(out.Fail(failure.Usage(fmt.Errorf("the current configuration is the same as base %s", name))))
+	}
+
+	if *password == "" {
+		if *password, err = confirmedLinkPassword(); err != nil {
+			os.Exit(out.Fail(err))
+		}
+	}
+	link, err := share.CreateShareableURL(&share.SharedConfig{Delta: delta}, *password, "https://hacka.re/")
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+
+	// The key is left out of a delta, so leave it out of the summary too
+	current.APIKey = base.APIKey
+	changes := sharelink.Diff(base, current)
+	out.Write(os.Stdout, "deltaLink", struct {
+		Link    string             `json:"link"`
+		Base    string             `json:"base"`
+		Changes []sharelink.Change `json:"changes"`
+	}{link, name, changes}, func(w io.Writer) {
+		writeChanges(w, changes)
+		fmt.Fprintf(w, "\nDelta link from base %s:\n%s\n", name, link)
+	})
+}
+
+// confirmedLinkPassword asks for a new link password twice, on stderr
+func confirmedLinkPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "Enter password for the link: ")
+	password, err := utils.GetPasswordSilent()
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Confirm password: ")
+	again, err := utils.GetPasswordSilent()
+	if err != nil {
+		return "", err
+	}
+	if again != password {
+		return "", failure.Usage(errors.New("passwords do not match"))
+	}
+	return password, nil
+}
+
+func deltaApply(args []string) {
+	applyFlags := flag.NewFlagSet("delta apply", flag.ExitOnError)
+	password := applyFlags.String("password", "", "Password of the link; prompted for when empty")
+	yes := applyFlags.Bool("yes", false, "Apply without asking")
+	out := output.RegisterFlags(applyFlags)
+	applyFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s delta apply LINK [options]\n\n", os.Args[0])
+		applyFlags.PrintDefaults()
+	}
+	positional := parseInterspersed(applyFlags, args)
+	if len(positional) != 1 {
+		applyFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	shared, err := decryptLink(positional[0], *password, "Enter password: ")
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	if shared.Delta == nil {
+		os.Exit(out.Fail(failure.Usage(fmt.Errorf("not a delta link; open it with: %s LINK", os.Args[0]))))
+	}
+	if _, err := applyDelta(out, shared.Delta, *yes); err != nil {
+		os.Exit(out.Fail(err))
+	}
+}
+
+// deltaPreview is what applying a delta would do
+type deltaPreview struct {
+	Base      string             `json:"base"`
+	Changes   []sharelink.Change `json:"changes"`
+	Overrides []string           `json:"overrides,omitempty"` // Local changes the delta replaces
+	Note      string             `json:"note,omitempty"`      // Why overrides couldn't be checked
+	Applied   bool               `json:"applied"`
+}
+
+// applyDelta shows what the delta changes in the saved configuration, asks
+// unless yes is set, and saves the merge. It returns the configuration as it
+// is afterwards.
+func applyDelta(out *output.Options, delta *sharelink.Delta, yes bool) (*config.Config, error) {
+	cfg, current, err := currentSharedConfig()
+	if err != nil {
+		return nil, err
+	}
+	merged, err := delta.Apply(current)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := deltaPreview{Base: delta.Base, Changes: sharelink.Diff(current, merged)}
+	base, err := share.LoadBase(share.BasesDir(), delta.Base)
+	switch {
+	case errors.Is(err, share.ErrNoBase):
+		preview.Note = fmt.Sprintf("you have no base %s, so changes of your own that this replaces can't be shown", delta.Base)
+	case err != nil:
+		return nil, err
+	default:
+		preview.Overrides, err = delta.Overrides(base, current)
+		if errors.Is(err, sharelink.ErrBaseMismatch) {
+			preview.Note = fmt.Sprintf("your base %s differs from the sender's, so changes of your own that this replaces can't be shown", delta.Base)
+		}
+	}
+
+	writePreview := func(w io.Writer) {
+		fmt.Fprintf(w, "Delta from base %s:\n\n", delta.Base)
+		if len(preview.Changes) == 0 {
+			fmt.Fprintln(w, "Your configuration already has these changes.")
+		} else {
+			writeChanges(w, preview.Changes)
+		}
+		if len(preview.Overrides) > 0 {
+			fmt.Fprintf(w, "\n⚠ Replaces your own changes to: %s\n", strings.Join(preview.Overrides, ", "))
+		}
+		if preview.Note != "" {
+			fmt.Fprintf(w, "\nNote: %s\n", preview.Note)
+		}
+	}
+	if len(preview.Changes) > 0 && !yes {
+		writePreview(os.Stderr)
+		fmt.Fprint(os.Stderr, "\nApply? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			return nil, failure.Usage(errors.New("delta not applied"))
+		}
+		writePreview = func(io.Writer) {}
+	}
+
+	if len(preview.Changes) > 0 {
+		cfg.SetFromSharedConfig(merged)
+		if err := cfg.SaveToFile(config.GetConfigPath()); err != nil {
+			return nil, err
+		}
+		preview.Applied = true
+	}
+	out.Write(os.Stdout, "deltaApply", preview, func(w io.Writer) {
+		writePreview(w)
+		if preview.Applied {
+			fmt.Fprintf(w, "\n✓ Applied %d changes to %s\n", len(preview.Changes), config.GetConfigPath())
+		}
+	})
+	return cfg, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/hardware"
+	"github.com/hacka-re/cli/internal/output"
+)
+
+// DoctorCommand checks the configuration and the machine, and recommends
+// local models that fit its hardware
+func DoctorCommand(args []string) {
+	doctorFlags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	out := output.RegisterFlags(doctorFlags)
+	doctorFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s doctor [--json|--quiet]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Check the configuration and recommend local models for this machine.\n\n")
+		doctorFlags.PrintDefaults()
+	}
+	if err := doctorFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	checks := configChecks()
+	checks = append(checks, hardwareChecks(hardware.Detect())...)
+
+	out.Write(os.Stdout, "doctor", checks, func(w io.Writer) {
+		for _, check := range checks {
+			fmt.Fprintf(w, "[%-4s] %-24s %s\n", check.Status, check.Name, check.Detail)
+		}
+	})
+
+	for _, check := range checks {
+		if check.Status == output.CheckFail {
+			os.Exit(failure.ExitConfig)
+		}
+	}
+}
+
+// configChecks checks that the configuration loads and names a usable provider
+func configChecks() []output.Check {
+	path := config.GetConfigPath()
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		return []output.Check{{Name: "Configuration", Status: output.CheckFail, Detail: err.Error()}}
+	}
+	checks := []output.Check{{Name: "Configuration", Status: output.CheckOK, Detail: path}}
+
+	switch {
+	case cfg.Provider == "":
+		checks = append(checks, output.Check{Name: "Provider", Status: output.CheckWarn, Detail: "none configured, run hacka.re to pick one"})
+	case !config.IsLocalProvider(cfg.Provider) && cfg.APIKey == "":
+		checks = append(checks, output.Check{Name: "Provider", Status: output.CheckFail, Detail: fmt.Sprintf("%s needs an API key", cfg.Provider)})
+	default:
+		checks = append(checks, output.Check{Name: "Provider", Status: output.CheckOK, Detail: fmt.Sprintf("%s, model %s", cfg.Provider, cfg.Model)})
+	}
+	return checks
+}
+
+// hardwareChecks describes the machine and the local models that fit it
+func hardwareChecks(info hardware.Info) []output.Check {
+	checks := []output.Check{{Name: "Hardware", Status: output.CheckOK, Detail: info.Summary()}}
+	if len(info.GPUs) == 0 {
+		checks = append(checks, output.Check{Name: "GPU", Status: output.CheckWarn, Detail: "none detected, local models run on the CPU"})
+	}
+
+	recommendations := hardware.Recommend(info)
+	if len(recommendations) == 0 {
+		return append(checks, output.Check{Name: "Recommended local model", Status: output.CheckWarn, Detail: "not enough memory detected for a local model"})
+	}
+	for _, rec := range recommendations {
+		checks = append(checks, output.Check{Name: "Recommended local model", Status: output.CheckOK, Detail: rec.String()})
+	}
+	return checks
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/utils"
+	"github.com/hacka-re/cli/pkg/sharelink"
+)
+
+// DumpCommand decrypts a share link and prints its configuration as JSON,
+// or with --diff compares two links section by section
+func DumpCommand(args []string) {
+	dumpFlags := flag.NewFlagSet("dump", flag.ExitOnError)
+	password := dumpFlags.String("password", "", "Password of the link(s); prompted for when empty")
+	diff := dumpFlags.Bool("diff", false, "Compare two links: providers, prompts, functions and settings")
+	out := output.RegisterFlags(dumpFlags)
+	dumpFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s dump LINK [--password pw]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s dump --diff LINK1 LINK2 [--password pw] [--json|--quiet]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Decrypt a share link and print its configuration as JSON. LINK is a full URL,\n")
+		fmt.Fprintf(os.Stderr, "a gpt= fragment or the encrypted data. With --diff, print what LINK2 changes\n")
+		fmt.Fprintf(os.Stderr, "compared to LINK1, e.g. to audit a teammate's link before loading it.\n\n")
+		dumpFlags.PrintDefaults()
+	}
+	links := parseInterspersed(dumpFlags, args)
+
+	if !*diff {
+		if len(links) != 1 {
+			dumpFlags.Usage()
+			os.Exit(failure.ExitConfig)
+		}
+		cfg, err := decryptLink(links[0], *password, "Enter password: ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", failure.Message(err))
+			os.Exit(failure.ExitCode(err))
+		}
+		data, err := json.MarshalIndent(cfg, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(links) != 2 {
+		dumpFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+	before, err := decryptLink(links[0], *password, "Enter password for the first link: ")
+	if err != nil {
+		os.Exit(out.Fail(fmt.Errorf("first link: %w", err)))
+	}
+	after, err := decryptLink(links[1], *password, "Enter password for the second link: ")
+	if err != nil {
+		os.Exit(out.Fail(fmt.Errorf("second link: %w", err)))
+	}
+
+	changes := sharelink.Diff(before, after)
+	out.Write(os.Stdout, "dump-diff", changes, func(w io.Writer) {
+		if len(changes) == 0 {
+			fmt.Fprintln(w, "The links configure the same settings.")
+			return
+		}
+		writeChanges(w, changes)
+	})
+}
+
+// writeChanges prints changes grouped by section
+func writeChanges(w io.Writer, changes []sharelink.Change) {
+	section := ""
+	for _, change := range changes {
+		if change.Section != section {
+			if section != "" {
+				fmt.Fprintln(w)
+			}
+			section = change.Section
+			fmt.Fprintf(w, "%s:\n", section)
+		}
+		fmt.Fprintf(w, "  %s\n", change)
+	}
+}
+
+// decryptLink decrypts a link, prompting on stderr for the password when none
+// was given so stdout stays clean for the JSON
+func decryptLink(link, password, prompt string) (*share.SharedConfig, error) {
+	if password == "" {
+		fmt.Fprint(os.Stderr, prompt)
+		var err error
+		if password, err = utils.GetPasswordSilent(); err != nil {
+			return nil, err
+		}
+	}
+	return share.ParseURL(link, password)
+}
+
+// parseInterspersed parses fs from args, allowing flags after the positional
+// arguments as in "dump LINK --password pw", and returns the positionals
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			os.Exit(failure.ExitConfig)
+		}
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/eval"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/models"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// EvalCommand runs prompt suites against models and compares the scores
+func EvalCommand(args []string) {
+	if len(args) == 0 {
+		showEvalHelp()
+		os.Exit(failure.ExitConfig)
+	}
+	switch args[0] {
+	case "run":
+		evalRun(args[1:])
+	case "init":
+		evalInit(args[1:])
+	case "help", "-h", "--help":
+		showEvalHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown eval command: %s\n\n", args[0])
+		showEvalHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showEvalHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s eval <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Replay a suite of prompts against models and score the replies.\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  init [FILE]           Write an example %s\n", eval.DefaultFile)
+	fmt.Fprintf(os.Stderr, "  run [options] SUITE   Run every case against every model\n\n")
+	fmt.Fprintf(os.Stderr, "Examples:\n")
+	fmt.Fprintf(os.Stderr, "  %s eval init\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s eval run --report report.md suite.yaml\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s eval run --models gpt-4o-mini,llama-3.3-70b-versatile --deterministic --json suite.yaml\n\n", os.Args[0])
+}
+
+func evalInit(args []string) {
+	path := eval.DefaultFile
+	if len(args) > 0 {
+		path = args[0]
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(eval.Example); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+	fmt.Printf("Wrote %s\n", path)
+}
+
+func evalRun(args []string) {
+	runFlags := flag.NewFlagSet("eval run", flag.ExitOnError)
+	modelList := runFlags.String("models", "", "Comma-separated models to compare instead of the suite's")
+	judgeModel := runFlags.String("judge", "", "Model for judge scorers instead of the suite's")
+	reportFile := runFlags.String("report", "", "Also write the report as markdown to this file")
+	session := runFlags.String("session", "", "Share link to use instead of the saved configuration")
+	sampling := registerSamplingFlags(runFlags)
+	out := output.RegisterFlags(runFlags)
+	runFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s eval run [options] SUITE\n\n", os.Args[0])
+		runFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExits with 1 when any case fails, so it can gate CI.\n")
+	}
+	if err := runFlags.Parse(args); err != nil || runFlags.NArg() != 1 {
+		runFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	suite, err := eval.Load(runFlags.Arg(0))
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	cfg, err := loadBridgeConfig(*session)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+
+	modelIDs := suite.Models
+	if *modelList != "" {
+		modelIDs = nil
+		for _, model := range strings.Split(*modelList, ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				modelIDs = append(modelIDs, model)
+			}
+		}
+	}
+	if len(modelIDs) == 0 {
+		modelIDs = []string{cfg.Model}
+	}
+	judge := suite.Judge
+	if *judgeModel != "" {
+		judge = *judgeModel
+	}
+	if judge == "" {
+		judge = cfg.Model
+	}
+	sampling.apply(cfg, out, modelIDs...)
+
+	runner := &eval.Runner{
+		Suite:      suite,
+		Models:     modelIDs,
+		Clients:    map[string]eval.Completer{},
+		System:     cfg.SystemPrompt,
+		Judge:      api.NewClient(modelConfig(cfg, judge)),
+		JudgeModel: judge,
+		Tracker:    usage.NewTracker(usage.DefaultPath()),
+		Seed:       cfg.Seed,
+	}
+	registry := models.NewModelRegistry()
+	for _, model := range modelIDs {
+		runner.Clients[model] = api.NewClient(modelConfig(cfg, model))
+	}
+	priced := append([]string{}, modelIDs...)
+	if suite.UsesJudge() && runner.Clients[judge] == nil {
+		priced = append(priced, judge)
+	}
+	for _, model := range priced {
+		if meta, ok := registry.GetModel(model); !ok || meta.PricingInput == 0 {
+			out.Infof("Note: no pricing known for %s; its cost is reported as $0", model)
+		}
+	}
+	if !out.JSON && !out.Quiet {
+		runner.OnResult = printEvalResult
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out.Infof("Running %d cases against %s", len(suite.Cases), strings.Join(modelIDs, ", "))
+	report, runErr := runner.Run(ctx)
+
+	if *reportFile != "" && len(report.Results) > 0 {
+		if err := writeEvalReport(*reportFile, report); err != nil {
+			out.Infof("Warning: %v", err)
+		}
+	}
+	if runErr != nil {
+		os.Exit(out.Fail(runErr))
+	}
+
+	out.Write(os.Stdout, "eval", report, func(w io.Writer) {
+		fmt.Fprintln(w)
+		report.WriteMarkdown(w)
+	})
+	if report.Failed() > 0 {
+		os.Exit(failure.ExitError)
+	}
+}
+
+// modelConfig returns a copy of the configuration using model
+func modelConfig(cfg *config.Config, model string) *config.Config {
+	modelCfg := *cfg
+	modelCfg.Model = model
+	modelCfg.StreamResponse = false
+	return &modelCfg
+}
+
+// printEvalResult is the live progress view
+func printEvalResult(result eval.Result) {
+	mark := "\033[32m✓\033[0m"
+	if !result.Passed {
+		mark = "\033[31m✗\033[0m"
+	}
+	fmt.Printf("%s %s \033[90m(%s · %.2f · %.1fs)\033[0m\n",
+		mark, result.Case, result.Model, result.Score, float64(result.LatencyMs)/1000)
+	if result.Error != "" {
+		fmt.Printf("  \033[90m%s\033[0m\n", result.Error)
+	}
+}
+
+// writeEvalReport saves the report as markdown
+func writeEvalReport(path string, report *eval.Report) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	defer file.Close()
+	return report.WriteMarkdown(file)
+}
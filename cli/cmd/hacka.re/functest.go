@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/functest"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/share"
+)
+
+// FunctionTestCommand runs the test cases of the configured functions. It
+// handles "function test" when the arguments start with a flag; "function
+// test NAME JSON" still calls a function once.
+func FunctionTestCommand(args []string) {
+	testFlags := flag.NewFlagSet("function test", flag.ExitOnError)
+	all := testFlags.Bool("all", false, "Test every custom function, enabled or not")
+	cases := testFlags.Bool("cases", false, "Test the named functions")
+	verbose := testFlags.Bool("v", false, "List passing cases too")
+	out := output.RegisterFlags(testFlags)
+	testFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s function test --all [-v] [--json|--quiet]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s function test --cases [-v] [--json|--quiet] NAME...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Run the test cases of custom functions. Cases are kept as a \"tests\" list on a\n")
+		fmt.Fprintf(os.Stderr, "function in the configuration, or written as JSDoc lines:\n\n")
+		fmt.Fprintf(os.Stderr, "  @test {\"a\": 2, \"b\": 3} => 5\n")
+		fmt.Fprintf(os.Stderr, "  @test {\"text\": \"abc\"} ~ /^[0-9a-f]{32}$/\n")
+		fmt.Fprintf(os.Stderr, "  @test {\"n\": -1} throws\n\n")
+		testFlags.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExits with code 1 if a case fails or a function doesn't load.\n")
+	}
+	if err := testFlags.Parse(args); err != nil || *all == *cases || (*cases && testFlags.NArg() == 0) || (*all && testFlags.NArg() > 0) {
+		testFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	cfg, err := config.LoadFromFile(config.GetConfigPath())
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	functions := cfg.Functions
+	if *cases {
+		functions, err = selectFunctions(cfg.Functions, testFlags.Args())
+		if err != nil {
+			os.Exit(out.Fail(failure.Usage(err)))
+		}
+	}
+
+	reports := functest.RunAll(functions)
+	out.Write(os.Stdout, "function-tests", reports, func(w io.Writer) {
+		writeFunctionTests(w, reports, *verbose)
+	})
+	for _, report := range reports {
+		if !report.OK() {
+			os.Exit(failure.ExitError)
+		}
+	}
+}
+
+// selectFunctions returns the named functions, in the order named
+func selectFunctions(functions []share.Function, names []string) ([]share.Function, error) {
+	selected := make([]share.Function, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, fn := range functions {
+			if fn.Name == name {
+				selected = append(selected, fn)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no custom function named %q", name)
+		}
+	}
+	return selected, nil
+}
+
+func writeFunctionTests(w io.Writer, reports []functest.Report, verbose bool) {
+	if len(reports) == 0 {
+		fmt.Fprintln(w, "No custom functions configured.")
+		return
+	}
+	var passed, failed, untested int
+	for _, report := range reports {
+		label := "PASS"
+		switch {
+		case !report.OK():
+			label = "FAIL"
+			failed++
+		case len(report.Results) == 0:
+			label = "----"
+			untested++
+		default:
+			passed++
+		}
+		fmt.Fprintf(w, "%s  %-24s %s\n", label, report.Function, report.Status())
+		if report.Error != "" {
+			fmt.Fprintf(w, "      %s\n", report.Error)
+		}
+		for _, result := range report.Results {
+			switch {
+			case !result.Passed:
+				fmt.Fprintf(w, "      ✗ %s: %s, got %s\n", result.Name, result.Problem, result.Got)
+			case verbose:
+				fmt.Fprintf(w, "      ✓ %s\n", result.Name)
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n%d function(s): %d passed, %d failed, %d without tests\n", len(reports), passed, failed, untested)
+}
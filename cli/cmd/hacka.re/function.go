@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/jsruntime"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/tags"
+)
+
+// FunctionCommand handles the function subcommands
+func FunctionCommand(args []string) {
+	if len(args) == 0 {
+		showFunctionHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	switch args[0] {
+	case "list", "ls":
+		functionListCommand(args[1:])
+	case "test":
+		functionCallCommand(args[1:])
+	case "help", "-h", "--help":
+		showFunctionHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown function command: %s\n\n", args[0])
+		showFunctionHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showFunctionHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s function COMMAND [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  list [--tag TAGS] [--json|--quiet]  List the configured, default and file functions\n")
+	fmt.Fprintf(os.Stderr, "  test NAME [JSON]                    Call a function once with JSON arguments\n")
+	fmt.Fprintf(os.Stderr, "  test --all | --cases NAME...        Run the functions' test cases\n")
+}
+
+// functionListCommand lists the functions the model can be offered
+func functionListCommand(args []string) {
+	listFlags := flag.NewFlagSet("function list", flag.ExitOnError)
+	tagFilter := listFlags.String("tag", "", "Only list functions carrying all of these tags, e.g. \"recon,web\"")
+	out := output.RegisterFlags(listFlags)
+	listFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s function list [--tag TAGS] [--json|--quiet]\n\n", os.Args[0])
+		listFlags.PrintDefaults()
+	}
+	if err := listFlags.Parse(args); err != nil || listFlags.NArg() > 0 {
+		listFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	registry, disabled, err := loadFunctionRegistry(out.Infof)
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+
+	list := output.Functions(registry, tags.Parse(*tagFilter), disabled)
+	out.Write(os.Stdout, "functions", list, func(w io.Writer) {
+		if len(list) == 0 {
+			fmt.Fprintln(w, "No functions found.")
+			return
+		}
+		for _, fn := range list {
+			state := "enabled"
+			if !fn.Enabled {
+				state = "disabled"
+			}
+			fmt.Fprintf(w, "%-24s %-8s %s", fn.Name, state, fn.Description)
+			if len(fn.Tags) > 0 {
+				fmt.Fprintf(w, "  %s", tags.String(fn.Tags))
+			}
+			fmt.Fprintln(w)
+		}
+	})
+}
+
+// functionCallCommand calls one function with JSON arguments and prints the result
+func functionCallCommand(args []string) {
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Fprintf(os.Stderr, "Usage: %s function test NAME [JSON]\n", os.Args[0])
+		os.Exit(failure.ExitConfig)
+	}
+
+	callArgs := map[string]interface{}{}
+	if len(args) == 2 {
+		if err := json.Unmarshal([]byte(args[1]), &callArgs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: arguments must be a JSON object: %v\n", err)
+			os.Exit(failure.ExitConfig)
+		}
+	}
+
+	registry, _, err := loadFunctionRegistry(func(format string, a ...interface{}) {
+		fmt.Fprintf(os.Stderr, format+"\n", a...)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", failure.Message(err))
+		os.Exit(failure.ExitConfig)
+	}
+
+	result, err := registry.Execute(args[0], callArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		fmt.Println(result)
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// loadFunctionRegistry registers the configured functions, disabled ones
+// included, the enabled default functions and the functions directory. It
+// returns the names of the disabled functions alongside the registry.
+func loadFunctionRegistry(warnf func(format string, args ...interface{})) (*jsruntime.Registry, map[string]bool, error) {
+	cfg, err := config.LoadFromFile(config.GetConfigPath())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	registry := jsruntime.NewRegistry()
+	if err := jsruntime.LoadSharedFunctions(registry, cfg.Functions); err != nil {
+		warnf("Warning: %v", err)
+	}
+	disabled := map[string]bool{}
+	for _, shared := range cfg.Functions {
+		if shared.Enabled || registry.HasFunction(shared.Name) {
+			continue
+		}
+		fn, err := jsruntime.ParseFunction(shared.Code)
+		if err != nil {
+			warnf("Warning: function %s: %v", shared.Name, err)
+			continue
+		}
+		if fn.Description == "" {
+			fn.Description = shared.Description
+		}
+		fn.Tags = tags.Normalize(append(fn.Tags, shared.Tags...))
+		if err := registry.AddOrReplace(fn); err == nil {
+			disabled[fn.Name] = true
+		}
+	}
+	if err := jsruntime.LoadEnabledDefaults(registry, cfg.DefaultFunctions); err != nil {
+		warnf("Warning: %v", err)
+	}
+	loadFunctionsDir(registry, warnf)
+	return registry, disabled, nil
+}
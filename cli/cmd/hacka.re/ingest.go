@@ -0,0 +1,353 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/pcap"
+	"github.com/hacka-re/cli/internal/scans"
+)
+
+// IngestCommand reads Nmap and masscan output and packet captures for the
+// scan-data functions
+func IngestCommand(args []string) {
+	if len(args) == 0 {
+		showIngestHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	dir := scans.Dir()
+	switch args[0] {
+	case "nmap", "masscan":
+		ingestScan(dir, args[0], args[1:])
+	case "pcap":
+		ingestPcap(args[1:])
+	case "captures":
+		ingestCaptures(args[1:])
+	case "list", "ls":
+		ingestList(dir, args[1:])
+	case "show":
+		ingestShow(dir, args[1:])
+	case "remove", "rm":
+		if len(args) != 2 {
+			fmt.Fprintf(os.Stderr, "Usage: %s ingest remove NAME\n", os.Args[0])
+			os.Exit(failure.ExitConfig)
+		}
+		if err := scans.Remove(dir, args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(failure.ExitConfig)
+		}
+		fmt.Printf("Removed scan %s\n", args[1])
+	case "help", "-h", "--help":
+		showIngestHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown ingest command: %s\n\n", args[0])
+		showIngestHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showIngestHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s ingest <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Keep scan results so the model can query them with the scan-data functions.\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  nmap [--name NAME] FILE     Ingest Nmap XML output (nmap -oX)\n")
+	fmt.Fprintf(os.Stderr, "  masscan [--name NAME] FILE  Ingest masscan JSON output (masscan -oJ or -oD)\n")
+	fmt.Fprintf(os.Stderr, "  pcap [--name NAME] [--rag] FILE\n")
+	fmt.Fprintf(os.Stderr, "                              Summarize a pcap or pcapng capture: flows, DNS and HTTP\n")
+	fmt.Fprintf(os.Stderr, "  list                        Show the ingested scans, latest first\n")
+	fmt.Fprintf(os.Stderr, "  show [NAME]                 Summarize a scan (default: the latest)\n")
+	fmt.Fprintf(os.Stderr, "  remove NAME\n")
+	fmt.Fprintf(os.Stderr, "  captures [show [NAME] | remove NAME]\n")
+	fmt.Fprintf(os.Stderr, "                              List, show or remove the ingested captures\n\n")
+	fmt.Fprintf(os.Stderr, "Scans are stored in %s (or $HACKARE_SCANS_DIR),\n", scans.Dir())
+	fmt.Fprintf(os.Stderr, "captures in %s (or $HACKARE_CAPTURES_DIR).\n", pcap.Dir())
+	fmt.Fprintf(os.Stderr, "Enable the \"scan-data\" default functions to query them in a chat.\n")
+}
+
+func ingestScan(dir, format string, args []string) {
+	scanFlags := flag.NewFlagSet("ingest "+format, flag.ExitOnError)
+	name := scanFlags.String("name", "", "Name to keep the scan under (default: the file name)")
+	out := output.RegisterFlags(scanFlags)
+	scanFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s ingest %s [--name NAME] [--json|--quiet] FILE\n\n", os.Args[0], format)
+		scanFlags.PrintDefaults()
+	}
+	if err := scanFlags.Parse(args); err != nil || scanFlags.NArg() != 1 {
+		scanFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	file := scanFlags.Arg(0)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	scan, err := scans.Parse(format, data)
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(fmt.Errorf("%s: %w", file, err))))
+	}
+	scan.Name = *name
+	if scan.Name == "" {
+		scan.Name = scans.NameFor(file)
+	}
+	scan.File = file
+	scan.Ingested = time.Now()
+	if err := scans.Save(dir, scan); err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+
+	summary := scan.Summary()
+	out.Write(os.Stdout, "scan", summary, func(w io.Writer) {
+		fmt.Fprintf(w, "Ingested %s as %q\n", file, scan.Name)
+		writeScanSummary(w, summary)
+		fmt.Fprintf(w, "\nQuery it in a chat by enabling the \"scan-data\" default functions.\n")
+	})
+}
+
+func ingestList(dir string, args []string) {
+	listFlags := flag.NewFlagSet("ingest list", flag.ExitOnError)
+	out := output.RegisterFlags(listFlags)
+	if err := listFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	list, err := scans.List(dir)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	if list == nil {
+		list = []scans.Summary{}
+	}
+	out.Write(os.Stdout, "scans", list, func(w io.Writer) {
+		if len(list) == 0 {
+			fmt.Fprintf(w, "No scans ingested. Use '%s ingest nmap FILE' or 'ingest masscan FILE'.\n", os.Args[0])
+			return
+		}
+		for _, s := range list {
+			fmt.Fprintf(w, "%-24s %-8s %5d hosts %6d open ports  %s\n", s.Name, s.Source, s.Hosts, s.OpenPorts, s.File)
+		}
+	})
+}
+
+func ingestShow(dir string, args []string) {
+	showFlags := flag.NewFlagSet("ingest show", flag.ExitOnError)
+	out := output.RegisterFlags(showFlags)
+	if err := showFlags.Parse(args); err != nil || showFlags.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s ingest show [--json|--quiet] [NAME]\n", os.Args[0])
+		os.Exit(failure.ExitConfig)
+	}
+
+	scan, err := scans.Load(dir, showFlags.Arg(0))
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	summary := scan.Summary()
+	out.Write(os.Stdout, "scan", summary, func(w io.Writer) {
+		fmt.Fprintf(w, "Scan %s\n", scan.Name)
+		writeScanSummary(w, summary)
+	})
+}
+
+// writeScanSummary prints the counts and most common open ports of a scan
+func writeScanSummary(w io.Writer, summary scans.Summary) {
+	fmt.Fprintf(w, "  Source:     %s\n", summary.Source)
+	if !summary.Started.IsZero() {
+		fmt.Fprintf(w, "  Started:    %s\n", summary.Started.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintf(w, "  Hosts:      %d (%d up)\n", summary.Hosts, summary.HostsUp)
+	fmt.Fprintf(w, "  Open ports: %d\n", summary.OpenPorts)
+	if len(summary.TopPorts) == 0 {
+		return
+	}
+	var top []string
+	for _, p := range summary.TopPorts {
+		port := fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+		if p.Service != "" {
+			port += " " + p.Service
+		}
+		top = append(top, fmt.Sprintf("%s (%d)", port, p.Hosts))
+	}
+	fmt.Fprintf(w, "  Top ports:  %s\n", strings.Join(top, ", "))
+}
+
+func ingestPcap(args []string) {
+	pcapFlags := flag.NewFlagSet("ingest pcap", flag.ExitOnError)
+	name := pcapFlags.String("name", "", "Name to keep the capture under (default: the file name)")
+	rag := pcapFlags.Bool("rag", false, "Also add the summary to the RAG documents, for large captures")
+	out := output.RegisterFlags(pcapFlags)
+	pcapFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s ingest pcap [--name NAME] [--rag] [--json|--quiet] FILE\n\n", os.Args[0])
+		pcapFlags.PrintDefaults()
+	}
+	if err := pcapFlags.Parse(args); err != nil || pcapFlags.NArg() != 1 {
+		pcapFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	file := pcapFlags.Arg(0)
+	f, err := os.Open(file)
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	capture, err := pcap.Summarize(f)
+	f.Close()
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(fmt.Errorf("%s: %w", file, err))))
+	}
+	capture.Name = *name
+	if capture.Name == "" {
+		capture.Name = pcap.NameFor(file)
+	}
+	capture.File = file
+	capture.Ingested = time.Now()
+
+	dir := pcap.Dir()
+	if err := pcap.Save(dir, capture); err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	var document string
+	if *rag {
+		if document, err = addCaptureDocument(dir, capture); err != nil {
+			os.Exit(out.Fail(failure.Config(err)))
+		}
+	}
+
+	out.Write(os.Stdout, "capture", capture.Overview(), func(w io.Writer) {
+		fmt.Fprintf(w, "Ingested %s as %q\n", file, capture.Name)
+		writeCaptureSummary(w, capture)
+		if document != "" {
+			fmt.Fprintf(w, "\nAdded %s to the RAG documents.\n", document)
+		}
+		fmt.Fprintf(w, "\nQuery it in a chat by enabling the \"scan-data\" default functions.\n")
+	})
+}
+
+// addCaptureDocument writes the capture's summary as a document and adds it
+// to the RAG documents of the configuration
+func addCaptureDocument(dir string, capture *pcap.Capture) (string, error) {
+	path, err := pcap.SaveDocument(dir, capture)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := config.LoadFromFile(config.GetConfigPath())
+	if err != nil {
+		return "", err
+	}
+	for _, doc := range cfg.RAGDocuments {
+		if doc == path {
+			return path, nil // Already listed; the file was just rewritten
+		}
+	}
+	cfg.RAGEnabled = true
+	cfg.RAGDocuments = append(cfg.RAGDocuments, path)
+	return path, cfg.SaveToFile(config.GetConfigPath())
+}
+
+// removeCaptureDocument drops a removed capture's document from the RAG
+// documents of the configuration
+func removeCaptureDocument(path string) error {
+	cfg, err := config.LoadFromFile(config.GetConfigPath())
+	if err != nil {
+		return err
+	}
+	kept := cfg.RAGDocuments[:0]
+	for _, doc := range cfg.RAGDocuments {
+		if doc != path {
+			kept = append(kept, doc)
+		}
+	}
+	if len(kept) == len(cfg.RAGDocuments) {
+		return nil
+	}
+	cfg.RAGDocuments = kept
+	return cfg.SaveToFile(config.GetConfigPath())
+}
+
+func ingestCaptures(args []string) {
+	dir := pcap.Dir()
+	if len(args) > 0 && (args[0] == "show" || args[0] == "remove" || args[0] == "rm") {
+		command, args := args[0], args[1:]
+		commandFlags := flag.NewFlagSet("ingest captures "+command, flag.ExitOnError)
+		out := output.RegisterFlags(commandFlags)
+		if err := commandFlags.Parse(args); err != nil || commandFlags.NArg() > 1 || (command != "show" && commandFlags.NArg() != 1) {
+			fmt.Fprintf(os.Stderr, "Usage: %s ingest captures show [--json|--quiet] [NAME]\n", os.Args[0])
+			fmt.Fprintf(os.Stderr, "       %s ingest captures remove NAME\n", os.Args[0])
+			os.Exit(failure.ExitConfig)
+		}
+
+		name := commandFlags.Arg(0)
+		if command != "show" {
+			if err := pcap.Remove(dir, name); err != nil {
+				os.Exit(out.Fail(failure.Config(err)))
+			}
+			if err := removeCaptureDocument(pcap.DocumentPath(dir, name)); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			out.Infof("Removed capture %s", name)
+			return
+		}
+		capture, err := pcap.Load(dir, name)
+		if err != nil {
+			os.Exit(out.Fail(failure.Config(err)))
+		}
+		out.Write(os.Stdout, "capture", capture, func(w io.Writer) {
+			fmt.Fprintf(w, "Capture %s\n", capture.Name)
+			writeCaptureSummary(w, capture)
+		})
+		return
+	}
+
+	listFlags := flag.NewFlagSet("ingest captures", flag.ExitOnError)
+	out := output.RegisterFlags(listFlags)
+	if err := listFlags.Parse(args); err != nil || listFlags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s ingest captures [--json|--quiet]\n", os.Args[0])
+		os.Exit(failure.ExitConfig)
+	}
+	list, err := pcap.List(dir)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	if list == nil {
+		list = []pcap.Overview{}
+	}
+	out.Write(os.Stdout, "captures", list, func(w io.Writer) {
+		if len(list) == 0 {
+			fmt.Fprintf(w, "No captures ingested. Use '%s ingest pcap FILE'.\n", os.Args[0])
+			return
+		}
+		for _, c := range list {
+			fmt.Fprintf(w, "%-24s %8d packets %6d flows %5d HTTP  %s\n", c.Name, c.Packets, c.Flows, c.HTTP, c.File)
+		}
+	})
+}
+
+// writeCaptureSummary prints the counts, busiest hosts and lookups of a capture
+func writeCaptureSummary(w io.Writer, c *pcap.Capture) {
+	fmt.Fprintf(w, "  Format:     %s", c.Format)
+	if c.Truncated {
+		fmt.Fprintf(w, " (truncated)")
+	}
+	fmt.Fprintln(w)
+	if !c.Start.IsZero() {
+		fmt.Fprintf(w, "  Time:       %s to %s\n", c.Start.Local().Format("2006-01-02 15:04:05"), c.End.Local().Format("15:04:05"))
+	}
+	fmt.Fprintf(w, "  Packets:    %d (%d bytes)\n", c.Packets, c.Bytes)
+	fmt.Fprintf(w, "  Flows:      %d\n", c.FlowCount)
+	fmt.Fprintf(w, "  DNS:        %d queries for %d names\n", c.DNSQueries, len(c.DNS))
+	fmt.Fprintf(w, "  HTTP:       %d requests\n", c.HTTPCount)
+	var hosts []string
+	for _, h := range c.Hosts[:min(len(c.Hosts), 5)] {
+		hosts = append(hosts, fmt.Sprintf("%s (%d packets)", h.Address, h.Packets))
+	}
+	if len(hosts) > 0 {
+		fmt.Fprintf(w, "  Top hosts:  %s\n", strings.Join(hosts, ", "))
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/classroom"
+	"github.com/hacka-re/cli/internal/failure"
+)
+
+// JoinCommand fetches the configuration an instructor publishes with
+// 'serve --classroom' and loads it like a share link
+func JoinCommand(args []string) {
+	joinFlags := flag.NewFlagSet("join", flag.ExitOnError)
+	timeout := joinFlags.Duration("timeout", 10*time.Second, "How long to look for the instructor")
+	kiosk := joinFlags.Bool("kiosk", false, "Open the configuration read-only without saving it")
+	help := joinFlags.Bool("help", false, "Show help message")
+	helpShort := joinFlags.Bool("h", false, "Show help message (short form)")
+
+	joinFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s join [OPTIONS] CODE[@HOST:PORT]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Load the configuration an instructor publishes with 'serve --classroom'.\n")
+		fmt.Fprintf(os.Stderr, "The instructor is found by broadcasting CODE on the local network; add\n")
+		fmt.Fprintf(os.Stderr, "@HOST:PORT when broadcasts are blocked. You are asked for the passphrase\n")
+		fmt.Fprintf(os.Stderr, "the instructor gives the class, then the configuration is saved.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  --timeout DURATION   How long to look for the instructor (default: 10s)\n")
+		fmt.Fprintf(os.Stderr, "  --kiosk              Open read-only without saving the configuration\n")
+		fmt.Fprintf(os.Stderr, "  -h, --help           Show this help message\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  %s join K7P-4QX\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s join K7P-4QX@192.168.1.20:8080\n", os.Args[0])
+	}
+
+	if err := joinFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+	if *help || *helpShort {
+		joinFlags.Usage()
+		os.Exit(0)
+	}
+	if joinFlags.NArg() != 1 {
+		joinFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	code, hostPort, _ := strings.Cut(joinFlags.Arg(0), "@")
+	if classroom.NormalizeCode(code) == "" {
+		fmt.Fprintf(os.Stderr, "Error: missing classroom code\n")
+		os.Exit(failure.ExitConfig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var linkURL string
+	if hostPort != "" {
+		linkURL = "http://" + hostPort + classroom.PathPrefix + classroom.NormalizeCode(code)
+	} else {
+		fmt.Printf("Looking for classroom %s on the local network...\n", code)
+		var err error
+		linkURL, err = classroom.Discover(ctx, code, fmt.Sprintf("255.255.255.255:%d", classroom.DiscoveryPort))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Check the code, or ask the instructor for HOST:PORT and run '%s join %s@HOST:PORT'\n", os.Args[0], code)
+			os.Exit(failure.ExitNetwork)
+		}
+	}
+
+	link, err := classroom.Fetch(ctx, linkURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error fetching classroom configuration: %v\n", err)
+		os.Exit(failure.ExitNetwork)
+	}
+	fmt.Printf("✓ Found classroom %s\n\n", code)
+
+	kioskMode = *kiosk
+	handleURLArgument(link)
+}
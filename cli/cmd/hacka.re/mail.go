@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/mailgw"
+	"github.com/hacka-re/cli/internal/utils"
+)
+
+// MailGatewayCommand answers emails from allowlisted senders with the configured model
+func MailGatewayCommand(args []string) {
+	mailFlags := flag.NewFlagSet("mail-gateway", flag.ExitOnError)
+	imapAddr := mailFlags.String("imap", "", "IMAP server host:port, implicit TLS unless --imap-plaintext (required)")
+	imapPlaintext := mailFlags.Bool("imap-plaintext", false, "Connect to IMAP without TLS (trusted networks only)")
+	smtpAddr := mailFlags.String("smtp", "", "SMTP server host:port (required)")
+	user := mailFlags.String("user", "", "Mailbox username (required)")
+	from := mailFlags.String("from", "", "Reply address (default: --user)")
+	mailbox := mailFlags.String("mailbox", "INBOX", "Mailbox to poll")
+	allow := mailFlags.String("allow", "", "Comma-separated allowed senders: addresses or @domain (required)")
+	interval := mailFlags.Duration("interval", mailgw.DefaultPollInterval, "Time between mailbox checks")
+	once := mailFlags.Bool("once", false, "Check the mailbox once and exit")
+	session := mailFlags.String("session", "", "Share link to use instead of the saved configuration")
+	mailFlags.Usage = func() { showMailGatewayHelp(); mailFlags.PrintDefaults() }
+	if err := mailFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	mailCfg := mailgw.Config{
+		IMAPAddr:      *imapAddr,
+		IMAPPlaintext: *imapPlaintext,
+		SMTPAddr:      *smtpAddr,
+		Username:      *user,
+		From:          *from,
+		Mailbox:       *mailbox,
+		PollInterval:  *interval,
+	}
+	for _, sender := range strings.Split(*allow, ",") {
+		if sender = strings.TrimSpace(sender); sender != "" {
+			mailCfg.AllowedSenders = append(mailCfg.AllowedSenders, sender)
+		}
+	}
+	if mailCfg.IMAPAddr == "" || mailCfg.SMTPAddr == "" || mailCfg.Username == "" || len(mailCfg.AllowedSenders) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: --imap, --smtp, --user and --allow are required\n\n")
+		mailFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	mailCfg.Password = os.Getenv("HACKARE_MAIL_PASSWORD")
+	if mailCfg.Password == "" {
+		password, err := utils.GetPassword("Enter mailbox password: ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(failure.ExitConfig)
+		}
+		mailCfg.Password = password
+	}
+
+	cfg, err := loadBridgeConfig(*session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", failure.Message(err))
+		os.Exit(failure.ExitCode(err))
+	}
+
+	gateway, err := mailgw.New(mailCfg, api.NewClient(cfg), cfg.SystemPrompt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *once {
+		answered, err := gateway.Poll(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(failure.ExitNetwork)
+		}
+		fmt.Printf("Answered %d message(s)\n", answered)
+		return
+	}
+
+	fmt.Printf("Answering mail to %s from %s with %s every %s. Press Ctrl+C to stop.\n",
+		mailCfg.Username, strings.Join(mailCfg.AllowedSenders, ", "), cfg.Model, mailCfg.PollInterval)
+	gateway.Run(ctx)
+}
+
+func showMailGatewayHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s mail-gateway --imap HOST:PORT --smtp HOST:PORT --user USER --allow SENDERS [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Poll a mailbox and answer each new email from an allowed sender with the\n")
+	fmt.Fprintf(os.Stderr, "configured model. The password is read from $HACKARE_MAIL_PASSWORD or prompted.\n")
+	fmt.Fprintf(os.Stderr, "Senders are checked by From header only; use a mail server that rejects forged mail.\n\n")
+	fmt.Fprintf(os.Stderr, "Examples:\n")
+	fmt.Fprintf(os.Stderr, "  %s mail-gateway --imap mail.lan:993 --smtp mail.lan:587 --user agent@corp.lan --allow @corp.lan\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s mail-gateway --imap 10.0.0.5:143 --imap-plaintext --smtp 10.0.0.5:25 --user agent --from agent@lab.lan --allow ops@lab.lan --once\n\n", os.Args[0])
+}
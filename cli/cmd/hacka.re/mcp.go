@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/mcp"
+	"github.com/hacka-re/cli/internal/mcp/connectors/shodan"
+	"github.com/hacka-re/cli/internal/netguard"
+	"github.com/hacka-re/cli/internal/output"
+)
+
+// mcpProbeTimeout bounds how long status waits for each configured server
+const mcpProbeTimeout = 10 * time.Second
+
+// MCPCommand handles the mcp subcommands
+func MCPCommand(args []string) {
+	if len(args) == 0 {
+		showMCPHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	switch args[0] {
+	case "status":
+		mcpStatus(args[1:])
+	case "help", "-h", "--help":
+		showMCPHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown mcp command: %s\n\n", args[0])
+		showMCPHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showMCPHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s mcp <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  status [--json|--quiet]   Connect to the configured MCP servers and list their tools\n\n")
+	fmt.Fprintf(os.Stderr, "Use '%s shodan mcp' to serve the built-in Shodan connector on stdio.\n", os.Args[0])
+}
+
+// mcpStatus connects to each configured server and reports its tools, next
+// to the built-in Shodan connector
+func mcpStatus(args []string) {
+	statusFlags := flag.NewFlagSet("mcp status", flag.ExitOnError)
+	out := output.RegisterFlags(statusFlags)
+	statusFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s mcp status [--json|--quiet]\n\n", os.Args[0])
+		statusFlags.PrintDefaults()
+	}
+	if err := statusFlags.Parse(args); err != nil || statusFlags.NArg() > 0 {
+		statusFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	cfg, err := config.LoadFromFile(config.GetConfigPath())
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+
+	client := netguard.MCPClient(mcpProbeTimeout)
+	var servers []output.MCPServer
+	for _, configured := range cfg.MCPServers {
+		if !configured.Enabled {
+			continue
+		}
+		server := output.MCPServer{Name: configured.Name, Transport: "http", Tools: []string{}}
+		ctx, cancel := context.WithTimeout(context.Background(), mcpProbeTimeout)
+		tools, err := mcp.Probe(ctx, client, configured.URL)
+		cancel()
+		if err != nil {
+			server.Error = err.Error()
+		} else {
+			server.Connected = true
+			server.Tools = tools
+		}
+		servers = append(servers, server)
+	}
+	servers = append(servers, shodanStatus(cfg))
+
+	out.Write(os.Stdout, "mcp-status", servers, func(w io.Writer) {
+		for _, server := range servers {
+			state := "connected"
+			switch {
+			case server.Error != "":
+				state = "error: " + server.Error
+			case !server.Connected:
+				state = "not connected"
+			}
+			fmt.Fprintf(w, "%-20s %-6s %s\n", server.Name, server.Transport, state)
+			if len(server.Tools) > 0 {
+				fmt.Fprintf(w, "  tools: %s\n", strings.Join(server.Tools, ", "))
+			}
+		}
+	})
+}
+
+// shodanStatus describes the built-in Shodan connector, which is usable
+// once an API key is set
+func shodanStatus(cfg *config.Config) output.MCPServer {
+	server := output.MCPServer{Name: shodan.ServerName, Transport: "stdio", Tools: []string{}}
+	key := cfg.ShodanAPIKey
+	if env := os.Getenv("SHODAN_API_KEY"); env != "" {
+		key = env
+	}
+	if key == "" {
+		server.Error = "no API key (SHODAN_API_KEY or shodanApiKey)"
+		return server
+	}
+	server.Connected = true
+	for _, tool := range shodan.NewTools(key).GetToolDefinitions() {
+		server.Tools = append(server.Tools, tool.Name)
+	}
+	return server
+}
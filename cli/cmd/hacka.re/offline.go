@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/integration"
+	"github.com/hacka-re/cli/internal/netguard"
+	"github.com/hacka-re/cli/internal/offline"
+	"github.com/hacka-re/cli/internal/share"
+)
+
+// OfflineCommand starts offline mode: a local LLM and the TUI, or the web
+// interface in a browser when the first argument names one
+func OfflineCommand(args []string) {
+	// "hacka.re -o BROWSER [OPTIONS]" serves the web interface with llamafile
+	if len(args) > 0 {
+		browserArgs := append([]string{"--offline"}, args[1:]...)
+		switch args[0] {
+		case "browse":
+			BrowseCommand(browserArgs)
+			return
+		case "firefox", "ff":
+			FirefoxCommand(browserArgs)
+			return
+		case "chrome":
+			ChromeCommand(browserArgs)
+			return
+		case "brave":
+			BraveCommand(browserArgs)
+			return
+		case "edge":
+			EdgeCommand(browserArgs)
+			return
+		case "safari":
+			SafariCommand(browserArgs)
+			return
+		}
+	}
+
+	offlineFlags := flag.NewFlagSet("offline", flag.ExitOnError)
+	llamafile := offlineFlags.String("llamafile", "", "Path to llamafile executable")
+	apiProvider := offlineFlags.String("api-provider", "", "Local API provider (ollama, lmstudio, gpt4all, localai) instead of llamafile")
+	apiKey := offlineFlags.String("api-key", "", "API key for the local provider")
+	baseURL := offlineFlags.String("base-url", "", "Base URL of the local provider")
+	model := offlineFlags.String("model", "", "Model name")
+	allowRemoteMCP := offlineFlags.Bool("allow-remote-mcp", false, "Allow remote MCP connections in offline mode")
+	allowRemoteEmbeddings := offlineFlags.Bool("allow-remote-embeddings", false, "Allow remote embeddings API in offline mode")
+	offlineFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s --offline [OPTIONS] [URL|FRAGMENT|DATA]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s --offline browse|firefox|chrome|brave|edge|safari [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Start a local LLM and open hacka.re with it. A shared link keeps its prompts,\n")
+		fmt.Fprintf(os.Stderr, "functions and welcome message, but its model is replaced by the local one.\n\n")
+		offlineFlags.PrintDefaults()
+	}
+	positional := parseInterspersed(offlineFlags, args)
+	if len(positional) > 1 {
+		offlineFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	// A shared link only contributes its prompts, functions and messages
+	var sharedConfig *share.SharedConfig
+	var password string
+	if len(positional) == 1 {
+		var err error
+		_, sharedConfig, password, err = offline.ParseSharedLinkForOffline(positional[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing shared link: %v\n", err)
+			os.Exit(failure.ExitCode(err))
+		}
+	}
+
+	settings := offline.MergeConfigurations(&offline.Configuration{
+		IsOfflineMode:         true,
+		LlamafilePath:         *llamafile,
+		APIProvider:           *apiProvider,
+		APIKey:                *apiKey,
+		BaseURL:               *baseURL,
+		Model:                 *model,
+		AllowRemoteMCP:        *allowRemoteMCP,
+		AllowRemoteEmbeddings: *allowRemoteEmbeddings,
+	}, nil, offline.GetConfigFromEnvironment())
+	if err := offline.ValidateOfflineMode(settings); err != nil {
+		var conflict *offline.ConflictError
+		if errors.As(err, &conflict) {
+			offline.ShowConflictError(settings, conflict)
+		} else {
+			offline.ShowNoProviderGuidance()
+		}
+		os.Exit(failure.ExitConfig)
+	}
+
+	cfg := config.NewConfig()
+	if sharedConfig != nil {
+		cfg.LoadFromSharedConfig(sharedConfig)
+	}
+	cfg.IsOfflineMode = true
+	cfg.AllowRemoteMCP = settings.AllowRemoteMCP
+	cfg.AllowRemoteEmbeddings = settings.AllowRemoteEmbeddings
+	netguard.Init(true)
+	netguard.AllowRemoteMCP(settings.AllowRemoteMCP)
+
+	var llamafileManager *offline.LlamafileManager
+	if settings.APIProvider != "" && settings.APIProvider != string(config.ProviderLlamafile) {
+		// A local runtime that is already running, such as ollama
+		local := offline.OverrideForOfflineMode(settings)
+		cfg.Provider = config.Provider(local.APIProvider)
+		cfg.BaseURL = local.BaseURL
+		cfg.APIKey = local.APIKey
+		if local.Model != "" {
+			cfg.Model = local.Model
+		}
+	} else {
+		if settings.LlamafilePath != "" {
+			os.Setenv("HACKARE_LLAMAFILE", settings.LlamafilePath)
+		}
+		if settings.Model != "" {
+			os.Setenv("HACKARE_MODEL", settings.Model)
+		}
+		fmt.Println("Starting offline mode...")
+		offlineConfig, manager, err := offline.RunOfflineMode(sharedConfig, password)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting offline mode: %v\n", err)
+			os.Exit(failure.ExitCode(err))
+		}
+		llamafileManager = manager
+		offline.PrintOfflineModeInfo(offlineConfig)
+
+		cfg.Provider = config.ProviderLlamafile
+		cfg.BaseURL = manager.BaseURL
+		cfg.APIKey = "no-key"
+		cfg.Model = offlineConfig.ModelName
+	}
+
+	err := integration.LaunchTUI(cfg)
+	if llamafileManager != nil {
+		fmt.Println("Stopping llamafile server...")
+		llamafileManager.Stop()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error launching TUI: %v\n", err)
+		os.Exit(failure.ExitCode(err))
+	}
+}
+
+// OfflineStopCommand stops the llamafile server of a running offline mode session
+func OfflineStopCommand(args []string) {
+	stopFlags := flag.NewFlagSet("offline stop", flag.ExitOnError)
+	stopFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s offline stop\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Stop the llamafile server started by offline mode.\n")
+	}
+	if err := stopFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	if err := offline.StopRunning(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+	fmt.Println("Stopped llamafile server")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/models"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// ModelsCommand lists the known models, optionally for one provider
+func ModelsCommand(args []string) {
+	modelsFlags := flag.NewFlagSet("models", flag.ExitOnError)
+	provider := modelsFlags.String("provider", "", "Only list models from this provider")
+	out := output.RegisterFlags(modelsFlags)
+	modelsFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s models [--provider NAME] [--json|--quiet]\n\n", os.Args[0])
+		modelsFlags.PrintDefaults()
+	}
+	if err := modelsFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	registry := models.NewModelRegistry()
+	metadata := registry.GetAllModels()
+	if *provider != "" {
+		metadata = registry.GetProviderModels(models.ModelProvider(*provider))
+	}
+
+	list := output.Models(metadata)
+	out.Write(os.Stdout, "models", list, func(w io.Writer) {
+		for _, m := range list {
+			fmt.Fprintf(w, "%-12s %-40s %s\n", m.Provider, m.ID, m.Category)
+		}
+	})
+}
+
+// UsageCommand shows today's token usage and cost
+func UsageCommand(args []string) {
+	usageFlags := flag.NewFlagSet("usage", flag.ExitOnError)
+	out := output.RegisterFlags(usageFlags)
+	usageFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s usage [--json|--quiet]\n\n", os.Args[0])
+		usageFlags.PrintDefaults()
+	}
+	if err := usageFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	report := output.UsageFrom(usage.NewTracker(usage.DefaultPath()))
+	out.Write(os.Stdout, "usage", report, func(w io.Writer) {
+		fmt.Fprintf(w, "Usage for %s\n", report.Date)
+		fmt.Fprintf(w, "  Prompt tokens:     %d\n", report.Today.PromptTokens)
+		fmt.Fprintf(w, "  Completion tokens: %d\n", report.Today.CompletionTokens)
+		fmt.Fprintf(w, "  Cost:              $%.4f\n", report.Today.Cost)
+		if report.Today.CachedTokens > 0 {
+			fmt.Fprintf(w, "  Cached tokens:     %d (saved $%.4f)\n", report.Today.CachedTokens, report.Today.CacheSavings)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/output"
+)
+
+// samplingFlags are the --seed and --deterministic options of commands that run from scripts
+type samplingFlags struct {
+	seed          *int
+	deterministic *bool
+}
+
+func registerSamplingFlags(fs *flag.FlagSet) *samplingFlags {
+	return &samplingFlags{
+		seed:          fs.Int("seed", 0, "Sampling seed for providers that support one (0: the configured seed, if any)"),
+		deterministic: fs.Bool("deterministic", false, "Use temperature 0 and a fixed seed for reproducible runs"),
+	}
+}
+
+// apply sets the seed and, with --deterministic, temperature 0 on cfg, and
+// says so when the model ignores part of it
+func (f *samplingFlags) apply(cfg *config.Config, out *output.Options, models ...string) {
+	if *f.seed != 0 {
+		cfg.Seed = *f.seed
+	}
+	if !*f.deterministic {
+		return
+	}
+	cfg.MakeDeterministic()
+	if len(models) == 0 {
+		models = []string{cfg.Model}
+	}
+	compat := api.NewModelCompatibility()
+	for _, model := range models {
+		if !compat.GetModelConfig(model).SupportsCustomTemperature {
+			out.Infof("Note: %s only runs at its default temperature, so its replies may still vary", model)
+		}
+	}
+	out.Infof("Deterministic run: temperature 0, seed %d", cfg.Seed)
+}
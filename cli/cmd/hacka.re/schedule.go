@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/schedule"
+	"github.com/hacka-re/cli/internal/utils"
+)
+
+// envFlags collects repeated --env KEY=VALUE flags
+type envFlags map[string]string
+
+func (e envFlags) String() string {
+	return fmt.Sprint(map[string]string(e))
+}
+
+func (e envFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", value)
+	}
+	e[key] = val
+	return nil
+}
+
+// ScheduleCommand manages scheduled runs of hacka.re commands
+func ScheduleCommand(args []string) {
+	if len(args) == 0 {
+		showScheduleHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	switch args[0] {
+	case "add":
+		scheduleAdd(args[1:])
+	case "list", "ls":
+		scheduleList(args[1:])
+	case "remove", "rm":
+		scheduleRemove(args[1:])
+	case "pause", "resume":
+		schedulePause(args[0] == "pause", args[1:])
+	case "history":
+		scheduleHistory(args[1:])
+	case "run-now":
+		scheduleRunNow(args[1:])
+	case "run":
+		scheduleRun(args[1:])
+	case "help", "-h", "--help":
+		showScheduleHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown schedule command: %s\n\n", args[0])
+		showScheduleHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showScheduleHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s schedule <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Run hacka.re commands on a cron schedule.\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  add NAME --cron EXPR --command CMD   Add a job\n")
+	fmt.Fprintf(os.Stderr, "  list                                 List jobs with their last run\n")
+	fmt.Fprintf(os.Stderr, "  remove NAME                          Remove a job and its history\n")
+	fmt.Fprintf(os.Stderr, "  pause NAME / resume NAME             Stop or restart a job's schedule\n")
+	fmt.Fprintf(os.Stderr, "  history NAME                         Show recent runs of a job\n")
+	fmt.Fprintf(os.Stderr, "  run-now NAME                         Run a job once, now\n")
+	fmt.Fprintf(os.Stderr, "  run                                  Run the scheduler in the foreground\n\n")
+	fmt.Fprintf(os.Stderr, "Jobs only run while '%s schedule run' is running, e.g. as a systemd\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "user service. Failed runs raise a desktop notification.\n\n")
+	fmt.Fprintf(os.Stderr, "Examples:\n")
+	fmt.Fprintf(os.Stderr, "  %s schedule add daily-usage --cron \"0 8 * * *\" --command \"usage --json\"\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s schedule add recon --cron @hourly --session \"$LINK\" --command \"shodan host 1.2.3.4\"\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s schedule history recon\n", os.Args[0])
+}
+
+func scheduleAdd(args []string) {
+	addFlags := flag.NewFlagSet("schedule add", flag.ExitOnError)
+	cronExpr := addFlags.String("cron", "", "Cron expression (minute hour day month weekday) or @hourly/@daily/@weekly")
+	command := addFlags.String("command", "", "hacka.re command to run, e.g. \"usage --json\"")
+	session := addFlags.String("session", "", "Share link to use instead of the saved configuration")
+	timeout := addFlags.Duration("timeout", schedule.DefaultJobTimeout, "Stop a run that takes longer than this")
+	notifySuccess := addFlags.Bool("notify-success", false, "Also notify when a run succeeds")
+	env := envFlags{}
+	addFlags.Var(env, "env", "Set an environment variable for the job (KEY=VALUE, repeatable)")
+	addFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s schedule add [options] NAME\n\n", os.Args[0])
+		addFlags.PrintDefaults()
+	}
+
+	// Allow the name before or after the flags
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if err := addFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+	if name == "" && addFlags.NArg() == 1 {
+		name = addFlags.Arg(0)
+	}
+	if name == "" || *cronExpr == "" || *command == "" {
+		addFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	commandArgs, err := schedule.SplitCommand(*command)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+
+	job := schedule.Job{
+		Name:            name,
+		Cron:            *cronExpr,
+		Command:         commandArgs,
+		Session:         *session,
+		Env:             env,
+		Timeout:         int(timeout.Seconds()),
+		NotifyOnSuccess: *notifySuccess,
+	}
+	if len(env) == 0 {
+		job.Env = nil
+	}
+
+	if err := schedule.NewStore(schedule.DefaultPath()).Add(job); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+
+	cron, _ := schedule.ParseCron(job.Cron)
+	fmt.Printf("Added job %s, next run %s\n", name, cron.Next(time.Now()).Format("2006-01-02 15:04"))
+	fmt.Printf("Jobs run while '%s schedule run' is running.\n", os.Args[0])
+}
+
+// scheduledJob is the JSON form of a job in `schedule list`
+type scheduledJob struct {
+	Name     string        `json:"name"`
+	Cron     string        `json:"cron"`
+	Command  []string      `json:"command"`
+	Disabled bool          `json:"disabled"`
+	NextRun  *time.Time    `json:"nextRun,omitempty"`
+	LastRun  *schedule.Run `json:"lastRun,omitempty"`
+}
+
+func scheduleList(args []string) {
+	listFlags := flag.NewFlagSet("schedule list", flag.ExitOnError)
+	out := output.RegisterFlags(listFlags)
+	if err := listFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	jobs, err := schedule.NewStore(schedule.DefaultPath()).Jobs()
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+
+	list := make([]scheduledJob, 0, len(jobs))
+	for _, job := range jobs {
+		entry := scheduledJob{Name: job.Name, Cron: job.Cron, Command: job.Command, Disabled: job.Disabled}
+		if cron, err := schedule.ParseCron(job.Cron); err == nil && !job.Disabled {
+			next := cron.Next(time.Now())
+			entry.NextRun = &next
+		}
+		if last, ok := job.LastRun(); ok {
+			entry.LastRun = &last
+		}
+		list = append(list, entry)
+	}
+
+	out.Write(os.Stdout, "schedule", list, func(w io.Writer) {
+		if len(list) == 0 {
+			fmt.Fprintln(w, "No scheduled jobs.")
+			return
+		}
+		for _, job := range list {
+			next := "paused"
+			if job.NextRun != nil {
+				next = job.NextRun.Format("2006-01-02 15:04")
+			}
+			last := "never run"
+			if job.LastRun != nil {
+				last = fmt.Sprintf("last %s exit %d", job.LastRun.StartedAt.Format("2006-01-02 15:04"), job.LastRun.ExitCode)
+			}
+			fmt.Fprintf(w, "%-20s %-15s next %-16s %-32s %s\n", job.Name, job.Cron, next, last, strings.Join(job.Command, " "))
+		}
+	})
+}
+
+func scheduleRemove(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s schedule remove NAME\n", os.Args[0])
+		os.Exit(failure.ExitConfig)
+	}
+	if err := schedule.NewStore(schedule.DefaultPath()).Remove(args[0]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+	fmt.Printf("Removed job %s\n", args[0])
+}
+
+func schedulePause(pause bool, args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s schedule pause|resume NAME\n", os.Args[0])
+		os.Exit(failure.ExitConfig)
+	}
+	if err := schedule.NewStore(schedule.DefaultPath()).SetDisabled(args[0], pause); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+	if pause {
+		fmt.Printf("Paused job %s\n", args[0])
+	} else {
+		fmt.Printf("Resumed job %s\n", args[0])
+	}
+}
+
+func scheduleHistory(args []string) {
+	historyFlags := flag.NewFlagSet("schedule history", flag.ExitOnError)
+	showOutput := historyFlags.Bool("output", false, "Show the captured output of each run")
+	out := output.RegisterFlags(historyFlags)
+	historyFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s schedule history [--output] [--json] NAME\n\n", os.Args[0])
+		historyFlags.PrintDefaults()
+	}
+	if err := historyFlags.Parse(args); err != nil || historyFlags.NArg() != 1 {
+		historyFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	job, err := schedule.NewStore(schedule.DefaultPath()).Get(historyFlags.Arg(0))
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+
+	runs := job.Runs
+	if runs == nil {
+		runs = []schedule.Run{}
+	}
+	out.Write(os.Stdout, "scheduleHistory", runs, func(w io.Writer) {
+		if len(runs) == 0 {
+			fmt.Fprintf(w, "Job %s has not run yet.\n", job.Name)
+			return
+		}
+		for _, run := range runs {
+			status := "ok"
+			if !run.Succeeded() {
+				status = fmt.Sprintf("FAILED (exit %d)", run.ExitCode)
+				if run.Error != "" {
+					status = "FAILED: " + run.Error
+				}
+			}
+			fmt.Fprintf(w, "%s  %6.1fs  %s\n", run.StartedAt.Format("2006-01-02 15:04:05"), run.Duration, status)
+			if *showOutput && run.Output != "" {
+				for _, line := range strings.Split(strings.TrimRight(run.Output, "\n"), "\n") {
+					fmt.Fprintf(w, "    %s\n", line)
+				}
+			}
+		}
+	})
+}
+
+func scheduleRunNow(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s schedule run-now NAME\n", os.Args[0])
+		os.Exit(failure.ExitConfig)
+	}
+
+	store := schedule.NewStore(schedule.DefaultPath())
+	job, err := store.Get(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+
+	run := schedule.NewRunner(store).RunJob(context.Background(), job)
+	fmt.Print(run.Output)
+	if !run.Succeeded() {
+		if run.Error != "" {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", run.Error)
+		}
+		os.Exit(failure.ExitError)
+	}
+}
+
+func scheduleRun(args []string) {
+	runFlags := flag.NewFlagSet("schedule run", flag.ExitOnError)
+	noNotify := runFlags.Bool("no-notify", false, "Don't send desktop notifications (failures are still logged)")
+	if err := runFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	runner := schedule.NewRunner(schedule.NewStore(schedule.DefaultPath()))
+	if !*noNotify {
+		runner.Notify = func(title, message string) {
+			if err := utils.SendDesktopNotification(title, message); err != nil {
+				if log := logger.Get(); log != nil {
+					log.Warn("Failed to send notification: %v", err)
+				}
+			}
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Scheduler running with jobs from %s (Ctrl+C to stop)\n", schedule.DefaultPath())
+	if err := runner.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+}
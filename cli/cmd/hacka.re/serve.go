@@ -0,0 +1,547 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hacka-re/cli/internal/accesslog"
+	"github.com/hacka-re/cli/internal/classroom"
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/csp"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/offline"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/shareqr"
+	"github.com/hacka-re/cli/internal/users"
+	"github.com/hacka-re/cli/internal/utils"
+	"github.com/hacka-re/cli/internal/web"
+)
+
+// ServeCommand handles the serve subcommand
+func ServeCommand(args []string) {
+	// Check if first arg is a sub-subcommand
+	if len(args) > 0 && args[0] == "api" {
+		fmt.Println("API server: To be implemented")
+		return
+	}
+	
+	// If first arg is "web", consume it and continue
+	if len(args) > 0 && args[0] == "web" {
+		args = args[1:]
+	}
+	// Otherwise, default to web server (no args or other args)
+	
+	// Create a new flagset for the serve command
+	serveFlags := flag.NewFlagSet("serve", flag.ExitOnError)
+	
+	// Define flags (same as browse but no --no-browser flag)
+	port := serveFlags.Int("port", 0, "Port to serve on (default: 8080 or HACKARE_WEB_PORT)")
+	portShort := serveFlags.Int("p", 0, "Port to serve on (short form)")
+	host := serveFlags.String("host", "localhost", "Host to bind to")
+	unixSocket := serveFlags.String("unix-socket", "", "Listen on a unix socket instead of host:port")
+	verbose := serveFlags.Bool("verbose", false, "Verbose mode - log each request")
+	verboseShort := serveFlags.Bool("v", false, "Verbose mode - log each request (short form)")
+	veryVerbose := serveFlags.Bool("vv", false, "Very verbose mode - log requests with headers")
+	enableMetrics := serveFlags.Bool("metrics", false, "Serve Prometheus metrics at /metrics, without sign-in unless --users is set")
+	noShareQR := serveFlags.Bool("no-share-qr", false, "Disable the /share-qr page of the session link")
+	accessLogPath := serveFlags.String("access-log", "", "Log each request as a JSON line to FILE (- for stdout)")
+	accessLogMaxSize := serveFlags.Int("access-log-max-size", 100, "Rotate the access log after this many MB")
+	accessLogBackups := serveFlags.Int("access-log-backups", 5, "Rotated access logs to keep")
+	rateLimit := serveFlags.Int("rate-limit", 0, "Requests per minute allowed per client (0 = unlimited)")
+	trustProxy := serveFlags.Bool("trust-proxy", false, "Take the client address from X-Forwarded-For")
+	multiUser := serveFlags.Bool("users", false, "Require sign-in with accounts managed by 'hacka.re users'")
+	usersFile := serveFlags.String("users-file", users.DefaultPath(), "Accounts file used with --users")
+	upstream := serveFlags.String("upstream", "", "LLM base URL proxied at /llm for signed-in users (with --users)")
+	classroomMode := serveFlags.Bool("classroom", false, "Publish the session or saved config on the LAN for 'hacka.re join CODE'")
+	offlineMode := serveFlags.Bool("offline", false, "Start in offline mode with local llamafile")
+	offlineModeShort := serveFlags.Bool("o", false, "Start in offline mode (short form)")
+	help := serveFlags.Bool("help", false, "Show help message")
+	helpShort := serveFlags.Bool("h", false, "Show help message (short form)")
+	
+	// Custom usage
+	serveFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s serve [web|api] [OPTIONS] [URL|FRAGMENT|DATA]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Start a server without opening browser\n\n")
+		fmt.Fprintf(os.Stderr, "Subcommands:\n")
+		fmt.Fprintf(os.Stderr, "  web          Serve web interface (default)\n")
+		fmt.Fprintf(os.Stderr, "  api          Serve API endpoint (not yet implemented)\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -p, --port PORT       Port to serve on (default: 8080)\n")
+		fmt.Fprintf(os.Stderr, "  --host HOST           Host to bind to (default: localhost)\n")
+		fmt.Fprintf(os.Stderr, "  --unix-socket PATH    Listen on a unix socket (mode 0660) for a reverse proxy\n")
+		fmt.Fprintf(os.Stderr, "  -o, --offline         Start in offline mode with local llamafile\n")
+		fmt.Fprintf(os.Stderr, "  -v, --verbose         Log each request (method, path, time)\n")
+		fmt.Fprintf(os.Stderr, "  -vv                   Very verbose - log requests with headers\n")
+		fmt.Fprintf(os.Stderr, "  --metrics             Serve Prometheus metrics at /metrics (open to anyone who\n")
+		fmt.Fprintf(os.Stderr, "                        can reach the server unless --users is set)\n")
+		fmt.Fprintf(os.Stderr, "  --no-share-qr         Disable the /share-qr page that shows the session link\n")
+		fmt.Fprintf(os.Stderr, "                        as a QR code for phones (needs the printed token)\n")
+		fmt.Fprintf(os.Stderr, "  --access-log FILE     Log requests as JSON lines (- for stdout)\n")
+		fmt.Fprintf(os.Stderr, "  --access-log-max-size MB  Rotate the access log at this size (default: 100)\n")
+		fmt.Fprintf(os.Stderr, "  --access-log-backups N    Rotated logs to keep as FILE.1 ... FILE.N (default: 5)\n")
+		fmt.Fprintf(os.Stderr, "  --rate-limit N        Allow N requests per minute per client; 429 beyond\n")
+		fmt.Fprintf(os.Stderr, "  --trust-proxy         Client address from X-Forwarded-For (behind a proxy)\n")
+		fmt.Fprintf(os.Stderr, "  --users               Require sign-in; per-user namespaces and quotas\n")
+		fmt.Fprintf(os.Stderr, "  --users-file FILE     Accounts file (default: ~/.config/hacka.re/users.json)\n")
+		fmt.Fprintf(os.Stderr, "  --upstream URL        LLM base URL shared at /llm with --users\n")
+		fmt.Fprintf(os.Stderr, "                        (default: the llamafile in offline mode)\n")
+		fmt.Fprintf(os.Stderr, "  --classroom           Publish the session (or saved config) to students\n")
+		fmt.Fprintf(os.Stderr, "                        on the LAN; they run 'hacka.re join CODE'\n")
+		fmt.Fprintf(os.Stderr, "  -h, --help            Show this help message\n\n")
+		fmt.Fprintf(os.Stderr, "Arguments:\n")
+		fmt.Fprintf(os.Stderr, "  URL          Full hacka.re URL to load session from\n")
+		fmt.Fprintf(os.Stderr, "  FRAGMENT     Fragment with prefix (gpt=...)\n")
+		fmt.Fprintf(os.Stderr, "  DATA         Just the encrypted data (eyJlbmM...)\n\n")
+		fmt.Fprintf(os.Stderr, "Environment Variables:\n")
+		fmt.Fprintf(os.Stderr, "  HACKARE_WEB_PORT      Default port if not specified via flag\n")
+		fmt.Fprintf(os.Stderr, "  HACKARE_LINK          Session link (synonymous with HACKARE_SESSION/CONFIG)\n")
+		fmt.Fprintf(os.Stderr, "  HACKARE_SESSION       Session link (synonymous with HACKARE_LINK/CONFIG)\n")
+		fmt.Fprintf(os.Stderr, "  HACKARE_CONFIG        Session link (synonymous with HACKARE_LINK/SESSION)\n")
+		fmt.Fprintf(os.Stderr, "  HACKARE_UPSTREAM_KEY  API key sent to --upstream by the /llm proxy\n")
+		fmt.Fprintf(os.Stderr, "  LISTEN_FDS, LISTEN_PID  Set by systemd socket activation; the passed socket\n")
+		fmt.Fprintf(os.Stderr, "                        is used instead of --host, --port and --unix-socket\n\n")
+		fmt.Fprintf(os.Stderr, "Note: HACKARE_LINK, HACKARE_SESSION, and HACKARE_CONFIG are synonymous.\n")
+		fmt.Fprintf(os.Stderr, "      They all accept the same formats as command line arguments.\n")
+		fmt.Fprintf(os.Stderr, "      Only ONE should be set - setting multiple will cause an error.\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  %s serve                               # Serve web on port 8080\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s serve web                           # Explicitly serve web\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s serve -p 3000                       # Serve on port 3000\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s serve \"gpt=eyJlbmM...\"             # Serve with session\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  HACKARE_SESSION=\"gpt=...\" %s serve    # Load session from env\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s serve --users --host 0.0.0.0 -o     # Share a local LLM with a team\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s serve --unix-socket /run/hacka.re/web.sock  # Behind nginx or Caddy\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s serve --classroom                   # Give a class your saved setup\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s serve api                           # (Future) API server\n", os.Args[0])
+	}
+	
+	// Parse flags
+	if err := serveFlags.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	
+	// Show help if requested
+	if *help || *helpShort {
+		serveFlags.Usage()
+		os.Exit(0)
+	}
+
+	// Track if we're coming from offline mode
+	var fromOfflineMode bool
+	var remainingArgs []string
+
+	// Handle offline mode if requested
+	if *offlineMode || *offlineModeShort {
+		fromOfflineMode = true
+
+		// Get remaining args to check for shared link
+		remainingArgs = serveFlags.Args()
+
+		// Process shared link if provided for offline mode
+		var fullSharedConfig *share.SharedConfig
+		var sharedLinkPassword string
+		if len(remainingArgs) > 0 {
+			// Parse the shared link and extract configuration
+			_, fullConfig, password, err := offline.ParseSharedLinkForOffline(remainingArgs[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing shared link: %v\n", err)
+				os.Exit(failure.ExitCode(err))
+			}
+			fullSharedConfig = fullConfig
+			sharedLinkPassword = password
+		}
+
+		// Start offline mode first
+		fmt.Println("Starting offline mode...")
+		var llamafileManager *offline.LlamafileManager
+		offlineConfig, llamafileManager, err := offline.RunOfflineMode(fullSharedConfig, sharedLinkPassword)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting offline mode: %v\n", err)
+			os.Exit(failure.ExitCode(err))
+		}
+		// Ensure llamafile is stopped on exit
+		defer func() {
+			if llamafileManager != nil {
+				fmt.Println("Stopping llamafile server...")
+				llamafileManager.Stop()
+			}
+		}()
+
+		// Print offline mode info
+		offline.PrintOfflineModeInfo(offlineConfig)
+		if *upstream == "" && llamafileManager != nil {
+			*upstream = llamafileManager.BaseURL
+		}
+
+		// Use the offline share URL as the session
+		// This already contains the correct encrypted data with the original password
+		remainingArgs = []string{offlineConfig.ShareURL}
+
+		// Continue with regular serve flow using offline configuration
+		// Note: sessionLink and password will be handled below
+	} else {
+		// Get non-flag arguments (shared link components) for non-offline mode
+		remainingArgs = serveFlags.Args()
+	}
+
+	// Determine port
+	serverPort := 8080
+	if *port != 0 {
+		serverPort = *port
+	} else if *portShort != 0 {
+		serverPort = *portShort
+	} else {
+		// Check environment variable
+		serverPort = web.GetPortFromEnv(8080)
+	}
+	
+	// Validate port
+	if serverPort < 1 || serverPort > 65535 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid port number %d\n", serverPort)
+		os.Exit(failure.ExitConfig)
+	}
+	
+	// Determine verbosity level
+	verbosityLevel := 0
+	if *veryVerbose {
+		verbosityLevel = 2
+	} else if *verbose || *verboseShort {
+		verbosityLevel = 1
+	}
+
+	// Check for session from environment first, then command line
+	var sessionLink string
+	var sessionSource string
+
+	// Check environment variables for session
+	envSession, err := share.GetSessionFromEnvironment()
+	if err != nil {
+		// Multiple session env vars defined - this is an error
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+
+	// Determine session source: command line takes precedence over environment
+	if len(remainingArgs) > 0 {
+		sessionLink = remainingArgs[0]
+		sessionSource = "command line"
+	} else if envSession != "" {
+		sessionLink = envSession
+		envVar, _ := share.GetEnvironmentSessionSource()
+		sessionSource = fmt.Sprintf("environment variable %s", envVar)
+	}
+
+	// Process session if provided
+	var sharedConfigFragment string
+	if sessionLink != "" {
+		// If we're coming from offline mode, the sessionLink is already processed
+		// and contains the correctly encrypted share URL from offline.RunOfflineMode
+		if fromOfflineMode {
+			// The offline mode has already created the proper share URL
+			// We just need to extract the fragment part for the web interface
+			if strings.Contains(sessionLink, "#gpt=") {
+				// Extract fragment from the full URL (https://hacka.re/#gpt=...)
+				parts := strings.Split(sessionLink, "#")
+				if len(parts) > 1 {
+					sharedConfigFragment = parts[1]
+				}
+			} else if strings.HasPrefix(sessionLink, "gpt=") {
+				// It's already a fragment
+				sharedConfigFragment = sessionLink
+			}
+			// Skip the "Session loaded successfully!" message since offline mode already printed its info
+		} else {
+			// Normal processing for non-offline mode
+			fmt.Printf("Processing session from %s...\n", sessionSource)
+
+			// Ask for password
+			password, err := utils.GetPassword("Enter password for session: ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading password: %v\n", err)
+				os.Exit(failure.ExitCode(err))
+			}
+
+			// Parse the URL/fragment
+			sharedConfig, err := share.ParseURL(sessionLink, password)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing session: %s\n", failure.Message(err))
+				os.Exit(failure.ExitCode(err))
+			}
+
+			// Validate the configuration
+			if err := share.ValidateConfig(sharedConfig); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid session configuration: %v\n", err)
+				os.Exit(failure.ExitConfig)
+			}
+
+			// Create a new shareable URL fragment for the web interface
+			sharedConfigFragment, err = createFragmentFromConfigServe(sharedConfig, password)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating fragment: %v\n", err)
+				os.Exit(failure.ExitCode(err))
+			}
+
+			fmt.Println("✓ Session loaded successfully!")
+			fmt.Println()
+		}
+	}
+	
+	// In classroom mode, publish the session, or else the saved config under a
+	// new passphrase, and listen on the LAN rather than just this machine
+	var publisher *classroom.Publisher
+	if *classroomMode {
+		if sharedConfigFragment == "" {
+			sharedConfigFragment, err = createFragmentFromSavedConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(failure.ExitCode(err))
+			}
+		}
+		publisher = classroom.NewPublisher(classroom.NewCode(), sharedConfigFragment)
+		if *host == "localhost" {
+			*host = "0.0.0.0"
+		}
+	}
+
+	// Print banner
+	fmt.Println("╔════════════════════════════════════════════╗")
+	fmt.Println("║        hacka.re: serverless agency         ║")
+	fmt.Println("╠════════════════════════════════════════════╣")
+	fmt.Println("║  Starting web server (no browser)...       ║")
+	fmt.Println("╚════════════════════════════════════════════╝")
+	fmt.Println()
+	
+	// Show verbose mode if enabled
+	if verbosityLevel == 1 {
+		fmt.Println("Verbose mode: Logging requests")
+	} else if verbosityLevel == 2 {
+		fmt.Println("Very verbose mode: Logging requests with headers")
+	}
+	
+	// Create and start ZIP-based server with verbosity
+	server, err := web.NewZipServer(*host, serverPort, verbosityLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating server: %v\n", err)
+		os.Exit(failure.ExitCode(err))
+	}
+	server.SetSecurityHeaders(securityHeaders())
+	if *accessLogPath != "" {
+		var out io.Writer = os.Stdout
+		if *accessLogPath != "-" {
+			logFile, err := accesslog.OpenRotatingFile(*accessLogPath, int64(*accessLogMaxSize)<<20, *accessLogBackups)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(failure.ExitConfig)
+			}
+			defer logFile.Close()
+			out = logFile
+		}
+		server.EnableAccessLog(accesslog.New(out, *trustProxy))
+	}
+	if *rateLimit > 0 {
+		server.EnableRateLimit(accesslog.NewRateLimiter(*rateLimit, *trustProxy))
+	}
+	if *enableMetrics {
+		server.EnableMetrics()
+	}
+	if *multiUser {
+		userStore := users.NewStore(*usersFile)
+		accounts, err := userStore.Users()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(failure.ExitConfig)
+		}
+		if len(accounts) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: no users in %s; add one with '%s users add NAME'\n", *usersFile, os.Args[0])
+			os.Exit(failure.ExitConfig)
+		}
+		usersServer, err := users.NewServer(userStore, *upstream, os.Getenv("HACKARE_UPSTREAM_KEY"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(failure.ExitConfig)
+		}
+		server.EnableUsers(usersServer)
+	}
+	if publisher != nil {
+		server.EnableClassroom(publisher)
+	}
+	var shareQR *shareqr.Page
+	if sharedConfigFragment != "" && !*noShareQR {
+		shareQR = shareqr.New("https://hacka.re/#" + sharedConfigFragment)
+		server.EnableShareQR(shareQR)
+	}
+
+	// A socket passed by systemd wins over --unix-socket, which wins over host:port
+	listener, err := web.SystemdListener()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	if listener != nil {
+		fmt.Println("Using the socket passed by systemd")
+	} else if *unixSocket != "" {
+		if listener, err = web.UnixListener(*unixSocket); err != nil {
+			fmt.Fprintf(os.Stderr, "Error listening on %s: %v\n", *unixSocket, err)
+			os.Exit(failure.ExitConfig)
+		}
+	}
+	if listener != nil {
+		tcpAddr, isTCP := listener.Addr().(*net.TCPAddr)
+		if publisher != nil && !isTCP {
+			fmt.Fprintf(os.Stderr, "Error: classroom mode needs a TCP port that students can reach\n")
+			os.Exit(failure.ExitConfig)
+		}
+		if isTCP {
+			serverPort = tcpAddr.Port
+		}
+		server.UseListener(listener)
+	}
+	
+	// Handle interrupt signal for graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	
+	// Start server in goroutine
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start()
+	}()
+	
+	// Give server a moment to start
+	time.Sleep(100 * time.Millisecond)
+
+	// Answer join broadcasts until shutdown
+	announceCtx, stopAnnouncing := context.WithCancel(context.Background())
+	defer stopAnnouncing()
+	if publisher != nil {
+		lanURL := fmt.Sprintf("http://%s:%d", classroom.LANAddress(), serverPort)
+		go func() {
+			if err := publisher.Announce(announceCtx, fmt.Sprintf(":%d", classroom.DiscoveryPort), lanURL); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: students must join with CODE@%s:%d: %v\n", classroom.LANAddress(), serverPort, err)
+			}
+		}()
+	}
+	
+	// Show the URL (with fragment if applicable)
+	serverURL := server.GetURL()
+	if sharedConfigFragment != "" {
+		fmt.Printf("Web server started at: %s/#%s\n", serverURL, sharedConfigFragment)
+	} else {
+		fmt.Printf("Web server started at: %s\n", serverURL)
+	}
+	fmt.Println("Open this URL in your browser to access hacka.re")
+	if *enableMetrics {
+		fmt.Printf("Prometheus metrics at: %s/metrics\n", serverURL)
+	}
+	if shareQR != nil {
+		fmt.Printf("Share link QR code for phones: %s\n", shareQR.URL(serverURL))
+	}
+	if *accessLogPath != "" && *accessLogPath != "-" {
+		fmt.Printf("Access log: %s\n", *accessLogPath)
+	}
+	if *rateLimit > 0 {
+		fmt.Printf("Rate limit: %d requests per minute per client\n", *rateLimit)
+	}
+	if *multiUser {
+		fmt.Printf("Sign-in required for users in %s\n", *usersFile)
+		if *upstream != "" {
+			fmt.Printf("LLM proxy for signed-in users: %s%s (base URL)\n", serverURL, strings.TrimSuffix(users.ProxyPrefix, "/"))
+		}
+	}
+	if publisher != nil {
+		fmt.Println()
+		fmt.Printf("Classroom code: %s\n", publisher.Code())
+		fmt.Printf("Students run:   %s join %s\n", os.Args[0], publisher.Code())
+		fmt.Printf("Or, if broadcasts are blocked: %s join %s@%s:%d\n", os.Args[0], publisher.Code(), classroom.LANAddress(), serverPort)
+		fmt.Println("Tell them the passphrase out loud; it is never sent over the network")
+	}
+	
+	// Wait for interrupt or server error
+	select {
+	case <-sigChan:
+		fmt.Println("\nShutting down server...")
+		if err := server.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error stopping server: %v\n", err)
+		}
+		fmt.Println("Server stopped.")
+	case err := <-serverErr:
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+			os.Exit(failure.ExitCode(err))
+		}
+	}
+}
+
+// securityHeaders returns the securityHeaders section of the saved
+// configuration, or the strict defaults when there is none
+func securityHeaders() csp.Options {
+	cfg, err := config.LoadFromFile(config.GetConfigPath())
+	if err != nil || cfg.SecurityHeaders == nil {
+		return csp.Options{}
+	}
+	return *cfg.SecurityHeaders
+}
+
+// createFragmentFromSavedConfig encrypts the saved configuration under a new
+// passphrase for classroom mode
+func createFragmentFromSavedConfig() (string, error) {
+	cfg, err := config.LoadFromFile(config.GetConfigPath())
+	if err != nil {
+		return "", fmt.Errorf("classroom mode needs a session link or a saved configuration: %w", err)
+	}
+
+	fmt.Println("Publishing your saved configuration to the classroom")
+	password, err := utils.GetPassword("Choose a passphrase for students: ")
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", fmt.Errorf("the passphrase cannot be empty")
+	}
+	confirm, err := utils.GetPassword("Repeat the passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != password {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+
+	// Every student gets the link, so the API key only goes in if confirmed
+	sharedConfig := cfg.ToSharedConfig()
+	if _, err := share.ConfirmContents(sharedConfig, os.Stdin, os.Stdout); err != nil {
+		return "", err
+	}
+	return createFragmentFromConfigServe(sharedConfig, password)
+}
+
+// createFragmentFromConfigServe creates a URL fragment from a shared configuration
+func createFragmentFromConfigServe(sharedConfig *share.SharedConfig, password string) (string, error) {
+	// Convert shared config to JSON
+	configJSON, err := json.Marshal(sharedConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	
+	// Encrypt the configuration
+	encryptedData, err := share.EncryptConfig(configJSON, password)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt config: %w", err)
+	}
+	
+	// Create the fragment (just the gpt=... part, not the full URL)
+	fragment := "gpt=" + url.QueryEscape(encryptedData)
+	
+	return fragment, nil
+}
+
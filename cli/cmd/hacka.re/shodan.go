@@ -0,0 +1,193 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/mcp/connectors/shodan"
+	"github.com/hacka-re/cli/internal/output"
+)
+
+// ShodanCommand handles the shodan subcommands
+func ShodanCommand(args []string) {
+	if len(args) == 0 {
+		showShodanHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	switch args[0] {
+	case "host":
+		shodanHost(args[1:])
+	case "search":
+		shodanSearch(args[1:])
+	case "dns":
+		shodanDNS(args[1:])
+	case "myip":
+		shodanMyIP(args[1:])
+	case "account":
+		shodanAccount(args[1:])
+	case "mcp":
+		shodanMCP(args[1:])
+	case "help", "-h", "--help":
+		showShodanHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown shodan command: %s\n\n", args[0])
+		showShodanHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showShodanHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s shodan <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  host [--history] IP       Services, vulnerabilities and location of an IP address\n")
+	fmt.Fprintf(os.Stderr, "  search [--facets F] [--page N] QUERY\n")
+	fmt.Fprintf(os.Stderr, "                            Search Shodan, e.g. \"apache country:SE\"\n")
+	fmt.Fprintf(os.Stderr, "  dns DOMAIN                Subdomains and DNS records of a domain\n")
+	fmt.Fprintf(os.Stderr, "  myip                      Your external IP address\n")
+	fmt.Fprintf(os.Stderr, "  account                   Your Shodan plan and query credits\n")
+	fmt.Fprintf(os.Stderr, "  mcp                       Serve the Shodan tools as an MCP server on stdio\n\n")
+	fmt.Fprintf(os.Stderr, "Every command takes --api-key KEY and --json|--quiet. The key defaults to\n")
+	fmt.Fprintf(os.Stderr, "SHODAN_API_KEY, then shodanApiKey in the configuration file.\n")
+}
+
+// shodanAPIKey returns key if set, else SHODAN_API_KEY, else the configured key
+func shodanAPIKey(key string) string {
+	if key != "" {
+		return key
+	}
+	if key := os.Getenv("SHODAN_API_KEY"); key != "" {
+		return key
+	}
+	if cfg, err := config.LoadFromFile(config.GetConfigPath()); err == nil {
+		return cfg.ShodanAPIKey
+	}
+	return ""
+}
+
+// shodanFlags parses the flags shared by the shodan commands and returns the
+// client, the output options and the positional arguments
+func shodanFlags(fs *flag.FlagSet, usage string, args []string, nargs int) (*shodan.Client, *output.Options, []string) {
+	apiKey := fs.String("api-key", "", "Shodan API key")
+	out := output.RegisterFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s\n\n", os.Args[0], usage)
+		fs.PrintDefaults()
+	}
+	rest := parseInterspersed(fs, args)
+	if nargs >= 0 && len(rest) != nargs {
+		fs.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	key := shodanAPIKey(*apiKey)
+	if key == "" {
+		os.Exit(out.Fail(failure.Config(fmt.Errorf("no Shodan API key; use --api-key, SHODAN_API_KEY or shodanApiKey in %s", config.GetConfigPath()))))
+	}
+	return shodan.NewClient(key), out, rest
+}
+
+func shodanHost(args []string) {
+	fs := flag.NewFlagSet("shodan host", flag.ExitOnError)
+	history := fs.Bool("history", false, "Include historical banners")
+	client, out, rest := shodanFlags(fs, "shodan host [--history] [--api-key KEY] [--json|--quiet] IP", args, 1)
+
+	info, err := client.GetHostInfo(rest[0], *history, false)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	out.Write(os.Stdout, "shodan-host", info, func(w io.Writer) {
+		fmt.Fprint(w, shodan.FormatHostInfo(info))
+	})
+}
+
+func shodanSearch(args []string) {
+	fs := flag.NewFlagSet("shodan search", flag.ExitOnError)
+	facets := fs.String("facets", "", "Comma-separated facets, e.g. \"country,port,org\"")
+	page := fs.Int("page", 1, "Result page (1-indexed)")
+	client, out, rest := shodanFlags(fs, "shodan search [--facets F] [--page N] [--api-key KEY] [--json|--quiet] QUERY...", args, -1)
+	if len(rest) == 0 {
+		fs.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	results, err := client.Search(strings.Join(rest, " "), *facets, *page, false)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	out.Write(os.Stdout, "shodan-search", results, func(w io.Writer) {
+		fmt.Fprint(w, shodan.FormatSearchResults(results))
+	})
+}
+
+func shodanDNS(args []string) {
+	fs := flag.NewFlagSet("shodan dns", flag.ExitOnError)
+	client, out, rest := shodanFlags(fs, "shodan dns [--api-key KEY] [--json|--quiet] DOMAIN", args, 1)
+
+	domain, err := client.GetDomainInfo(rest[0], false, "", 1)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	out.Write(os.Stdout, "shodan-dns", domain, func(w io.Writer) {
+		fmt.Fprint(w, shodan.FormatDomainInfo(domain))
+	})
+}
+
+func shodanMyIP(args []string) {
+	fs := flag.NewFlagSet("shodan myip", flag.ExitOnError)
+	client, out, _ := shodanFlags(fs, "shodan myip [--api-key KEY] [--json|--quiet]", args, 0)
+
+	ip, err := client.GetMyIP()
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	out.Write(os.Stdout, "shodan-myip", map[string]string{"ip": ip}, func(w io.Writer) {
+		fmt.Fprintln(w, ip)
+	})
+}
+
+func shodanAccount(args []string) {
+	fs := flag.NewFlagSet("shodan account", flag.ExitOnError)
+	client, out, _ := shodanFlags(fs, "shodan account [--api-key KEY] [--json|--quiet]", args, 0)
+
+	profile, err := client.GetAccountProfile()
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	out.Write(os.Stdout, "shodan-account", profile, func(w io.Writer) {
+		fmt.Fprintf(w, "Name:    %s\n", profile.DisplayName)
+		fmt.Fprintf(w, "Member:  %v\n", profile.Member)
+		fmt.Fprintf(w, "Credits: %d\n", profile.Credits)
+		fmt.Fprintf(w, "Created: %s\n", profile.Created)
+	})
+}
+
+// shodanMCP serves the Shodan tools over stdio, for MCP clients that start
+// hacka.re as a subprocess
+func shodanMCP(args []string) {
+	fs := flag.NewFlagSet("shodan mcp", flag.ExitOnError)
+	apiKey := fs.String("api-key", "", "Shodan API key")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s shodan mcp [--api-key KEY]\n\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	server, err := shodan.NewServer(shodanAPIKey(*apiKey))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	if err := server.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/snapshot"
+	"github.com/hacka-re/cli/internal/utils"
+)
+
+// SnapshotCommand saves a namespace into an encrypted file and restores it
+func SnapshotCommand(args []string) {
+	if len(args) == 0 {
+		showSnapshotHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	switch args[0] {
+	case "create":
+		snapshotCreate(args[1:])
+	case "restore":
+		snapshotRestore(args[1:])
+	case "help", "-h", "--help":
+		showSnapshotHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown snapshot command: %s\n\n", args[0])
+		showSnapshotHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showSnapshotHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s snapshot <command> [options] NAME\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Save a namespace into one encrypted file, for backups or moving to another machine.\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  create [--namespace NS] NAME   Save the config, prompts, functions, open\n")
+	fmt.Fprintf(os.Stderr, "                                 conversation, memory and input history\n")
+	fmt.Fprintf(os.Stderr, "  restore [--force] NAME         Put them back; --force replaces what differs\n\n")
+	fmt.Fprintf(os.Stderr, "NAME is kept in %s as NAME%s, unless it is a path.\n", snapshot.Dir(), snapshot.Extension)
+	fmt.Fprintf(os.Stderr, "The password is read from $HACKARE_SNAPSHOT_PASSWORD or prompted.\n")
+}
+
+// snapshotPassword reads the password from env or the terminal, asking twice
+// for a new snapshot
+func snapshotPassword(env string, confirm bool) (string, error) {
+	if password := os.Getenv(env); password != "" {
+		return password, nil
+	}
+	fmt.Fprint(os.Stderr, "Snapshot password: ")
+	password, err := utils.GetPasswordSilent()
+	if err != nil || !confirm {
+		return password, err
+	}
+	fmt.Fprint(os.Stderr, "Confirm password: ")
+	again, err := utils.GetPasswordSilent()
+	if err != nil {
+		return "", err
+	}
+	if again != password {
+		return "", failure.Usage(errors.New("passwords do not match"))
+	}
+	return password, nil
+}
+
+func snapshotCreate(args []string) {
+	createFlags := flag.NewFlagSet("snapshot create", flag.ExitOnError)
+	namespace := createFlags.String("namespace", "", "Namespace whose memory and history to save (default: the configured one)")
+	out := output.RegisterFlags(createFlags)
+	createFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s snapshot create [options] NAME\n\n", os.Args[0])
+		createFlags.PrintDefaults()
+	}
+	if err := createFlags.Parse(args); err != nil || createFlags.NArg() != 1 {
+		createFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	s, err := snapshot.Create(snapshot.DefaultPaths(), snapshotNamespace(*namespace))
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	password, err := snapshotPassword("HACKARE_SNAPSHOT_PASSWORD", true)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	path := snapshot.File(createFlags.Arg(0))
+	if err := s.Save(path, password); err != nil {
+		os.Exit(out.Fail(err))
+	}
+
+	summary := s.Summary()
+	out.Write(os.Stdout, "snapshot", struct {
+		Path string `json:"path"`
+		snapshot.Summary
+	}{path, summary}, func(w io.Writer) {
+		fmt.Fprintf(w, "Saved namespace %s to %s: %s\n", summary.Namespace, path, summary)
+	})
+}
+
+func snapshotRestore(args []string) {
+	restoreFlags := flag.NewFlagSet("snapshot restore", flag.ExitOnError)
+	force := restoreFlags.Bool("force", false, "Replace files and memory that differ from the snapshot")
+	out := output.RegisterFlags(restoreFlags)
+	restoreFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s snapshot restore [options] NAME\n\n", os.Args[0])
+		restoreFlags.PrintDefaults()
+	}
+	if err := restoreFlags.Parse(args); err != nil || restoreFlags.NArg() != 1 {
+		restoreFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	path := snapshot.File(restoreFlags.Arg(0))
+	if _, err := os.Stat(path); err != nil {
+		os.Exit(out.Fail(failure.Usage(fmt.Errorf("no snapshot at %s", path))))
+	}
+	password, err := snapshotPassword("HACKARE_SNAPSHOT_PASSWORD", false)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	s, err := snapshot.Load(path, password)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+
+	conflicts := restoreSnapshot(out, s, *force)
+	summary := s.Summary()
+	out.Write(os.Stdout, "snapshotRestore", struct {
+		Path     string   `json:"path"`
+		Replaced []string `json:"replaced,omitempty"`
+		snapshot.Summary
+	}{path, conflicts, summary}, func(w io.Writer) {
+		fmt.Fprintf(w, "Restored namespace %s from %s (created %s): %s\n",
+			summary.Namespace, path, summary.Created.Local().Format("2006-01-02 15:04"), summary)
+	})
+}
+
+// snapshotNamespace returns namespace, or the configured one if it is empty
+func snapshotNamespace(namespace string) string {
+	if namespace == "" {
+		if cfg, err := config.LoadFromFile(snapshot.DefaultPaths().Config); err == nil {
+			namespace = cfg.Namespace
+		}
+	}
+	return namespace
+}
+
+// restoreSnapshot restores s into the default paths and returns what it
+// replaced. Without force it exits instead of replacing anything.
+func restoreSnapshot(out *output.Options, s *snapshot.Snapshot, force bool) []string {
+	paths := snapshot.DefaultPaths()
+	conflicts, err := s.Conflicts(paths)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	if len(conflicts) > 0 && !force {
+		for _, conflict := range conflicts {
+			out.Infof("Would replace %s", conflict)
+		}
+		os.Exit(out.Fail(failure.Usage(errors.New("restoring would replace existing data; run again with --force to replace it"))))
+	}
+	if err := s.Restore(paths); err != nil {
+		os.Exit(out.Fail(err))
+	}
+	return conflicts
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/remotesync"
+	"github.com/hacka-re/cli/internal/snapshot"
+)
+
+// SyncCommand pushes a namespace to the user's own storage and pulls it back
+func SyncCommand(args []string) {
+	if len(args) == 0 {
+		showSyncHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	switch args[0] {
+	case "push", "pull":
+		syncRun(args[0], args[1:])
+	case "help", "-h", "--help":
+		showSyncHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown sync command: %s\n\n", args[0])
+		showSyncHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showSyncHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s sync <push|pull> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Keep a namespace the same on several machines through a private git repo,\n")
+	fmt.Fprintf(os.Stderr, "S3 bucket or WebDAV folder. It is encrypted before it is sent, and API keys\n")
+	fmt.Fprintf(os.Stderr, "and other secrets stay on each machine.\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  push [--namespace NS]           Send this machine's namespace\n")
+	fmt.Fprintf(os.Stderr, "  pull [--namespace NS] [--force] Replace it with the one last pushed\n\n")
+	fmt.Fprintf(os.Stderr, "The storage is set in %s (or $HACKARE_SYNC_CONFIG), e.g.\n", remotesync.Path())
+	fmt.Fprintf(os.Stderr, "  {\"backend\": \"git\", \"repo\": \"git@github.com:me/hackare-sync.git\"}\n")
+	fmt.Fprintf(os.Stderr, "  {\"backend\": \"s3\", \"bucket\": \"my-bucket\", \"region\": \"eu-north-1\"}\n")
+	fmt.Fprintf(os.Stderr, "  {\"backend\": \"webdav\", \"url\": \"https://dav.example.com/hacka.re/\", \"username\": \"me\"}\n")
+	fmt.Fprintf(os.Stderr, "The password is read from $HACKARE_SYNC_PASSWORD or prompted.\n")
+}
+
+func syncRun(direction string, args []string) {
+	runFlags := flag.NewFlagSet("sync "+direction, flag.ExitOnError)
+	namespace := runFlags.String("namespace", "", "Namespace to sync (default: the configured one)")
+	force := false
+	if direction == "pull" {
+		runFlags.BoolVar(&force, "force", false, "Replace local files and memory that differ")
+	}
+	out := output.RegisterFlags(runFlags)
+	runFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s sync %s [options]\n\n", os.Args[0], direction)
+		runFlags.PrintDefaults()
+	}
+	if err := runFlags.Parse(args); err != nil || runFlags.NArg() != 0 {
+		runFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	cfg, err := remotesync.Load(remotesync.Path())
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	backend, err := remotesync.Open(cfg)
+	if err != nil {
+		os.Exit(out.Fail(failure.Config(err)))
+	}
+	password, err := snapshotPassword("HACKARE_SYNC_PASSWORD", false)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ns := snapshotNamespace(*namespace)
+	paths := snapshot.DefaultPaths()
+
+	if direction == "push" {
+		s, err := remotesync.Push(ctx, backend, paths, ns, password)
+		if err != nil {
+			os.Exit(out.Fail(err))
+		}
+		summary := s.Summary()
+		out.Write(os.Stdout, "syncPush", struct {
+			Backend string `json:"backend"`
+			snapshot.Summary
+		}{backend.Name(), summary}, func(w io.Writer) {
+			fmt.Fprintf(w, "Pushed namespace %s to %s: %s\n", summary.Namespace, backend.Name(), summary)
+		})
+		return
+	}
+
+	s, err := remotesync.Pull(ctx, backend, paths, ns, password)
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	replaced := restoreSnapshot(out, s, force)
+	summary := s.Summary()
+	out.Write(os.Stdout, "syncPull", struct {
+		Backend  string   `json:"backend"`
+		Replaced []string `json:"replaced,omitempty"`
+		snapshot.Summary
+	}{backend.Name(), replaced, summary}, func(w io.Writer) {
+		fmt.Fprintf(w, "Pulled namespace %s from %s (pushed %s): %s\n",
+			summary.Namespace, backend.Name(), summary.Created.Local().Format("2006-01-02 15:04"), summary)
+	})
+}
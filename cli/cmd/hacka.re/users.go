@@ -0,0 +1,232 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/users"
+	"github.com/hacka-re/cli/internal/utils"
+)
+
+// UsersCommand manages the accounts used by 'serve --users'
+func UsersCommand(args []string) {
+	if len(args) == 0 {
+		showUsersHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	store := users.NewStore(users.DefaultPath())
+	if path := os.Getenv("HACKARE_USERS_FILE"); path != "" {
+		store = users.NewStore(path)
+	}
+
+	switch args[0] {
+	case "add":
+		usersAdd(store, args[1:])
+	case "list", "ls":
+		usersList(store, args[1:])
+	case "remove", "rm":
+		usersUpdate(args[1:], "remove", "Removed", store.Remove)
+	case "passwd":
+		usersPasswd(store, args[1:])
+	case "quota":
+		usersQuota(store, args[1:])
+	case "disable":
+		usersUpdate(args[1:], "disable", "Disabled", func(name string) error { return store.SetDisabled(name, true) })
+	case "enable":
+		usersUpdate(args[1:], "enable", "Enabled", func(name string) error { return store.SetDisabled(name, false) })
+	case "promote":
+		usersUpdate(args[1:], "promote", "Made admin:", func(name string) error { return store.SetAdmin(name, true) })
+	case "demote":
+		usersUpdate(args[1:], "demote", "Removed admin from", func(name string) error { return store.SetAdmin(name, false) })
+	case "reset-usage":
+		usersUpdate(args[1:], "reset-usage", "Reset today's usage of", store.ResetUsage)
+	case "help", "-h", "--help":
+		showUsersHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown users command: %s\n\n", args[0])
+		showUsersHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showUsersHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s users <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "Manage the accounts that can sign in to 'serve --users'.\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  add [--namespace NS] [--admin] [--daily-requests N] [--daily-tokens N] NAME\n")
+	fmt.Fprintf(os.Stderr, "  list                     Show users, quotas and today's usage\n")
+	fmt.Fprintf(os.Stderr, "  remove NAME\n")
+	fmt.Fprintf(os.Stderr, "  passwd NAME              Set a new password\n")
+	fmt.Fprintf(os.Stderr, "  quota [--daily-requests N] [--daily-tokens N] NAME   (0 = unlimited)\n")
+	fmt.Fprintf(os.Stderr, "  disable NAME | enable NAME\n")
+	fmt.Fprintf(os.Stderr, "  promote NAME | demote NAME   Grant or revoke admin\n")
+	fmt.Fprintf(os.Stderr, "  reset-usage NAME         Clear today's usage\n\n")
+	fmt.Fprintf(os.Stderr, "Accounts are stored in %s (or $HACKARE_USERS_FILE).\n", users.DefaultPath())
+	fmt.Fprintf(os.Stderr, "Passwords are read from $HACKARE_USER_PASSWORD or prompted.\n")
+}
+
+// userPassword reads a new password from the environment or the terminal
+func userPassword(name string) string {
+	if password := os.Getenv("HACKARE_USER_PASSWORD"); password != "" {
+		return password
+	}
+	password, err := utils.GetPasswordWithConfirmation(
+		fmt.Sprintf("Password for %s: ", name), "Confirm password: ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	return password
+}
+
+func usersAdd(store *users.Store, args []string) {
+	addFlags := flag.NewFlagSet("users add", flag.ExitOnError)
+	namespace := addFlags.String("namespace", "", "Namespace for the user's data (default: the name)")
+	admin := addFlags.Bool("admin", false, "Allow the user to administer the server")
+	dailyRequests := addFlags.Int("daily-requests", 0, "LLM requests per day (0 = unlimited)")
+	dailyTokens := addFlags.Int("daily-tokens", 0, "LLM tokens per day (0 = unlimited)")
+	addFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s users add [options] NAME\n\n", os.Args[0])
+		addFlags.PrintDefaults()
+	}
+	if err := addFlags.Parse(args); err != nil || addFlags.NArg() != 1 {
+		addFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	user := users.User{
+		Name:      users.NormalizeName(addFlags.Arg(0)),
+		Namespace: *namespace,
+		Admin:     *admin,
+		Quota:     users.Quota{DailyRequests: *dailyRequests, DailyTokens: *dailyTokens},
+	}
+	if err := store.Add(user, userPassword(user.Name)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	fmt.Printf("Added user %s\n", user.Name)
+}
+
+// listedUser is the JSON form of a user in `users list`; the password hash is never printed
+type listedUser struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace"`
+	Admin     bool        `json:"admin"`
+	Disabled  bool        `json:"disabled"`
+	Quota     users.Quota `json:"quota"`
+	Today     users.Usage `json:"today"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+func usersList(store *users.Store, args []string) {
+	listFlags := flag.NewFlagSet("users list", flag.ExitOnError)
+	out := output.RegisterFlags(listFlags)
+	if err := listFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	all, err := store.Users()
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+	list := make([]listedUser, 0, len(all))
+	for _, user := range all {
+		list = append(list, listedUser{user.Name, user.Namespace, user.Admin, user.Disabled, user.Quota, user.Today(), user.CreatedAt})
+	}
+
+	out.Write(os.Stdout, "users", list, func(w io.Writer) {
+		if len(list) == 0 {
+			fmt.Fprintln(w, "No users. Add one with 'users add NAME'.")
+			return
+		}
+		fmt.Fprintf(w, "%-16s %-16s %-18s %-18s %s\n", "NAME", "NAMESPACE", "REQUESTS TODAY", "TOKENS TODAY", "FLAGS")
+		for _, user := range list {
+			flags := ""
+			if user.Admin {
+				flags += "admin "
+			}
+			if user.Disabled {
+				flags += "disabled"
+			}
+			fmt.Fprintf(w, "%-16s %-16s %-18s %-18s %s\n", user.Name, user.Namespace,
+				usageOf(user.Today.Requests, user.Quota.DailyRequests),
+				usageOf(user.Today.Tokens, user.Quota.DailyTokens), flags)
+		}
+	})
+}
+
+// usageOf formats usage against a limit, e.g. "12/100" or "12" when unlimited
+func usageOf(used, limit int) string {
+	if limit <= 0 {
+		return fmt.Sprintf("%d", used)
+	}
+	return fmt.Sprintf("%d/%d", used, limit)
+}
+
+func usersPasswd(store *users.Store, args []string) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s users passwd NAME\n", os.Args[0])
+		os.Exit(failure.ExitConfig)
+	}
+	name := users.NormalizeName(args[0])
+	if _, err := store.Get(name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	if err := store.SetPassword(name, userPassword(name)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	fmt.Printf("Changed password of %s\n", name)
+}
+
+func usersQuota(store *users.Store, args []string) {
+	quotaFlags := flag.NewFlagSet("users quota", flag.ExitOnError)
+	dailyRequests := quotaFlags.Int("daily-requests", 0, "LLM requests per day (0 = unlimited)")
+	dailyTokens := quotaFlags.Int("daily-tokens", 0, "LLM tokens per day (0 = unlimited)")
+	quotaFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s users quota [options] NAME\n\n", os.Args[0])
+		quotaFlags.PrintDefaults()
+	}
+	if err := quotaFlags.Parse(args); err != nil || quotaFlags.NArg() != 1 {
+		quotaFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	name := users.NormalizeName(quotaFlags.Arg(0))
+	quota := users.Quota{DailyRequests: *dailyRequests, DailyTokens: *dailyTokens}
+	if err := store.SetQuota(name, quota); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	fmt.Printf("Set quota of %s to %s requests and %s tokens per day\n", name,
+		limitOf(quota.DailyRequests), limitOf(quota.DailyTokens))
+}
+
+// limitOf formats a quota limit
+func limitOf(limit int) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d", limit)
+}
+
+// usersUpdate applies a single-name change and reports it
+func usersUpdate(args []string, command, done string, apply func(name string) error) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s users %s NAME\n", os.Args[0], command)
+		os.Exit(failure.ExitConfig)
+	}
+	name := users.NormalizeName(args[0])
+	if err := apply(name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	fmt.Printf("%s %s\n", done, name)
+}
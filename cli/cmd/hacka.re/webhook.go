@@ -0,0 +1,185 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/output"
+	"github.com/hacka-re/cli/internal/webhook"
+)
+
+// WebhookCommand manages outbound webhooks
+func WebhookCommand(args []string) {
+	if len(args) == 0 {
+		showWebhookHelp()
+		os.Exit(failure.ExitConfig)
+	}
+
+	switch args[0] {
+	case "add":
+		webhookAdd(args[1:])
+	case "list", "ls":
+		webhookList(args[1:])
+	case "remove", "rm":
+		webhookRemove(args[1:])
+	case "test":
+		webhookTest(args[1:])
+	case "help", "-h", "--help":
+		showWebhookHelp()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown webhook command: %s\n\n", args[0])
+		showWebhookHelp()
+		os.Exit(failure.ExitConfig)
+	}
+}
+
+func showWebhookHelp() {
+	fmt.Fprintf(os.Stderr, "Usage: %s webhook <command> [options]\n\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "POST signed JSON events to URLs while hacka.re runs (chat, serve, schedule run).\n\n")
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	fmt.Fprintf(os.Stderr, "  add [--secret S] [--events LIST] [--include-content] URL\n")
+	fmt.Fprintf(os.Stderr, "  list\n")
+	fmt.Fprintf(os.Stderr, "  remove N      Remove webhook number N from 'list'\n")
+	fmt.Fprintf(os.Stderr, "  test N        Send a test event to webhook N\n\n")
+	fmt.Fprintf(os.Stderr, "Events: %s (or a prefix like \"tool.*\")\n", strings.Join(webhook.EventTypes, ", "))
+}
+
+func webhookAdd(args []string) {
+	addFlags := flag.NewFlagSet("webhook add", flag.ExitOnError)
+	secret := addFlags.String("secret", "", "Shared secret for the X-Hackare-Signature HMAC")
+	events := addFlags.String("events", "", "Comma-separated events to send (default: all)")
+	includeContent := addFlags.Bool("include-content", false, "Include message text and tool arguments in events")
+	addFlags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s webhook add [options] URL\n\n", os.Args[0])
+		addFlags.PrintDefaults()
+	}
+	if err := addFlags.Parse(args); err != nil || addFlags.NArg() != 1 {
+		addFlags.Usage()
+		os.Exit(failure.ExitConfig)
+	}
+
+	hook := webhook.Hook{
+		URL:            addFlags.Arg(0),
+		Secret:         *secret,
+		IncludeContent: *includeContent,
+	}
+	for _, event := range strings.Split(*events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			hook.Events = append(hook.Events, event)
+		}
+	}
+	if err := webhook.Validate(hook); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+
+	path := webhook.DefaultPath()
+	hooks, err := webhook.Load(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	if err := webhook.Save(path, append(hooks, hook)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+
+	fmt.Printf("Added webhook %d: %s\n", len(hooks)+1, hook.URL)
+	if hook.Secret == "" {
+		fmt.Println("Warning: without --secret, receivers cannot verify that events come from you.")
+	}
+}
+
+// listedHook is the JSON form of a hook in `webhook list`; the secret is never printed
+type listedHook struct {
+	Number         int      `json:"number"`
+	URL            string   `json:"url"`
+	Events         []string `json:"events"`
+	Signed         bool     `json:"signed"`
+	IncludeContent bool     `json:"includeContent"`
+}
+
+func webhookList(args []string) {
+	listFlags := flag.NewFlagSet("webhook list", flag.ExitOnError)
+	out := output.RegisterFlags(listFlags)
+	if err := listFlags.Parse(args); err != nil {
+		os.Exit(failure.ExitConfig)
+	}
+
+	hooks, err := webhook.Load(webhook.DefaultPath())
+	if err != nil {
+		os.Exit(out.Fail(err))
+	}
+
+	list := make([]listedHook, 0, len(hooks))
+	for i, hook := range hooks {
+		events := hook.Events
+		if len(events) == 0 {
+			events = []string{"*"}
+		}
+		list = append(list, listedHook{i + 1, hook.URL, events, hook.Secret != "", hook.IncludeContent})
+	}
+
+	out.Write(os.Stdout, "webhooks", list, func(w io.Writer) {
+		if len(list) == 0 {
+			fmt.Fprintln(w, "No webhooks configured.")
+			return
+		}
+		for _, hook := range list {
+			flags := ""
+			if hook.Signed {
+				flags += " signed"
+			}
+			if hook.IncludeContent {
+				flags += " content"
+			}
+			fmt.Fprintf(w, "%2d  %-50s %s%s\n", hook.Number, hook.URL, strings.Join(hook.Events, ","), flags)
+		}
+	})
+}
+
+// webhookArg loads the hooks and resolves a 1-based number from the command line
+func webhookArg(args []string, usage string) ([]webhook.Hook, int) {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s webhook %s N\n", os.Args[0], usage)
+		os.Exit(failure.ExitConfig)
+	}
+	hooks, err := webhook.Load(webhook.DefaultPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitConfig)
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(hooks) {
+		fmt.Fprintf(os.Stderr, "Error: no webhook %s (see '%s webhook list')\n", args[0], os.Args[0])
+		os.Exit(failure.ExitConfig)
+	}
+	return hooks, n - 1
+}
+
+func webhookRemove(args []string) {
+	hooks, i := webhookArg(args, "remove")
+	removed := hooks[i]
+	if err := webhook.Save(webhook.DefaultPath(), append(hooks[:i], hooks[i+1:]...)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitError)
+	}
+	fmt.Printf("Removed webhook %s\n", removed.URL)
+}
+
+func webhookTest(args []string) {
+	hooks, i := webhookArg(args, "test")
+	err := webhook.Send(hooks[i], "webhook.test", map[string]interface{}{
+		"message": "Test event from hacka.re",
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(failure.ExitNetwork)
+	}
+	fmt.Printf("Delivered test event to %s\n", hooks[i].URL)
+}
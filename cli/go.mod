@@ -3,6 +3,7 @@ module github.com/hacka-re/cli
 go 1.23.0
 
 require (
+	github.com/dop251/goja v0.0.0-20250630131328-58d95d85e994
 	github.com/gdamore/tcell/v2 v2.9.0
 	golang.org/x/crypto v0.17.0
 	golang.org/x/term v0.34.0
@@ -10,7 +11,6 @@ require (
 
 require (
 	github.com/dlclark/regexp2 v1.11.4 // indirect
-	github.com/dop251/goja v0.0.0-20250630131328-58d95d85e994 // indirect
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/go-sourcemap/sourcemap v2.1.3+incompatible // indirect
 	github.com/google/pprof v0.0.0-20230207041349-798e818bf904 // indirect
@@ -0,0 +1,195 @@
+// Package accesslog records web server requests as JSON lines, in a file
+// that is rotated by size, and limits how many requests each client makes.
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one logged request
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Client     string    `json:"client"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"` // Without the query string, which may hold secrets
+	Status     int       `json:"status"`
+	Bytes      int64     `json:"bytes"`
+	DurationMs float64   `json:"durationMs"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	Referer    string    `json:"referer,omitempty"`
+}
+
+// Logger writes an Entry per request
+type Logger struct {
+	out        io.Writer
+	trustProxy bool
+
+	mu sync.Mutex
+}
+
+// New logs to out. With trustProxy, the client is taken from X-Forwarded-For.
+func New(out io.Writer, trustProxy bool) *Logger {
+	return &Logger{out: out, trustProxy: trustProxy}
+}
+
+// Wrap logs every request to next
+func (l *Logger) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		l.Log(Entry{
+			Time:       start.UTC(),
+			Client:     ClientIP(r, l.trustProxy),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Status:     recorder.status,
+			Bytes:      recorder.bytes,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+			UserAgent:  r.UserAgent(),
+			Referer:    r.Referer(),
+		})
+	})
+}
+
+// Log writes entry as one JSON line
+func (l *Logger) Log(entry Entry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(append(line, '\n'))
+}
+
+// ClientIP returns the address a request came from. Behind a reverse proxy
+// (trustProxy), that is the last X-Forwarded-For entry, which the proxy
+// added; earlier entries are set by the client and can't be trusted.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			parts := strings.Split(forwarded, ",")
+			if ip := strings.TrimSpace(parts[len(parts)-1]); ip != "" {
+				return ip
+			}
+		}
+		if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// responseRecorder captures the status code and body size
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the status code before writing it
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the bytes written
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController flush streamed responses, such as
+// the /llm proxy's
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// RotatingFile appends to a file and renames it to PATH.1 (shifting older
+// backups to PATH.2 and so on) when it grows past maxSize bytes. Backups
+// beyond maxBackups are deleted.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile opens path for appending
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open access log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if it would take the file past maxSize
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups and starts a new file
+func (f *RotatingFile) rotate() error {
+	f.file.Close()
+	if f.maxBackups < 1 {
+		os.Remove(f.path)
+	} else {
+		os.Remove(fmt.Sprintf("%s.%d", f.path, f.maxBackups))
+		for i := f.maxBackups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+		}
+		os.Rename(f.path, f.path+".1")
+	}
+	return f.open()
+}
+
+// Close closes the file
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
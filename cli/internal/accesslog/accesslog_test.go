@@ -0,0 +1,116 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoggerWritesEntries(t *testing.T) {
+	var out bytes.Buffer
+	handler := New(&out, false).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/thing?token=secret", nil)
+	req.RemoteAddr = "192.0.2.7:51234"
+	req.Header.Set("User-Agent", "curl/8")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry Entry
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("not a JSON line: %q", out.String())
+	}
+	if entry.Client != "192.0.2.7" || entry.Method != "POST" || entry.Path != "/api/thing" ||
+		entry.Status != http.StatusCreated || entry.Bytes != 5 || entry.UserAgent != "curl/8" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if strings.Contains(out.String(), "secret") {
+		t.Error("the query string was logged")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:4000"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 198.51.100.9")
+
+	if got := ClientIP(req, false); got != "10.0.0.1" {
+		t.Errorf("without trustProxy: %q", got)
+	}
+	if got := ClientIP(req, true); got != "198.51.100.9" {
+		t.Errorf("with trustProxy: %q, want the entry the proxy added", got)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	f, err := OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first-1\n", "second\n", "third-\n", "fourth\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.Close()
+
+	read := func(name string) string {
+		data, _ := os.ReadFile(name)
+		return string(data)
+	}
+	if got := read(path); got != "fourth\n" {
+		t.Errorf("current = %q", got)
+	}
+	if got := read(path + ".1"); got != "third-\n" {
+		t.Errorf(".1 = %q", got)
+	}
+	if got := read(path + ".2"); got != "second\n" {
+		t.Errorf(".2 = %q", got)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("kept more backups than maxBackups")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(60, false)
+	limiter.now = func() time.Time { return now }
+
+	// A page load may use the whole minute's allowance at once
+	for i := 0; i < 60; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("request %d refused", i+1)
+		}
+	}
+	ok, wait := limiter.Allow("a")
+	if ok || wait <= 0 || wait > time.Second {
+		t.Errorf("61st request: allowed=%v wait=%v", ok, wait)
+	}
+	if ok, _ := limiter.Allow("b"); !ok {
+		t.Error("another client should have its own allowance")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Error("allowance should refill at one request per second")
+	}
+
+	handler := limiter.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "a:1"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("status = %d, Retry-After = %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
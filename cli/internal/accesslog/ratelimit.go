@@ -0,0 +1,100 @@
+package accesslog
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/metrics"
+)
+
+// idleBucketTTL is how long a client's bucket is kept after it was last used
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimited counts requests refused with 429
+var RateLimited = metrics.NewCounterVec("hackare_http_rate_limited_total",
+	"Web server requests refused by the per-client rate limit")
+
+// RateLimiter allows each client a number of requests per minute. Clients
+// can use the whole minute's allowance at once, since loading the web app
+// fetches many files, and it refills evenly over the minute.
+type RateLimiter struct {
+	perMinute  float64
+	trustProxy bool
+	now        func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket is a client's remaining allowance
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter allows perMinute requests per client. With trustProxy, the
+// client is taken from X-Forwarded-For.
+func NewRateLimiter(perMinute int, trustProxy bool) *RateLimiter {
+	return &RateLimiter{
+		perMinute:  float64(perMinute),
+		trustProxy: trustProxy,
+		now:        time.Now,
+		buckets:    make(map[string]*bucket),
+	}
+}
+
+// Allow takes one request from client's allowance. When it is used up, it
+// returns false and how long until the next request is allowed.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.perMinute, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(l.perMinute, b.tokens+now.Sub(b.last).Minutes()*l.perMinute)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.perMinute * float64(time.Minute))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops buckets of clients that have been idle long enough to be full
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	l.lastSweep = now
+	for client, b := range l.buckets {
+		if now.Sub(b.last) > idleBucketTTL {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// Wrap answers 429 Too Many Requests to clients over their allowance
+func (l *RateLimiter) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, wait := l.Allow(ClientIP(r, l.trustProxy))
+		if !allowed {
+			RateLimited.Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ratings of an annotated message
+const (
+	RatingUp   = "up"
+	RatingDown = "down"
+)
+
+// Annotation is the user's review of an assistant message: a thumbs up or
+// down and a short note, collected for eval datasets built from real usage
+type Annotation struct {
+	Rating string `json:"rating,omitempty"` // RatingUp, RatingDown or empty
+	Note   string `json:"note,omitempty"`
+}
+
+// ParseRating reads a rating as typed by the user: up, down, +, -, 👍 or 👎.
+// An empty string is no rating.
+func ParseRating(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return "", nil
+	case RatingUp, "u", "+", "+1", "good", "👍":
+		return RatingUp, nil
+	case RatingDown, "d", "-", "-1", "bad", "👎":
+		return RatingDown, nil
+	}
+	return "", fmt.Errorf("unknown rating %q (use up or down)", s)
+}
+
+// IsEmpty reports whether the annotation has neither rating nor note
+func (a *Annotation) IsEmpty() bool {
+	return a == nil || (a.Rating == "" && a.Note == "")
+}
+
+// String renders the annotation for people, e.g. "👍 clear and correct"
+func (a *Annotation) String() string {
+	if a.IsEmpty() {
+		return ""
+	}
+	var parts []string
+	switch a.Rating {
+	case RatingUp:
+		parts = append(parts, "👍")
+	case RatingDown:
+		parts = append(parts, "👎")
+	}
+	if a.Note != "" {
+		parts = append(parts, a.Note)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/config"
+)
+
+// StreamOptions asks a streaming endpoint to report usage in its last chunk
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// cacheControlHosts accept Anthropic-style cache_control markers on content parts
+var cacheControlHosts = []string{"anthropic.com", "openrouter.ai"}
+
+// applyPromptCache marks the static start of the prompt for the provider's cache.
+// OpenAI, Groq and DeepSeek cache long prefixes automatically and only need usage
+// reporting; Anthropic and OpenRouter need an explicit marker; llama.cpp servers
+// keep the prompt in their KV cache when asked.
+func (c *Client) applyPromptCache(request *ChatRequest) {
+	if request.Stream && !config.IsLocalProvider(c.config.Provider) {
+		request.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+	if c.config.DisablePromptCache {
+		return
+	}
+
+	if c.config.Provider == config.ProviderLlamafile {
+		request.CachePrompt = true
+	}
+	if supportsCacheControl(c.config.BaseURL) {
+		request.Messages = withCacheControl(request.Messages)
+	}
+}
+
+// supportsCacheControl reports whether the endpoint understands cache_control markers
+func supportsCacheControl(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range cacheControlHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// withCacheControl returns a copy of messages with the last of the leading system
+// messages marked, so the system prompt and RAG context are cached together
+func withCacheControl(messages []Message) []Message {
+	last := -1
+	for i, msg := range messages {
+		if msg.Role != "system" {
+			break
+		}
+		last = i
+	}
+	if last < 0 || messages[last].Content == "" {
+		return messages
+	}
+	marked := append([]Message(nil), messages...)
+	marked[last].cacheControl = true
+	return marked
+}
+
+// withoutCacheControl returns a copy of messages with all markers removed
+func withoutCacheControl(messages []Message) []Message {
+	plain := append([]Message(nil), messages...)
+	for i := range plain {
+		plain[i].cacheControl = false
+	}
+	return plain
+}
+
+// MarshalJSON sends a marked message as a single text part carrying cache_control
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plain Message
+	if !m.cacheControl {
+		return json.Marshal(plain(m))
+	}
+	return json.Marshal(struct {
+		plain
+		Content []contentPart `json:"content"`
+	}{plain(m), []contentPart{{Type: "text", Text: m.Content, CacheControl: &cacheControl{Type: "ephemeral"}}}})
+}
+
+// contentPart is one element of array-form message content
+type contentPart struct {
+	Type         string        `json:"type"`
+	Text         string        `json:"text"`
+	CacheControl *cacheControl `json:"cache_control,omitempty"`
+}
+
+type cacheControl struct {
+	Type string `json:"type"`
+}
+
+// CachedTokens returns the prompt tokens the provider served from its cache
+func (r *ChatResponse) CachedTokens() int {
+	if r == nil {
+		return 0
+	}
+	if r.Usage.PromptTokensDetails.CachedTokens > 0 {
+		return r.Usage.PromptTokensDetails.CachedTokens
+	}
+	return r.Usage.PromptCacheHitTokens
+}
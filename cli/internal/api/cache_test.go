@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/config"
+)
+
+func TestCacheControlMarksLastSystemMessage(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "system", Content: "Context: the RAG excerpts."},
+		{Role: "user", Content: "Hi"},
+	}
+	body, err := json.Marshal(withCacheControl(messages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"role":"system","content":"You are helpful."},` +
+		`{"role":"system","content":[{"type":"text","text":"Context: the RAG excerpts.","cache_control":{"type":"ephemeral"}}]},` +
+		`{"role":"user","content":"Hi"}]`
+	if string(body) != want {
+		t.Errorf("marshalled = %s\nwant %s", body, want)
+	}
+
+	if messages[1].cacheControl {
+		t.Error("withCacheControl modified its input")
+	}
+	if plain, _ := json.Marshal(withoutCacheControl(withCacheControl(messages))); strings.Contains(string(plain), "cache_control") {
+		t.Errorf("withoutCacheControl left a marker: %s", plain)
+	}
+}
+
+func TestSupportsCacheControl(t *testing.T) {
+	for baseURL, want := range map[string]bool{
+		"https://openrouter.ai/api/v1":  true,
+		"https://api.anthropic.com/v1":  true,
+		"https://api.openai.com/v1":     false,
+		"https://openrouter.ai.evil/v1": false,
+	} {
+		if got := supportsCacheControl(baseURL); got != want {
+			t.Errorf("supportsCacheControl(%q) = %v, want %v", baseURL, got, want)
+		}
+	}
+}
+
+func TestPromptCacheRequestAndUsage(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&request)
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"ok"}}],`+
+			`"usage":{"prompt_tokens":1200,"completion_tokens":5,"total_tokens":1205,"prompt_tokens_details":{"cached_tokens":1024}}}`)
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.BaseURL = server.URL + "/v1"
+	cfg.Provider = config.ProviderLlamafile
+	cfg.Model = "test-model"
+	response, err := NewClient(cfg).SendChatCompletion([]Message{{Role: "user", Content: "Hi"}}, nil)
+	if err != nil {
+		t.Fatalf("SendChatCompletion() error = %v", err)
+	}
+
+	if request["cache_prompt"] != true {
+		t.Errorf("cache_prompt = %v, want true for llamafile", request["cache_prompt"])
+	}
+	if got := response.CachedTokens(); got != 1024 {
+		t.Errorf("CachedTokens() = %d, want 1024", got)
+	}
+
+	cfg.DisablePromptCache = true
+	request = nil
+	if _, err := NewClient(cfg).SendChatCompletion([]Message{{Role: "user", Content: "Hi"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := request["cache_prompt"]; ok {
+		t.Error("cache_prompt sent although prompt caching is disabled")
+	}
+}
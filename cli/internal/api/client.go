@@ -3,6 +3,7 @@ package api
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,10 +11,16 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/hacka-re/cli/internal/auditlog"
 	"github.com/hacka-re/cli/internal/config"
 	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/metrics"
+	"github.com/hacka-re/cli/internal/netguard"
+	"github.com/hacka-re/cli/internal/tracing"
+	"github.com/hacka-re/cli/internal/webhook"
 )
 
 // Client represents an OpenAI-compatible API client
@@ -21,6 +28,8 @@ type Client struct {
 	config          *config.Config
 	httpClient      *http.Client
 	modelCompat     *ModelCompatibility
+	toolsMu         sync.RWMutex
+	tools           []Tool // Offered to the model with every request
 }
 
 // NewClient creates a new API client
@@ -30,18 +39,46 @@ func NewClient(cfg *config.Config) *Client {
 		cfg.Provider, cfg.BaseURL, cfg.Model)
 
 	return &Client{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		config:      cfg,
+		httpClient:  newHTTPClient(cfg),
 		modelCompat: NewModelCompatibility(),
 	}
 }
 
 // Message represents a chat message
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string      `json:"role"`
+	Content    string      `json:"content"`
+	ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`   // Tools the assistant wants to call
+	ToolCallID string      `json:"tool_call_id,omitempty"` // Call answered by a "tool" message
+	Annotation *Annotation `json:"-"`                      // The user's review, never sent to the provider
+	Pinned     bool        `json:"-"`                      // Always kept when older messages are left out to fit the context window
+
+	cacheControl bool // Send content as a text part with a cache_control marker
+}
+
+// Tool describes a function the model may call
+type Tool struct {
+	Type     string       `json:"type"` // Always "function"
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction is the name, description and JSON schema parameters of a tool
+type ToolFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ToolCall is a tool invocation requested by the model
+type ToolCall struct {
+	Index    *int   `json:"index,omitempty"` // Position in the list, only set in stream deltas
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"` // JSON-encoded arguments
+	} `json:"function"`
 }
 
 // ChatRequest represents a chat completion request
@@ -50,8 +87,14 @@ type ChatRequest struct {
 	Messages            []Message `json:"messages"`
 	MaxTokens           int       `json:"max_tokens,omitempty"`
 	MaxCompletionTokens int       `json:"max_completion_tokens,omitempty"`
-	Temperature         float64   `json:"temperature,omitempty"`
+	Temperature         *float64  `json:"temperature,omitempty"` // Nil leaves the model's default
+	TopP                *float64  `json:"top_p,omitempty"`       // Nil leaves the model's default
+	Seed                int       `json:"seed,omitempty"`
 	Stream              bool      `json:"stream,omitempty"`
+	Tools               []Tool    `json:"tools,omitempty"`
+
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"` // Ask for usage in the last chunk
+	CachePrompt   bool           `json:"cache_prompt,omitempty"`   // llama.cpp: reuse the KV cache
 }
 
 // ChatResponse represents a chat completion response
@@ -60,20 +103,33 @@ type ChatResponse struct {
 	Object  string `json:"object"`
 	Created int64  `json:"created"`
 	Model   string `json:"model"`
-	Choices []struct {
-		Index   int     `json:"index"`
-		Message Message `json:"message"`
-		Delta   Message `json:"delta,omitempty"` // For streaming
-		FinishReason string `json:"finish_reason,omitempty"`
-	} `json:"choices"`
+	Choices []Choice `json:"choices"`
+
+	// SystemFingerprint identifies the backend configuration; replies with the
+	// same seed are only reproducible while it stays the same (OpenAI)
+	SystemFingerprint string `json:"system_fingerprint,omitempty"`
+
 	Usage struct {
 		PromptTokens     int `json:"prompt_tokens"`
 		CompletionTokens int `json:"completion_tokens"`
 		TotalTokens      int `json:"total_tokens"`
+
+		PromptTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+		PromptCacheHitTokens int `json:"prompt_cache_hit_tokens"` // DeepSeek
 	} `json:"usage,omitempty"`
 	Error *APIError `json:"error,omitempty"`
 }
 
+// Choice represents a single completion choice
+type Choice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	Delta        Message `json:"delta,omitempty"` // For streaming
+	FinishReason string  `json:"finish_reason,omitempty"`
+}
+
 // APIError represents an API error
 type APIError struct {
 	Message string `json:"message"`
@@ -84,24 +140,48 @@ type APIError struct {
 // StreamCallback is called for each chunk in a streaming response
 type StreamCallback func(chunk string) error
 
+// SetTools sets the tools offered to the model with every request. It may be
+// called while requests are in flight, e.g. when functions are reloaded.
+func (c *Client) SetTools(tools []Tool) {
+	c.toolsMu.Lock()
+	c.tools = tools
+	c.toolsMu.Unlock()
+}
+
 // SendChatCompletion sends a chat completion request
 func (c *Client) SendChatCompletion(messages []Message, streamCallback StreamCallback) (*ChatResponse, error) {
+	return c.SendChatCompletionContext(context.Background(), messages, streamCallback)
+}
+
+// SendChatCompletionContext sends a chat completion request that is cancelled with ctx
+func (c *Client) SendChatCompletionContext(ctx context.Context, messages []Message, streamCallback StreamCallback) (response *ChatResponse, err error) {
 	logger.Get().Debug("SendChatCompletion called with %d messages", len(messages))
 
+	ctx, span := tracing.Start(ctx, "chat.completion")
+	span.SetAttribute("llm.provider", string(c.config.Provider))
+	span.SetAttribute("llm.model", c.config.Model)
+	span.SetAttribute("llm.messages", len(messages))
+	defer func() { span.End(err) }()
+
 	// Build request with model-appropriate parameters
-	request := c.modelCompat.BuildCompatibleRequest(
-		c.config.Model,
-		messages,
-		c.config.MaxTokens,
-		c.config.Temperature,
-		c.config.StreamResponse && streamCallback != nil,
-	)
+	_, buildSpan := tracing.Start(ctx, "chat.prompt_build")
+	request := c.BuildRequest(messages, streamCallback != nil)
+	buildSpan.SetAttribute("llm.stream", request.Stream)
+	buildSpan.End(nil)
+
+	temperature := "default"
+	if request.Temperature != nil {
+		temperature = fmt.Sprint(*request.Temperature)
+	}
+	logger.Get().Debug("Request parameters: model=%s, maxTokens=%d, temperature=%s, seed=%d, stream=%v",
+		request.Model, request.MaxTokens, temperature, request.Seed, request.Stream)
 
-	logger.Get().Debug("Request parameters: model=%s, maxTokens=%d, temperature=%f, stream=%v",
-		request.Model, request.MaxTokens, request.Temperature, request.Stream)
+	startTime := time.Now()
+	defer func() { c.recordMetrics(startTime, response, err) }()
+	defer func() { c.emitCompletion(startTime, response, err) }()
 
 	// First attempt
-	response, err := c.sendRequestWithRetry(request, messages, streamCallback)
+	response, err = c.sendRequestWithRetry(ctx, request, messages, streamCallback)
 	if err != nil {
 		logger.Get().Error("API request failed: %v", err)
 		// Try to fix the request based on the error
@@ -109,17 +189,139 @@ func (c *Client) SendChatCompletion(messages []Message, streamCallback StreamCal
 			// Log the retry attempt
 			logger.Get().Info("Retrying with adjusted parameters")
 			logger.Get().Debug("Retrying with adjusted parameters")
-			return c.sendRequestWithRetry(*fixedRequest, messages, streamCallback)
+			span.SetAttribute("llm.compat_retry", true)
+			return c.sendRequestWithRetry(ctx, *fixedRequest, messages, streamCallback)
 		}
 	}
 
 	return response, err
 }
 
-// sendRequestWithRetry sends the actual request
-func (c *Client) sendRequestWithRetry(request ChatRequest, messages []Message, streamCallback StreamCallback) (*ChatResponse, error) {
+// BuildRequest assembles the request that SendChatCompletion sends for
+// messages: the model's parameters with the session's sampling overrides, the
+// tools and the prompt cache markers.
+// stream asks for a streamed reply if the configuration allows it.
+func (c *Client) BuildRequest(messages []Message, stream bool) ChatRequest {
+	request := c.modelCompat.BuildCompatibleRequest(
+		c.config.Model,
+		messages,
+		c.config.MaxTokens,
+		c.config.SessionSampling.TemperatureOr(c.config.Temperature),
+		c.config.StreamResponse && stream,
+	)
+	if topP := c.config.SessionSampling.TopP; topP != nil && c.modelCompat.GetModelConfig(c.config.Model).SupportsCustomTemperature {
+		request.TopP = topP
+	}
+	c.toolsMu.RLock()
+	request.Tools = c.tools
+	c.toolsMu.RUnlock()
+	request.Seed = c.config.Seed
+	c.applyPromptCache(&request)
+	return request
+}
+
+// Endpoint returns the chat completions URL requests are sent to
+func (c *Client) Endpoint() string {
+	// Handle BaseURL that already includes /v1 (e.g., llamafile, ollama)
+	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
+	if strings.HasSuffix(baseURL, "/v1") {
+		// BaseURL already includes /v1, just add the endpoint
+		return baseURL + "/chat/completions"
+	}
+	// Add the full path
+	return baseURL + "/v1/chat/completions"
+}
+
+// recordMetrics updates the request, latency and token metrics for a completion
+func (c *Client) recordMetrics(startTime time.Time, response *ChatResponse, err error) {
+	provider := string(c.config.Provider)
+	metrics.LLMRequests.Inc(provider, metrics.Status(err))
+	metrics.LLMLatency.ObserveSince(startTime, provider)
+	if err != nil {
+		metrics.Errors.Inc("api")
+	}
+	if response != nil {
+		metrics.LLMTokens.Add(float64(response.Usage.PromptTokens), provider, "prompt")
+		metrics.LLMTokens.Add(float64(response.Usage.CompletionTokens), provider, "completion")
+	}
+}
+
+// emitCompletion fires the message.completed webhook for a successful completion
+// and writes the completion, successful or not, to the audit log
+func (c *Client) emitCompletion(startTime time.Time, response *ChatResponse, err error) {
+	c.auditCompletion(startTime, response, err)
+	if err != nil || response == nil || !webhook.Enabled() {
+		return
+	}
+
+	data := map[string]interface{}{
+		"provider":         string(c.config.Provider),
+		"model":            c.config.Model,
+		"durationMs":       time.Since(startTime).Milliseconds(),
+		"promptTokens":     response.Usage.PromptTokens,
+		"completionTokens": response.Usage.CompletionTokens,
+	}
+	if c.config.Seed != 0 {
+		data["seed"] = c.config.Seed
+	}
+	var content map[string]interface{}
+	if len(response.Choices) > 0 {
+		choice := response.Choices[0]
+		data["finishReason"] = choice.FinishReason
+		data["toolCalls"] = len(choice.Message.ToolCalls)
+		content = map[string]interface{}{"content": choice.Message.Content}
+	}
+	webhook.Emit(webhook.EventMessageCompleted, data, content)
+}
+
+// auditCompletion writes a message.completed event to the audit log
+func (c *Client) auditCompletion(startTime time.Time, response *ChatResponse, err error) {
+	if !auditlog.Enabled() {
+		return
+	}
+
+	event := auditlog.Event{
+		Type:       auditlog.EventMessageCompleted,
+		Namespace:  c.config.Namespace,
+		Provider:   string(c.config.Provider),
+		Model:      c.config.Model,
+		Seed:       c.config.Seed,
+		Status:     "ok",
+		DurationMs: time.Since(startTime).Milliseconds(),
+	}
+	if err != nil {
+		event.Status = "error"
+		event.Error = err.Error()
+	}
+	if response != nil {
+		event.PromptTokens = response.Usage.PromptTokens
+		event.CompletionTokens = response.Usage.CompletionTokens
+		if len(response.Choices) > 0 {
+			choice := response.Choices[0]
+			event.FinishReason = choice.FinishReason
+			event.ToolCalls = len(choice.Message.ToolCalls)
+			event.Content = choice.Message.Content
+		}
+	}
+	auditlog.Emit(event)
+}
+
+// sendRequestWithRetry sends the request, resuming streams that drop mid-response
+func (c *Client) sendRequestWithRetry(ctx context.Context, request ChatRequest, messages []Message, streamCallback StreamCallback) (*ChatResponse, error) {
 	logger.Get().Debug("sendRequestWithRetry called")
 
+	response, err := c.sendRequest(ctx, request, streamCallback)
+	if errors.Is(err, ErrStreamInterrupted) {
+		return c.resumeInterruptedStream(ctx, request, response, streamCallback)
+	}
+	return response, err
+}
+
+// sendRequest sends the actual request
+func (c *Client) sendRequest(ctx context.Context, request ChatRequest, streamCallback StreamCallback) (response *ChatResponse, err error) {
+	ctx, span := tracing.Start(ctx, "provider.call")
+	defer func() { span.End(err) }()
+
 	// Marshal request
 	body, err := json.Marshal(request)
 	if err != nil {
@@ -130,16 +332,7 @@ func (c *Client) sendRequestWithRetry(request ChatRequest, messages []Message, s
 	logger.Get().Debug("Request body: %s", string(body))
 
 	// Create HTTP request
-	// Handle BaseURL that already includes /v1 (e.g., llamafile, ollama)
-	baseURL := strings.TrimSuffix(c.config.BaseURL, "/")
-	var url string
-	if strings.HasSuffix(baseURL, "/v1") {
-		// BaseURL already includes /v1, just add the endpoint
-		url = baseURL + "/chat/completions"
-	} else {
-		// Add the full path
-		url = baseURL + "/v1/chat/completions"
-	}
+	url := c.Endpoint()
 	logger.Get().Debug("Base URL: %s, Final URL: %s", c.config.BaseURL, url)
 	logger.Get().Info("API URL: %s", url)
 	logger.Get().Debug("Base URL from config: %s", c.config.BaseURL)
@@ -147,10 +340,23 @@ func (c *Client) sendRequestWithRetry(request ChatRequest, messages []Message, s
 	// Validate offline mode - check based on request type and allowances
 	if err := validateOfflineRequest(url, c.config); err != nil {
 		logger.Get().Error("Offline mode violation: %v", err)
-		return nil, fmt.Errorf("offline mode violation: %w", err)
+		webhook.Emit(webhook.EventPolicyViolation, map[string]interface{}{
+			"policy": "offline",
+			"url":    url,
+			"reason": err.Error(),
+		}, nil)
+		auditlog.Emit(auditlog.Event{
+			Type:      auditlog.EventPolicyViolation,
+			Namespace: c.config.Namespace,
+			Policy:    "offline",
+			URL:       url,
+			Error:     err.Error(),
+		})
+		return nil, fmt.Errorf("%w: %w", ErrOfflineViolation, err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	span.SetAttribute("http.url", url)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		logger.Get().Error("Failed to create request: %v", err)
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -172,23 +378,32 @@ func (c *Client) sendRequestWithRetry(request ChatRequest, messages []Message, s
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		logger.Get().Error("HTTP request failed: %v", err)
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w: %w", ErrProviderUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	logger.Get().Info("Response status code: %d", resp.StatusCode)
+	span.SetAttribute("http.status_code", resp.StatusCode)
 	logger.Get().Debug("Response headers: %v", resp.Header)
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		logger.Get().Error("API error (status %d): %s", resp.StatusCode, string(body))
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newStatusError(resp, body)
 	}
 
-	// Handle streaming response
+	// Handle streaming response, aborting if the server goes silent
 	if request.Stream {
-		return c.handleStreamingResponse(resp.Body, streamCallback)
+		idleTimeout := secondsOrDefault(c.config.StreamIdleTimeout, DefaultStreamIdleTimeout)
+		body := newIdleTimeoutReader(resp.Body, idleTimeout)
+		defer body.Close()
+
+		_, streamSpan := tracing.Start(ctx, "provider.stream")
+		response, err = c.handleStreamingResponse(body, streamCallback)
+		streamSpan.SetAttribute("stream.chars", len(responseContent(response)))
+		streamSpan.End(err)
+		return response, err
 	}
 
 	// Handle regular response
@@ -204,11 +419,15 @@ func (c *Client) sendRequestWithRetry(request ChatRequest, messages []Message, s
 	return &chatResp, nil
 }
 
-// handleStreamingResponse handles a streaming response
+// handleStreamingResponse handles a streaming response.
+// If the stream ends before the provider signals completion, the partial
+// response is returned together with ErrStreamInterrupted.
 func (c *Client) handleStreamingResponse(body io.Reader, callback StreamCallback) (*ChatResponse, error) {
 	scanner := bufio.NewScanner(body)
 	var fullContent strings.Builder
 	var lastResponse *ChatResponse
+	var toolCalls []ToolCall
+	completed := false
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -228,6 +447,7 @@ func (c *Client) handleStreamingResponse(body io.Reader, callback StreamCallback
 		
 		// Check for end of stream
 		if data == "[DONE]" {
+			completed = true
 			break
 		}
 
@@ -238,6 +458,16 @@ func (c *Client) handleStreamingResponse(body io.Reader, callback StreamCallback
 			continue
 		}
 
+		// A finish reason also marks a complete response
+		if len(chunk.Choices) > 0 && chunk.Choices[0].FinishReason != "" {
+			completed = true
+		}
+
+		// Tool calls arrive in fragments keyed by index
+		if len(chunk.Choices) > 0 {
+			toolCalls = mergeToolCallDeltas(toolCalls, chunk.Choices[0].Delta.ToolCalls)
+		}
+
 		// Extract content from delta
 		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
 			content := chunk.Choices[0].Delta.Content
@@ -254,19 +484,65 @@ func (c *Client) handleStreamingResponse(body io.Reader, callback StreamCallback
 		lastResponse = &chunk
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading stream: %w", err)
-	}
-
 	// Build final response
+	if lastResponse != nil && len(lastResponse.Choices) == 0 {
+		lastResponse.Choices = make([]Choice, 1)
+	}
 	if lastResponse != nil && fullContent.Len() > 0 {
 		lastResponse.Choices[0].Message.Content = fullContent.String()
+	}
+	if lastResponse != nil && len(toolCalls) > 0 {
+		lastResponse.Choices[0].Message.Role = "assistant"
+		lastResponse.Choices[0].Message.ToolCalls = toolCalls
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, ErrStreamIdle) {
+			logger.Get().Error("Stream stalled after %d chars: %v", fullContent.Len(), err)
+			return lastResponse, fmt.Errorf("%w - the model server stopped responding; local servers like llamafile can hang, so check it or raise streamIdleTimeout", err)
+		}
+		logger.Get().Warn("Stream read error after %d chars: %v", fullContent.Len(), err)
+		return lastResponse, fmt.Errorf("%w: %v", ErrStreamInterrupted, err)
+	}
+
+	if !completed {
+		logger.Get().Warn("Stream ended without completion marker after %d chars", fullContent.Len())
+		return lastResponse, ErrStreamInterrupted
+	}
+
+	if lastResponse != nil && (fullContent.Len() > 0 || len(toolCalls) > 0) {
 		return lastResponse, nil
 	}
 
 	return nil, errors.New("no content received from stream")
 }
 
+// mergeToolCallDeltas folds streamed tool call fragments into complete calls
+func mergeToolCallDeltas(calls []ToolCall, deltas []ToolCall) []ToolCall {
+	for _, delta := range deltas {
+		index := len(calls)
+		if delta.Index != nil {
+			index = *delta.Index
+		}
+		for len(calls) <= index {
+			calls = append(calls, ToolCall{Type: "function"})
+		}
+
+		call := &calls[index]
+		if delta.ID != "" {
+			call.ID = delta.ID
+		}
+		if delta.Type != "" {
+			call.Type = delta.Type
+		}
+		if delta.Function.Name != "" {
+			call.Function.Name = delta.Function.Name
+		}
+		call.Function.Arguments += delta.Function.Arguments
+	}
+	return calls
+}
+
 // ListModels lists available models
 func (c *Client) ListModels() ([]string, error) {
 	url := strings.TrimSuffix(c.config.BaseURL, "/") + "/models"
@@ -387,29 +663,28 @@ func validateOfflineURL(urlStr string) error {
 // validateOfflineRequest validates a request based on offline mode and allowances
 func validateOfflineRequest(urlStr string, config *config.Config) error {
 	// Not in offline mode, allow all
-	if !config.IsOfflineMode {
+	if !config.IsOfflineMode && !netguard.Offline() {
 		return nil
 	}
 
-	// Determine request type
-	requestType := detectRequestType(urlStr)
-
 	// Check allowances based on request type
-	switch requestType {
-	case RequestTypeMCP:
-		if config.AllowRemoteMCP {
-			logger.Get().Debug("Offline mode: Allowing remote MCP request")
-			return nil
-		}
-	case RequestTypeEmbeddings:
-		if config.AllowRemoteEmbeddings {
-			logger.Get().Debug("Offline mode: Allowing remote embeddings request")
-			return nil
-		}
-	case RequestTypeLLM:
-		// LLM requests must always be local in offline mode
+	if offlineExempt(urlStr, config) {
+		logger.Get().Debug("Offline mode: Allowing remote request to %s", urlStr)
+		return nil
 	}
 
 	// Validate that URL is localhost
 	return validateOfflineURL(urlStr)
+}
+
+// offlineExempt reports whether an --allow-remote-* flag covers the request;
+// LLM requests must always be local in offline mode
+func offlineExempt(urlStr string, config *config.Config) bool {
+	switch detectRequestType(urlStr) {
+	case RequestTypeMCP:
+		return config.AllowRemoteMCP
+	case RequestTypeEmbeddings:
+		return config.AllowRemoteEmbeddings
+	}
+	return false
 }
\ No newline at end of file
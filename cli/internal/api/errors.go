@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error classes for provider failures; test with errors.Is
+var (
+	// ErrUnauthorized is returned when the provider rejects the API key (401/403)
+	ErrUnauthorized = errors.New("provider rejected the API key")
+	// ErrRateLimited is returned when the provider throttles requests or the quota is used up (429)
+	ErrRateLimited = errors.New("provider rate limit exceeded")
+	// ErrProviderUnavailable is returned when the provider can't be reached or fails (network errors, 5xx)
+	ErrProviderUnavailable = errors.New("provider unavailable")
+	// ErrModelNotFound is returned when the provider doesn't know the requested model (404)
+	ErrModelNotFound = errors.New("model not found")
+	// ErrOfflineViolation is returned when offline mode blocks a request to a remote host
+	ErrOfflineViolation = errors.New("offline mode violation")
+)
+
+// StatusError is a non-200 response from the provider
+type StatusError struct {
+	StatusCode int
+	Message    string
+	RetryAfter string // Retry-After header, if the provider sent one
+}
+
+// Error implements error
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the error class for the status code, so errors.Is works on StatusError
+func (e *StatusError) Unwrap() error {
+	return classifyStatus(e.StatusCode)
+}
+
+// classifyStatus maps an HTTP status code to an error class (nil if none applies)
+func classifyStatus(code int) error {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return ErrUnauthorized
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code == http.StatusNotFound:
+		return ErrModelNotFound
+	case code >= 500:
+		return ErrProviderUnavailable
+	}
+	return nil
+}
+
+// newStatusError builds a StatusError from a failed response, extracting the provider's message if it's JSON
+func newStatusError(resp *http.Response, body []byte) *StatusError {
+	message := strings.TrimSpace(string(body))
+	var parsed struct {
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Error != nil && parsed.Error.Message != "" {
+		message = parsed.Error.Message
+	}
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		RetryAfter: resp.Header.Get("Retry-After"),
+	}
+}
@@ -313,7 +313,7 @@ func (mc *ModelCompatibility) BuildCompatibleRequest(
 		} else if temperature > config.MaxTemperature {
 			temperature = config.MaxTemperature
 		}
-		request.Temperature = temperature
+		request.Temperature = &temperature
 	}
 	// If model doesn't support custom temperature, don't include it
 	// (API will use the model's default)
@@ -338,16 +338,42 @@ func (mc *ModelCompatibility) HandleAPIError(
 		return &fixedRequest, true
 	}
 	
+	// Check for providers that reject top_p, or top_p beside temperature
+	if strings.Contains(errStr, "top_p") && originalRequest.TopP != nil {
+		fixedRequest := originalRequest
+		fixedRequest.TopP = nil
+		return &fixedRequest, true
+	}
+
 	// Check for temperature error
 	if strings.Contains(errStr, "temperature") && 
 	   (strings.Contains(errStr, "does not support") || 
 	    strings.Contains(errStr, "Only the default")) {
 		// Remove temperature parameter
 		fixedRequest := originalRequest
-		fixedRequest.Temperature = 0 // Will be omitted in JSON with omitempty
+		fixedRequest.Temperature = nil
+		return &fixedRequest, true
+	}
+
+	// Check for providers that don't take a seed
+	if strings.Contains(errStr, "seed") && originalRequest.Seed != 0 {
+		fixedRequest := originalRequest
+		fixedRequest.Seed = 0
 		return &fixedRequest, true
 	}
 	
+	// Check for servers that reject stream_options or cache_control content parts
+	if strings.Contains(errStr, "stream_options") && originalRequest.StreamOptions != nil {
+		fixedRequest := originalRequest
+		fixedRequest.StreamOptions = nil
+		return &fixedRequest, true
+	}
+	if strings.Contains(errStr, "cache_control") {
+		fixedRequest := originalRequest
+		fixedRequest.Messages = withoutCacheControl(originalRequest.Messages)
+		return &fixedRequest, true
+	}
+
 	// Check for reverse max_tokens error (less common)
 	if strings.Contains(errStr, "max_completion_tokens") && 
 	   strings.Contains(errStr, "max_tokens") &&
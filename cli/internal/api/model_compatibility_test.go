@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/sampling"
+)
+
+func TestDeterministicRequest(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+		if _, ok := request["seed"]; ok && len(requests) > 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"Unrecognized request argument supplied: seed"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"system_fingerprint":"fp_1","choices":[{"message":{"role":"assistant","content":"ok"}}]}`)
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.BaseURL = server.URL + "/v1"
+	cfg.Model = "gpt-4o"
+	cfg.MakeDeterministic()
+	response, err := NewClient(cfg).SendChatCompletion([]Message{{Role: "user", Content: "Hi"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests[0]["temperature"] != 0.0 || requests[0]["seed"] != float64(config.DefaultSeed) {
+		t.Errorf("request = %v, want temperature 0 and the default seed", requests[0])
+	}
+	if response.SystemFingerprint != "fp_1" {
+		t.Errorf("SystemFingerprint = %q", response.SystemFingerprint)
+	}
+
+	// A provider that rejects the seed gets the request again without it
+	if _, err := NewClient(cfg).SendChatCompletion([]Message{{Role: "user", Content: "Hi"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 3 {
+		t.Fatalf("%d requests, want a retry", len(requests))
+	}
+	if _, ok := requests[2]["seed"]; ok || requests[2]["temperature"] != 0.0 {
+		t.Errorf("retry = %v, want temperature 0 without a seed", requests[2])
+	}
+}
+
+func TestSessionSamplingRequest(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Model = "gpt-4o"
+	cfg.Temperature = 0.7
+	cfg.SessionSampling = sampling.Override{Temperature: sampling.Float(0.2), TopP: sampling.Float(0.9)}
+	request := NewClient(cfg).BuildRequest([]Message{{Role: "user", Content: "Hi"}}, false)
+	if request.Temperature == nil || *request.Temperature != 0.2 || request.TopP == nil || *request.TopP != 0.9 {
+		t.Errorf("request has temperature %v and top_p %v, want the session's 0.2 and 0.9", request.Temperature, request.TopP)
+	}
+	if cfg.Temperature != 0.7 {
+		t.Errorf("configured temperature changed to %g", cfg.Temperature)
+	}
+
+	// Models that only run at their defaults get neither
+	cfg.Model = "gpt-5-mini"
+	request = NewClient(cfg).BuildRequest([]Message{{Role: "user", Content: "Hi"}}, false)
+	if request.Temperature != nil || request.TopP != nil {
+		t.Errorf("request has temperature %v and top_p %v, want neither", request.Temperature, request.TopP)
+	}
+
+	// A provider that rejects top_p gets the request again without it
+	fixed, retry := NewModelCompatibility().HandleAPIError(fmt.Errorf("temperature and top_p cannot both be specified"), ChatRequest{TopP: sampling.Float(0.9)})
+	if !retry || fixed.TopP != nil {
+		t.Errorf("HandleAPIError() = %+v, %v, want a retry without top_p", fixed, retry)
+	}
+}
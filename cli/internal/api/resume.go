@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/tracing"
+)
+
+// ErrStreamInterrupted is returned when a stream ends before the provider signals completion
+var ErrStreamInterrupted = errors.New("stream interrupted before completion")
+
+// MaxStreamResumeAttempts limits how many times a dropped stream is reconnected
+const MaxStreamResumeAttempts = 2
+
+// streamResumeBackoff is the base delay before reconnecting, multiplied by the attempt number
+var streamResumeBackoff = time.Second
+
+// ContinuationPrompt asks the model to pick up exactly where a dropped reply stopped
+const ContinuationPrompt = "Your previous reply was cut off by a network error. Continue exactly where it stopped, without repeating any text and without commentary."
+
+// resumeInterruptedStream reconnects after a dropped stream. If part of the reply was
+// already received, the model is asked to continue from that prefix; otherwise the
+// request is simply regenerated. The returned response contains the full reply.
+func (c *Client) resumeInterruptedStream(ctx context.Context, request ChatRequest, partial *ChatResponse, callback StreamCallback) (response *ChatResponse, err error) {
+	prefix := responseContent(partial)
+	lastErr := ErrStreamInterrupted
+
+	ctx, span := tracing.Start(ctx, "stream.resume")
+	span.SetAttribute("stream.received_chars", len(prefix))
+	defer func() { span.End(err) }()
+
+	for attempt := 1; attempt <= MaxStreamResumeAttempts; attempt++ {
+		// Back off a little before reconnecting, unless the request was cancelled
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(attempt) * streamResumeBackoff):
+		}
+
+		retryRequest := request
+		if prefix != "" {
+			retryRequest.Messages = continuationMessages(request.Messages, prefix)
+			logger.Get().Info("Resuming interrupted stream (attempt %d) from %d received chars", attempt, len(prefix))
+		} else {
+			logger.Get().Info("Regenerating interrupted stream (attempt %d)", attempt)
+		}
+
+		span.SetAttribute("stream.attempts", attempt)
+		response, err := c.sendRequest(ctx, retryRequest, callback)
+		prefix += responseContent(response)
+
+		if err == nil {
+			if response != nil && len(response.Choices) > 0 {
+				response.Choices[0].Message.Content = prefix
+			}
+			return response, nil
+		}
+
+		if !errors.Is(err, ErrStreamInterrupted) {
+			// Connection refused, auth errors, etc. won't be fixed by retrying
+			lastErr = fmt.Errorf("%w: reconnect failed: %v", ErrStreamInterrupted, err)
+			break
+		}
+		lastErr = err
+	}
+
+	logger.Get().Error("Giving up on interrupted stream: %v", lastErr)
+
+	// Return what we have so the caller can show the partial reply
+	if prefix != "" {
+		return &ChatResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: prefix}}},
+		}, lastErr
+	}
+	return nil, lastErr
+}
+
+// continuationMessages appends the partial reply and a request to continue it
+func continuationMessages(messages []Message, prefix string) []Message {
+	result := make([]Message, 0, len(messages)+2)
+	result = append(result, messages...)
+	result = append(result,
+		Message{Role: "assistant", Content: prefix},
+		Message{Role: "user", Content: ContinuationPrompt},
+	)
+	return result
+}
+
+// responseContent returns the message content of a response, if any
+func responseContent(response *ChatResponse) string {
+	if response == nil || len(response.Choices) == 0 {
+		return ""
+	}
+	return response.Choices[0].Message.Content
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hacka-re/cli/internal/config"
+)
+
+func writeChunk(w http.ResponseWriter, content string) {
+	fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", content)
+}
+
+func newStreamTestClient(url string) *Client {
+	cfg := config.NewConfig()
+	cfg.BaseURL = url + "/v1"
+	cfg.Provider = config.ProviderCustom
+	cfg.Model = "test-model"
+	return NewClient(cfg)
+}
+
+// noResumeBackoff reconnects without waiting for the rest of the test
+func noResumeBackoff(t *testing.T) {
+	saved := streamResumeBackoff
+	streamResumeBackoff = 0
+	t.Cleanup(func() { streamResumeBackoff = saved })
+}
+
+func TestResumeInterruptedStream(t *testing.T) {
+	noResumeBackoff(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// First attempt drops before the completion marker
+			writeChunk(w, "Hello, ")
+			return
+		}
+		writeChunk(w, "world!")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := newStreamTestClient(server.URL)
+
+	var streamed strings.Builder
+	response, err := client.SendChatCompletion([]Message{{Role: "user", Content: "Hi"}}, func(chunk string) error {
+		streamed.WriteString(chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SendChatCompletion() error = %v", err)
+	}
+
+	if got := responseContent(response); got != "Hello, world!" {
+		t.Errorf("response content = %q, want %q", got, "Hello, world!")
+	}
+	if streamed.String() != "Hello, world!" {
+		t.Errorf("streamed content = %q, want %q", streamed.String(), "Hello, world!")
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 requests, got %d", calls)
+	}
+}
+
+func TestResumeInterruptedStreamGivesUp(t *testing.T) {
+	noResumeBackoff(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeChunk(w, "partial ")
+	}))
+	defer server.Close()
+
+	client := newStreamTestClient(server.URL)
+
+	response, err := client.SendChatCompletion([]Message{{Role: "user", Content: "Hi"}}, func(chunk string) error {
+		return nil
+	})
+	if !errors.Is(err, ErrStreamInterrupted) {
+		t.Fatalf("expected ErrStreamInterrupted, got %v", err)
+	}
+	if got := responseContent(response); got != "partial partial partial " {
+		t.Errorf("partial content = %q", got)
+	}
+	if calls != 1+MaxStreamResumeAttempts {
+		t.Errorf("expected %d requests, got %d", 1+MaxStreamResumeAttempts, calls)
+	}
+}
+
+func TestResumeInterruptedStreamCancelled(t *testing.T) {
+	saved := streamResumeBackoff
+	streamResumeBackoff = time.Hour
+	t.Cleanup(func() { streamResumeBackoff = saved })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeChunk(w, "partial ")
+	}))
+	defer server.Close()
+
+	client := newStreamTestClient(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.SendChatCompletionContext(ctx, []Message{{Role: "user", Content: "Hi"}}, func(chunk string) error {
+			return nil
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the deadline error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the resume backoff ignored the cancelled context")
+	}
+}
+
+func TestContinuationMessages(t *testing.T) {
+	original := []Message{{Role: "user", Content: "Tell me a story"}}
+	messages := continuationMessages(original, "Once upon")
+
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+	if messages[1].Role != "assistant" || messages[1].Content != "Once upon" {
+		t.Errorf("unexpected prefix message: %+v", messages[1])
+	}
+	if messages[2].Role != "user" {
+		t.Errorf("expected trailing user message, got %s", messages[2].Role)
+	}
+	if len(original) != 1 {
+		t.Errorf("original messages were modified")
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// Default network timeouts, used when the config leaves them unset
+const (
+	DefaultConnectTimeout    = 10 * time.Second
+	DefaultReadTimeout       = 120 * time.Second
+	DefaultStreamIdleTimeout = 60 * time.Second
+)
+
+// ErrStreamIdle is returned when a stream stops sending data for longer than the idle timeout
+var ErrStreamIdle = errors.New("stream idle timeout")
+
+// newHTTPClient builds an HTTP client with connect and read timeouts from the config.
+// There is no overall request timeout, so long streaming replies aren't cut off;
+// stalled streams are caught by the idle watchdog instead. In offline mode the
+// transport only dials local addresses, so a redirect or a hostname that
+// resolves to a public address is refused even after the URL check passed.
+func newHTTPClient(cfg *config.Config) *http.Client {
+	connectTimeout := secondsOrDefault(cfg.ConnectTimeout, DefaultConnectTimeout)
+	readTimeout := secondsOrDefault(cfg.ReadTimeout, DefaultReadTimeout)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.ResponseHeaderTimeout = readTimeout
+
+	guarded := netguard.NewTransport(transport, func(req *http.Request) bool {
+		return (cfg.IsOfflineMode || netguard.Offline()) && !offlineExempt(req.URL.String(), cfg)
+	})
+	return &http.Client{Transport: guarded}
+}
+
+// secondsOrDefault converts a seconds setting to a duration, falling back to def when unset
+func secondsOrDefault(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// idleTimeoutReader closes the underlying body if no data arrives within the timeout
+type idleTimeoutReader struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+
+	mu       sync.Mutex
+	timedOut bool
+}
+
+// newIdleTimeoutReader starts the watchdog for a response body
+func newIdleTimeoutReader(body io.ReadCloser, timeout time.Duration) *idleTimeoutReader {
+	r := &idleTimeoutReader{body: body, timeout: timeout}
+	r.timer = time.AfterFunc(timeout, r.expire)
+	return r
+}
+
+// expire is called by the watchdog timer when the stream has been silent too long
+func (r *idleTimeoutReader) expire() {
+	r.mu.Lock()
+	r.timedOut = true
+	r.mu.Unlock()
+	r.body.Close()
+}
+
+// Read reads from the body and resets the watchdog whenever data arrives
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	if err != nil && r.TimedOut() {
+		return n, fmt.Errorf("%w: no data for %s", ErrStreamIdle, r.timeout)
+	}
+	return n, err
+}
+
+// Close stops the watchdog and closes the body
+func (r *idleTimeoutReader) Close() error {
+	r.timer.Stop()
+	return r.body.Close()
+}
+
+// TimedOut reports whether the watchdog fired
+func (r *idleTimeoutReader) TimedOut() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.timedOut
+}
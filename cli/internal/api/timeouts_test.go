@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+func TestIdleTimeoutReaderExpires(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	reader := newIdleTimeoutReader(pr, 50*time.Millisecond)
+	defer reader.Close()
+
+	go pw.Write([]byte("data"))
+
+	buf := make([]byte, 16)
+	n, err := reader.Read(buf)
+	if err != nil || string(buf[:n]) != "data" {
+		t.Fatalf("first Read() = %q, %v", buf[:n], err)
+	}
+
+	// Nothing else is written, so the watchdog should fire
+	_, err = reader.Read(buf)
+	if !errors.Is(err, ErrStreamIdle) {
+		t.Fatalf("expected ErrStreamIdle, got %v", err)
+	}
+	if !reader.TimedOut() {
+		t.Error("TimedOut() = false after watchdog fired")
+	}
+}
+
+func TestSecondsOrDefault(t *testing.T) {
+	if got := secondsOrDefault(0, DefaultReadTimeout); got != DefaultReadTimeout {
+		t.Errorf("secondsOrDefault(0) = %v, want %v", got, DefaultReadTimeout)
+	}
+	if got := secondsOrDefault(5, DefaultReadTimeout); got != 5*time.Second {
+		t.Errorf("secondsOrDefault(5) = %v, want 5s", got)
+	}
+}
+
+func TestHTTPClientBlocksRedirectOffline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://8.8.8.8/v1/chat/completions", http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.IsOfflineMode = true
+	_, err := newHTTPClient(cfg).Get(server.URL)
+	if !errors.Is(err, netguard.ErrBlocked) {
+		t.Fatalf("expected the redirect to a public host to be refused, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 
+	"github.com/hacka-re/cli/internal/api"
 	"github.com/hacka-re/cli/internal/chat"
 	"github.com/hacka-re/cli/internal/config"
 	"github.com/hacka-re/cli/internal/integration"
@@ -11,10 +12,18 @@ import (
 
 // StartChatInterface starts the enhanced chat interface with all modal handlers configured
 func StartChatInterface(cfg *config.Config) error {
+	return StartChatWithHistory(cfg, nil)
+}
+
+// StartChatWithHistory starts the chat interface continuing from earlier messages
+func StartChatWithHistory(cfg *config.Config, history []api.Message) error {
 	logger.Get().Info("StartChatInterface called with Provider=%s, BaseURL=%s, Model=%s", cfg.Provider, cfg.BaseURL, cfg.Model)
 
 	// Create the terminal chat with proper input handling
 	terminalChat := chat.NewTerminalChat(cfg)
+	if len(history) > 0 {
+		terminalChat.LoadHistory(history)
+	}
 
 	// Set up modal handlers
 	terminalChat.SetModalHandlers(chat.ModalHandlers{
@@ -0,0 +1,346 @@
+// Package artifacts keeps the files a chat session produces, such as files
+// saved by functions and pasted images, in a directory per session under
+// Root(). Old sessions are removed by Cleanup according to a Policy.
+package artifacts
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cleanup defaults, used when a Policy field is 0
+const (
+	DefaultMaxAgeDays = 30
+	DefaultMaxSizeMB  = 500
+)
+
+// Policy caps how long artifacts are kept and how much space they use. 0 uses
+// the default and a negative value means no limit.
+type Policy struct {
+	MaxAgeDays int
+	MaxSizeMB  int
+}
+
+// maxAge returns the age cap, or 0 for none
+func (p Policy) maxAge() time.Duration {
+	days := p.MaxAgeDays
+	if days == 0 {
+		days = DefaultMaxAgeDays
+	}
+	if days < 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// maxSize returns the size cap in bytes, or 0 for none
+func (p Policy) maxSize() int64 {
+	mb := p.MaxSizeMB
+	if mb == 0 {
+		mb = DefaultMaxSizeMB
+	}
+	if mb < 0 {
+		return 0
+	}
+	return int64(mb) << 20
+}
+
+// String renders the policy as ParsePolicy reads it, e.g. "days=30 mb=500"
+func (p Policy) String() string {
+	if p.maxAge() == 0 && p.maxSize() == 0 {
+		return "off"
+	}
+	limit := func(v, def int) string {
+		switch {
+		case v == 0:
+			return strconv.Itoa(def)
+		case v < 0:
+			return "off"
+		}
+		return strconv.Itoa(v)
+	}
+	return fmt.Sprintf("days=%s mb=%s", limit(p.MaxAgeDays, DefaultMaxAgeDays), limit(p.MaxSizeMB, DefaultMaxSizeMB))
+}
+
+// ParsePolicy reads "days=N mb=N" (either may be left out to keep its
+// default, and "off" as a value removes that cap), or "off" for no cleanup
+func ParsePolicy(s string) (Policy, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "off" || s == "none" {
+		return Policy{MaxAgeDays: -1, MaxSizeMB: -1}, nil
+	}
+	var p Policy
+	for _, field := range strings.Fields(strings.ReplaceAll(s, ",", " ")) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return Policy{}, fmt.Errorf("expected key=value, got %q", field)
+		}
+		n := -1
+		if value != "off" {
+			var err error
+			if n, err = strconv.Atoi(value); err != nil || n < 1 {
+				return Policy{}, fmt.Errorf("%s must be a positive number or off", key)
+			}
+		}
+		switch key {
+		case "days":
+			p.MaxAgeDays = n
+		case "mb":
+			p.MaxSizeMB = n
+		default:
+			return Policy{}, fmt.Errorf("unknown limit %q (use days or mb)", key)
+		}
+	}
+	return p, nil
+}
+
+// Root returns the directory holding every session's artifacts.
+// HACKARE_ARTIFACTS_DIR overrides it.
+func Root() string {
+	if dir := os.Getenv("HACKARE_ARTIFACTS_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hacka.re-artifacts")
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "artifacts")
+}
+
+// Artifact is a file in a session directory
+type Artifact struct {
+	Name     string
+	Path     string
+	Size     int64
+	Modified time.Time
+}
+
+// Session is the artifacts directory of one run of hacka.re. The directory
+// is only created when the first file is saved.
+type Session struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewSession starts a session under root, named by its start time so that
+// sessions sort by age
+func NewSession(root string) *Session {
+	id := make([]byte, 3)
+	rand.Read(id)
+	name := time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(id)
+	return &Session{dir: filepath.Join(root, name)}
+}
+
+var (
+	current     *Session
+	currentOnce sync.Once
+)
+
+// Current returns the session of this process
+func Current() *Session {
+	currentOnce.Do(func() {
+		current = NewSession(Root())
+	})
+	return current
+}
+
+// Dir returns the session directory, which may not exist yet
+func (s *Session) Dir() string {
+	return s.dir
+}
+
+// Save writes data to a file called name in the session directory and
+// returns its path. Only the base name is used, and a name already taken
+// gets a numbered suffix rather than overwriting the earlier file.
+func (s *Session) Save(name string, data []byte) (string, error) {
+	name = filepath.Base(strings.TrimSpace(name))
+	if name == "." || name == string(filepath.Separator) || name == "" {
+		name = "artifact"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	path := filepath.Join(s.dir, name)
+	for i := 2; ; i++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			path = filepath.Join(s.dir, fmt.Sprintf("%s-%d%s", stem, i, ext))
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return path, f.Close()
+	}
+}
+
+// List returns the session's artifacts, oldest first
+func (s *Session) List() ([]Artifact, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Artifact
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		list = append(list, Artifact{
+			Name:     entry.Name(),
+			Path:     filepath.Join(s.dir, entry.Name()),
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Modified.Before(list[j].Modified) })
+	return list, nil
+}
+
+// sessionDir is a session directory found under the root
+type sessionDir struct {
+	path     string
+	size     int64
+	modified time.Time // Of the newest file
+}
+
+// scan returns the session directories under root, oldest first
+func scan(root string) ([]sessionDir, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sessions []sessionDir
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		session := sessionDir{path: filepath.Join(root, entry.Name())}
+		if info, err := entry.Info(); err == nil {
+			session.modified = info.ModTime()
+		}
+		filepath.WalkDir(session.path, func(_ string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				session.size += info.Size()
+				if info.ModTime().After(session.modified) {
+					session.modified = info.ModTime()
+				}
+			}
+			return nil
+		})
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].modified.Before(sessions[j].modified) })
+	return sessions, nil
+}
+
+// Usage returns the number of sessions under root and their total size
+func Usage(root string) (int, int64, error) {
+	sessions, err := scan(root)
+	var total int64
+	for _, session := range sessions {
+		total += session.size
+	}
+	return len(sessions), total, err
+}
+
+// Cleanup removes whole sessions under root: first those untouched for
+// longer than the age cap, then the oldest ones until the rest fit in the
+// size cap. The directory keep, normally Current().Dir(), is never removed.
+// It returns how many sessions were removed and the bytes freed.
+func Cleanup(root string, policy Policy, keep string) (int, int64, error) {
+	sessions, err := scan(root)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total int64
+	for _, session := range sessions {
+		total += session.size
+	}
+
+	removed, freed := 0, int64(0)
+	maxAge, maxSize := policy.maxAge(), policy.maxSize()
+	for _, session := range sessions {
+		if filepath.Clean(session.path) == filepath.Clean(keep) {
+			continue
+		}
+		expired := maxAge > 0 && time.Since(session.modified) > maxAge
+		oversize := maxSize > 0 && total > maxSize
+		if !expired && !oversize {
+			continue
+		}
+		if err := os.RemoveAll(session.path); err != nil {
+			return removed, freed, err
+		}
+		removed++
+		freed += session.size
+		total -= session.size
+	}
+	return removed, freed, nil
+}
+
+// Summary lists the session's artifacts with their sizes, followed by the
+// space all sessions under root take
+func Summary(s *Session, root string) (string, error) {
+	list, err := s.List()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if len(list) == 0 {
+		fmt.Fprintf(&b, "No artifacts in this session yet (%s).\n", s.Dir())
+	} else {
+		var total int64
+		fmt.Fprintf(&b, "Artifacts in %s:\n", s.Dir())
+		for _, artifact := range list {
+			fmt.Fprintf(&b, "  %-40s %10s\n", artifact.Name, FormatSize(artifact.Size))
+			total += artifact.Size
+		}
+		fmt.Fprintf(&b, "  %d files, %s\n", len(list), FormatSize(total))
+	}
+	if sessions, size, err := Usage(root); err == nil && sessions > 0 {
+		fmt.Fprintf(&b, "All sessions: %d, %s", sessions, FormatSize(size))
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// FormatSize renders a byte count for people, e.g. "1.5 MB"
+func FormatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,121 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveAndList(t *testing.T) {
+	session := NewSession(t.TempDir())
+	if list, err := session.List(); err != nil || len(list) != 0 {
+		t.Fatalf("new session: %v, %v", list, err)
+	}
+
+	first, err := session.Save("../../report.txt", []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(first) != session.Dir() || filepath.Base(first) != "report.txt" {
+		t.Errorf("saved to %s, want report.txt in the session directory", first)
+	}
+	second, err := session.Save("report.txt", []byte("again!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(second) != "report-2.txt" {
+		t.Errorf("second save = %s, want report-2.txt", filepath.Base(second))
+	}
+
+	list, err := session.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Size+list[1].Size != 11 {
+		t.Errorf("list = %+v", list)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Policy
+	}{
+		{"", Policy{}},
+		{"days=7", Policy{MaxAgeDays: 7}},
+		{"days=7 mb=100", Policy{MaxAgeDays: 7, MaxSizeMB: 100}},
+		{"mb=off", Policy{MaxSizeMB: -1}},
+		{"off", Policy{MaxAgeDays: -1, MaxSizeMB: -1}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePolicy(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePolicy(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+		if again, _ := ParsePolicy(got.String()); again.maxAge() != got.maxAge() || again.maxSize() != got.maxSize() {
+			t.Errorf("%q did not survive String(): %q", tt.in, got.String())
+		}
+	}
+	for _, bad := range []string{"days", "days=0", "weeks=2", "mb=-5"} {
+		if _, err := ParsePolicy(bad); err == nil {
+			t.Errorf("ParsePolicy(%q) accepted", bad)
+		}
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	root := t.TempDir()
+	write := func(session string, size int, age time.Duration) {
+		dir := filepath.Join(root, session)
+		os.MkdirAll(dir, 0700)
+		path := filepath.Join(dir, "file")
+		os.WriteFile(path, make([]byte, size), 0600)
+		when := time.Now().Add(-age)
+		os.Chtimes(path, when, when)
+		os.Chtimes(dir, when, when)
+	}
+	write("old", 10, 40*24*time.Hour)
+	write("big", 2<<20, 2*time.Hour)
+	write("recent", 1<<20, time.Hour)
+	write("current", 1<<20, 0)
+
+	removed, freed, err := Cleanup(root, Policy{MaxSizeMB: 2}, filepath.Join(root, "current"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 || freed != 10+2<<20 {
+		t.Errorf("removed %d sessions, %d bytes", removed, freed)
+	}
+	for session, kept := range map[string]bool{"old": false, "big": false, "recent": true, "current": true} {
+		if _, err := os.Stat(filepath.Join(root, session)); (err == nil) != kept {
+			t.Errorf("%s kept = %v, want %v", session, err == nil, kept)
+		}
+	}
+
+	if removed, _, _ := Cleanup(root, Policy{MaxAgeDays: -1, MaxSizeMB: -1}, ""); removed != 0 {
+		t.Errorf("disabled policy removed %d sessions", removed)
+	}
+	if n, size, _ := Usage(root); n != 2 || size != 2<<20 {
+		t.Errorf("usage = %d sessions, %d bytes", n, size)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	root := t.TempDir()
+	session := NewSession(root)
+	if text, _ := Summary(session, root); !strings.Contains(text, "No artifacts") {
+		t.Errorf("empty summary = %q", text)
+	}
+	session.Save("notes.md", make([]byte, 2048))
+	text, err := Summary(session, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"notes.md", "2.0 KB", "1 files", "All sessions: 1"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("summary %q is missing %q", text, want)
+		}
+	}
+}
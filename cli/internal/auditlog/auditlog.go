@@ -0,0 +1,376 @@
+// Package auditlog writes chat completions, tool runs and policy violations
+// as JSON lines for SIEM ingestion. It is enabled by HACKARE_AUDIT_SINK:
+//
+//	/var/log/hacka.re/audit.jsonl   append to a file (also file:///path)
+//	tcp://siem.corp.lan:5170        newline-delimited JSON over TCP
+//	syslog://siem.corp.lan:514      RFC 5424 syslog over UDP, JSON as the message
+//	syslog+tcp://siem.corp.lan:601  RFC 5424 syslog over TCP, one message per line
+//
+// Every event has the same flat shape (see Event) so it can be indexed without
+// per-type parsing. Message text and tool arguments are only included when
+// HACKARE_AUDIT_CONTENT is set.
+package auditlog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+)
+
+// Event types, the same as the webhook events
+const (
+	EventMessageCompleted = "message.completed"
+	EventToolExecuted     = "tool.executed"
+	EventPolicyViolation  = "policy.violation"
+)
+
+const (
+	queueSize       = 1024
+	dialTimeout     = 5 * time.Second
+	shutdownTimeout = 5 * time.Second
+)
+
+// Event is one normalized audit record. Fields that don't apply to an event
+// type are left out of the JSON.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"event"`
+	Session   string    `json:"session"` // Random per process, to correlate events
+	Host      string    `json:"host"`
+	User      string    `json:"user"`
+	Namespace string    `json:"namespace,omitempty"`
+
+	Provider         string `json:"provider,omitempty"`
+	Model            string `json:"model,omitempty"`
+	PromptTokens     int    `json:"promptTokens,omitempty"`
+	CompletionTokens int    `json:"completionTokens,omitempty"`
+	FinishReason     string `json:"finishReason,omitempty"`
+	ToolCalls        int    `json:"toolCalls,omitempty"`
+	Seed             int    `json:"seed,omitempty"` // Sampling seed sent with the request
+
+	Tool        string `json:"tool,omitempty"`
+	ToolRuntime string `json:"toolRuntime,omitempty"` // "js" or "mcp"
+
+	Policy string `json:"policy,omitempty"`
+	URL    string `json:"url,omitempty"`
+
+	Status     string `json:"status,omitempty"` // "ok" or "error"
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs,omitempty"`
+
+	Content   string      `json:"content,omitempty"`
+	Arguments interface{} `json:"arguments,omitempty"`
+}
+
+// sink is where encoded events are written, one line per call
+type sink interface {
+	write(line []byte) error
+	close() error
+}
+
+// writer encodes events and writes them to the sink in the background, so
+// chat and tool calls never wait on the SIEM
+type writer struct {
+	sink           sink
+	spec           string
+	includeContent bool
+	session        string
+	host           string
+	user           string
+
+	mu     sync.RWMutex // Guards closing the queue against Emit
+	closed bool
+	queue  chan Event
+	done   chan struct{}
+}
+
+// active is the configured writer, or nil when audit logging is disabled
+var active *writer
+
+// InitFromEnv enables audit logging if HACKARE_AUDIT_SINK is set. In offline
+// mode, network sinks outside this machine and private networks are refused.
+func InitFromEnv(offline bool) error {
+	spec := os.Getenv("HACKARE_AUDIT_SINK")
+	if spec == "" {
+		return nil
+	}
+	if offline && !IsLocal(spec) {
+		return fmt.Errorf("audit sink %s is not on a local or private network; disabled in offline mode", spec)
+	}
+	content := os.Getenv("HACKARE_AUDIT_CONTENT")
+	return Init(spec, content != "" && content != "0" && content != "false")
+}
+
+// Init enables audit logging to the sink described by spec
+func Init(spec string, includeContent bool) error {
+	s, err := openSink(spec)
+	if err != nil {
+		return err
+	}
+
+	host, _ := os.Hostname()
+	w := &writer{
+		sink:           s,
+		spec:           spec,
+		includeContent: includeContent,
+		session:        newSessionID(),
+		host:           host,
+		user:           currentUser(),
+		queue:          make(chan Event, queueSize),
+		done:           make(chan struct{}),
+	}
+	go w.run()
+	active = w
+	logger.Get().Info("[Audit] Writing events to %s", spec)
+	return nil
+}
+
+// Enabled reports whether audit logging is configured
+func Enabled() bool {
+	return active != nil
+}
+
+// Emit records an event, filling in the time, session, host and user. Content
+// and arguments are dropped unless content logging is on. It never blocks;
+// events are dropped if the sink falls too far behind.
+func Emit(event Event) {
+	w := active
+	if w == nil {
+		return
+	}
+
+	event.Time = time.Now().UTC()
+	event.Session = w.session
+	event.Host = w.host
+	event.User = w.user
+	if !w.includeContent {
+		event.Content = ""
+		event.Arguments = nil
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.queue <- event:
+	default:
+		logger.Get().Warn("[Audit] Queue full, dropped a %s event", event.Type)
+	}
+}
+
+// EmitToolExecuted records a JS function or MCP tool run
+func EmitToolExecuted(name, runtime string, start time.Time, args interface{}, err error) {
+	if active == nil {
+		return
+	}
+	event := Event{
+		Type:        EventToolExecuted,
+		Tool:        name,
+		ToolRuntime: runtime,
+		Status:      "ok",
+		DurationMs:  time.Since(start).Milliseconds(),
+		Arguments:   args,
+	}
+	if err != nil {
+		event.Status = "error"
+		event.Error = err.Error()
+	}
+	Emit(event)
+}
+
+// Shutdown writes the queued events and closes the sink
+func Shutdown() {
+	w := active
+	if w == nil {
+		return
+	}
+	active = nil
+	w.mu.Lock()
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+	select {
+	case <-w.done:
+	case <-time.After(shutdownTimeout):
+		logger.Get().Warn("[Audit] Gave up waiting for pending events")
+	}
+}
+
+// run writes queued events until Shutdown closes the queue
+func (w *writer) run() {
+	defer close(w.done)
+	defer w.sink.close()
+	for event := range w.queue {
+		line, err := json.Marshal(event)
+		if err != nil {
+			logger.Get().Warn("[Audit] Failed to encode %s event: %v", event.Type, err)
+			continue
+		}
+		if err := w.sink.write(line); err != nil {
+			logger.Get().Warn("[Audit] Failed to write to %s: %v", w.spec, err)
+		}
+	}
+}
+
+// openSink parses a HACKARE_AUDIT_SINK value
+func openSink(spec string) (sink, error) {
+	if !strings.Contains(spec, "://") {
+		return openFileSink(spec)
+	}
+	parsed, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit sink %q: %w", spec, err)
+	}
+
+	switch parsed.Scheme {
+	case "file":
+		return openFileSink(parsed.Path)
+	case "tcp":
+		return &netSink{network: "tcp", addr: parsed.Host}, nil
+	case "syslog", "syslog+udp":
+		return newSyslogSink("udp", parsed.Host), nil
+	case "syslog+tcp":
+		return newSyslogSink("tcp", parsed.Host), nil
+	default:
+		return nil, fmt.Errorf("unsupported audit sink %q (use a path, file://, tcp://, syslog:// or syslog+tcp://)", spec)
+	}
+}
+
+// IsLocal reports whether spec writes to a file, this machine or a private network
+func IsLocal(spec string) bool {
+	if !strings.Contains(spec, "://") || strings.HasPrefix(spec, "file://") {
+		return true
+	}
+	parsed, err := url.Parse(spec)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate())
+}
+
+// fileSink appends lines to a file
+type fileSink struct {
+	file *os.File
+}
+
+func openFileSink(path string) (*fileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &fileSink{file: file}, nil
+}
+
+func (s *fileSink) write(line []byte) error {
+	_, err := s.file.Write(append(line, '\n'))
+	return err
+}
+
+func (s *fileSink) close() error {
+	return s.file.Close()
+}
+
+// netSink writes lines to a TCP or UDP endpoint, dialing lazily and
+// redialing once when a write fails
+type netSink struct {
+	network string
+	addr    string
+	frame   func(line []byte) []byte // Wraps each line, e.g. in a syslog header
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (s *netSink) write(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.frame != nil {
+		line = s.frame(line)
+	}
+	if s.network == "tcp" {
+		line = append(line, '\n')
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if s.conn, err = net.DialTimeout(s.network, s.addr, dialTimeout); err != nil {
+				return err
+			}
+		}
+		if _, err = s.conn.Write(line); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+func (s *netSink) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// syslogPriority is facility local0 (16) at severity informational (6)
+const syslogPriority = 16*8 + 6
+
+// newSyslogSink frames each event as an RFC 5424 message
+func newSyslogSink(network, addr string) *netSink {
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "-"
+	}
+	pid := os.Getpid()
+	return &netSink{
+		network: network,
+		addr:    addr,
+		frame: func(line []byte) []byte {
+			header := fmt.Sprintf("<%d>1 %s %s hacka.re %d - - ",
+				syslogPriority, time.Now().UTC().Format(time.RFC3339Nano), host, pid)
+			return append([]byte(header), line...)
+		},
+	}
+}
+
+// currentUser returns HACKARE_AUDIT_USER, or else the login name
+func currentUser() string {
+	if name := os.Getenv("HACKARE_AUDIT_USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// newSessionID returns a random id for this process
+func newSessionID() string {
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,146 @@
+package auditlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSinkWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	t.Setenv("HACKARE_AUDIT_USER", "alice")
+	if err := Init(path, false); err != nil {
+		t.Fatal(err)
+	}
+
+	Emit(Event{
+		Type:         EventMessageCompleted,
+		Namespace:    "red-team",
+		Model:        "gpt-4o",
+		PromptTokens: 120,
+		Content:      "secret reply",
+	})
+	EmitToolExecuted("lookup", "js", time.Now(), map[string]string{"q": "x"}, errors.New("boom"))
+	Shutdown()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), data)
+	}
+
+	var message, tool map[string]interface{}
+	json.Unmarshal([]byte(lines[0]), &message)
+	json.Unmarshal([]byte(lines[1]), &tool)
+
+	if message["event"] != EventMessageCompleted || message["user"] != "alice" ||
+		message["namespace"] != "red-team" || message["promptTokens"] != 120.0 {
+		t.Errorf("message event = %v", message)
+	}
+	if _, ok := message["content"]; ok {
+		t.Error("content was logged without HACKARE_AUDIT_CONTENT")
+	}
+	if tool["tool"] != "lookup" || tool["status"] != "error" || tool["error"] != "boom" {
+		t.Errorf("tool event = %v", tool)
+	}
+	if _, ok := tool["arguments"]; ok {
+		t.Error("arguments were logged without HACKARE_AUDIT_CONTENT")
+	}
+	if message["session"] == "" || message["session"] != tool["session"] {
+		t.Errorf("sessions %v and %v should match", message["session"], tool["session"])
+	}
+}
+
+func TestIncludeContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := Init(path, true); err != nil {
+		t.Fatal(err)
+	}
+	Emit(Event{Type: EventMessageCompleted, Content: "hello"})
+	Shutdown()
+
+	data, _ := os.ReadFile(path)
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Content != "hello" {
+		t.Errorf("content = %q, want hello", event.Content)
+	}
+}
+
+func TestSyslogTCPSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	if err := Init("syslog+tcp://"+listener.Addr().String(), false); err != nil {
+		t.Fatal(err)
+	}
+	Emit(Event{Type: EventPolicyViolation, Policy: "offline", URL: "https://api.openai.com"})
+	Shutdown()
+
+	select {
+	case line := <-received:
+		if !strings.HasPrefix(line, "<134>1 ") || !strings.Contains(line, " hacka.re ") {
+			t.Errorf("missing RFC 5424 header: %q", line)
+		}
+		body := line[strings.Index(line, "{"):]
+		var event Event
+		if err := json.Unmarshal([]byte(body), &event); err != nil {
+			t.Fatalf("message is not JSON: %v", err)
+		}
+		if event.Type != EventPolicyViolation || event.Policy != "offline" {
+			t.Errorf("event = %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no syslog message received")
+	}
+}
+
+func TestOpenSinkRejectsUnknownScheme(t *testing.T) {
+	if _, err := openSink("kafka://broker:9092"); err == nil {
+		t.Error("expected an error for an unsupported scheme")
+	}
+}
+
+func TestIsLocal(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{"/var/log/audit.jsonl", true},
+		{"file:///var/log/audit.jsonl", true},
+		{"tcp://localhost:5170", true},
+		{"syslog://10.0.0.5:514", true},
+		{"syslog+tcp://siem.example.com:601", false},
+		{"tcp://203.0.113.7:5170", false},
+	}
+	for _, tt := range tests {
+		if got := IsLocal(tt.spec); got != tt.want {
+			t.Errorf("IsLocal(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,478 @@
+// Package bridge connects a hacka.re configuration to a Slack or Discord
+// channel. Each new message in the channel starts a thread with its own chat
+// session; replies in the thread continue that session. Tool calls are posted
+// for approval and only run once someone reacts with ✅ (❌ declines).
+//
+// Platforms are polled over their REST APIs, so a bot token is all that is
+// needed: no public URL, websocket or app manifest.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/toolpolicy"
+)
+
+// Defaults for Options fields left zero
+const (
+	DefaultPollInterval    = 3 * time.Second
+	DefaultApprovalTimeout = 5 * time.Minute
+	DefaultUserRate        = 6 // Messages per user per minute
+	DefaultMaxHistory      = 40
+	maxToolRounds          = 5
+)
+
+// Message is a chat message read from a platform
+type Message struct {
+	ID       string
+	ThreadID string // Empty for a top-level channel message
+	UserID   string
+	Text     string
+}
+
+// Reaction is an emoji reaction by one user. Emoji is the platform's name for
+// it: "white_check_mark" on Slack, "✅" on Discord.
+type Reaction struct {
+	Emoji  string
+	UserID string
+}
+
+// Platform is a chat service the bridge reads from and posts to
+type Platform interface {
+	Name() string
+
+	// Connect checks the token and starts reading from now; earlier messages are ignored
+	Connect(ctx context.Context) error
+
+	// Poll returns new messages from other users in the channel and in watched threads
+	Poll(ctx context.Context) ([]Message, error)
+
+	// StartThread opens a thread for a top-level message and watches it for replies
+	StartThread(ctx context.Context, msg Message) (threadID string, err error)
+
+	// Post sends text to a thread and returns the new message's ID
+	Post(ctx context.Context, threadID, text string) (messageID string, err error)
+
+	// Reactions lists the reactions on a message in a thread
+	Reactions(ctx context.Context, threadID, messageID string) ([]Reaction, error)
+
+	// MaxMessageLength is the longest text Post accepts
+	MaxMessageLength() int
+}
+
+// Completer sends chat completions; *api.Client implements it
+type Completer interface {
+	SendChatCompletionContext(ctx context.Context, messages []api.Message, streamCallback api.StreamCallback) (*api.ChatResponse, error)
+}
+
+// ToolExecutor runs tools the model calls; *jsruntime.Registry implements it
+type ToolExecutor interface {
+	Execute(name string, args map[string]interface{}) (interface{}, error)
+}
+
+// Options configure a bridge
+type Options struct {
+	SystemPrompt    string
+	PollInterval    time.Duration
+	ApprovalTimeout time.Duration
+	UserRate        int                 // Messages per user per minute; negative disables the limit
+	MaxHistory      int                 // Messages kept per thread, excluding the system prompt
+	Approvers       []string            // User IDs allowed to approve tools; empty allows anyone but the requester
+	AutoApprove     bool                // Run tools without asking (yolo mode)
+	ToolLimiter     *toolpolicy.Limiter // Applies the tool policy; nil only caps the output
+}
+
+// Bridge relays messages between a platform and a chat model
+type Bridge struct {
+	platform Platform
+	client   Completer
+	tools    ToolExecutor
+	opts     Options
+	limiter  *rateLimiter
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	wg       sync.WaitGroup
+}
+
+// session is the conversation in one thread; mu serialises its replies
+type session struct {
+	mu       sync.Mutex
+	messages []api.Message
+}
+
+// New creates a bridge; tools may be nil when no functions are configured
+func New(platform Platform, client Completer, tools ToolExecutor, opts Options) *Bridge {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.ApprovalTimeout <= 0 {
+		opts.ApprovalTimeout = DefaultApprovalTimeout
+	}
+	if opts.UserRate == 0 {
+		opts.UserRate = DefaultUserRate
+	}
+	if opts.MaxHistory <= 0 {
+		opts.MaxHistory = DefaultMaxHistory
+	}
+	return &Bridge{
+		platform: platform,
+		client:   client,
+		tools:    tools,
+		opts:     opts,
+		limiter:  newRateLimiter(opts.UserRate, time.Minute),
+		sessions: make(map[string]*session),
+	}
+}
+
+// Run relays messages until ctx is cancelled, then waits for replies in progress
+func (b *Bridge) Run(ctx context.Context) error {
+	if err := b.platform.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", b.platform.Name(), err)
+	}
+	logger.Get().Info("[Bridge] Connected to %s", b.platform.Name())
+	defer b.wg.Wait()
+
+	ticker := time.NewTicker(b.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		messages, err := b.platform.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Get().Warn("[Bridge] Poll failed: %v", err)
+		}
+		for _, msg := range messages {
+			b.handle(ctx, msg)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// handle routes a message to its thread's session, starting one for top-level messages
+func (b *Bridge) handle(ctx context.Context, msg Message) {
+	text := strings.TrimSpace(msg.Text)
+	if text == "" {
+		return
+	}
+
+	if !b.limiter.Allow(msg.UserID) {
+		logger.Get().Info("[Bridge] Rate limited user %s", msg.UserID)
+		if msg.ThreadID != "" && b.limiter.FirstDenial(msg.UserID) {
+			b.post(ctx, msg.ThreadID, fmt.Sprintf("You're sending messages too quickly (limit %d per minute). Please wait a moment.", b.opts.UserRate))
+		}
+		return
+	}
+
+	threadID := msg.ThreadID
+	if threadID == "" {
+		var err error
+		if threadID, err = b.platform.StartThread(ctx, msg); err != nil {
+			logger.Get().Warn("[Bridge] Failed to start thread for %s: %v", msg.ID, err)
+			return
+		}
+	}
+
+	s := b.session(threadID)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		b.reply(ctx, threadID, s, msg.UserID, text)
+	}()
+}
+
+// session returns the thread's session, creating it on first use
+func (b *Bridge) session(threadID string) *session {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.sessions[threadID]
+	if !ok {
+		s = &session{}
+		if b.opts.SystemPrompt != "" {
+			s.messages = append(s.messages, api.Message{Role: "system", Content: b.opts.SystemPrompt})
+		}
+		b.sessions[threadID] = s
+	}
+	return s
+}
+
+// reply sends the conversation to the model, running approved tools, and posts
+// the answer to requester's message
+func (b *Bridge) reply(ctx context.Context, threadID string, s *session, requester, text string) {
+	s.messages = append(s.messages, api.Message{Role: "user", Content: text})
+
+	for round := 0; round < maxToolRounds; round++ {
+		response, err := b.client.SendChatCompletionContext(ctx, b.history(s), nil)
+		if err != nil {
+			logger.Get().Error("[Bridge] Completion failed: %v", err)
+			b.post(ctx, threadID, "Sorry, the request failed: "+err.Error())
+			return
+		}
+		if len(response.Choices) == 0 {
+			b.post(ctx, threadID, "The model returned no answer.")
+			return
+		}
+
+		message := response.Choices[0].Message
+		message.Role = "assistant"
+		s.messages = append(s.messages, message)
+
+		if len(message.ToolCalls) == 0 {
+			if strings.TrimSpace(message.Content) == "" {
+				message.Content = "(empty response)"
+			}
+			b.post(ctx, threadID, message.Content)
+			return
+		}
+
+		for _, call := range message.ToolCalls {
+			s.messages = append(s.messages, api.Message{
+				Role:       "tool",
+				ToolCallID: call.ID,
+				Content:    b.runTool(ctx, threadID, requester, call),
+			})
+		}
+	}
+	b.post(ctx, threadID, fmt.Sprintf("Stopped after %d rounds of tool calls.", maxToolRounds))
+}
+
+// history returns the system prompt and the most recent MaxHistory messages,
+// never starting on a tool result whose call was trimmed
+func (b *Bridge) history(s *session) []api.Message {
+	var system []api.Message
+	messages := s.messages
+	if len(messages) > 0 && messages[0].Role == "system" {
+		system, messages = messages[:1], messages[1:]
+	}
+	if len(messages) > b.opts.MaxHistory {
+		messages = messages[len(messages)-b.opts.MaxHistory:]
+		for len(messages) > 0 && messages[0].Role == "tool" {
+			messages = messages[1:]
+		}
+	}
+	return append(append([]api.Message(nil), system...), messages...)
+}
+
+// runTool asks for approval and runs one tool call, returning the result for the model
+func (b *Bridge) runTool(ctx context.Context, threadID, requester string, call api.ToolCall) string {
+	name := call.Function.Name
+	if b.tools == nil {
+		return fmt.Sprintf("Error: tool %s is not available", name)
+	}
+
+	var args map[string]interface{}
+	if call.Function.Arguments != "" {
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			return fmt.Sprintf("Error: invalid arguments for %s: %v", name, err)
+		}
+	}
+
+	if !b.opts.AutoApprove {
+		approved, err := b.requestApproval(ctx, threadID, requester, name, call.Function.Arguments)
+		if err != nil {
+			return fmt.Sprintf("Error: approval for %s failed: %v", name, err)
+		}
+		if !approved {
+			return fmt.Sprintf("The user declined to run %s.", name)
+		}
+	}
+
+	release, err := b.opts.ToolLimiter.Acquire(ctx, name, func(reason string) {
+		b.post(ctx, threadID, fmt.Sprintf("Tool `%s` is queued: %s.", name, reason))
+	})
+	if err != nil {
+		b.post(ctx, threadID, fmt.Sprintf("Tool `%s` did not run: %v", name, err))
+		return fmt.Sprintf("Error: %v", err)
+	}
+	limit := b.opts.ToolLimiter.Limit(name)
+	result, err := toolpolicy.Run(ctx, limit.Timeout(), func() (interface{}, error) {
+		defer release()
+		return b.tools.Execute(name, args)
+	})
+	if err != nil {
+		b.post(ctx, threadID, fmt.Sprintf("Tool `%s` failed: %v", name, err))
+		return fmt.Sprintf("Error: %v", err)
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return toolpolicy.Truncate(fmt.Sprint(result), limit.MaxOutput())
+	}
+	return toolpolicy.Truncate(string(encoded), limit.MaxOutput())
+}
+
+// requestApproval posts the tool call and waits for an approving or declining reaction
+func (b *Bridge) requestApproval(ctx context.Context, threadID, requester, name, arguments string) (bool, error) {
+	prompt := fmt.Sprintf("The assistant wants to run `%s` with:\n```\n%s\n```\nReact with ✅ to approve or ❌ to decline (expires in %s).",
+		name, truncate(arguments, 1000), b.opts.ApprovalTimeout)
+	messageID, err := b.platform.Post(ctx, threadID, prompt)
+	if err != nil {
+		return false, err
+	}
+
+	deadline := time.Now().Add(b.opts.ApprovalTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(b.opts.PollInterval):
+		}
+
+		reactions, err := b.platform.Reactions(ctx, threadID, messageID)
+		if err != nil {
+			logger.Get().Warn("[Bridge] Failed to read reactions: %v", err)
+			continue
+		}
+		for _, reaction := range reactions {
+			if !b.mayApprove(reaction.UserID, requester) {
+				continue
+			}
+			switch {
+			case isDecline(reaction.Emoji):
+				return false, nil
+			case isApprove(reaction.Emoji):
+				return true, nil
+			}
+		}
+	}
+
+	b.post(ctx, threadID, fmt.Sprintf("No approval for `%s`; skipped.", name))
+	return false, nil
+}
+
+// mayApprove reports whether a user may approve or decline a tool call made
+// for requester. Without Approvers, anyone but the requester may.
+func (b *Bridge) mayApprove(userID, requester string) bool {
+	if len(b.opts.Approvers) == 0 {
+		return userID != requester
+	}
+	for _, approver := range b.opts.Approvers {
+		if approver == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// post sends text to a thread, splitting it to fit the platform's limit
+func (b *Bridge) post(ctx context.Context, threadID, text string) {
+	for _, chunk := range splitMessage(text, b.platform.MaxMessageLength()) {
+		if _, err := b.platform.Post(ctx, threadID, chunk); err != nil {
+			logger.Get().Error("[Bridge] Failed to post to %s: %v", threadID, err)
+			return
+		}
+	}
+}
+
+// isApprove matches the approving reactions on Slack and Discord
+func isApprove(emoji string) bool {
+	switch emoji {
+	case "white_check_mark", "heavy_check_mark", "+1", "✅", "✔️", "👍":
+		return true
+	}
+	return false
+}
+
+// isDecline matches the declining reactions on Slack and Discord
+func isDecline(emoji string) bool {
+	switch emoji {
+	case "x", "no_entry", "-1", "❌", "⛔", "👎":
+		return true
+	}
+	return false
+}
+
+// splitMessage breaks text into chunks of at most limit bytes, preferring line breaks
+func splitMessage(text string, limit int) []string {
+	var chunks []string
+	for len(text) > limit {
+		cut := strings.LastIndex(text[:limit], "\n")
+		if cut <= 0 {
+			cut = limit
+			// Don't split a UTF-8 sequence
+			for cut > 0 && text[cut]&0xC0 == 0x80 {
+				cut--
+			}
+		}
+		chunks = append(chunks, text[:cut])
+		text = strings.TrimLeft(text[cut:], "\n")
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
+
+// rateLimiter allows each user a number of messages per sliding window
+type rateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	seen   map[string][]time.Time
+	warned map[string]bool
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+		seen:   make(map[string][]time.Time),
+		warned: make(map[string]bool),
+	}
+}
+
+// Allow records a message from user and reports whether it is within the limit
+func (r *rateLimiter) Allow(user string) bool {
+	if r.limit < 0 {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	recent := r.seen[user][:0]
+	for _, t := range r.seen[user] {
+		if now.Sub(t) < r.window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= r.limit {
+		r.seen[user] = recent
+		return false
+	}
+	r.seen[user] = append(recent, now)
+	r.warned[user] = false
+	return true
+}
+
+// FirstDenial reports whether this is the first rejected message since the user was last allowed,
+// so the bridge warns once instead of replying to every message
+func (r *rateLimiter) FirstDenial(user string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.warned[user] {
+		return false
+	}
+	r.warned[user] = true
+	return true
+}
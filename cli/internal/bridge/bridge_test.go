@@ -0,0 +1,245 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/toolpolicy"
+)
+
+// fakePlatform records posts and serves reactions from a fixed list
+type fakePlatform struct {
+	mu        sync.Mutex
+	posts     []string
+	reactions []Reaction
+}
+
+func (f *fakePlatform) Name() string                                { return "fake" }
+func (f *fakePlatform) Connect(ctx context.Context) error           { return nil }
+func (f *fakePlatform) Poll(ctx context.Context) ([]Message, error) { return nil, nil }
+func (f *fakePlatform) MaxMessageLength() int                       { return 100 }
+func (f *fakePlatform) StartThread(ctx context.Context, msg Message) (string, error) {
+	return "thread-" + msg.ID, nil
+}
+
+func (f *fakePlatform) Post(ctx context.Context, threadID, text string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.posts = append(f.posts, threadID+": "+text)
+	return "posted", nil
+}
+
+func (f *fakePlatform) Reactions(ctx context.Context, threadID, messageID string) ([]Reaction, error) {
+	return f.reactions, nil
+}
+
+// scriptedCompleter answers with a tool call first, then echoes the tool result
+type scriptedCompleter struct{}
+
+func (scriptedCompleter) SendChatCompletionContext(ctx context.Context, messages []api.Message, cb api.StreamCallback) (*api.ChatResponse, error) {
+	last := messages[len(messages)-1]
+
+	reply := api.Message{Role: "assistant"}
+	switch last.Role {
+	case "user":
+		call := api.ToolCall{ID: "call_1", Type: "function"}
+		call.Function.Name = "lookup"
+		call.Function.Arguments = `{"ip":"203.0.113.7"}`
+		reply.ToolCalls = []api.ToolCall{call}
+	case "tool":
+		reply.Content = "result was " + last.Content
+	}
+	return &api.ChatResponse{Choices: []api.Choice{{Message: reply}}}, nil
+}
+
+type fakeTools struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (t *fakeTools) Execute(name string, args map[string]interface{}) (interface{}, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls++
+	return map[string]interface{}{"open": args["ip"]}, nil
+}
+
+func TestBridgeToolApproval(t *testing.T) {
+	tests := []struct {
+		name      string
+		reactions []Reaction
+		approvers []string
+		wantCalls int
+		wantReply string
+	}{
+		{"approved", []Reaction{{"white_check_mark", "U2"}}, nil, 1, `result was {"open":"203.0.113.7"}`},
+		{"declined", []Reaction{{"❌", "U2"}}, nil, 0, "result was The user declined to run lookup."},
+		{"not an approver", []Reaction{{"✅", "U2"}}, []string{"U1"}, 0, "result was The user declined to run lookup."},
+		{"own call", []Reaction{{"✅", "U1"}}, nil, 0, "result was The user declined to run lookup."},
+		{"listed approver's own call", []Reaction{{"✅", "U1"}}, []string{"U1"}, 1, `result was {"open":"203.0.113.7"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			platform := &fakePlatform{reactions: tt.reactions}
+			tools := &fakeTools{}
+			b := New(platform, scriptedCompleter{}, tools, Options{
+				SystemPrompt:    "be brief",
+				PollInterval:    time.Millisecond,
+				ApprovalTimeout: 20 * time.Millisecond,
+				Approvers:       tt.approvers,
+			})
+
+			b.handle(context.Background(), Message{ID: "1", UserID: "U1", Text: "scan it"})
+			b.wg.Wait()
+
+			if tools.calls != tt.wantCalls {
+				t.Errorf("tool calls = %d, want %d", tools.calls, tt.wantCalls)
+			}
+			last := platform.posts[len(platform.posts)-1]
+			if last != "thread-1: "+tt.wantReply {
+				t.Errorf("last post = %q, want reply %q", last, tt.wantReply)
+			}
+		})
+	}
+}
+
+func TestBridgeKeepsSessionsPerThread(t *testing.T) {
+	b := New(&fakePlatform{}, scriptedCompleter{}, &fakeTools{}, Options{AutoApprove: true, PollInterval: time.Millisecond})
+
+	b.handle(context.Background(), Message{ID: "1", UserID: "U1", Text: "first"})
+	b.wg.Wait()
+	b.handle(context.Background(), Message{ID: "2", ThreadID: "thread-1", UserID: "U1", Text: "second"})
+	b.handle(context.Background(), Message{ID: "3", UserID: "U1", Text: "other thread"})
+	b.wg.Wait()
+
+	if len(b.sessions) != 2 {
+		t.Fatalf("sessions = %d, want 2", len(b.sessions))
+	}
+	// user, assistant tool call, tool result, assistant, user, ...
+	if got := len(b.sessions["thread-1"].messages); got != 8 {
+		t.Errorf("thread-1 has %d messages, want 8", got)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(2, time.Minute)
+	if !limiter.Allow("U1") || !limiter.Allow("U1") {
+		t.Fatal("first two messages were limited")
+	}
+	if limiter.Allow("U1") {
+		t.Error("third message within a minute was allowed")
+	}
+	if !limiter.FirstDenial("U1") || limiter.FirstDenial("U1") {
+		t.Error("FirstDenial should be true exactly once")
+	}
+	if !limiter.Allow("U2") {
+		t.Error("limit leaked to another user")
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	text := strings.Repeat("a", 60) + "\n" + strings.Repeat("b", 60)
+	chunks := splitMessage(text, 100)
+	if len(chunks) != 2 || chunks[0] != strings.Repeat("a", 60) || chunks[1] != strings.Repeat("b", 60) {
+		t.Errorf("splitMessage = %q", chunks)
+	}
+
+	for _, chunk := range splitMessage(strings.Repeat("å", 80), 25) {
+		if !strings.HasPrefix(chunk, "å") || len(chunk) > 25 {
+			t.Errorf("chunk %q splits a character or exceeds the limit", chunk)
+		}
+	}
+}
+
+func TestSlackPoll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		// Like Slack, history excludes "oldest" and replies include it
+		oldest := r.URL.Query().Get("oldest")
+		since := func(messages []map[string]string, inclusive bool) []map[string]string {
+			var kept []map[string]string
+			for _, m := range messages {
+				if m["ts"] > oldest || (inclusive && m["ts"] == oldest) {
+					kept = append(kept, m)
+				}
+			}
+			return kept
+		}
+
+		var resp interface{}
+		switch r.URL.Path {
+		case "/conversations.history":
+			resp = map[string]interface{}{"ok": true, "messages": since([]map[string]string{
+				{"ts": "1700000003.000000", "user": "UBOT", "text": "my own reply"},
+				{"ts": "1700000002.000000", "user": "U1", "text": "reply", "thread_ts": "1700000001.000000", "subtype": "thread_broadcast"},
+				{"ts": "1700000001.000000", "user": "U1", "text": "hello"},
+			}, false)}
+		case "/conversations.replies":
+			resp = map[string]interface{}{"ok": true, "messages": since([]map[string]string{
+				{"ts": "1700000001.000000", "user": "U1", "text": "hello"},
+				{"ts": "1700000002.000000", "user": "U1", "text": "reply", "thread_ts": "1700000001.000000"},
+			}, true)}
+		default:
+			resp = map[string]interface{}{"ok": false, "error": "unknown_method"}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	slack := NewSlack("xoxb-test", "C1")
+	slack.api.baseURL = server.URL + "/"
+	slack.botUser = "UBOT"
+	slack.cursor = "1700000000.000000"
+
+	messages, err := slack.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].Text != "hello" || messages[0].ThreadID != "" {
+		t.Fatalf("first poll = %+v, want only the top-level hello", messages)
+	}
+
+	slack.StartThread(context.Background(), messages[0])
+	messages, err = slack.Poll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].Text != "reply" || messages[0].ThreadID != "1700000001.000000" {
+		t.Errorf("second poll = %+v, want the thread reply", messages)
+	}
+}
+
+func TestBridgeToolLimit(t *testing.T) {
+	limiter := toolpolicy.NewLimiter(&toolpolicy.Policy{Tools: map[string]toolpolicy.Limit{
+		"lookup": {MaxConcurrent: 1, MaxWaitSeconds: 1},
+	}})
+	release, err := limiter.Acquire(context.Background(), "lookup", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	platform := &fakePlatform{}
+	tools := &fakeTools{}
+	b := New(platform, scriptedCompleter{}, tools, Options{AutoApprove: true, PollInterval: time.Millisecond, ToolLimiter: limiter})
+	b.handle(context.Background(), Message{ID: "1", UserID: "U1", Text: "scan it"})
+	b.wg.Wait()
+
+	if tools.calls != 0 {
+		t.Errorf("tool calls = %d, want 0", tools.calls)
+	}
+	posts := strings.Join(platform.posts, "\n")
+	if !strings.Contains(posts, "Tool `lookup` is queued: 1 lookup call(s) already running") || !strings.Contains(posts, "Tool `lookup` did not run") {
+		t.Errorf("posts = %s", posts)
+	}
+}
@@ -0,0 +1,224 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// discordEpoch is the first millisecond of 2015, where Discord IDs start counting
+const discordEpoch = 1420070400000
+
+// discordReactionEmojis are the reactions read for tool approval
+var discordReactionEmojis = []string{"✅", "👍", "❌", "👎"}
+
+// Discord bridges a text channel using a bot token. The bot needs the Read
+// Message History, Send Messages, Create Public Threads and Send Messages in
+// Threads permissions, and the Message Content intent enabled.
+type Discord struct {
+	channel string
+	api     *restClient
+
+	mu      sync.Mutex
+	botUser string
+	cursor  string            // ID of the newest channel message seen
+	threads map[string]string // Thread channel ID -> newest message ID seen
+}
+
+// NewDiscord creates a Discord platform for the channel ID
+func NewDiscord(token, channel string) *Discord {
+	return &Discord{
+		channel: channel,
+		api:     newRESTClient("https://discord.com/api/v10", "Bot "+token),
+		threads: make(map[string]string),
+	}
+}
+
+// Name implements Platform
+func (d *Discord) Name() string {
+	return "Discord"
+}
+
+// MaxMessageLength implements Platform
+func (d *Discord) MaxMessageLength() int {
+	return 2000
+}
+
+type discordMessage struct {
+	ID      string `json:"id"`
+	Type    int    `json:"type"`
+	Content string `json:"content"`
+	Author  struct {
+		ID  string `json:"id"`
+		Bot bool   `json:"bot"`
+	} `json:"author"`
+}
+
+// Connect implements Platform
+func (d *Discord) Connect(ctx context.Context) error {
+	var me struct {
+		ID string `json:"id"`
+	}
+	if err := d.api.do(ctx, "GET", "/users/@me", nil, &me); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.botUser = me.ID
+	d.cursor = discordIDAt(time.Now())
+	d.mu.Unlock()
+	return nil
+}
+
+// Poll implements Platform
+func (d *Discord) Poll(ctx context.Context) ([]Message, error) {
+	d.mu.Lock()
+	cursor := d.cursor
+	threads := make(map[string]string, len(d.threads))
+	for thread, last := range d.threads {
+		threads[thread] = last
+	}
+	d.mu.Unlock()
+
+	var messages []Message
+	var errs []error
+
+	top, err := d.messagesAfter(ctx, d.channel, cursor)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, m := range top {
+		cursor = maxID(cursor, m.ID)
+		if d.fromUser(m) {
+			messages = append(messages, Message{ID: m.ID, UserID: m.Author.ID, Text: m.Content})
+		}
+	}
+
+	for thread, last := range threads {
+		replies, err := d.messagesAfter(ctx, thread, last)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, m := range replies {
+			threads[thread] = maxID(threads[thread], m.ID)
+			if d.fromUser(m) {
+				messages = append(messages, Message{ID: m.ID, ThreadID: thread, UserID: m.Author.ID, Text: m.Content})
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.cursor = cursor
+	for thread, last := range threads {
+		d.threads[thread] = maxID(d.threads[thread], last)
+	}
+	d.mu.Unlock()
+
+	sort.SliceStable(messages, func(i, j int) bool { return lessID(messages[i].ID, messages[j].ID) })
+	return messages, errors.Join(errs...)
+}
+
+// messagesAfter lists up to 100 messages in a channel or thread newer than after
+func (d *Discord) messagesAfter(ctx context.Context, channel, after string) ([]discordMessage, error) {
+	query := url.Values{"after": {after}, "limit": {"100"}}
+	var messages []discordMessage
+	if err := d.api.do(ctx, "GET", "/channels/"+channel+"/messages?"+query.Encode(), nil, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// fromUser reports whether a message is a normal message or reply from a person
+func (d *Discord) fromUser(m discordMessage) bool {
+	return !m.Author.Bot && m.Author.ID != d.botUser && (m.Type == 0 || m.Type == 19)
+}
+
+// StartThread implements Platform by opening a public thread on the message
+func (d *Discord) StartThread(ctx context.Context, msg Message) (string, error) {
+	name := strings.Join(strings.Fields(msg.Text), " ")
+	if len([]rune(name)) > 90 {
+		name = string([]rune(name)[:90])
+	}
+	if name == "" {
+		name = "hacka.re"
+	}
+
+	var thread struct {
+		ID string `json:"id"`
+	}
+	body := map[string]interface{}{"name": name, "auto_archive_duration": 1440}
+	path := fmt.Sprintf("/channels/%s/messages/%s/threads", d.channel, msg.ID)
+	if err := d.api.do(ctx, "POST", path, body, &thread); err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	d.threads[thread.ID] = msg.ID
+	d.mu.Unlock()
+	return thread.ID, nil
+}
+
+// Post implements Platform
+func (d *Discord) Post(ctx context.Context, threadID, text string) (string, error) {
+	var posted discordMessage
+	body := map[string]interface{}{
+		"content":          text,
+		"allowed_mentions": map[string]interface{}{"parse": []string{}}, // Never ping from model output
+	}
+	if err := d.api.do(ctx, "POST", "/channels/"+threadID+"/messages", body, &posted); err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	d.threads[threadID] = maxID(d.threads[threadID], posted.ID)
+	d.mu.Unlock()
+	return posted.ID, nil
+}
+
+// Reactions implements Platform
+func (d *Discord) Reactions(ctx context.Context, threadID, messageID string) ([]Reaction, error) {
+	var reactions []Reaction
+	for _, emoji := range discordReactionEmojis {
+		var users []struct {
+			ID string `json:"id"`
+		}
+		path := fmt.Sprintf("/channels/%s/messages/%s/reactions/%s", threadID, messageID, url.PathEscape(emoji))
+		if err := d.api.do(ctx, "GET", path, nil, &users); err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			if user.ID != d.botUser {
+				reactions = append(reactions, Reaction{Emoji: emoji, UserID: user.ID})
+			}
+		}
+	}
+	return reactions, nil
+}
+
+// discordIDAt returns the smallest Discord ID created at t
+func discordIDAt(t time.Time) string {
+	return strconv.FormatUint(uint64(t.UnixMilli()-discordEpoch)<<22, 10)
+}
+
+// lessID compares Discord IDs numerically
+func lessID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// maxID returns the later of two Discord IDs
+func maxID(a, b string) string {
+	if lessID(a, b) {
+		return b
+	}
+	return a
+}
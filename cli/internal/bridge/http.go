@@ -0,0 +1,94 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// maxRateLimitRetries bounds how often a request is retried after a 429
+const maxRateLimitRetries = 3
+
+// restClient makes authenticated JSON requests, waiting out platform rate limits
+type restClient struct {
+	baseURL string
+	auth    string // Authorization header value
+	http    *http.Client
+}
+
+func newRESTClient(baseURL, auth string) *restClient {
+	return &restClient{
+		baseURL: baseURL,
+		auth:    auth,
+		http:    netguard.Client(30 * time.Second),
+	}
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into out
+func (c *restClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", c.auth)
+		req.Header.Set("User-Agent", "hacka.re-cli bridge")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRateLimitRetries {
+			wait := retryAfter(resp.Header.Get("Retry-After"))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, truncate(string(data), 200))
+		}
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", path, err)
+		}
+		return nil
+	}
+}
+
+// retryAfter parses a Retry-After header in (possibly fractional) seconds
+func retryAfter(header string) time.Duration {
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 {
+		return time.Second
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,230 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Slack bridges a channel using a bot token (xoxb-...). The bot needs the
+// channels:history (or groups:history), chat:write and reactions:read scopes
+// and must be a member of the channel.
+type Slack struct {
+	channel string
+	api     *restClient
+
+	mu      sync.Mutex
+	botUser string
+	cursor  string            // ts of the newest top-level message seen
+	threads map[string]string // Thread ts -> ts of the newest reply seen
+}
+
+// NewSlack creates a Slack platform for the channel ID (e.g. C0123456789)
+func NewSlack(token, channel string) *Slack {
+	return &Slack{
+		channel: channel,
+		api:     newRESTClient("https://slack.com/api/", "Bearer "+token),
+		threads: make(map[string]string),
+	}
+}
+
+// Name implements Platform
+func (s *Slack) Name() string {
+	return "Slack"
+}
+
+// MaxMessageLength implements Platform; Slack truncates messages over 40,000 characters
+func (s *Slack) MaxMessageLength() int {
+	return 39000
+}
+
+// slackResponse is the envelope of every Slack Web API response
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+func (r slackResponse) err(method string) error {
+	if r.OK {
+		return nil
+	}
+	return fmt.Errorf("slack %s: %s", method, r.Error)
+}
+
+type slackMessage struct {
+	TS       string `json:"ts"`
+	ThreadTS string `json:"thread_ts"`
+	User     string `json:"user"`
+	BotID    string `json:"bot_id"`
+	Subtype  string `json:"subtype"`
+	Text     string `json:"text"`
+}
+
+// Connect implements Platform
+func (s *Slack) Connect(ctx context.Context) error {
+	var auth struct {
+		slackResponse
+		UserID string `json:"user_id"`
+	}
+	if err := s.api.do(ctx, "POST", "auth.test", nil, &auth); err != nil {
+		return err
+	}
+	if err := auth.err("auth.test"); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.botUser = auth.UserID
+	s.cursor = fmt.Sprintf("%d.000000", time.Now().Unix())
+	s.mu.Unlock()
+	return nil
+}
+
+// Poll implements Platform
+func (s *Slack) Poll(ctx context.Context) ([]Message, error) {
+	s.mu.Lock()
+	cursor := s.cursor
+	threads := make(map[string]string, len(s.threads))
+	for thread, last := range s.threads {
+		threads[thread] = last
+	}
+	s.mu.Unlock()
+
+	var messages []Message
+	var errs []error
+
+	top, err := s.history(ctx, "conversations.history", url.Values{"oldest": {cursor}})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, m := range top {
+		if m.ThreadTS != "" && m.ThreadTS != m.TS {
+			continue // A thread reply also sent to the channel; seen in its thread
+		}
+		cursor = maxTS(cursor, m.TS)
+		if s.fromUser(m) {
+			messages = append(messages, Message{ID: m.TS, UserID: m.User, Text: m.Text})
+		}
+	}
+
+	for thread, last := range threads {
+		replies, err := s.history(ctx, "conversations.replies", url.Values{"ts": {thread}, "oldest": {last}})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, m := range replies {
+			if m.TS == thread || m.TS <= last {
+				continue // The parent, or already seen (oldest is inclusive)
+			}
+			threads[thread] = maxTS(threads[thread], m.TS)
+			if s.fromUser(m) {
+				messages = append(messages, Message{ID: m.TS, ThreadID: thread, UserID: m.User, Text: m.Text})
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.cursor = cursor
+	for thread, last := range threads {
+		s.threads[thread] = maxTS(s.threads[thread], last)
+	}
+	s.mu.Unlock()
+
+	sort.SliceStable(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, errors.Join(errs...)
+}
+
+// history reads messages newer than "oldest" from conversations.history or conversations.replies
+func (s *Slack) history(ctx context.Context, method string, query url.Values) ([]slackMessage, error) {
+	query.Set("channel", s.channel)
+	query.Set("limit", "100")
+	var resp struct {
+		slackResponse
+		Messages []slackMessage `json:"messages"`
+	}
+	if err := s.api.do(ctx, "GET", method+"?"+query.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	if err := resp.err(method); err != nil {
+		return nil, err
+	}
+	return resp.Messages, nil
+}
+
+// fromUser reports whether a message was written by a person other than the bot
+func (s *Slack) fromUser(m slackMessage) bool {
+	return m.User != "" && m.User != s.botUser && m.BotID == "" && (m.Subtype == "" || m.Subtype == "thread_broadcast")
+}
+
+// StartThread implements Platform; a Slack thread is identified by its parent's ts
+func (s *Slack) StartThread(ctx context.Context, msg Message) (string, error) {
+	s.mu.Lock()
+	s.threads[msg.ID] = msg.ID
+	s.mu.Unlock()
+	return msg.ID, nil
+}
+
+// Post implements Platform
+func (s *Slack) Post(ctx context.Context, threadID, text string) (string, error) {
+	var resp struct {
+		slackResponse
+		TS string `json:"ts"`
+	}
+	body := map[string]string{"channel": s.channel, "thread_ts": threadID, "text": text}
+	if err := s.api.do(ctx, "POST", "chat.postMessage", body, &resp); err != nil {
+		return "", err
+	}
+	if err := resp.err("chat.postMessage"); err != nil {
+		return "", err
+	}
+
+	// Don't read our own reply back as a new message
+	s.mu.Lock()
+	s.threads[threadID] = maxTS(s.threads[threadID], resp.TS)
+	s.mu.Unlock()
+	return resp.TS, nil
+}
+
+// Reactions implements Platform
+func (s *Slack) Reactions(ctx context.Context, threadID, messageID string) ([]Reaction, error) {
+	var resp struct {
+		slackResponse
+		Message struct {
+			Reactions []struct {
+				Name  string   `json:"name"`
+				Users []string `json:"users"`
+			} `json:"reactions"`
+		} `json:"message"`
+	}
+	query := url.Values{"channel": {s.channel}, "timestamp": {messageID}}
+	if err := s.api.do(ctx, "GET", "reactions.get?"+query.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	if err := resp.err("reactions.get"); err != nil {
+		return nil, err
+	}
+
+	var reactions []Reaction
+	for _, r := range resp.Message.Reactions {
+		for _, user := range r.Users {
+			if user != s.botUser {
+				reactions = append(reactions, Reaction{Emoji: r.Name, UserID: user})
+			}
+		}
+	}
+	return reactions, nil
+}
+
+// maxTS returns the later of two Slack timestamps; they compare as strings
+// because the seconds part has a fixed width
+func maxTS(a, b string) string {
+	if b > a {
+		return b
+	}
+	return a
+}
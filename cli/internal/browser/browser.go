@@ -12,6 +12,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/hacka-re/cli/internal/csp"
+	"github.com/hacka-re/cli/internal/failure"
 	"github.com/hacka-re/cli/internal/share"
 	"github.com/hacka-re/cli/internal/utils"
 	"github.com/hacka-re/cli/internal/web"
@@ -33,6 +35,7 @@ const (
 type BrowserLauncher struct {
 	Type    BrowserType
 	Profile string
+	Command string // Command template for the default browser (see CommandOpener)
 }
 
 // NewBrowserLauncher creates a new browser launcher
@@ -57,6 +60,9 @@ func (bl *BrowserLauncher) Launch(url string) error {
 	case Safari:
 		return bl.launchSafari(url)
 	default:
+		if bl.Command != "" {
+			return CommandOpener{Template: bl.Command}.Open(url)
+		}
 		return OpenDefaultBrowser(url)
 	}
 }
@@ -326,41 +332,20 @@ func (bl *BrowserLauncher) launchSafari(url string) error {
 	return cmd.Start()
 }
 
-// OpenDefaultBrowser opens the default system browser
+// OpenDefaultBrowser opens the default browser, honoring HACKARE_BROWSER and BROWSER
 func OpenDefaultBrowser(url string) error {
-	var cmd *exec.Cmd
-
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "linux":
-		// Try xdg-open first, then fallback to others
-		if _, err := exec.LookPath("xdg-open"); err == nil {
-			cmd = exec.Command("xdg-open", url)
-		} else if _, err := exec.LookPath("gnome-open"); err == nil {
-			cmd = exec.Command("gnome-open", url)
-		} else if _, err := exec.LookPath("kde-open"); err == nil {
-			cmd = exec.Command("kde-open", url)
-		} else {
-			return fmt.Errorf("no suitable browser opener found")
-		}
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", url)
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
-	}
-
-	return cmd.Start()
+	return DefaultOpener().Open(url)
 }
 
 // ServerConfig contains configuration for the web server
 type ServerConfig struct {
-	Host         string
-	Port         int
-	Verbose      int
-	SessionLink  string
-	SessionSource string
-	Password     string
+	Host            string
+	Port            int
+	Verbose         int
+	SessionLink     string
+	SessionSource   string
+	Password        string
+	SecurityHeaders csp.Options // Relaxes the default security headers
 }
 
 // StartServerAndBrowser starts the web server and optionally opens a browser
@@ -402,8 +387,7 @@ func StartServerAndBrowser(config *ServerConfig, launcher *BrowserLauncher) erro
 			// Parse the URL/fragment
 			sharedConfig, err := share.ParseURL(config.SessionLink, password)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error parsing session: %v\n", err)
-				fmt.Println("\nThe password may be incorrect or the link may be corrupted.")
+				fmt.Fprintf(os.Stderr, "Error parsing session: %s\n", failure.Message(err))
 				return err
 			}
 
@@ -432,6 +416,7 @@ func StartServerAndBrowser(config *ServerConfig, launcher *BrowserLauncher) erro
 	if err != nil {
 		return fmt.Errorf("error creating server: %w", err)
 	}
+	server.SetSecurityHeaders(config.SecurityHeaders)
 
 	// Handle interrupt signal for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -446,15 +431,15 @@ func StartServerAndBrowser(config *ServerConfig, launcher *BrowserLauncher) erro
 	// Give server a moment to start
 	time.Sleep(100 * time.Millisecond)
 
-	// Open browser if launcher is provided
-	if launcher != nil {
-		browserURL := server.GetURL()
+	browserURL := server.GetURL()
 
-		// Append fragment if we have a shared configuration
-		if sharedConfigFragment != "" {
-			browserURL = browserURL + "/#" + sharedConfigFragment
-		}
+	// Append fragment if we have a shared configuration
+	if sharedConfigFragment != "" {
+		browserURL = browserURL + "/#" + sharedConfigFragment
+	}
 
+	// Open browser if launcher is provided
+	if launcher != nil {
 		if err := launcher.Launch(browserURL); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Could not open browser automatically: %v\n", err)
 			fmt.Printf("Please open your browser and navigate to: %s\n", browserURL)
@@ -470,7 +455,7 @@ func StartServerAndBrowser(config *ServerConfig, launcher *BrowserLauncher) erro
 			}
 		}
 	} else {
-		fmt.Printf("Server running at: %s\n", server.GetURL())
+		fmt.Printf("Server running at: %s\n", browserURL)
 	}
 
 	// Wait for interrupt or server error
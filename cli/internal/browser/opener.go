@@ -0,0 +1,155 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Opener opens a URL in a browser
+type Opener interface {
+	Open(url string) error
+}
+
+// CommandOpener runs a user-supplied command template such as
+// "firefox --new-window {url}". {url} (or %s) is replaced by the URL, which
+// is appended when the template has no placeholder. Arguments may be quoted.
+type CommandOpener struct {
+	Template string
+}
+
+// Open runs the template with url
+func (o CommandOpener) Open(url string) error {
+	args, err := expandTemplate(o.Template, url)
+	if err != nil {
+		return err
+	}
+	return exec.Command(args[0], args[1:]...).Start()
+}
+
+// SystemOpener opens URLs with the platform's handler: open on macOS, the
+// Windows browser from WSL, xdg-open and its fallbacks on Linux and BSD, and
+// the URL protocol handler on Windows
+type SystemOpener struct{}
+
+// linuxOpeners are tried in order when the first is not installed
+var linuxOpeners = []string{"xdg-open", "gnome-open", "kde-open", "sensible-browser", "x-www-browser"}
+
+// Open opens url in the default browser
+func (SystemOpener) Open(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		// Unlike "cmd /c start", rundll32 passes & and ^ in the URL through untouched
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if IsWSL() {
+			if _, err := exec.LookPath("wslview"); err == nil {
+				return exec.Command("wslview", url).Start()
+			}
+			if _, err := exec.LookPath("rundll32.exe"); err == nil {
+				return exec.Command("rundll32.exe", "url.dll,FileProtocolHandler", url).Start()
+			}
+		}
+		for _, name := range linuxOpeners {
+			if _, err := exec.LookPath(name); err == nil {
+				return exec.Command(name, url).Start()
+			}
+		}
+		return fmt.Errorf("no suitable browser opener found (install xdg-utils or set HACKARE_BROWSER)")
+	default:
+		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+}
+
+// DefaultOpener returns the opener for the default browser. HACKARE_BROWSER
+// takes a command template; otherwise the first entry of the conventional
+// colon-separated BROWSER list is used, and then the system handler.
+func DefaultOpener() Opener {
+	if template := strings.TrimSpace(os.Getenv("HACKARE_BROWSER")); template != "" {
+		return CommandOpener{Template: template}
+	}
+	if list := os.Getenv("BROWSER"); list != "" {
+		if first := strings.TrimSpace(strings.Split(list, string(os.PathListSeparator))[0]); first != "" {
+			return CommandOpener{Template: first}
+		}
+	}
+	return SystemOpener{}
+}
+
+// IsWSL reports whether this is Linux running under Windows Subsystem for Linux
+func IsWSL() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if os.Getenv("WSL_DISTRO_NAME") != "" || os.Getenv("WSL_INTEROP") != "" {
+		return true
+	}
+	version, err := os.ReadFile("/proc/version")
+	return err == nil && strings.Contains(strings.ToLower(string(version)), "microsoft")
+}
+
+// expandTemplate splits a command template into arguments and puts url in
+// place of {url} or %s
+func expandTemplate(template, url string) ([]string, error) {
+	args, err := splitArgs(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty browser command")
+	}
+
+	substituted := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{url}") || strings.Contains(arg, "%s") {
+			args[i] = strings.ReplaceAll(strings.ReplaceAll(arg, "{url}", url), "%s", url)
+			substituted = true
+		}
+	}
+	if !substituted {
+		args = append(args, url)
+	}
+	return args, nil
+}
+
+// splitArgs splits s on spaces, keeping single- or double-quoted runs together
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in browser command %q", s)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,56 @@
+package browser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	const url = "http://localhost:8080/#gpt=abc"
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"firefox", []string{"firefox", url}},
+		{"firefox --new-window {url}", []string{"firefox", "--new-window", url}},
+		{"chromium --app=%s", []string{"chromium", "--app=" + url}},
+		{`"/mnt/c/Program Files/Google/Chrome/Application/chrome.exe" {url}`,
+			[]string{"/mnt/c/Program Files/Google/Chrome/Application/chrome.exe", url}},
+		{`open -a 'Google Chrome'`, []string{"open", "-a", "Google Chrome", url}},
+	}
+	for _, tt := range tests {
+		got, err := expandTemplate(tt.template, url)
+		if err != nil {
+			t.Errorf("expandTemplate(%q): %v", tt.template, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	if _, err := expandTemplate(`"unterminated {url}`, url); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+	if _, err := expandTemplate("   ", url); err == nil {
+		t.Error("expected an error for an empty command")
+	}
+}
+
+func TestDefaultOpener(t *testing.T) {
+	t.Setenv("HACKARE_BROWSER", "")
+	t.Setenv("BROWSER", "")
+	if _, ok := DefaultOpener().(SystemOpener); !ok {
+		t.Error("expected the system opener with no environment set")
+	}
+
+	t.Setenv("BROWSER", "w3m:lynx")
+	if got := DefaultOpener(); got != (CommandOpener{Template: "w3m"}) {
+		t.Errorf("BROWSER: got %#v, want the first entry", got)
+	}
+
+	t.Setenv("HACKARE_BROWSER", "firefox {url}")
+	if got := DefaultOpener(); got != (CommandOpener{Template: "firefox {url}"}) {
+		t.Errorf("HACKARE_BROWSER should win over BROWSER, got %#v", got)
+	}
+}
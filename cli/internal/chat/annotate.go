@@ -0,0 +1,152 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/transcript"
+)
+
+// annotateReply rates an assistant reply 👍/👎 and adds a note to it. The
+// latest reply is annotated unless the user picks an earlier one.
+func (tc *TerminalChat) annotateReply() error {
+	tc.mu.Lock()
+	var replies []int
+	for i, msg := range tc.messages {
+		if msg.Role == "assistant" && msg.Content != "" {
+			replies = append(replies, i)
+		}
+	}
+	tc.mu.Unlock()
+	if len(replies) == 0 {
+		fmt.Println("\nNo replies to annotate yet.")
+		return nil
+	}
+
+	index := replies[len(replies)-1]
+	if len(replies) > 1 {
+		answer, err := tc.ask(fmt.Sprintf("\nReply to annotate (1-%d, Enter for the latest): ", len(replies)))
+		if err != nil {
+			return err
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			n, err := strconv.Atoi(answer)
+			if err != nil || n < 1 || n > len(replies) {
+				fmt.Printf("Give a number from 1 to %d.\n", len(replies))
+				return nil
+			}
+			index = replies[n-1]
+		}
+	}
+
+	tc.mu.Lock()
+	reply := tc.messages[index]
+	tc.mu.Unlock()
+	fmt.Printf("\n\033[90m%s\033[0m\n", preview(reply.Content, 120))
+	if !reply.Annotation.IsEmpty() {
+		fmt.Printf("Current annotation: %s\n", reply.Annotation)
+	}
+
+	answer, err := tc.ask("Rating: (u)p, (d)own, Enter for none: ")
+	if err != nil {
+		return err
+	}
+	rating, err := api.ParseRating(answer)
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+	note, err := tc.ask("Note (Enter for none): ")
+	if err != nil {
+		return err
+	}
+
+	annotation := &api.Annotation{Rating: rating, Note: strings.TrimSpace(note)}
+	if annotation.IsEmpty() {
+		annotation = nil
+	}
+	tc.mu.Lock()
+	if index < len(tc.messages) {
+		tc.messages[index].Annotation = annotation
+	}
+	tc.mu.Unlock()
+
+	if annotation == nil {
+		fmt.Println("Removed the annotation.")
+	} else {
+		fmt.Printf("Annotated: %s\n", annotation)
+	}
+	return nil
+}
+
+// exportConversation saves the conversation as a markdown transcript, or the
+// annotated replies as a JSONL eval dataset, in the current directory
+func (tc *TerminalChat) exportConversation() error {
+	tc.mu.Lock()
+	messages := append([]api.Message(nil), tc.messages...)
+	tc.mu.Unlock()
+	if len(messages) == 0 || (len(messages) == 1 && messages[0].Role == "system") {
+		fmt.Println("\nNothing to export yet.")
+		return nil
+	}
+
+	answer, err := tc.ask("\nExport as (m)arkdown transcript, (e)val dataset of annotated replies, or (c)ancel? ")
+	if err != nil {
+		return err
+	}
+	stamp := time.Now().Format("20060102-150405")
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "m", "markdown":
+		path := fmt.Sprintf("hacka.re-chat-%s.md", stamp)
+		return writeExport(path, func(file *os.File) error {
+			return transcript.WriteMarkdown(file, "Conversation", messages)
+		})
+	case "e", "eval":
+		annotated := 0
+		for _, msg := range messages {
+			if msg.Role == "assistant" && !msg.Annotation.IsEmpty() {
+				annotated++
+			}
+		}
+		if annotated == 0 {
+			fmt.Println("No replies are annotated yet; use /annotate first.")
+			return nil
+		}
+		path := fmt.Sprintf("hacka.re-eval-%s.jsonl", stamp)
+		return writeExport(path, func(file *os.File) error {
+			_, err := transcript.WriteEvalJSONL(file, tc.config.Model, messages)
+			return err
+		})
+	default:
+		fmt.Println("Cancelled; nothing was exported.")
+		return nil
+	}
+}
+
+// writeExport creates a new file at path and fills it with write
+func writeExport(path string, write func(file *os.File) error) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	fmt.Printf("Saved %s\n", path)
+	return nil
+}
+
+// preview shortens text to one line of at most n characters
+func preview(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,37 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/artifacts"
+)
+
+// manageArtifacts lists the files saved this session and offers to remove old
+// sessions according to the cleanup policy
+func (tc *TerminalChat) manageArtifacts() error {
+	summary, err := artifacts.Summary(artifacts.Current(), artifacts.Root())
+	if err != nil {
+		return err
+	}
+	fmt.Println("\n════ Artifacts ════")
+	fmt.Println(summary)
+	if tc.config.Kiosk {
+		return nil
+	}
+
+	policy := tc.config.ArtifactPolicy()
+	answer, err := tc.ask(fmt.Sprintf("\n(c)lean up old sessions (%s), or Enter to leave: ", policy))
+	if err != nil {
+		return err
+	}
+	if command := strings.ToLower(strings.TrimSpace(answer)); command != "c" && command != "clean" {
+		return nil
+	}
+	removed, freed, err := artifacts.Cleanup(artifacts.Root(), policy, artifacts.Current().Dir())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d old sessions (%s).\n", removed, artifacts.FormatSize(freed))
+	return nil
+}
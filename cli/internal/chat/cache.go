@@ -0,0 +1,32 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/toolcache"
+)
+
+// cacheCommand handles /cache: the size of the MCP tool result cache, or
+// "clear [TOOL]" to empty it for one tool or all of them
+func (tc *TerminalChat) cacheCommand(args string) error {
+	cache := toolcache.New(toolcache.Dir())
+	sub, tool, _ := strings.Cut(strings.TrimSpace(args), " ")
+	switch sub {
+	case "":
+		stats, err := cache.Stats()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n%d cached tool result(s), %d bytes, in %s\n", stats.Entries, stats.Bytes, toolcache.Dir())
+	case "clear":
+		removed, err := cache.Clear(strings.TrimSpace(tool))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nRemoved %d cached tool result(s).\n", removed)
+	default:
+		fmt.Println("\nUsage: /cache [clear [TOOL]]")
+	}
+	return nil
+}
@@ -12,6 +12,9 @@ type Command struct {
 	Aliases     []string // Short aliases (e.g., ["s"])
 	Description string   // Help text
 	Handler     func() error // Function to execute
+
+	// ArgsHandler is used instead of Handler by commands that take arguments
+	ArgsHandler func(args string) error
 }
 
 // CommandRegistry manages available commands
@@ -36,6 +39,18 @@ func (r *CommandRegistry) Register(cmd *Command) {
 	}
 }
 
+// Remove drops commands and their aliases, e.g. those a kiosk doesn't offer
+func (r *CommandRegistry) Remove(names ...string) {
+	for _, name := range names {
+		delete(r.commands, name)
+		for alias, target := range r.aliases {
+			if target == name {
+				delete(r.aliases, alias)
+			}
+		}
+	}
+}
+
 // Autocomplete returns the best matching command for the given input
 func (r *CommandRegistry) Autocomplete(input string) (string, *Command) {
 	// Remove leading slash if present
@@ -0,0 +1,50 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/contextwindow"
+	"github.com/hacka-re/cli/internal/dryrun"
+)
+
+// debugCommand handles /debug. "request [MESSAGE]" shows the payload that
+// sending MESSAGE next would send, or the conversation so far without one.
+func (tc *TerminalChat) debugCommand(args string) error {
+	sub, message, _ := strings.Cut(strings.TrimSpace(args), " ")
+	if sub != "request" {
+		fmt.Println("\nUsage: /debug request [MESSAGE]")
+		return nil
+	}
+	message = strings.TrimSpace(message)
+
+	// Assemble the messages as sending would, without changing the session
+	tc.mu.Lock()
+	messages := append([]api.Message(nil), tc.messages...)
+	session := tc.language
+	tc.mu.Unlock()
+	if message != "" {
+		messages = append(messages, api.Message{Role: "user", Content: message})
+	}
+	budget := contextwindow.Budget(string(tc.config.Provider), tc.config.Model, tc.config.MaxTokens)
+	messages, dropped := contextwindow.FitMessages(messages, budget)
+	if code := session.Reply(message); code != "" {
+		messages = addReplyLanguage(messages, code)
+	}
+
+	report, err := dryrun.Build(tc.client, messages, true)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	report.Write(os.Stdout)
+	if dropped > 0 {
+		fmt.Printf("%d older message(s) are left out to fit the context window of %d tokens.\n", dropped, budget)
+	}
+	if tc.draftMode {
+		fmt.Printf("Draft mode is on: %s drafts the reply first, and this payload is only sent if the draft fails.\n", tc.config.DraftModel)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/draft"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// toggleDraftMode switches local drafting with remote verification on or off
+func (tc *TerminalChat) toggleDraftMode() error {
+	if tc.draftClient == nil {
+		fmt.Println("\nDraft mode needs a local model. Set \"draftModel\" (and optionally")
+		fmt.Println("\"draftProvider\" or \"draftBaseUrl\", default llamafile) in the config.")
+		return nil
+	}
+	tc.draftMode = !tc.draftMode
+	if tc.draftMode {
+		fmt.Printf("\nDraft mode on: %s drafts, %s verifies.\n", tc.config.DraftModel, tc.config.Model)
+	} else {
+		fmt.Printf("\nDraft mode off: %s answers directly.\n", tc.config.Model)
+	}
+	return nil
+}
+
+// draftAndVerify streams a draft from the local model, has the main model review
+// it, and lets the user pick the answer. It returns false if no draft could be
+// made, so the caller asks the main model directly.
+func (tc *TerminalChat) draftAndVerify(ctx context.Context, callback api.StreamCallback, fullResponse *strings.Builder) bool {
+	startTime := time.Now()
+	fmt.Printf("\033[90m[Draft: %s]\033[0m\n", tc.config.DraftModel)
+
+	response, err := tc.draftClient.SendChatCompletionContext(ctx, tc.messages, callback)
+	draftText := fullResponse.String()
+	if draftText == "" && response != nil && len(response.Choices) > 0 {
+		draftText = response.Choices[0].Message.Content
+		fmt.Println(draftText)
+	}
+	if err != nil || strings.TrimSpace(draftText) == "" {
+		logger.Get().Warn("Draft failed: %v", err)
+		if ctx.Err() != nil {
+			return true
+		}
+		fmt.Printf("\n\033[90m[Draft failed: %s; asking %s directly]\033[0m\n", failure.Message(err), tc.config.Model)
+		fullResponse.Reset()
+		return false
+	}
+	tc.usage.Record(tc.config.DraftModel, estimatePromptTokens(tc.messages), usage.EstimateTokens(draftText))
+
+	fmt.Printf("\n\033[90m[Verifying with %s...]\033[0m\n", tc.config.Model)
+	verify := draft.VerifyMessages(tc.messages, draftText)
+	response, err = tc.client.SendChatCompletionContext(ctx, verify, nil)
+	if err != nil {
+		logger.Get().Error("Verification failed: %v", err)
+		fmt.Printf("\033[90m[Verification failed: %s; keeping the unverified draft]\033[0m\n", failure.Message(err))
+		tc.messages = append(tc.messages, api.Message{Role: "assistant", Content: tc.afterReceive(draftText)})
+		return true
+	}
+	reply := ""
+	if len(response.Choices) > 0 {
+		reply = response.Choices[0].Message.Content
+	}
+	tc.recordUsage(response, estimatePromptTokens(verify), reply)
+
+	final, approved := draft.Refined(reply, draftText)
+	spans := draft.Diff(draftText, final)
+	if approved || !draft.Changed(spans) {
+		fmt.Printf("\033[32m[✓ Draft verified by %s]\033[0m\n", tc.config.Model)
+		tc.messages = append(tc.messages, api.Message{Role: "assistant", Content: tc.afterReceive(draftText)})
+		tc.notifyIfSlow(startTime)
+		return true
+	}
+
+	fmt.Printf("\n\033[90m── Changes by %s ──\033[0m\n%s\n\n", tc.config.Model, draft.Render(spans, true))
+	answer, err := tc.ask("Keep the (r)efined answer or the (d)raft? [R/d] ")
+	if err == nil && strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "d") {
+		fmt.Println("Kept the draft.")
+		final = draftText
+	} else {
+		fmt.Println("Kept the refined answer.")
+	}
+	tc.messages = append(tc.messages, api.Message{Role: "assistant", Content: tc.afterReceive(final)})
+	tc.notifyIfSlow(startTime)
+	return true
+}
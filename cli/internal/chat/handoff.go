@@ -0,0 +1,136 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/handoff"
+	"github.com/hacka-re/cli/internal/redact"
+)
+
+// openMenu runs a configuration menu and offers the handoff review when the
+// provider or model changed while it was open
+func (tc *TerminalChat) openMenu(open func() error) error {
+	provider, model := tc.config.Provider, tc.config.Model
+	if err := open(); err != nil {
+		return err
+	}
+	if tc.config.Provider == provider && tc.config.Model == model {
+		return nil
+	}
+
+	tc.mu.Lock()
+	conversation := hasConversation(tc.messages)
+	tc.mu.Unlock()
+	if !conversation {
+		return nil
+	}
+	fmt.Printf("\nSwitched from %s (%s) to %s (%s).\n", model, provider, tc.config.Model, tc.config.Provider)
+	answer, err := tc.ask("Review the history before it is sent to the new model? [Y/n] ")
+	if err != nil || strings.HasPrefix(strings.ToLower(answer), "n") {
+		return err
+	}
+	return tc.reviewHandoff()
+}
+
+// reviewHandoff edits the history that will be sent from now on: it strips
+// provider-specific artifacts, redacts secrets, and replaces text the user names.
+// Nothing changes until the user confirms the result.
+func (tc *TerminalChat) reviewHandoff() error {
+	tc.mu.Lock()
+	messages := append([]api.Message(nil), tc.messages...)
+	tc.mu.Unlock()
+	if !hasConversation(messages) {
+		fmt.Println("\nNothing to review yet.")
+		return nil
+	}
+	edited := messages
+	changes := 0
+
+	fmt.Printf("\n════ Handoff review (%s, %d messages) ════\n", tc.config.Model, len(messages))
+	artifacts := handoff.Detect(edited)
+	if len(artifacts) == 0 {
+		fmt.Println("\nNo provider-specific artifacts found.")
+	}
+	var strip []string
+	for _, a := range artifacts {
+		answer, err := tc.ask(fmt.Sprintf("\n%s.\nStrip them? [Y/n] ", a.Describe()))
+		if err != nil {
+			return err
+		}
+		if answer == "" || isYes(answer) {
+			strip = append(strip, a.Kind)
+		}
+	}
+	if len(strip) > 0 {
+		edited = handoff.Strip(edited, strip...)
+		changes += len(strip)
+	}
+
+	if findings := redact.Scan(edited); len(findings) > 0 {
+		answer, err := tc.ask(fmt.Sprintf("\n%d possible secret(s) or personal data found. Review them? [Y/n] ", len(findings)))
+		if err != nil {
+			return err
+		}
+		if answer == "" || isYes(answer) {
+			accepted, err := tc.reviewFindings(edited, findings)
+			if err != nil {
+				return err
+			}
+			if accepted == nil {
+				fmt.Println("Review aborted; no findings redacted.")
+			}
+			edited = redact.Apply(edited, accepted)
+			changes += len(accepted)
+		}
+	}
+
+	fmt.Println("\nFind and replace text in every message. Leave Find empty to finish.")
+	for {
+		find, err := tc.ask("Find: ")
+		if err != nil {
+			return err
+		}
+		if find == "" {
+			break
+		}
+		replacement, err := tc.ask("Replace with: ")
+		if err != nil {
+			return err
+		}
+		var count int
+		edited, count = handoff.Replace(edited, find, replacement)
+		fmt.Printf("Replaced %d occurrence(s).\n", count)
+		changes += count
+	}
+
+	if changes == 0 {
+		fmt.Println("\nThe history is unchanged.")
+		return nil
+	}
+	answer, err := tc.ask(fmt.Sprintf("\nSend the edited history (%d messages) from now on? [y/N] ", len(edited)))
+	if err != nil {
+		return err
+	}
+	if !isYes(answer) {
+		fmt.Println("Discarded; the history is unchanged.")
+		return nil
+	}
+
+	tc.mu.Lock()
+	tc.messages = edited
+	tc.mu.Unlock()
+	fmt.Println("The edited history will be sent from now on.")
+	return nil
+}
+
+// hasConversation reports whether there is more than a system prompt
+func hasConversation(messages []api.Message) bool {
+	for _, msg := range messages {
+		if msg.Role != "system" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/inputhistory"
+	"github.com/hacka-re/cli/internal/lineedit"
+)
+
+// reverseSearch searches the input history as the user types, like Ctrl+R in
+// a shell. Ctrl+R again finds an older match, Enter runs the match, an arrow
+// key or another control key keeps it for editing, and Ctrl+G cancels. It
+// returns the line to run and true when the user pressed Enter.
+func (tc *TerminalChat) reverseSearch() (string, bool) {
+	original, originalPos := tc.currentLine, tc.cursorPos
+	var query []rune
+	match := -1
+
+	for {
+		tc.drawSearch(string(query), match)
+		key, err := lineedit.ReadKey(tc.keys)
+		if err != nil {
+			break
+		}
+		switch {
+		case key.Code == lineedit.KeyEnter: // Run the match
+			if match < 0 {
+				tc.redrawLine()
+				return string(tc.currentLine), true
+			}
+			tc.currentLine = []rune(tc.history[match])
+			tc.cursorPos = len(tc.currentLine)
+			tc.redrawLine()
+			return tc.history[match], true
+
+		case key.IsCtrl('r'): // Older match
+			from := match
+			if from < 0 {
+				from = len(tc.history)
+			}
+			if older := inputhistory.Search(tc.history, string(query), from); older >= 0 {
+				match = older
+			}
+
+		case key.IsCtrl('g'), key.IsCtrl('c'): // Cancel
+			tc.currentLine, tc.cursorPos = original, originalPos
+			tc.redrawLine()
+			return "", false
+
+		case key.Code == lineedit.KeyBackspace && !key.Alt:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				match = inputhistory.Search(tc.history, string(query), len(tc.history))
+			}
+
+		case key.Code == lineedit.KeyRune && !key.Alt:
+			query = append(query, key.Rune)
+			match = inputhistory.Search(tc.history, string(query), len(tc.history))
+
+		case key.Code == lineedit.KeyPaste: // Search for the first line of it
+			query = append(query, []rune(strings.SplitN(key.Text, "\n", 2)[0])...)
+			match = inputhistory.Search(tc.history, string(query), len(tc.history))
+
+		default: // Keep the match for editing
+			if match >= 0 {
+				tc.currentLine = []rune(tc.history[match])
+				tc.cursorPos = len(tc.currentLine)
+			}
+			tc.redrawLine()
+			return "", false
+		}
+	}
+	tc.redrawLine()
+	return "", false
+}
+
+// drawSearch shows the search prompt and the current match on the input line
+func (tc *TerminalChat) drawSearch(query string, match int) {
+	label := "reverse-i-search"
+	found := ""
+	if match >= 0 {
+		found = strings.ReplaceAll(tc.history[match], "\n", " ")
+	} else if query != "" {
+		label = "failed reverse-i-search"
+	}
+	line := fmt.Sprintf("(%s)`%s': %s", label, query, found)
+	if runes := []rune(line); len(runes) > tc.termWidth-1 && tc.termWidth > 1 {
+		line = string(runes[:tc.termWidth-1])
+	}
+	fmt.Print("\r\033[K" + line)
+}
+
+// manageHistory lists recent input or, with "clear", forgets the saved input
+// of this namespace
+func (tc *TerminalChat) manageHistory(args string) error {
+	switch args {
+	case "":
+		if len(tc.history) == 0 {
+			fmt.Println("\nNo input history yet.")
+			return nil
+		}
+		start := len(tc.history) - 20
+		if start < 0 {
+			start = 0
+		}
+		fmt.Println()
+		for i := start; i < len(tc.history); i++ {
+			fmt.Printf("  %4d  %s\n", i+1, preview(tc.history[i], 100))
+		}
+		if tc.inputHistory == nil {
+			fmt.Println("\033[90mInput isn't saved across runs (historySize is negative, or kiosk mode).\033[0m")
+		} else {
+			fmt.Println("\033[90m↑/↓ go through it, Ctrl+R searches it. Lines with secrets aren't saved.\033[0m")
+		}
+		return nil
+
+	case "clear":
+		tc.history = nil
+		tc.historyPos = -1
+		if tc.inputHistory != nil {
+			if err := tc.inputHistory.Clear(tc.config.Namespace); err != nil {
+				return err
+			}
+		}
+		fmt.Println("\nInput history cleared.")
+		return nil
+	}
+	fmt.Println("\nUsage: /history, or /history clear")
+	return nil
+}
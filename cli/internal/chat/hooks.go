@@ -0,0 +1,33 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+)
+
+// beforeSend runs the hooks on a message about to be sent and returns the
+// text to send, or false when a hook stopped it
+func (tc *TerminalChat) beforeSend(input string) (string, bool) {
+	sent, err := tc.hooks.Send(context.Background(), input, tc.config.Model)
+	if err != nil {
+		fmt.Printf("\nNot sent, %v\n", err)
+		return "", false
+	}
+	if sent != input {
+		fmt.Printf("\033[90m[Sent as changed by hooks:]\033[0m\n%s\n", sent)
+	}
+	return sent, true
+}
+
+// afterReceive runs the hooks on a reply and returns the text to keep,
+// showing it again when a hook changed it
+func (tc *TerminalChat) afterReceive(reply string) string {
+	kept, err := tc.hooks.Receive(context.Background(), reply, tc.config.Model)
+	if err != nil {
+		fmt.Printf("\033[90m[Hook failed: %v]\033[0m\n", err)
+	}
+	if kept != reply {
+		fmt.Printf("\n\033[90m[Kept as changed by hooks:]\033[0m\n%s\n", kept)
+	}
+	return kept
+}
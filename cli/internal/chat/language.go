@@ -0,0 +1,66 @@
+package chat
+
+import (
+	"fmt"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/language"
+	"github.com/hacka-re/cli/internal/logger"
+)
+
+// setLanguage shows or sets the reply language of this session. It lasts
+// until the chat ends, also across /clear; "language" in the configuration
+// sets the one new sessions start with.
+func (tc *TerminalChat) setLanguage(args string) error {
+	if args == "" {
+		fmt.Printf("\nReply language: %s\n", tc.language.String())
+		fmt.Println("Use /lang <code> (e.g. /lang sv), /lang auto to follow your messages, or /lang off.")
+		return nil
+	}
+	setting, err := language.Parse(args)
+	if err != nil {
+		fmt.Printf("\n%v\n", err)
+		return nil
+	}
+	tc.mu.Lock()
+	tc.language = language.Session{Setting: setting}
+	tc.mu.Unlock()
+	switch setting {
+	case language.Off:
+		fmt.Println("\nReply language off: the model picks the language.")
+	case language.Auto:
+		fmt.Println("\nReplies follow the language of your latest message.")
+	default:
+		fmt.Printf("\nReplies are in %s for the rest of the session.\n", language.Name(setting))
+	}
+	return nil
+}
+
+// withReplyLanguage adds the reply language instruction for input to the
+// system message of the messages about to be sent. The conversation itself
+// is left as it is.
+func (tc *TerminalChat) withReplyLanguage(messages []api.Message, input string) []api.Message {
+	tc.mu.Lock()
+	before := tc.language.Detected
+	code := tc.language.Reply(input)
+	auto := tc.language.Setting == language.Auto
+	tc.mu.Unlock()
+	if code == "" {
+		return messages
+	}
+	if auto && before != "" && before != code {
+		fmt.Printf("\033[90m(Replying in %s; /lang sets a fixed language)\033[0m\n", language.Name(code))
+	}
+	logger.Get().Debug("Reply language: %s", code)
+	return addReplyLanguage(messages, code)
+}
+
+// addReplyLanguage adds the instruction to reply in code to the system message
+func addReplyLanguage(messages []api.Message, code string) []api.Message {
+	if len(messages) > 0 && messages[0].Role == "system" {
+		system := messages[0]
+		system.Content = language.WithInstruction(system.Content, code)
+		return append([]api.Message{system}, messages[1:]...)
+	}
+	return append([]api.Message{{Role: "system", Content: language.Instruction(code)}}, messages...)
+}
@@ -0,0 +1,126 @@
+package chat
+
+import (
+	"fmt"
+
+	"github.com/hacka-re/cli/internal/lineedit"
+)
+
+// lastEdit is what the previous key did: kills in a row join into one kill
+// ring entry, and Alt+Y only replaces text that was just yanked
+type lastEdit int
+
+const (
+	editOther lastEdit = iota
+	editKill
+	editYank
+)
+
+// editKey applies an emacs-style editing key to the input line
+func (tc *TerminalChat) editKey(key lineedit.Key) {
+	previous := tc.lastEdit
+	tc.lastEdit = editOther
+	line, pos := tc.currentLine, tc.cursorPos
+
+	switch {
+	case key.Code == lineedit.KeyRune && !key.Alt:
+		tc.insertText(string(key.Rune))
+	case key.Code == lineedit.KeyPaste:
+		tc.insertPaste(key.Text)
+
+	// Movement
+	case key.Code == lineedit.KeyLeft && (key.Alt || key.Ctrl), key.IsAlt('b'):
+		tc.cursorPos = lineedit.WordStart(line, pos)
+	case key.Code == lineedit.KeyRight && (key.Alt || key.Ctrl), key.IsAlt('f'):
+		tc.cursorPos = lineedit.WordEnd(line, pos)
+	case key.Code == lineedit.KeyLeft, key.IsCtrl('b'):
+		if pos > 0 {
+			tc.cursorPos--
+		}
+	case key.Code == lineedit.KeyRight, key.IsCtrl('f'):
+		if pos < len(line) {
+			tc.cursorPos++
+		}
+	case key.Code == lineedit.KeyHome, key.IsCtrl('a'):
+		tc.cursorPos = 0
+	case key.Code == lineedit.KeyEnd, key.IsCtrl('e'):
+		tc.cursorPos = len(line)
+
+	// Deleting
+	case key.Code == lineedit.KeyBackspace && !key.Alt:
+		if start, ok := tc.placeholderBefore(pos); ok {
+			tc.currentLine = append(line[:start], line[pos:]...)
+			tc.cursorPos = start
+		} else if pos > 0 {
+			tc.currentLine = append(line[:pos-1], line[pos:]...)
+			tc.cursorPos--
+		}
+	case key.Code == lineedit.KeyDelete, key.IsCtrl('d'):
+		if pos < len(line) {
+			tc.currentLine = append(line[:pos], line[pos+1:]...)
+		}
+	case key.IsCtrl('t'): // Swap the characters before the cursor, moving it on
+		if pos > 0 && len(line) > 1 {
+			if pos == len(line) {
+				pos--
+			}
+			line[pos-1], line[pos] = line[pos], line[pos-1]
+			tc.cursorPos = pos + 1
+		}
+
+	// Killing and yanking
+	case key.IsCtrl('k'):
+		tc.kill(pos, len(line), false, previous)
+	case key.IsCtrl('u'):
+		tc.kill(0, pos, true, previous)
+	case key.IsCtrl('w'):
+		tc.kill(lineedit.FieldStart(line, pos), pos, true, previous)
+	case key.Code == lineedit.KeyBackspace && key.Alt:
+		tc.kill(lineedit.WordStart(line, pos), pos, true, previous)
+	case key.IsAlt('d'):
+		tc.kill(pos, lineedit.WordEnd(line, pos), false, previous)
+	case key.IsCtrl('y'):
+		tc.yankStart = pos
+		tc.insertText(tc.killRing.Yank())
+		tc.lastEdit = editYank
+	case key.IsAlt('y'):
+		if previous == editYank {
+			tc.currentLine = append(line[:tc.yankStart], line[pos:]...)
+			tc.cursorPos = tc.yankStart
+			tc.insertText(tc.killRing.Rotate())
+			tc.lastEdit = editYank
+		}
+
+	case key.IsCtrl('l'):
+		fmt.Print("\033[H\033[2J") // Clear the screen, keeping the line
+	}
+
+	// The last of the keys already waiting redraws the line
+	if tc.inputPending() {
+		tc.stale = true
+		return
+	}
+	tc.redrawLine()
+}
+
+// insertText inserts text at the cursor and moves the cursor after it
+func (tc *TerminalChat) insertText(text string) {
+	inserted := []rune(text)
+	line := make([]rune, 0, len(tc.currentLine)+len(inserted))
+	line = append(line, tc.currentLine[:tc.cursorPos]...)
+	line = append(line, inserted...)
+	tc.currentLine = append(line, tc.currentLine[tc.cursorPos:]...)
+	tc.cursorPos += len(inserted)
+}
+
+// kill removes the text between start and end into the kill ring. Kills in a
+// row join, so they can be yanked back together.
+func (tc *TerminalChat) kill(start, end int, backward bool, previous lastEdit) {
+	tc.lastEdit = editKill
+	if start >= end {
+		return
+	}
+	tc.killRing.Kill(string(tc.currentLine[start:end]), backward, previous == editKill)
+	tc.currentLine = append(tc.currentLine[:start], tc.currentLine[end:]...)
+	tc.cursorPos = start
+}
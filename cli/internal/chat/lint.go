@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/promptlint"
+)
+
+// toggleLint switches prompt checks on or off for this session
+func (tc *TerminalChat) toggleLint() error {
+	tc.config.LintPrompts = !tc.config.LintPrompts
+	if tc.config.LintPrompts {
+		fmt.Println("\nPrompt checks on: secrets, open code fences, doubled words and very long paragraphs are flagged before sending.")
+	} else {
+		fmt.Println("\nPrompt checks off.")
+	}
+	return nil
+}
+
+// lintPrompt shows what promptlint finds in input and asks whether to send
+// it. It returns the prompt to send, with secrets redacted if the user asks
+// for that, and false if the user cancels.
+func (tc *TerminalChat) lintPrompt(input string) (string, bool) {
+	warnings := promptlint.Lint(input)
+	if len(warnings) == 0 {
+		return input, true
+	}
+
+	secrets := false
+	fmt.Println("\n\033[33mBefore sending:\033[0m")
+	for _, w := range warnings {
+		fmt.Printf("  \033[33m•\033[0m %s\n", w.Message)
+		secrets = secrets || w.Kind == promptlint.KindSecret
+	}
+	question := "(s)end anyway, or Enter to cancel: "
+	if secrets {
+		question = "(s)end anyway, (r)edact the secrets and send, or Enter to cancel: "
+	}
+	answer, err := tc.ask(question)
+	if err != nil {
+		logger.Get().Error("Failed to read the answer: %v", err)
+		return "", false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "s", "send", "y", "yes":
+		return input, true
+	case "r", "redact":
+		if secrets {
+			return promptlint.Redact(input), true
+		}
+	}
+	fmt.Println("Not sent. Press ↑ to edit the prompt.")
+	return "", false
+}
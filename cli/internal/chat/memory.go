@@ -0,0 +1,189 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/memory"
+)
+
+// systemPrompt is the system prompt of the session plus the memory block of
+// the namespace
+func (tc *TerminalChat) systemPrompt() string {
+	prompt := tc.config.SystemPrompt
+	if tc.config.SessionSystemPrompt != "" {
+		prompt = tc.config.SessionSystemPrompt
+	}
+	if tc.memory == nil {
+		return prompt
+	}
+	facts, err := tc.memory.Facts(tc.config.Namespace)
+	if err != nil {
+		logger.Get().Error("Failed to load memory: %v", err)
+		return prompt
+	}
+	return memory.WithBlock(prompt, facts)
+}
+
+// refreshSystemPrompt updates the system message after memory changes, so the
+// next request already sees them
+func (tc *TerminalChat) refreshSystemPrompt() {
+	systemPrompt := tc.systemPrompt()
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	switch {
+	case len(tc.messages) > 0 && tc.messages[0].Role == "system":
+		if systemPrompt == "" {
+			tc.messages = tc.messages[1:]
+		} else {
+			tc.messages[0].Content = systemPrompt
+		}
+	case systemPrompt != "":
+		tc.messages = append([]api.Message{{Role: "system", Content: systemPrompt}}, tc.messages...)
+	}
+}
+
+// manageMemory lists the remembered facts and lets the user add, edit or delete them
+func (tc *TerminalChat) manageMemory() error {
+	if tc.memory == nil {
+		fmt.Println("\nMemory is disabled (disableMemory in the configuration).")
+		return nil
+	}
+	namespace := memory.Namespace(tc.config.Namespace)
+	for {
+		facts, err := tc.memory.Facts(namespace)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n════ Memory (%s) ════\n", namespace)
+		if len(facts) == 0 {
+			fmt.Println("Nothing remembered yet. Use /remember after a chat, or add a fact here.")
+		}
+		for i, fact := range facts {
+			fmt.Printf("  %2d. %s \033[90m(%s, %s)\033[0m\n", i+1, fact.Text, fact.Source, fact.CreatedAt.Local().Format("2006-01-02"))
+		}
+
+		answer, err := tc.ask("\n(a)dd, (e)dit N, (d)elete N, (c)lear all, or Enter to leave: ")
+		if err != nil {
+			return err
+		}
+		command, arg, _ := strings.Cut(strings.TrimSpace(answer), " ")
+		switch strings.ToLower(command) {
+		case "":
+			return nil
+		case "a", "add":
+			text, err := tc.ask("Fact: ")
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(text) != "" {
+				if _, err := tc.memory.Add(namespace, text, "manual"); err != nil {
+					fmt.Printf("Error: %v\n", err)
+				}
+			}
+		case "e", "edit", "d", "delete":
+			n, err := strconv.Atoi(strings.TrimSpace(arg))
+			if err != nil || n < 1 || n > len(facts) {
+				fmt.Println("Give the number of a fact, e.g. \"d 2\".")
+				continue
+			}
+			fact := facts[n-1]
+			if strings.HasPrefix(strings.ToLower(command), "d") {
+				err = tc.memory.Remove(namespace, fact.ID)
+			} else {
+				var text string
+				if text, err = tc.ask("New text (empty deletes): "); err == nil {
+					err = tc.memory.Update(namespace, fact.ID, text)
+				}
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+		case "c", "clear":
+			answer, err := tc.ask(fmt.Sprintf("Forget all %d fact(s) in %s? [y/N] ", len(facts), namespace))
+			if err != nil {
+				return err
+			}
+			if isYes(answer) {
+				if err := tc.memory.Clear(namespace); err != nil {
+					return err
+				}
+			}
+		default:
+			fmt.Printf("Unknown choice %q\n", command)
+		}
+		tc.refreshSystemPrompt()
+	}
+}
+
+// rememberFacts asks the model for durable facts in the conversation and
+// stores the ones the user approves
+func (tc *TerminalChat) rememberFacts() error {
+	if tc.memory == nil {
+		fmt.Println("\nMemory is disabled (disableMemory in the configuration).")
+		return nil
+	}
+	tc.mu.Lock()
+	messages := append([]api.Message(nil), tc.messages...)
+	tc.mu.Unlock()
+	if len(messages) == 0 || (len(messages) == 1 && messages[0].Role == "system") {
+		fmt.Println("\nNothing to remember yet.")
+		return nil
+	}
+
+	namespace := memory.Namespace(tc.config.Namespace)
+	known, err := tc.memory.Facts(namespace)
+	if err != nil {
+		return err
+	}
+	fmt.Println("\nLooking for facts worth remembering...")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	candidates, err := memory.Extract(ctx, tc.client, messages, known)
+	cancel()
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		fmt.Println("Nothing new to remember.")
+		return nil
+	}
+
+	saved := 0
+review:
+	for i, candidate := range candidates {
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(candidates), candidate)
+		answer, err := tc.ask("Remember? [Y]es, (n)o, (e)dit, (q)uit: ")
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "n", "no":
+			continue
+		case "q", "quit":
+			break review
+		case "e", "edit":
+			if candidate, err = tc.ask("Fact: "); err != nil {
+				return err
+			}
+			if strings.TrimSpace(candidate) == "" {
+				continue
+			}
+		}
+		if _, err := tc.memory.Add(namespace, candidate, "chat"); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		saved++
+	}
+
+	fmt.Printf("\nRemembered %d fact(s) in %s.\n", saved, namespace)
+	if saved > 0 {
+		tc.refreshSystemPrompt()
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package chat
+
+import (
+	"fmt"
+
+	"github.com/hacka-re/cli/internal/browser"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/utils"
+	"github.com/hacka-re/cli/internal/webopen"
+)
+
+// openWeb opens the conversation and the configuration in the web app on a
+// local server, starting one if none is running
+func (tc *TerminalChat) openWeb() error {
+	cfg := tc.config.ToSharedConfig()
+	for _, msg := range tc.messages {
+		// Tool calls and their results stay behind; the web app runs its own
+		if (msg.Role == "user" || msg.Role == "assistant") && msg.Content != "" {
+			shared := share.Message{Role: msg.Role, Content: msg.Content}
+			if msg.Annotation != nil {
+				shared.Rating, shared.Note = msg.Annotation.Rating, msg.Annotation.Note
+			}
+			cfg.Messages = append(cfg.Messages, shared)
+		}
+	}
+
+	result, err := webopen.Open(cfg, browser.DefaultOpener())
+	if err != nil {
+		return err
+	}
+	if result.Started {
+		fmt.Println("\nStarted a local web server for this session.")
+	}
+	fmt.Printf("\nOpened %d messages in the browser.\nPassword: %s", len(cfg.Messages), result.Password)
+	if utils.SetClipboardContent(result.Password) == nil {
+		fmt.Print(" (copied to the clipboard)")
+	}
+	fmt.Println()
+	return nil
+}
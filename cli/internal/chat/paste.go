@@ -0,0 +1,76 @@
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/artifacts"
+)
+
+// pasteInlineMax is the longest paste shown as it is in the input line;
+// longer pastes, and pastes with line breaks or tabs, show as a placeholder
+const pasteInlineMax = 200
+
+// paste is a pasted block shown as a placeholder in the input line
+type paste struct {
+	placeholder string
+	text        string
+}
+
+// insertPaste inserts pasted text at the cursor. A large or multi-line paste
+// shows as "(pasted 1.2 KB)", and the text is put back when the line is sent.
+func (tc *TerminalChat) insertPaste(text string) {
+	if text == "" {
+		return
+	}
+	if len(text) <= pasteInlineMax && !strings.ContainsAny(text, "\n\t") {
+		tc.insertText(text)
+		return
+	}
+	size := artifacts.FormatSize(int64(len(text)))
+	placeholder := fmt.Sprintf("(pasted %s)", size)
+	for n := 2; tc.pasteIndex(placeholder) >= 0; n++ {
+		placeholder = fmt.Sprintf("(pasted %s #%d)", size, n)
+	}
+	tc.pastes = append(tc.pastes, paste{placeholder: placeholder, text: text})
+	tc.insertText(placeholder)
+}
+
+// pasteIndex returns the index of the paste with placeholder, or -1
+func (tc *TerminalChat) pasteIndex(placeholder string) int {
+	for i, p := range tc.pastes {
+		if p.placeholder == placeholder {
+			return i
+		}
+	}
+	return -1
+}
+
+// placeholderBefore returns where a paste placeholder ending at pos starts,
+// so Backspace removes it whole
+func (tc *TerminalChat) placeholderBefore(pos int) (int, bool) {
+	for _, p := range tc.pastes {
+		n := len([]rune(p.placeholder))
+		if pos >= n && string(tc.currentLine[pos-n:pos]) == p.placeholder {
+			return pos - n, true
+		}
+	}
+	return 0, false
+}
+
+// expandPastes puts the pasted text back in place of the placeholders in
+// line and forgets the pastes
+func (tc *TerminalChat) expandPastes(line string) string {
+	for _, p := range tc.pastes {
+		line = strings.Replace(line, p.placeholder, p.text, 1)
+	}
+	tc.pastes = nil
+	return line
+}
+
+// inputPending reports whether more input is already waiting, as while a
+// paste arrives without bracketed paste mode; redrawing the line for every
+// character of it would be slow
+func (tc *TerminalChat) inputPending() bool {
+	return tc.keys != nil && tc.keys.Buffered() > 0
+}
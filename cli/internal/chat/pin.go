@@ -0,0 +1,138 @@
+package chat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/contextwindow"
+	"github.com/hacka-re/cli/internal/logger"
+)
+
+// pinMessage marks a message so it is always sent, even when older messages
+// are left out to fit the context window. The latest user message is pinned
+// unless the user picks another one.
+func (tc *TerminalChat) pinMessage() error {
+	tc.mu.Lock()
+	var candidates []int
+	latest := -1
+	for i, msg := range tc.messages {
+		if (msg.Role == "user" || msg.Role == "assistant") && msg.Content != "" {
+			candidates = append(candidates, i)
+			if msg.Role == "user" {
+				latest = len(candidates) - 1
+			}
+		}
+	}
+	tc.mu.Unlock()
+	if len(candidates) == 0 {
+		fmt.Println("\nNo messages to pin yet.")
+		return nil
+	}
+
+	fmt.Println()
+	for n, i := range candidates {
+		tc.printPinCandidate(n+1, i)
+	}
+	pick := latest
+	if pick < 0 {
+		pick = len(candidates) - 1
+	}
+	n, ok, err := tc.askNumber(fmt.Sprintf("Message to pin (1-%d, Enter for %d): ", len(candidates), pick+1), len(candidates))
+	if err != nil || !ok {
+		return err
+	}
+	if n > 0 {
+		pick = n - 1
+	}
+
+	tc.setPinned(candidates[pick], true)
+	fmt.Println("Pinned: it is kept whenever older messages are left out to fit the context window.")
+	return nil
+}
+
+// unpinMessage removes a pin; the most recent pin unless the user picks another
+func (tc *TerminalChat) unpinMessage() error {
+	tc.mu.Lock()
+	var pinned []int
+	for i, msg := range tc.messages {
+		if msg.Pinned {
+			pinned = append(pinned, i)
+		}
+	}
+	tc.mu.Unlock()
+	if len(pinned) == 0 {
+		fmt.Println("\nNo messages are pinned.")
+		return nil
+	}
+
+	pick := len(pinned) - 1
+	if len(pinned) > 1 {
+		fmt.Println()
+		for n, i := range pinned {
+			tc.printPinCandidate(n+1, i)
+		}
+		n, ok, err := tc.askNumber(fmt.Sprintf("Pin to remove (1-%d, Enter for the latest): ", len(pinned)), len(pinned))
+		if err != nil || !ok {
+			return err
+		}
+		if n > 0 {
+			pick = n - 1
+		}
+	}
+
+	tc.setPinned(pinned[pick], false)
+	fmt.Println("\nUnpinned.")
+	return nil
+}
+
+// printPinCandidate shows message i as choice n, marking pinned messages
+func (tc *TerminalChat) printPinCandidate(n, i int) {
+	tc.mu.Lock()
+	msg := tc.messages[i]
+	tc.mu.Unlock()
+	mark := "  "
+	if msg.Pinned {
+		mark = "📌"
+	}
+	fmt.Printf("%s %2d. \033[90m%s:\033[0m %s\n", mark, n, msg.Role, preview(msg.Content, 80))
+}
+
+// askNumber asks for a choice from 1 to max; 0 means the user pressed Enter.
+// ok is false when the answer wasn't a valid choice.
+func (tc *TerminalChat) askNumber(prompt string, max int) (int, bool, error) {
+	answer, err := tc.ask(prompt)
+	if err != nil {
+		return 0, false, err
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return 0, true, nil
+	}
+	n, err := strconv.Atoi(answer)
+	if err != nil || n < 1 || n > max {
+		fmt.Printf("Give a number from 1 to %d.\n", max)
+		return 0, false, nil
+	}
+	return n, true, nil
+}
+
+func (tc *TerminalChat) setPinned(index int, pinned bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if index < len(tc.messages) {
+		tc.messages[index].Pinned = pinned
+	}
+}
+
+// contextMessages returns the conversation trimmed to the model's context
+// window, saying so when older messages are left out
+func (tc *TerminalChat) contextMessages() []api.Message {
+	budget := contextwindow.Budget(string(tc.config.Provider), tc.config.Model, tc.config.MaxTokens)
+	messages, dropped := contextwindow.FitMessages(tc.messages, budget)
+	if dropped > 0 {
+		logger.Get().Info("Left out %d of %d messages to fit a budget of %d tokens", dropped, len(tc.messages), budget)
+		fmt.Printf("\033[90m(%d older messages left out to fit the context window; /pin keeps a message)\033[0m\n", dropped)
+	}
+	return messages
+}
@@ -0,0 +1,163 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/crypto"
+	"github.com/hacka-re/cli/internal/redact"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/transcript"
+)
+
+// redactConversation scans the conversation for secrets and personal data,
+// asks which findings to redact, and exports the result as markdown or a share link
+func (tc *TerminalChat) redactConversation() error {
+	tc.mu.Lock()
+	messages := append([]api.Message(nil), tc.messages...)
+	tc.mu.Unlock()
+	if len(messages) == 0 || (len(messages) == 1 && messages[0].Role == "system") {
+		fmt.Println("\nNothing to redact yet.")
+		return nil
+	}
+
+	findings := redact.Scan(messages)
+	fmt.Printf("\nPattern scan: %d finding(s).\n", len(findings))
+
+	answer, err := tc.ask("Also ask the model to look for names, addresses and other PII? It sees the whole conversation again. [y/N] ")
+	if err != nil {
+		return err
+	}
+	if isYes(answer) {
+		fmt.Println("Asking the model...")
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		found, err := redact.ModelScan(ctx, tc.client, messages)
+		cancel()
+		if err != nil {
+			fmt.Printf("%v\nContinuing with the pattern findings.\n", err)
+		} else {
+			before := len(findings)
+			findings = redact.Merge(findings, found)
+			fmt.Printf("Model scan: %d more finding(s).\n", len(findings)-before)
+		}
+	}
+
+	accepted, err := tc.reviewFindings(messages, findings)
+	if err != nil {
+		return err
+	}
+	if accepted == nil {
+		fmt.Println("Review aborted; nothing was exported.")
+		return nil
+	}
+	sanitized := redact.Apply(messages, accepted)
+	fmt.Printf("\n%d of %d finding(s) will be redacted.\n", len(accepted), len(findings))
+
+	answer, err = tc.ask("Export as (m)arkdown file, (s)hare link, or (c)ancel? ")
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "m", "markdown":
+		return exportRedactedMarkdown(sanitized)
+	case "s", "share", "link":
+		return tc.exportRedactedLink(sanitized)
+	default:
+		fmt.Println("Cancelled; nothing was exported.")
+		return nil
+	}
+}
+
+// reviewFindings shows each finding and returns those the user wants redacted,
+// or nil if the review was aborted
+func (tc *TerminalChat) reviewFindings(messages []api.Message, findings []redact.Finding) ([]redact.Finding, error) {
+	accepted := []redact.Finding{}
+	for i, f := range findings {
+		fmt.Printf("\n[%d/%d] %s in message %d (%s, found by %s)\n  %s\n",
+			i+1, len(findings), f.Kind, f.Message+1, messages[f.Message].Role, f.Source,
+			f.Context(messages, 30))
+		answer, err := tc.ask("Redact? [Y]es, (n)o, (a)ll remaining, (q)uit: ")
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "n", "no":
+		case "a", "all":
+			return append(accepted, findings[i:]...), nil
+		case "q", "quit":
+			return nil, nil
+		default:
+			accepted = append(accepted, f)
+		}
+	}
+	return accepted, nil
+}
+
+// exportRedactedMarkdown writes the sanitized conversation to a new file in the current directory
+func exportRedactedMarkdown(messages []api.Message) error {
+	path := fmt.Sprintf("hacka.re-redacted-%s.md", time.Now().Format("20060102-150405"))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	defer file.Close()
+
+	if err := transcript.WriteMarkdown(file, "Redacted conversation", messages); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	fmt.Printf("Saved redacted conversation to %s\n", path)
+	return nil
+}
+
+// exportRedactedLink creates a share link with the sanitized conversation and
+// the model settings, but no API key
+func (tc *TerminalChat) exportRedactedLink(messages []api.Message) error {
+	password, err := crypto.GenerateSecurePassword()
+	if err != nil {
+		return err
+	}
+
+	cfg := &share.SharedConfig{BaseURL: tc.config.BaseURL, Model: tc.config.Model}
+	for _, msg := range messages {
+		if msg.Role == "system" && cfg.SystemPrompt == "" {
+			cfg.SystemPrompt = msg.Content
+			continue
+		}
+		shared := share.Message{Role: msg.Role, Content: msg.Content}
+		if msg.Annotation != nil {
+			shared.Rating, shared.Note = msg.Annotation.Rating, msg.Annotation.Note
+		}
+		cfg.Messages = append(cfg.Messages, shared)
+	}
+
+	link, err := share.CreateShareableURL(cfg, password, "")
+	if err != nil {
+		return fmt.Errorf("failed to create share link: %w", err)
+	}
+	fmt.Printf("\nShare link (no API key included):\n%s\n\nPassword: %s\nSend the password separately from the link.\n", link, password)
+	return nil
+}
+
+// ask prints a question and reads one line of input in raw or simple mode
+func (tc *TerminalChat) ask(question string) (string, error) {
+	fmt.Print(question)
+	if tc.oldState != nil {
+		return tc.readLineWithFeatures()
+	}
+	if tc.stdin == nil {
+		tc.stdin = bufio.NewReader(os.Stdin)
+	}
+	line, err := tc.stdin.ReadString('\n')
+	return strings.TrimSpace(line), err
+}
+
+// isYes reports whether an answer is affirmative
+func isYes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,51 @@
+package chat
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/snippets"
+)
+
+// expandSnippet replaces the ;name before the cursor with its snippet
+func (tc *TerminalChat) expandSnippet() {
+	defined, err := snippets.Load(".")
+	if err != nil {
+		logger.Get().Warn("Failed to load snippets: %v", err)
+		return
+	}
+	expanded, cursor, _, ok := snippets.Expand(string(tc.currentLine), tc.cursorPos, defined)
+	if !ok {
+		return
+	}
+	tc.currentLine = []rune(expanded)
+	tc.cursorPos = cursor
+	tc.redrawLine()
+}
+
+// listSnippets shows the defined snippets and the files they come from
+func (tc *TerminalChat) listSnippets() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "."
+	}
+	defined, err := snippets.Load(cwd)
+	if err != nil {
+		return err
+	}
+	fmt.Println("\n════ Snippets ════")
+	if len(defined) == 0 {
+		fmt.Printf("No snippets yet. Define them in %s, or with /snippets edit in the TUI chat.\n", snippets.EditPath(cwd))
+		return nil
+	}
+	for _, name := range snippets.Names(defined) {
+		fmt.Printf("  %s%-12s \033[90m%s\033[0m\n", snippets.Trigger, name, preview(defined[name], 60))
+	}
+	fmt.Println()
+	for _, path := range snippets.Paths(cwd) {
+		fmt.Printf("\033[90mFrom %s\033[0m\n", path)
+	}
+	fmt.Printf("Type %sname and press Tab to expand it.\n", snippets.Trigger)
+	return nil
+}
@@ -3,17 +3,26 @@ package chat
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/signal"
 	"strings"
 	"sync"
-	"syscall"
+	"time"
 
 	"github.com/hacka-re/cli/internal/api"
 	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/failure"
+	"github.com/hacka-re/cli/internal/hooks"
+	"github.com/hacka-re/cli/internal/inputhistory"
+	"github.com/hacka-re/cli/internal/language"
+	"github.com/hacka-re/cli/internal/lineedit"
 	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/memory"
+	"github.com/hacka-re/cli/internal/promptlint"
+	"github.com/hacka-re/cli/internal/usage"
+	"github.com/hacka-re/cli/internal/utils"
 	"golang.org/x/term"
 )
 
@@ -30,13 +39,28 @@ type TerminalChat struct {
 	isStreaming    bool
 	commands       *CommandRegistry
 	modalHandlers  ModalHandlers
+	usage          *usage.Tracker
+	budgetOverride string              // Message the user may resend to bypass the budget
+	stdin          *bufio.Reader       // Line reader used outside raw mode
+	draftClient    *api.Client         // Local model for draft mode, nil if none is configured
+	draftMode      bool                // Draft locally, then verify with the main model
+	memory         *memory.Store       // Long-term facts, nil when memory is disabled
+	language       language.Session    // Reply language of this session, set with /lang
+	inputHistory   *inputhistory.Store // Input lines kept across runs, nil when disabled
+	hooks          *hooks.Hooks        // Scripts run on each message and reply, nil when none are set up
 
 	// Terminal state
-	currentLine    []rune
-	cursorPos      int
-	oldState       *term.State
-	termWidth      int
-	termHeight     int
+	currentLine []rune
+	cursorPos   int
+	oldState    *term.State
+	termWidth   int
+	termHeight  int
+	killRing    lineedit.KillRing // Text removed with Ctrl+K, Ctrl+W and the like
+	lastEdit    lastEdit          // What the previous key did
+	yankStart   int               // Where the text of the last Ctrl+Y starts
+	keys        *bufio.Reader     // Keys read in raw mode
+	pastes      []paste           // Pastes in the line, shown as placeholders
+	stale       bool              // The line changed since it was last drawn
 }
 
 // NewTerminalChat creates a new terminal chat session
@@ -56,21 +80,54 @@ func NewTerminalChat(cfg *config.Config) *TerminalChat {
 		history:     []string{},
 		historyPos:  -1,
 		commands:    NewCommandRegistry(),
+		usage:       usage.NewTracker(usage.DefaultPath()),
 		currentLine: []rune{},
 		cursorPos:   0,
 		termWidth:   80,  // Default width
 		termHeight:  24,  // Default height
 	}
 
+	if draftCfg := cfg.DraftConfig(); draftCfg != nil {
+		chat.draftClient = api.NewClient(draftCfg)
+		chat.draftMode = true
+	}
+
+	if !cfg.DisableMemory {
+		chat.memory = memory.NewStore(memory.DefaultPath())
+	}
+
+	// Kiosks are shared, so nothing typed there is kept
+	if cfg.HistorySize >= 0 && !cfg.Kiosk {
+		chat.inputHistory = inputhistory.NewStore(inputhistory.DefaultPath(), cfg.HistorySize)
+		if lines, err := chat.inputHistory.Entries(cfg.Namespace); err == nil {
+			chat.history = lines
+		} else {
+			logger.Get().Warn("Failed to load input history: %v", err)
+		}
+	}
+
+	loaded, err := hooks.Load(hooks.Path())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: hooks are off: %v\n", err)
+	}
+	chat.hooks = loaded
+
+	setting, err := language.Parse(cfg.Language)
+	if err != nil {
+		logger.Get().Warn("Ignoring the language setting: %v", err)
+		setting = language.Off
+	}
+	chat.language.Setting = setting
+
 	// Register all commands
 	chat.registerCommands()
 
 	// Add system prompt if configured
-	if cfg.SystemPrompt != "" {
-		logger.Get().Info("Adding system prompt: %s", cfg.SystemPrompt)
+	if systemPrompt := chat.systemPrompt(); systemPrompt != "" {
+		logger.Get().Info("Adding system prompt: %s", systemPrompt)
 		chat.messages = append(chat.messages, api.Message{
 			Role:    "system",
-			Content: cfg.SystemPrompt,
+			Content: systemPrompt,
 		})
 	}
 
@@ -87,7 +144,7 @@ func (tc *TerminalChat) registerCommands() {
 		Description: "Open configuration menu",
 		Handler: func() error {
 			if tc.modalHandlers.OpenTUI != nil {
-				return tc.modalHandlers.OpenTUI()
+				return tc.openMenu(tc.modalHandlers.OpenTUI)
 			}
 			return fmt.Errorf("TUI handler not configured")
 		},
@@ -122,7 +179,7 @@ func (tc *TerminalChat) registerCommands() {
 		Description: "Exit the application",
 		Handler: func() error {
 			fmt.Println("\nGoodbye!")
-			os.Exit(0)
+			utils.Exit(0) // Restores the terminal from raw mode first
 			return nil
 		},
 	})
@@ -151,6 +208,21 @@ func (tc *TerminalChat) registerCommands() {
 			fmt.Println("  ▶ Mathematical (5 functions)")
 			fmt.Println("    ✓ calculate - Evaluate expressions")
 			fmt.Println("    ✓ factorial - Calculate factorial")
+			fmt.Println("  ▶ Security Utilities (8 functions)")
+			fmt.Println("    ✓ hash_text - Hash with md5, sha1 or sha256")
+			fmt.Println("    ✓ jwt_decode - Decode a JWT")
+			fmt.Println("    ✓ cidr_info - Calculate an IPv4 subnet")
+			fmt.Println("  ▶ Scan Data (6 functions)")
+			fmt.Println("    ✓ scan_find - Find hosts in ingested scans")
+			fmt.Println("    ✓ scan_host - Show one scanned host")
+			fmt.Println("    ✓ capture_summary - Flows, DNS and HTTP of a capture")
+			fmt.Println("  ▶ CVE Lookup (1 function)")
+			fmt.Println("    ✓ cve_lookup - CVSS scores from the NVD")
+			fmt.Println("  ▶ OSINT Lookups (3 functions)")
+			fmt.Println("    ✓ breach_check - Check an account with Have I Been Pwned")
+			fmt.Println("    ✓ crtsh_lookup - Certificates logged for a domain")
+			fmt.Println("  ▶ URL Reputation (1 function)")
+			fmt.Println("    ✓ url_reputation - Verdict on a URL, domain or IP")
 			fmt.Println("  ▶ MCP Adapters (3 functions)")
 			fmt.Println("    ✓ mcp_tool_call - Execute MCP tools")
 			fmt.Println("\nCustom Functions:")
@@ -226,6 +298,109 @@ func (tc *TerminalChat) registerCommands() {
 		},
 	})
 
+	// Redact command
+	tc.commands.Register(&Command{
+		Name:        "redact",
+		Aliases:     []string{"sanitize"},
+		Description: "Review secrets/PII and export a redacted copy",
+		Handler:     tc.redactConversation,
+	})
+	tc.commands.Register(&Command{
+		Name:        "handoff",
+		Description: "Review and edit the history before it goes to a new provider or model",
+		Handler:     tc.reviewHandoff,
+	})
+
+	// Draft command
+	tc.commands.Register(&Command{
+		Name:        "draft",
+		Description: "Toggle local drafting with remote verification",
+		Handler:     tc.toggleDraftMode,
+	})
+
+	// Memory commands
+	tc.commands.Register(&Command{
+		Name:        "memory",
+		Aliases:     []string{"mem"},
+		Description: "List and manage long-term memory",
+		Handler:     tc.manageMemory,
+	})
+	tc.commands.Register(&Command{
+		Name:        "remember",
+		Description: "Suggest facts from this chat to remember",
+		Handler:     tc.rememberFacts,
+	})
+
+	// Review commands
+	tc.commands.Register(&Command{
+		Name:        "annotate",
+		Aliases:     []string{"rate", "note"},
+		Description: "Rate a reply 👍/👎 and add a note for later review",
+		Handler:     tc.annotateReply,
+	})
+	tc.commands.Register(&Command{
+		Name:        "export",
+		Description: "Save the chat as markdown, or annotated replies as an eval dataset",
+		Handler:     tc.exportConversation,
+	})
+	tc.commands.Register(&Command{
+		Name:        "pin",
+		Description: "Always keep a message when the chat outgrows the context window",
+		Handler:     tc.pinMessage,
+	})
+	tc.commands.Register(&Command{
+		Name:        "unpin",
+		Description: "Remove a pin",
+		Handler:     tc.unpinMessage,
+	})
+	tc.commands.Register(&Command{
+		Name:        "history",
+		Description: "Show recent input, or 'clear' to forget the saved input of this namespace",
+		ArgsHandler: tc.manageHistory,
+	})
+	tc.commands.Register(&Command{
+		Name:        "snippets",
+		Description: "List the ;snippets that Tab expands in the input",
+		Handler:     tc.listSnippets,
+	})
+	tc.commands.Register(&Command{
+		Name:        "lint",
+		Description: "Toggle checking prompts for secrets and slips before sending",
+		Handler:     tc.toggleLint,
+	})
+	tc.commands.Register(&Command{
+		Name:        "lang",
+		Aliases:     []string{"language"},
+		Description: "Set the reply language of this session: a code like sv, auto or off",
+		ArgsHandler: tc.setLanguage,
+	})
+
+	tc.commands.Register(&Command{
+		Name:        "debug",
+		Description: "'request [message]' shows the JSON payload the next message would send",
+		ArgsHandler: tc.debugCommand,
+	})
+
+	tc.commands.Register(&Command{
+		Name:        "open-web",
+		Description: "Continue this conversation in the web app on a local server",
+		Handler:     tc.openWeb,
+	})
+
+	tc.commands.Register(&Command{
+		Name:        "cache",
+		Description: "Show the MCP tool result cache; 'clear [tool]' empties it",
+		ArgsHandler: tc.cacheCommand,
+	})
+
+	// Artifacts command
+	tc.commands.Register(&Command{
+		Name:        "artifacts",
+		Aliases:     []string{"files"},
+		Description: "List files saved this session and clean up old ones",
+		Handler:     tc.manageArtifacts,
+	})
+
 	// Share command
 	tc.commands.Register(&Command{
 		Name:        "share",
@@ -247,6 +422,11 @@ func (tc *TerminalChat) registerCommands() {
 			return nil
 		},
 	})
+
+	// A kiosk can only chat: no configuration menus, sharing, exports or memory changes
+	if tc.config.Kiosk {
+		tc.commands.Remove("menu", "functions", "share", "redact", "handoff", "export", "memory", "remember", "cache", "open-web")
+	}
 }
 
 // SetModalHandlers sets the modal handler functions
@@ -269,20 +449,17 @@ func (tc *TerminalChat) Run() error {
 		// Fall back to simple mode if raw mode fails
 		return tc.runSimpleMode()
 	}
-	defer term.Restore(int(os.Stdin.Fd()), tc.oldState)
+	// Restore the terminal on return and on every other exit path (signals, panics, utils.Exit)
+	restoreTerminal := utils.OnExit(func() {
+		fmt.Print(lineedit.DisableBracketedPaste)
+		term.Restore(int(os.Stdin.Fd()), tc.oldState)
+	})
+	defer restoreTerminal()
 	logger.Get().Info("Terminal in raw mode")
+	tc.keys = bufio.NewReader(os.Stdin)
 
-	// Setup signal handling
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		<-sigChan
-		// Restore terminal before exit
-		term.Restore(int(os.Stdin.Fd()), tc.oldState)
-		fmt.Println("\n\nUse /exit to quit the application")
-		os.Exit(0)
-	}()
+	// Signals while in raw mode should still leave a usable shell
+	utils.HandleSignals()
 
 	// Show welcome
 	tc.showWelcome()
@@ -323,36 +500,36 @@ func (tc *TerminalChat) Run() error {
 	}
 }
 
-// readLineWithFeatures reads a line with autocomplete and history support
+// readLineWithFeatures reads a line with autocomplete, history and
+// emacs-style editing
 func (tc *TerminalChat) readLineWithFeatures() (string, error) {
 	tc.currentLine = []rune{}
 	tc.cursorPos = 0
+	tc.lastEdit = editOther
+	tc.pastes = nil
 
-	// Buffer for reading input
-	buf := make([]byte, 1)
+	// Set on every line, since a modal view may have turned it off
+	fmt.Print(lineedit.EnableBracketedPaste)
 
 	for {
-		// Read one byte
-		n, err := os.Stdin.Read(buf)
+		key, err := lineedit.ReadKey(tc.keys)
 		if err != nil {
 			return "", err
 		}
-		if n == 0 {
-			continue
-		}
 
-		b := buf[0]
-
-		// Handle special keys
-		switch b {
-		case 0x0D, 0x0A: // Enter
-			line := string(tc.currentLine)
+		switch {
+		case key.Code == lineedit.KeyEnter:
+			if tc.stale {
+				tc.redrawLine()
+			}
+			line := tc.expandPastes(string(tc.currentLine))
 			fmt.Println() // New line after input
 
 			// Check for autocomplete if it's a partial command
 			if IsCommand(line) {
-				cmdStr, _ := ParseCommand(line)
-				if fullCmd, cmd := tc.commands.Autocomplete(cmdStr); cmd != nil && fullCmd != line {
+				cmdStr, args := ParseCommand(line)
+				if fullCmd, cmd := tc.commands.Autocomplete(cmdStr); cmd != nil && withArgs(fullCmd, args) != line {
+					fullCmd = withArgs(fullCmd, args)
 					// Show autocomplete suggestion
 					fmt.Printf("Executing: %s\n", fullCmd)
 					return fullCmd, nil
@@ -361,69 +538,38 @@ func (tc *TerminalChat) readLineWithFeatures() (string, error) {
 
 			return line, nil
 
-		case 0x09: // Tab - autocomplete
+		case key.Code == lineedit.KeyTab: // Autocomplete, or expand a ;snippet
+			tc.lastEdit = editOther
 			line := string(tc.currentLine)
 			if IsCommand(line) {
 				tc.handleAutocomplete()
+			} else {
+				tc.expandSnippet()
 			}
 
-		case 0x1B: // Escape sequence (arrow keys, etc.)
-			// Read next bytes for escape sequence
-			seq := make([]byte, 2)
-			os.Stdin.Read(seq)
-
-			if seq[0] == '[' {
-				switch seq[1] {
-				case 'A': // Up arrow - previous history
-					tc.navigateHistory(-1)
-				case 'B': // Down arrow - next history
-					tc.navigateHistory(1)
-				case 'C': // Right arrow
-					if tc.cursorPos < len(tc.currentLine) {
-						tc.cursorPos++
-						tc.redrawLine()
-					}
-				case 'D': // Left arrow
-					if tc.cursorPos > 0 {
-						tc.cursorPos--
-						tc.redrawLine()
-					}
-				}
-			}
+		case key.Code == lineedit.KeyUp && !key.Alt, key.IsCtrl('p'): // Previous history
+			tc.lastEdit = editOther
+			tc.navigateHistory(-1)
 
-		case 0x7F, 0x08: // Backspace
-			if tc.cursorPos > 0 {
-				// Remove character before cursor
-				tc.currentLine = append(tc.currentLine[:tc.cursorPos-1], tc.currentLine[tc.cursorPos:]...)
-				tc.cursorPos--
-				tc.redrawLine()
-			}
+		case key.Code == lineedit.KeyDown && !key.Alt, key.IsCtrl('n'): // Next history
+			tc.lastEdit = editOther
+			tc.navigateHistory(1)
 
-		case 0x03: // Ctrl+C
+		case key.IsCtrl('c'):
 			return "", io.EOF
 
-		case 0x04: // Ctrl+D
-			if len(tc.currentLine) == 0 {
-				return "", io.EOF
-			}
-
-		case 0x15: // Ctrl+U - clear line
-			tc.currentLine = []rune{}
-			tc.cursorPos = 0
-			tc.redrawLine()
+		case key.IsCtrl('d') && len(tc.currentLine) == 0:
+			return "", io.EOF
 
-		case 0x17: // Ctrl+W - delete word
-			tc.deleteWord()
+		case key.IsCtrl('r'): // Reverse search
+			tc.lastEdit = editOther
+			if line, execute := tc.reverseSearch(); execute {
+				fmt.Println()
+				return line, nil
+			}
 
 		default:
-			// Regular character
-			if b >= 0x20 && b < 0x7F {
-				// Insert character at cursor position
-				tc.currentLine = append(tc.currentLine[:tc.cursorPos],
-					append([]rune{rune(b)}, tc.currentLine[tc.cursorPos:]...)...)
-				tc.cursorPos++
-				tc.redrawLine()
-			}
+			tc.editKey(key)
 		}
 	}
 }
@@ -512,6 +658,8 @@ func (tc *TerminalChat) updateTerminalSize() {
 
 // redrawLine redraws the current input line
 func (tc *TerminalChat) redrawLine() {
+	tc.stale = false
+
 	// Clear current line completely
 	fmt.Print("\r\033[K")
 
@@ -520,7 +668,10 @@ func (tc *TerminalChat) redrawLine() {
 	promptLen := len(prompt)
 	fmt.Print(prompt)
 
-	line := string(tc.currentLine)
+	// Snippets may add line breaks; they show as spaces on the one input line.
+	// Lengths are in runes, so non-ASCII input keeps the cursor in place.
+	text := strings.ReplaceAll(string(tc.currentLine), "\n", " ")
+	line := []rune(text)
 	availableWidth := tc.termWidth - promptLen - 1 // Leave 1 char margin
 
 	// Handle line wrapping for display
@@ -546,40 +697,40 @@ func (tc *TerminalChat) redrawLine() {
 			end = len(line)
 		}
 
-		displayLine = line[start:end]
+		displayLine = append([]rune{}, line[start:end]...)
 		displayCursor = tc.cursorPos - start
 
 		// Add indicators for more content
 		if start > 0 {
-			displayLine = "…" + displayLine[1:]
+			displayLine[0] = '…'
 		}
 		if end < len(line) {
-			displayLine = displayLine[:len(displayLine)-1] + "…"
+			displayLine[len(displayLine)-1] = '…'
 		}
 	}
 
 	// Check for autocomplete hint (only if at end of line)
-	if IsCommand(line) && tc.cursorPos == len(tc.currentLine) && len(displayLine) < availableWidth-10 {
-		cmdStr, _ := ParseCommand(line)
-		if fullCmd, cmd := tc.commands.Autocomplete(cmdStr); cmd != nil && fullCmd != line && strings.HasPrefix(fullCmd, line) {
+	if IsCommand(text) && tc.cursorPos == len(tc.currentLine) && len(displayLine) < availableWidth-10 {
+		cmdStr, _ := ParseCommand(text)
+		if fullCmd, cmd := tc.commands.Autocomplete(cmdStr); cmd != nil && fullCmd != text && strings.HasPrefix(fullCmd, text) {
 			// Show the typed part normally
-			fmt.Print(displayLine)
+			fmt.Print(string(displayLine))
 			// Show the autocomplete suggestion in dim (truncate if needed)
-			hint := fullCmd[len(line):]
+			hint := []rune(fullCmd[len(text):])
 			remainingSpace := availableWidth - len(displayLine)
 			if len(hint) > remainingSpace {
 				hint = hint[:remainingSpace]
 			}
-			fmt.Printf("\033[2m%s\033[0m", hint)
+			fmt.Printf("\033[2m%s\033[0m", string(hint))
 			// Move cursor back to end of typed part
 			if len(hint) > 0 {
 				fmt.Printf("\033[%dD", len(hint))
 			}
 		} else {
-			fmt.Print(displayLine)
+			fmt.Print(string(displayLine))
 		}
 	} else {
-		fmt.Print(displayLine)
+		fmt.Print(string(displayLine))
 	}
 
 	// Position cursor correctly
@@ -592,43 +743,24 @@ func (tc *TerminalChat) redrawLine() {
 	}
 }
 
-// deleteWord deletes the word before cursor
-func (tc *TerminalChat) deleteWord() {
-	if tc.cursorPos == 0 {
-		return
-	}
-
-	// Find start of word
-	start := tc.cursorPos - 1
-	for start > 0 && tc.currentLine[start] == ' ' {
-		start--
-	}
-	for start > 0 && tc.currentLine[start-1] != ' ' {
-		start--
-	}
-
-	// Delete from start to cursor
-	tc.currentLine = append(tc.currentLine[:start], tc.currentLine[tc.cursorPos:]...)
-	tc.cursorPos = start
-	tc.redrawLine()
-}
-
-// addToHistory adds a line to history
+// addToHistory adds a line to history, moving an earlier copy of it to the
+// end, and saves it for later runs unless it contains a secret
 func (tc *TerminalChat) addToHistory(line string) {
-	// Don't add empty lines or duplicates
 	if line == "" {
 		return
 	}
-	if len(tc.history) > 0 && tc.history[len(tc.history)-1] == line {
-		return
+	max := tc.config.HistorySize
+	if max <= 0 {
+		max = inputhistory.DefaultMaxSize
 	}
-
-	tc.history = append(tc.history, line)
+	tc.history = inputhistory.Append(tc.history, line, max)
 	tc.historyPos = -1 // Reset position
 
-	// Limit history size
-	if len(tc.history) > 100 {
-		tc.history = tc.history[1:]
+	if tc.inputHistory == nil || len(promptlint.Secrets(line)) > 0 {
+		return
+	}
+	if err := tc.inputHistory.Add(tc.config.Namespace, line); err != nil {
+		logger.Get().Warn("Failed to save input history: %v", err)
 	}
 }
 
@@ -651,6 +783,12 @@ func (tc *TerminalChat) showWelcome() {
 	// Simplified welcome - no borders, just essential info
 	fmt.Println("Chat started. Type /help for commands, /exit to quit.")
 	fmt.Println()
+
+	// Show where the conversation left off, such as a template's opening message
+	if n := len(tc.messages); n > 0 && tc.messages[n-1].Role == "assistant" {
+		fmt.Println(tc.messages[n-1].Content)
+		fmt.Println()
+	}
 }
 
 // createBorder creates a border line that fits the terminal width
@@ -706,7 +844,8 @@ func (tc *TerminalChat) printFormatted(format string, args ...interface{}) {
 // runSimpleMode runs in simple mode if raw mode is not available
 func (tc *TerminalChat) runSimpleMode() error {
 	logger.Get().Info("Running in simple mode (fallback)")
-	reader := bufio.NewReader(os.Stdin)
+	tc.stdin = bufio.NewReader(os.Stdin)
+	reader := tc.stdin
 
 	// Show welcome
 	tc.showWelcome()
@@ -732,8 +871,9 @@ func (tc *TerminalChat) runSimpleMode() error {
 
 		// Check for command with autocomplete
 		if IsCommand(input) {
-			cmdStr, _ := ParseCommand(input)
-			if fullCmd, cmd := tc.commands.Autocomplete(cmdStr); cmd != nil && fullCmd != input {
+			cmdStr, args := ParseCommand(input)
+			if fullCmd, cmd := tc.commands.Autocomplete(cmdStr); cmd != nil && withArgs(fullCmd, args) != input {
+				fullCmd = withArgs(fullCmd, args)
 				fmt.Printf("Executing: %s\n", fullCmd)
 				input = fullCmd
 			}
@@ -746,7 +886,7 @@ func (tc *TerminalChat) runSimpleMode() error {
 
 // handleCommand processes slash commands
 func (tc *TerminalChat) handleCommand(input string) {
-	cmdStr, _ := ParseCommand(input)
+	cmdStr, args := ParseCommand(input)
 
 	cmd := tc.commands.GetCommand(cmdStr)
 	if cmd == nil {
@@ -756,11 +896,42 @@ func (tc *TerminalChat) handleCommand(input string) {
 	}
 
 	// Execute the command
-	if err := cmd.Handler(); err != nil {
+	var err error
+	if cmd.ArgsHandler != nil {
+		err = cmd.ArgsHandler(strings.TrimSpace(args))
+	} else {
+		err = cmd.Handler()
+	}
+	if err != nil {
 		fmt.Printf("Error executing command: %v\n", err)
 	}
 }
 
+// withArgs puts the arguments back after an autocompleted command
+func withArgs(cmd, args string) string {
+	if args == "" {
+		return cmd
+	}
+	return cmd + " " + args
+}
+
+// LoadHistory continues an earlier conversation, e.g. one imported from another tool.
+// The configured system prompt is kept unless the history brings its own.
+func (tc *TerminalChat) LoadHistory(messages []api.Message) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.messages = []api.Message{}
+	if systemPrompt := tc.systemPrompt(); systemPrompt != "" && (len(messages) == 0 || messages[0].Role != "system") {
+		tc.messages = append(tc.messages, api.Message{
+			Role:    "system",
+			Content: systemPrompt,
+		})
+	}
+	tc.messages = append(tc.messages, messages...)
+	logger.Get().Info("Loaded %d messages of history", len(messages))
+}
+
 // clearChat clears the chat history
 func (tc *TerminalChat) clearChat() {
 	logger.Get().Info("Clearing chat history")
@@ -768,13 +939,14 @@ func (tc *TerminalChat) clearChat() {
 	tc.messages = []api.Message{}
 
 	// Re-add system prompt if configured
-	if tc.config.SystemPrompt != "" {
+	systemPrompt := tc.systemPrompt()
+	if systemPrompt != "" {
 		tc.messages = append(tc.messages, api.Message{
 			Role:    "system",
-			Content: tc.config.SystemPrompt,
+			Content: systemPrompt,
 		})
 	}
-	logger.Get().Info("Cleared %d messages, kept system prompt: %v", oldCount, tc.config.SystemPrompt != "")
+	logger.Get().Info("Cleared %d messages, kept system prompt: %v", oldCount, systemPrompt != "")
 
 	// Clear screen - simplified display
 	fmt.Print("\033[2J\033[H")
@@ -793,6 +965,21 @@ func (tc *TerminalChat) processMessage(input string) {
 		logger.Get().Debug("  Message[%d] Role=%s, Content='%s'", i, msg.Role, msg.Content)
 	}
 
+	// Check the prompt before it leaves the machine
+	if tc.config.LintPrompts {
+		checked, send := tc.lintPrompt(input)
+		if !send {
+			return
+		}
+		input = checked
+	}
+
+	// Let the user's hooks change or stop the message
+	input, send := tc.beforeSend(input)
+	if !send {
+		return
+	}
+
 	// Add user message
 	tc.messages = append(tc.messages, api.Message{
 		Role:    "user",
@@ -800,6 +987,20 @@ func (tc *TerminalChat) processMessage(input string) {
 	})
 	logger.Get().Info("Added user message, total now: %d", len(tc.messages))
 
+	// Enforce budget limits; resending the same message overrides
+	promptTokens := estimatePromptTokens(tc.messages)
+	if err := tc.usage.Check(tc.config.Model, promptTokens, tc.budget()); err != nil {
+		if tc.budgetOverride != input {
+			logger.Get().Warn("Request blocked: %v", err)
+			tc.messages = tc.messages[:len(tc.messages)-1]
+			tc.budgetOverride = input
+			fmt.Printf("\n%v\nSend the same message again to override.\n", err)
+			return
+		}
+		logger.Get().Warn("Budget override: %v", err)
+	}
+	tc.budgetOverride = ""
+
 	// Create context for this request
 	ctx, cancel := context.WithCancel(context.Background())
 	tc.mu.Lock()
@@ -855,13 +1056,30 @@ func (tc *TerminalChat) processMessage(input string) {
 		callback = streamCallback
 	}
 
-	logger.Get().Info("Calling SendChatCompletion with %d messages", len(tc.messages))
+	if tc.draftMode && tc.draftAndVerify(ctx, callback, &fullResponse) {
+		return
+	}
+
+	messages := tc.withReplyLanguage(tc.contextMessages(), input)
+	logger.Get().Info("Calling SendChatCompletion with %d messages", len(messages))
 	logger.Get().Info("Stream mode: %v", tc.config.StreamResponse)
 
-	response, err := tc.client.SendChatCompletion(tc.messages, callback)
+	startTime := time.Now()
+	response, err := tc.client.SendChatCompletion(messages, callback)
 	if err != nil {
 		logger.Get().Error("API call failed: %v", err)
-		fmt.Printf("\nError: %v\n", err)
+
+		// Keep the part of the reply that made it through before the stream dropped
+		if errors.Is(err, api.ErrStreamInterrupted) && fullResponse.Len() > 0 {
+			tc.messages = append(tc.messages, api.Message{
+				Role:    "assistant",
+				Content: fullResponse.String(),
+			})
+			fmt.Printf("\n[Response interrupted: %v]\n", err)
+			return
+		}
+
+		fmt.Printf("\nError: %s\n", failure.Message(err))
 		return
 	}
 
@@ -876,6 +1094,66 @@ func (tc *TerminalChat) processMessage(input string) {
 
 	tc.messages = append(tc.messages, api.Message{
 		Role:    "assistant",
-		Content: responseText,
+		Content: tc.afterReceive(responseText),
 	})
+
+	tc.recordUsage(response, promptTokens, responseText)
+	tc.notifyIfSlow(startTime)
+}
+
+// budget returns the configured budget limits
+func (tc *TerminalChat) budget() usage.Budget {
+	return usage.Budget{
+		MaxTokensPerRequest: tc.config.MaxTokensPerRequest,
+		MaxCostPerSession:   tc.config.MaxCostPerSession,
+		MaxCostPerDay:       tc.config.MaxCostPerDay,
+	}
+}
+
+// recordUsage adds the request to the usage tracker and shows the remaining budget
+func (tc *TerminalChat) recordUsage(response *api.ChatResponse, promptTokens int, responseText string) {
+	completionTokens := usage.EstimateTokens(responseText)
+	if response != nil && response.Usage.TotalTokens > 0 {
+		promptTokens = response.Usage.PromptTokens
+		completionTokens = response.Usage.CompletionTokens
+	}
+	cachedTokens := response.CachedTokens()
+	tc.usage.RecordCached(tc.config.Model, promptTokens, cachedTokens, completionTokens)
+
+	if cachedTokens > 0 {
+		_, saved := tc.usage.SessionCache()
+		fmt.Printf("\033[90m[Prompt cache: %d of %d prompt tokens cached, $%.4f saved this session]\033[0m\n",
+			cachedTokens, promptTokens, saved)
+	}
+
+	if remaining := tc.usage.Remaining(tc.budget()); remaining != "" {
+		fmt.Printf("\033[90m[Budget: %s]\033[0m\n", remaining)
+	}
+}
+
+// estimatePromptTokens estimates the tokens sent for a conversation
+func estimatePromptTokens(messages []api.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += usage.EstimateTokens(msg.Content)
+	}
+	return total
+}
+
+// notifyIfSlow sends a desktop notification when a response took longer than the configured threshold.
+// Stdin isn't read while streaming, so terminal focus can't be tracked here; the threshold alone decides.
+func (tc *TerminalChat) notifyIfSlow(startTime time.Time) {
+	if !tc.config.NotifyOnComplete {
+		return
+	}
+
+	elapsed := time.Since(startTime)
+	if elapsed < tc.config.NotifyThreshold() {
+		return
+	}
+
+	message := fmt.Sprintf("Your answer is ready (took %s)", elapsed.Round(time.Second))
+	if err := utils.SendDesktopNotification("hacka.re", message); err != nil {
+		logger.Get().Warn("Failed to send desktop notification: %v", err)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,242 @@
+// Package classroom lets an instructor publish an encrypted share link on the
+// LAN under a short code, and students fetch it with `hacka.re join CODE`.
+//
+// The instructor's `serve --classroom` serves the link at /classroom/CODE and
+// answers UDP broadcasts on DiscoveryPort, so students only need the code.
+// The link stays encrypted end to end: students still need the passphrase,
+// which the instructor tells them out of band.
+package classroom
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+const (
+	// DiscoveryPort is the UDP port instructors listen on for join broadcasts
+	DiscoveryPort = 47813
+
+	// PathPrefix is where a published link is served, followed by the code
+	PathPrefix = "/classroom/"
+
+	// codeAlphabet leaves out 0/O, 1/I/L and other letters easily misread when
+	// written on a whiteboard
+	codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	codeLength   = 6
+
+	discoveryMagic = "HACKARE-JOIN "
+	maxLinkSize    = 1 << 20
+)
+
+// ErrNotFound is returned when no instructor on the network publishes the code
+var ErrNotFound = errors.New("no classroom with that code found on the network")
+
+// NewCode returns a random code such as "K7P-4QX"
+func NewCode() string {
+	var random [codeLength]byte
+	rand.Read(random[:])
+	code := make([]byte, 0, codeLength+1)
+	for i, b := range random {
+		if i == codeLength/2 {
+			code = append(code, '-')
+		}
+		code = append(code, codeAlphabet[int(b)%len(codeAlphabet)])
+	}
+	return string(code)
+}
+
+// NormalizeCode uppercases a code and drops dashes and spaces, so "k7p 4qx"
+// and "K7P-4QX" match
+func NormalizeCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return r
+	}, code)
+}
+
+// announcement is the reply to a join broadcast
+type announcement struct {
+	Code string `json:"code"`
+	URL  string `json:"url"`
+}
+
+// published is the body served at PathPrefix + code
+type published struct {
+	Link string `json:"link"`
+}
+
+// Publisher serves one encrypted share link under a code
+type Publisher struct {
+	code string
+	link string
+}
+
+// NewPublisher publishes link (a share URL or gpt= fragment) under code
+func NewPublisher(code, link string) *Publisher {
+	return &Publisher{code: NormalizeCode(code), link: link}
+}
+
+// Code returns the code students join with
+func (p *Publisher) Code() string {
+	return p.code
+}
+
+// ServeHTTP serves the link at PathPrefix + code and 404s everything else
+func (p *Publisher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	code := NormalizeCode(strings.TrimPrefix(r.URL.Path, PathPrefix))
+	if r.Method != http.MethodGet || code != p.code {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(published{Link: p.link})
+}
+
+// Wrap serves the link in front of next
+func (p *Publisher) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, PathPrefix) {
+			p.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Announce answers join broadcasts for the publisher's code on addr (e.g.
+// ":47813") with baseURL, where the link is served, until ctx is done
+func (p *Publisher) Announce(ctx context.Context, addr, baseURL string) error {
+	conn, err := net.ListenPacket("udp4", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for join requests: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	reply, _ := json.Marshal(announcement{Code: p.code, URL: strings.TrimSuffix(baseURL, "/") + PathPrefix + p.code})
+	buf := make([]byte, 512)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		request := string(buf[:n])
+		if !strings.HasPrefix(request, discoveryMagic) || NormalizeCode(strings.TrimSpace(request[len(discoveryMagic):])) != p.code {
+			continue
+		}
+		logger.Get().Info("[Classroom] Join request from %s", from)
+		conn.WriteTo(reply, from)
+	}
+}
+
+// Discover broadcasts a join request for code to addr (normally
+// 255.255.255.255:DiscoveryPort) and returns the URL the instructor serves
+// the link at
+func Discover(ctx context.Context, code, addr string) (string, error) {
+	code = NormalizeCode(code)
+	target, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return "", err
+	}
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	request := []byte(discoveryMagic + code)
+	buf := make([]byte, 2048)
+	// Broadcasts get lost on busy Wi-Fi, so ask again every second
+	for {
+		if _, err := conn.WriteTo(request, target); err != nil {
+			return "", fmt.Errorf("failed to send join request: %w", err)
+		}
+
+		deadline := time.Now().Add(time.Second)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		conn.SetReadDeadline(deadline)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				break
+			}
+			var reply announcement
+			if json.Unmarshal(buf[:n], &reply) == nil && reply.Code == code && reply.URL != "" {
+				return reply.URL, nil
+			}
+		}
+
+		if ctx.Err() != nil {
+			return "", ErrNotFound
+		}
+	}
+}
+
+// Fetch downloads the encrypted link published at url
+func Fetch(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := netguard.Client(0).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("instructor returned status %d", resp.StatusCode)
+	}
+	var body published
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxLinkSize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid response from instructor: %w", err)
+	}
+	if body.Link == "" {
+		return "", errors.New("instructor published an empty link")
+	}
+	return body.Link, nil
+}
+
+// LANAddress returns this machine's address on the local network, for the URL
+// students are sent to, or "" if there is none
+func LANAddress() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			if ip := ipNet.IP.To4(); ip != nil && ip.IsPrivate() {
+				return ip.String()
+			}
+		}
+	}
+	return ""
+}
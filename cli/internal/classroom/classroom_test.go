@@ -0,0 +1,94 @@
+package classroom
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewCode(t *testing.T) {
+	code := NewCode()
+	if len(code) != codeLength+1 || code[codeLength/2] != '-' {
+		t.Fatalf("code = %q, want XXX-XXX", code)
+	}
+	for _, r := range NormalizeCode(code) {
+		if !strings.ContainsRune(codeAlphabet, r) {
+			t.Errorf("code %q has %q outside the alphabet", code, r)
+		}
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	if got := NormalizeCode("k7p 4qx"); got != "K7P4QX" {
+		t.Errorf("NormalizeCode = %q", got)
+	}
+	if NormalizeCode("K7P-4QX") != NormalizeCode("k7p4qx") {
+		t.Error("codes with and without dashes should match")
+	}
+}
+
+func TestPublisherServesOnlyItsCode(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("web ui"))
+	})
+	server := httptest.NewServer(NewPublisher("K7P-4QX", "https://hacka.re/#gpt=abc").Wrap(next))
+	defer server.Close()
+
+	ctx := context.Background()
+	link, err := Fetch(ctx, server.URL+PathPrefix+"k7p4qx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link != "https://hacka.re/#gpt=abc" {
+		t.Errorf("link = %q", link)
+	}
+
+	if _, err := Fetch(ctx, server.URL+PathPrefix+"WRONG1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("wrong code: err = %v, want ErrNotFound", err)
+	}
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("other paths should reach the wrapped handler, got %d", resp.StatusCode)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	// Reserve a free port, then hand it to Announce
+	probe, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := probe.LocalAddr().String()
+	probe.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	publisher := NewPublisher("K7P-4QX", "gpt=abc")
+	go publisher.Announce(ctx, addr, "http://10.0.0.5:8080/")
+
+	discoverCtx, cancelDiscover := context.WithTimeout(ctx, 3*time.Second)
+	defer cancelDiscover()
+	url, err := Discover(discoverCtx, "k7p-4qx", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "http://10.0.0.5:8080/classroom/K7P4QX" {
+		t.Errorf("url = %q", url)
+	}
+
+	shortCtx, cancelShort := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancelShort()
+	if _, err := Discover(shortCtx, "OTHER1", addr); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown code: err = %v, want ErrNotFound", err)
+	}
+}
@@ -8,7 +8,11 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/hacka-re/cli/internal/artifacts"
+	"github.com/hacka-re/cli/internal/csp"
+	"github.com/hacka-re/cli/internal/sampling"
 	"github.com/hacka-re/cli/internal/share"
 )
 
@@ -56,6 +60,7 @@ type Config struct {
 	Model       string   `json:"model"`
 	MaxTokens   int      `json:"maxTokens"`
 	Temperature float64  `json:"temperature"`
+	Seed        int      `json:"seed,omitempty"` // Sampling seed for providers that support one; 0 for none
 
 	// UI Configuration
 	Theme          string `json:"theme"`
@@ -64,17 +69,71 @@ type Config struct {
 	// System Configuration
 	SystemPrompt string `json:"systemPrompt"`
 	Namespace    string `json:"namespace,omitempty"`
+	Language     string `json:"language,omitempty"` // Reply language: a code like sv, auto to follow the user, or off (default)
 
 	// Features
 	YoloMode       bool `json:"yoloMode"`       // Auto-execute functions
 	VoiceControl   bool `json:"voiceControl"`   // Voice input
 	StreamResponse bool `json:"streamResponse"` // Stream API responses
 
+	// Prompt caching: mark the system prompt for provider caches (on unless disabled)
+	DisablePromptCache bool `json:"disablePromptCache,omitempty"`
+
+	// Check prompts for pasted secrets, open code fences and the like before sending
+	LintPrompts bool `json:"lintPrompts,omitempty"`
+
+	// Lines of terminal chat input kept per namespace across runs (0: 1000, negative: none)
+	HistorySize int `json:"historySize,omitempty"`
+
+	// Long-term memory: approved facts are added to the system prompt (on unless disabled)
+	DisableMemory bool `json:"disableMemory,omitempty"`
+
+	// Draft locally, verify remotely: a local model answers first and the main model refines it
+	DraftModel    string   `json:"draftModel,omitempty"`
+	DraftProvider Provider `json:"draftProvider,omitempty"` // Default: detected from draftBaseUrl
+	DraftBaseURL  string   `json:"draftBaseUrl,omitempty"`  // Default: the draft provider's URL
+
+	// Network timeouts in seconds (0 uses the default)
+	ConnectTimeout    int `json:"connectTimeout,omitempty"`    // Time to establish a connection
+	ReadTimeout       int `json:"readTimeout,omitempty"`       // Time to wait for response headers
+	StreamIdleTimeout int `json:"streamIdleTimeout,omitempty"` // Max silence between streamed chunks
+
+	// Budget limits (0 means unlimited)
+	MaxTokensPerRequest int     `json:"maxTokensPerRequest,omitempty"` // Estimated prompt tokens per request
+	MaxCostPerSession   float64 `json:"maxCostPerSession,omitempty"`   // USD per chat session
+	MaxCostPerDay       float64 `json:"maxCostPerDay,omitempty"`       // USD per calendar day
+
+	// Desktop notifications
+	NotifyOnComplete   bool `json:"notifyOnComplete"`             // Notify when a slow response finishes
+	NotifyAfterSeconds int  `json:"notifyAfterSeconds,omitempty"` // Minimum response time before notifying
+
+	// Artifact cleanup (0 uses the default, negative means no limit)
+	ArtifactsMaxAgeDays int `json:"artifactsMaxAgeDays,omitempty"` // Remove sessions older than this
+	ArtifactsMaxSizeMB  int `json:"artifactsMaxSizeMb,omitempty"`  // Then remove the oldest until all fit
+
+	// Llamafile server options for offline mode (0 keeps the llamafile default)
+	LlamafileContextSize int    `json:"llamafileContextSize,omitempty"` // --ctx-size
+	LlamafileThreads     int    `json:"llamafileThreads,omitempty"`     // --threads
+	LlamafileGPULayers   int    `json:"llamafileGpuLayers,omitempty"`   // --n-gpu-layers
+	LlamafileGPU         string `json:"llamafileGpu,omitempty"`         // --gpu: auto, apple, amd, nvidia or disable
+
 	// Offline mode settings (not serialized)
 	IsOfflineMode         bool `json:"-"` // Offline mode flag
 	AllowRemoteMCP        bool `json:"-"` // Allow remote MCP in offline mode
 	AllowRemoteEmbeddings bool `json:"-"` // Allow remote embeddings in offline mode
 
+	// Kiosk mode (not serialized): a read-only demo of the saved configuration.
+	// Settings, prompts and functions can't be changed, and nothing is shared or saved.
+	Kiosk bool `json:"-"`
+
+	// System prompt of this session only (not serialized), set by chat --template.
+	// It replaces SystemPrompt without changing the saved one.
+	SessionSystemPrompt string `json:"-"`
+
+	// Sampling of this session only (not serialized), set by a template or an
+	// agent. It is merged over Temperature as each request is built.
+	SessionSampling sampling.Override `json:"-"`
+
 	// Function Calling
 	Functions        []share.Function        `json:"functions,omitempty"`
 	DefaultFunctions map[string]bool         `json:"defaultFunctions,omitempty"`
@@ -86,36 +145,46 @@ type Config struct {
 	RAGEnabled   bool     `json:"ragEnabled"`
 	RAGDocuments []string `json:"ragDocuments,omitempty"`
 
+	// Share link lock: the link's creator made prompts, functions and provider read-only
+	LockedByLink bool   `json:"lockedByLink,omitempty"`
+	UnlockHash   string `json:"unlockHash,omitempty"` // Override password hash, see sharelink.CheckOverride
+
 	// MCP Servers
 	MCPServers []MCPServer `json:"mcpServers,omitempty"`
 
 	// API Keys for services
-	ShodanAPIKey string `json:"shodanApiKey,omitempty"`
+	ShodanAPIKey     string `json:"shodanApiKey,omitempty"`
+	VirusTotalAPIKey string `json:"virusTotalApiKey,omitempty"` // URL reputation, see internal/reputation
+	URLScanAPIKey    string `json:"urlscanApiKey,omitempty"`    // Optional for urlscan.io searches
+
+	// Security headers sent by serve and browse (strict unless relaxed here)
+	SecurityHeaders *csp.Options `json:"securityHeaders,omitempty"`
 
 	// File path for persistence
 	ConfigFile string `json:"-"`
 }
 
+// DefaultNotifyAfterSeconds is used when no notification threshold is
+// configured, by the terminal chat and the TUI
+const DefaultNotifyAfterSeconds = 30
+
 // MCPServer represents a Model Context Protocol server
-type MCPServer struct {
-	Name    string `json:"name"`
-	URL     string `json:"url"`
-	Enabled bool   `json:"enabled"`
-}
+type MCPServer = share.MCPServer
 
 // NewConfig creates a new configuration with defaults
 func NewConfig() *Config {
 	return &Config{
-		Provider:       ProviderOpenAI,
-		BaseURL:        Providers[ProviderOpenAI].BaseURL,
-		Model:          "gpt-4",
-		MaxTokens:      2048,
-		Temperature:    0.7,
-		Theme:          "modern",
-		StreamResponse: true,
-		Functions:      []share.Function{},
-		Prompts:        []share.Prompt{},
-		MCPServers:     []MCPServer{},
+		Provider:           ProviderOpenAI,
+		BaseURL:            Providers[ProviderOpenAI].BaseURL,
+		Model:              "gpt-4",
+		MaxTokens:          2048,
+		Temperature:        0.7,
+		Theme:              "modern",
+		StreamResponse:     true,
+		NotifyAfterSeconds: DefaultNotifyAfterSeconds,
+		Functions:          []share.Function{},
+		Prompts:            []share.Prompt{},
+		MCPServers:         []MCPServer{},
 	}
 }
 
@@ -143,6 +212,10 @@ func LoadFromFile(path string) (*Config, error) {
 
 // SaveToFile saves configuration to a JSON file
 func (c *Config) SaveToFile(path string) error {
+	if c.Kiosk {
+		return nil // The configuration of a kiosk stays as it was set up
+	}
+
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
@@ -239,6 +312,16 @@ func (c *Config) LoadFromSharedConfig(shared *share.SharedConfig) {
 	if len(shared.RAGDocuments) > 0 {
 		c.RAGDocuments = shared.RAGDocuments
 	}
+	if len(shared.MCPServers) > 0 {
+		c.MCPServers = shared.MCPServers
+	}
+	c.LockedByLink = shared.Locked
+	c.UnlockHash = shared.UnlockHash
+
+	// The link's own namespace, so its state is kept apart and matches the web app
+	if shared.Namespace != "" {
+		c.Namespace = shared.Namespace
+	}
 }
 
 // ToSharedConfig converts configuration to a shared config object
@@ -257,21 +340,50 @@ func (c *Config) ToSharedConfig() *share.SharedConfig {
 		Prompts:          c.Prompts,
 		RAGEnabled:       c.RAGEnabled,
 		RAGDocuments:     c.RAGDocuments,
+		Locked:           c.LockedByLink, // Sharing a locked configuration on keeps it locked
+		UnlockHash:       c.UnlockHash,
 	}
 }
 
+// SetFromSharedConfig sets every field ToSharedConfig reads to its value in
+// shared. Unlike LoadFromSharedConfig an empty value clears the field, so a
+// merged delta link that removes a setting removes it here too.
+func (c *Config) SetFromSharedConfig(shared *share.SharedConfig) {
+	if shared.BaseURL != c.BaseURL {
+		c.Provider = detectProvider(shared.BaseURL)
+	}
+	c.APIKey = shared.APIKey
+	c.BaseURL = shared.BaseURL
+	c.Model = shared.Model
+	c.MaxTokens = shared.MaxTokens
+	c.Temperature = shared.Temperature
+	c.SystemPrompt = shared.SystemPrompt
+	c.WelcomeMessage = shared.WelcomeMessage
+	c.Theme = shared.Theme
+	c.Functions = shared.Functions
+	c.DefaultFunctions = shared.DefaultFunctions
+	c.Prompts = shared.Prompts
+	c.RAGEnabled = shared.RAGEnabled
+	c.RAGDocuments = shared.RAGDocuments
+	c.LockedByLink = shared.Locked
+	c.UnlockHash = shared.UnlockHash
+}
+
+// ErrInvalidConfig is wrapped by all Validate errors
+var ErrInvalidConfig = errors.New("invalid configuration")
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	if c.BaseURL == "" {
-		return errors.New("base URL is required")
+		return fmt.Errorf("%w: base URL is required", ErrInvalidConfig)
 	}
 
 	if !strings.HasPrefix(c.BaseURL, "http://") && !strings.HasPrefix(c.BaseURL, "https://") {
-		return errors.New("base URL must start with http:// or https://")
+		return fmt.Errorf("%w: base URL must start with http:// or https://", ErrInvalidConfig)
 	}
 
 	if c.Model == "" {
-		return errors.New("model is required")
+		return fmt.Errorf("%w: model is required", ErrInvalidConfig)
 	}
 
 	if c.MaxTokens <= 0 {
@@ -279,12 +391,63 @@ func (c *Config) Validate() error {
 	}
 
 	if c.Temperature < 0 || c.Temperature > 2 {
-		return errors.New("temperature must be between 0 and 2")
+		return fmt.Errorf("%w: temperature must be between 0 and 2", ErrInvalidConfig)
 	}
 
 	return nil
 }
 
+// DefaultSeed is the seed MakeDeterministic uses when none is configured
+const DefaultSeed = 42
+
+// MakeDeterministic sets temperature 0 and a fixed seed, so that repeated
+// runs get the same reply wherever the provider and model allow it. Session
+// sampling overrides are dropped, as they would change the temperature.
+func (c *Config) MakeDeterministic() {
+	c.Temperature = 0
+	c.SessionSampling = sampling.Override{}
+	if c.Seed == 0 {
+		c.Seed = DefaultSeed
+	}
+}
+
+// NotifyThreshold returns how long a response must take before a notification is sent
+func (c *Config) NotifyThreshold() time.Duration {
+	if c.NotifyAfterSeconds <= 0 {
+		return DefaultNotifyAfterSeconds * time.Second
+	}
+	return time.Duration(c.NotifyAfterSeconds) * time.Second
+}
+
+// ArtifactPolicy returns the cleanup policy for session artifacts
+func (c *Config) ArtifactPolicy() artifacts.Policy {
+	return artifacts.Policy{MaxAgeDays: c.ArtifactsMaxAgeDays, MaxSizeMB: c.ArtifactsMaxSizeMB}
+}
+
+// DraftConfig returns the configuration for the local draft model, or nil if none is set
+func (c *Config) DraftConfig() *Config {
+	if c.DraftModel == "" {
+		return nil
+	}
+	draft := *c
+	draft.Model = c.DraftModel
+	draft.Provider = c.DraftProvider
+	draft.BaseURL = c.DraftBaseURL
+	if draft.Provider == "" {
+		draft.Provider = ProviderLlamafile
+		if draft.BaseURL != "" {
+			draft.Provider = detectProvider(draft.BaseURL)
+		}
+	}
+	if draft.BaseURL == "" {
+		draft.BaseURL = GetProviderBaseURL(draft.Provider)
+	}
+	if IsLocalProvider(draft.Provider) {
+		draft.APIKey = ""
+	}
+	return &draft
+}
+
 // GetConfigPath returns the default configuration file path
 func GetConfigPath() string {
 	homeDir, err := os.UserHomeDir()
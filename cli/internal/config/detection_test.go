@@ -116,4 +116,17 @@ func TestLoadFromSharedConfigWithAutoDetection(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestSetFromSharedConfigClears(t *testing.T) {
+	cfg := NewConfig()
+	cfg.APIKey = "sk-mine"
+	cfg.SystemPrompt = "Be helpful."
+	shared := cfg.ToSharedConfig()
+	shared.SystemPrompt = ""
+	shared.BaseURL = "https://api.groq.com/openai/v1"
+
+	cfg.SetFromSharedConfig(shared)
+	if cfg.SystemPrompt != "" || cfg.APIKey != "sk-mine" || cfg.Provider != detectProvider(shared.BaseURL) {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+}
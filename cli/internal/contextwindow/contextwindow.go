@@ -0,0 +1,120 @@
+// Package contextwindow trims a conversation to the model's context window
+// before it is sent. The oldest messages are left out first; system prompts,
+// pinned messages and the latest message are always kept.
+package contextwindow
+
+import (
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/models"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// DefaultReplyTokens is reserved for the reply when no max_tokens is configured
+const DefaultReplyTokens = 2048
+
+// messageOverhead approximates the tokens a message costs besides its content
+const messageOverhead = 4
+
+// Entry is what Fit needs to know about a message
+type Entry struct {
+	Role      string
+	Tokens    int
+	Pinned    bool
+	ToolCalls bool // An assistant message calling tools; the "tool" results after it go with it
+}
+
+// Budget returns the tokens the conversation may use with a model: most of
+// its context window, less room for the reply. It returns 0 (no trimming)
+// when the context window isn't known.
+func Budget(provider, model string, maxTokens int) int {
+	window := 0
+	if meta, ok := models.NewModelRegistry().GetModel(model); ok {
+		window = meta.ContextWindow
+	} else if info, ok := models.GetModelInfo(provider, model); ok {
+		window = info.ContextWindow
+	}
+	if window <= 0 {
+		return 0
+	}
+	if maxTokens <= 0 {
+		maxTokens = DefaultReplyTokens
+	}
+	// Token counts are estimates, so leave a margin
+	budget := window*9/10 - maxTokens
+	if budget < window/4 {
+		budget = window / 4
+	}
+	return budget
+}
+
+// Fit reports which entries to keep so their tokens fit budget. The oldest
+// entries that aren't system prompts or pinned are dropped first; the last
+// entry is always kept, and a tool call is kept or dropped together with its
+// results. A budget of 0 or less keeps everything.
+func Fit(entries []Entry, budget int) []bool {
+	keep := make([]bool, len(entries))
+	total := 0
+	for i, entry := range entries {
+		keep[i] = true
+		total += entry.Tokens
+	}
+	if budget <= 0 || total <= budget {
+		return keep
+	}
+
+	for start := 0; start < len(entries) && total > budget; {
+		end := start + 1
+		if entries[start].ToolCalls {
+			for end < len(entries) && entries[end].Role == "tool" {
+				end++
+			}
+		}
+		if droppable(entries[start:end]) && end < len(entries) {
+			for i := start; i < end; i++ {
+				keep[i] = false
+				total -= entries[i].Tokens
+			}
+		}
+		start = end
+	}
+	return keep
+}
+
+// droppable reports whether a unit of entries may be left out
+func droppable(unit []Entry) bool {
+	for _, entry := range unit {
+		if entry.Role == "system" || entry.Pinned {
+			return false
+		}
+	}
+	return true
+}
+
+// FitMessages returns the messages that fit budget and how many were left out
+func FitMessages(messages []api.Message, budget int) ([]api.Message, int) {
+	entries := make([]Entry, len(messages))
+	for i, msg := range messages {
+		entries[i] = Entry{
+			Role:      msg.Role,
+			Tokens:    MessageTokens(msg.Content),
+			Pinned:    msg.Pinned,
+			ToolCalls: len(msg.ToolCalls) > 0,
+		}
+		for _, call := range msg.ToolCalls {
+			entries[i].Tokens += usage.EstimateTokens(call.Function.Name + call.Function.Arguments)
+		}
+	}
+	keep := Fit(entries, budget)
+	fitted := make([]api.Message, 0, len(messages))
+	for i, msg := range messages {
+		if keep[i] {
+			fitted = append(fitted, msg)
+		}
+	}
+	return fitted, len(messages) - len(fitted)
+}
+
+// MessageTokens estimates the tokens of a message with the given content
+func MessageTokens(content string) int {
+	return usage.EstimateTokens(content) + messageOverhead
+}
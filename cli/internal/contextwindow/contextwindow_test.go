@@ -0,0 +1,67 @@
+package contextwindow
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+func TestFitDropsOldestUnpinned(t *testing.T) {
+	entries := []Entry{
+		{Role: "system", Tokens: 10},
+		{Role: "user", Tokens: 10, Pinned: true},
+		{Role: "assistant", Tokens: 10},
+		{Role: "user", Tokens: 10},
+		{Role: "assistant", Tokens: 10},
+		{Role: "user", Tokens: 10},
+	}
+	got := Fit(entries, 40)
+	want := []bool{true, true, false, false, true, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fit = %v, want %v", got, want)
+	}
+	if got := Fit(entries, 0); !reflect.DeepEqual(got, []bool{true, true, true, true, true, true}) {
+		t.Errorf("a zero budget dropped messages: %v", got)
+	}
+}
+
+func TestFitKeepsLatestAndToolResultsTogether(t *testing.T) {
+	entries := []Entry{
+		{Role: "user", Tokens: 10},
+		{Role: "assistant", Tokens: 10, ToolCalls: true},
+		{Role: "tool", Tokens: 10},
+		{Role: "tool", Tokens: 10},
+		{Role: "assistant", Tokens: 10},
+		{Role: "user", Tokens: 100},
+	}
+	got := Fit(entries, 50)
+	want := []bool{false, false, false, false, false, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fit = %v, want %v", got, want)
+	}
+	got = Fit(entries, 135)
+	want = []bool{false, false, false, false, true, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Fit = %v, want the tool call dropped with its results: %v", got, want)
+	}
+}
+
+func TestFitMessages(t *testing.T) {
+	long := string(make([]byte, 400))
+	messages := []api.Message{
+		{Role: "user", Content: "The report must be in French.", Pinned: true},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: "Now summarize it."},
+	}
+	fitted, dropped := FitMessages(messages, 50)
+	if dropped != 1 || len(fitted) != 2 || !fitted[0].Pinned || fitted[1].Content != "Now summarize it." {
+		t.Errorf("FitMessages = %+v, %d dropped", fitted, dropped)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	if got := Budget("nowhere", "unknown-model", 0); got != 0 {
+		t.Errorf("Budget of an unknown model = %d, want 0", got)
+	}
+}
@@ -0,0 +1,175 @@
+// Package crew runs several named agents on one task. Each agent has its own
+// model, system prompt and tools; they take turns in a fixed order, each
+// seeing the task and everything said so far (researcher → writer →
+// reviewer), and a reviewer can send the work back for another round.
+package crew
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/sampling"
+	"github.com/hacka-re/cli/internal/yaml"
+)
+
+// DefaultFile is the agents file read when none is given
+const DefaultFile = "agents.yaml"
+
+// Agent is one participant in a crew
+type Agent struct {
+	Name     string   `json:"name"`
+	Role     string   `json:"role,omitempty"`     // Short description shown to the other agents
+	Model    string   `json:"model,omitempty"`    // Default: the configured model
+	Provider string   `json:"provider,omitempty"` // Default: the configured provider
+	BaseURL  string   `json:"base_url,omitempty"` // Default: the provider's URL
+	System   string   `json:"system,omitempty"`   // System prompt
+	Tools    []string `json:"tools,omitempty"`    // Names of configured functions the agent may call
+
+	// temperature and top_p of the agent's requests; default: the configured ones
+	sampling.Override
+}
+
+// Budget limits a run; zero means unlimited
+type Budget struct {
+	MaxRounds int     `json:"max_rounds,omitempty"` // Passes through the flow (default 3)
+	MaxTokens int     `json:"max_tokens,omitempty"` // Prompt plus completion tokens
+	MaxCost   float64 `json:"max_cost,omitempty"`   // USD
+}
+
+// DefaultMaxRounds is used when the budget sets no round limit
+const DefaultMaxRounds = 3
+
+// Crew is the content of an agents file
+type Crew struct {
+	Agents []Agent  `json:"agents"`
+	Flow   []string `json:"flow,omitempty"`    // Turn order by name; default: the agents in order
+	LoopTo string   `json:"loop_to,omitempty"` // Agent that gets the work back when the last one asks for changes
+	Budget Budget   `json:"budget,omitempty"`
+}
+
+// Load reads an agents file in YAML (or JSON) and validates it
+func Load(path string) (*Crew, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agents file: %w", err)
+	}
+	crew, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return crew, nil
+}
+
+// Parse decodes and validates an agents definition
+func Parse(data []byte) (*Crew, error) {
+	var crew Crew
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		if err := json.Unmarshal(data, &crew); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else {
+		tree, err := yaml.Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		// Round-trip through JSON to reuse the struct tags
+		encoded, err := json.Marshal(tree)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(encoded, &crew); err != nil {
+			return nil, fmt.Errorf("invalid agents file: %w", err)
+		}
+	}
+	if err := crew.validate(); err != nil {
+		return nil, err
+	}
+	return &crew, nil
+}
+
+func (c *Crew) validate() error {
+	if len(c.Agents) == 0 {
+		return fmt.Errorf("no agents defined")
+	}
+	names := map[string]bool{}
+	for _, agent := range c.Agents {
+		if agent.Name == "" {
+			return fmt.Errorf("every agent needs a name")
+		}
+		if names[agent.Name] {
+			return fmt.Errorf("duplicate agent %q", agent.Name)
+		}
+		names[agent.Name] = true
+		if err := agent.Validate(); err != nil {
+			return fmt.Errorf("agent %s: %w", agent.Name, err)
+		}
+	}
+	for _, name := range c.Flow {
+		if !names[name] {
+			return fmt.Errorf("flow names unknown agent %q", name)
+		}
+	}
+	if c.LoopTo != "" {
+		flow := c.Order()
+		if !names[c.LoopTo] || flow[len(flow)-1] == c.LoopTo {
+			return fmt.Errorf("loop_to must name an agent before the last one in the flow")
+		}
+	}
+	return nil
+}
+
+// Order returns the agent names in turn order
+func (c *Crew) Order() []string {
+	if len(c.Flow) > 0 {
+		return c.Flow
+	}
+	names := make([]string, len(c.Agents))
+	for i, agent := range c.Agents {
+		names[i] = agent.Name
+	}
+	return names
+}
+
+// Agent returns the agent with the given name
+func (c *Crew) Agent(name string) (Agent, bool) {
+	for _, agent := range c.Agents {
+		if agent.Name == name {
+			return agent, true
+		}
+	}
+	return Agent{}, false
+}
+
+// Example is written by 'crew init'
+const Example = `# hacka.re crew: agents take turns in this order, each seeing the task
+# and everything said before. The last agent can start its reply with
+# APPROVED; otherwise the work goes back to the loop_to agent.
+agents:
+  - name: researcher
+    role: Collects facts and sources
+    model: gpt-4o-mini
+    system: |
+      You are a meticulous researcher. List the relevant facts for the task,
+      with sources where you have them. Do not write the final text.
+  - name: writer
+    role: Writes the deliverable
+    temperature: 0.2    # Replaces the configured one; top_p can be set too
+    system: |
+      You are a clear technical writer. Turn the research into the requested
+      deliverable. When the reviewer asks for changes, rewrite it in full.
+  - name: reviewer
+    role: Checks the draft
+    system: |
+      You are a strict reviewer. Check the writer's latest version against
+      the task and the research.
+
+loop_to: writer
+
+budget:
+  max_rounds: 3
+  max_cost: 0.50      # USD
+  max_tokens: 100000
+`
@@ -0,0 +1,115 @@
+package crew
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+func TestParseExample(t *testing.T) {
+	crew, err := Parse([]byte(Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := crew.Order(); !reflect.DeepEqual(got, []string{"researcher", "writer", "reviewer"}) {
+		t.Errorf("Order() = %v", got)
+	}
+	researcher, _ := crew.Agent("researcher")
+	if researcher.Model != "gpt-4o-mini" || !strings.HasSuffix(researcher.System, "Do not write the final text.\n") {
+		t.Errorf("researcher = %+v", researcher)
+	}
+	writer, _ := crew.Agent("writer")
+	if writer.Temperature == nil || *writer.Temperature != 0.2 || writer.TopP != nil {
+		t.Errorf("writer sampling = %s, want temperature 0.2", writer.Override)
+	}
+	if crew.LoopTo != "writer" || crew.Budget.MaxRounds != 3 || crew.Budget.MaxCost != 0.5 || crew.Budget.MaxTokens != 100000 {
+		t.Errorf("crew = %+v", crew)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, bad := range []string{
+		"agents: []",
+		"agents:\n  - name: a\n  - name: a",
+		"agents:\n  - name: a\nflow: [a, b]",
+		"agents:\n  - name: a\n  - name: b\nloop_to: b",
+		"agents:\n  - name: a\n    temperature: 3",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+// scripted replies in order and records the briefings it was sent
+type scripted struct {
+	mu       sync.Mutex
+	replies  []string
+	received []string
+}
+
+func (s *scripted) SendChatCompletionContext(ctx context.Context, messages []api.Message, cb api.StreamCallback) (*api.ChatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.received = append(s.received, messages[len(messages)-1].Content)
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	response := &api.ChatResponse{Choices: []api.Choice{{Message: api.Message{Content: reply}}}}
+	response.Usage.PromptTokens, response.Usage.CompletionTokens, response.Usage.TotalTokens = 100, 10, 110
+	return response, nil
+}
+
+func TestRunLoopsUntilApproved(t *testing.T) {
+	crew, err := Parse([]byte(Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crew.Budget = Budget{}
+	researcher := &scripted{replies: []string{"Fact: port 22 is SSH."}}
+	writer := &scripted{replies: []string{"Draft v1", "Draft v2"}}
+	reviewer := &scripted{replies: []string{"Mention the source.", "**APPROVED** looks good"}}
+
+	var live []string
+	runner := &Runner{
+		Crew:    crew,
+		Clients: map[string]Completer{"researcher": researcher, "writer": writer, "reviewer": reviewer},
+		OnTurn:  func(turn Turn) { live = append(live, turn.Agent) },
+	}
+	transcript, err := runner.Run(context.Background(), "Explain SSH")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"researcher", "writer", "reviewer", "writer", "reviewer"}; !reflect.DeepEqual(live, want) {
+		t.Errorf("turns = %v, want %v", live, want)
+	}
+	if transcript.Stopped != StopApproved || transcript.Result(crew) != "Draft v2" || transcript.Tokens != 550 {
+		t.Errorf("transcript = %+v", transcript)
+	}
+	if !strings.Contains(writer.received[1], "Mention the source.") || !strings.Contains(reviewer.received[0], "start your reply with APPROVED") {
+		t.Errorf("briefings missed the conversation: %q", writer.received[1])
+	}
+
+	var md strings.Builder
+	if err := transcript.WriteMarkdown(&md, crew); err != nil || !strings.Contains(md.String(), "## reviewer — round 2") {
+		t.Errorf("WriteMarkdown() = %q, %v", md.String(), err)
+	}
+}
+
+func TestRunStopsAtBudget(t *testing.T) {
+	crew := &Crew{Agents: []Agent{{Name: "a"}, {Name: "b"}}, Budget: Budget{MaxTokens: 100}}
+	a := &scripted{replies: []string{"first"}}
+	runner := &Runner{Crew: crew, Clients: map[string]Completer{"a": a, "b": &scripted{}}}
+
+	transcript, err := runner.Run(context.Background(), "task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transcript.Stopped != StopBudget || len(transcript.Turns) != 1 {
+		t.Errorf("transcript = %+v, want a stop after the first turn", transcript)
+	}
+}
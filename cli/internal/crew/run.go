@@ -0,0 +1,322 @@
+package crew
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/toolpolicy"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// Approved starts the last agent's reply when the work needs no more changes
+const Approved = "APPROVED"
+
+// maxToolRounds limits tool calls within one turn
+const maxToolRounds = 5
+
+// Completer sends chat completions; *api.Client implements it
+type Completer interface {
+	SendChatCompletionContext(ctx context.Context, messages []api.Message, streamCallback api.StreamCallback) (*api.ChatResponse, error)
+}
+
+// ToolExecutor runs tools the model calls; *jsruntime.Registry implements it
+type ToolExecutor interface {
+	Execute(name string, args map[string]interface{}) (interface{}, error)
+}
+
+// Turn is one agent's contribution
+type Turn struct {
+	Round            int      `json:"round"`
+	Agent            string   `json:"agent"`
+	Model            string   `json:"model"`
+	Content          string   `json:"content"`
+	ToolCalls        []string `json:"toolCalls,omitempty"`
+	PromptTokens     int      `json:"promptTokens"`
+	CompletionTokens int      `json:"completionTokens"`
+	Cost             float64  `json:"cost"` // USD
+}
+
+// Outcomes of a run
+const (
+	StopApproved  = "approved"   // The last agent approved the work
+	StopCompleted = "completed"  // The flow ran once and there is no review loop
+	StopRounds    = "max rounds" // The reviewer still wanted changes after the last round
+	StopBudget    = "budget"     // A token or cost limit was reached
+)
+
+// Transcript is the record of a run
+type Transcript struct {
+	Task    string    `json:"task"`
+	Turns   []Turn    `json:"turns"`
+	Stopped string    `json:"stopped"`
+	Reason  string    `json:"reason,omitempty"` // Detail for StopBudget
+	Tokens  int       `json:"tokens"`
+	Cost    float64   `json:"cost"` // USD
+	Started time.Time `json:"started"`
+}
+
+// Runner runs a crew on a task
+type Runner struct {
+	Crew    *Crew
+	Clients map[string]Completer // By agent name
+	Models  map[string]string    // Model of each agent, for pricing and the transcript
+	Tools   ToolExecutor         // May be nil when no functions are configured
+
+	// Approve is asked before each tool call; nil runs tools without asking
+	Approve func(agent, tool, arguments string) bool
+
+	// ToolLimiter queues tool calls over their policy limits and bounds their
+	// time and output; nil applies only the default output cap
+	ToolLimiter *toolpolicy.Limiter
+
+	// OnQueued is told why a tool call has to wait for the ToolLimiter
+	OnQueued func(agent, tool, reason string)
+
+	// OnTurn is called as each turn finishes, for a live transcript
+	OnTurn func(Turn)
+}
+
+// Run lets the agents take turns until the work is approved or a limit is reached
+func (r *Runner) Run(ctx context.Context, task string) (*Transcript, error) {
+	transcript := &Transcript{Task: task, Started: time.Now()}
+	tracker := usage.NewTracker("")
+	flow := r.Crew.Order()
+	maxRounds := r.Crew.Budget.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = DefaultMaxRounds
+	}
+
+	start := 0
+	for round := 1; ; round++ {
+		for i := start; i < len(flow); i++ {
+			agent, _ := r.Crew.Agent(flow[i])
+			last := i == len(flow)-1 && r.Crew.LoopTo != ""
+
+			turn, err := r.turn(ctx, agent, round, last, transcript)
+			if err != nil {
+				return transcript, fmt.Errorf("%s: %w", agent.Name, err)
+			}
+			turn.Cost = tracker.Cost(turn.Model, turn.PromptTokens, turn.CompletionTokens)
+			transcript.Turns = append(transcript.Turns, turn)
+			transcript.Tokens += turn.PromptTokens + turn.CompletionTokens
+			transcript.Cost += turn.Cost
+			if r.OnTurn != nil {
+				r.OnTurn(turn)
+			}
+
+			if last && isApproved(turn.Content) {
+				transcript.Stopped = StopApproved
+				return transcript, nil
+			}
+			if reason := r.overBudget(transcript); reason != "" {
+				transcript.Stopped, transcript.Reason = StopBudget, reason
+				return transcript, nil
+			}
+		}
+
+		if r.Crew.LoopTo == "" {
+			transcript.Stopped = StopCompleted
+			return transcript, nil
+		}
+		if round >= maxRounds {
+			transcript.Stopped = StopRounds
+			return transcript, nil
+		}
+		for i, name := range flow {
+			if name == r.Crew.LoopTo {
+				start = i
+			}
+		}
+	}
+}
+
+// overBudget returns why the run must stop, or ""
+func (r *Runner) overBudget(t *Transcript) string {
+	budget := r.Crew.Budget
+	if budget.MaxTokens > 0 && t.Tokens >= budget.MaxTokens {
+		return fmt.Sprintf("%d of %d tokens used", t.Tokens, budget.MaxTokens)
+	}
+	if budget.MaxCost > 0 && t.Cost >= budget.MaxCost {
+		return fmt.Sprintf("$%.4f of $%.2f spent", t.Cost, budget.MaxCost)
+	}
+	return ""
+}
+
+// turn asks one agent for its contribution, running its tool calls
+func (r *Runner) turn(ctx context.Context, agent Agent, round int, reviewer bool, t *Transcript) (Turn, error) {
+	turn := Turn{Round: round, Agent: agent.Name, Model: r.Models[agent.Name]}
+	client := r.Clients[agent.Name]
+	if client == nil {
+		return turn, fmt.Errorf("no client for agent")
+	}
+
+	messages := []api.Message{
+		{Role: "system", Content: r.systemPrompt(agent)},
+		{Role: "user", Content: r.briefing(agent, reviewer, t)},
+	}
+	for step := 0; step < maxToolRounds; step++ {
+		response, err := client.SendChatCompletionContext(ctx, messages, nil)
+		if err != nil {
+			return turn, err
+		}
+		if len(response.Choices) == 0 {
+			return turn, fmt.Errorf("the model returned no answer")
+		}
+		turn.PromptTokens += response.Usage.PromptTokens
+		turn.CompletionTokens += response.Usage.CompletionTokens
+		if response.Usage.TotalTokens == 0 {
+			turn.PromptTokens += estimateTokens(messages)
+			turn.CompletionTokens += usage.EstimateTokens(response.Choices[0].Message.Content)
+		}
+
+		message := response.Choices[0].Message
+		message.Role = "assistant"
+		if len(message.ToolCalls) == 0 {
+			turn.Content = strings.TrimSpace(message.Content)
+			return turn, nil
+		}
+
+		messages = append(messages, message)
+		for _, call := range message.ToolCalls {
+			turn.ToolCalls = append(turn.ToolCalls, call.Function.Name)
+			messages = append(messages, api.Message{
+				Role:       "tool",
+				ToolCallID: call.ID,
+				Content:    r.runTool(ctx, agent, call),
+			})
+		}
+	}
+	return turn, fmt.Errorf("stopped after %d rounds of tool calls", maxToolRounds)
+}
+
+// systemPrompt is the agent's own prompt plus who else is in the crew
+func (r *Runner) systemPrompt(agent Agent) string {
+	var b strings.Builder
+	if agent.System != "" {
+		b.WriteString(strings.TrimSpace(agent.System) + "\n\n")
+	}
+	fmt.Fprintf(&b, "You are %q, one agent in a crew working on a task together. The agents, in turn order:\n", agent.Name)
+	for _, name := range r.Crew.Order() {
+		other, _ := r.Crew.Agent(name)
+		role := other.Role
+		if role == "" {
+			role = "no role given"
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", name, role)
+	}
+	return b.String()
+}
+
+// briefing is the task and everything said so far
+func (r *Runner) briefing(agent Agent, reviewer bool, t *Transcript) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Task\n\n%s\n", strings.TrimSpace(t.Task))
+	if len(t.Turns) > 0 {
+		b.WriteString("\n# Conversation so far\n")
+		for _, turn := range t.Turns {
+			fmt.Fprintf(&b, "\n## %s (round %d)\n\n%s\n", turn.Agent, turn.Round, turn.Content)
+		}
+	}
+	fmt.Fprintf(&b, "\n# Your turn, %s\n\n", agent.Name)
+	if reviewer {
+		fmt.Fprintf(&b, "If the work fully meets the task, start your reply with %s. Otherwise list the changes %s must make.", Approved, r.Crew.LoopTo)
+	} else {
+		b.WriteString("Write your contribution. Address the other agents' latest points where relevant.")
+	}
+	return b.String()
+}
+
+// runTool runs a tool call if the agent may use the tool and the user approves
+func (r *Runner) runTool(ctx context.Context, agent Agent, call api.ToolCall) string {
+	name := call.Function.Name
+	allowed := false
+	for _, tool := range agent.Tools {
+		allowed = allowed || tool == name
+	}
+	if !allowed || r.Tools == nil {
+		return fmt.Sprintf("Error: tool %s is not available to %s", name, agent.Name)
+	}
+
+	var args map[string]interface{}
+	if call.Function.Arguments != "" {
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+			return fmt.Sprintf("Error: invalid arguments for %s: %v", name, err)
+		}
+	}
+	if r.Approve != nil && !r.Approve(agent.Name, name, call.Function.Arguments) {
+		return fmt.Sprintf("The user declined to run %s.", name)
+	}
+
+	release, err := r.ToolLimiter.Acquire(ctx, name, func(reason string) {
+		if r.OnQueued != nil {
+			r.OnQueued(agent.Name, name, reason)
+		}
+	})
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	limit := r.ToolLimiter.Limit(name)
+	result, err := toolpolicy.Run(ctx, limit.Timeout(), func() (interface{}, error) {
+		defer release()
+		return r.Tools.Execute(name, args)
+	})
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return toolpolicy.Truncate(fmt.Sprint(result), limit.MaxOutput())
+	}
+	return toolpolicy.Truncate(string(encoded), limit.MaxOutput())
+}
+
+func isApproved(content string) bool {
+	return strings.HasPrefix(strings.TrimLeft(content, " *#`\n"), Approved)
+}
+
+func estimateTokens(messages []api.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += usage.EstimateTokens(msg.Content)
+	}
+	return total
+}
+
+// Result is the deliverable: with a review loop, the latest turn of the agent
+// the work loops back to; otherwise the last turn
+func (t *Transcript) Result(c *Crew) string {
+	for i := len(t.Turns) - 1; i >= 0; i-- {
+		if c.LoopTo == "" || t.Turns[i].Agent == c.LoopTo {
+			return t.Turns[i].Content
+		}
+	}
+	return ""
+}
+
+// WriteMarkdown writes the transcript with one section per turn
+func (t *Transcript) WriteMarkdown(w io.Writer, c *Crew) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Crew transcript\n\n_%s · %d turns · %d tokens · $%.4f · %s_\n\n## Task\n\n%s\n",
+		t.Started.Format("2006-01-02 15:04"), len(t.Turns), t.Tokens, t.Cost, t.Stopped, strings.TrimSpace(t.Task))
+	for _, turn := range t.Turns {
+		fmt.Fprintf(&b, "\n## %s — round %d\n\n", turn.Agent, turn.Round)
+		if turn.Model != "" {
+			fmt.Fprintf(&b, "_%s", turn.Model)
+			if len(turn.ToolCalls) > 0 {
+				fmt.Fprintf(&b, " · tools: %s", strings.Join(turn.ToolCalls, ", "))
+			}
+			b.WriteString("_\n\n")
+		}
+		b.WriteString(turn.Content + "\n")
+	}
+	if result := t.Result(c); result != "" {
+		fmt.Fprintf(&b, "\n## Result\n\n%s\n", result)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
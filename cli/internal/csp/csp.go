@@ -0,0 +1,208 @@
+// Package csp adds Content-Security-Policy and other security headers to web
+// app responses, and logs the violations browsers report to ReportPath.
+//
+// The defaults are as strict as the web app allows: it runs inline scripts
+// and user-defined functions in the page, and talks to whatever LLM endpoint
+// the user configures, so scripts and connections can't be locked to 'self'.
+// Everything else is, and the app can't be framed. Options relaxes this for
+// embedding, e.g. in an intranet portal.
+package csp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/metrics"
+)
+
+// ReportPath is where browsers send violation reports
+const ReportPath = "/csp-report"
+
+// maxReportSize caps a report body; browsers send well under this
+const maxReportSize = 64 << 10
+
+// DefaultPolicy is the Content-Security-Policy sent unless Options.Policy
+// replaces it. frame-ancestors and report-uri are added separately.
+const DefaultPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' 'unsafe-eval'; " +
+	"style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data: blob:; " +
+	"font-src 'self' data:; " +
+	"connect-src 'self' https: http: ws: wss:; " +
+	"worker-src 'self' blob:; " +
+	"object-src 'none'; " +
+	"base-uri 'self'; " +
+	"form-action 'self'"
+
+// Violations counts reported violations by directive
+var Violations = metrics.NewCounterVec("hackare_csp_violations_total",
+	"Content-Security-Policy violations reported by browsers", "directive")
+
+// reportLog is where violations are printed
+var reportLog io.Writer = os.Stderr
+
+// Options relaxes the default headers. The zero value is the strictest setting.
+type Options struct {
+	Disabled       bool     `json:"disabled,omitempty"`       // Send no security headers at all
+	Policy         string   `json:"policy,omitempty"`         // Replaces DefaultPolicy
+	FrameAncestors []string `json:"frameAncestors,omitempty"` // Origins allowed to embed the app, e.g. "https://intranet.example.com"
+	ReferrerPolicy string   `json:"referrerPolicy,omitempty"` // Default: no-referrer
+	ReportOnly     bool     `json:"reportOnly,omitempty"`     // Only report violations, don't block
+}
+
+// Headers returns the security headers for opts
+func Headers(opts Options) http.Header {
+	headers := http.Header{}
+	if opts.Disabled {
+		return headers
+	}
+
+	policy := opts.Policy
+	if policy == "" {
+		policy = DefaultPolicy
+	}
+	policy = strings.TrimRight(strings.TrimSpace(policy), ";")
+
+	ancestors := "'none'"
+	if len(opts.FrameAncestors) > 0 {
+		ancestors = strings.Join(opts.FrameAncestors, " ")
+	}
+	customFraming := strings.Contains(policy, "frame-ancestors")
+	if !customFraming {
+		policy += "; frame-ancestors " + ancestors
+	}
+	policy += "; report-uri " + ReportPath
+
+	if opts.ReportOnly {
+		headers.Set("Content-Security-Policy-Report-Only", policy)
+	} else {
+		headers.Set("Content-Security-Policy", policy)
+	}
+
+	// X-Frame-Options can't list origins, so it is only sent when CSP
+	// frame-ancestors has an equivalent; browsers that know CSP ignore it
+	switch {
+	case customFraming:
+	case ancestors == "'none'":
+		headers.Set("X-Frame-Options", "DENY")
+	case ancestors == "'self'":
+		headers.Set("X-Frame-Options", "SAMEORIGIN")
+	}
+
+	referrer := opts.ReferrerPolicy
+	if referrer == "" {
+		referrer = "no-referrer"
+	}
+	headers.Set("Referrer-Policy", referrer)
+	headers.Set("X-Content-Type-Options", "nosniff")
+	headers.Set("Cross-Origin-Opener-Policy", "same-origin")
+	headers.Set("Permissions-Policy", "camera=(), geolocation=(), payment=(), usb=()")
+	return headers
+}
+
+// Wrap sets the security headers on every response from next and handles
+// violation reports at ReportPath
+func Wrap(next http.Handler, opts Options) http.Handler {
+	headers := Headers(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ReportPath {
+			handleReport(w, r)
+			return
+		}
+		for name, values := range headers {
+			w.Header()[name] = values
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// violation holds the fields logged from a report
+type violation struct {
+	DocumentURI        string `json:"document-uri"`
+	BlockedURI         string `json:"blocked-uri"`
+	ViolatedDirective  string `json:"violated-directive"`
+	EffectiveDirective string `json:"effective-directive"`
+	SourceFile         string `json:"source-file"`
+	LineNumber         int    `json:"line-number"`
+}
+
+// handleReport logs a report in either the report-uri format
+// ({"csp-report": {...}}) or the Reporting API format ([{"body": {...}}])
+func handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxReportSize))
+	if err != nil {
+		http.Error(w, "bad report", http.StatusBadRequest)
+		return
+	}
+
+	violations, err := parseReport(body)
+	if err != nil {
+		http.Error(w, "bad report", http.StatusBadRequest)
+		return
+	}
+	for _, v := range violations {
+		directive := v.EffectiveDirective
+		if directive == "" {
+			directive, _, _ = strings.Cut(v.ViolatedDirective, " ")
+		}
+		Violations.Inc(directive)
+
+		source := v.SourceFile
+		if source != "" && v.LineNumber > 0 {
+			source = fmt.Sprintf("%s:%d", source, v.LineNumber)
+		}
+		fmt.Fprintf(reportLog, "[%s] CSP violation: %s blocked %q on %s %s\n",
+			time.Now().Format("15:04:05"), directive, v.BlockedURI, v.DocumentURI, source)
+		logger.Get().Warn("[CSP] %s blocked %q on %s %s", directive, v.BlockedURI, v.DocumentURI, source)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseReport decodes both report formats
+func parseReport(body []byte) ([]violation, error) {
+	var legacy struct {
+		Report *violation `json:"csp-report"`
+	}
+	if err := json.Unmarshal(body, &legacy); err == nil && legacy.Report != nil {
+		return []violation{*legacy.Report}, nil
+	}
+
+	var reports []struct {
+		Type string `json:"type"`
+		Body struct {
+			DocumentURL        string `json:"documentURL"`
+			BlockedURL         string `json:"blockedURL"`
+			EffectiveDirective string `json:"effectiveDirective"`
+			SourceFile         string `json:"sourceFile"`
+			LineNumber         int    `json:"lineNumber"`
+		} `json:"body"`
+	}
+	if err := json.Unmarshal(body, &reports); err != nil {
+		return nil, err
+	}
+	var violations []violation
+	for _, report := range reports {
+		if report.Type != "" && report.Type != "csp-violation" {
+			continue
+		}
+		violations = append(violations, violation{
+			DocumentURI:        report.Body.DocumentURL,
+			BlockedURI:         report.Body.BlockedURL,
+			EffectiveDirective: report.Body.EffectiveDirective,
+			SourceFile:         report.Body.SourceFile,
+			LineNumber:         report.Body.LineNumber,
+		})
+	}
+	return violations, nil
+}
@@ -0,0 +1,105 @@
+package csp
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDefaultHeadersAreStrict(t *testing.T) {
+	headers := Headers(Options{})
+	policy := headers.Get("Content-Security-Policy")
+	for _, want := range []string{"default-src 'self'", "object-src 'none'", "frame-ancestors 'none'", "report-uri " + ReportPath} {
+		if !strings.Contains(policy, want) {
+			t.Errorf("policy %q is missing %q", policy, want)
+		}
+	}
+	if headers.Get("X-Frame-Options") != "DENY" {
+		t.Errorf("X-Frame-Options = %q, want DENY", headers.Get("X-Frame-Options"))
+	}
+	if headers.Get("Referrer-Policy") != "no-referrer" {
+		t.Errorf("Referrer-Policy = %q", headers.Get("Referrer-Policy"))
+	}
+	if headers.Get("X-Content-Type-Options") != "nosniff" {
+		t.Error("missing X-Content-Type-Options")
+	}
+}
+
+func TestRelaxedHeaders(t *testing.T) {
+	headers := Headers(Options{
+		FrameAncestors: []string{"'self'", "https://intranet.example.com"},
+		ReferrerPolicy: "strict-origin",
+		ReportOnly:     true,
+	})
+	if headers.Get("Content-Security-Policy") != "" {
+		t.Error("report-only mode should not send an enforcing policy")
+	}
+	policy := headers.Get("Content-Security-Policy-Report-Only")
+	if !strings.Contains(policy, "frame-ancestors 'self' https://intranet.example.com") {
+		t.Errorf("policy = %q", policy)
+	}
+	if headers.Get("X-Frame-Options") != "" {
+		t.Error("X-Frame-Options would block the allowed origin")
+	}
+	if headers.Get("Referrer-Policy") != "strict-origin" {
+		t.Errorf("Referrer-Policy = %q", headers.Get("Referrer-Policy"))
+	}
+
+	if len(Headers(Options{Disabled: true})) != 0 {
+		t.Error("disabled options should send no headers")
+	}
+
+	custom := Headers(Options{Policy: "default-src 'self'; frame-ancestors 'self';"})
+	if got := custom.Get("Content-Security-Policy"); got != "default-src 'self'; frame-ancestors 'self'; report-uri "+ReportPath {
+		t.Errorf("custom policy = %q", got)
+	}
+	if custom.Get("X-Frame-Options") != "" {
+		t.Error("X-Frame-Options should follow Options.FrameAncestors, not a custom policy")
+	}
+}
+
+func TestWrapSetsHeadersAndLogsReports(t *testing.T) {
+	var logged bytes.Buffer
+	reportLog = &logged
+
+	handler := Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}), Options{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/index.html", nil))
+	if rec.Header().Get("Content-Security-Policy") == "" {
+		t.Error("page response has no Content-Security-Policy")
+	}
+
+	before := Violations.Value("script-src-elem")
+	legacy := `{"csp-report":{"document-uri":"http://localhost:8080/","blocked-uri":"https://evil.example/x.js","violated-directive":"script-src-elem","source-file":"http://localhost:8080/app.js","line-number":12}}`
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ReportPath, strings.NewReader(legacy)))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("report status = %d", rec.Code)
+	}
+
+	reportingAPI := `[{"type":"csp-violation","body":{"documentURL":"http://localhost:8080/","blockedURL":"inline","effectiveDirective":"script-src-elem"}}]`
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ReportPath, strings.NewReader(reportingAPI)))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Reporting API status = %d", rec.Code)
+	}
+
+	if got := Violations.Value("script-src-elem") - before; got != 2 {
+		t.Errorf("counted %v violations, want 2", got)
+	}
+	if !strings.Contains(logged.String(), `blocked "https://evil.example/x.js"`) ||
+		!strings.Contains(logged.String(), "app.js:12") {
+		t.Errorf("log = %q", logged.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, ReportPath, strings.NewReader("not json")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad report status = %d", rec.Code)
+	}
+}
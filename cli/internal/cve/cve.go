@@ -0,0 +1,392 @@
+// Package cve looks up vulnerabilities in the NVD (National Vulnerability
+// Database) API and keeps every answer in a local cache. The cache is read
+// first, so repeated lookups make no requests and the NVD rate limit is rarely
+// reached. In offline mode only the cache is used.
+package cve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// DefaultBaseURL is the NVD CVE API
+const DefaultBaseURL = "https://services.nvd.nist.gov/rest/json/cves/2.0"
+
+// DefaultMaxAge is how long a cached record is used before it is fetched
+// again. Scores of new CVEs are often added days after publication.
+const DefaultMaxAge = 7 * 24 * time.Hour
+
+// notFoundMaxAge is how long an unknown ID is remembered, shorter so a CVE
+// that has just been published is found the next day
+const notFoundMaxAge = 24 * time.Hour
+
+// requestTimeout stays below the 5 second limit of the JavaScript functions,
+// so a slow NVD is reported instead of the function timing out
+const requestTimeout = 4 * time.Second
+
+// ErrOffline is returned for a CVE that isn't cached when offline
+var ErrOffline = errors.New("not in the local cache, and offline mode makes no NVD requests")
+
+// ErrNotFound is returned for an ID the NVD doesn't know
+var ErrNotFound = errors.New("not found in the NVD")
+
+// CVE is a vulnerability as the NVD describes it
+type CVE struct {
+	ID             string      `json:"id"`
+	Published      time.Time   `json:"published,omitempty"`
+	LastModified   time.Time   `json:"lastModified,omitempty"`
+	Status         string      `json:"status,omitempty"` // e.g. Analyzed, Awaiting Analysis
+	Description    string      `json:"description,omitempty"`
+	Scores         []Score     `json:"scores,omitempty"` // Newest CVSS version and primary source first
+	Weaknesses     []string    `json:"weaknesses,omitempty"`
+	References     []Reference `json:"references,omitempty"`
+	KnownExploited bool        `json:"knownExploited,omitempty"` // In the CISA KEV catalog
+	FetchedAt      time.Time   `json:"fetchedAt"`
+	Cached         bool        `json:"cached"` // Answered from the cache, set on lookup
+}
+
+// Score is a CVSS score
+type Score struct {
+	Version  string  `json:"version"`
+	Score    float64 `json:"score"`
+	Severity string  `json:"severity,omitempty"`
+	Vector   string  `json:"vector,omitempty"`
+	Source   string  `json:"source,omitempty"`
+	Primary  bool    `json:"primary,omitempty"` // Scored by the NVD rather than the CNA
+}
+
+// Reference is a link about a CVE
+type Reference struct {
+	URL  string   `json:"url"`
+	Tags []string `json:"tags,omitempty"` // e.g. Patch, Exploit, Vendor Advisory
+}
+
+// entry is a cached answer; an unknown ID is cached with no CVE
+type entry struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	CVE       *CVE      `json:"cve,omitempty"`
+}
+
+// Client looks up CVEs, through the cache in CacheDir
+type Client struct {
+	BaseURL    string
+	APIKey     string // Raises the NVD rate limit from 5 to 50 requests per 30 seconds
+	CacheDir   string
+	MaxAge     time.Duration
+	Offline    bool
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client of the NVD API that caches in CacheDir()
+func NewClient(apiKey string, offline bool) *Client {
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		APIKey:     apiKey,
+		CacheDir:   CacheDir(),
+		MaxAge:     DefaultMaxAge,
+		Offline:    offline,
+		HTTPClient: netguard.Client(requestTimeout),
+	}
+}
+
+var (
+	current   *Client
+	currentMu sync.Mutex
+)
+
+// Init sets up the client used by the cve_lookup function, with the API key
+// from HACKARE_NVD_API_KEY or NVD_API_KEY
+func Init(offline bool) {
+	apiKey := os.Getenv("HACKARE_NVD_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("NVD_API_KEY")
+	}
+	SetDefault(NewClient(apiKey, offline))
+}
+
+// SetDefault replaces the client returned by Default
+func SetDefault(c *Client) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = c
+}
+
+// Default returns the client set up by Init, or an online client without an
+// API key if Init hasn't been called
+func Default() *Client {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current == nil {
+		current = NewClient("", false)
+	}
+	return current
+}
+
+// CacheDir returns the directory of cached CVEs. HACKARE_CVE_CACHE_DIR
+// overrides it.
+func CacheDir() string {
+	if dir := os.Getenv("HACKARE_CVE_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hacka.re-cve")
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "cve")
+}
+
+var validID = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// NormalizeID returns id in upper case, or an error if it isn't a CVE ID
+func NormalizeID(id string) (string, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if !validID.MatchString(id) {
+		return "", fmt.Errorf("invalid CVE ID %q, expected the form CVE-2021-44228", id)
+	}
+	return id, nil
+}
+
+// Lookup returns the CVE with the ID. A cached answer younger than MaxAge is
+// used without asking the NVD; an older one is used if the NVD can't be
+// reached, and any cached answer is used when offline.
+func (c *Client) Lookup(ctx context.Context, id string) (*CVE, error) {
+	id, err := NormalizeID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, cacheErr := c.readCache(id)
+	if cached != nil && (c.Offline || cached.fresh(c.MaxAge)) {
+		return cached.result(id)
+	}
+	if c.Offline {
+		if cacheErr != nil {
+			return nil, cacheErr
+		}
+		return nil, fmt.Errorf("%s: %w", id, ErrOffline)
+	}
+
+	fetched, err := c.fetch(ctx, id)
+	if err != nil {
+		if cached != nil {
+			return cached.result(id) // Stale, but better than nothing
+		}
+		return nil, err
+	}
+	c.writeCache(id, fetched) // The answer is still good if it can't be cached
+	if fetched.CVE == nil {
+		return nil, fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	return fetched.CVE, nil
+}
+
+func (e *entry) fresh(maxAge time.Duration) bool {
+	if e.CVE == nil && maxAge > notFoundMaxAge {
+		maxAge = notFoundMaxAge
+	}
+	return time.Since(e.FetchedAt) < maxAge
+}
+
+// result returns the cached CVE, or ErrNotFound for a cached unknown ID
+func (e *entry) result(id string) (*CVE, error) {
+	if e.CVE == nil {
+		return nil, fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	e.CVE.Cached = true
+	return e.CVE, nil
+}
+
+func (c *Client) cachePath(id string) string {
+	return filepath.Join(c.CacheDir, id+".json")
+}
+
+// readCache returns the cached answer for id, or nil if there is none
+func (c *Client) readCache(id string) (*entry, error) {
+	data, err := os.ReadFile(c.cachePath(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CVE cache: %w", err)
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, nil // A damaged entry is fetched again
+	}
+	return &e, nil
+}
+
+func (c *Client) writeCache(id string, e *entry) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode CVE: %w", err)
+	}
+	if err := os.MkdirAll(c.CacheDir, 0700); err != nil {
+		return fmt.Errorf("failed to create CVE cache: %w", err)
+	}
+	path := c.cachePath(id)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write CVE cache: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// fetch asks the NVD for id
+func (c *Client) fetch(ctx context.Context, id string) (*entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"?cveId="+url.QueryEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.APIKey != "" {
+		req.Header.Set("apiKey", c.APIKey)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("NVD request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return &entry{FetchedAt: time.Now()}, nil
+	case (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) && c.APIKey == "":
+		return nil, fmt.Errorf("NVD rate limit reached (%s); set HACKARE_NVD_API_KEY for a higher limit", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("NVD request failed: %s", resp.Status)
+	}
+
+	var body nvdResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse NVD response: %w", err)
+	}
+	e := &entry{FetchedAt: time.Now()}
+	for _, v := range body.Vulnerabilities {
+		if strings.EqualFold(v.CVE.ID, id) {
+			e.CVE = v.CVE.convert()
+			e.CVE.FetchedAt = e.FetchedAt
+		}
+	}
+	return e, nil
+}
+
+// nvdResponse is the part of an NVD API 2.0 response that is kept
+type nvdResponse struct {
+	Vulnerabilities []struct {
+		CVE nvdCVE `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+type nvdCVE struct {
+	ID           string `json:"id"`
+	Published    string `json:"published"`
+	LastModified string `json:"lastModified"`
+	VulnStatus   string `json:"vulnStatus"`
+	Descriptions []struct {
+		Lang  string `json:"lang"`
+		Value string `json:"value"`
+	} `json:"descriptions"`
+	Metrics    map[string][]nvdMetric `json:"metrics"`
+	Weaknesses []struct {
+		Description []struct {
+			Value string `json:"value"`
+		} `json:"description"`
+	} `json:"weaknesses"`
+	References []struct {
+		URL  string   `json:"url"`
+		Tags []string `json:"tags"`
+	} `json:"references"`
+	CISAExploitAdd string `json:"cisaExploitAdd"`
+}
+
+type nvdMetric struct {
+	Source       string `json:"source"`
+	Type         string `json:"type"`
+	BaseSeverity string `json:"baseSeverity"` // Outside cvssData in CVSS v2 metrics
+	CVSSData     struct {
+		Version      string  `json:"version"`
+		VectorString string  `json:"vectorString"`
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+}
+
+// nvdTime reads the NVD's timestamps, which have no time zone and are UTC
+func nvdTime(s string) time.Time {
+	t, _ := time.Parse("2006-01-02T15:04:05.000", s)
+	return t
+}
+
+func (n nvdCVE) convert() *CVE {
+	c := &CVE{
+		ID:             n.ID,
+		Published:      nvdTime(n.Published),
+		LastModified:   nvdTime(n.LastModified),
+		Status:         n.VulnStatus,
+		KnownExploited: n.CISAExploitAdd != "",
+	}
+	for _, d := range n.Descriptions {
+		if d.Lang == "en" {
+			c.Description = d.Value
+		}
+	}
+	for _, metrics := range n.Metrics {
+		for _, m := range metrics {
+			severity := m.CVSSData.BaseSeverity
+			if severity == "" {
+				severity = m.BaseSeverity
+			}
+			c.Scores = append(c.Scores, Score{
+				Version:  m.CVSSData.Version,
+				Score:    m.CVSSData.BaseScore,
+				Severity: severity,
+				Vector:   m.CVSSData.VectorString,
+				Source:   m.Source,
+				Primary:  m.Type == "Primary",
+			})
+		}
+	}
+	sort.Slice(c.Scores, func(i, j int) bool {
+		a, b := c.Scores[i], c.Scores[j]
+		if a.Version != b.Version {
+			return a.Version > b.Version
+		}
+		if a.Primary != b.Primary {
+			return a.Primary
+		}
+		return a.Source < b.Source
+	})
+	for _, w := range n.Weaknesses {
+		for _, d := range w.Description {
+			if d.Value != "NVD-CWE-noinfo" && d.Value != "NVD-CWE-Other" && !contains(c.Weaknesses, d.Value) {
+				c.Weaknesses = append(c.Weaknesses, d.Value)
+			}
+		}
+	}
+	for _, r := range n.References {
+		c.References = append(c.References, Reference{URL: r.URL, Tags: r.Tags})
+	}
+	return c
+}
+
+func contains(list []string, s string) bool {
+	for _, existing := range list {
+		if existing == s {
+			return true
+		}
+	}
+	return false
+}
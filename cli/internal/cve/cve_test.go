@@ -0,0 +1,161 @@
+package cve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// log4shell is an NVD API 2.0 answer, shortened
+const log4shell = `{"resultsPerPage": 1, "totalResults": 1, "vulnerabilities": [{"cve": {
+  "id": "CVE-2021-44228",
+  "published": "2021-12-10T10:15:09.143",
+  "lastModified": "2024-07-24T17:08:24.167",
+  "vulnStatus": "Analyzed",
+  "cisaExploitAdd": "2021-12-10",
+  "descriptions": [{"lang": "en", "value": "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP."}, {"lang": "es", "value": "Las funciones JNDI..."}],
+  "metrics": {
+    "cvssMetricV31": [
+      {"source": "security@apache.org", "type": "Secondary", "cvssData": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", "baseScore": 10.0, "baseSeverity": "CRITICAL"}},
+      {"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", "baseScore": 10.0, "baseSeverity": "CRITICAL"}}
+    ],
+    "cvssMetricV2": [{"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"version": "2.0", "vectorString": "AV:N/AC:M/Au:N/C:C/I:C/A:C", "baseScore": 9.3}, "baseSeverity": "HIGH"}]
+  },
+  "weaknesses": [{"description": [{"lang": "en", "value": "CWE-917"}]}, {"description": [{"lang": "en", "value": "CWE-502"}, {"lang": "en", "value": "CWE-917"}]}],
+  "references": [{"url": "https://logging.apache.org/log4j/2.x/security.html", "tags": ["Vendor Advisory"]}]
+}}]}`
+
+// nvdServer answers like the NVD and counts the requests
+func nvdServer(t *testing.T, requests *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		switch r.URL.Query().Get("cveId") {
+		case "CVE-2021-44228":
+			w.Write([]byte(log4shell))
+		case "CVE-2099-0001":
+			w.Write([]byte(`{"resultsPerPage": 0, "totalResults": 0, "vulnerabilities": []}`))
+		case "CVE-2024-9999":
+			if r.Header.Get("apiKey") == "" {
+				http.Error(w, "rate limited", http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"vulnerabilities": []}`))
+		default:
+			http.Error(w, "unexpected", http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testClient(t *testing.T, requests *int32) *Client {
+	c := NewClient("", false)
+	c.BaseURL = nvdServer(t, requests).URL
+	c.CacheDir = t.TempDir()
+	return c
+}
+
+func TestLookup(t *testing.T) {
+	var requests int32
+	c := testClient(t, &requests)
+
+	got, err := c.Lookup(context.Background(), " cve-2021-44228 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "CVE-2021-44228" || got.Cached || !got.KnownExploited || got.Status != "Analyzed" {
+		t.Errorf("CVE = %+v", got)
+	}
+	if got.Description != "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP." {
+		t.Errorf("description = %q", got.Description)
+	}
+	if want := time.Date(2021, 12, 10, 10, 15, 9, 143e6, time.UTC); !got.Published.Equal(want) {
+		t.Errorf("published = %v, want %v", got.Published, want)
+	}
+	if len(got.Scores) != 3 {
+		t.Fatalf("scores = %+v", got.Scores)
+	}
+	if top := got.Scores[0]; top.Version != "3.1" || !top.Primary || top.Score != 10 || top.Severity != "CRITICAL" {
+		t.Errorf("top score = %+v", top)
+	}
+	if v2 := got.Scores[2]; v2.Version != "2.0" || v2.Severity != "HIGH" {
+		t.Errorf("CVSS v2 score = %+v", v2)
+	}
+	if len(got.Weaknesses) != 2 || got.Weaknesses[0] != "CWE-917" || len(got.References) != 1 {
+		t.Errorf("weaknesses = %v, references = %v", got.Weaknesses, got.References)
+	}
+
+	// The second lookup is answered from the cache
+	got, err = c.Lookup(context.Background(), "CVE-2021-44228")
+	if err != nil || !got.Cached || requests != 1 {
+		t.Errorf("second lookup: cached = %v, err = %v, %d requests", got != nil && got.Cached, err, requests)
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	var requests int32
+	c := testClient(t, &requests)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Lookup(context.Background(), "CVE-2099-0001"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("lookup %d: err = %v, want ErrNotFound", i+1, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, want the unknown ID to be cached", requests)
+	}
+	if _, err := c.Lookup(context.Background(), "log4shell"); err == nil {
+		t.Error("an invalid ID was looked up")
+	}
+}
+
+func TestLookupOffline(t *testing.T) {
+	var requests int32
+	c := testClient(t, &requests)
+	if _, err := c.Lookup(context.Background(), "CVE-2021-44228"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Offline, the cache is used however old it is and nothing else is fetched
+	c.Offline = true
+	c.MaxAge = time.Nanosecond
+	if got, err := c.Lookup(context.Background(), "CVE-2021-44228"); err != nil || !got.Cached {
+		t.Errorf("offline lookup of a cached CVE = %+v, %v", got, err)
+	}
+	if _, err := c.Lookup(context.Background(), "CVE-2099-0001"); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline lookup of an uncached CVE: err = %v, want ErrOffline", err)
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, want none while offline", requests)
+	}
+}
+
+func TestLookupStaleAndRateLimit(t *testing.T) {
+	var requests int32
+	c := testClient(t, &requests)
+	if _, err := c.Lookup(context.Background(), "CVE-2021-44228"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A stale entry is used when the NVD can't be reached
+	c.MaxAge = time.Nanosecond
+	c.BaseURL = "http://127.0.0.1:1"
+	if got, err := c.Lookup(context.Background(), "CVE-2021-44228"); err != nil || !got.Cached {
+		t.Errorf("stale lookup = %+v, %v", got, err)
+	}
+
+	var limited int32
+	c = testClient(t, &limited)
+	if _, err := c.Lookup(context.Background(), "CVE-2024-9999"); err == nil || !strings.Contains(err.Error(), "HACKARE_NVD_API_KEY") {
+		t.Errorf("rate limited lookup: err = %v", err)
+	}
+	c.APIKey = "key"
+	if _, err := c.Lookup(context.Background(), "CVE-2024-9999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("lookup with an API key: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,141 @@
+package draft
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Op is the kind of a diff span
+type Op int
+
+// Diff operations
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Span is a run of text that is unchanged, only in the draft, or only in the refinement
+type Span struct {
+	Op   Op
+	Text string
+}
+
+// maxDiffCells bounds the LCS table; larger texts are shown as a full replacement
+const maxDiffCells = 4_000_000
+
+// Diff compares two texts word by word, keeping whitespace so the spans
+// concatenate back to either text
+func Diff(a, b string) []Span {
+	x, y := tokenize(a), tokenize(b)
+	if (len(x)+1)*(len(y)+1) > maxDiffCells {
+		return compact([]Span{{Delete, a}, {Insert, b}})
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var spans []Span
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			spans = append(spans, Span{Equal, x[i]})
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			spans = append(spans, Span{Delete, x[i]})
+			i++
+		default:
+			spans = append(spans, Span{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		spans = append(spans, Span{Delete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		spans = append(spans, Span{Insert, y[j]})
+	}
+	return compact(spans)
+}
+
+// tokenize splits text into alternating words and whitespace runs
+func tokenize(text string) []string {
+	var tokens []string
+	start, inSpace := 0, false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if i > start && space != inSpace {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// compact merges adjacent spans with the same operation and drops empty ones
+func compact(spans []Span) []Span {
+	var merged []Span
+	for _, s := range spans {
+		if s.Text == "" {
+			continue
+		}
+		if n := len(merged); n > 0 && merged[n-1].Op == s.Op {
+			merged[n-1].Text += s.Text
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// Render formats a diff in git's word-diff style, [-removed-]{+added+},
+// optionally wrapped in ANSI colors
+func Render(spans []Span, color bool) string {
+	var b strings.Builder
+	for _, s := range spans {
+		switch s.Op {
+		case Equal:
+			b.WriteString(s.Text)
+		case Delete:
+			if color {
+				b.WriteString("\033[31m[-" + s.Text + "-]\033[0m")
+			} else {
+				b.WriteString("[-" + s.Text + "-]")
+			}
+		case Insert:
+			if color {
+				b.WriteString("\033[32m{+" + s.Text + "+}\033[0m")
+			} else {
+				b.WriteString("{+" + s.Text + "+}")
+			}
+		}
+	}
+	return b.String()
+}
+
+// Changed reports whether the diff has any insertions or deletions
+func Changed(spans []Span) bool {
+	for _, s := range spans {
+		if s.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+// Package draft implements "draft locally, verify remotely": a fast local model
+// answers first and the configured remote model reviews and refines the draft.
+package draft
+
+import (
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// Approved is the reply the reviewing model gives when the draft needs no changes.
+// Asking for a marker instead of the full text keeps verification cheap.
+const Approved = "APPROVED"
+
+// verifyPrompt asks the remote model to review the draft that precedes it
+const verifyPrompt = `The previous assistant reply is a draft written by a smaller, faster model.
+Check it for factual errors, missing steps and unclear wording.
+If it is correct and complete, reply with exactly ` + Approved + ` and nothing else.
+Otherwise reply with the improved final answer only, written as a direct reply to my
+last message, without mentioning the draft or this review.`
+
+// VerifyMessages returns the conversation with the draft and the review request appended
+func VerifyMessages(conversation []api.Message, draft string) []api.Message {
+	messages := append([]api.Message(nil), conversation...)
+	return append(messages,
+		api.Message{Role: "assistant", Content: draft},
+		api.Message{Role: "user", Content: verifyPrompt},
+	)
+}
+
+// Refined interprets the reviewing model's reply. It returns the final answer and
+// whether the draft was accepted unchanged.
+func Refined(reply, draft string) (string, bool) {
+	trimmed := strings.TrimSpace(reply)
+	if trimmed == "" || strings.Trim(trimmed, ".*`\"' ") == Approved || trimmed == strings.TrimSpace(draft) {
+		return draft, true
+	}
+	return trimmed, false
+}
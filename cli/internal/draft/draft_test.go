@@ -0,0 +1,50 @@
+package draft
+
+import (
+	"testing"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+func TestDiff(t *testing.T) {
+	draft := "Port 22 runs  Telnet by default."
+	refined := "Port 22 runs SSH by default.\nPort 23 is Telnet."
+
+	spans := Diff(draft, refined)
+	if got := Render(spans, false); got != "Port 22 runs[-  Telnet-] {+SSH +}by default.{+\nPort 23 is Telnet.+}" {
+		t.Errorf("Render() = %q", got)
+	}
+
+	var a, b string
+	for _, s := range spans {
+		if s.Op != Insert {
+			a += s.Text
+		}
+		if s.Op != Delete {
+			b += s.Text
+		}
+	}
+	if a != draft || b != refined {
+		t.Errorf("spans don't rebuild the inputs: %q, %q", a, b)
+	}
+	if Changed(Diff(draft, draft)) {
+		t.Error("identical texts reported as changed")
+	}
+}
+
+func TestRefined(t *testing.T) {
+	for _, reply := range []string{"APPROVED", " **APPROVED.** ", "", "the draft"} {
+		if final, ok := Refined(reply, "the draft"); !ok || final != "the draft" {
+			t.Errorf("Refined(%q) = %q, %v; want the draft accepted", reply, final, ok)
+		}
+	}
+	if final, ok := Refined("A better answer\n", "the draft"); ok || final != "A better answer" {
+		t.Errorf("Refined() = %q, %v; want the refinement", final, ok)
+	}
+
+	conversation := []api.Message{{Role: "user", Content: "Which port does SSH use?"}}
+	messages := VerifyMessages(conversation, "22")
+	if len(messages) != 3 || messages[1].Content != "22" || len(conversation) != 1 {
+		t.Errorf("VerifyMessages() = %+v", messages)
+	}
+}
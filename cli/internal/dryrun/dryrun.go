@@ -0,0 +1,103 @@
+// Package dryrun shows the request a chat would send without sending it: the
+// endpoint, the exact JSON payload, and an estimate of the tokens each part of
+// the prompt takes.
+package dryrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/contextwindow"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// Section is one part of the prompt
+type Section struct {
+	Name   string `json:"name"`
+	Items  int    `json:"items"` // Messages, or tools for the tools section
+	Tokens int    `json:"tokens"`
+}
+
+// Report is the request that would be sent
+type Report struct {
+	Endpoint string          `json:"endpoint"`
+	Model    string          `json:"model"`
+	Payload  json.RawMessage `json:"payload"`
+	Bytes    int             `json:"bytes"`
+	Sections []Section       `json:"sections"`
+	Tokens   int             `json:"tokens"` // Estimated prompt tokens of all sections
+}
+
+// Build assembles the request client would send for messages. stream is
+// whether the caller would stream the reply.
+func Build(client *api.Client, messages []api.Message, stream bool) (*Report, error) {
+	request := client.BuildRequest(messages, stream)
+	payload, err := json.MarshalIndent(request, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	report := &Report{
+		Endpoint: client.Endpoint(),
+		Model:    request.Model,
+		Payload:  payload,
+		Bytes:    len(payload),
+		Sections: Sections(request),
+	}
+	for _, section := range report.Sections {
+		report.Tokens += section.Tokens
+	}
+	return report, nil
+}
+
+// Sections splits a request into the system prompt, the earlier
+// conversation, tool calls and results, the latest message and the tool
+// definitions. Sections that are empty are left out.
+func Sections(request api.ChatRequest) []Section {
+	sections := []Section{{Name: "system"}, {Name: "history"}, {Name: "tool calls"}, {Name: "latest message"}, {Name: "tools"}}
+	add := func(i, tokens int) {
+		sections[i].Items++
+		sections[i].Tokens += tokens
+	}
+
+	for i, msg := range request.Messages {
+		tokens := contextwindow.MessageTokens(msg.Content)
+		for _, call := range msg.ToolCalls {
+			tokens += usage.EstimateTokens(call.Function.Name + call.Function.Arguments)
+		}
+		switch {
+		case msg.Role == "system":
+			add(0, tokens)
+		case i == len(request.Messages)-1:
+			add(3, tokens)
+		case msg.Role == "tool" || len(msg.ToolCalls) > 0:
+			add(2, tokens)
+		default:
+			add(1, tokens)
+		}
+	}
+	for _, tool := range request.Tools {
+		definition, _ := json.Marshal(tool)
+		add(4, usage.EstimateTokens(string(definition)))
+	}
+
+	var present []Section
+	for _, section := range sections {
+		if section.Items > 0 {
+			present = append(present, section)
+		}
+	}
+	return present
+}
+
+// Write prints the endpoint, the payload and the token estimate per section
+func (r *Report) Write(w io.Writer) {
+	fmt.Fprintf(w, "POST %s\n\n%s\n\n", r.Endpoint, r.Payload)
+	fmt.Fprintf(w, "%-16s %6s %8s\n", "Section", "Items", "~Tokens")
+	for _, section := range r.Sections {
+		fmt.Fprintf(w, "%-16s %6d %8d\n", section.Name, section.Items, section.Tokens)
+	}
+	fmt.Fprintf(w, "%-16s %6s %8d\n", "total", "", r.Tokens)
+	fmt.Fprintf(w, "\n%d bytes for %s. Token counts are estimates; nothing was sent.\n", r.Bytes, r.Model)
+}
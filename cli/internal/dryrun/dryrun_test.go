@@ -0,0 +1,81 @@
+package dryrun
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/config"
+)
+
+func TestBuild(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Provider = config.ProviderOpenAI
+	cfg.BaseURL = "https://api.openai.com/v1"
+	cfg.Model = "gpt-4o"
+	cfg.APIKey = "sk-test-key"
+	client := api.NewClient(cfg)
+	client.SetTools([]api.Tool{{Type: "function", Function: api.ToolFunction{Name: "lookup", Description: "Look up an IP address"}}})
+
+	call := api.ToolCall{ID: "call_1", Type: "function"}
+	call.Function.Name = "lookup"
+	call.Function.Arguments = `{"ip":"203.0.113.7"}`
+	messages := []api.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "Check 203.0.113.7"},
+		{Role: "assistant", ToolCalls: []api.ToolCall{call}},
+		{Role: "tool", ToolCallID: "call_1", Content: `{"open":[22]}`},
+		{Role: "assistant", Content: "Only SSH is open."},
+		{Role: "user", Content: "What runs on it?"},
+	}
+
+	report, err := Build(client, messages, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Endpoint != "https://api.openai.com/v1/chat/completions" || report.Model != "gpt-4o" {
+		t.Errorf("report = %s %s", report.Endpoint, report.Model)
+	}
+
+	var payload api.ChatRequest
+	if err := json.Unmarshal(report.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Messages) != 6 || len(payload.Tools) != 1 || payload.Stream {
+		t.Errorf("payload = %s", report.Payload)
+	}
+	if strings.Contains(string(report.Payload), "sk-test-key") {
+		t.Error("the payload holds the API key")
+	}
+
+	items := map[string]int{}
+	total := 0
+	for _, section := range report.Sections {
+		items[section.Name] = section.Items
+		total += section.Tokens
+	}
+	want := map[string]int{"system": 1, "history": 2, "tool calls": 2, "latest message": 1, "tools": 1}
+	for name, n := range want {
+		if items[name] != n {
+			t.Errorf("%s has %d item(s), want %d", name, items[name], n)
+		}
+	}
+	if total != report.Tokens || report.Tokens == 0 {
+		t.Errorf("tokens = %d, sections sum to %d", report.Tokens, total)
+	}
+
+	var out bytes.Buffer
+	report.Write(&out)
+	if !strings.HasPrefix(out.String(), "POST https://api.openai.com/v1/chat/completions") || !strings.Contains(out.String(), "nothing was sent") {
+		t.Errorf("output = %s", out.String())
+	}
+}
+
+func TestSectionsLeaveOutEmpty(t *testing.T) {
+	sections := Sections(api.ChatRequest{Messages: []api.Message{{Role: "user", Content: "hi"}}})
+	if len(sections) != 1 || sections[0].Name != "latest message" {
+		t.Errorf("sections = %+v", sections)
+	}
+}
@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+func TestParseExample(t *testing.T) {
+	suite, err := Parse([]byte(Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.Name != "triage" || len(suite.Models) != 2 || suite.Judge != "gpt-4o" || len(suite.Cases) != 3 {
+		t.Fatalf("suite = %+v", suite)
+	}
+	if kind := suite.Cases[1].Expect[0].Kind(); kind != "json_schema" {
+		t.Errorf("second case scorer = %q", kind)
+	}
+	if judge := suite.Cases[2].Expect[0]; judge.MinScore != 7 || !strings.HasPrefix(judge.Judge, "Gives three") {
+		t.Errorf("judge scorer = %+v", judge)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, bad := range []string{
+		"cases: []",
+		"cases:\n  - name: a\n    expect:\n      - regex: x",
+		"cases:\n  - name: a\n    prompt: hi",
+		"cases:\n  - name: a\n    prompt: hi\n    expect:\n      - regex: (",
+		"cases:\n  - name: a\n    prompt: hi\n    expect:\n      - regex: x\n        judge: good",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestScoreJSON(t *testing.T) {
+	suite, err := Parse([]byte(Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scorer := suite.Cases[1].Expect[0]
+	tests := []struct {
+		reply string
+		pass  bool
+	}{
+		{`{"cve": "CVE-2024-3094", "package": "xz"}`, true},
+		{"Here you go:\n```json\n{\"cve\": \"CVE-2024-3094\", \"package\": \"xz\"}\n```", true},
+		{`{"cve": "CVE-2024-3094"}`, false},
+		{`{"cve": "CVE-2021-44228", "package": "log4j"}`, false},
+		{`{"cve": 3094, "package": "xz"}`, false},
+		{`The CVE is CVE-2024-3094`, false},
+	}
+	for _, tt := range tests {
+		if check := scoreJSON(scorer, tt.reply); check.Passed != tt.pass {
+			t.Errorf("scoreJSON(%q) = %+v, want passed=%v", tt.reply, check, tt.pass)
+		}
+	}
+}
+
+func TestParseJudgement(t *testing.T) {
+	check := parseJudgement(Scorer{Judge: "x"}, "Clear and correct steps.\nSCORE: 8")
+	if !check.Passed || check.Score != 0.8 || check.Detail != "8/10 Clear and correct steps." {
+		t.Errorf("check = %+v", check)
+	}
+	if check := parseJudgement(Scorer{Judge: "x", MinScore: 9}, "Score: 8/10"); check.Passed {
+		t.Errorf("8 passed a min_score of 9")
+	}
+	if check := parseJudgement(Scorer{Judge: "x"}, "Looks fine"); check.Passed || check.Detail == "" {
+		t.Errorf("a judgement without a score = %+v", check)
+	}
+}
+
+// scripted replies by the content of the last message
+type scripted struct {
+	mu      sync.Mutex
+	replies func(last string) string
+	calls   int
+}
+
+func (s *scripted) SendChatCompletionContext(ctx context.Context, messages []api.Message, cb api.StreamCallback) (*api.ChatResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	reply := s.replies(messages[len(messages)-1].Content)
+	response := &api.ChatResponse{Choices: []api.Choice{{Message: api.Message{Content: reply}}}}
+	response.Usage.PromptTokens, response.Usage.CompletionTokens, response.Usage.TotalTokens = 100, 10, 110
+	return response, nil
+}
+
+func TestRunComparesModels(t *testing.T) {
+	dir := t.TempDir()
+	recorded := "## User\n\nWe saw logins from 203.0.113.5.\n\n## Assistant\n\nBlock it.\n\n## User\n\nWhich port do they target?\n\n## Assistant\n\nPort 22.\n"
+	if err := os.WriteFile(filepath.Join(dir, "chat.md"), []byte(recorded), 0600); err != nil {
+		t.Fatal(err)
+	}
+	suitePath := filepath.Join(dir, "suite.yaml")
+	suiteFile := `models: [good, bad]
+cases:
+  - name: port
+    conversation: chat.md
+    expect:
+      - regex: \b22\b
+  - name: advice
+    prompt: What now?
+    expect:
+      - not_regex: (?i)no idea
+      - judge: Practical next steps
+`
+	if err := os.WriteFile(suitePath, []byte(suiteFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	suite, err := Load(suitePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.Name != "suite" {
+		t.Errorf("name = %q, want the file name", suite.Name)
+	}
+
+	good := &scripted{replies: func(string) string { return "Port 22, rotate the keys." }}
+	bad := &scripted{replies: func(string) string { return "No idea." }}
+	judge := &scripted{replies: func(prompt string) string {
+		if strings.Contains(prompt, "rotate the keys") {
+			return "Concrete.\nSCORE: 9"
+		}
+		return "Unhelpful.\nSCORE: 2"
+	}}
+	var live []string
+	runner := &Runner{
+		Suite:    suite,
+		Models:   []string{"good", "bad"},
+		Clients:  map[string]Completer{"good": good, "bad": bad},
+		Judge:    judge,
+		OnResult: func(r Result) { live = append(live, r.Case+"/"+r.Model) },
+	}
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(live, " ") != "port/good port/bad advice/good advice/bad" {
+		t.Errorf("results in order %v", live)
+	}
+	if judge.calls != 2 {
+		t.Errorf("judge called %d times, want 2", judge.calls)
+	}
+	if report.Failed() != 2 || report.Models[0].Passed != 2 || report.Models[1].Passed != 0 {
+		t.Errorf("summaries = %+v", report.Models)
+	}
+	if got := report.Results[2].Score; got < 0.949 || got > 0.951 {
+		t.Errorf("advice/good score = %v, want 0.95", got)
+	}
+	if report.Models[0].Tokens != 220 || report.JudgeTokens != 220 {
+		t.Errorf("tokens = %d, judge tokens = %d", report.Models[0].Tokens, report.JudgeTokens)
+	}
+
+	var md strings.Builder
+	if err := report.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| good | 2/2 |", "| port | ✅ 1.00 | ❌ 0.00 |", "### advice — bad", "not_regex: found \"No idea\""} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("report is missing %q:\n%s", want, md.String())
+		}
+	}
+}
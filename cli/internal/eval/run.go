@@ -0,0 +1,307 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// Completer sends chat completions; *api.Client implements it
+type Completer interface {
+	SendChatCompletionContext(ctx context.Context, messages []api.Message, streamCallback api.StreamCallback) (*api.ChatResponse, error)
+}
+
+// Result is one case run against one model
+type Result struct {
+	Case             string  `json:"case"`
+	Model            string  `json:"model"`
+	Reply            string  `json:"reply"`
+	Checks           []Check `json:"checks"`
+	Passed           bool    `json:"passed"`
+	Score            float64 `json:"score"` // Mean of the checks, 0 to 1
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	Cost             float64 `json:"cost"` // USD, without judging
+	LatencyMs        int64   `json:"latencyMs"`
+	Error            string  `json:"error,omitempty"`
+}
+
+// ModelSummary totals one model's results
+type ModelSummary struct {
+	Model     string  `json:"model"`
+	Passed    int     `json:"passed"`
+	Cases     int     `json:"cases"`
+	Score     float64 `json:"score"` // Mean over the cases, 0 to 1
+	Tokens    int     `json:"tokens"`
+	Cost      float64 `json:"cost"`      // USD
+	LatencyMs int64   `json:"latencyMs"` // Mean per case
+}
+
+// Report is the record of a run
+type Report struct {
+	Suite       string         `json:"suite"`
+	Started     time.Time      `json:"started"`
+	Models      []ModelSummary `json:"models"`
+	Results     []Result       `json:"results"`
+	JudgeTokens int            `json:"judgeTokens,omitempty"`
+	Cost        float64        `json:"cost"`           // USD, including judging
+	Seed        int            `json:"seed,omitempty"` // Sampling seed of the run
+}
+
+// Failed returns the number of results that didn't pass
+func (r *Report) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Runner runs a suite against models
+type Runner struct {
+	Suite   *Suite
+	Models  []string             // In report order
+	Clients map[string]Completer // By model
+	System  string               // System prompt when neither the suite nor the case sets one
+
+	// Judge grades replies for judge scorers; it may be nil when no case uses one
+	Judge      Completer
+	JudgeModel string
+
+	// Tracker prices and records every request; nil prices without recording
+	Tracker *usage.Tracker
+
+	// Seed the clients send, recorded in the report; 0 for none
+	Seed int
+
+	// OnResult is called as each result is scored, for live progress
+	OnResult func(Result)
+}
+
+// Run sends every case to every model and scores the replies. Failed requests
+// are recorded in the results; only cancellation stops the run early.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	report := &Report{Suite: r.Suite.Name, Started: time.Now(), Seed: r.Seed}
+	if r.Tracker == nil {
+		r.Tracker = usage.NewTracker("")
+	}
+	system := r.Suite.System
+	if system == "" {
+		system = r.System
+	}
+
+	for i := range r.Suite.Cases {
+		c := &r.Suite.Cases[i]
+		messages, err := c.Messages(system)
+		if err != nil {
+			return report, fmt.Errorf("case %q: %w", c.Name, err)
+		}
+		for _, model := range r.Models {
+			result := r.runCase(ctx, c, model, messages, report)
+			if ctx.Err() != nil {
+				report.summarize(r.Models)
+				return report, ctx.Err()
+			}
+			report.Results = append(report.Results, result)
+			report.Cost += result.Cost
+			if r.OnResult != nil {
+				r.OnResult(result)
+			}
+		}
+	}
+	report.summarize(r.Models)
+	return report, nil
+}
+
+// runCase gets one model's reply to a case and scores it
+func (r *Runner) runCase(ctx context.Context, c *Case, model string, messages []api.Message, report *Report) Result {
+	result := Result{Case: c.Name, Model: model, Checks: []Check{}}
+	client := r.Clients[model]
+	if client == nil {
+		result.Error = "no client for model"
+		return result
+	}
+
+	started := time.Now()
+	reply, promptTokens, completionTokens, err := r.complete(ctx, client, model, messages)
+	result.LatencyMs = time.Since(started).Milliseconds()
+	result.PromptTokens, result.CompletionTokens = promptTokens, completionTokens
+	result.Cost = r.Tracker.Cost(model, promptTokens, completionTokens)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Reply = reply
+
+	question := messages[len(messages)-1].Content
+	result.Passed = true
+	for _, scorer := range c.Expect {
+		var check Check
+		switch scorer.Kind() {
+		case "regex", "not_regex":
+			check = scoreMatch(scorer, reply)
+		case "json_schema":
+			check = scoreJSON(scorer, reply)
+		case "judge":
+			check = r.judge(ctx, scorer, question, reply, report)
+		}
+		result.Checks = append(result.Checks, check)
+		result.Passed = result.Passed && check.Passed
+		result.Score += check.Score / float64(len(c.Expect))
+	}
+	return result
+}
+
+// judge asks the judge model to grade a reply
+func (r *Runner) judge(ctx context.Context, scorer Scorer, question, reply string, report *Report) Check {
+	if r.Judge == nil {
+		return Check{Scorer: scorer.Kind(), Detail: "no judge model"}
+	}
+	messages := []api.Message{
+		{Role: "system", Content: judgeSystemPrompt},
+		{Role: "user", Content: judgePrompt(scorer.Judge, question, reply)},
+	}
+	judgement, promptTokens, completionTokens, err := r.complete(ctx, r.Judge, r.JudgeModel, messages)
+	report.JudgeTokens += promptTokens + completionTokens
+	report.Cost += r.Tracker.Cost(r.JudgeModel, promptTokens, completionTokens)
+	if err != nil {
+		return Check{Scorer: scorer.Kind(), Detail: fmt.Sprintf("judge failed: %v", err)}
+	}
+	return parseJudgement(scorer, judgement)
+}
+
+// complete sends one request, records its usage and returns the reply and token counts
+func (r *Runner) complete(ctx context.Context, client Completer, model string, messages []api.Message) (string, int, int, error) {
+	response, err := client.SendChatCompletionContext(ctx, messages, nil)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if len(response.Choices) == 0 {
+		return "", 0, 0, fmt.Errorf("the model returned no answer")
+	}
+	reply := strings.TrimSpace(response.Choices[0].Message.Content)
+	promptTokens, completionTokens := response.Usage.PromptTokens, response.Usage.CompletionTokens
+	if response.Usage.TotalTokens == 0 {
+		for _, msg := range messages {
+			promptTokens += usage.EstimateTokens(msg.Content)
+		}
+		completionTokens = usage.EstimateTokens(reply)
+	}
+	r.Tracker.RecordCached(model, promptTokens, response.CachedTokens(), completionTokens)
+	return reply, promptTokens, completionTokens, nil
+}
+
+// summarize totals the results per model
+func (r *Report) summarize(models []string) {
+	r.Models = make([]ModelSummary, 0, len(models))
+	for _, model := range models {
+		summary := ModelSummary{Model: model}
+		var latency int64
+		for _, result := range r.Results {
+			if result.Model != model {
+				continue
+			}
+			summary.Cases++
+			if result.Passed {
+				summary.Passed++
+			}
+			summary.Score += result.Score
+			summary.Tokens += result.PromptTokens + result.CompletionTokens
+			summary.Cost += result.Cost
+			latency += result.LatencyMs
+		}
+		if summary.Cases > 0 {
+			summary.Score /= float64(summary.Cases)
+			summary.LatencyMs = latency / int64(summary.Cases)
+		}
+		r.Models = append(r.Models, summary)
+	}
+}
+
+// WriteMarkdown writes the comparison table, the per-case grid and the failures
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Eval: %s\n\n_%s · %d results · %d failed · $%.4f",
+		r.Suite, r.Started.Format("2006-01-02 15:04"), len(r.Results), r.Failed(), r.Cost)
+	if r.JudgeTokens > 0 {
+		fmt.Fprintf(&b, " · %d judge tokens", r.JudgeTokens)
+	}
+	if r.Seed != 0 {
+		fmt.Fprintf(&b, " · seed %d", r.Seed)
+	}
+	b.WriteString("_\n\n## Models\n\n| Model | Passed | Score | Tokens | Cost | Latency |\n|---|---|---|---|---|---|\n")
+	for _, m := range r.Models {
+		fmt.Fprintf(&b, "| %s | %d/%d | %.2f | %d | $%.4f | %.1fs |\n",
+			m.Model, m.Passed, m.Cases, m.Score, m.Tokens, m.Cost, float64(m.LatencyMs)/1000)
+	}
+
+	b.WriteString("\n## Cases\n\n| Case |")
+	for _, m := range r.Models {
+		fmt.Fprintf(&b, " %s |", m.Model)
+	}
+	b.WriteString("\n|---|" + strings.Repeat("---|", len(r.Models)) + "\n")
+	var cases []string
+	byCase := map[string]map[string]Result{}
+	for _, result := range r.Results {
+		if byCase[result.Case] == nil {
+			byCase[result.Case] = map[string]Result{}
+			cases = append(cases, result.Case)
+		}
+		byCase[result.Case][result.Model] = result
+	}
+	for _, name := range cases {
+		fmt.Fprintf(&b, "| %s |", name)
+		for _, m := range r.Models {
+			result, ok := byCase[name][m.Model]
+			switch {
+			case !ok:
+				b.WriteString(" |")
+			case result.Error != "":
+				b.WriteString(" ⚠️ error |")
+			case result.Passed:
+				fmt.Fprintf(&b, " ✅ %.2f |", result.Score)
+			default:
+				fmt.Fprintf(&b, " ❌ %.2f |", result.Score)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if r.Failed() > 0 {
+		b.WriteString("\n## Failures\n")
+		for _, result := range r.Results {
+			if result.Passed {
+				continue
+			}
+			fmt.Fprintf(&b, "\n### %s — %s\n\n", result.Case, result.Model)
+			if result.Error != "" {
+				fmt.Fprintf(&b, "- Error: %s\n", result.Error)
+				continue
+			}
+			for _, check := range result.Checks {
+				if !check.Passed {
+					fmt.Fprintf(&b, "- %s: %s\n", check.Scorer, check.Detail)
+				}
+			}
+			fmt.Fprintf(&b, "\n%s\n", quote(result.Reply, 20))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quote renders up to maxLines of text as a markdown quote
+func quote(text string, maxLines int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines], "…")
+	}
+	return "> " + strings.Join(lines, "\n> ")
+}
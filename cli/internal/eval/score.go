@@ -0,0 +1,249 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Check is the outcome of one scorer on one reply
+type Check struct {
+	Scorer string  `json:"scorer"` // regex, not_regex, json_schema or judge
+	Passed bool    `json:"passed"`
+	Score  float64 `json:"score"` // 0 to 1
+	Detail string  `json:"detail,omitempty"`
+}
+
+// scoreMatch runs the regex and not_regex scorers
+func scoreMatch(s Scorer, reply string) Check {
+	check := Check{Scorer: s.Kind()}
+	if s.Regex != "" {
+		check.Passed = regexp.MustCompile(s.Regex).MatchString(reply)
+		if !check.Passed {
+			check.Detail = fmt.Sprintf("no match for %s", s.Regex)
+		}
+	} else {
+		match := regexp.MustCompile(s.NotRegex).FindString(reply)
+		check.Passed = match == ""
+		if !check.Passed {
+			check.Detail = fmt.Sprintf("found %q", match)
+		}
+	}
+	if check.Passed {
+		check.Score = 1
+	}
+	return check
+}
+
+// scoreJSON checks that the reply is JSON valid against the scorer's schema.
+// A fenced ```json block is accepted, as models often add one.
+func scoreJSON(s Scorer, reply string) Check {
+	check := Check{Scorer: s.Kind()}
+	var value interface{}
+	if err := json.Unmarshal([]byte(extractJSON(reply)), &value); err != nil {
+		check.Detail = fmt.Sprintf("not JSON: %v", err)
+		return check
+	}
+	if err := validateSchema(s.JSONSchema, value, "$"); err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.Passed, check.Score = true, 1
+	return check
+}
+
+// extractJSON returns the content of the first fenced code block, or the trimmed reply
+func extractJSON(reply string) string {
+	reply = strings.TrimSpace(reply)
+	start := strings.Index(reply, "```")
+	if start < 0 {
+		return reply
+	}
+	body := reply[start+3:]
+	if newline := strings.IndexByte(body, '\n'); newline >= 0 {
+		body = body[newline+1:]
+	}
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+	return strings.TrimSpace(body)
+}
+
+// validateSchema checks value against the JSON Schema keywords models are
+// usually asked to follow: type, enum, const, required, properties,
+// additionalProperties (false), items, minItems, maxItems, minLength,
+// maxLength, pattern, minimum and maximum. Other keywords are ignored.
+func validateSchema(schema map[string]interface{}, value interface{}, path string) error {
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		return fmt.Errorf("%s: expected %v, got %s", path, t, typeOf(value))
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			found = found || equalJSON(allowed, value)
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+	if want, ok := schema["const"]; ok && !equalJSON(want, value) {
+		return fmt.Errorf("%s: expected %v, got %v", path, want, value)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, key := range required {
+				if _, ok := v[fmt.Sprint(key)]; !ok {
+					return fmt.Errorf("%s: missing %q", path, key)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			sub, ok := properties[key].(map[string]interface{})
+			if !ok {
+				if additional, set := schema["additionalProperties"].(bool); set && !additional {
+					return fmt.Errorf("%s: unexpected property %q", path, key)
+				}
+				continue
+			}
+			if err := validateSchema(sub, v[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if n, ok := number(schema["minItems"]); ok && float64(len(v)) < n {
+			return fmt.Errorf("%s: %d items, want at least %v", path, len(v), n)
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(v)) > n {
+			return fmt.Errorf("%s: %d items, want at most %v", path, len(v), n)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			return fmt.Errorf("%s: shorter than %v characters", path, n)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			return fmt.Errorf("%s: longer than %v characters", path, n)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern in schema: %v", path, err)
+			}
+			if !re.MatchString(v) {
+				return fmt.Errorf("%s: %q doesn't match %s", path, v, pattern)
+			}
+		}
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && v < n {
+			return fmt.Errorf("%s: %v is below %v", path, v, n)
+		}
+		if n, ok := number(schema["maximum"]); ok && v > n {
+			return fmt.Errorf("%s: %v is above %v", path, v, n)
+		}
+	}
+	return nil
+}
+
+// matchesType reports whether value has the schema type, or one of a list of types
+func matchesType(t interface{}, value interface{}) bool {
+	if list, ok := t.([]interface{}); ok {
+		for _, one := range list {
+			if matchesType(one, value) {
+				return true
+			}
+		}
+		return false
+	}
+	want := fmt.Sprint(t)
+	got := typeOf(value)
+	return want == got || (want == "number" && got == "integer")
+}
+
+// typeOf returns the JSON Schema type name of a decoded JSON value
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func number(v interface{}) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+func equalJSON(a, b interface{}) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+// judgeSystemPrompt tells the judge model how to grade
+const judgeSystemPrompt = `You grade replies from an AI assistant against a rubric. Be strict and consistent. Explain your grade in one sentence, then end with a line "SCORE: N" where N is a whole number from 1 (fails the rubric) to 10 (fully meets it).`
+
+// judgePrompt asks the judge to grade reply to the last user message
+func judgePrompt(rubric, question, reply string) string {
+	return fmt.Sprintf("# Rubric\n\n%s\n\n# User message\n\n%s\n\n# Reply to grade\n\n%s\n",
+		strings.TrimSpace(rubric), strings.TrimSpace(question), strings.TrimSpace(reply))
+}
+
+var judgeScore = regexp.MustCompile(`(?i)score\W*(\d+(?:\.\d+)?)`)
+
+// parseJudgement reads the last "SCORE: N" of the judge's reply into a check
+func parseJudgement(s Scorer, judgement string) Check {
+	check := Check{Scorer: s.Kind()}
+	matches := judgeScore.FindAllStringSubmatchIndex(judgement, -1)
+	if len(matches) == 0 {
+		check.Detail = "the judge gave no score"
+		return check
+	}
+	last := matches[len(matches)-1]
+	score, _ := strconv.ParseFloat(judgement[last[2]:last[3]], 64)
+	score = math.Max(1, math.Min(10, score))
+	minScore := s.MinScore
+	if minScore == 0 {
+		minScore = DefaultMinScore
+	}
+	check.Score = score / 10
+	check.Passed = score >= minScore
+
+	reason := strings.TrimSpace(judgement[:last[0]])
+	if line := strings.IndexByte(reason, '\n'); line >= 0 {
+		reason = reason[:line]
+	}
+	check.Detail = fmt.Sprintf("%g/10 %s", score, strings.TrimSpace(reason))
+	return check
+}
@@ -0,0 +1,255 @@
+// Package eval replays a suite of prompts against one or more models and
+// scores the replies with regular expressions, JSON schemas or a judge model,
+// so that models, prompts and providers can be compared on the same cases.
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/transcript"
+	"github.com/hacka-re/cli/internal/yaml"
+)
+
+// DefaultFile is the suite file written by 'eval init'
+const DefaultFile = "suite.yaml"
+
+// DefaultMinScore is the judge score (1-10) a reply needs to pass
+const DefaultMinScore = 7
+
+// Suite is the content of a suite file
+type Suite struct {
+	Name   string   `json:"name,omitempty"`
+	Models []string `json:"models,omitempty"` // Default: the configured model
+	Judge  string   `json:"judge,omitempty"`  // Model for judge scorers; default: the configured model
+	System string   `json:"system,omitempty"` // System prompt for cases that set none
+	Cases  []Case   `json:"cases"`
+}
+
+// Case is one prompt and what its reply must satisfy
+type Case struct {
+	Name   string `json:"name"`
+	System string `json:"system,omitempty"`
+	Prompt string `json:"prompt,omitempty"`
+
+	// Conversation is a recorded chat (any format 'chat import' reads) that is
+	// replayed before Prompt. A trailing assistant reply is left out so the
+	// model answers the last user message again.
+	Conversation string `json:"conversation,omitempty"`
+
+	Expect []Scorer `json:"expect"`
+
+	history []api.Message
+}
+
+// Scorer checks a reply. Exactly one of Regex, NotRegex, JSONSchema and Judge is set.
+type Scorer struct {
+	Regex      string                 `json:"regex,omitempty"`       // The reply must match
+	NotRegex   string                 `json:"not_regex,omitempty"`   // The reply must not match
+	JSONSchema map[string]interface{} `json:"json_schema,omitempty"` // The reply must be JSON valid against it
+	Judge      string                 `json:"judge,omitempty"`       // Rubric the judge model grades the reply on
+	MinScore   float64                `json:"min_score,omitempty"`   // Judge score needed to pass; default DefaultMinScore
+}
+
+// Kind names the scorer for reports
+func (s Scorer) Kind() string {
+	switch {
+	case s.Regex != "":
+		return "regex"
+	case s.NotRegex != "":
+		return "not_regex"
+	case s.JSONSchema != nil:
+		return "json_schema"
+	case s.Judge != "":
+		return "judge"
+	}
+	return ""
+}
+
+// UsesJudge reports whether any case has a judge scorer
+func (s *Suite) UsesJudge() bool {
+	for _, c := range s.Cases {
+		for _, scorer := range c.Expect {
+			if scorer.Judge != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Load reads a suite file in YAML (or JSON) and validates it. Conversation
+// paths are relative to the file.
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite: %w", err)
+	}
+	suite, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	for i := range suite.Cases {
+		c := &suite.Cases[i]
+		if c.Conversation == "" {
+			continue
+		}
+		if !filepath.IsAbs(c.Conversation) {
+			c.Conversation = filepath.Join(filepath.Dir(path), c.Conversation)
+		}
+		if _, err := c.Messages(""); err != nil {
+			return nil, fmt.Errorf("%s: case %q: %w", filepath.Base(path), c.Name, err)
+		}
+	}
+	return suite, nil
+}
+
+// Parse decodes and validates a suite definition
+func Parse(data []byte) (*Suite, error) {
+	var suite Suite
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		if err := json.Unmarshal(data, &suite); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("invalid suite: %w", err)
+	}
+	if err := suite.validate(); err != nil {
+		return nil, err
+	}
+	return &suite, nil
+}
+
+func (s *Suite) validate() error {
+	if len(s.Cases) == 0 {
+		return fmt.Errorf("no cases defined")
+	}
+	names := map[string]bool{}
+	for _, c := range s.Cases {
+		if c.Name == "" {
+			return fmt.Errorf("every case needs a name")
+		}
+		if names[c.Name] {
+			return fmt.Errorf("duplicate case %q", c.Name)
+		}
+		names[c.Name] = true
+		if strings.TrimSpace(c.Prompt) == "" && c.Conversation == "" {
+			return fmt.Errorf("case %q needs a prompt or a conversation", c.Name)
+		}
+		if len(c.Expect) == 0 {
+			return fmt.Errorf("case %q has nothing to expect", c.Name)
+		}
+		for _, scorer := range c.Expect {
+			if err := scorer.validate(); err != nil {
+				return fmt.Errorf("case %q: %w", c.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s Scorer) validate() error {
+	set := 0
+	for _, present := range []bool{s.Regex != "", s.NotRegex != "", s.JSONSchema != nil, s.Judge != ""} {
+		if present {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("each expect entry needs exactly one of regex, not_regex, json_schema or judge")
+	}
+	for _, pattern := range []string{s.Regex, s.NotRegex} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+	}
+	if s.MinScore < 0 || s.MinScore > 10 {
+		return fmt.Errorf("min_score must be between 1 and 10")
+	}
+	return nil
+}
+
+// Messages returns what is sent to the model: the system prompt (the case's,
+// else system), the recorded conversation and the prompt
+func (c *Case) Messages(system string) ([]api.Message, error) {
+	if c.history == nil && c.Conversation != "" {
+		conversations, err := transcript.ImportFile(c.Conversation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read conversation: %w", err)
+		}
+		history := conversations[0].Messages
+		for len(history) > 0 && history[len(history)-1].Role == "assistant" {
+			history = history[:len(history)-1]
+		}
+		c.history = history
+	}
+
+	var messages []api.Message
+	if c.System != "" {
+		system = c.System
+	}
+	if system != "" {
+		messages = append(messages, api.Message{Role: "system", Content: system})
+	}
+	for _, msg := range c.history {
+		if msg.Role == "system" && system != "" {
+			continue // Replaced by the suite's prompt
+		}
+		messages = append(messages, msg)
+	}
+	if strings.TrimSpace(c.Prompt) != "" {
+		messages = append(messages, api.Message{Role: "user", Content: c.Prompt})
+	}
+	if len(messages) == 0 || messages[len(messages)-1].Role != "user" {
+		return nil, fmt.Errorf("the conversation doesn't end with a user message and there is no prompt")
+	}
+	return messages, nil
+}
+
+// Example is written by 'eval init'
+const Example = `# hacka.re eval suite: every case is sent to every model and the reply is
+# checked by each expect entry (regex, not_regex, json_schema or judge).
+name: triage
+models: [gpt-4o-mini, gpt-4o]
+judge: gpt-4o         # grades judge entries; default: the configured model
+system: You are a concise security analyst.
+
+cases:
+  - name: ssh-port
+    prompt: Which TCP port does SSH use by default? Answer with the number.
+    expect:
+      - regex: \b22\b
+      - not_regex: (?i)i don't know
+
+  - name: cve-json
+    prompt: |
+      Give the CVE ID and affected package of the xz backdoor as JSON with
+      the keys "cve" and "package". Reply with the JSON only.
+    expect:
+      - json_schema:
+          type: object
+          required: [cve, package]
+          properties:
+            cve:
+              type: string
+              pattern: ^CVE-2024-3094$
+            package:
+              type: string
+
+  - name: phishing-advice
+    # conversation: chats/phishing.md   # replay a recorded chat first
+    prompt: A user clicked a phishing link. What are the first three steps?
+    expect:
+      - judge: |
+          Gives three concrete, correctly ordered first steps (isolate or
+          reset credentials, check for sessions or malware, report). No filler.
+        min_score: 7
+`
@@ -0,0 +1,127 @@
+// Package failure turns errors from the api and share packages into messages
+// for the user and process exit codes for scripts.
+//
+// Exit codes are part of the CLI's interface and must stay stable:
+//
+//	0    success
+//	1    other error
+//	2    bad usage, invalid or missing configuration, expired or corrupt share link
+//	3    authentication: wrong share link or snapshot password, or API key rejected
+//	4    network: provider unreachable, failing, rate limiting or timing out
+//	70   internal error (crash)
+//	130  interrupted (Ctrl+C)
+package failure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/snapshot"
+)
+
+// Exit codes by failure class
+const (
+	ExitOK        = 0
+	ExitError     = 1   // Anything not covered below
+	ExitConfig    = 2   // Bad usage, bad or missing configuration, expired or corrupt share link
+	ExitAuth      = 3   // Wrong share link or snapshot password, or API key rejected
+	ExitNetwork   = 4   // Provider unreachable, failing or rate limiting
+	ExitInternal  = 70  // Crash (EX_SOFTWARE from sysexits.h)
+	ExitUserAbort = 130 // Interrupted (Ctrl+C), as shells report SIGINT
+)
+
+// Classes for errors raised by the CLI itself rather than the api and share packages
+var (
+	// ErrUsage marks invalid flags or arguments
+	ErrUsage = errors.New("invalid usage")
+	// ErrConfig marks missing or invalid configuration
+	ErrConfig = errors.New("invalid configuration")
+)
+
+// class describes one kind of failure
+type class struct {
+	err      error
+	exitCode int
+	hint     string
+}
+
+// classes are checked in order; the first match wins
+var classes = []class{
+	{context.Canceled, ExitUserAbort, "Cancelled."},
+	{ErrUsage, ExitConfig, ""},
+	{ErrConfig, ExitConfig, ""},
+	{config.ErrInvalidConfig, ExitConfig, ""},
+	{share.ErrBadPassword, ExitAuth, "Wrong password for this share link (or the link was modified)."},
+	{share.ErrExpiredLink, ExitConfig, "This share link has expired. Ask the sender for a new one."},
+	{share.ErrCorruptLink, ExitConfig, "The share link looks truncated or corrupted. Copy the whole link, including everything after #gpt=."},
+	{snapshot.ErrBadPassword, ExitAuth, "Wrong password for this snapshot (or the file was modified)."},
+	{share.ErrNoPayload, ExitConfig, "That doesn't look like a hacka.re share link (expected ...#gpt=...)."},
+	{api.ErrUnauthorized, ExitAuth, "The provider rejected the API key. Check it with 'hacka.re' settings or --api-key."},
+	{api.ErrRateLimited, ExitNetwork, "The provider is rate limiting requests or the quota is used up. Wait a moment and retry."},
+	{api.ErrModelNotFound, ExitConfig, "The provider doesn't offer this model. Pick another with --model or in settings."},
+	{api.ErrOfflineViolation, ExitConfig, "Offline mode only allows local endpoints. Use a localhost base URL or drop --offline."},
+	{api.ErrProviderUnavailable, ExitNetwork, "Couldn't reach the provider. Check the base URL and your network connection."},
+	{context.DeadlineExceeded, ExitNetwork, "The request timed out."},
+}
+
+// lookup returns the class matching err, or nil
+func lookup(err error) *class {
+	for i := range classes {
+		if errors.Is(err, classes[i].err) {
+			return &classes[i]
+		}
+	}
+	return nil
+}
+
+// Message returns a user-facing description of err: a hint for known
+// failure classes, followed by the underlying error for details
+func Message(err error) string {
+	if err == nil {
+		return ""
+	}
+	if c := lookup(err); c != nil && c.hint != "" {
+		return fmt.Sprintf("%s\n  (%v)", c.hint, err)
+	}
+	return err.Error()
+}
+
+// ExitCode returns the process exit code for err (ExitOK for nil)
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if c := lookup(err); c != nil {
+		return c.exitCode
+	}
+	return ExitError
+}
+
+// classified tags an error with a failure class without changing its message
+type classified struct {
+	class error
+	err   error
+}
+
+func (c *classified) Error() string   { return c.err.Error() }
+func (c *classified) Unwrap() []error { return []error{c.class, c.err} }
+
+// Usage marks err as invalid command-line usage (exit code 2)
+func Usage(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classified{class: ErrUsage, err: err}
+}
+
+// Config marks err as a configuration problem (exit code 2)
+func Config(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classified{class: ErrConfig, err: err}
+}
@@ -0,0 +1,49 @@
+package failure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/share"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitOK},
+		{errors.New("boom"), ExitError},
+		{fmt.Errorf("failed to decrypt configuration: %w", share.ErrBadPassword), ExitAuth},
+		{share.ErrExpiredLink, ExitConfig},
+		{&api.StatusError{StatusCode: 401}, ExitAuth},
+		{&api.StatusError{StatusCode: 429}, ExitNetwork},
+		{&api.StatusError{StatusCode: 503}, ExitNetwork},
+		{&api.StatusError{StatusCode: 400}, ExitError},
+		{fmt.Errorf("request: %w", context.Canceled), ExitUserAbort},
+		{Usage(errors.New("unknown flag")), ExitConfig},
+		{Config(errors.New("API key is required")), ExitConfig},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	msg := Message(fmt.Errorf("failed to decrypt configuration: %w", share.ErrBadPassword))
+	if !strings.HasPrefix(msg, "Wrong password") || !strings.Contains(msg, "failed to decrypt") {
+		t.Errorf("unexpected message %q", msg)
+	}
+	if msg := Message(Config(errors.New("model is required"))); msg != "model is required" {
+		t.Errorf("unexpected message %q", msg)
+	}
+	if msg := Message(errors.New("boom")); msg != "boom" {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
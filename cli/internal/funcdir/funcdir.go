@@ -0,0 +1,243 @@
+// Package funcdir loads functions from a directory of JavaScript files, one
+// function per file, and keeps a registry in step with it while the files are
+// edited. A file that doesn't parse keeps its last good version registered and
+// is reported until it is fixed.
+package funcdir
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/jsruntime"
+	"github.com/hacka-re/cli/internal/share"
+)
+
+// PollInterval is how often a watched directory is checked for changes
+const PollInterval = 500 * time.Millisecond
+
+// Dir returns the functions directory. HACKARE_FUNCTIONS_DIR overrides it.
+func Dir() string {
+	if dir := os.Getenv("HACKARE_FUNCTIONS_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hacka.re-functions")
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "functions")
+}
+
+// FileError is a file that couldn't be loaded
+type FileError struct {
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// Change is what a scan did to the registry
+type Change struct {
+	Added   []string    `json:"added,omitempty"`
+	Updated []string    `json:"updated,omitempty"`
+	Removed []string    `json:"removed,omitempty"`
+	Errors  []FileError `json:"errors,omitempty"` // Files that changed but don't load
+}
+
+// Empty reports whether the scan found nothing new
+func (c Change) Empty() bool {
+	return len(c.Added)+len(c.Updated)+len(c.Removed)+len(c.Errors) == 0
+}
+
+// String summarizes the change, such as "updated greet; 1 error"
+func (c Change) String() string {
+	var parts []string
+	for _, list := range []struct {
+		verb  string
+		names []string
+	}{{"added", c.Added}, {"updated", c.Updated}, {"removed", c.Removed}} {
+		if len(list.names) > 0 {
+			parts = append(parts, list.verb+" "+strings.Join(list.names, ", "))
+		}
+	}
+	for _, e := range c.Errors {
+		parts = append(parts, fmt.Sprintf("%s: %s", e.File, e.Error))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// file is what was last seen of a file
+type file struct {
+	modTime  time.Time
+	size     int64
+	function *jsruntime.Function // Last version that loaded, nil if none did
+	err      string
+}
+
+// Watcher keeps a registry in step with a directory
+type Watcher struct {
+	dir      string
+	registry *jsruntime.Registry
+
+	mu    sync.Mutex
+	files map[string]*file // By file name
+}
+
+// NewWatcher returns a watcher that registers the functions of dir in registry.
+// Nothing is loaded until Scan or Run.
+func NewWatcher(dir string, registry *jsruntime.Registry) *Watcher {
+	return &Watcher{dir: dir, registry: registry, files: map[string]*file{}}
+}
+
+// Dir returns the watched directory
+func (w *Watcher) Dir() string {
+	return w.dir
+}
+
+// Scan loads the files that changed since the last scan and unregisters the
+// functions of removed files. A missing directory holds no functions.
+func (w *Watcher) Scan() (Change, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return Change{}, fmt.Errorf("failed to read functions directory: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var change Change
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".js") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed while listing; the next scan notices
+		}
+		seen[name] = true
+		f := w.files[name]
+		if f != nil && f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+			continue
+		}
+		if f == nil {
+			f = &file{}
+			w.files[name] = f
+		}
+		f.modTime, f.size = info.ModTime(), info.Size()
+		w.load(name, f, &change)
+	}
+
+	for name, f := range w.files {
+		if seen[name] {
+			continue
+		}
+		delete(w.files, name)
+		if f.function != nil && w.registry.Remove(f.function.Name) == nil {
+			change.Removed = append(change.Removed, f.function.Name)
+		}
+	}
+	sort.Strings(change.Added)
+	sort.Strings(change.Updated)
+	sort.Strings(change.Removed)
+	sort.Slice(change.Errors, func(i, j int) bool { return change.Errors[i].File < change.Errors[j].File })
+	return change, nil
+}
+
+// load registers the function of a changed file
+func (w *Watcher) load(name string, f *file, change *Change) {
+	fn, err := parseFile(filepath.Join(w.dir, name))
+	if err == nil {
+		err = w.registry.AddOrReplace(fn)
+	}
+	if err != nil {
+		f.err = err.Error()
+		change.Errors = append(change.Errors, FileError{File: name, Error: f.err})
+		return
+	}
+
+	f.err = ""
+	switch {
+	case f.function == nil:
+		change.Added = append(change.Added, fn.Name)
+	case f.function.Name != fn.Name:
+		// Renamed: the old name is no longer offered
+		w.registry.Remove(f.function.Name)
+		change.Removed = append(change.Removed, f.function.Name)
+		change.Added = append(change.Added, fn.Name)
+	default:
+		change.Updated = append(change.Updated, fn.Name)
+	}
+	f.function = fn
+}
+
+// parseFile reads a function from a file
+func parseFile(path string) (*jsruntime.Function, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fn, err := jsruntime.ParseFunction(string(code))
+	if err != nil {
+		return nil, err
+	}
+	if err := fn.Validate(); err != nil {
+		return nil, err
+	}
+	fn.IsCallable = true
+	return fn, nil
+}
+
+// Errors returns the files that currently fail to load
+func (w *Watcher) Errors() []FileError {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var errors []FileError
+	for name, f := range w.files {
+		if f.err != "" {
+			errors = append(errors, FileError{File: name, Error: f.err})
+		}
+	}
+	sort.Slice(errors, func(i, j int) bool { return errors[i].File < errors[j].File })
+	return errors
+}
+
+// Functions returns the loaded functions, sorted by name
+func (w *Watcher) Functions() []share.Function {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var functions []share.Function
+	for _, f := range w.files {
+		if f.function != nil {
+			functions = append(functions, share.Function{
+				Name:        f.function.Name,
+				Code:        f.function.Code,
+				Description: f.function.Description,
+				Enabled:     true,
+				Tags:        f.function.Tags,
+			})
+		}
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions
+}
+
+// Run scans every interval until ctx is done, calling onChange after each
+// scan that found something new
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, onChange func(Change)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if change, err := w.Scan(); err == nil && !change.Empty() {
+				onChange(change)
+			}
+		}
+	}
+}
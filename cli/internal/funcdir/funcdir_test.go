@@ -0,0 +1,105 @@
+package funcdir
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hacka-re/cli/internal/jsruntime"
+)
+
+// write writes a file and moves its modification time forward, so a rewrite
+// within the file system's time resolution is still noticed
+func write(t *testing.T, path, code string, age int) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(code), 0600); err != nil {
+		t.Fatal(err)
+	}
+	when := time.Now().Add(time.Duration(age) * time.Second)
+	if err := os.Chtimes(path, when, when); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	registry := jsruntime.NewRegistry()
+	w := NewWatcher(dir, registry)
+
+	write(t, filepath.Join(dir, "greet.js"), `function greet(name) { return "Hello, " + name; }`, 1)
+	write(t, filepath.Join(dir, "notes.txt"), "not a function", 1)
+	change, err := w.Scan()
+	if err != nil || strings.Join(change.Added, ",") != "greet" || !registry.HasFunction("greet") {
+		t.Fatalf("first scan = %+v, %v", change, err)
+	}
+	if change, _ := w.Scan(); !change.Empty() {
+		t.Errorf("unchanged directory reported %+v", change)
+	}
+
+	// A syntax error keeps the last good version
+	write(t, filepath.Join(dir, "greet.js"), `function greet(name) { return "Hi, " + ; }`, 2)
+	change, _ = w.Scan()
+	if len(change.Errors) != 1 || change.Errors[0].File != "greet.js" || len(w.Errors()) != 1 {
+		t.Errorf("broken file: change = %+v, errors = %+v", change, w.Errors())
+	}
+	if result, err := registry.Execute("greet", map[string]interface{}{"name": "Ada"}); err != nil || result != "Hello, Ada" {
+		t.Errorf("greet = %v, %v", result, err)
+	}
+
+	write(t, filepath.Join(dir, "greet.js"), `function greet(name) { return "Hi, " + name; }`, 3)
+	change, _ = w.Scan()
+	if strings.Join(change.Updated, ",") != "greet" || len(w.Errors()) != 0 {
+		t.Errorf("fixed file: change = %+v, errors = %+v", change, w.Errors())
+	}
+	if result, _ := registry.Execute("greet", map[string]interface{}{"name": "Ada"}); result != "Hi, Ada" {
+		t.Errorf("greet after the fix = %v", result)
+	}
+
+	// Renaming the function replaces the old name
+	write(t, filepath.Join(dir, "greet.js"), `function welcome(name) { return "Welcome, " + name; }`, 4)
+	change, _ = w.Scan()
+	if strings.Join(change.Removed, ",") != "greet" || strings.Join(change.Added, ",") != "welcome" || registry.HasFunction("greet") {
+		t.Errorf("renamed function: change = %+v", change)
+	}
+	if functions := w.Functions(); len(functions) != 1 || functions[0].Name != "welcome" || !functions[0].Enabled {
+		t.Errorf("functions = %+v", functions)
+	}
+
+	os.Remove(filepath.Join(dir, "greet.js"))
+	change, _ = w.Scan()
+	if strings.Join(change.Removed, ",") != "welcome" || registry.Size() != 0 {
+		t.Errorf("removed file: change = %+v, %d functions left", change, registry.Size())
+	}
+	if change.String() != "removed welcome" {
+		t.Errorf("String() = %q", change.String())
+	}
+}
+
+func TestMissingDir(t *testing.T) {
+	w := NewWatcher(filepath.Join(t.TempDir(), "none"), jsruntime.NewRegistry())
+	if change, err := w.Scan(); err != nil || !change.Empty() {
+		t.Errorf("Scan = %+v, %v", change, err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	w := NewWatcher(dir, jsruntime.NewRegistry())
+	changes := make(chan Change, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx, 10*time.Millisecond, func(c Change) { changes <- c })
+
+	write(t, filepath.Join(dir, "add.js"), `function add(a, b) { return a + b; }`, 1)
+	select {
+	case change := <-changes:
+		if strings.Join(change.Added, ",") != "add" {
+			t.Errorf("change = %+v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the new file wasn't noticed")
+	}
+}
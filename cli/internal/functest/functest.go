@@ -0,0 +1,238 @@
+// Package functest runs the test cases of custom functions, so a broken
+// function is caught before the model calls it. Cases are kept with the
+// function in the configuration, or written in its JSDoc as @test lines:
+//
+//	@test {"a": 2, "b": 3} => 5                 the result, as JSON
+//	@test {"text": "abc"} ~ /^[0-9a-f]{32}$/    a regular expression the result must match
+//	@test {"n": -1} throws                      the call must fail
+//	@test {"n": 1}                              the call must succeed
+package functest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/jsruntime"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/pkg/sharelink"
+)
+
+// CaseTimeout bounds each call
+const CaseTimeout = 10 * time.Second
+
+// Result is the outcome of one case
+type Result struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Got      string        `json:"got,omitempty"`     // The result or error of the call
+	Problem  string        `json:"problem,omitempty"` // Why the case failed
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the outcome of a function's cases
+type Report struct {
+	Function string   `json:"function"`
+	Enabled  bool     `json:"enabled"`
+	Passed   int      `json:"passed"`
+	Failed   int      `json:"failed"`
+	Error    string   `json:"error,omitempty"` // The function or its cases couldn't be loaded
+	Results  []Result `json:"results"`
+}
+
+// OK reports whether the function loaded and passed every case
+func (r Report) OK() bool {
+	return r.Error == "" && r.Failed == 0
+}
+
+// Status is a short summary, such as "3/3 passed"
+func (r Report) Status() string {
+	switch {
+	case r.Error != "":
+		return "error"
+	case len(r.Results) == 0:
+		return "no tests"
+	case r.Failed > 0:
+		return fmt.Sprintf("%d/%d failed", r.Failed, len(r.Results))
+	default:
+		return fmt.Sprintf("%d/%d passed", r.Passed, len(r.Results))
+	}
+}
+
+var (
+	jsdocBlock = regexp.MustCompile(`(?s)/\*\*(.*?)\*/`)
+	testLine   = regexp.MustCompile(`\*\s*@test\s+(.+)`) // The JSDoc line of a case
+)
+
+// Cases returns the cases of fn: those kept in the configuration, then those
+// in its JSDoc
+func Cases(fn share.Function) ([]sharelink.FunctionTest, error) {
+	cases := append([]sharelink.FunctionTest(nil), fn.Tests...)
+	jsdoc := jsdocBlock.FindStringSubmatch(fn.Code)
+	if jsdoc == nil {
+		return cases, nil
+	}
+	for _, match := range testLine.FindAllStringSubmatch(jsdoc[1], -1) {
+		c, err := ParseCase(match[1])
+		if err != nil {
+			return cases, fmt.Errorf("@test %s: %w", strings.TrimSpace(match[1]), err)
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// ParseCase parses the text of a @test line: the arguments as a JSON object,
+// optionally followed by "=> JSON", "~ /regexp/" or "throws"
+func ParseCase(text string) (sharelink.FunctionTest, error) {
+	text = strings.TrimSpace(text)
+	c := sharelink.FunctionTest{Name: text}
+	decoder := json.NewDecoder(strings.NewReader(text))
+	if err := decoder.Decode(&c.Args); err != nil || c.Args == nil {
+		return c, errors.New("the arguments must be a JSON object")
+	}
+	rest := strings.TrimSpace(text[decoder.InputOffset():])
+	switch {
+	case rest == "":
+	case rest == "throws":
+		c.Throws = true
+	case strings.HasPrefix(rest, "=>"):
+		expect := strings.TrimSpace(strings.TrimPrefix(rest, "=>"))
+		if err := json.Unmarshal([]byte(expect), &c.Expect); err != nil {
+			return c, fmt.Errorf("the expected result must be JSON: %w", err)
+		}
+	case strings.HasPrefix(rest, "~"):
+		pattern := strings.TrimSpace(strings.TrimPrefix(rest, "~"))
+		if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			pattern = pattern[1 : len(pattern)-1]
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return c, fmt.Errorf("invalid pattern: %w", err)
+		}
+		c.Match = pattern
+	default:
+		return c, fmt.Errorf("expected => JSON, ~ /regexp/ or throws after the arguments, not %q", rest)
+	}
+	return c, nil
+}
+
+// Run runs the cases of fn, also when it isn't enabled
+func Run(fn share.Function) Report {
+	report := Report{Function: fn.Name, Enabled: fn.Enabled, Results: []Result{}}
+	cases, err := Cases(fn)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	if len(cases) == 0 {
+		return report
+	}
+	// A syntax error fails the function, rather than passing its "throws" cases
+	parsed, err := jsruntime.ParseFunction(fn.Code)
+	if err == nil {
+		err = parsed.Validate()
+	}
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	if parsed.Timeout == 0 {
+		parsed.Timeout = CaseTimeout
+	}
+
+	for i, c := range cases {
+		result := runCase(parsed, c)
+		if result.Name == "" {
+			result.Name = fmt.Sprintf("case %d", i+1)
+		}
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// RunAll runs the cases of every function, in order
+func RunAll(functions []share.Function) []Report {
+	reports := make([]Report, len(functions))
+	for i, fn := range functions {
+		reports[i] = Run(fn)
+	}
+	return reports
+}
+
+func runCase(fn *jsruntime.Function, c sharelink.FunctionTest) Result {
+	result := Result{Name: c.Name}
+	start := time.Now()
+	value, err := fn.Execute(c.Args)
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		result.Got = err.Error()
+		if c.Throws {
+			result.Passed = true
+		} else {
+			result.Problem = "the call failed"
+		}
+		return result
+	}
+	result.Got = format(value)
+
+	switch {
+	case c.Throws:
+		result.Problem = "the call succeeded, but should have failed"
+	case c.Match != "":
+		pattern, err := regexp.Compile(c.Match)
+		if err != nil {
+			result.Problem = fmt.Sprintf("invalid pattern: %v", err)
+		} else if !pattern.MatchString(result.Got) {
+			result.Problem = fmt.Sprintf("does not match /%s/", c.Match)
+		}
+	case c.Expect != nil:
+		if !equalJSON(value, c.Expect) {
+			result.Problem = "want " + format(c.Expect)
+		}
+	}
+	result.Passed = result.Problem == ""
+	return result
+}
+
+// equalJSON compares two values by their JSON form, so 5 and 5.0 are equal
+func equalJSON(a, b interface{}) bool {
+	var x, y interface{}
+	for _, v := range []struct {
+		in  interface{}
+		out *interface{}
+	}{{a, &x}, {b, &y}} {
+		data, err := json.Marshal(v.in)
+		if err != nil || json.Unmarshal(data, v.out) != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// format renders a result: strings as they are, anything else as JSON
+func format(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	if value == nil {
+		return "undefined"
+	}
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(b.String())
+}
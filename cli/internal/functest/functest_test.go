@@ -0,0 +1,76 @@
+package functest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/pkg/sharelink"
+)
+
+const add = `/**
+ * Add two numbers
+ * @param {number} a - First
+ * @param {number} b - Second
+ * @test {"a": 2, "b": 3} => 5
+ * @test {"a": 0.5, "b": 0.25} => 0.75
+ * @test {"a": 1, "b": "x"} throws
+ */
+function add(a, b) {
+    if (typeof b !== "number") throw new Error("b is not a number");
+    return a + b;
+}`
+
+func TestRun(t *testing.T) {
+	fn := share.Function{Name: "add", Code: add, Tests: []sharelink.FunctionTest{
+		{Name: "object", Args: map[string]interface{}{"a": 1, "b": 1}, Match: `^2$`},
+		{Args: map[string]interface{}{"a": 1, "b": 1}, Expect: 3},
+	}}
+	report := Run(fn)
+	if report.Error != "" || report.Passed != 4 || report.Failed != 1 || report.OK() {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.Status() != "1/5 failed" {
+		t.Errorf("status = %q", report.Status())
+	}
+	failed := report.Results[1]
+	if failed.Name != "case 2" || failed.Got != "2" || failed.Problem != "want 3" {
+		t.Errorf("failed case = %+v", failed)
+	}
+	if report.Results[2].Name != `{"a": 2, "b": 3} => 5` {
+		t.Errorf("JSDoc cases follow the configured ones, got %q", report.Results[2].Name)
+	}
+}
+
+func TestRunWithoutCases(t *testing.T) {
+	report := Run(share.Function{Name: "add", Code: "function add(a, b) { return a + b; }"})
+	if !report.OK() || report.Status() != "no tests" {
+		t.Errorf("report = %+v", report)
+	}
+
+	broken := strings.Replace(add, "return a + b;", "return a +;", 1)
+	if report := Run(share.Function{Name: "add", Code: broken}); report.OK() || !strings.Contains(report.Error, "SyntaxError") {
+		t.Errorf("broken function report = %+v", report)
+	}
+}
+
+func TestParseCase(t *testing.T) {
+	c, err := ParseCase(`{"text": "abc"} ~ /^[0-9a-f]+$/`)
+	if err != nil || c.Match != "^[0-9a-f]+$" || c.Args["text"] != "abc" {
+		t.Errorf("ParseCase = %+v, %v", c, err)
+	}
+	c, err = ParseCase(`{"n": 1} => {"ok": true}`)
+	if err != nil || c.Expect.(map[string]interface{})["ok"] != true {
+		t.Errorf("ParseCase = %+v, %v", c, err)
+	}
+	for _, bad := range []string{`[1, 2] => 3`, `{"n": 1} == 3`, `{"n": 1} => nope`, `{"n": 1} ~ /(/`, `{"n":`} {
+		if _, err := ParseCase(bad); err == nil {
+			t.Errorf("%s was accepted", bad)
+		}
+	}
+
+	code := strings.Replace(add, "@test {\"a\": 2, \"b\": 3} => 5", "@test {\"a\": 2} >= 5", 1)
+	if report := Run(share.Function{Name: "add", Code: code}); report.Error == "" || report.Status() != "error" {
+		t.Errorf("report = %+v", report)
+	}
+}
@@ -0,0 +1,154 @@
+// Package handoff prepares a conversation for another provider or model. It
+// finds what only the previous provider produced or accepted, strips it on
+// request, and edits the history with find-and-replace, so what the new model
+// is sent has been reviewed first.
+package handoff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// Kinds of provider-specific artifacts
+const (
+	KindToolCalls = "tool-calls" // Tool calls and results, whose IDs and format vary by provider
+	KindReasoning = "reasoning"  // <think> blocks that reasoning models put in their replies
+	KindSystem    = "system"     // System messages after the first, which some providers reject
+)
+
+// reasoningPattern matches a reasoning block and the space after it
+var reasoningPattern = regexp.MustCompile(`(?s)<(think|thinking|reasoning)>.*?</(think|thinking|reasoning)>\s*`)
+
+// Artifact is one kind of provider-specific content and where it occurs
+type Artifact struct {
+	Kind     string
+	Messages []int // Indexes of the messages holding it
+}
+
+// Describe says what the artifact is and what stripping it does
+func (a Artifact) Describe() string {
+	switch a.Kind {
+	case KindToolCalls:
+		return fmt.Sprintf("Tool calls and results in %d message(s); stripping turns them into plain text", len(a.Messages))
+	case KindReasoning:
+		return fmt.Sprintf("Reasoning blocks in %d reply(s); stripping removes them", len(a.Messages))
+	case KindSystem:
+		return fmt.Sprintf("%d system message(s) after the first; stripping merges them into the first", len(a.Messages))
+	}
+	return a.Kind
+}
+
+// Detect lists the artifacts found in messages, in the order of the kinds above
+func Detect(messages []api.Message) []Artifact {
+	found := map[string][]int{}
+	firstSystem := -1
+	for i, msg := range messages {
+		if len(msg.ToolCalls) > 0 || msg.Role == "tool" || msg.ToolCallID != "" {
+			found[KindToolCalls] = append(found[KindToolCalls], i)
+		}
+		if msg.Role == "assistant" && reasoningPattern.MatchString(msg.Content) {
+			found[KindReasoning] = append(found[KindReasoning], i)
+		}
+		if msg.Role == "system" {
+			if firstSystem >= 0 {
+				found[KindSystem] = append(found[KindSystem], i)
+			} else {
+				firstSystem = i
+			}
+		}
+	}
+
+	var artifacts []Artifact
+	for _, kind := range []string{KindToolCalls, KindReasoning, KindSystem} {
+		if len(found[kind]) > 0 {
+			artifacts = append(artifacts, Artifact{Kind: kind, Messages: found[kind]})
+		}
+	}
+	return artifacts
+}
+
+// Strip returns a copy of messages without the given kinds of artifacts.
+// Tool calls become a note in the assistant's reply and tool results become
+// user messages, so the turns still alternate.
+func Strip(messages []api.Message, kinds ...string) []api.Message {
+	strip := map[string]bool{}
+	for _, kind := range kinds {
+		strip[kind] = true
+	}
+
+	toolNames := map[string]string{} // By call ID
+	var stripped []api.Message
+	firstSystem := -1
+	for _, msg := range messages {
+		if strip[KindToolCalls] {
+			msg = plainToolMessage(msg, toolNames)
+		}
+		if strip[KindReasoning] && msg.Role == "assistant" {
+			msg.Content = strings.TrimSpace(reasoningPattern.ReplaceAllString(msg.Content, ""))
+		}
+		if msg.Role == "system" {
+			if firstSystem < 0 {
+				firstSystem = len(stripped)
+			} else if strip[KindSystem] {
+				stripped[firstSystem].Content = strings.TrimSpace(stripped[firstSystem].Content + "\n\n" + msg.Content)
+				continue
+			}
+		}
+		stripped = append(stripped, msg)
+	}
+	return stripped
+}
+
+// plainToolMessage writes a tool call or result as text, remembering the
+// names of calls so their results can be labelled
+func plainToolMessage(msg api.Message, toolNames map[string]string) api.Message {
+	if len(msg.ToolCalls) > 0 {
+		var notes []string
+		for _, call := range msg.ToolCalls {
+			toolNames[call.ID] = call.Function.Name
+			notes = append(notes, fmt.Sprintf("[Called %s with %s]", call.Function.Name, call.Function.Arguments))
+		}
+		msg.Content = strings.TrimSpace(msg.Content + "\n" + strings.Join(notes, "\n"))
+		msg.ToolCalls = nil
+	}
+	if msg.Role == "tool" {
+		name := toolNames[msg.ToolCallID]
+		if name == "" {
+			name = "a tool"
+		}
+		msg.Role = "user"
+		msg.Content = fmt.Sprintf("[Result of %s]\n%s", name, msg.Content)
+	}
+	msg.ToolCallID = ""
+	return msg
+}
+
+// Replace returns a copy of messages with every occurrence of find replaced,
+// in contents and tool call arguments, and how many were replaced
+func Replace(messages []api.Message, find, replacement string) ([]api.Message, int) {
+	edited := make([]api.Message, len(messages))
+	copy(edited, messages)
+	if find == "" {
+		return edited, 0
+	}
+
+	count := 0
+	for i := range edited {
+		count += strings.Count(edited[i].Content, find)
+		edited[i].Content = strings.ReplaceAll(edited[i].Content, find, replacement)
+		if len(edited[i].ToolCalls) == 0 {
+			continue
+		}
+		calls := make([]api.ToolCall, len(edited[i].ToolCalls))
+		copy(calls, edited[i].ToolCalls)
+		for j := range calls {
+			count += strings.Count(calls[j].Function.Arguments, find)
+			calls[j].Function.Arguments = strings.ReplaceAll(calls[j].Function.Arguments, find, replacement)
+		}
+		edited[i].ToolCalls = calls
+	}
+	return edited, count
+}
@@ -0,0 +1,84 @@
+package handoff
+
+import (
+	"testing"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+func conversation() []api.Message {
+	call := api.ToolCall{ID: "call_1", Type: "function"}
+	call.Function.Name = "lookup"
+	call.Function.Arguments = `{"ip":"203.0.113.7"}`
+	return []api.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "Check 203.0.113.7"},
+		{Role: "assistant", ToolCalls: []api.ToolCall{call}},
+		{Role: "tool", ToolCallID: "call_1", Content: `{"open":[22]}`},
+		{Role: "assistant", Content: "<think>Port 22 is SSH.</think>\n\nOnly SSH is open on 203.0.113.7."},
+		{Role: "system", Content: "Answer in Swedish."},
+	}
+}
+
+func TestDetect(t *testing.T) {
+	artifacts := Detect(conversation())
+	if len(artifacts) != 3 {
+		t.Fatalf("artifacts = %+v", artifacts)
+	}
+	for i, want := range []struct {
+		kind     string
+		messages int
+	}{{KindToolCalls, 2}, {KindReasoning, 1}, {KindSystem, 1}} {
+		if artifacts[i].Kind != want.kind || len(artifacts[i].Messages) != want.messages {
+			t.Errorf("artifact %d = %+v, want %s in %d message(s)", i, artifacts[i], want.kind, want.messages)
+		}
+	}
+	if artifacts := Detect([]api.Message{{Role: "system"}, {Role: "user", Content: "hi"}}); len(artifacts) != 0 {
+		t.Errorf("plain conversation has %+v", artifacts)
+	}
+}
+
+func TestStrip(t *testing.T) {
+	messages := conversation()
+	stripped := Strip(messages, KindToolCalls, KindReasoning, KindSystem)
+	if len(Detect(stripped)) != 0 {
+		t.Errorf("left after stripping: %+v", Detect(stripped))
+	}
+	if len(stripped) != 5 || stripped[0].Content != "Be brief.\n\nAnswer in Swedish." {
+		t.Fatalf("stripped = %+v", stripped)
+	}
+	if got := stripped[2].Content; got != `[Called lookup with {"ip":"203.0.113.7"}]` {
+		t.Errorf("tool call = %q", got)
+	}
+	if got := stripped[3]; got.Role != "user" || got.Content != "[Result of lookup]\n{\"open\":[22]}" {
+		t.Errorf("tool result = %+v", got)
+	}
+	if got := stripped[4].Content; got != "Only SSH is open on 203.0.113.7." {
+		t.Errorf("reply = %q", got)
+	}
+	if len(messages[2].ToolCalls) != 1 || messages[3].Role != "tool" {
+		t.Error("Strip changed its input")
+	}
+
+	// Only the kinds asked for are stripped
+	if onlyReasoning := Strip(messages, KindReasoning); len(onlyReasoning) != 6 || onlyReasoning[3].Role != "tool" {
+		t.Errorf("reasoning only = %+v", onlyReasoning)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	messages := conversation()
+	edited, count := Replace(messages, "203.0.113.7", "the host")
+	if count != 3 {
+		t.Errorf("count = %d, want 3", count)
+	}
+	if edited[1].Content != "Check the host" || edited[2].ToolCalls[0].Function.Arguments != `{"ip":"the host"}` {
+		t.Errorf("edited = %+v", edited[:3])
+	}
+	if messages[2].ToolCalls[0].Function.Arguments != `{"ip":"203.0.113.7"}` {
+		t.Error("Replace changed its input")
+	}
+	if _, count := Replace(messages, "", "x"); count != 0 {
+		t.Errorf("empty search replaced %d", count)
+	}
+}
@@ -0,0 +1,149 @@
+// Package hardware probes the CPU, memory and GPUs of the machine and
+// recommends local models and quantizations that fit them.
+package hardware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GB is the unit memory sizes are shown in
+const GB = 1 << 30
+
+// Info describes the machine
+type Info struct {
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	CPUCores int    `json:"cpuCores"`
+	RAMBytes uint64 `json:"ramBytes"` // 0 when it could not be detected
+	GPUs     []GPU  `json:"gpus,omitempty"`
+}
+
+// GPU is a graphics processor usable for model offloading
+type GPU struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`      // nvidia, apple
+	VRAMBytes uint64 `json:"vramBytes"` // For Apple Silicon, the share of unified memory Metal may use
+}
+
+// VRAMBytes returns the memory of the largest GPU, or 0 without one
+func (i Info) VRAMBytes() uint64 {
+	var largest uint64
+	for _, gpu := range i.GPUs {
+		if gpu.VRAMBytes > largest {
+			largest = gpu.VRAMBytes
+		}
+	}
+	return largest
+}
+
+// Summary describes the hardware on one line
+func (i Info) Summary() string {
+	summary := fmt.Sprintf("%d CPU cores, %s RAM", i.CPUCores, formatGB(i.RAMBytes))
+	for _, gpu := range i.GPUs {
+		summary += fmt.Sprintf(", %s (%s)", gpu.Name, formatGB(gpu.VRAMBytes))
+	}
+	return summary
+}
+
+// Probes are variables so tests can replace them
+var (
+	meminfoPath = "/proc/meminfo"
+	runCommand  = func(name string, args ...string) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return exec.CommandContext(ctx, name, args...).Output()
+	}
+	goos   = runtime.GOOS
+	goarch = runtime.GOARCH
+)
+
+// Detect probes the machine. Anything it cannot find out is left empty.
+func Detect() Info {
+	info := Info{
+		OS:       goos,
+		Arch:     goarch,
+		CPUCores: runtime.NumCPU(),
+		RAMBytes: detectRAM(),
+	}
+	info.GPUs = append(info.GPUs, detectNvidia()...)
+
+	// Apple Silicon shares memory with the GPU; Metal may use about three quarters of it
+	if goos == "darwin" && goarch == "arm64" && info.RAMBytes > 0 {
+		info.GPUs = append(info.GPUs, GPU{Name: "Apple Silicon", Kind: "apple", VRAMBytes: info.RAMBytes / 4 * 3})
+	}
+	return info
+}
+
+// detectRAM reads the total memory from /proc/meminfo on Linux and sysctl on macOS
+func detectRAM() uint64 {
+	switch goos {
+	case "linux":
+		file, err := os.Open(meminfoPath)
+		if err != nil {
+			return 0
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "MemTotal:" {
+				kb, err := strconv.ParseUint(fields[1], 10, 64)
+				if err != nil {
+					return 0
+				}
+				return kb * 1024
+			}
+		}
+	case "darwin":
+		out, err := runCommand("sysctl", "-n", "hw.memsize")
+		if err != nil {
+			return 0
+		}
+		size, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return size
+	}
+	return 0
+}
+
+// detectNvidia asks nvidia-smi for the GPUs and their memory
+func detectNvidia() []GPU {
+	out, err := runCommand("nvidia-smi", "--query-gpu=name,memory.total", "--format=csv,noheader,nounits")
+	if err != nil {
+		return nil
+	}
+
+	var gpus []GPU
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		name, mib, ok := strings.Cut(scanner.Text(), ",")
+		if !ok {
+			continue
+		}
+		size, err := strconv.ParseUint(strings.TrimSpace(mib), 10, 64)
+		if err != nil {
+			continue
+		}
+		gpus = append(gpus, GPU{Name: strings.TrimSpace(name), Kind: "nvidia", VRAMBytes: size << 20})
+	}
+	return gpus
+}
+
+// formatGB renders a byte count as gigabytes
+func formatGB(size uint64) string {
+	if size == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%.0f GB", float64(size)/GB)
+}
@@ -0,0 +1,55 @@
+package hardware
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectLinux(t *testing.T) {
+	meminfo := filepath.Join(t.TempDir(), "meminfo")
+	os.WriteFile(meminfo, []byte("MemTotal:       32768000 kB\nMemFree:         1000 kB\n"), 0644)
+
+	oldPath, oldRun, oldOS := meminfoPath, runCommand, goos
+	defer func() { meminfoPath, runCommand, goos = oldPath, oldRun, oldOS }()
+	meminfoPath = meminfo
+	goos = "linux"
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		if name == "nvidia-smi" {
+			return []byte("NVIDIA GeForce RTX 4090, 24564\n"), nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	info := Detect()
+	if info.RAMBytes != 32768000*1024 {
+		t.Errorf("expected RAM from meminfo, got %d", info.RAMBytes)
+	}
+	if len(info.GPUs) != 1 || info.GPUs[0].Name != "NVIDIA GeForce RTX 4090" || info.VRAMBytes() != 24564<<20 {
+		t.Errorf("unexpected GPUs: %+v", info.GPUs)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	gpu := Recommend(Info{RAMBytes: 64 * GB, GPUs: []GPU{{Name: "RTX 4090", VRAMBytes: 24 * GB}}})
+	if len(gpu) == 0 || !gpu[0].GPU || gpu[0].Model.Name != "Qwen3 32B" {
+		t.Errorf("expected a 32B model offloaded to a 24 GB GPU first, got %v", gpu)
+	}
+
+	cpu := Recommend(Info{RAMBytes: 64 * GB})
+	for _, rec := range cpu {
+		if rec.GPU || rec.Model.Params > maxCPUParams {
+			t.Errorf("expected only CPU-sized models without a GPU, got %v", rec)
+		}
+	}
+
+	small := Recommend(Info{RAMBytes: 4 * GB})
+	if len(small) == 0 || !strings.HasPrefix(small[0].Model.Name, "Llama 3.2") {
+		t.Errorf("expected a small model for 4 GB, got %v", small)
+	}
+	if none := Recommend(Info{}); len(none) != 0 {
+		t.Errorf("expected no recommendations without memory information, got %v", none)
+	}
+}
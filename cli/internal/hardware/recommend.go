@@ -0,0 +1,95 @@
+package hardware
+
+import "fmt"
+
+// LocalModel is a model that runs well in the local runtimes hacka.re supports
+type LocalModel struct {
+	Name      string
+	Params    float64 // Billions of parameters
+	OllamaTag string
+}
+
+// Quantization is a weight format and its average size per parameter
+type Quantization struct {
+	Name          string
+	BitsPerWeight float64
+}
+
+// LocalModels are the candidates for recommendations, smallest first
+var LocalModels = []LocalModel{
+	{Name: "Llama 3.2 1B", Params: 1.2, OllamaTag: "llama3.2:1b"},
+	{Name: "Llama 3.2 3B", Params: 3.2, OllamaTag: "llama3.2:3b"},
+	{Name: "Qwen3 4B", Params: 4.0, OllamaTag: "qwen3:4b"},
+	{Name: "Llama 3.1 8B", Params: 8.0, OllamaTag: "llama3.1:8b"},
+	{Name: "Gemma 3 12B", Params: 12.0, OllamaTag: "gemma3:12b"},
+	{Name: "Qwen3 14B", Params: 14.8, OllamaTag: "qwen3:14b"},
+	{Name: "Qwen3 32B", Params: 32.8, OllamaTag: "qwen3:32b"},
+	{Name: "Llama 3.3 70B", Params: 70.6, OllamaTag: "llama3.3:70b"},
+}
+
+// Quantizations from best quality to smallest
+var Quantizations = []Quantization{
+	{Name: "Q8_0", BitsPerWeight: 8.5},
+	{Name: "Q5_K_M", BitsPerWeight: 5.7},
+	{Name: "Q4_K_M", BitsPerWeight: 4.8},
+}
+
+// Recommendation is a model and quantization that fits the machine
+type Recommendation struct {
+	Model        LocalModel
+	Quantization string
+	SizeBytes    uint64 // Memory needed to run it, including context
+	GPU          bool   // Fits in GPU memory, so offload all layers
+}
+
+// String describes the recommendation on one line
+func (r Recommendation) String() string {
+	where := "CPU"
+	if r.GPU {
+		where = "GPU"
+	}
+	return fmt.Sprintf("%s %s (~%.1f GB, %s) - ollama pull %s", r.Model.Name, r.Quantization, float64(r.SizeBytes)/GB, where, r.Model.OllamaTag)
+}
+
+// maxCPUParams is the largest model (in billions of parameters) recommended
+// without a GPU; bigger ones fit in RAM but answer too slowly to be useful
+const maxCPUParams = 15
+
+// overhead covers the context cache and runtime buffers on top of the weights
+const overhead = 1.2
+
+// memoryNeeded estimates the memory a model takes at a quantization
+func memoryNeeded(model LocalModel, quant Quantization) uint64 {
+	return uint64(model.Params * 1e9 * quant.BitsPerWeight / 8 * overhead)
+}
+
+// Recommend returns up to three models that fit the machine, largest first.
+// Each gets the best quantization that fits: in GPU memory if there is a GPU,
+// otherwise in about 60% of RAM so the rest of the system keeps running.
+func Recommend(info Info) []Recommendation {
+	gpuBudget := info.VRAMBytes()
+	ramBudget := info.RAMBytes / 10 * 6
+
+	var recommendations []Recommendation
+	for i := len(LocalModels) - 1; i >= 0 && len(recommendations) < 3; i-- {
+		model := LocalModels[i]
+		if rec, ok := fit(model, gpuBudget, true); ok {
+			recommendations = append(recommendations, rec)
+		} else if model.Params > maxCPUParams {
+			continue
+		} else if rec, ok := fit(model, ramBudget, false); ok {
+			recommendations = append(recommendations, rec)
+		}
+	}
+	return recommendations
+}
+
+// fit picks the best quantization of model within budget
+func fit(model LocalModel, budget uint64, gpu bool) (Recommendation, bool) {
+	for _, quant := range Quantizations {
+		if size := memoryNeeded(model, quant); size <= budget {
+			return Recommendation{Model: model, Quantization: quant.Name, SizeBytes: size, GPU: gpu}, true
+		}
+	}
+	return Recommendation{}, false
+}
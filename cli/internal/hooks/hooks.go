@@ -0,0 +1,272 @@
+// Package hooks runs the user's own scripts on chat messages: before a
+// message is sent, to change or stop it, and after a reply arrives, to change
+// it or pass it on, e.g. to a notes app. Hooks are listed in a file:
+//
+//	{
+//	  "beforeSend": [
+//	    {"run": "~/bin/expand-ticket-ids"},
+//	    {"js": "no-secrets.js"}
+//	  ],
+//	  "afterReceive": [
+//	    {"run": "cat >> ~/notes/chat.md", "timeoutSeconds": 5}
+//	  ]
+//	}
+//
+// A "run" hook is a shell command, run with sh -c, or cmd /C on Windows. It
+// gets the message on stdin, and HACKARE_HOOK (the event) and HACKARE_MODEL
+// in its environment. What it prints replaces the message; printing nothing
+// keeps it. A beforeSend command that exits non-zero stops the message, with
+// its stderr as the reason.
+//
+// A "js" hook is a JavaScript file, relative to the hooks file, that defines
+// hook(text, info), where info has event and model. Returning a string
+// replaces the message and returning nothing keeps it. Throwing in a
+// beforeSend hook stops the message.
+//
+// Hooks run in order, each on the text the one before it returned. A failing
+// afterReceive hook is skipped, so the reply is never lost.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/jsruntime"
+)
+
+// Events
+const (
+	BeforeSend   = "beforeSend"
+	AfterReceive = "afterReceive"
+)
+
+// DefaultTimeout bounds a hook without timeoutSeconds
+const DefaultTimeout = 10 * time.Second
+
+// Hook is one script; exactly one of Run and JS is set
+type Hook struct {
+	Run            string `json:"run,omitempty"` // Shell command
+	JS             string `json:"js,omitempty"`  // JavaScript file defining hook(text, info)
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty"`
+
+	code string // Contents of the JS file
+}
+
+// Name identifies the hook in messages: its command or file
+func (h Hook) Name() string {
+	if h.Run != "" {
+		return h.Run
+	}
+	return h.JS
+}
+
+// Timeout returns how long the hook may run
+func (h Hook) Timeout() time.Duration {
+	if h.TimeoutSeconds > 0 {
+		return time.Duration(h.TimeoutSeconds) * time.Second
+	}
+	return DefaultTimeout
+}
+
+// Hooks is the contents of the hooks file. The methods of a nil *Hooks
+// leave messages as they are.
+type Hooks struct {
+	BeforeSend   []Hook `json:"beforeSend,omitempty"`
+	AfterReceive []Hook `json:"afterReceive,omitempty"`
+}
+
+// AbortError is returned when a beforeSend hook stops a message
+type AbortError struct {
+	Hook   string
+	Reason string
+}
+
+func (e *AbortError) Error() string {
+	return fmt.Sprintf("stopped by hook %s: %s", e.Hook, e.Reason)
+}
+
+// Path returns the hooks file. HACKARE_HOOKS overrides it.
+func Path() string {
+	if path := os.Getenv("HACKARE_HOOKS"); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-hooks.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "hooks.json")
+}
+
+// Load reads a hooks file and the JS files it names; without a file there
+// are no hooks and it returns nil
+func Load(path string) (*Hooks, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks: %w", err)
+	}
+	var h Hooks
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks %s: %w", path, err)
+	}
+	for _, list := range [][]Hook{h.BeforeSend, h.AfterReceive} {
+		for i := range list {
+			if err := list[i].load(filepath.Dir(path)); err != nil {
+				return nil, fmt.Errorf("hooks %s: %w", path, err)
+			}
+		}
+	}
+	if h.Empty() {
+		return nil, nil
+	}
+	return &h, nil
+}
+
+// load checks the hook and reads its JS file, relative to dir
+func (h *Hook) load(dir string) error {
+	if (h.Run == "") == (h.JS == "") {
+		return errors.New(`each hook needs either "run" or "js"`)
+	}
+	if h.TimeoutSeconds < 0 {
+		return fmt.Errorf("hook %s: negative timeout", h.Name())
+	}
+	if h.JS == "" {
+		return nil
+	}
+	path := h.JS
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	} else if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read hook %s: %w", h.JS, err)
+	}
+	h.code = string(code)
+	return nil
+}
+
+// Empty reports whether there are no hooks
+func (h *Hooks) Empty() bool {
+	return h == nil || len(h.BeforeSend) == 0 && len(h.AfterReceive) == 0
+}
+
+// Send runs the beforeSend hooks on a message about to go to model and
+// returns the text to send. An *AbortError means the message must not be sent.
+func (h *Hooks) Send(ctx context.Context, text, model string) (string, error) {
+	if h == nil {
+		return text, nil
+	}
+	for _, hook := range h.BeforeSend {
+		changed, err := hook.apply(ctx, BeforeSend, text, model)
+		if err != nil {
+			return "", &AbortError{Hook: hook.Name(), Reason: err.Error()}
+		}
+		text = changed
+	}
+	return text, nil
+}
+
+// Receive runs the afterReceive hooks on a reply from model and returns the
+// text to keep. Hooks that fail are skipped and reported in the error, which
+// comes with the text as far as the others got.
+func (h *Hooks) Receive(ctx context.Context, text, model string) (string, error) {
+	if h == nil {
+		return text, nil
+	}
+	var errs []error
+	for _, hook := range h.AfterReceive {
+		changed, err := hook.apply(ctx, AfterReceive, text, model)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hook %s: %w", hook.Name(), err))
+			continue
+		}
+		text = changed
+	}
+	return text, errors.Join(errs...)
+}
+
+// apply runs the hook on text and returns its replacement, or text when the
+// hook returned nothing
+func (h Hook) apply(ctx context.Context, event, text, model string) (string, error) {
+	var out string
+	var err error
+	if h.Run != "" {
+		out, err = h.runShell(ctx, event, text, model)
+	} else {
+		out, err = h.runJS(event, text, model)
+	}
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(out) == "" {
+		return text, nil
+	}
+	return out, nil
+}
+
+// runShell runs the command with text on stdin and returns its output
+func (h Hook) runShell(ctx context.Context, event, text, model string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout())
+	defer cancel()
+
+	cmd := shellCommand(ctx, h.Run)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Env = append(os.Environ(), "HACKARE_HOOK="+event, "HACKARE_MODEL="+model)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.WaitDelay = time.Second // Don't wait on children that keep the output open
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("timed out after %v", h.Timeout())
+		}
+		if reason := strings.TrimSpace(stderr.String()); reason != "" {
+			return "", errors.New(reason)
+		}
+		return "", err
+	}
+	// Commands end their output with a newline that isn't part of the message
+	out := strings.TrimSuffix(stdout.String(), "\n")
+	return strings.TrimSuffix(out, "\r"), nil
+}
+
+// shellCommand runs command with the platform's shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// runJS calls hook(text, info) in the JS file and returns the string it returned
+func (h Hook) runJS(event, text, model string) (string, error) {
+	engine := jsruntime.NewEngine()
+	engine.SetTimeout(h.Timeout())
+	info := map[string]interface{}{"event": event, "model": model}
+	result, err := engine.ExecuteFunctionArgs(h.code, "hook", []interface{}{text, info})
+	if err != nil {
+		return "", err
+	}
+	switch result := result.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return result, nil
+	default:
+		return "", fmt.Errorf("hook returned %T, want a string or nothing", result)
+	}
+}
@@ -0,0 +1,119 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeHooks writes a hooks file and any JS files into a temporary directory
+// and loads it
+func writeHooks(t *testing.T, config string, files map[string]string) *Hooks {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "hooks.json")
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	h, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestLoad(t *testing.T) {
+	h, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || h != nil {
+		t.Errorf("Load(missing) = %v, %v, want no hooks", h, err)
+	}
+	if text, err := h.Send(context.Background(), "hi", "m"); text != "hi" || err != nil {
+		t.Errorf("nil hooks changed the message: %q, %v", text, err)
+	}
+
+	dir := t.TempDir()
+	for _, bad := range []string{
+		`{"beforeSend": [{}]}`,
+		`{"beforeSend": [{"run": "cat", "js": "a.js"}]}`,
+		`{"afterReceive": [{"js": "missing.js"}]}`,
+		`{"afterReceive": [{"run": "cat", "timeoutSeconds": -1}]}`,
+		`not json`,
+	} {
+		path := filepath.Join(dir, "hooks.json")
+		os.WriteFile(path, []byte(bad), 0600)
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load(%s) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	h := writeHooks(t, `{"beforeSend": [
+		{"run": "tr a-z A-Z"},
+		{"js": "suffix.js"},
+		{"run": "cat > /dev/null"}
+	]}`, map[string]string{
+		"suffix.js": `function hook(text, info) { return text + " (" + info.event + ", " + info.model + ")"; }`,
+	})
+	text, err := h.Send(context.Background(), "hello", "gpt-4o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "HELLO (beforeSend, gpt-4o)" {
+		t.Errorf("Send() = %q", text)
+	}
+}
+
+func TestSendAbort(t *testing.T) {
+	h := writeHooks(t, `{"beforeSend": [{"run": "grep -q secret && { echo 'looks like a secret' >&2; exit 1; }; exit 0"}]}`, nil)
+	if text, err := h.Send(context.Background(), "nothing to see", "m"); err != nil || text != "nothing to see" {
+		t.Errorf("Send() = %q, %v, want the message unchanged", text, err)
+	}
+	_, err := h.Send(context.Background(), "my secret is 42", "m")
+	var abort *AbortError
+	if !errors.As(err, &abort) || abort.Reason != "looks like a secret" {
+		t.Errorf("Send() error = %v, want an abort with the hook's reason", err)
+	}
+
+	h = writeHooks(t, `{"beforeSend": [{"js": "stop.js"}]}`, map[string]string{
+		"stop.js": `function hook(text) { if (text.indexOf("AKIA") >= 0) throw new Error("AWS key"); }`,
+	})
+	if _, err := h.Send(context.Background(), "key AKIA123", "m"); !errors.As(err, &abort) || !strings.Contains(abort.Reason, "AWS key") {
+		t.Errorf("Send() error = %v, want an abort from the thrown error", err)
+	}
+
+	h = writeHooks(t, `{"beforeSend": [{"run": "sleep 5", "timeoutSeconds": 1}]}`, nil)
+	if _, err := h.Send(context.Background(), "hi", "m"); !errors.As(err, &abort) || !strings.Contains(abort.Reason, "timed out") {
+		t.Errorf("Send() error = %v, want a timeout", err)
+	}
+}
+
+func TestReceive(t *testing.T) {
+	notes := filepath.Join(t.TempDir(), "notes.md")
+	h := writeHooks(t, `{"afterReceive": [
+		{"run": "exit 3"},
+		{"run": "sed 's/colour/color/'"},
+		{"run": "cat >> `+notes+`"},
+		{"js": "bad.js"}
+	]}`, map[string]string{
+		"bad.js": `function hook(text) { return 42; }`,
+	})
+	text, err := h.Receive(context.Background(), "the colour red", "m")
+	if text != "the color red" {
+		t.Errorf("Receive() = %q, want the working hooks applied", text)
+	}
+	if err == nil || !strings.Contains(err.Error(), "exit 3") || !strings.Contains(err.Error(), "bad.js") {
+		t.Errorf("Receive() error = %v, want both failing hooks reported", err)
+	}
+	if data, _ := os.ReadFile(notes); string(data) != "the color red" {
+		t.Errorf("notes = %q", data)
+	}
+}
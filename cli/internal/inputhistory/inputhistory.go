@@ -0,0 +1,170 @@
+// Package inputhistory keeps what was typed into the terminal chat across
+// runs, per namespace, like a shell history: the latest use of a line is kept
+// and older duplicates are dropped, up to a maximum number of lines.
+package inputhistory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hacka-re/cli/internal/memory"
+)
+
+// DefaultMaxSize is the number of lines kept per namespace when none is configured
+const DefaultMaxSize = 1000
+
+// Store is the history file
+type Store struct {
+	path string
+	max  int
+	mu   sync.Mutex
+}
+
+// historyFile is the content of the file
+type historyFile struct {
+	Namespaces map[string][]string `json:"namespaces"`
+}
+
+// NewStore returns a store at path keeping up to max lines per namespace;
+// max 0 uses DefaultMaxSize
+func NewStore(path string, max int) *Store {
+	if max <= 0 {
+		max = DefaultMaxSize
+	}
+	return &Store{path: path, max: max}
+}
+
+// DefaultPath returns the default location of the history file
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-history.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "history.json")
+}
+
+// Entries returns the lines of a namespace, oldest first
+func (s *Store) Entries(namespace string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return file.Namespaces[memory.Namespace(namespace)], nil
+}
+
+// Add records line as the latest of a namespace. The file is read again first,
+// so lines added by other chats running at the same time are kept.
+func (s *Store) Add(namespace, line string) error {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := s.load()
+	if err != nil {
+		return err
+	}
+	ns := memory.Namespace(namespace)
+	file.Namespaces[ns] = Append(file.Namespaces[ns], line, s.max)
+	return s.save(file)
+}
+
+// Clear removes the lines of a namespace
+func (s *Store) Clear(namespace string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := s.load()
+	if err != nil {
+		return err
+	}
+	delete(file.Namespaces, memory.Namespace(namespace))
+	return s.save(file)
+}
+
+// Set replaces the lines of a namespace, as when restoring a snapshot
+func (s *Store) Set(namespace string, lines []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := s.load()
+	if err != nil {
+		return err
+	}
+	ns := memory.Namespace(namespace)
+	if len(lines) == 0 {
+		delete(file.Namespaces, ns)
+	} else {
+		file.Namespaces[ns] = append([]string(nil), lines...)
+	}
+	return s.save(file)
+}
+
+// Append adds line to lines, dropping an earlier copy of it and the oldest
+// lines beyond max
+func Append(lines []string, line string, max int) []string {
+	kept := make([]string, 0, len(lines)+1)
+	for _, l := range lines {
+		if l != line {
+			kept = append(kept, l)
+		}
+	}
+	kept = append(kept, line)
+	if max > 0 && len(kept) > max {
+		kept = kept[len(kept)-max:]
+	}
+	return kept
+}
+
+// Search returns the index of the latest line before index from that contains
+// query, ignoring case, or -1 if there is none
+func Search(lines []string, query string, from int) int {
+	if from > len(lines) {
+		from = len(lines)
+	}
+	query = strings.ToLower(query)
+	for i := from - 1; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(lines[i]), query) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) load() (*historyFile, error) {
+	file := &historyFile{Namespaces: map[string][]string{}}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	if file.Namespaces == nil {
+		file.Namespaces = map[string][]string{}
+	}
+	return file, nil
+}
+
+func (s *Store) save(file *historyFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
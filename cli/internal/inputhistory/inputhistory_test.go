@@ -0,0 +1,55 @@
+package inputhistory
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAddDeduplicatesAndCaps(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "history.json"), 3)
+	for _, line := range []string{"a", "b", "a", "c", "d", "  "} {
+		if err := store.Add("work", line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Add("", "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Entries("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("work = %q, want %q", got, want)
+	}
+	if got, _ := store.Entries("default"); !reflect.DeepEqual(got, []string{"other"}) {
+		t.Errorf("default = %q", got)
+	}
+
+	// A second store on the same file, like another chat, sees the lines
+	again := NewStore(store.path, 0)
+	if got, _ := again.Entries("work"); len(got) != 3 {
+		t.Errorf("reloaded %q", got)
+	}
+	if err := again.Clear("work"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Entries("work"); len(got) != 0 {
+		t.Errorf("cleared history has %q", got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	lines := []string{"scan port 22", "/clear", "Scan the subnet", "hello"}
+	if got := Search(lines, "scan", len(lines)); got != 2 {
+		t.Errorf("latest match = %d, want 2", got)
+	}
+	if got := Search(lines, "scan", 2); got != 0 {
+		t.Errorf("older match = %d, want 0", got)
+	}
+	if got := Search(lines, "nope", len(lines)); got != -1 {
+		t.Errorf("no match = %d", got)
+	}
+}
@@ -0,0 +1,207 @@
+// Package inspect records what a provider sent back for the last request: the
+// status, rate-limit headers, the raw stream lines, the finish reason and the
+// usage object. It helps explain replies that stop early and tool calls that
+// don't parse.
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxChunks is how many stream lines of a response are kept
+const MaxChunks = 500
+
+// Header is a response header worth showing
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Response is what came back for one request
+type Response struct {
+	Started        time.Time
+	Endpoint       string
+	Status         int // Zero until the response arrives
+	Headers        []Header
+	Chunks         []string // Raw stream lines, the first MaxChunks of them
+	Omitted        int      // Lines past MaxChunks
+	ToolCallChunks int      // Lines carrying tool call deltas
+	FinishReason   string
+	Usage          string // The usage object as sent
+	Done           bool   // The stream ended with [DONE]
+	Error          string
+	Duration       time.Duration
+}
+
+// Headers returns the rate-limit, retry and request ID headers of h, sorted
+func Headers(h http.Header) []Header {
+	var headers []Header
+	for name, values := range h {
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "ratelimit") || strings.Contains(lower, "rate-limit") ||
+			lower == "retry-after" || strings.HasSuffix(lower, "request-id") || lower == "openai-processing-ms" {
+			headers = append(headers, Header{Name: lower, Value: strings.Join(values, ", ")})
+		}
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Name < headers[j].Name })
+	return headers
+}
+
+// chunk holds the parts of a stream event that are summarized: the OpenAI
+// format and Anthropic's message_delta
+type chunk struct {
+	Choices []struct {
+		FinishReason *string `json:"finish_reason"`
+		Delta        struct {
+			ToolCalls []json.RawMessage `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage json.RawMessage `json:"usage"`
+	Delta struct {
+		StopReason *string `json:"stop_reason"`
+	} `json:"delta"`
+}
+
+// add records one stream line
+func (r *Response) add(line string) {
+	if len(r.Chunks) < MaxChunks {
+		r.Chunks = append(r.Chunks, line)
+	} else {
+		r.Omitted++
+	}
+
+	data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+	if data == "[DONE]" {
+		r.Done = true
+		return
+	}
+	var c chunk
+	if json.Unmarshal([]byte(data), &c) != nil {
+		return
+	}
+	for _, choice := range c.Choices {
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			r.FinishReason = *choice.FinishReason
+		}
+		if len(choice.Delta.ToolCalls) > 0 {
+			r.ToolCallChunks++
+		}
+	}
+	if c.Delta.StopReason != nil && *c.Delta.StopReason != "" {
+		r.FinishReason = *c.Delta.StopReason
+	}
+	if len(c.Usage) > 0 && string(c.Usage) != "null" {
+		r.Usage = string(c.Usage)
+	}
+}
+
+// Lines describes the response for display, ending with the raw stream
+func (r *Response) Lines() []string {
+	status := "no response"
+	if r.Status != 0 {
+		status = fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+	}
+	lines := []string{
+		fmt.Sprintf("POST %s", r.Endpoint),
+		fmt.Sprintf("Status: %s at %s, %s", status, r.Started.Format("15:04:05"), r.Duration.Round(time.Millisecond)),
+	}
+	if r.Error != "" {
+		lines = append(lines, "Error: "+r.Error)
+	}
+	finish := r.FinishReason
+	if finish == "" {
+		finish = "(none)"
+	}
+	if !r.Done && r.Status == http.StatusOK {
+		finish += ", stream ended without [DONE]"
+	}
+	lines = append(lines, "finish_reason: "+finish)
+	usage := r.Usage
+	if usage == "" {
+		usage = "(not reported)"
+	}
+	lines = append(lines, "usage: "+usage)
+
+	lines = append(lines, "", "Rate-limit headers:")
+	if len(r.Headers) == 0 {
+		lines = append(lines, "  (none)")
+	}
+	for _, h := range r.Headers {
+		lines = append(lines, fmt.Sprintf("  %s: %s", h.Name, h.Value))
+	}
+
+	lines = append(lines, "", fmt.Sprintf("Stream: %d line(s), %d with tool call deltas", len(r.Chunks)+r.Omitted, r.ToolCallChunks))
+	lines = append(lines, r.Chunks...)
+	if r.Omitted > 0 {
+		lines = append(lines, fmt.Sprintf("… %d more line(s) not kept", r.Omitted))
+	}
+	return lines
+}
+
+// Recorder keeps the last response. It is safe to read while a response is
+// still streaming in.
+type Recorder struct {
+	mu   sync.Mutex
+	last *Response
+}
+
+// Begin starts recording a request to endpoint, replacing the last response
+func (rec *Recorder) Begin(endpoint string) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.last = &Response{Started: time.Now(), Endpoint: endpoint}
+}
+
+// Status records the status and headers of the response
+func (rec *Recorder) Status(status int, header http.Header) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.last != nil {
+		rec.last.Status = status
+		rec.last.Headers = Headers(header)
+	}
+}
+
+// Chunk records one line of the response body
+func (rec *Recorder) Chunk(line string) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.last != nil {
+		rec.last.add(line)
+	}
+}
+
+// End records how the request ended
+func (rec *Recorder) End(err error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.last == nil {
+		return
+	}
+	rec.last.Duration = time.Since(rec.last.Started)
+	if err != nil {
+		rec.last.Error = err.Error()
+	}
+}
+
+// Last returns a copy of the last response; false if nothing was sent yet
+func (rec *Recorder) Last() (Response, bool) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.last == nil {
+		return Response{}, false
+	}
+	last := *rec.last
+	last.Chunks = append([]string(nil), rec.last.Chunks...)
+	last.Headers = append([]Header(nil), rec.last.Headers...)
+	if last.Duration == 0 {
+		last.Duration = time.Since(last.Started)
+	}
+	return last, true
+}
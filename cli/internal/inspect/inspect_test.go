@@ -0,0 +1,82 @@
+package inspect
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	var rec Recorder
+	if _, ok := rec.Last(); ok {
+		t.Error("an empty recorder has a response")
+	}
+
+	rec.Begin("https://api.openai.com/v1/chat/completions")
+	header := http.Header{}
+	header.Set("X-Ratelimit-Remaining-Requests", "99")
+	header.Set("Retry-After", "2")
+	header.Set("X-Request-Id", "req_123")
+	header.Set("Content-Type", "text/event-stream")
+	rec.Status(http.StatusOK, header)
+
+	for _, line := range []string{
+		`data: {"choices":[{"delta":{"content":"Hi"},"finish_reason":null}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"ip"}}]}}]}`,
+		`data: {"choices":[{"delta":{},"finish_reason":"length"}],"usage":null}`,
+		`data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":5}}`,
+		`data: [DONE]`,
+	} {
+		rec.Chunk(line)
+	}
+	rec.End(nil)
+
+	last, ok := rec.Last()
+	if !ok {
+		t.Fatal("no response recorded")
+	}
+	if last.FinishReason != "length" || last.Usage != `{"prompt_tokens":12,"completion_tokens":5}` || !last.Done || last.ToolCallChunks != 1 {
+		t.Errorf("last = %+v", last)
+	}
+	var names []string
+	for _, h := range last.Headers {
+		names = append(names, h.Name)
+	}
+	if strings.Join(names, ",") != "retry-after,x-ratelimit-remaining-requests,x-request-id" {
+		t.Errorf("headers = %v", names)
+	}
+
+	text := strings.Join(last.Lines(), "\n")
+	for _, want := range []string{"Status: 200 OK", "finish_reason: length", "x-ratelimit-remaining-requests: 99", "Stream: 5 line(s), 1 with tool call deltas", "data: [DONE]"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("lines lack %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestRecorderFailures(t *testing.T) {
+	var rec Recorder
+	rec.Begin("http://localhost:11434/v1/chat/completions")
+	rec.End(errors.New("connection refused"))
+	last, _ := rec.Last()
+	if text := strings.Join(last.Lines(), "\n"); !strings.Contains(text, "Status: no response") || !strings.Contains(text, "Error: connection refused") {
+		t.Errorf("lines = %s", text)
+	}
+
+	// Anthropic reports the stop reason in message_delta, and a cut-off stream is flagged
+	rec.Begin("https://api.anthropic.com/v1/chat/completions")
+	rec.Status(http.StatusOK, nil)
+	rec.Chunk("event: message_delta")
+	rec.Chunk(`data: {"type":"message_delta","delta":{"stop_reason":"max_tokens"},"usage":{"output_tokens":1024}}`)
+	for i := 0; i < MaxChunks; i++ {
+		rec.Chunk(`data: {}`)
+	}
+	last, _ = rec.Last()
+	if last.FinishReason != "max_tokens" || last.Omitted != 2 {
+		t.Errorf("last = %s, %d omitted", last.FinishReason, last.Omitted)
+	}
+	if text := strings.Join(last.Lines(), "\n"); !strings.Contains(text, "stream ended without [DONE]") || !strings.Contains(text, "2 more line(s) not kept") {
+		t.Errorf("lines = %s", text)
+	}
+}
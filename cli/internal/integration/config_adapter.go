@@ -3,13 +3,25 @@ package integration
 import (
 	"github.com/hacka-re/cli/internal/config"
 	"github.com/hacka-re/cli/internal/tui/pkg/interfaces"
+	"github.com/hacka-re/cli/pkg/sharelink"
 )
 
-// CLIConfigAdapter makes CLI config compatible with hackare-tui
+// CLIConfigAdapter makes CLI config compatible with hackare-tui.
+// It implements interfaces.ExternalConfig plus the optional budget, notification,
+// offline and ConfigReceiver interfaces, so settings flow both ways.
 type CLIConfigAdapter struct {
 	*config.Config
 }
 
+var (
+	_ interfaces.ExternalConfig     = (*CLIConfigAdapter)(nil)
+	_ interfaces.BudgetConfig       = (*CLIConfigAdapter)(nil)
+	_ interfaces.NotificationConfig = (*CLIConfigAdapter)(nil)
+	_ interfaces.OfflineConfig      = (*CLIConfigAdapter)(nil)
+	_ interfaces.ShareConfig        = (*CLIConfigAdapter)(nil)
+	_ interfaces.ConfigReceiver     = (*CLIConfigAdapter)(nil)
+)
+
 // GetProvider returns the API provider as string
 func (c *CLIConfigAdapter) GetProvider() string {
 	return string(c.Config.Provider)
@@ -65,12 +77,171 @@ func (c *CLIConfigAdapter) GetNamespace() string {
 	return c.Config.Namespace
 }
 
+// GetStreamMode returns whether streaming is enabled
+func (c *CLIConfigAdapter) GetStreamMode() bool {
+	return c.Config.StreamResponse
+}
+
+// GetFunctions returns the CLI's function definitions
+func (c *CLIConfigAdapter) GetFunctions() []interfaces.FunctionDef {
+	functions := make([]interfaces.FunctionDef, 0, len(c.Config.Functions))
+	for _, fn := range c.Config.Functions {
+		functions = append(functions, interfaces.FunctionDef{
+			Name:        fn.Name,
+			Code:        fn.Code,
+			Description: fn.Description,
+			Enabled:     fn.Enabled,
+			Tags:        fn.Tags,
+		})
+	}
+	return functions
+}
+
+// GetPrompts returns the CLI's prompt library
+func (c *CLIConfigAdapter) GetPrompts() []interfaces.PromptDef {
+	prompts := make([]interfaces.PromptDef, 0, len(c.Config.Prompts))
+	for _, prompt := range c.Config.Prompts {
+		prompts = append(prompts, interfaces.PromptDef{
+			Name:     prompt.Name,
+			Content:  prompt.Content,
+			Category: prompt.Category,
+			Enabled:  prompt.Enabled,
+			Tags:     prompt.Tags,
+		})
+	}
+	return prompts
+}
+
+// GetMaxTokensPerRequest returns the per-request token budget
+func (c *CLIConfigAdapter) GetMaxTokensPerRequest() int {
+	return c.Config.MaxTokensPerRequest
+}
+
+// GetMaxCostPerSession returns the per-session cost budget
+func (c *CLIConfigAdapter) GetMaxCostPerSession() float64 {
+	return c.Config.MaxCostPerSession
+}
+
+// GetMaxCostPerDay returns the daily cost budget
+func (c *CLIConfigAdapter) GetMaxCostPerDay() float64 {
+	return c.Config.MaxCostPerDay
+}
+
+// GetNotifyOnComplete returns whether completion notifications are enabled
+func (c *CLIConfigAdapter) GetNotifyOnComplete() bool {
+	return c.Config.NotifyOnComplete
+}
+
+// GetNotifyAfterSeconds returns the minimum response time before notifying
+func (c *CLIConfigAdapter) GetNotifyAfterSeconds() int {
+	return c.Config.NotifyAfterSeconds
+}
+
+// GetSeed returns the sampling seed, 0 for none
+func (c *CLIConfigAdapter) GetSeed() int {
+	return c.Config.Seed
+}
+
+// GetLanguage returns the reply language setting
+func (c *CLIConfigAdapter) GetLanguage() string {
+	return c.Config.Language
+}
+
+// GetLintPrompts returns whether prompts are checked before sending
+func (c *CLIConfigAdapter) GetLintPrompts() bool {
+	return c.Config.LintPrompts
+}
+
+// GetArtifactsMaxAgeDays returns how long session artifacts are kept
+func (c *CLIConfigAdapter) GetArtifactsMaxAgeDays() int {
+	return c.Config.ArtifactsMaxAgeDays
+}
+
+// GetArtifactsMaxSizeMB returns how much space session artifacts may use
+func (c *CLIConfigAdapter) GetArtifactsMaxSizeMB() int {
+	return c.Config.ArtifactsMaxSizeMB
+}
+
 // GetIsOfflineMode returns whether offline mode is enabled
 func (c *CLIConfigAdapter) GetIsOfflineMode() bool {
 	return c.Config.IsOfflineMode
 }
 
+// GetAllowRemoteMCP returns whether remote MCP is allowed in offline mode
+func (c *CLIConfigAdapter) GetAllowRemoteMCP() bool {
+	return c.Config.AllowRemoteMCP
+}
+
+// GetAllowRemoteEmbeddings returns whether remote embeddings are allowed in offline mode
+func (c *CLIConfigAdapter) GetAllowRemoteEmbeddings() bool {
+	return c.Config.AllowRemoteEmbeddings
+}
+
+// GetLockedByLink returns whether the share link the config came from is locked
+func (c *CLIConfigAdapter) GetLockedByLink() bool {
+	return c.Config.LockedByLink
+}
+
+// GetUnlockHash returns the hash of the share link's override password
+func (c *CLIConfigAdapter) GetUnlockHash() string {
+	return c.Config.UnlockHash
+}
+
+// GetKiosk returns whether the CLI was started with --kiosk
+func (c *CLIConfigAdapter) GetKiosk() bool {
+	return c.Config.Kiosk
+}
+
+// GetShareConfig returns what a share link of the CLI config would carry,
+// including the MCP servers
+func (c *CLIConfigAdapter) GetShareConfig() *sharelink.Config {
+	shared := c.Config.ToSharedConfig()
+	shared.MCPServers = c.Config.MCPServers
+	return shared
+}
+
+// ApplyTUIConfig copies the settings changed in the TUI back into the CLI config
+func (c *CLIConfigAdapter) ApplyTUIConfig(tuiCfg interfaces.ExternalConfig) {
+	c.Config.Provider = config.Provider(tuiCfg.GetProvider())
+	c.Config.APIKey = tuiCfg.GetAPIKey()
+	c.Config.BaseURL = tuiCfg.GetBaseURL()
+	c.Config.Model = tuiCfg.GetModel()
+	c.Config.Temperature = tuiCfg.GetTemperature()
+	c.Config.MaxTokens = tuiCfg.GetMaxTokens()
+	c.Config.StreamResponse = tuiCfg.GetStreamMode()
+	c.Config.YoloMode = tuiCfg.GetYoloMode()
+	c.Config.VoiceControl = tuiCfg.GetVoiceControl()
+	c.Config.SystemPrompt = tuiCfg.GetSystemPrompt()
+	c.Config.Namespace = tuiCfg.GetNamespace()
+
+	if budget, ok := tuiCfg.(interfaces.BudgetConfig); ok {
+		c.Config.MaxTokensPerRequest = budget.GetMaxTokensPerRequest()
+		c.Config.MaxCostPerSession = budget.GetMaxCostPerSession()
+		c.Config.MaxCostPerDay = budget.GetMaxCostPerDay()
+	}
+	if notify, ok := tuiCfg.(interfaces.NotificationConfig); ok {
+		c.Config.NotifyOnComplete = notify.GetNotifyOnComplete()
+		c.Config.NotifyAfterSeconds = notify.GetNotifyAfterSeconds()
+	}
+	if seed, ok := tuiCfg.(interfaces.SeedConfig); ok {
+		c.Config.Seed = seed.GetSeed()
+	}
+	if lang, ok := tuiCfg.(interfaces.LanguageConfig); ok {
+		c.Config.Language = lang.GetLanguage()
+	}
+	if lint, ok := tuiCfg.(interfaces.LintConfig); ok {
+		c.Config.LintPrompts = lint.GetLintPrompts()
+	}
+	if cleanup, ok := tuiCfg.(interfaces.ArtifactsConfig); ok {
+		c.Config.ArtifactsMaxAgeDays = cleanup.GetArtifactsMaxAgeDays()
+		c.Config.ArtifactsMaxSizeMB = cleanup.GetArtifactsMaxSizeMB()
+	}
+	if lock, ok := tuiCfg.(interfaces.LinkLockConfig); ok {
+		c.Config.LockedByLink = lock.GetLockedByLink() // Stays unlocked once the override password was entered
+	}
+}
+
 // WrapConfig wraps CLI config for TUI compatibility
-func WrapConfig(cfg *config.Config) interfaces.CLIConfig {
+func WrapConfig(cfg *config.Config) *CLIConfigAdapter {
 	return &CLIConfigAdapter{Config: cfg}
 }
\ No newline at end of file
@@ -23,87 +23,7 @@ func LaunchTUIWithPanel(cfg *config.Config, targetPanel string) error {
 	adaptedConfig := WrapConfig(cfg)
 
 	// Create callbacks for CLI command integration
-	callbacks := &tui.Callbacks{
-		OnStartChat: func(configInterface interface{}) error {
-			// Start CLI chat with enhanced terminal interface
-			return startEnhancedChat(cfg)
-		},
-
-		OnBrowse: func(configInterface interface{}) error {
-			// Launch browse command with current configuration
-			fmt.Println("Starting web server and opening browser...")
-			// Save config first to ensure it's available for the browse command
-			configPath := config.GetConfigPath()
-			if err := cfg.SaveToFile(configPath); err != nil {
-				return fmt.Errorf("failed to save config: %w", err)
-			}
-			// Execute browse command in a new process
-			cmd := exec.Command(os.Args[0], "browse")
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			return cmd.Start()
-		},
-
-		OnServe: func(configInterface interface{}) error {
-			// Launch serve command with current configuration
-			fmt.Println("Starting web server...")
-			// Save config first to ensure it's available for the serve command
-			configPath := config.GetConfigPath()
-			if err := cfg.SaveToFile(configPath); err != nil {
-				return fmt.Errorf("failed to save config: %w", err)
-			}
-			// Execute serve command in a new process
-			cmd := exec.Command(os.Args[0], "serve")
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			return cmd.Start()
-		},
-
-		OnShareLink: func(configInterface interface{}) (string, error) {
-			// Generate share link using CLI functionality
-			sharedConfig := cfg.ToSharedConfig()
-
-			// Get password from user
-			password, err := utils.GetPassword("Enter password for share link: ")
-			if err != nil {
-				return "", fmt.Errorf("failed to read password: %w", err)
-			}
-
-			url, err := share.CreateShareableURL(sharedConfig, password, "https://hacka.re/")
-			if err != nil {
-				return "", fmt.Errorf("failed to generate share link: %w", err)
-			}
-
-			return url, nil
-		},
-
-		OnSaveConfig: func(configInterface interface{}) error {
-			// Save CLI config to file
-			configPath := config.GetConfigPath()
-			return cfg.SaveToFile(configPath)
-		},
-
-		OnLoadConfig: func() (interface{}, error) {
-			// Load CLI config from file
-			configPath := config.GetConfigPath()
-			return config.LoadFromFile(configPath)
-		},
-
-		OnPasswordPrompt: func(prompt string) (string, error) {
-			return utils.GetPassword(prompt)
-		},
-
-		OnGetModels: func(provider string) ([]string, error) {
-			// TODO: Implement dynamic model fetching from API
-			// For now, return static lists
-			return getStaticModels(provider), nil
-		},
-
-		OnExit: func() {
-			// CLI cleanup if needed
-			// Currently no cleanup required
-		},
-	}
+	callbacks := createCallbacks(cfg)
 
 	// Configure launch options
 	options := &tui.LaunchOptions{
@@ -141,39 +61,71 @@ func LaunchTUIWithMode(cfg *config.Config, mode string) error {
 func createCallbacks(cfg *config.Config) *tui.Callbacks {
 	return &tui.Callbacks{
 		OnStartChat: func(configInterface interface{}) error {
+			// Start CLI chat with enhanced terminal interface
 			return startEnhancedChat(cfg)
 		},
 
 		OnBrowse: func(configInterface interface{}) error {
-			fmt.Println("Browse functionality will be available in future version")
-			return nil
+			// Launch browse command with current configuration
+			fmt.Println("Starting web server and opening browser...")
+			return runDetached(cfg, "browse")
 		},
 
 		OnServe: func(configInterface interface{}) error {
-			fmt.Println("Serve functionality will be available in future version")
-			return nil
+			// Launch serve command with current configuration
+			fmt.Println("Starting web server...")
+			return runDetached(cfg, "serve")
 		},
 
 		OnShareLink: func(configInterface interface{}) (string, error) {
+			if cfg.Kiosk {
+				return "", fmt.Errorf("sharing is disabled in kiosk mode")
+			}
+
+			// Generate share link using CLI functionality
 			sharedConfig := cfg.ToSharedConfig()
+
+			// Show what the link carries; the API key stays out unless confirmed
+			if _, err := share.ConfirmContents(sharedConfig, os.Stdin, os.Stdout); err != nil {
+				return "", err
+			}
+
 			// Get password from user
 			password, err := utils.GetPassword("Enter password for share link: ")
 			if err != nil {
 				return "", fmt.Errorf("failed to read password: %w", err)
 			}
+
+			// Optionally lock prompts, functions and provider for whoever opens the link.
+			// A configuration that came locked is shared on locked.
+			if !sharedConfig.Locked {
+				override, err := utils.GetPassword("Override password to lock the configuration (leave empty to share it editable): ")
+				if err != nil {
+					return "", fmt.Errorf("failed to read password: %w", err)
+				}
+				if override != "" {
+					if err := sharedConfig.Lock(override); err != nil {
+						return "", err
+					}
+				}
+			}
+
 			url, err := share.CreateShareableURL(sharedConfig, password, "https://hacka.re/")
 			if err != nil {
 				return "", fmt.Errorf("failed to generate share link: %w", err)
 			}
+
 			return url, nil
 		},
 
 		OnSaveConfig: func(configInterface interface{}) error {
+			// Save CLI config to file
 			configPath := config.GetConfigPath()
 			return cfg.SaveToFile(configPath)
 		},
 
 		OnLoadConfig: func() (interface{}, error) {
+			// Load CLI config from file
 			configPath := config.GetConfigPath()
 			return config.LoadFromFile(configPath)
 		},
@@ -183,15 +135,31 @@ func createCallbacks(cfg *config.Config) *tui.Callbacks {
 		},
 
 		OnGetModels: func(provider string) ([]string, error) {
+			// TODO: Implement dynamic model fetching from API
+			// For now, return static lists
 			return getStaticModels(provider), nil
 		},
 
 		OnExit: func() {
 			// CLI cleanup if needed
+			// Currently no cleanup required
 		},
 	}
 }
 
+// runDetached saves the config so the subcommand sees it, then starts the subcommand in a new process
+func runDetached(cfg *config.Config, subcommand string) error {
+	configPath := config.GetConfigPath()
+	if err := cfg.SaveToFile(configPath); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	cmd := exec.Command(os.Args[0], subcommand)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Start()
+}
+
 // isDebugMode checks if debug mode is enabled
 func isDebugMode() bool {
 	logLevel := os.Getenv("HACKARE_LOG_LEVEL")
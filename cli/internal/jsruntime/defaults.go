@@ -15,6 +15,21 @@ var defaultRC4Functions string
 //go:embed defaults/math.js
 var defaultMathFunctions string
 
+//go:embed defaults/security.js
+var defaultSecurityFunctions string
+
+//go:embed defaults/scans.js
+var defaultScanFunctions string
+
+//go:embed defaults/cve.js
+var defaultCVEFunctions string
+
+//go:embed defaults/osint.js
+var defaultOSINTFunctions string
+
+//go:embed defaults/reputation.js
+var defaultReputationFunctions string
+
 // DefaultFunctionGroup represents a group of related functions
 type DefaultFunctionGroup struct {
 	ID          string
@@ -38,6 +53,36 @@ func GetDefaultFunctionGroups() []DefaultFunctionGroup {
 			Description: "Mathematical helper functions",
 			Functions:   parseMultipleFunctions(defaultMathFunctions),
 		},
+		{
+			ID:          "security-utilities",
+			Name:        "Security Utilities",
+			Description: "Hashing, base64 and URL encoding, JWT decoding, subnet and epoch time helpers",
+			Functions:   parseMultipleFunctions(defaultSecurityFunctions),
+		},
+		{
+			ID:          "scan-data",
+			Name:        "Scan Data",
+			Description: "Query Nmap and masscan results and packet captures ingested with hacka.re ingest",
+			Functions:   parseMultipleFunctions(defaultScanFunctions),
+		},
+		{
+			ID:          "cve-lookup",
+			Name:        "CVE Lookup",
+			Description: "CVSS scores and references of CVEs from the NVD, cached locally",
+			Functions:   parseMultipleFunctions(defaultCVEFunctions),
+		},
+		{
+			ID:          "osint-lookups",
+			Name:        "OSINT Lookups",
+			Description: "Have I Been Pwned breach checks and crt.sh certificate transparency, rate limited",
+			Functions:   parseMultipleFunctions(defaultOSINTFunctions),
+		},
+		{
+			ID:          "url-reputation",
+			Name:        "URL Reputation",
+			Description: "Verdicts on URLs, domains and IP addresses from VirusTotal and urlscan.io",
+			Functions:   parseMultipleFunctions(defaultReputationFunctions),
+		},
 	}
 }
 
@@ -49,6 +94,16 @@ func LoadDefaultFunctions(registry *Registry, groupID string) error {
 		return LoadRC4Defaults(registry)
 	case "math-utilities", "math":
 		return LoadMathDefaults(registry)
+	case "security-utilities", "security":
+		return LoadSecurityDefaults(registry)
+	case "scan-data", "scans":
+		return LoadScanDefaults(registry)
+	case "cve-lookup", "cve":
+		return LoadCVEDefaults(registry)
+	case "osint-lookups", "osint":
+		return LoadOSINTDefaults(registry)
+	case "url-reputation", "reputation":
+		return LoadReputationDefaults(registry)
 	default:
 		groups := GetDefaultFunctionGroups()
 		for _, group := range groups {
@@ -61,6 +116,20 @@ func LoadDefaultFunctions(registry *Registry, groupID string) error {
 	return fmt.Errorf("default function group '%s' not found", groupID)
 }
 
+// LoadEnabledDefaults loads the default function groups switched on in
+// enabled, such as the defaultFunctions of the configuration
+func LoadEnabledDefaults(registry *Registry, enabled map[string]bool) error {
+	for _, group := range GetDefaultFunctionGroups() {
+		if !enabled[group.ID] {
+			continue
+		}
+		if err := LoadDefaultFunctions(registry, group.ID); err != nil {
+			return fmt.Errorf("failed to load group '%s': %w", group.ID, err)
+		}
+	}
+	return nil
+}
+
 // LoadAllDefaultFunctions loads all default function groups
 func LoadAllDefaultFunctions(registry *Registry) error {
 	groups := GetDefaultFunctionGroups()
@@ -0,0 +1,41 @@
+/**
+ * Look up a CVE in the NVD
+ * @description Looks up a CVE in the National Vulnerability Database: CVSS scores, severity, weaknesses and references
+ * @param {string} cve_id - The CVE ID, e.g. CVE-2021-44228
+ * @returns {Object} Object containing the vulnerability or error
+ * @callable
+ */
+function cve_lookup(cve_id) {
+    try {
+        if (typeof cve_id !== 'string' || cve_id.trim() === '') {
+            return { error: "CVE ID must be a non-empty string", success: false };
+        }
+
+        const record = cveLookup(cve_id);
+        const scores = record.scores || [];
+        const references = record.references || [];
+        return {
+            success: true,
+            id: record.id,
+            description: record.description,
+            published: record.published,
+            status: record.status,
+            cvss: scores.length > 0 ? scores[0] : null,
+            scores: scores,
+            weaknesses: record.weaknesses || [],
+            known_exploited: record.knownExploited === true,
+            references: references.slice(0, MAX_REFERENCES),
+            more_references: Math.max(0, references.length - MAX_REFERENCES),
+            cached: record.cached,
+            fetched_at: record.fetchedAt
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "CVE lookup failed"
+        };
+    }
+}
+
+// Long reference lists are cut to keep the answer small
+const MAX_REFERENCES = 15;
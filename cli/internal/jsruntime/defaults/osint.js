@@ -0,0 +1,100 @@
+/**
+ * Check an account for data breaches
+ * @description Lists the data breaches an email address or username appears in, from Have I Been Pwned
+ * @param {string} account - The email address or username to check
+ * @returns {Object} Object containing the breaches or error
+ * @callable
+ */
+function breach_check(account) {
+    try {
+        if (typeof account !== 'string' || account.trim() === '') {
+            return { error: "Account must be a non-empty string", success: false };
+        }
+
+        const breaches = osint.breachedAccount(account.trim());
+        return {
+            success: true,
+            account: account.trim(),
+            breached: breaches.length > 0,
+            breach_count: breaches.length,
+            breaches: breaches.map(function(breach) {
+                return {
+                    name: breach.title || breach.name,
+                    domain: breach.domain,
+                    date: breach.breachDate,
+                    accounts: breach.pwnCount,
+                    data: breach.dataClasses,
+                    verified: breach.isVerified
+                };
+            })
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Breach check failed"
+        };
+    }
+}
+
+/**
+ * Check a password against known breaches
+ * @description Counts how often a password appears in breaches (Pwned Passwords); only a hash prefix is sent
+ * @param {string} password - The password to check
+ * @returns {Object} Object containing the count or error
+ * @callable
+ */
+function pwned_password(password) {
+    try {
+        if (typeof password !== 'string' || password === '') {
+            return { error: "Password must be a non-empty string", success: false };
+        }
+
+        const count = osint.pwnedPassword(password);
+        return {
+            success: true,
+            pwned: count > 0,
+            count: count
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Password check failed"
+        };
+    }
+}
+
+/**
+ * Look up certificates for a domain
+ * @description Lists the hostnames and issuers of certificates logged for a domain and its subdomains (crt.sh certificate transparency)
+ * @param {string} domain - The domain, e.g. example.com
+ * @param {boolean} include_expired - Include expired certificates
+ * @returns {Object} Object containing the certificate report or error
+ * @callable
+ */
+function crtsh_lookup(domain, include_expired) {
+    try {
+        if (typeof domain !== 'string' || domain.trim() === '') {
+            return { error: "Domain must be a non-empty string", success: false };
+        }
+
+        const report = osint.certificates(domain, include_expired === true);
+        const names = report.names.slice(0, MAX_NAMES);
+        return {
+            success: true,
+            domain: report.domain,
+            certificates: report.certificates,
+            names: names,
+            more_names: report.names.length - names.length,
+            issuers: report.issuers,
+            latest: report.latest
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Certificate lookup failed"
+        };
+    }
+}
+
+// Long name lists are cut to keep the answer small
+const MAX_NAMES = 200;
@@ -0,0 +1,28 @@
+/**
+ * Check the reputation of a URL, domain or IP address
+ * @description Asks VirusTotal and urlscan.io about a URL, domain or IP address and returns a verdict: malicious, suspicious, harmless or unknown
+ * @param {string} target - A URL with its scheme, a domain or an IP address
+ * @returns {Object} Object containing the verdict per service or error
+ * @callable
+ */
+function url_reputation(target) {
+    try {
+        if (typeof target !== 'string' || target.trim() === '') {
+            return { error: "Target must be a non-empty string", success: false };
+        }
+
+        const report = reputationCheck(target.trim());
+        return {
+            success: true,
+            target: report.target.value,
+            kind: report.target.kind,
+            verdict: report.verdict,
+            results: report.results
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Reputation check failed"
+        };
+    }
+}
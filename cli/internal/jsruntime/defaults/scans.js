@@ -0,0 +1,152 @@
+/**
+ * List the ingested scans
+ * @description Lists the Nmap and masscan scans ingested with hacka.re ingest, latest first
+ * @returns {Object} Object containing the scan summaries or error
+ * @callable
+ */
+function scan_list() {
+    try {
+        const list = scanData.list();
+        return {
+            success: true,
+            scans: list,
+            count: list.length
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Listing scans failed"
+        };
+    }
+}
+
+/**
+ * Summarize a scan
+ * @description Counts the hosts and open ports of a scan and lists the most common open ports
+ * @param {string} scan - Name of the scan; the latest one if left out
+ * @returns {Object} Object containing the summary or error
+ * @callable
+ */
+function scan_summary(scan) {
+    try {
+        return {
+            success: true,
+            summary: scanData.summary(optionalText(scan))
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Summarizing the scan failed"
+        };
+    }
+}
+
+/**
+ * Find open ports in a scan
+ * @description Finds the hosts with open ports matching a port number, service, product or network
+ * @param {number} port - Port number, e.g. 443
+ * @param {string} service - Part of the service name, e.g. http or ssh
+ * @param {string} product - Part of the product name, e.g. nginx or OpenSSH
+ * @param {string} network - Only hosts in this CIDR block, e.g. 10.0.0.0/24
+ * @param {string} scan - Name of the scan; the latest one if left out
+ * @returns {Object} Object containing the matching hosts or error
+ * @callable
+ */
+function scan_find(port, service, product, network, scan) {
+    try {
+        const number = port === undefined || port === null || port === '' ? 0 : Number(port);
+        if (!Number.isInteger(number) || number < 0 || number > 65535) {
+            return { error: "Port must be a number from 1 to 65535", success: false };
+        }
+
+        const hosts = scanData.find(number, optionalText(service), optionalText(product), optionalText(network), optionalText(scan));
+        const ports = hosts.reduce(function(total, host) { return total + host.ports.length; }, 0);
+        return {
+            success: true,
+            hosts: hosts,
+            host_count: hosts.length,
+            port_count: ports
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Searching the scan failed"
+        };
+    }
+}
+
+/**
+ * Show one host of a scan
+ * @description Shows every port, service, banner and the OS guess of one scanned host
+ * @param {string} address - IP address or hostname of the host
+ * @param {string} scan - Name of the scan; the latest one if left out
+ * @returns {Object} Object containing the host or error
+ * @callable
+ */
+function scan_host(address, scan) {
+    try {
+        if (typeof address !== 'string' || address.trim() === '') {
+            return { error: "Address must be a non-empty string", success: false };
+        }
+
+        const host = scanData.host(address.trim(), optionalText(scan));
+        if (!host) {
+            return { error: "No host " + address + " in the scan", success: false };
+        }
+        return { success: true, host: host };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Looking up the host failed"
+        };
+    }
+}
+
+/**
+ * List the ingested packet captures
+ * @description Lists the packet captures ingested with hacka.re ingest pcap, latest first
+ * @returns {Object} Object containing the capture overviews or error
+ * @callable
+ */
+function capture_list() {
+    try {
+        const list = captureData.list();
+        return {
+            success: true,
+            captures: list,
+            count: list.length
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Listing captures failed"
+        };
+    }
+}
+
+/**
+ * Summarize a packet capture
+ * @description Shows the busiest hosts, largest flows, DNS lookups and HTTP requests of a packet capture
+ * @param {string} capture - Name of the capture; the latest one if left out
+ * @returns {Object} Object containing the summary or error
+ * @callable
+ */
+function capture_summary(capture) {
+    try {
+        return {
+            success: true,
+            summary: captureData.summary(optionalText(capture))
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Summarizing the capture failed"
+        };
+    }
+}
+
+// Helper functions
+
+function optionalText(value) {
+    return value === undefined || value === null ? '' : String(value).trim();
+}
@@ -0,0 +1,593 @@
+/**
+ * Hash text with MD5, SHA-1 or SHA-256
+ * @description Hashes the UTF-8 bytes of a text with md5, sha1 or sha256 (the default)
+ * @param {string} text - The text to hash
+ * @param {string} algorithm - md5, sha1 or sha256
+ * @returns {Object} Object containing the hex digest or error
+ * @callable
+ */
+function hash_text(text, algorithm) {
+    try {
+        if (typeof text !== 'string') {
+            return { error: "Text must be a string", success: false };
+        }
+
+        const name = String(algorithm || 'sha256').toLowerCase().replace('-', '');
+        const hashes = { md5: md5Bytes, sha1: sha1Bytes, sha256: sha256Bytes };
+        if (!hashes[name]) {
+            return { error: "Unknown algorithm (use md5, sha1 or sha256)", success: false };
+        }
+
+        const bytes = utf8Bytes(text);
+        return {
+            success: true,
+            algorithm: name,
+            hex: bytesToHex(hashes[name](bytes)),
+            bytes: bytes.length
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Hashing failed"
+        };
+    }
+}
+
+/**
+ * Encode text as base64
+ * @description Encodes the UTF-8 bytes of a text as base64, or unpadded base64url
+ * @param {string} text - The text to encode
+ * @param {boolean} url_safe - Use the URL-safe alphabet without padding
+ * @returns {Object} Object containing the encoded text or error
+ * @callable
+ */
+function base64_encode(text, url_safe) {
+    try {
+        if (typeof text !== 'string') {
+            return { error: "Text must be a string", success: false };
+        }
+
+        let encoded = base64FromBytes(utf8Bytes(text));
+        if (url_safe) {
+            encoded = encoded.replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+        }
+        return { success: true, encoded: encoded };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Encoding failed"
+        };
+    }
+}
+
+/**
+ * Decode base64 or base64url
+ * @description Decodes base64 or base64url, with or without padding, to text and hex
+ * @param {string} encoded - The base64 to decode
+ * @returns {Object} Object containing the decoded text and hex or error
+ * @callable
+ */
+function base64_decode(encoded) {
+    try {
+        if (typeof encoded !== 'string') {
+            return { error: "Input must be a string", success: false };
+        }
+
+        const bytes = base64ToBytes(encoded);
+        if (bytes === null) {
+            return { error: "Input is not valid base64", success: false };
+        }
+
+        const text = utf8Text(bytes);
+        const result = { success: true, hex: bytesToHex(bytes), bytes: bytes.length };
+        if (text !== null) {
+            result.text = text;
+        } else {
+            result.note = "The decoded bytes are not UTF-8 text";
+        }
+        return result;
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Decoding failed"
+        };
+    }
+}
+
+/**
+ * Percent-encode text for a URL
+ * @description Percent-encodes text for use in a URL query or path component
+ * @param {string} text - The text to encode
+ * @returns {Object} Object containing the encoded text or error
+ * @callable
+ */
+function url_encode(text) {
+    try {
+        if (typeof text !== 'string') {
+            return { error: "Text must be a string", success: false };
+        }
+
+        return { success: true, encoded: encodeURIComponent(text) };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Encoding failed"
+        };
+    }
+}
+
+/**
+ * Decode percent-encoded text
+ * @description Decodes percent-encoded text from a URL; + is read as a space
+ * @param {string} encoded - The text to decode
+ * @returns {Object} Object containing the decoded text or error
+ * @callable
+ */
+function url_decode(encoded) {
+    try {
+        if (typeof encoded !== 'string') {
+            return { error: "Input must be a string", success: false };
+        }
+
+        return { success: true, text: decodeURIComponent(encoded.replace(/\+/g, ' ')) };
+    } catch (error) {
+        return {
+            success: false,
+            error: "Input is not valid percent-encoding"
+        };
+    }
+}
+
+/**
+ * Decode a JWT without verifying it
+ * @description Decodes the header and payload of a JSON Web Token; the signature is not verified
+ * @param {string} token - The JWT, e.g. eyJhbGciOi...
+ * @returns {Object} Object containing the header, payload and claim times or error
+ * @callable
+ */
+function jwt_decode(token) {
+    try {
+        if (typeof token !== 'string') {
+            return { error: "Token must be a string", success: false };
+        }
+
+        const parts = token.trim().replace(/^Bearer\s+/i, '').split('.');
+        if (parts.length < 2 || parts.length > 3) {
+            return { error: "A JWT has two or three dot-separated parts", success: false };
+        }
+
+        const decodePart = (part, label) => {
+            const bytes = base64ToBytes(part);
+            const text = bytes === null ? null : utf8Text(bytes);
+            if (text === null) {
+                throw new Error("The " + label + " is not valid base64url");
+            }
+            try {
+                return JSON.parse(text);
+            } catch (e) {
+                throw new Error("The " + label + " is not JSON");
+            }
+        };
+
+        const header = decodePart(parts[0], "header");
+        const payload = decodePart(parts[1], "payload");
+
+        // Registered time claims are seconds since the epoch
+        const times = {};
+        ['exp', 'nbf', 'iat'].forEach(claim => {
+            if (typeof payload[claim] === 'number') {
+                times[claim] = new Date(payload[claim] * 1000).toISOString();
+            }
+        });
+
+        const result = {
+            success: true,
+            header: header,
+            payload: payload,
+            times: times,
+            signed: parts.length === 3 && parts[2] !== '',
+            note: "The signature was not verified"
+        };
+        if (typeof payload.exp === 'number') {
+            result.expired = payload.exp * 1000 < Date.now();
+        }
+        return result;
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Decoding failed"
+        };
+    }
+}
+
+/**
+ * Calculate an IPv4 subnet
+ * @description Calculates the network, broadcast, netmask and host range of an IPv4 CIDR block, and whether it contains an address
+ * @param {string} cidr - The block, e.g. 10.0.12.0/22; a bare address is a /32
+ * @param {string} ip - An address to check against the block (optional)
+ * @returns {Object} Object containing the subnet details or error
+ * @callable
+ */
+function cidr_info(cidr, ip) {
+    try {
+        if (typeof cidr !== 'string') {
+            return { error: "CIDR must be a string", success: false };
+        }
+
+        const parts = cidr.trim().split('/');
+        const address = parseIPv4(parts[0]);
+        const prefix = parts.length === 2 && /^\d{1,2}$/.test(parts[1]) ? parseInt(parts[1], 10) : (parts.length === 1 ? 32 : -1);
+        if (address === null || parts.length > 2 || prefix < 0 || prefix > 32) {
+            return { error: "Expected an IPv4 block like 192.168.1.0/24", success: false };
+        }
+
+        const mask = prefix === 0 ? 0 : (0xffffffff << (32 - prefix)) >>> 0;
+        const network = (address & mask) >>> 0;
+        const broadcast = (network | ~mask) >>> 0;
+        const total = Math.pow(2, 32 - prefix);
+
+        // /31 and /32 have no network or broadcast address to leave out
+        const small = prefix >= 31;
+        const result = {
+            success: true,
+            cidr: formatIPv4(network) + '/' + prefix,
+            network: formatIPv4(network),
+            broadcast: formatIPv4(broadcast),
+            netmask: formatIPv4(mask),
+            wildcard: formatIPv4(~mask >>> 0),
+            first_host: formatIPv4(small ? network : network + 1),
+            last_host: formatIPv4(small ? broadcast : broadcast - 1),
+            total_addresses: total,
+            usable_hosts: small ? total : total - 2,
+            prefix: prefix
+        };
+
+        if (ip) {
+            const other = parseIPv4(String(ip));
+            if (other === null) {
+                return { error: "Not an IPv4 address: " + ip, success: false };
+            }
+            result.ip = formatIPv4(other);
+            result.contains = ((other & mask) >>> 0) === network;
+        }
+        return result;
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Calculation failed"
+        };
+    }
+}
+
+/**
+ * Convert between epoch time and dates
+ * @description Converts epoch seconds or milliseconds to an ISO date in UTC, or a date to epoch time
+ * @param {string} value - Epoch seconds or milliseconds, or a date such as 2024-05-01T12:00:00Z
+ * @returns {Object} Object containing the epoch seconds, milliseconds and ISO date or error
+ * @callable
+ */
+function epoch_convert(value) {
+    try {
+        const text = String(value).trim();
+        let millis;
+        let input;
+        if (/^-?\d+(\.\d+)?$/.test(text)) {
+            // As seconds, values this large would be thousands of years away
+            const n = parseFloat(text);
+            input = Math.abs(n) >= 1e11 ? "milliseconds" : "seconds";
+            millis = input === "milliseconds" ? n : n * 1000;
+        } else {
+            millis = Date.parse(text);
+            input = "date";
+        }
+
+        if (isNaN(millis) || Math.abs(millis) > 8.64e15) {
+            return { error: "Expected epoch seconds, milliseconds or a date", success: false };
+        }
+
+        return {
+            success: true,
+            input: input,
+            seconds: Math.floor(millis / 1000),
+            milliseconds: Math.floor(millis),
+            iso: new Date(millis).toISOString()
+        };
+    } catch (error) {
+        return {
+            success: false,
+            error: error.message || "Conversion failed"
+        };
+    }
+}
+
+// Helpers shared by the functions above
+
+function utf8Bytes(str) {
+    const bytes = [];
+    for (let i = 0; i < str.length; i++) {
+        let c = str.charCodeAt(i);
+        if (c >= 0xd800 && c < 0xdc00 && i + 1 < str.length) {
+            const low = str.charCodeAt(i + 1);
+            if (low >= 0xdc00 && low < 0xe000) {
+                c = 0x10000 + ((c - 0xd800) << 10) + (low - 0xdc00);
+                i++;
+            }
+        }
+        if (c < 0x80) {
+            bytes.push(c);
+        } else if (c < 0x800) {
+            bytes.push(0xc0 | (c >> 6), 0x80 | (c & 63));
+        } else if (c < 0x10000) {
+            bytes.push(0xe0 | (c >> 12), 0x80 | ((c >> 6) & 63), 0x80 | (c & 63));
+        } else {
+            bytes.push(0xf0 | (c >> 18), 0x80 | ((c >> 12) & 63), 0x80 | ((c >> 6) & 63), 0x80 | (c & 63));
+        }
+    }
+    return bytes;
+}
+
+// utf8Text returns the text of UTF-8 bytes, or null if they aren't valid UTF-8
+function utf8Text(bytes) {
+    let text = '';
+    for (let i = 0; i < bytes.length; ) {
+        const b = bytes[i];
+        let c, n;
+        if (b < 0x80) {
+            c = b; n = 0;
+        } else if (b >= 0xc2 && b < 0xe0) {
+            c = b & 31; n = 1;
+        } else if (b >= 0xe0 && b < 0xf0) {
+            c = b & 15; n = 2;
+        } else if (b >= 0xf0 && b < 0xf5) {
+            c = b & 7; n = 3;
+        } else {
+            return null;
+        }
+        if (n > 0 && i + n >= bytes.length) {
+            return null;
+        }
+        for (let k = 1; k <= n; k++) {
+            if ((bytes[i + k] & 0xc0) !== 0x80) {
+                return null;
+            }
+            c = (c << 6) | (bytes[i + k] & 63);
+        }
+        if ((n === 2 && (c < 0x800 || (c >= 0xd800 && c < 0xe000))) || (n === 3 && (c < 0x10000 || c > 0x10ffff))) {
+            return null;
+        }
+        if (c >= 0x10000) {
+            c -= 0x10000;
+            text += String.fromCharCode(0xd800 + (c >> 10), 0xdc00 + (c & 1023));
+        } else {
+            text += String.fromCharCode(c);
+        }
+        i += n + 1;
+    }
+    return text;
+}
+
+function bytesToHex(bytes) {
+    return bytes.map(b => b.toString(16).padStart(2, '0')).join('');
+}
+
+const BASE64_ALPHABET = 'ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/';
+
+function base64FromBytes(bytes) {
+    let out = '';
+    for (let i = 0; i < bytes.length; i += 3) {
+        const n = (bytes[i] << 16) | ((bytes[i + 1] || 0) << 8) | (bytes[i + 2] || 0);
+        out += BASE64_ALPHABET[(n >> 18) & 63] + BASE64_ALPHABET[(n >> 12) & 63];
+        out += i + 1 < bytes.length ? BASE64_ALPHABET[(n >> 6) & 63] : '=';
+        out += i + 2 < bytes.length ? BASE64_ALPHABET[n & 63] : '=';
+    }
+    return out;
+}
+
+// base64ToBytes decodes base64 or base64url, or returns null if it isn't
+function base64ToBytes(text) {
+    const clean = text.replace(/\s+/g, '').replace(/-/g, '+').replace(/_/g, '/').replace(/=+$/, '');
+    if (!/^[A-Za-z0-9+\/]*$/.test(clean) || clean.length % 4 === 1) {
+        return null;
+    }
+    const bytes = [];
+    let bits = 0;
+    let value = 0;
+    for (let i = 0; i < clean.length; i++) {
+        value = (value << 6) | BASE64_ALPHABET.indexOf(clean[i]);
+        bits += 6;
+        if (bits >= 8) {
+            bits -= 8;
+            bytes.push((value >> bits) & 0xff);
+        }
+    }
+    return bytes;
+}
+
+function parseIPv4(text) {
+    const octets = text.trim().split('.');
+    if (octets.length !== 4) {
+        return null;
+    }
+    let address = 0;
+    for (let i = 0; i < 4; i++) {
+        if (!/^\d{1,3}$/.test(octets[i]) || parseInt(octets[i], 10) > 255) {
+            return null;
+        }
+        address = address * 256 + parseInt(octets[i], 10);
+    }
+    return address;
+}
+
+function formatIPv4(address) {
+    return [address >>> 24, (address >>> 16) & 255, (address >>> 8) & 255, address & 255].join('.');
+}
+
+function rotl(x, n) {
+    return (x << n) | (x >>> (32 - n));
+}
+
+function rotr(x, n) {
+    return (x >>> n) | (x << (32 - n));
+}
+
+// padMessage adds the MD5/SHA padding: a 1 bit, zeros, and the length in
+// bits as 64 bits, little-endian for MD5 and big-endian for SHA
+function padMessage(bytes, littleEndian) {
+    const msg = bytes.slice();
+    msg.push(0x80);
+    while (msg.length % 64 !== 56) {
+        msg.push(0);
+    }
+    const bitLength = bytes.length * 8;
+    const high = Math.floor(bitLength / 0x100000000);
+    const low = bitLength >>> 0;
+    const length = [];
+    for (let i = 0; i < 4; i++) {
+        length.push((low >>> (8 * i)) & 255);
+    }
+    for (let i = 0; i < 4; i++) {
+        length.push((high >>> (8 * i)) & 255);
+    }
+    return msg.concat(littleEndian ? length : length.reverse());
+}
+
+function wordsToBytes(words, littleEndian) {
+    const bytes = [];
+    words.forEach(w => {
+        for (let i = 0; i < 4; i++) {
+            bytes.push((w >>> (littleEndian ? 8 * i : 24 - 8 * i)) & 255);
+        }
+    });
+    return bytes;
+}
+
+function md5Bytes(bytes) {
+    const shifts = [7, 12, 17, 22, 5, 9, 14, 20, 4, 11, 16, 23, 6, 10, 15, 21];
+    const K = [];
+    for (let i = 0; i < 64; i++) {
+        K.push(Math.floor(Math.abs(Math.sin(i + 1)) * 0x100000000) | 0);
+    }
+
+    const h = [0x67452301, 0xefcdab89 | 0, 0x98badcfe | 0, 0x10325476];
+    const msg = padMessage(bytes, true);
+    const M = new Array(16);
+    for (let off = 0; off < msg.length; off += 64) {
+        for (let j = 0; j < 16; j++) {
+            M[j] = msg[off + 4 * j] | (msg[off + 4 * j + 1] << 8) | (msg[off + 4 * j + 2] << 16) | (msg[off + 4 * j + 3] << 24);
+        }
+        let [a, b, c, d] = h;
+        for (let i = 0; i < 64; i++) {
+            let f, g;
+            if (i < 16) {
+                f = (b & c) | (~b & d); g = i;
+            } else if (i < 32) {
+                f = (d & b) | (~d & c); g = (5 * i + 1) % 16;
+            } else if (i < 48) {
+                f = b ^ c ^ d; g = (3 * i + 5) % 16;
+            } else {
+                f = c ^ (b | ~d); g = (7 * i) % 16;
+            }
+            f = (f + a + K[i] + M[g]) | 0;
+            a = d;
+            d = c;
+            c = b;
+            b = (b + rotl(f, shifts[(i >> 4) * 4 + (i % 4)])) | 0;
+        }
+        h[0] = (h[0] + a) | 0;
+        h[1] = (h[1] + b) | 0;
+        h[2] = (h[2] + c) | 0;
+        h[3] = (h[3] + d) | 0;
+    }
+    return wordsToBytes(h, true);
+}
+
+function sha1Bytes(bytes) {
+    const h = [0x67452301, 0xefcdab89 | 0, 0x98badcfe | 0, 0x10325476, 0xc3d2e1f0 | 0];
+    const msg = padMessage(bytes, false);
+    const w = new Array(80);
+    for (let off = 0; off < msg.length; off += 64) {
+        for (let t = 0; t < 16; t++) {
+            w[t] = (msg[off + 4 * t] << 24) | (msg[off + 4 * t + 1] << 16) | (msg[off + 4 * t + 2] << 8) | msg[off + 4 * t + 3];
+        }
+        for (let t = 16; t < 80; t++) {
+            w[t] = rotl(w[t - 3] ^ w[t - 8] ^ w[t - 14] ^ w[t - 16], 1);
+        }
+        let [a, b, c, d, e] = h;
+        for (let t = 0; t < 80; t++) {
+            let f, k;
+            if (t < 20) {
+                f = (b & c) | (~b & d); k = 0x5a827999;
+            } else if (t < 40) {
+                f = b ^ c ^ d; k = 0x6ed9eba1;
+            } else if (t < 60) {
+                f = (b & c) | (b & d) | (c & d); k = 0x8f1bbcdc | 0;
+            } else {
+                f = b ^ c ^ d; k = 0xca62c1d6 | 0;
+            }
+            const temp = (rotl(a, 5) + f + e + k + w[t]) | 0;
+            e = d;
+            d = c;
+            c = rotl(b, 30);
+            b = a;
+            a = temp;
+        }
+        h[0] = (h[0] + a) | 0;
+        h[1] = (h[1] + b) | 0;
+        h[2] = (h[2] + c) | 0;
+        h[3] = (h[3] + d) | 0;
+        h[4] = (h[4] + e) | 0;
+    }
+    return wordsToBytes(h, false);
+}
+
+function sha256Bytes(bytes) {
+    const K = [
+        0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+        0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+        0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+        0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+        0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+        0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+        0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+        0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2
+    ];
+    const h = [0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19];
+    const msg = padMessage(bytes, false);
+    const w = new Array(64);
+    for (let off = 0; off < msg.length; off += 64) {
+        for (let t = 0; t < 16; t++) {
+            w[t] = (msg[off + 4 * t] << 24) | (msg[off + 4 * t + 1] << 16) | (msg[off + 4 * t + 2] << 8) | msg[off + 4 * t + 3];
+        }
+        for (let t = 16; t < 64; t++) {
+            const s0 = rotr(w[t - 15], 7) ^ rotr(w[t - 15], 18) ^ (w[t - 15] >>> 3);
+            const s1 = rotr(w[t - 2], 17) ^ rotr(w[t - 2], 19) ^ (w[t - 2] >>> 10);
+            w[t] = (w[t - 16] + s0 + w[t - 7] + s1) | 0;
+        }
+        let [a, b, c, d, e, f, g, hh] = h;
+        for (let t = 0; t < 64; t++) {
+            const S1 = rotr(e, 6) ^ rotr(e, 11) ^ rotr(e, 25);
+            const ch = (e & f) ^ (~e & g);
+            const t1 = (hh + S1 + ch + K[t] + w[t]) | 0;
+            const S0 = rotr(a, 2) ^ rotr(a, 13) ^ rotr(a, 22);
+            const maj = (a & b) ^ (a & c) ^ (b & c);
+            const t2 = (S0 + maj) | 0;
+            hh = g;
+            g = f;
+            f = e;
+            e = (d + t1) | 0;
+            d = c;
+            c = b;
+            b = a;
+            a = (t1 + t2) | 0;
+        }
+        h[0] = (h[0] + a) | 0;
+        h[1] = (h[1] + b) | 0;
+        h[2] = (h[2] + c) | 0;
+        h[3] = (h[3] + d) | 0;
+        h[4] = (h[4] + e) | 0;
+        h[5] = (h[5] + f) | 0;
+        h[6] = (h[6] + g) | 0;
+        h[7] = (h[7] + hh) | 0;
+    }
+    return wordsToBytes(h, false);
+}
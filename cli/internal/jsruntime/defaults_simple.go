@@ -1,5 +1,12 @@
 package jsruntime
 
+import (
+	"time"
+
+	"github.com/hacka-re/cli/internal/osint"
+	"github.com/hacka-re/cli/internal/reputation"
+)
+
 // SimplifiedDefaults provides a simplified way to load default functions
 // Each function gets the entire code block so all helper functions are available
 
@@ -102,9 +109,247 @@ func LoadMathDefaults(registry *Registry) error {
 	return nil
 }
 
+// LoadSecurityDefaults loads the security utility pack. The functions share
+// helpers, so like the others each one gets the whole file.
+func LoadSecurityDefaults(registry *Registry) error {
+	functions := []*Function{
+		{
+			Name:        "hash_text",
+			Description: "Hashes the UTF-8 bytes of a text with md5, sha1 or sha256 (the default)",
+			Parameters: []Parameter{
+				{Name: "text", Type: "string", Description: "The text to hash", Required: true},
+				{Name: "algorithm", Type: "string", Description: "md5, sha1 or sha256"},
+			},
+			Tags: []string{"security", "crypto"},
+		},
+		{
+			Name:        "base64_encode",
+			Description: "Encodes the UTF-8 bytes of a text as base64, or unpadded base64url",
+			Parameters: []Parameter{
+				{Name: "text", Type: "string", Description: "The text to encode", Required: true},
+				{Name: "url_safe", Type: "boolean", Description: "Use the URL-safe alphabet without padding"},
+			},
+			Tags: []string{"security", "encoding"},
+		},
+		{
+			Name:        "base64_decode",
+			Description: "Decodes base64 or base64url, with or without padding, to text and hex",
+			Parameters: []Parameter{
+				{Name: "encoded", Type: "string", Description: "The base64 to decode", Required: true},
+			},
+			Tags: []string{"security", "encoding"},
+		},
+		{
+			Name:        "url_encode",
+			Description: "Percent-encodes text for use in a URL query or path component",
+			Parameters: []Parameter{
+				{Name: "text", Type: "string", Description: "The text to encode", Required: true},
+			},
+			Tags: []string{"security", "encoding"},
+		},
+		{
+			Name:        "url_decode",
+			Description: "Decodes percent-encoded text from a URL; + is read as a space",
+			Parameters: []Parameter{
+				{Name: "encoded", Type: "string", Description: "The text to decode", Required: true},
+			},
+			Tags: []string{"security", "encoding"},
+		},
+		{
+			Name:        "jwt_decode",
+			Description: "Decodes the header and payload of a JSON Web Token; the signature is not verified",
+			Parameters: []Parameter{
+				{Name: "token", Type: "string", Description: "The JWT, e.g. eyJhbGciOi...", Required: true},
+			},
+			Tags: []string{"security", "web"},
+		},
+		{
+			Name:        "cidr_info",
+			Description: "Calculates the network, broadcast, netmask and host range of an IPv4 CIDR block, and whether it contains an address",
+			Parameters: []Parameter{
+				{Name: "cidr", Type: "string", Description: "The block, e.g. 10.0.12.0/22; a bare address is a /32", Required: true},
+				{Name: "ip", Type: "string", Description: "An address to check against the block (optional)"},
+			},
+			Tags: []string{"security", "network"},
+		},
+		{
+			Name:        "epoch_convert",
+			Description: "Converts epoch seconds or milliseconds to an ISO date in UTC, or a date to epoch time",
+			Parameters: []Parameter{
+				{Name: "value", Type: "string", Description: "Epoch seconds or milliseconds, or a date such as 2024-05-01T12:00:00Z", Required: true},
+			},
+			Tags: []string{"security", "time"},
+		},
+	}
+
+	for _, fn := range functions {
+		fn.Code = defaultSecurityFunctions
+		fn.Returns = "Object"
+		fn.IsCallable = true
+		fn.GroupID = "security-utilities"
+		if err := registry.AddOrReplace(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadScanDefaults loads the functions that query scans and packet captures
+// ingested with hacka.re ingest, through the scanData and captureData objects
+// of the sandbox
+func LoadScanDefaults(registry *Registry) error {
+	scanParameter := Parameter{Name: "scan", Type: "string", Description: "Name of the scan; the latest one if left out"}
+	functions := []*Function{
+		{
+			Name:        "scan_list",
+			Description: "Lists the Nmap and masscan scans ingested with hacka.re ingest, latest first",
+		},
+		{
+			Name:        "scan_summary",
+			Description: "Counts the hosts and open ports of a scan and lists the most common open ports",
+			Parameters:  []Parameter{scanParameter},
+		},
+		{
+			Name:        "scan_find",
+			Description: "Finds the hosts with open ports matching a port number, service, product or network",
+			Parameters: []Parameter{
+				{Name: "port", Type: "number", Description: "Port number, e.g. 443"},
+				{Name: "service", Type: "string", Description: "Part of the service name, e.g. http or ssh"},
+				{Name: "product", Type: "string", Description: "Part of the product name, e.g. nginx or OpenSSH"},
+				{Name: "network", Type: "string", Description: "Only hosts in this CIDR block, e.g. 10.0.0.0/24"},
+				scanParameter,
+			},
+		},
+		{
+			Name:        "scan_host",
+			Description: "Shows every port, service, banner and the OS guess of one scanned host",
+			Parameters: []Parameter{
+				{Name: "address", Type: "string", Description: "IP address or hostname of the host", Required: true},
+				scanParameter,
+			},
+		},
+		{
+			Name:        "capture_list",
+			Description: "Lists the packet captures ingested with hacka.re ingest pcap, latest first",
+		},
+		{
+			Name:        "capture_summary",
+			Description: "Shows the busiest hosts, largest flows, DNS lookups and HTTP requests of a packet capture",
+			Parameters: []Parameter{
+				{Name: "capture", Type: "string", Description: "Name of the capture; the latest one if left out"},
+			},
+		},
+	}
+
+	for _, fn := range functions {
+		fn.Code = defaultScanFunctions
+		fn.Returns = "Object"
+		fn.IsCallable = true
+		fn.GroupID = "scan-data"
+		fn.Tags = []string{"security", "network", "scan"}
+		if err := registry.AddOrReplace(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadCVEDefaults loads cve_lookup, which reads the NVD through the cve package
+func LoadCVEDefaults(registry *Registry) error {
+	return registry.AddOrReplace(&Function{
+		Name:        "cve_lookup",
+		Code:        defaultCVEFunctions,
+		Description: "Looks up a CVE in the National Vulnerability Database: CVSS scores, severity, weaknesses and references",
+		Parameters: []Parameter{
+			{Name: "cve_id", Type: "string", Description: "The CVE ID, e.g. CVE-2021-44228", Required: true},
+		},
+		Returns:    "Object",
+		IsCallable: true,
+		GroupID:    "cve-lookup",
+		Tags:       []string{"security", "vulnerability"},
+	})
+}
+
+// LoadOSINTDefaults loads the Have I Been Pwned and crt.sh lookups. They wait
+// for the rate limits of the services, so they get a longer timeout.
+func LoadOSINTDefaults(registry *Registry) error {
+	functions := []*Function{
+		{
+			Name:        "breach_check",
+			Description: "Lists the data breaches an email address or username appears in, from Have I Been Pwned",
+			Parameters: []Parameter{
+				{Name: "account", Type: "string", Description: "The email address or username to check", Required: true},
+			},
+			Tags: []string{"security", "osint", "breach"},
+		},
+		{
+			Name:        "pwned_password",
+			Description: "Counts how often a password appears in breaches (Pwned Passwords); only a hash prefix is sent",
+			Parameters: []Parameter{
+				{Name: "password", Type: "string", Description: "The password to check", Required: true},
+			},
+			Tags: []string{"security", "osint", "breach"},
+		},
+		{
+			Name:        "crtsh_lookup",
+			Description: "Lists the hostnames and issuers of certificates logged for a domain and its subdomains (crt.sh certificate transparency)",
+			Parameters: []Parameter{
+				{Name: "domain", Type: "string", Description: "The domain, e.g. example.com", Required: true},
+				{Name: "include_expired", Type: "boolean", Description: "Include expired certificates"},
+			},
+			Tags: []string{"security", "osint", "recon"},
+		},
+	}
+
+	for _, fn := range functions {
+		fn.Code = defaultOSINTFunctions
+		fn.Returns = "Object"
+		fn.IsCallable = true
+		fn.GroupID = "osint-lookups"
+		fn.Timeout = osint.RequestTimeout + 5*time.Second
+		if err := registry.AddOrReplace(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadReputationDefaults loads url_reputation, which asks several services
+// and so gets a longer timeout
+func LoadReputationDefaults(registry *Registry) error {
+	return registry.AddOrReplace(&Function{
+		Name:        "url_reputation",
+		Code:        defaultReputationFunctions,
+		Description: "Asks VirusTotal and urlscan.io about a URL, domain or IP address and returns a verdict: malicious, suspicious, harmless or unknown",
+		Parameters: []Parameter{
+			{Name: "target", Type: "string", Description: "A URL with its scheme, a domain or an IP address", Required: true},
+		},
+		Returns:    "Object",
+		IsCallable: true,
+		GroupID:    "url-reputation",
+		Tags:       []string{"security", "osint", "web"},
+		Timeout:    reputation.RequestTimeout + 5*time.Second,
+	})
+}
+
 func LoadSimplifiedDefaults(registry *Registry) error {
 	if err := LoadRC4Defaults(registry); err != nil {
 		return err
 	}
-	return LoadMathDefaults(registry)
+	if err := LoadMathDefaults(registry); err != nil {
+		return err
+	}
+	if err := LoadSecurityDefaults(registry); err != nil {
+		return err
+	}
+	if err := LoadScanDefaults(registry); err != nil {
+		return err
+	}
+	if err := LoadCVEDefaults(registry); err != nil {
+		return err
+	}
+	if err := LoadOSINTDefaults(registry); err != nil {
+		return err
+	}
+	return LoadReputationDefaults(registry)
 }
\ No newline at end of file
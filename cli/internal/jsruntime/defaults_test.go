@@ -0,0 +1,400 @@
+package jsruntime
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hacka-re/cli/internal/cve"
+	"github.com/hacka-re/cli/internal/osint"
+	"github.com/hacka-re/cli/internal/pcap"
+	"github.com/hacka-re/cli/internal/reputation"
+	"github.com/hacka-re/cli/internal/scans"
+)
+
+// callDefault runs a default function and returns its result object
+func callDefault(t *testing.T, registry *Registry, name string, args map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	result, err := registry.Execute(name, args)
+	if err != nil {
+		t.Fatalf("%s(%v): %v", name, args, err)
+	}
+	m, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatalf("%s(%v) = %v, want an object", name, args, result)
+	}
+	return m
+}
+
+func TestSecurityHashes(t *testing.T) {
+	registry := NewRegistry()
+	if err := LoadDefaultFunctions(registry, "security-utilities"); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []string{"", "abc", "hacka.re ✓ 😀", strings.Repeat("a", 55), strings.Repeat("b", 56), strings.Repeat("c", 1000)}
+	for _, input := range inputs {
+		want := map[string]string{
+			"md5":    digest(md5.New(), input),
+			"sha1":   digest(sha1.New(), input),
+			"sha256": digest(sha256.New(), input),
+		}
+		for algorithm, digest := range want {
+			got := callDefault(t, registry, "hash_text", map[string]interface{}{"text": input, "algorithm": algorithm})
+			if got["hex"] != digest {
+				t.Errorf("%s(%.20q) = %v, want %s", algorithm, input, got["hex"], digest)
+			}
+		}
+	}
+
+	// sha256 is the default, and unknown algorithms are refused
+	got := callDefault(t, registry, "hash_text", map[string]interface{}{"text": "abc"})
+	if got["algorithm"] != "sha256" {
+		t.Errorf("default algorithm = %v", got["algorithm"])
+	}
+	got = callDefault(t, registry, "hash_text", map[string]interface{}{"text": "abc", "algorithm": "crc32"})
+	if got["success"] != false {
+		t.Errorf("crc32 = %v, want an error", got)
+	}
+}
+
+func digest(h hash.Hash, input string) string {
+	h.Write([]byte(input))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestSecurityEncoding(t *testing.T) {
+	registry := NewRegistry()
+	if err := LoadSecurityDefaults(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{"", "f", "fo", "foo", "hej då ?&/", "😀 >>>"} {
+		got := callDefault(t, registry, "base64_encode", map[string]interface{}{"text": input})
+		if want := base64.StdEncoding.EncodeToString([]byte(input)); got["encoded"] != want {
+			t.Errorf("base64_encode(%q) = %v, want %s", input, got["encoded"], want)
+		}
+		got = callDefault(t, registry, "base64_encode", map[string]interface{}{"text": input, "url_safe": true})
+		urlSafe := base64.RawURLEncoding.EncodeToString([]byte(input))
+		if got["encoded"] != urlSafe {
+			t.Errorf("base64_encode(%q, url_safe) = %v, want %s", input, got["encoded"], urlSafe)
+		}
+		got = callDefault(t, registry, "base64_decode", map[string]interface{}{"encoded": urlSafe})
+		if input != "" && got["text"] != input {
+			t.Errorf("base64_decode(%s) = %v, want %q", urlSafe, got, input)
+		}
+	}
+
+	got := callDefault(t, registry, "base64_decode", map[string]interface{}{"encoded": "/w=="})
+	if got["hex"] != "ff" || got["text"] != nil {
+		t.Errorf("base64_decode of a non-UTF-8 byte = %v", got)
+	}
+	got = callDefault(t, registry, "base64_decode", map[string]interface{}{"encoded": "not base64!"})
+	if got["success"] != false {
+		t.Errorf("invalid base64 = %v, want an error", got)
+	}
+
+	got = callDefault(t, registry, "url_encode", map[string]interface{}{"text": "a b&c=ä"})
+	if got["encoded"] != "a%20b%26c%3D%C3%A4" {
+		t.Errorf("url_encode = %v", got["encoded"])
+	}
+	got = callDefault(t, registry, "url_decode", map[string]interface{}{"encoded": "a+b%26c%3D%C3%A4"})
+	if got["text"] != "a b&c=ä" {
+		t.Errorf("url_decode = %v", got["text"])
+	}
+	got = callDefault(t, registry, "url_decode", map[string]interface{}{"encoded": "%E0%A4%A"})
+	if got["success"] != false {
+		t.Errorf("invalid percent-encoding = %v, want an error", got)
+	}
+}
+
+func TestSecurityJWT(t *testing.T) {
+	registry := NewRegistry()
+	if err := LoadSecurityDefaults(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	part := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	token := part(`{"alg":"HS256","typ":"JWT"}`) + "." + part(`{"sub":"1234","name":"Åsa","exp":1700000000}`) + ".c2ln"
+
+	got := callDefault(t, registry, "jwt_decode", map[string]interface{}{"token": "Bearer " + token})
+	header, _ := got["header"].(map[string]interface{})
+	payload, _ := got["payload"].(map[string]interface{})
+	times, _ := got["times"].(map[string]interface{})
+	if header["alg"] != "HS256" || payload["name"] != "Åsa" {
+		t.Errorf("jwt_decode = %v", got)
+	}
+	if times["exp"] != "2023-11-14T22:13:20.000Z" || got["expired"] != true || got["signed"] != true {
+		t.Errorf("jwt_decode times = %v, expired = %v, signed = %v", times, got["expired"], got["signed"])
+	}
+
+	got = callDefault(t, registry, "jwt_decode", map[string]interface{}{"token": part("nope") + "." + part("{}")})
+	if got["success"] != false || !strings.Contains(got["error"].(string), "header") {
+		t.Errorf("bad header = %v, want an error about the header", got)
+	}
+}
+
+func TestSecurityCIDR(t *testing.T) {
+	registry := NewRegistry()
+	if err := LoadSecurityDefaults(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	got := callDefault(t, registry, "cidr_info", map[string]interface{}{"cidr": "10.0.13.7/22", "ip": "10.0.15.255"})
+	want := map[string]interface{}{
+		"cidr":            "10.0.12.0/22",
+		"network":         "10.0.12.0",
+		"broadcast":       "10.0.15.255",
+		"netmask":         "255.255.252.0",
+		"wildcard":        "0.0.3.255",
+		"first_host":      "10.0.12.1",
+		"last_host":       "10.0.15.254",
+		"total_addresses": int64(1024),
+		"usable_hosts":    int64(1022),
+		"contains":        true,
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("cidr_info %s = %v (%T), want %v", key, got[key], got[key], value)
+		}
+	}
+
+	got = callDefault(t, registry, "cidr_info", map[string]interface{}{"cidr": "192.168.1.1/31", "ip": "192.168.2.1"})
+	if got["usable_hosts"] != int64(2) || got["first_host"] != "192.168.1.0" || got["contains"] != false {
+		t.Errorf("cidr_info /31 = %v", got)
+	}
+	got = callDefault(t, registry, "cidr_info", map[string]interface{}{"cidr": "0.0.0.0/0"})
+	if got["broadcast"] != "255.255.255.255" || got["total_addresses"] != int64(4294967296) {
+		t.Errorf("cidr_info /0 = %v", got)
+	}
+	for _, bad := range []string{"10.0.0.256/8", "10.0.0.0/33", "10.0.0/8", "10.0.0.0/"} {
+		if got := callDefault(t, registry, "cidr_info", map[string]interface{}{"cidr": bad}); got["success"] != false {
+			t.Errorf("cidr_info(%s) = %v, want an error", bad, got)
+		}
+	}
+}
+
+func TestSecurityEpoch(t *testing.T) {
+	registry := NewRegistry()
+	if err := LoadSecurityDefaults(registry); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value interface{}
+		iso   string
+		input string
+	}{
+		{int64(1700000000), "2023-11-14T22:13:20.000Z", "seconds"},
+		{"1700000000123", "2023-11-14T22:13:20.123Z", "milliseconds"},
+		{"2024-05-01T12:00:00Z", "2024-05-01T12:00:00.000Z", "date"},
+	}
+	for _, tt := range tests {
+		got := callDefault(t, registry, "epoch_convert", map[string]interface{}{"value": tt.value})
+		if got["iso"] != tt.iso || got["input"] != tt.input {
+			t.Errorf("epoch_convert(%v) = %v, want %s from %s", tt.value, got, tt.iso, tt.input)
+		}
+	}
+	got := callDefault(t, registry, "epoch_convert", map[string]interface{}{"value": "2024-05-01T12:00:00Z"})
+	if got["seconds"] != int64(1714564800) {
+		t.Errorf("seconds = %v (%T)", got["seconds"], got["seconds"])
+	}
+	if got := callDefault(t, registry, "epoch_convert", map[string]interface{}{"value": "yesterday-ish"}); got["success"] != false {
+		t.Errorf("unparseable date = %v, want an error", got)
+	}
+}
+
+func TestScanFunctions(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HACKARE_SCANS_DIR", dir)
+	scan, err := scans.ParseMasscan([]byte(`{"ip": "10.0.0.1", "timestamp": "1700000000", "ports": [{"port": 22, "proto": "tcp", "status": "open"}]}
+{"ip": "10.0.0.2", "timestamp": "1700000000", "ports": [{"port": 443, "proto": "tcp", "status": "open", "service": {"name": "https", "banner": "nginx"}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scan.Name = "lab"
+	if err := scans.Save(dir, scan); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewRegistry()
+	if err := LoadEnabledDefaults(registry, map[string]bool{"scan-data": true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Get("hash_text"); err == nil {
+		t.Error("a group that isn't enabled was loaded")
+	}
+
+	got := callDefault(t, registry, "scan_list", nil)
+	if got["count"] != int64(1) {
+		t.Errorf("scan_list = %v", got)
+	}
+	got = callDefault(t, registry, "scan_summary", map[string]interface{}{})
+	summary, _ := got["summary"].(map[string]interface{})
+	if summary["name"] != "lab" || summary["openPorts"] != float64(2) {
+		t.Errorf("scan_summary = %v", got)
+	}
+	got = callDefault(t, registry, "scan_find", map[string]interface{}{"service": "HTTPS", "scan": "lab"})
+	hosts, _ := got["hosts"].([]interface{})
+	if got["host_count"] != int64(1) || len(hosts) != 1 || hosts[0].(map[string]interface{})["address"] != "10.0.0.2" {
+		t.Errorf("scan_find = %v", got)
+	}
+	got = callDefault(t, registry, "scan_find", map[string]interface{}{"port": 3389})
+	if got["success"] != true || got["host_count"] != int64(0) {
+		t.Errorf("scan_find with no matches = %v", got)
+	}
+	got = callDefault(t, registry, "scan_host", map[string]interface{}{"address": "10.0.0.1"})
+	host, _ := got["host"].(map[string]interface{})
+	if host["address"] != "10.0.0.1" {
+		t.Errorf("scan_host = %v", got)
+	}
+	got = callDefault(t, registry, "scan_host", map[string]interface{}{"address": "10.9.9.9"})
+	if got["success"] != false {
+		t.Errorf("scan_host of an unknown host = %v, want an error", got)
+	}
+	got = callDefault(t, registry, "scan_summary", map[string]interface{}{"scan": "missing"})
+	if got["success"] != false || !strings.Contains(got["error"].(string), "missing") {
+		t.Errorf("scan_summary of a missing scan = %v, want an error", got)
+	}
+}
+
+func TestCaptureFunctions(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HACKARE_CAPTURES_DIR", dir)
+
+	registry := NewRegistry()
+	if err := LoadDefaultFunctions(registry, "scan-data"); err != nil {
+		t.Fatal(err)
+	}
+	got := callDefault(t, registry, "capture_list", nil)
+	if got["success"] != true || got["count"] != int64(0) {
+		t.Errorf("capture_list of no captures = %v", got)
+	}
+	if got := callDefault(t, registry, "capture_summary", nil); got["success"] != false {
+		t.Errorf("capture_summary of no captures = %v, want an error", got)
+	}
+
+	capture := &pcap.Capture{
+		Name:      "incident",
+		Ingested:  time.Now(),
+		Packets:   12,
+		FlowCount: 1,
+		Flows:     []pcap.Flow{{Protocol: "tcp", Client: "10.0.0.5:50000", Server: "93.184.216.34:80", Packets: 12}},
+		DNS:       []pcap.DNSName{{Name: "example.com", Types: []string{"A"}, Queries: 1}},
+	}
+	if err := pcap.Save(dir, capture); err != nil {
+		t.Fatal(err)
+	}
+	got = callDefault(t, registry, "capture_list", nil)
+	if got["count"] != int64(1) {
+		t.Errorf("capture_list = %v", got)
+	}
+	got = callDefault(t, registry, "capture_summary", map[string]interface{}{"capture": "incident"})
+	summary, _ := got["summary"].(map[string]interface{})
+	flows, _ := summary["flows"].([]interface{})
+	if summary["packets"] != float64(12) || len(flows) != 1 || flows[0].(map[string]interface{})["server"] != "93.184.216.34:80" {
+		t.Errorf("capture_summary = %v", got)
+	}
+}
+
+func TestCVELookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"vulnerabilities": [{"cve": {"id": "CVE-2014-0160", "cisaExploitAdd": "2022-05-04",
+			"descriptions": [{"lang": "en", "value": "Heartbleed"}],
+			"metrics": {"cvssMetricV31": [{"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"version": "3.1", "baseScore": 7.5, "baseSeverity": "HIGH"}}]}}}]}`))
+	}))
+	defer server.Close()
+	client := cve.NewClient("", false)
+	client.BaseURL = server.URL
+	client.CacheDir = t.TempDir()
+	cve.SetDefault(client)
+	defer cve.SetDefault(nil)
+
+	registry := NewRegistry()
+	if err := LoadDefaultFunctions(registry, "cve"); err != nil {
+		t.Fatal(err)
+	}
+	got := callDefault(t, registry, "cve_lookup", map[string]interface{}{"cve_id": "cve-2014-0160"})
+	cvss, _ := got["cvss"].(map[string]interface{})
+	if got["id"] != "CVE-2014-0160" || got["known_exploited"] != true || cvss["score"] != 7.5 || cvss["severity"] != "HIGH" {
+		t.Errorf("cve_lookup = %v", got)
+	}
+
+	client.Offline = true
+	got = callDefault(t, registry, "cve_lookup", map[string]interface{}{"cve_id": "CVE-2014-0160"})
+	if got["cached"] != true {
+		t.Errorf("offline cve_lookup of a cached CVE = %v", got)
+	}
+	got = callDefault(t, registry, "cve_lookup", map[string]interface{}{"cve_id": "CVE-2021-44228"})
+	if got["success"] != false || !strings.Contains(got["error"].(string), "offline") {
+		t.Errorf("offline cve_lookup of an uncached CVE = %v, want an error", got)
+	}
+}
+
+func TestOSINTLookups(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 7, "issuer_name": "O=Let's Encrypt, CN=R3", "common_name": "example.com", "name_value": "example.com\nwww.example.com", "not_before": "2024-01-01T00:00:00"}]`))
+	}))
+	defer server.Close()
+	client := osint.NewClient("", false)
+	client.CrtShURL = server.URL
+	osint.SetDefault(client)
+	defer osint.SetDefault(nil)
+
+	registry := NewRegistry()
+	if err := LoadEnabledDefaults(registry, map[string]bool{"osint-lookups": true}); err != nil {
+		t.Fatal(err)
+	}
+	got := callDefault(t, registry, "crtsh_lookup", map[string]interface{}{"domain": "example.com"})
+	names, _ := got["names"].([]interface{})
+	if got["certificates"] != int64(1) || len(names) != 2 || got["more_names"] != int64(0) {
+		t.Errorf("crtsh_lookup = %v", got)
+	}
+
+	// Account checks need an API key, and offline mode blocks every lookup
+	got = callDefault(t, registry, "breach_check", map[string]interface{}{"account": "alice@example.com"})
+	if got["success"] != false || !strings.Contains(got["error"].(string), "HACKARE_HIBP_API_KEY") {
+		t.Errorf("breach_check without an API key = %v, want an error", got)
+	}
+	client.Offline = true
+	got = callDefault(t, registry, "pwned_password", map[string]interface{}{"password": "hunter2"})
+	if got["success"] != false || !strings.Contains(got["error"].(string), "offline") {
+		t.Errorf("offline pwned_password = %v, want an error", got)
+	}
+}
+
+func TestURLReputation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"attributes": {"last_analysis_stats": {"malicious": 5, "harmless": 50}}}}`))
+	}))
+	defer server.Close()
+	checker := &reputation.Checker{Backends: []reputation.Backend{
+		&reputation.VirusTotal{BaseURL: server.URL, APIKey: "key", HTTPClient: server.Client()},
+	}}
+	reputation.SetDefault(checker)
+	defer reputation.SetDefault(nil)
+
+	registry := NewRegistry()
+	if err := LoadDefaultFunctions(registry, "url-reputation"); err != nil {
+		t.Fatal(err)
+	}
+	got := callDefault(t, registry, "url_reputation", map[string]interface{}{"target": "https://evil.example/login"})
+	results, _ := got["results"].([]interface{})
+	if got["verdict"] != "malicious" || got["kind"] != "url" || len(results) != 1 {
+		t.Errorf("url_reputation = %v", got)
+	}
+
+	checker.Offline = true
+	got = callDefault(t, registry, "url_reputation", map[string]interface{}{"target": "evil.example"})
+	if got["success"] != false || !strings.Contains(got["error"].(string), "offline") {
+		t.Errorf("offline url_reputation = %v, want an error", got)
+	}
+}
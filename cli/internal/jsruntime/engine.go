@@ -2,10 +2,19 @@ package jsruntime
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dop251/goja"
+	"github.com/hacka-re/cli/internal/artifacts"
+	"github.com/hacka-re/cli/internal/cve"
+	"github.com/hacka-re/cli/internal/osint"
+	"github.com/hacka-re/cli/internal/pcap"
+	"github.com/hacka-re/cli/internal/reputation"
+	"github.com/hacka-re/cli/internal/scans"
 )
 
 // Engine wraps the Goja JavaScript runtime
@@ -70,6 +79,16 @@ func (e *Engine) Execute(code string) (interface{}, error) {
 
 // ExecuteFunction runs a specific JavaScript function with arguments
 func (e *Engine) ExecuteFunction(functionCode string, functionName string, args map[string]interface{}) (interface{}, error) {
+	values := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg)
+	}
+	return e.ExecuteFunctionArgs(functionCode, functionName, values)
+}
+
+// ExecuteFunctionArgs runs a specific JavaScript function with positional
+// arguments; nil is passed as undefined, so optional parameters can be skipped
+func (e *Engine) ExecuteFunctionArgs(functionCode string, functionName string, args []interface{}) (interface{}, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
 	defer cancel()
 
@@ -101,9 +120,13 @@ func (e *Engine) ExecuteFunction(functionCode string, functionName string, args
 		}
 
 		// Convert args to goja values
-		gojaArgs := make([]goja.Value, 0)
+		gojaArgs := make([]goja.Value, 0, len(args))
 		for _, arg := range args {
-			gojaArgs = append(gojaArgs, vm.ToValue(arg))
+			if arg == nil {
+				gojaArgs = append(gojaArgs, goja.Undefined())
+			} else {
+				gojaArgs = append(gojaArgs, vm.ToValue(arg))
+			}
 		}
 
 		// Call the function
@@ -176,5 +199,152 @@ func (e *Engine) setupSandbox(vm *goja.Runtime) error {
 	// Add Error constructor for proper error handling
 	vm.Set("Error", vm.Get("Error"))
 
+	// Let functions keep files they produce, in this session's artifacts
+	// directory. Content given as a base64 data: URL is stored decoded.
+	vm.Set("saveArtifact", func(name string, content string) string {
+		data := []byte(content)
+		if header, encoded, ok := strings.Cut(content, ","); ok &&
+			strings.HasPrefix(header, "data:") && strings.HasSuffix(header, ";base64") {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				panic(vm.NewGoError(fmt.Errorf("saveArtifact: invalid base64 data: %w", err)))
+			}
+			data = decoded
+		}
+		path, err := artifacts.Current().Save(name, data)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("saveArtifact: %w", err)))
+		}
+		return path
+	})
+
+	// Let the scan-data functions read scans ingested with hacka.re ingest.
+	// Results pass through JSON so objects have the same keys as the files.
+	scanData := vm.NewObject()
+	loadScan := func(name string) *scans.Scan {
+		scan, err := scans.Load(scans.Dir(), name)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("scanData: %w", err)))
+		}
+		return scan
+	}
+	scanData.Set("list", func() interface{} {
+		list, err := scans.List(scans.Dir())
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("scanData: %w", err)))
+		}
+		return jsonValue(vm, list)
+	})
+	scanData.Set("summary", func(name string) interface{} {
+		return jsonValue(vm, loadScan(name).Summary())
+	})
+	scanData.Set("find", func(port int, service, product, network, name string) interface{} {
+		hosts, err := loadScan(name).Find(scans.Query{Port: port, Service: service, Product: product, Network: network})
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("scanData: %w", err)))
+		}
+		return jsonValue(vm, hosts)
+	})
+	scanData.Set("host", func(address, name string) interface{} {
+		host, ok := loadScan(name).Host(address)
+		if !ok {
+			return nil
+		}
+		return jsonValue(vm, host)
+	})
+	vm.Set("scanData", scanData)
+
+	// Let the capture functions read packet captures ingested with hacka.re
+	// ingest pcap
+	captureData := vm.NewObject()
+	captureData.Set("list", func() interface{} {
+		list, err := pcap.List(pcap.Dir())
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("captureData: %w", err)))
+		}
+		return jsonValue(vm, list)
+	})
+	captureData.Set("summary", func(name string) interface{} {
+		capture, err := pcap.Load(pcap.Dir(), name)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("captureData: %w", err)))
+		}
+		return jsonValue(vm, capture)
+	})
+	vm.Set("captureData", captureData)
+
+	// Let cve_lookup ask the NVD, through the local cache; offline mode only
+	// reads the cache
+	vm.Set("cveLookup", func(id string) interface{} {
+		record, err := cve.Default().Lookup(context.Background(), id)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("cveLookup: %w", err)))
+		}
+		return jsonValue(vm, record)
+	})
+
+	// Let the OSINT functions query Have I Been Pwned and crt.sh, within
+	// their rate limits; offline mode refuses every request
+	osintLookups := vm.NewObject()
+	osintContext := func() (context.Context, context.CancelFunc) {
+		return context.WithTimeout(context.Background(), osint.RequestTimeout)
+	}
+	osintLookups.Set("breachedAccount", func(account string) interface{} {
+		ctx, cancel := osintContext()
+		defer cancel()
+		breaches, err := osint.Default().BreachedAccount(ctx, account)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("osint: %w", err)))
+		}
+		return jsonValue(vm, breaches)
+	})
+	osintLookups.Set("pwnedPassword", func(password string) int {
+		ctx, cancel := osintContext()
+		defer cancel()
+		count, err := osint.Default().PwnedPassword(ctx, password)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("osint: %w", err)))
+		}
+		return count
+	})
+	osintLookups.Set("certificates", func(domain string, includeExpired bool) interface{} {
+		ctx, cancel := osintContext()
+		defer cancel()
+		report, err := osint.Default().CertificateTransparency(ctx, domain, includeExpired)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("osint: %w", err)))
+		}
+		return jsonValue(vm, report)
+	})
+	vm.Set("osint", osintLookups)
+
+	// Let url_reputation ask VirusTotal and urlscan.io, with the keys set up
+	// by reputation.Init
+	vm.Set("reputationCheck", func(target string) interface{} {
+		ctx, cancel := context.WithTimeout(context.Background(), reputation.RequestTimeout)
+		defer cancel()
+		report, err := reputation.Default().Check(ctx, target)
+		if err != nil {
+			panic(vm.NewGoError(fmt.Errorf("reputation: %w", err)))
+		}
+		return jsonValue(vm, report)
+	})
+
 	return nil
+}
+
+// jsonValue converts v to plain JavaScript values by way of its JSON encoding
+func jsonValue(vm *goja.Runtime, v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(vm.NewGoError(err))
+	}
+	var plain interface{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		panic(vm.NewGoError(err))
+	}
+	if plain == nil {
+		return []interface{}{} // A nil slice is an empty list, not null
+	}
+	return plain
 }
\ No newline at end of file
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/tags"
 )
 
 // Function represents a JavaScript function with metadata
@@ -17,7 +20,12 @@ type Function struct {
 	IsCallable  bool                   `json:"callable"`
 	IsTool      bool                   `json:"tool"`
 	GroupID     string                 `json:"groupId,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	// Timeout replaces the engine's default limit, for functions that make
+	// slow network requests
+	Timeout time.Duration `json:"-"`
 }
 
 // Parameter represents a function parameter
@@ -100,6 +108,12 @@ func ParseFunction(code string) (*Function, error) {
 			fn.Returns = strings.TrimSpace(matches[1])
 		}
 
+		// Parse @tags (or @tag) for filtering, e.g. "@tags recon, web"
+		tagRegex := regexp.MustCompile(`\*\s*@tags?\s+(.+)`)
+		for _, matches := range tagRegex.FindAllStringSubmatch(jsdoc, -1) {
+			fn.Tags = tags.Normalize(append(fn.Tags, tags.Parse(matches[1])...))
+		}
+
 		// Check for @callable tag
 		if strings.Contains(jsdoc, "@callable") {
 			fn.IsCallable = true
@@ -198,7 +212,19 @@ func (f *Function) Validate() error {
 // Execute runs the function with the given arguments
 func (f *Function) Execute(args map[string]interface{}) (interface{}, error) {
 	engine := NewEngine()
-	return engine.ExecuteFunction(f.Code, f.Name, args)
+	if f.Timeout > 0 {
+		engine.SetTimeout(f.Timeout)
+	}
+	if len(f.Parameters) == 0 {
+		return engine.ExecuteFunction(f.Code, f.Name, args)
+	}
+
+	// Pass the arguments in the order the function declares them
+	values := make([]interface{}, len(f.Parameters))
+	for i, param := range f.Parameters {
+		values[i] = args[param.Name]
+	}
+	return engine.ExecuteFunctionArgs(f.Code, f.Name, values)
 }
 
 // ToJSON serializes the function to JSON
@@ -1,6 +1,7 @@
 package jsruntime
 
 import (
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -319,4 +320,47 @@ func TestDefaultFunctions(t *testing.T) {
 	} else {
 		t.Error("Result is not a map")
 	}
-}
\ No newline at end of file
+}
+func TestFunctionTags(t *testing.T) {
+	fn, err := ParseFunction(`/**
+ * Look up a domain
+ * @tags recon, OSINT
+ * @tag web
+ * @callable
+ */
+function lookup(domain) { return domain; }`)
+	if err != nil {
+		t.Fatalf("ParseFunction() error = %v", err)
+	}
+	if strings.Join(fn.Tags, ",") != "recon,osint,web" {
+		t.Errorf("Tags = %q", fn.Tags)
+	}
+
+	registry := NewRegistry()
+	registry.Add(fn)
+	registry.Add(&Function{Name: "encode", Code: "function encode() {}", Tags: []string{"crypto"}})
+	if got := registry.ListTagged([]string{"#Recon"}); len(got) != 1 || got[0] != "lookup" {
+		t.Errorf("ListTagged(recon) = %q", got)
+	}
+	if got := registry.Tags(); strings.Join(got, ",") != "crypto,osint,recon,web" {
+		t.Errorf("Tags() = %q", got)
+	}
+}
+
+func TestSaveArtifact(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HACKARE_ARTIFACTS_DIR", root)
+
+	engine := NewEngine()
+	result, err := engine.Execute(`saveArtifact("pixel.txt", "data:text/plain;base64,aGVsbG8=")`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	path, _ := result.(string)
+	if !strings.HasPrefix(path, root) {
+		t.Fatalf("saved to %q, want a file under %s", path, root)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "hello" {
+		t.Errorf("artifact = %q, %v", data, err)
+	}
+}
@@ -1,8 +1,20 @@
 package jsruntime
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/auditlog"
+	"github.com/hacka-re/cli/internal/metrics"
+	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/tags"
+	"github.com/hacka-re/cli/internal/tracing"
+	"github.com/hacka-re/cli/internal/webhook"
 )
 
 // Registry manages a collection of JavaScript functions
@@ -143,6 +155,33 @@ func (r *Registry) List() []string {
 	return names
 }
 
+// ListTagged returns the sorted names of functions carrying every tag in want
+func (r *Registry) ListTagged(want []string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := []string{}
+	for name, fn := range r.functions {
+		if tags.Match(fn.Tags, want) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tags returns the sorted set of tags used by the registered functions
+func (r *Registry) Tags() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var lists [][]string
+	for _, fn := range r.functions {
+		lists = append(lists, fn.Tags)
+	}
+	return tags.Collect(lists...)
+}
+
 // ListGroup returns all function names in a group
 func (r *Registry) ListGroup(groupID string) []string {
 	r.mu.RLock()
@@ -185,14 +224,65 @@ func (r *Registry) GetToolDefinitions() []map[string]interface{} {
 	return tools
 }
 
+// APITools returns the callable functions as tools for the chat completions API
+func (r *Registry) APITools() []api.Tool {
+	var tools []api.Tool
+	for _, definition := range r.GetToolDefinitions() {
+		data, err := json.Marshal(definition)
+		if err != nil {
+			continue
+		}
+		var tool api.Tool
+		if err := json.Unmarshal(data, &tool); err == nil {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// LoadSharedFunctions registers the enabled functions from a session or saved config
+// as callable tools, returning the first error but loading the rest
+func LoadSharedFunctions(registry *Registry, functions []share.Function) error {
+	var firstErr error
+	for _, shared := range functions {
+		if !shared.Enabled {
+			continue
+		}
+		fn, err := ParseFunction(shared.Code)
+		if err == nil {
+			fn.IsCallable = true
+			if fn.Description == "" {
+				fn.Description = shared.Description
+			}
+			fn.Tags = tags.Normalize(append(fn.Tags, shared.Tags...))
+			err = registry.AddOrReplace(fn)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("function %s: %w", shared.Name, err)
+		}
+	}
+	return firstErr
+}
+
 // Execute runs a function by name with the given arguments
-func (r *Registry) Execute(name string, args map[string]interface{}) (interface{}, error) {
+func (r *Registry) Execute(name string, args map[string]interface{}) (result interface{}, err error) {
+	_, span := tracing.Start(context.Background(), "tool.execute")
+	span.SetAttribute("tool.name", name)
+	span.SetAttribute("tool.runtime", "js")
+	defer func() { span.End(err) }()
+
 	fn, err := r.Get(name)
 	if err != nil {
+		metrics.ToolExecutions.Inc("js", "error")
 		return nil, err
 	}
 
-	return fn.Execute(args)
+	start := time.Now()
+	result, err = fn.Execute(args)
+	metrics.ToolExecutions.Inc("js", metrics.Status(err))
+	webhook.EmitToolExecuted(name, "js", start, args, err)
+	auditlog.EmitToolExecuted(name, "js", start, args, err)
+	return result, err
 }
 
 // Clear removes all functions from the registry
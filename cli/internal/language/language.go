@@ -0,0 +1,246 @@
+// Package language guesses the language a message is written in and words the
+// instruction that asks a model to reply in a given language, so conversations
+// can follow users who switch between languages.
+package language
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Settings of the reply language besides a language code
+const (
+	Off  = "off"  // Leave the language to the model
+	Auto = "auto" // Reply in the language of the user's latest message
+)
+
+// names are the languages a reply language can be set to, by ISO 639-1 code
+var names = map[string]string{
+	"ar": "Arabic", "cs": "Czech", "da": "Danish", "de": "German", "el": "Greek",
+	"en": "English", "es": "Spanish", "et": "Estonian", "fa": "Persian", "fi": "Finnish",
+	"fr": "French", "he": "Hebrew", "hi": "Hindi", "hu": "Hungarian", "id": "Indonesian",
+	"is": "Icelandic", "it": "Italian", "ja": "Japanese", "ko": "Korean", "lt": "Lithuanian",
+	"lv": "Latvian", "nl": "Dutch", "no": "Norwegian", "pl": "Polish", "pt": "Portuguese",
+	"ro": "Romanian", "ru": "Russian", "sv": "Swedish", "th": "Thai", "tr": "Turkish",
+	"uk": "Ukrainian", "vi": "Vietnamese", "zh": "Chinese",
+}
+
+// stopwords are frequent short words that tell languages in Latin script apart
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "of", "to", "what", "how", "this", "that", "with", "you", "can", "it", "do", "for", "my", "have", "in", "not"},
+	"sv": {"och", "är", "det", "att", "jag", "inte", "som", "på", "med", "en", "ett", "vad", "hur", "kan", "du", "har", "för", "den", "av", "till", "mig", "vi"},
+	"da": {"og", "er", "det", "at", "jeg", "ikke", "som", "på", "med", "en", "et", "hvad", "hvordan", "kan", "du", "har", "for", "den", "af", "til", "mig", "vi"},
+	"no": {"og", "er", "det", "at", "jeg", "ikke", "som", "på", "med", "en", "et", "hva", "hvordan", "kan", "du", "har", "for", "den", "av", "til", "meg", "vi"},
+	"de": {"und", "ist", "der", "die", "das", "nicht", "ich", "ein", "eine", "zu", "mit", "was", "wie", "kann", "du", "sie", "für", "auf", "den", "es", "mir", "wir"},
+	"nl": {"en", "is", "de", "het", "een", "niet", "ik", "van", "met", "wat", "hoe", "kan", "je", "dat", "voor", "op", "zijn", "mij", "we", "ook"},
+	"fr": {"et", "est", "le", "la", "les", "un", "une", "de", "des", "je", "ne", "pas", "que", "qui", "avec", "pour", "vous", "comment", "quoi", "ce", "il", "nous"},
+	"es": {"y", "es", "el", "la", "los", "las", "un", "una", "de", "que", "no", "con", "para", "por", "cómo", "qué", "como", "puedo", "mi", "lo", "en", "se"},
+	"it": {"e", "è", "il", "la", "le", "gli", "un", "una", "di", "che", "non", "con", "per", "come", "cosa", "sono", "mi", "lo", "posso", "del", "della"},
+	"pt": {"e", "é", "o", "a", "os", "as", "um", "uma", "de", "que", "não", "com", "para", "por", "como", "eu", "do", "da", "em", "você", "se"},
+	"fi": {"ja", "on", "ei", "se", "että", "mitä", "miten", "kuinka", "minä", "sinä", "voi", "ole", "mutta", "kun", "tämä", "mikä", "minun", "kanssa"},
+	"pl": {"i", "jest", "nie", "to", "się", "w", "na", "z", "że", "jak", "co", "czy", "mam", "ale", "do", "od", "jestem", "mogę", "dla", "ten"},
+}
+
+// letters are characters that point to a language of Latin script
+var letters = map[rune][]string{
+	'å': {"sv", "da", "no"}, 'ä': {"sv", "fi", "de"}, 'ö': {"sv", "fi", "de"},
+	'ø': {"da", "no"}, 'æ': {"da", "no"}, 'ß': {"de"}, 'ü': {"de"},
+	'ñ': {"es"}, '¿': {"es"}, '¡': {"es"}, 'ã': {"pt"}, 'õ': {"pt"}, 'ç': {"fr", "pt"},
+	'ł': {"pl"}, 'ą': {"pl"}, 'ę': {"pl"}, 'ś': {"pl"}, 'ż': {"pl"}, 'ź': {"pl"}, 'ń': {"pl"},
+	'è': {"fr", "it"}, 'ê': {"fr"}, 'à': {"fr", "it"}, 'ù': {"fr", "it"}, 'ò': {"it"},
+}
+
+// minScore is the evidence Detect needs before it names a language
+const minScore = 2
+
+// Detect returns the code of the language text is most likely written in, or
+// "" when the text is too short or mixed to tell
+func Detect(text string) string {
+	if code := detectScript(text); code != "" {
+		return code
+	}
+
+	scores := map[string]float64{}
+	for _, r := range strings.ToLower(text) {
+		for _, code := range letters[r] {
+			scores[code] += 1 / float64(len(letters[r]))
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		for code, list := range stopwords {
+			for _, stopword := range list {
+				if word == stopword {
+					scores[code]++
+					break
+				}
+			}
+		}
+	}
+
+	codes := make([]string, 0, len(scores))
+	for code := range scores {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if scores[codes[i]] != scores[codes[j]] {
+			return scores[codes[i]] > scores[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+	if len(codes) == 0 || scores[codes[0]] < minScore {
+		return ""
+	}
+	if len(codes) > 1 && scores[codes[1]] == scores[codes[0]] {
+		return "" // A tie, e.g. Danish and Norwegian
+	}
+	return codes[0]
+}
+
+// detectScript names the language of text mostly written in a script other
+// than Latin
+func detectScript(text string) string {
+	counts := map[string]int{}
+	latin, total := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		total++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				counts["uk"] += 10
+			}
+			counts["ru"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		}
+	}
+	if total == 0 || latin*2 >= total {
+		return ""
+	}
+	// Japanese mixes kana with Han characters
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	if counts["uk"] > counts["ru"] {
+		return "uk"
+	}
+	best := ""
+	for _, code := range []string{"zh", "ko", "ru", "el", "ar", "he", "hi", "th"} {
+		if counts[code] > counts[best] {
+			best = code
+		}
+	}
+	return best
+}
+
+// Name returns the English name of a language code, or "" if it is unknown
+func Name(code string) string {
+	return names[code]
+}
+
+// Parse reads a reply language setting: off, auto, a language code or an
+// English language name
+func Parse(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case Off, "", "none":
+		return Off, nil
+	case Auto:
+		return Auto, nil
+	}
+	if _, ok := names[value]; ok {
+		return value, nil
+	}
+	for code, name := range names {
+		if strings.ToLower(name) == value {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("unknown language %q; use a code like sv or en, auto or off", value)
+}
+
+// Codes returns the known language codes, sorted
+func Codes() []string {
+	codes := make([]string, 0, len(names))
+	for code := range names {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Instruction asks a model to reply in the language with the given code
+func Instruction(code string) string {
+	return fmt.Sprintf("Reply in %s unless the user asks for another language.", Name(code))
+}
+
+// WithInstruction appends the reply language instruction to a system prompt
+func WithInstruction(systemPrompt, code string) string {
+	if Name(code) == "" {
+		return systemPrompt
+	}
+	if systemPrompt == "" {
+		return Instruction(code)
+	}
+	return systemPrompt + "\n\n" + Instruction(code)
+}
+
+// Session tracks the reply language of one conversation: the setting, and in
+// auto mode the language last detected in the user's messages
+type Session struct {
+	Setting  string // Off, Auto or a language code
+	Detected string // Last language detected in auto mode
+}
+
+// Reply returns the code of the language the reply to message should be in,
+// or "" to leave it to the model. In auto mode a message whose language can't
+// be told keeps the language detected before.
+func (s *Session) Reply(message string) string {
+	switch s.Setting {
+	case Off, "":
+		return ""
+	case Auto:
+		if code := Detect(message); code != "" {
+			s.Detected = code
+		}
+		return s.Detected
+	}
+	return s.Setting
+}
+
+// String describes the setting for the user, e.g. "auto (Swedish)"
+func (s *Session) String() string {
+	switch s.Setting {
+	case Off, "":
+		return "off"
+	case Auto:
+		if s.Detected != "" {
+			return fmt.Sprintf("auto (%s so far)", Name(s.Detected))
+		}
+		return "auto"
+	}
+	return fmt.Sprintf("%s (%s)", Name(s.Setting), s.Setting)
+}
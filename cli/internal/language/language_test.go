@@ -0,0 +1,65 @@
+package language
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"How do I rotate the API keys of this service?", "en"},
+		{"Hur roterar jag API-nycklarna för den här tjänsten?", "sv"},
+		{"Vad är det som inte fungerar med min konfiguration?", "sv"},
+		{"Hvordan kan jeg se hva som er galt med meg?", "no"},
+		{"Wie kann ich die Schlüssel für den Dienst ändern?", "de"},
+		{"Comment est-ce que je peux changer les clés de ce service ?", "fr"},
+		{"¿Cómo puedo cambiar las claves de la API?", "es"},
+		{"Come posso cambiare le chiavi di questo servizio?", "it"},
+		{"Como eu posso trocar as chaves da API? Não funciona.", "pt"},
+		{"Miten voin vaihtaa avaimet? Se ei toimi ja minä en tiedä mitä tehdä.", "fi"},
+		{"Jak mogę zmienić klucze? To nie działa.", "pl"},
+		{"Как сменить ключи API?", "ru"},
+		{"Як змінити ключі API? Це не працює.", "uk"},
+		{"APIキーを変更するにはどうすればいいですか？", "ja"},
+		{"如何更换API密钥？", "zh"},
+		{"API 키를 어떻게 바꾸나요?", "ko"},
+		{"ok", ""},
+		{"nmap -sV 10.0.0.1", ""},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.text); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for input, want := range map[string]string{"sv": "sv", "Swedish": "sv", " AUTO ": Auto, "": Off, "none": Off} {
+		if got, err := Parse(input); err != nil || got != want {
+			t.Errorf("Parse(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := Parse("klingon"); err == nil {
+		t.Error("Parse accepted an unknown language")
+	}
+}
+
+func TestSessionKeepsDetectedLanguage(t *testing.T) {
+	session := &Session{Setting: Auto}
+	if got := session.Reply("Hur gör jag det här? Jag förstår inte."); got != "sv" {
+		t.Fatalf("first reply in %q, want sv", got)
+	}
+	if got := session.Reply("ok, thanks"); got != "sv" {
+		t.Errorf("an unclear message switched the language to %q", got)
+	}
+	if got := session.Reply("What is the default port of SSH and how do I change it?"); got != "en" {
+		t.Errorf("switching to English replied in %q", got)
+	}
+	session.Setting = "de"
+	if got := session.Reply("What now?"); got != "de" {
+		t.Errorf("a fixed language replied in %q", got)
+	}
+	if got := WithInstruction("Be brief.", "sv"); got != "Be brief.\n\nReply in Swedish unless the user asks for another language." {
+		t.Errorf("WithInstruction = %q", got)
+	}
+}
@@ -0,0 +1,343 @@
+// Package lineedit provides the pieces of emacs-style line editing used by
+// the terminal chat: decoding keys from a terminal in raw mode, including
+// UTF-8 characters and the escape sequences of arrow keys with modifiers,
+// word boundaries, and a kill ring for Ctrl+K, Ctrl+Y and Alt+Y. Pastes in
+// bracketed paste mode come as one key with the pasted text.
+package lineedit
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyCode identifies a key
+type KeyCode int
+
+// Keys
+const (
+	KeyUnknown   KeyCode = iota // A sequence that isn't recognized
+	KeyRune                     // A character, in Key.Rune
+	KeyCtrl                     // Ctrl with a letter, in Key.Rune ('a' for Ctrl+A)
+	KeyEnter                    // Enter or Return
+	KeyTab                      // Tab
+	KeyBackspace                // Backspace
+	KeyDelete                   // Delete, the key that removes the character under the cursor
+	KeyUp                       // Up arrow
+	KeyDown                     // Down arrow
+	KeyLeft                     // Left arrow
+	KeyRight                    // Right arrow
+	KeyHome                     // Home
+	KeyEnd                      // End
+	KeyPaste                    // Text pasted in bracketed paste mode, in Key.Text
+)
+
+// Bracketed paste mode makes the terminal mark pasted text, so a paste can't
+// be taken for typing: a newline in it doesn't send the line
+const (
+	EnableBracketedPaste  = "\033[?2004h"
+	DisableBracketedPaste = "\033[?2004l"
+	pasteEnd              = "\x1b[201~"
+)
+
+// Key is one key press
+type Key struct {
+	Code KeyCode
+	Rune rune
+	Alt  bool   // Alt (Meta) was held
+	Ctrl bool   // Ctrl was held with an arrow, Home or End
+	Text string // The pasted text of KeyPaste
+}
+
+// IsCtrl reports whether k is Ctrl with the letter c, without Alt
+func (k Key) IsCtrl(c rune) bool {
+	return k.Code == KeyCtrl && k.Rune == c && !k.Alt
+}
+
+// IsAlt reports whether k is Alt with the character c
+func (k Key) IsAlt(c rune) bool {
+	return k.Code == KeyRune && k.Alt && unicode.ToLower(k.Rune) == c
+}
+
+// ReadKey reads one key from r, which should be a terminal in raw mode. It
+// reads a byte at a time, so nothing after the key is consumed.
+func ReadKey(r io.Reader) (Key, error) {
+	b, err := readByte(r)
+	if err != nil {
+		return Key{}, err
+	}
+	if b != 0x1B {
+		return decode(r, b)
+	}
+	return readEscape(r)
+}
+
+// readEscape reads what follows Escape: a sequence, or the key pressed with Alt
+func readEscape(r io.Reader) (Key, error) {
+	b, err := readByte(r)
+	if err != nil {
+		return Key{}, err
+	}
+	switch b {
+	case '[':
+		return readCSI(r)
+	case 'O':
+		b, err = readByte(r)
+		if err != nil {
+			return Key{}, err
+		}
+		return Key{Code: finalKey(b)}, nil
+	case 0x1B: // Some terminals send Alt+arrow as Escape and the arrow
+		key, err := readEscape(r)
+		key.Alt = true
+		return key, err
+	}
+	key, err := decode(r, b)
+	key.Alt = true
+	return key, err
+}
+
+// decode returns the key that starts with byte b
+func decode(r io.Reader, b byte) (Key, error) {
+	switch {
+	case b == 0x0D || b == 0x0A:
+		return Key{Code: KeyEnter}, nil
+	case b == 0x09:
+		return Key{Code: KeyTab}, nil
+	case b == 0x7F || b == 0x08:
+		return Key{Code: KeyBackspace}, nil
+	case b < 0x20:
+		return Key{Code: KeyCtrl, Rune: rune(b) + 'a' - 1}, nil
+	case b < utf8.RuneSelf:
+		return Key{Code: KeyRune, Rune: rune(b)}, nil
+	}
+
+	// The first byte of a UTF-8 character tells how many follow
+	var size int
+	switch {
+	case b&0xE0 == 0xC0:
+		size = 2
+	case b&0xF0 == 0xE0:
+		size = 3
+	case b&0xF8 == 0xF0:
+		size = 4
+	default:
+		return Key{Code: KeyUnknown}, nil
+	}
+	buf := []byte{b}
+	for len(buf) < size {
+		next, err := readByte(r)
+		if err != nil {
+			return Key{}, err
+		}
+		buf = append(buf, next)
+	}
+	char, _ := utf8.DecodeRune(buf)
+	if char == utf8.RuneError {
+		return Key{Code: KeyUnknown}, nil
+	}
+	return Key{Code: KeyRune, Rune: char}, nil
+}
+
+// readCSI reads the rest of an "Escape [" sequence, such as "1;5C" for
+// Ctrl+Right or "3~" for Delete
+func readCSI(r io.Reader) (Key, error) {
+	var params []byte
+	for {
+		b, err := readByte(r)
+		if err != nil {
+			return Key{}, err
+		}
+		if b >= 0x40 && b <= 0x7E {
+			if string(params) == "200" && b == '~' {
+				return readPaste(r)
+			}
+			return csiKey(string(params), b), nil
+		}
+		params = append(params, b)
+	}
+}
+
+// readPaste reads pasted text up to the sequence that ends it. Line breaks
+// become "\n", bytes that aren't UTF-8 are replaced, and other control
+// characters are dropped.
+func readPaste(r io.Reader) (Key, error) {
+	var text []byte
+	end := []byte(pasteEnd)
+	for !bytes.HasSuffix(text, end) {
+		b, err := readByte(r)
+		if err != nil {
+			return Key{}, err
+		}
+		text = append(text, b)
+	}
+	pasted := strings.TrimSuffix(string(text), pasteEnd)
+	pasted = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(pasted)
+	pasted = strings.ToValidUTF8(pasted, string(utf8.RuneError))
+	// Other control characters would reach the terminal when the line is drawn
+	pasted = strings.Map(func(c rune) rune {
+		if unicode.IsControl(c) && c != '\n' && c != '\t' {
+			return -1
+		}
+		return c
+	}, pasted)
+	return Key{Code: KeyPaste, Text: pasted}, nil
+}
+
+// csiKey returns the key of a sequence with params and a final byte
+func csiKey(params string, final byte) Key {
+	fields := strings.Split(params, ";")
+	var key Key
+	if final == '~' {
+		switch fields[0] {
+		case "1", "7":
+			key.Code = KeyHome
+		case "4", "8":
+			key.Code = KeyEnd
+		case "3":
+			key.Code = KeyDelete
+		}
+	} else {
+		key.Code = finalKey(final)
+	}
+
+	// The modifier is 1 plus a bit mask: 2 for Alt and 4 for Ctrl
+	if len(fields) > 1 {
+		if modifier, err := strconv.Atoi(fields[1]); err == nil && modifier > 1 {
+			key.Alt = (modifier-1)&2 != 0
+			key.Ctrl = (modifier-1)&4 != 0
+		}
+	}
+	return key
+}
+
+// finalKey returns the key named by the last byte of a sequence
+func finalKey(final byte) KeyCode {
+	switch final {
+	case 'A':
+		return KeyUp
+	case 'B':
+		return KeyDown
+	case 'C':
+		return KeyRight
+	case 'D':
+		return KeyLeft
+	case 'H':
+		return KeyHome
+	case 'F':
+		return KeyEnd
+	}
+	return KeyUnknown
+}
+
+func readByte(r io.Reader) (byte, error) {
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n == 1 {
+			return buf[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// isWordRune reports whether c is part of a word for word movement
+func isWordRune(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// WordStart returns the position of the start of the word before pos, the
+// target of Alt+B: letters and digits make words, anything else separates
+// them
+func WordStart(line []rune, pos int) int {
+	for pos > 0 && !isWordRune(line[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(line[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// WordEnd returns the position of the end of the word after pos, the target
+// of Alt+F
+func WordEnd(line []rune, pos int) int {
+	for pos < len(line) && !isWordRune(line[pos]) {
+		pos++
+	}
+	for pos < len(line) && isWordRune(line[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// FieldStart returns the position of the start of the whitespace-separated
+// field before pos, what Ctrl+W removes
+func FieldStart(line []rune, pos int) int {
+	for pos > 0 && unicode.IsSpace(line[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(line[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// KillRingSize is the number of kills kept
+const KillRingSize = 10
+
+// KillRing keeps killed text for yanking it back. Kills in a row join into
+// one entry, as in Emacs, so Ctrl+W pressed three times yanks back as the
+// three words.
+type KillRing struct {
+	entries []string // Oldest first
+	yanked  int      // Index of the entry yanked last
+}
+
+// Kill saves killed text. If continued, the previous edit was also a kill
+// and text joins the latest entry: before it when killing backward.
+func (k *KillRing) Kill(text string, backward, continued bool) {
+	if text == "" {
+		return
+	}
+	if continued && len(k.entries) > 0 {
+		last := len(k.entries) - 1
+		if backward {
+			k.entries[last] = text + k.entries[last]
+		} else {
+			k.entries[last] += text
+		}
+		return
+	}
+	k.entries = append(k.entries, text)
+	if len(k.entries) > KillRingSize {
+		k.entries = k.entries[len(k.entries)-KillRingSize:]
+	}
+}
+
+// Yank returns the latest kill, or "" if there is none
+func (k *KillRing) Yank() string {
+	if len(k.entries) == 0 {
+		return ""
+	}
+	k.yanked = len(k.entries) - 1
+	return k.entries[k.yanked]
+}
+
+// Rotate returns the kill before the one yanked last, wrapping around to the
+// latest, for replacing yanked text with Alt+Y
+func (k *KillRing) Rotate() string {
+	if len(k.entries) == 0 {
+		return ""
+	}
+	k.yanked--
+	if k.yanked < 0 {
+		k.yanked = len(k.entries) - 1
+	}
+	return k.entries[k.yanked]
+}
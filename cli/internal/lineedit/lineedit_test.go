@@ -0,0 +1,100 @@
+package lineedit
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadKey(t *testing.T) {
+	input := "a\x01\r\x7fé😀\x1b[A\x1b[1;3C\x1b[1;5D\x1bb\x1b\x7f\x1b[3~\x1bOH\x1b[4~\x1b\x1b[D"
+	want := []Key{
+		{Code: KeyRune, Rune: 'a'},
+		{Code: KeyCtrl, Rune: 'a'},
+		{Code: KeyEnter},
+		{Code: KeyBackspace},
+		{Code: KeyRune, Rune: 'é'},
+		{Code: KeyRune, Rune: '😀'},
+		{Code: KeyUp},
+		{Code: KeyRight, Alt: true},
+		{Code: KeyLeft, Ctrl: true},
+		{Code: KeyRune, Rune: 'b', Alt: true},
+		{Code: KeyBackspace, Alt: true},
+		{Code: KeyDelete},
+		{Code: KeyHome},
+		{Code: KeyEnd},
+		{Code: KeyLeft, Alt: true},
+	}
+	r := strings.NewReader(input)
+	for i, w := range want {
+		got, err := ReadKey(r)
+		if err != nil {
+			t.Fatalf("key %d: %v", i, err)
+		}
+		if got != w {
+			t.Errorf("key %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if _, err := ReadKey(r); err != io.EOF {
+		t.Errorf("after the input: %v, want EOF", err)
+	}
+}
+
+func TestReadPaste(t *testing.T) {
+	r := strings.NewReader("\x1b[200~line one\r\nline \xfftwo\x1b[A\x1b[201~x")
+	key, err := ReadKey(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Key{Code: KeyPaste, Text: "line one\nline \uFFFDtwo[A"}
+	if key != want {
+		t.Errorf("paste = %+v, want %+v", key, want)
+	}
+	if key, _ := ReadKey(r); key.Rune != 'x' {
+		t.Errorf("after the paste: %+v", key)
+	}
+}
+
+func TestWords(t *testing.T) {
+	line := []rune("scan  host-1.example ")
+	if got := WordStart(line, len(line)); got != 13 {
+		t.Errorf("WordStart = %d, want 13", got)
+	}
+	if got := WordStart(line, 6); got != 0 {
+		t.Errorf("WordStart from the gap = %d, want 0", got)
+	}
+	if got := WordEnd(line, 4); got != 10 {
+		t.Errorf("WordEnd = %d, want 10", got)
+	}
+	if got := FieldStart(line, len(line)); got != 6 {
+		t.Errorf("FieldStart = %d, want 6", got)
+	}
+}
+
+func TestKillRing(t *testing.T) {
+	var ring KillRing
+	if got := ring.Yank(); got != "" {
+		t.Errorf("empty ring yanked %q", got)
+	}
+
+	ring.Kill("first", false, false)
+	ring.Kill("world", true, false)
+	ring.Kill("hello ", true, true) // Ctrl+W again joins in front
+	ring.Kill("!", false, true)
+	if got := ring.Yank(); got != "hello world!" {
+		t.Errorf("Yank = %q", got)
+	}
+	if got := ring.Rotate(); got != "first" {
+		t.Errorf("Rotate = %q, want first", got)
+	}
+	if got := ring.Rotate(); got != "hello world!" {
+		t.Errorf("Rotate wraps to %q", got)
+	}
+
+	for i := 0; i < KillRingSize+5; i++ {
+		ring.Kill(strings.Repeat("x", i+1), false, false)
+	}
+	if len(ring.entries) != KillRingSize {
+		t.Errorf("ring keeps %d kills, want %d", len(ring.entries), KillRingSize)
+	}
+}
@@ -0,0 +1,204 @@
+package mailgw
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// imapClient speaks just enough IMAP4rev1 (RFC 3501) to read and flag new mail
+type imapClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	tag    int
+}
+
+// imapResponse is one untagged response line with any literals it carried
+type imapResponse struct {
+	line     string
+	literals [][]byte
+}
+
+// dialIMAP connects and reads the server greeting. With plaintext false the
+// connection uses implicit TLS (port 993).
+func dialIMAP(addr string, plaintext bool, timeout time.Duration) (*imapClient, error) {
+	conn, err := netguard.Dial(context.Background(), &net.Dialer{Timeout: timeout}, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to IMAP server %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	if !plaintext {
+		host, _, _ := net.SplitHostPort(addr)
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to connect to IMAP server %s: %w", addr, err)
+		}
+		conn = tlsConn
+	}
+
+	c := &imapClient{conn: conn, reader: bufio.NewReader(conn)}
+	greeting, err := c.readLine()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read IMAP greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected IMAP greeting: %s", greeting)
+	}
+	return c, nil
+}
+
+// Login authenticates with a username and password
+func (c *imapClient) Login(username, password string) error {
+	_, err := c.command("LOGIN %s %s", quote(username), quote(password))
+	if err != nil {
+		return fmt.Errorf("IMAP login failed: %w", err)
+	}
+	return nil
+}
+
+// Select opens a mailbox for reading and flagging
+func (c *imapClient) Select(mailbox string) error {
+	_, err := c.command("SELECT %s", quote(mailbox))
+	return err
+}
+
+// SearchUnseen returns the UIDs of messages without the \Seen flag
+func (c *imapClient) SearchUnseen() ([]uint32, error) {
+	responses, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, err
+	}
+	var uids []uint32
+	for _, resp := range responses {
+		fields := strings.Fields(resp.line)
+		if len(fields) < 2 || !strings.EqualFold(fields[1], "SEARCH") {
+			continue
+		}
+		for _, field := range fields[2:] {
+			if uid, err := strconv.ParseUint(field, 10, 32); err == nil {
+				uids = append(uids, uint32(uid))
+			}
+		}
+	}
+	return uids, nil
+}
+
+// Fetch returns the full raw message without marking it seen
+func (c *imapClient) Fetch(uid uint32) ([]byte, error) {
+	responses, err := c.command("UID FETCH %d (BODY.PEEK[])", uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, resp := range responses {
+		if strings.Contains(strings.ToUpper(resp.line), "FETCH") && len(resp.literals) > 0 {
+			return resp.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("message %d not returned by server", uid)
+}
+
+// MarkSeen sets the \Seen flag so the message isn't processed again
+func (c *imapClient) MarkSeen(uid uint32) error {
+	_, err := c.command(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid)
+	return err
+}
+
+// Logout ends the session and closes the connection
+func (c *imapClient) Logout() {
+	c.command("LOGOUT")
+	c.conn.Close()
+}
+
+// command sends a tagged command and collects untagged responses until the tagged result
+func (c *imapClient) command(format string, args ...interface{}) ([]imapResponse, error) {
+	c.tag++
+	tag := fmt.Sprintf("A%03d", c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return nil, err
+	}
+
+	var responses []imapResponse
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(resp.line, tag+" ") {
+			status := strings.TrimPrefix(resp.line, tag+" ")
+			if strings.HasPrefix(strings.ToUpper(status), "OK") {
+				return responses, nil
+			}
+			return nil, errors.New(status)
+		}
+		responses = append(responses, resp)
+	}
+}
+
+// readResponse reads one response line, following {n} literals onto continuation lines
+func (c *imapClient) readResponse() (imapResponse, error) {
+	var resp imapResponse
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return resp, err
+		}
+		resp.line += line
+
+		size, ok := literalSize(line)
+		if !ok {
+			return resp, nil
+		}
+		if size > maxMessageSize {
+			return resp, fmt.Errorf("IMAP literal of %d bytes is too large", size)
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.reader, literal); err != nil {
+			return resp, fmt.Errorf("failed to read IMAP literal: %w", err)
+		}
+		resp.literals = append(resp.literals, literal)
+	}
+}
+
+// readLine reads a CRLF-terminated line without the terminator
+func (c *imapClient) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// literalSize parses a trailing "{123}" literal marker
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	open := strings.LastIndex(line, "{")
+	if open < 0 {
+		return 0, false
+	}
+	size, err := strconv.Atoi(line[open+1 : len(line)-1])
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// quote makes an IMAP quoted string
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,306 @@
+// Package mailgw answers email with a hacka.re configuration: it polls an IMAP
+// mailbox, treats each new message from an allowlisted sender as a prompt,
+// and replies over SMTP. It suits setups where the only way in or out is
+// a (local) mail server.
+//
+// Senders are checked against the From header only, which is easy to forge;
+// run the gateway behind a mail server that enforces SPF/DKIM/DMARC or only
+// accepts mail from inside the network.
+package mailgw
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// maxMessageSize is the largest email the gateway reads
+const maxMessageSize = 10 << 20
+
+// maxPromptLength bounds the text taken from one email
+const maxPromptLength = 32 << 10
+
+// DefaultPollInterval is used when Config.PollInterval is zero
+const DefaultPollInterval = time.Minute
+
+// ErrNoAllowedSenders is returned when the allowlist is empty; the gateway
+// never answers arbitrary senders
+var ErrNoAllowedSenders = errors.New("at least one allowed sender is required")
+
+// Config describes the mailbox and who may use it
+type Config struct {
+	IMAPAddr       string // host:port, e.g. mail.lan:993
+	IMAPPlaintext  bool   // Connect without TLS (e.g. port 143 on a trusted LAN)
+	SMTPAddr       string // host:port; STARTTLS is used when the server offers it
+	Username       string // Used for both IMAP and SMTP
+	Password       string
+	From           string   // Reply address; defaults to Username
+	Mailbox        string   // Defaults to INBOX
+	AllowedSenders []string // Addresses or @domain entries
+	PollInterval   time.Duration
+}
+
+// Completer sends chat completions; *api.Client implements it
+type Completer interface {
+	SendChatCompletionContext(ctx context.Context, messages []api.Message, streamCallback api.StreamCallback) (*api.ChatResponse, error)
+}
+
+// Gateway polls the mailbox and replies to allowed senders
+type Gateway struct {
+	cfg          Config
+	client       Completer
+	systemPrompt string
+
+	// send delivers a message; replaced in tests
+	send func(to string, msg []byte) error
+}
+
+// New creates a gateway answering with client and the given system prompt
+func New(cfg Config, client Completer, systemPrompt string) (*Gateway, error) {
+	if len(cfg.AllowedSenders) == 0 {
+		return nil, ErrNoAllowedSenders
+	}
+	if cfg.IMAPAddr == "" || cfg.SMTPAddr == "" {
+		return nil, errors.New("IMAP and SMTP server addresses are required")
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	if cfg.Mailbox == "" {
+		cfg.Mailbox = "INBOX"
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+
+	g := &Gateway{cfg: cfg, client: client, systemPrompt: systemPrompt}
+	g.send = g.sendSMTP
+	return g, nil
+}
+
+// Run polls until ctx is cancelled. Poll failures are logged and retried.
+func (g *Gateway) Run(ctx context.Context) error {
+	for {
+		if n, err := g.Poll(ctx); err != nil {
+			logger.Get().Warn("[Mail] Poll failed: %v", err)
+		} else if n > 0 {
+			logger.Get().Info("[Mail] Answered %d message(s)", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(g.cfg.PollInterval):
+		}
+	}
+}
+
+// Poll processes all unseen messages once and returns how many were answered.
+// Every message is marked seen, including rejected ones, so none is handled twice.
+func (g *Gateway) Poll(ctx context.Context) (int, error) {
+	c, err := dialIMAP(g.cfg.IMAPAddr, g.cfg.IMAPPlaintext, 2*time.Minute)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Logout()
+
+	if err := c.Login(g.cfg.Username, g.cfg.Password); err != nil {
+		return 0, err
+	}
+	if err := c.Select(g.cfg.Mailbox); err != nil {
+		return 0, fmt.Errorf("failed to open mailbox %s: %w", g.cfg.Mailbox, err)
+	}
+	uids, err := c.SearchUnseen()
+	if err != nil {
+		return 0, err
+	}
+
+	answered := 0
+	for _, uid := range uids {
+		if ctx.Err() != nil {
+			break
+		}
+		raw, err := c.Fetch(uid)
+		if err != nil {
+			return answered, err
+		}
+		// Flag first: a reply that fails is logged rather than retried forever
+		if err := c.MarkSeen(uid); err != nil {
+			return answered, err
+		}
+		if g.handle(ctx, raw) {
+			answered++
+		}
+	}
+	return answered, nil
+}
+
+// handle answers one raw message, reporting whether a reply was sent
+func (g *Gateway) handle(ctx context.Context, raw []byte) bool {
+	email, err := parseEmail(raw)
+	if err != nil {
+		logger.Get().Warn("[Mail] Skipping unreadable message: %v", err)
+		return false
+	}
+	if email.Automated {
+		logger.Get().Info("[Mail] Skipping automated message from %s", email.From)
+		return false
+	}
+	if strings.EqualFold(email.From, g.cfg.From) {
+		return false
+	}
+	if !g.allowed(email.From) {
+		logger.Get().Warn("[Mail] Ignoring message from non-allowlisted sender %s", email.From)
+		return false
+	}
+
+	prompt := email.prompt()
+	if prompt == "" {
+		return false
+	}
+
+	var messages []api.Message
+	if g.systemPrompt != "" {
+		messages = append(messages, api.Message{Role: "system", Content: g.systemPrompt})
+	}
+	messages = append(messages, api.Message{Role: "user", Content: prompt})
+
+	answer := ""
+	response, err := g.client.SendChatCompletionContext(ctx, messages, nil)
+	switch {
+	case err != nil:
+		logger.Get().Error("[Mail] Completion failed for %s: %v", email.From, err)
+		answer = "Sorry, the request failed: " + err.Error()
+	case len(response.Choices) == 0:
+		answer = "The model returned no answer."
+	default:
+		answer = response.Choices[0].Message.Content
+	}
+
+	if err := g.send(email.From, g.composeReply(email, answer)); err != nil {
+		logger.Get().Error("[Mail] Failed to reply to %s: %v", email.From, err)
+		return false
+	}
+	return true
+}
+
+// allowed checks a sender against the allowlist; "@example.com" allows a domain
+func (g *Gateway) allowed(address string) bool {
+	address = strings.ToLower(address)
+	for _, entry := range g.cfg.AllowedSenders {
+		entry = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(entry, "*")))
+		if entry == "" {
+			continue
+		}
+		if strings.HasPrefix(entry, "@") {
+			if strings.HasSuffix(address, entry) {
+				return true
+			}
+		} else if address == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// composeReply builds a threaded plain-text reply
+func (g *Gateway) composeReply(email *email, answer string) []byte {
+	subject := email.Subject
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+	references := strings.TrimSpace(email.References + " " + email.MessageID)
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+		}
+	}
+	header("From", g.cfg.From)
+	header("To", email.From)
+	header("Subject", encodeHeader(subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", g.newMessageID())
+	header("In-Reply-To", email.MessageID)
+	header("References", references)
+	header("Auto-Submitted", "auto-replied") // Stops other gateways and autoresponders replying back
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+	writeQuotedPrintable(&buf, answer)
+	return buf.Bytes()
+}
+
+// newMessageID returns a unique Message-ID in the reply address's domain
+func (g *Gateway) newMessageID() string {
+	var id [12]byte
+	rand.Read(id[:])
+	domain := "hacka.re"
+	if at := strings.LastIndex(g.cfg.From, "@"); at >= 0 {
+		domain = g.cfg.From[at+1:]
+	}
+	return "<" + hex.EncodeToString(id[:]) + "@" + domain + ">"
+}
+
+// sendSMTP delivers a reply through the configured server, authenticating
+// when a password is set. It does what smtp.SendMail does, over a connection
+// from netguard so offline mode applies.
+func (g *Gateway) sendSMTP(to string, msg []byte) error {
+	host, _, err := net.SplitHostPort(g.cfg.SMTPAddr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP address %q: %w", g.cfg.SMTPAddr, err)
+	}
+	conn, err := netguard.Dial(context.Background(), &net.Dialer{Timeout: 30 * time.Second}, "tcp", g.cfg.SMTPAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server %s: %w", g.cfg.SMTPAddr, err)
+	}
+	conn.SetDeadline(time.Now().Add(2 * time.Minute))
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if g.cfg.Password != "" {
+		if err := c.Auth(smtp.PlainAuth("", g.cfg.Username, g.cfg.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(g.cfg.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,156 @@
+package mailgw
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// email is the part of an incoming message the gateway uses
+type email struct {
+	From       string // Bare address
+	Subject    string
+	MessageID  string
+	References string
+	Body       string // Plain text
+	Automated  bool   // Auto-replies, bounces and list mail
+}
+
+// parseEmail reads the sender, threading headers and plain-text body of a raw message
+func parseEmail(raw []byte) (*email, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid From header: %w", err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	e := &email{
+		From:       from.Address,
+		Subject:    subject,
+		MessageID:  strings.TrimSpace(msg.Header.Get("Message-ID")),
+		References: strings.TrimSpace(msg.Header.Get("References")),
+		Automated:  isAutomated(msg.Header),
+	}
+
+	body, err := textBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, err
+	}
+	e.Body = body
+	return e, nil
+}
+
+// isAutomated recognises messages that must never get a reply (RFC 3834)
+func isAutomated(header mail.Header) bool {
+	if auto := strings.ToLower(header.Get("Auto-Submitted")); auto != "" && auto != "no" {
+		return true
+	}
+	switch strings.ToLower(header.Get("Precedence")) {
+	case "bulk", "list", "junk":
+		return true
+	}
+	return header.Get("List-Id") != "" || header.Get("Return-Path") == "<>"
+}
+
+// textBody finds the text/plain content, descending into multipart messages
+func textBody(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain" // RFC 2045 default
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return "", nil
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to read multipart body: %w", err)
+			}
+			// NextPart already decodes quoted-printable parts
+			text, err := textBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err == nil && text != "" {
+				return text, nil
+			}
+		}
+	}
+	if mediaType != "text/plain" {
+		return "", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body) // Ignores line breaks
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxMessageSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode body: %w", err)
+	}
+	return string(data), nil
+}
+
+// replyHeader matches the "On <date>, <name> wrote:" line above a quoted reply
+var replyHeader = regexp.MustCompile(`(?i)^on .+ wrote:$`)
+
+// prompt is the subject and the new text of the body, without quoted history or signature
+func (e *email) prompt() string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(e.Body, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "--" || replyHeader.MatchString(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	body := strings.TrimSpace(strings.Join(lines, "\n"))
+
+	subject := strings.TrimSpace(e.Subject)
+	for strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = strings.TrimSpace(subject[3:])
+	}
+
+	prompt := body
+	if subject != "" && !strings.HasPrefix(body, subject) {
+		prompt = strings.TrimSpace(subject + "\n\n" + body)
+	}
+	if len(prompt) > maxPromptLength {
+		prompt = prompt[:maxPromptLength]
+	}
+	return prompt
+}
+
+// encodeHeader encodes non-ASCII header text as an RFC 2047 encoded word
+func encodeHeader(s string) string {
+	return mime.QEncoding.Encode("utf-8", s)
+}
+
+// writeQuotedPrintable writes text with CRLF line endings in quoted-printable
+func writeQuotedPrintable(w io.Writer, text string) {
+	qp := quotedprintable.NewWriter(w)
+	qp.Write([]byte(strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")))
+	qp.Close()
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/netguard"
 )
 
 const (
@@ -27,9 +28,7 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		httpClient: netguard.Client(30 * time.Second),
 	}
 }
 
@@ -3,9 +3,13 @@ package shodan
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/hacka-re/cli/internal/logger"
 	"github.com/hacka-re/cli/internal/mcp"
 	"github.com/hacka-re/cli/internal/mcp/types"
+	"github.com/hacka-re/cli/internal/toolcache"
+	"github.com/hacka-re/cli/internal/toolpolicy"
 )
 
 const (
@@ -47,6 +51,16 @@ func NewServer(apiKey string) (*Server, error) {
 	// Set system prompt
 	s.mcpServer.SetSystemPrompt(s.getSystemPrompt())
 
+	// Reuse results for the tools the tool policy gives a cacheSeconds
+	policy, err := toolpolicy.Load(toolpolicy.Path())
+	if err != nil {
+		logger.Get().Warn("[Shodan] %v", err)
+	}
+	s.mcpServer.SetCache(toolcache.New(toolcache.Dir()), func(tool string) time.Duration {
+		limit, _ := policy.LimitFor(tool)
+		return limit.CacheTTL()
+	})
+
 	return s, nil
 }
 
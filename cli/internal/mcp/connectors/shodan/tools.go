@@ -144,7 +144,7 @@ func (t *Tools) HandleHostInfo(args json.RawMessage) ([]types.Content, error) {
 		return nil, err
 	}
 	
-	result := formatHostInfo(info)
+	result := FormatHostInfo(info)
 	return []types.Content{{Type: "text", Text: result}}, nil
 }
 
@@ -170,7 +170,7 @@ func (t *Tools) HandleSearch(args json.RawMessage) ([]types.Content, error) {
 		return nil, err
 	}
 	
-	result := formatSearchResults(results)
+	result := FormatSearchResults(results)
 	return []types.Content{{Type: "text", Text: result}}, nil
 }
 
@@ -216,7 +216,7 @@ func (t *Tools) HandleDNSDomain(args json.RawMessage) ([]types.Content, error) {
 		return nil, err
 	}
 	
-	result := formatDomainInfo(domain)
+	result := FormatDomainInfo(domain)
 	return []types.Content{{Type: "text", Text: result}}, nil
 }
 
@@ -335,7 +335,8 @@ func (t *Tools) HandleMyIP(args json.RawMessage) ([]types.Content, error) {
 
 // Helper functions for formatting results
 
-func formatHostInfo(info *HostInfo) string {
+// FormatHostInfo renders a host lookup as Markdown
+func FormatHostInfo(info *HostInfo) string {
 	var output strings.Builder
 	
 	output.WriteString(fmt.Sprintf("# Shodan Host Information for %s\n\n", info.IP))
@@ -402,7 +403,8 @@ func formatHostInfo(info *HostInfo) string {
 	return output.String()
 }
 
-func formatSearchResults(results *SearchResult) string {
+// FormatSearchResults renders the first matches and facets of a search as Markdown
+func FormatSearchResults(results *SearchResult) string {
 	var output strings.Builder
 	
 	output.WriteString(fmt.Sprintf("# Shodan Search Results\n\n"))
@@ -449,7 +451,8 @@ func formatSearchResults(results *SearchResult) string {
 	return output.String()
 }
 
-func formatDomainInfo(domain *DNSDomain) string {
+// FormatDomainInfo renders a domain's subdomains and DNS records as Markdown
+func FormatDomainInfo(domain *DNSDomain) string {
 	var output strings.Builder
 	
 	output.WriteString(fmt.Sprintf("# DNS Information for %s\n\n", domain.Domain))
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Probe initializes a session with the MCP server at url over HTTP and
+// returns the names of its tools. Servers may answer with JSON or with a
+// server-sent event stream.
+func Probe(ctx context.Context, client *http.Client, url string) ([]string, error) {
+	p := &prober{client: client, url: url}
+
+	initParams := map[string]interface{}{
+		"protocolVersion": MCPProtocolVersion,
+		"clientInfo":      map[string]string{"name": "hacka.re", "version": "1.0"},
+		"capabilities":    map[string]interface{}{},
+	}
+	if _, err := p.call(ctx, 1, "initialize", initParams); err != nil {
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+	if _, err := p.call(ctx, nil, "notifications/initialized", nil); err != nil {
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	result, err := p.call(ctx, 2, "tools/list", map[string]interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	var list struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(result, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse tool list: %w", err)
+	}
+	names := make([]string, 0, len(list.Tools))
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	return names, nil
+}
+
+// prober sends JSON-RPC messages to one server, keeping its session ID
+type prober struct {
+	client    *http.Client
+	url       string
+	sessionID string
+}
+
+// call sends a request, or a notification when id is nil, and returns the result
+func (p *prober) call(ctx context.Context, id interface{}, method string, params interface{}) (json.RawMessage, error) {
+	req := Request{JSONRPC: JSONRPCVersion, ID: id, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		req.Params = data
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	if p.sessionID != "" {
+		httpReq.Header.Set("Mcp-Session-Id", p.sessionID)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	if sessionID := resp.Header.Get("Mcp-Session-Id"); sessionID != "" {
+		p.sessionID = sessionID
+	}
+	if id == nil {
+		return nil, nil
+	}
+
+	data, err := readMessage(resp)
+	if err != nil {
+		return nil, err
+	}
+	var response Response
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if response.Error != nil {
+		return nil, response.Error
+	}
+	return response.Result, nil
+}
+
+// readMessage returns the JSON body, or the first data line of an event stream
+func readMessage(resp *http.Response) ([]byte, error) {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data:"); ok {
+			return []byte(strings.TrimSpace(data)), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("event stream ended without a message")
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/mcp/types"
+)
+
+// httpServer serves s over HTTP, one JSON-RPC message per POST
+func httpServer(t *testing.T, s *Server, stream bool) *httptest.Server {
+	s.registerHandlers()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		response, err := s.protocol.HandleMessage(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Mcp-Session-Id", "session-1")
+		if response == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", response)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProbe(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		s := NewServer("test", "1.0")
+		handler := func(json.RawMessage) ([]types.Content, error) { return nil, nil }
+		s.RegisterTool(&types.Tool{Name: "lookup", InputSchema: json.RawMessage(`{}`)}, handler)
+		s.RegisterTool(&types.Tool{Name: "scan", InputSchema: json.RawMessage(`{}`)}, handler)
+		server := httpServer(t, s, stream)
+
+		tools, err := Probe(context.Background(), server.Client(), server.URL)
+		if err != nil {
+			t.Fatalf("stream=%v: %v", stream, err)
+		}
+		if len(tools) != 2 {
+			t.Errorf("stream=%v: expected 2 tools, got %v", stream, tools)
+		}
+	}
+}
+
+func TestProbeFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	if _, err := Probe(context.Background(), server.Client(), server.URL); err == nil {
+		t.Error("expected an error from a server that refuses the session")
+	}
+}
@@ -2,13 +2,20 @@ package mcp
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"sync"
+	"time"
 
+	"github.com/hacka-re/cli/internal/auditlog"
 	"github.com/hacka-re/cli/internal/logger"
 	"github.com/hacka-re/cli/internal/mcp/types"
+	"github.com/hacka-re/cli/internal/metrics"
+	"github.com/hacka-re/cli/internal/toolcache"
+	"github.com/hacka-re/cli/internal/tracing"
+	"github.com/hacka-re/cli/internal/webhook"
 )
 
 // Server represents an MCP server
@@ -23,6 +30,8 @@ type Server struct {
 	initialised bool
 	capabilities types.Capabilities
 	systemPrompt string
+	cache        *toolcache.Cache
+	cacheTTL     func(tool string) time.Duration
 }
 
 // NewServer creates a new MCP server
@@ -49,6 +58,15 @@ func (s *Server) SetSystemPrompt(prompt string) {
 	s.systemPrompt = prompt
 }
 
+// SetCache reuses tool results from cache for the time ttl returns for the
+// tool; tools it returns zero for are always run
+func (s *Server) SetCache(cache *toolcache.Cache, ttl func(tool string) time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = cache
+	s.cacheTTL = ttl
+}
+
 // RegisterTool registers a tool with the server
 func (s *Server) RegisterTool(tool *types.Tool, handler types.ToolHandler) {
 	s.mu.Lock()
@@ -204,16 +222,49 @@ func (s *Server) handleCallTool(params json.RawMessage) (interface{}, error) {
 		s.mu.RUnlock()
 		return nil, NewError(InvalidRequest, "Server not initialized", nil)
 	}
+	cache, ttl := s.cache, time.Duration(0)
+	if cache != nil && s.cacheTTL != nil {
+		ttl = s.cacheTTL(req.Name)
+	}
 	s.mu.RUnlock()
 	
 	logger.Get().Info("[MCP Server] Calling tool: %s", req.Name)
 	
+	_, span := tracing.Start(context.Background(), "tool.execute")
+	span.SetAttribute("tool.name", req.Name)
+	span.SetAttribute("tool.runtime", "mcp")
+	if ttl > 0 {
+		if entry, ok := cache.Get(req.Name, req.Arguments); ok {
+			var content []types.Content
+			if err := json.Unmarshal(entry.Result, &content); err == nil {
+				span.SetAttribute("tool.cache", "hit")
+				span.End(nil)
+				logger.Get().Info("[MCP Server] Cache hit for %s, stored %s", req.Name, entry.Stored.Format(time.RFC3339))
+				return types.CallToolResponse{
+					Content: content,
+					Meta:    map[string]interface{}{"cached": entry.Stored.Format(time.RFC3339)},
+				}, nil
+			}
+		}
+		span.SetAttribute("tool.cache", "miss")
+	}
+	start := time.Now()
 	content, err := s.toolReg.ExecuteTool(req.Name, req.Arguments)
+	span.End(err)
+	metrics.ToolExecutions.Inc("mcp", metrics.Status(err))
+	webhook.EmitToolExecuted(req.Name, "mcp", start, req.Arguments, err)
+	auditlog.EmitToolExecuted(req.Name, "mcp", start, req.Arguments, err)
 	if err != nil {
 		logger.Get().Error("[MCP Server] Tool execution failed: %v", err)
 		return nil, NewError(InternalError, fmt.Sprintf("Tool execution failed: %v", err), nil)
 	}
 	
+	if ttl > 0 {
+		if err := cache.Put(req.Name, req.Arguments, content, ttl); err != nil {
+			logger.Get().Warn("[MCP Server] %v", err)
+		}
+	}
+	
 	return types.CallToolResponse{
 		Content: content,
 	}, nil
@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/netguard"
 )
 
 // Transport defines the interface for MCP transports
@@ -229,7 +230,7 @@ type HTTPTransport struct {
 func NewHTTPTransport(url string) *HTTPTransport {
 	return &HTTPTransport{
 		url:      url,
-		client:   &http.Client{},
+		client:   netguard.MCPClient(0),
 		recvChan: make(chan []byte, 100),
 		stopChan: make(chan struct{}),
 	}
@@ -68,7 +68,8 @@ type CallToolRequest struct {
 
 // CallToolResponse contains the result of tool execution
 type CallToolResponse struct {
-	Content []Content `json:"content"`
+	Content []Content              `json:"content"`
+	Meta    map[string]interface{} `json:"_meta,omitempty"` // e.g. "cached" with the time a reused result was stored
 }
 
 // Content represents a piece of content (text, image, etc.)
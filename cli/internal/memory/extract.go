@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// MaxBlockChars bounds the memory block added to the system prompt
+const MaxBlockChars = 2000
+
+// blockHeading introduces the facts in the system prompt
+const blockHeading = "Long-term memory (facts and preferences the user approved in earlier conversations):"
+
+// Block renders facts as a compact list for the system prompt, newest first
+// when they don't all fit, or "" when there are none
+func Block(facts []Fact) string {
+	if len(facts) == 0 {
+		return ""
+	}
+	var lines []string
+	size := len(blockHeading)
+	for i := len(facts) - 1; i >= 0; i-- {
+		line := "- " + facts[i].Text
+		if size+len(line)+1 > MaxBlockChars {
+			break
+		}
+		lines = append([]string{line}, lines...)
+		size += len(line) + 1
+	}
+	return blockHeading + "\n" + strings.Join(lines, "\n")
+}
+
+// WithBlock appends the memory block to a system prompt
+func WithBlock(systemPrompt string, facts []Fact) string {
+	block := Block(facts)
+	switch {
+	case block == "":
+		return systemPrompt
+	case systemPrompt == "":
+		return block
+	default:
+		return systemPrompt + "\n\n" + block
+	}
+}
+
+// Completer sends chat completions; *api.Client implements it
+type Completer interface {
+	SendChatCompletionContext(ctx context.Context, messages []api.Message, streamCallback api.StreamCallback) (*api.ChatResponse, error)
+}
+
+// extractPrompt asks for durable facts as a JSON list of strings
+const extractPrompt = `You maintain a long-term memory of a user across conversations.
+From the conversation below, list durable facts about the user and their
+preferences that will still matter in future conversations: their role,
+projects, tools and environment, and how they like answers written.
+Skip one-off requests, the content of answers, secrets, credentials and
+anything already in the known list. Write each fact as one short sentence
+in the third person ("Prefers Go over Python.").
+
+Reply with JSON only, no prose: ["fact", ...]. Reply [] if there is nothing new.`
+
+// Extract asks the model for new facts worth remembering. The caller must let
+// the user approve each one before storing it.
+func Extract(ctx context.Context, client Completer, messages []api.Message, known []Fact) ([]string, error) {
+	var prompt strings.Builder
+	prompt.WriteString("Known facts:\n")
+	for _, fact := range known {
+		prompt.WriteString("- " + fact.Text + "\n")
+	}
+	prompt.WriteString("\nConversation:\n")
+	for _, msg := range messages {
+		if msg.Role == "user" || msg.Role == "assistant" {
+			fmt.Fprintf(&prompt, "[%s] %s\n", msg.Role, msg.Content)
+		}
+	}
+
+	response, err := client.SendChatCompletionContext(ctx, []api.Message{
+		{Role: "system", Content: extractPrompt},
+		{Role: "user", Content: prompt.String()},
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("memory extraction failed: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("memory extraction failed: empty response")
+	}
+
+	reply := response.Choices[0].Message.Content
+	start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("memory extraction returned no JSON list")
+	}
+	var candidates []string
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &candidates); err != nil {
+		return nil, fmt.Errorf("memory extraction returned invalid JSON: %w", err)
+	}
+
+	var facts []string
+	for _, candidate := range candidates {
+		candidate = strings.Join(strings.Fields(candidate), " ")
+		if candidate == "" || len(candidate) > MaxFactLength || isKnown(candidate, known) {
+			continue
+		}
+		facts = append(facts, candidate)
+	}
+	return facts, nil
+}
+
+func isKnown(text string, known []Fact) bool {
+	for _, fact := range known {
+		if strings.EqualFold(fact.Text, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+func TestStoreNamespaces(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "memory.json"))
+
+	first, err := store.Add("", "Prefers  Go over Python.", "manual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := store.Add("default", "prefers go over python.", "chat"); again.ID != first.ID {
+		t.Error("duplicate fact was stored twice")
+	}
+	if _, err := store.Add("work", "Works on the red team.", "chat"); err != nil {
+		t.Fatal(err)
+	}
+
+	facts, _ := store.Facts(DefaultNamespace)
+	if len(facts) != 1 || facts[0].Text != "Prefers Go over Python." {
+		t.Fatalf("default facts = %+v", facts)
+	}
+	if err := store.Update("", first.ID, "Prefers Go."); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Remove("work", first.ID); !errors.Is(err, ErrFactNotFound) {
+		t.Errorf("removing a fact from another namespace: %v", err)
+	}
+
+	reloaded := NewStore(store.path)
+	if facts, _ := reloaded.Facts(""); len(facts) != 1 || facts[0].Text != "Prefers Go." {
+		t.Errorf("reloaded facts = %+v", facts)
+	}
+	if err := reloaded.Clear("work"); err != nil {
+		t.Fatal(err)
+	}
+	if facts, _ := reloaded.Facts("work"); len(facts) != 0 {
+		t.Errorf("cleared namespace still has %+v", facts)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	if got := WithBlock("Be brief.", nil); got != "Be brief." {
+		t.Errorf("WithBlock without facts = %q", got)
+	}
+
+	var facts []Fact
+	for i := 0; i < 100; i++ {
+		facts = append(facts, Fact{Text: strings.Repeat("x", 40) + string(rune('a'+i%26))})
+	}
+	facts[99].Text = "Newest fact."
+	block := Block(facts)
+	if len(block) > MaxBlockChars || !strings.HasSuffix(block, "- Newest fact.") {
+		t.Errorf("Block() kept %d chars ending %q", len(block), block[len(block)-20:])
+	}
+}
+
+type reply string
+
+func (r reply) SendChatCompletionContext(ctx context.Context, messages []api.Message, cb api.StreamCallback) (*api.ChatResponse, error) {
+	return &api.ChatResponse{Choices: []api.Choice{{Message: api.Message{Content: string(r)}}}}, nil
+}
+
+func TestExtract(t *testing.T) {
+	known := []Fact{{Text: "Uses Kali Linux."}}
+	messages := []api.Message{{Role: "user", Content: "I'm on Kali, answer in Swedish please"}}
+
+	facts, err := Extract(context.Background(), reply("```json\n[\"uses kali linux.\", \"Wants answers in Swedish.\", \"\"]\n```"), messages, known)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(facts) != 1 || facts[0] != "Wants answers in Swedish." {
+		t.Errorf("Extract() = %q", facts)
+	}
+
+	if _, err := Extract(context.Background(), reply("Nothing to add."), messages, known); err == nil {
+		t.Error("expected an error for a reply without JSON")
+	}
+}
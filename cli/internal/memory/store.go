@@ -0,0 +1,210 @@
+// Package memory keeps long-term facts and preferences learned from
+// conversations, per namespace, and renders them as a compact block for the
+// system prompt. Facts are only stored after the user approves them.
+package memory
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultNamespace is used when the configuration has no namespace
+const DefaultNamespace = "default"
+
+// MaxFactLength keeps single facts short enough for the memory block
+const MaxFactLength = 300
+
+// ErrFactNotFound is returned for operations on an unknown fact ID
+var ErrFactNotFound = errors.New("no such memory")
+
+// Fact is one remembered statement
+type Fact struct {
+	ID        string    `json:"id"`
+	Text      string    `json:"text"`
+	Source    string    `json:"source"` // "chat" (extracted and approved) or "manual"
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// memoryFile is the on-disk format
+type memoryFile struct {
+	Namespaces map[string][]Fact `json:"namespaces"`
+}
+
+// Store persists facts in a JSON file
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStore creates a store backed by path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the default location of the memory file
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-memory.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "memory.json")
+}
+
+// Namespace returns ns, or DefaultNamespace if it is empty
+func Namespace(ns string) string {
+	if ns = strings.TrimSpace(ns); ns != "" {
+		return ns
+	}
+	return DefaultNamespace
+}
+
+// Facts returns the facts of a namespace, oldest first
+func (s *Store) Facts(ns string) ([]Fact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return file.Namespaces[Namespace(ns)], nil
+}
+
+// Add stores a fact unless the namespace already has the same text; it returns
+// the stored (or existing) fact
+func (s *Store) Add(ns, text, source string) (Fact, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return Fact{}, errors.New("memory text is empty")
+	}
+	if len(text) > MaxFactLength {
+		return Fact{}, fmt.Errorf("memory is too long (%d characters, max %d)", len(text), MaxFactLength)
+	}
+
+	var fact Fact
+	err := s.update(ns, func(facts []Fact) ([]Fact, error) {
+		for _, existing := range facts {
+			if strings.EqualFold(existing.Text, text) {
+				fact = existing
+				return facts, nil
+			}
+		}
+		fact = Fact{ID: newID(), Text: text, Source: source, CreatedAt: time.Now().UTC()}
+		return append(facts, fact), nil
+	})
+	return fact, err
+}
+
+// Update replaces the text of a fact
+func (s *Store) Update(ns, id, text string) error {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return s.Remove(ns, id)
+	}
+	if len(text) > MaxFactLength {
+		return fmt.Errorf("memory is too long (%d characters, max %d)", len(text), MaxFactLength)
+	}
+	return s.update(ns, func(facts []Fact) ([]Fact, error) {
+		for i := range facts {
+			if facts[i].ID == id {
+				facts[i].Text = text
+				return facts, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrFactNotFound, id)
+	})
+}
+
+// Remove deletes a fact
+func (s *Store) Remove(ns, id string) error {
+	return s.update(ns, func(facts []Fact) ([]Fact, error) {
+		for i := range facts {
+			if facts[i].ID == id {
+				return append(facts[:i], facts[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrFactNotFound, id)
+	})
+}
+
+// Clear deletes all facts of a namespace
+func (s *Store) Clear(ns string) error {
+	return s.update(ns, func([]Fact) ([]Fact, error) { return nil, nil })
+}
+
+// Set replaces all facts of a namespace, as when restoring a snapshot
+func (s *Store) Set(ns string, facts []Fact) error {
+	return s.update(ns, func([]Fact) ([]Fact, error) { return append([]Fact(nil), facts...), nil })
+}
+
+// update loads the file, applies fn to one namespace and saves the result
+func (s *Store) update(ns string, fn func([]Fact) ([]Fact, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.load()
+	if err != nil {
+		return err
+	}
+	ns = Namespace(ns)
+	facts, err := fn(append([]Fact(nil), file.Namespaces[ns]...))
+	if err != nil {
+		return err
+	}
+	if len(facts) == 0 {
+		delete(file.Namespaces, ns)
+	} else {
+		file.Namespaces[ns] = facts
+	}
+	return s.save(file)
+}
+
+func (s *Store) load() (*memoryFile, error) {
+	file := &memoryFile{Namespaces: map[string][]Fact{}}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memory: %w", err)
+	}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse memory %s: %w", s.path, err)
+	}
+	if file.Namespaces == nil {
+		file.Namespaces = map[string][]Fact{}
+	}
+	return file, nil
+}
+
+func (s *Store) save(file *memoryFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode memory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create memory directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write memory: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// newID returns a short random identifier
+func newID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+	return hex.EncodeToString(b)
+}
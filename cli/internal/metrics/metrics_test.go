@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCounterText(t *testing.T) {
+	c := NewCounterVec("test_counter_total", "A test counter", "provider", "status")
+	c.Inc("openai", "ok")
+	c.Inc("openai", "ok")
+	c.Add(3, "groq", "error")
+
+	if got := c.Value("openai", "ok"); got != 2 {
+		t.Errorf("expected 2, got %v", got)
+	}
+
+	var buf bytes.Buffer
+	c.write(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE test_counter_total counter",
+		`test_counter_total{provider="groq",status="error"} 3`,
+		`test_counter_total{provider="openai",status="ok"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestHistogramText(t *testing.T) {
+	h := NewHistogramVec("test_latency_seconds", "A test histogram", []float64{1, 5}, "provider")
+	h.Observe(0.5, "openai")
+	h.Observe(3, "openai")
+	h.Observe(10, "openai")
+
+	var buf bytes.Buffer
+	h.write(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE test_latency_seconds histogram",
+		`test_latency_seconds_bucket{provider="openai",le="1"} 1`,
+		`test_latency_seconds_bucket{provider="openai",le="5"} 2`,
+		`test_latency_seconds_bucket{provider="openai",le="+Inf"} 3`,
+		`test_latency_seconds_sum{provider="openai"} 13.5`,
+		`test_latency_seconds_count{provider="openai"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	if got := labelKey([]string{"tool"}, []string{`say "hi"`}); got != `{tool="say \"hi\""}` {
+		t.Errorf("unexpected label key %s", got)
+	}
+}
@@ -35,6 +35,7 @@ type ModelMetadata struct {
 	Capabilities    []string       `json:"capabilities"`
 	PricingInput    float64        `json:"pricing_input"`  // per 1M tokens
 	PricingOutput   float64        `json:"pricing_output"` // per 1M tokens
+	PricingCachedInput float64     `json:"pricing_cached_input,omitempty"` // per 1M prompt-cache hits
 	IsDefault       bool           `json:"is_default"`
 }
 
@@ -112,14 +113,14 @@ func (r *ModelRegistry) loadDefaultModels() {
 			ContextWindow: 128000, MaxTokens: 16384, Category: "production",
 			Capabilities: []string{"chat", "functions", "vision", "audio"},
 			Description: "Multimodal GPT-4 optimized model",
-			OwnedBy: "openai", PricingInput: 2.50, PricingOutput: 10.00,
+			OwnedBy: "openai", PricingInput: 2.50, PricingOutput: 10.00, PricingCachedInput: 1.25,
 		},
 		{
 			ID: "gpt-4o-mini", Provider: ProviderOpenAI, Name: "GPT-4o Mini",
 			ContextWindow: 128000, MaxTokens: 16384, Category: "production",
 			Capabilities: []string{"chat", "functions", "vision"},
 			Description: "Smaller, faster GPT-4o variant",
-			OwnedBy: "openai", PricingInput: 0.15, PricingOutput: 0.60,
+			OwnedBy: "openai", PricingInput: 0.15, PricingOutput: 0.60, PricingCachedInput: 0.075,
 		},
 		{
 			ID: "gpt-4o-2024-08-06", Provider: ProviderOpenAI, Name: "GPT-4o (Aug 2024)",
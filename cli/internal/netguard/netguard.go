@@ -0,0 +1,171 @@
+// Package netguard keeps offline mode on the local network. Its transport
+// refuses connections to anything but loopback and private addresses,
+// checking the resolved IPs when dialing so redirects and DNS names can't
+// slip past URL checks. Every outbound HTTP client is built on it, and Dial
+// does the same for the mail gateway's IMAP and SMTP connections.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+)
+
+// ErrBlocked is returned for connections the guard refuses
+var ErrBlocked = errors.New("blocked by offline mode")
+
+var (
+	offline        atomic.Bool
+	allowRemoteMCP atomic.Bool
+)
+
+// Init turns offline mode on or off for the whole process
+func Init(enabled bool) {
+	offline.Store(enabled)
+}
+
+// AllowRemoteMCP exempts MCP clients from offline mode (--allow-remote-mcp)
+func AllowRemoteMCP(allow bool) {
+	allowRemoteMCP.Store(allow)
+}
+
+// Offline reports whether offline mode is on for the process
+func Offline() bool {
+	return offline.Load()
+}
+
+// Violation describes a connection the guard refused
+type Violation struct {
+	Target string
+	At     time.Time
+}
+
+var (
+	violationsMu  sync.Mutex
+	violations    int
+	lastViolation Violation
+)
+
+// Violations returns how many connections offline mode blocked and the latest one
+func Violations() (int, Violation) {
+	violationsMu.Lock()
+	defer violationsMu.Unlock()
+	return violations, lastViolation
+}
+
+// RecordViolation counts and logs a blocked connection, for callers that
+// refuse a request before it reaches the transport
+func RecordViolation(target string) {
+	violationsMu.Lock()
+	violations++
+	lastViolation = Violation{Target: target, At: time.Now()}
+	violationsMu.Unlock()
+
+	if log := logger.Get(); log != nil {
+		log.Warn("[OfflineGuard] Blocked remote connection to %s", target)
+	}
+}
+
+// guard sends a request through local when offline reports true for it, and
+// through remote otherwise. The two transports keep separate connection pools
+// so a local-only request never reuses a connection opened by an exempt one.
+type guard struct {
+	offline func(req *http.Request) bool
+	remote  http.RoundTripper
+	local   http.RoundTripper
+}
+
+// NewTransport wraps base so that requests for which offline returns true
+// only reach local addresses. base keeps its own settings, such as timeouts.
+func NewTransport(base *http.Transport, offline func(req *http.Request) bool) http.RoundTripper {
+	local := base.Clone()
+	dial := base.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	local.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		resolved, err := ResolveLocal(ctx, address)
+		if err != nil {
+			RecordViolation(address)
+			return nil, err
+		}
+		// Dial the checked IP rather than the name so DNS can't change the answer meanwhile
+		return dial(ctx, network, resolved)
+	}
+	local.Proxy = nil // A proxy would make the remote hop invisible to the dial check
+
+	return &guard{offline: offline, remote: base.Clone(), local: local}
+}
+
+// RoundTrip implements http.RoundTripper
+func (g *guard) RoundTrip(req *http.Request) (*http.Response, error) {
+	if g.offline(req) {
+		return g.local.RoundTrip(req)
+	}
+	return g.remote.RoundTrip(req)
+}
+
+// Transport returns a transport that follows the process-wide offline mode
+func Transport() http.RoundTripper {
+	return NewTransport(http.DefaultTransport.(*http.Transport), func(*http.Request) bool {
+		return Offline()
+	})
+}
+
+// Client returns an HTTP client with the given overall timeout (0 for none)
+// that follows the process-wide offline mode
+func Client(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: Transport()}
+}
+
+// MCPClient is Client for connections to MCP servers, which
+// --allow-remote-mcp exempts from offline mode
+func MCPClient(timeout time.Duration) *http.Client {
+	transport := NewTransport(http.DefaultTransport.(*http.Transport), func(*http.Request) bool {
+		return Offline() && !allowRemoteMCP.Load()
+	})
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// Dial connects with dialer, only to local addresses while offline mode is
+// on. It is for protocols other than HTTP, such as IMAP and SMTP.
+func Dial(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	if Offline() {
+		resolved, err := ResolveLocal(ctx, address)
+		if err != nil {
+			RecordViolation(address)
+			return nil, err
+		}
+		address = resolved
+	}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// ResolveLocal resolves a host:port and returns it with the host replaced by
+// its IP, refusing it unless every address of the host is local
+func ResolveLocal(ctx context.Context, address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBlocked, err)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("%w: cannot resolve %s: %v", ErrBlocked, host, err)
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("%w: %s has no addresses", ErrBlocked, host)
+	}
+	for _, addr := range addrs {
+		if !addr.IP.IsLoopback() && !addr.IP.IsPrivate() && !addr.IP.IsUnspecified() {
+			return "", fmt.Errorf("%w: %s resolves to remote address %s", ErrBlocked, host, addr.IP)
+		}
+	}
+	return net.JoinHostPort(addrs[0].IP.String(), port), nil
+}
@@ -0,0 +1,90 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResolveLocal(t *testing.T) {
+	if _, err := ResolveLocal(context.Background(), "127.0.0.1:8080"); err != nil {
+		t.Errorf("expected loopback to be allowed, got %v", err)
+	}
+	if _, err := ResolveLocal(context.Background(), "192.168.1.10:11434"); err != nil {
+		t.Errorf("expected a private address to be allowed, got %v", err)
+	}
+	if _, err := ResolveLocal(context.Background(), "8.8.8.8:443"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("expected a public address to be blocked, got %v", err)
+	}
+}
+
+func TestClientBlocksRedirectToRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://8.8.8.8/exfiltrate", http.StatusFound)
+	}))
+	defer server.Close()
+
+	Init(true)
+	t.Cleanup(func() { Init(false) })
+
+	before, _ := Violations()
+	client := Client(5 * time.Second)
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, ErrBlocked) {
+		t.Fatalf("expected the redirect to be blocked, got %v", err)
+	}
+	if after, last := Violations(); after != before+1 || last.Target != "8.8.8.8:80" {
+		t.Errorf("expected the violation to be recorded, got %d (%+v)", after, last)
+	}
+
+	// The local server itself is reachable
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer local.Close()
+	resp, err := client.Get(local.URL)
+	if err != nil {
+		t.Fatalf("expected a local request to pass, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestMCPClientExemption(t *testing.T) {
+	Init(true)
+	AllowRemoteMCP(true)
+	t.Cleanup(func() {
+		Init(false)
+		AllowRemoteMCP(false)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://8.8.8.8/", nil)
+	if g := MCPClient(0).Transport.(*guard); g.offline(req) {
+		t.Error("expected --allow-remote-mcp to exempt MCP clients")
+	}
+	if g := Client(0).Transport.(*guard); !g.offline(req) {
+		t.Error("expected other clients to stay local")
+	}
+}
+
+func TestDial(t *testing.T) {
+	Init(true)
+	t.Cleanup(func() { Init(false) })
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if _, err := Dial(context.Background(), dialer, "tcp", "8.8.8.8:25"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("expected a public address to be blocked, got %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	conn, err := Dial(context.Background(), dialer, "tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("expected a local connection to pass, got %v", err)
+	}
+	conn.Close()
+}
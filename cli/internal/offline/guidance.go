@@ -3,6 +3,8 @@ package offline
 import (
 	"fmt"
 	"strings"
+
+	"github.com/hacka-re/cli/internal/hardware"
 )
 
 // ShowConflictError displays an error message for conflicting configuration
@@ -40,6 +42,7 @@ func ShowNoProviderGuidance() {
 	fmt.Println("═══ OFFLINE OPTIONS (No Internet Required) ═══")
 	fmt.Println()
 
+	ShowHardwareRecommendations()
 	showLlamafileGuidance()
 	showOllamaGuidance()
 	showLMStudioGuidance()
@@ -51,6 +54,23 @@ func ShowNoProviderGuidance() {
 	showRemoteProvidersGuidance()
 }
 
+// ShowHardwareRecommendations lists the local models that fit this machine
+func ShowHardwareRecommendations() {
+	info := hardware.Detect()
+	fmt.Println("Your machine: " + info.Summary())
+	recommendations := hardware.Recommend(info)
+	if len(recommendations) == 0 {
+		fmt.Println("   Could not find a local model that fits the detected memory.")
+		fmt.Println()
+		return
+	}
+	fmt.Println("   Models that fit:")
+	for _, rec := range recommendations {
+		fmt.Println("     " + rec.String())
+	}
+	fmt.Println()
+}
+
 func showLlamafileGuidance() {
 	fmt.Println("1. Llamafile (Simplest - Single Executable)")
 	fmt.Println("   " + strings.Repeat("─", 40))
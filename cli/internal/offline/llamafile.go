@@ -4,27 +4,71 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/logger"
 )
 
-// LlamafileManager manages a llamafile process
+// LlamafileManager manages a llamafile process. After Start, it restarts the
+// server with backoff if it crashes, until Stop is called.
 type LlamafileManager struct {
 	FilePath  string
 	Port      int
 	Process   *exec.Cmd
 	BaseURL   string
 	ModelName string
+	Options   LlamafileOptions
 	Ready     chan bool
 	readyOnce sync.Once // Ensures ready signal is sent only once
+
+	mu       sync.Mutex
+	stopping bool
+	exited   chan struct{} // Closed when the current process has exited
+}
+
+// LlamafileOptions are passed to the llamafile server; zero values keep its defaults
+type LlamafileOptions struct {
+	ContextSize int    // --ctx-size
+	Threads     int    // --threads
+	GPULayers   int    // --n-gpu-layers
+	GPU         string // --gpu: auto, apple, amd, nvidia or disable
+}
+
+// Args returns the command line flags for the options
+func (o LlamafileOptions) Args() []string {
+	var args []string
+	if o.ContextSize > 0 {
+		args = append(args, "--ctx-size", strconv.Itoa(o.ContextSize))
+	}
+	if o.Threads > 0 {
+		args = append(args, "--threads", strconv.Itoa(o.Threads))
+	}
+	if o.GPULayers > 0 {
+		args = append(args, "--n-gpu-layers", strconv.Itoa(o.GPULayers))
+	}
+	if o.GPU != "" {
+		args = append(args, "--gpu", o.GPU)
+	}
+	return args
 }
 
+const (
+	llamafileStartTimeout = 30 * time.Second
+	llamafileMaxRestarts  = 5
+	llamafileMaxBackoff   = 30 * time.Second
+	llamafileStableAfter  = time.Minute // A run this long resets the restart count
+)
+
 // ModelsResponse represents the response from /v1/models endpoint
 type ModelsResponse struct {
 	Data []struct {
@@ -60,136 +104,252 @@ func NewLlamafileManager(filePath string) (*LlamafileManager, error) {
 	}, nil
 }
 
-// Start starts the llamafile server
+// Start starts the llamafile server and returns once it answers health checks
 func (lm *LlamafileManager) Start() error {
-	// Build command with arguments
-	// Use sh -c to properly handle the llamafile's special format
-	// This allows the shell to handle the executable format correctly
+	if err := lm.spawn(); err != nil {
+		return err
+	}
+	if err := lm.waitReady(llamafileStartTimeout); err != nil {
+		lm.Stop()
+		return err
+	}
+
+	lm.readyOnce.Do(func() {
+		lm.Ready <- true
+		close(lm.Ready)
+	})
+	go lm.supervise()
+	return nil
+}
+
+// spawn launches the llamafile process and records its PID
+func (lm *LlamafileManager) spawn() error {
+	// Use sh -c to properly handle the llamafile's special format.
+	// exec replaces the shell so the PID is the server's own and signals reach it.
 	// Quote the filepath in case it contains spaces
-	cmdString := fmt.Sprintf("'%s' --server --port %d --nobrowser", lm.FilePath, lm.Port)
+	cmdString := fmt.Sprintf("exec '%s' --server --port %d --nobrowser", lm.FilePath, lm.Port)
+	for _, arg := range lm.Options.Args() {
+		cmdString += " '" + arg + "'"
+	}
 
-	lm.Process = exec.Command("sh", "-c", cmdString)
+	cmd := exec.Command("sh", "-c", cmdString)
 
 	// Set environment to ensure proper execution
-	lm.Process.Env = os.Environ()
+	cmd.Env = os.Environ()
 
-	// Capture stdout for monitoring
-	stdout, err := lm.Process.StdoutPipe()
+	// Capture stdout and stderr for debugging
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
-
-	// Also capture stderr for debugging
-	stderr, err := lm.Process.StderrPipe()
+	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
-	// Start the process
-	if err := lm.Process.Start(); err != nil {
+	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start llamafile: %w", err)
 	}
 
-	// Monitor both stdout and stderr for readiness in goroutines
-	readySignals := []string{
-		"server listening",
-		"llama server listening",
-		"HTTP server listening",
-		"server:", // Common llamafile output pattern
-		"http://127.0.0.1", // URL pattern
-		"http://localhost", // URL pattern
+	// Only show the server output if HACKARE_DEBUG is set
+	for name, pipe := range map[string]io.Reader{"stdout": stdout, "stderr": stderr} {
+		go func(name string, pipe io.Reader) {
+			scanner := bufio.NewScanner(pipe)
+			for scanner.Scan() {
+				if os.Getenv("HACKARE_DEBUG") != "" {
+					fmt.Printf("[llamafile %s] %s\n", name, scanner.Text())
+				}
+			}
+		}(name, pipe)
 	}
 
-	checkReady := func(line string) bool {
-		for _, signal := range readySignals {
-			if strings.Contains(strings.ToLower(line), signal) {
-				return true
-			}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	lm.mu.Lock()
+	lm.Process = cmd
+	lm.exited = exited
+	lm.mu.Unlock()
+
+	if err := writePIDFile(cmd.Process.Pid); err != nil {
+		if log := logger.Get(); log != nil {
+			log.Warn("[Llamafile] Failed to write PID file: %v", err)
 		}
-		return false
 	}
+	return nil
+}
 
-	// Signal ready only once using sync.Once
-	signalReady := func() {
-		lm.readyOnce.Do(func() {
-			lm.Ready <- true
-			close(lm.Ready)
-		})
+// waitReady polls the health check until the server answers, the process exits or timeout passes
+func (lm *LlamafileManager) waitReady(timeout time.Duration) error {
+	lm.mu.Lock()
+	exited := lm.exited
+	lm.mu.Unlock()
+
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if lm.HealthCheck() {
+			return nil
+		}
+		select {
+		case <-exited:
+			return fmt.Errorf("llamafile server exited during startup")
+		case <-deadline:
+			return fmt.Errorf("llamafile server failed to start within %s", timeout)
+		case <-ticker.C:
+		}
 	}
+}
 
-	// Monitor stdout
-	go func() {
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Only show debug output if HACKARE_DEBUG is set
-			if os.Getenv("HACKARE_DEBUG") != "" {
-				fmt.Printf("[llamafile stdout] %s\n", line)
-			}
-			if checkReady(line) {
-				signalReady()
-				break
-			}
+// supervise restarts the server with exponential backoff when it exits unexpectedly
+func (lm *LlamafileManager) supervise() {
+	restarts := 0
+	started := time.Now()
+	for {
+		lm.mu.Lock()
+		exited := lm.exited
+		lm.mu.Unlock()
+		<-exited
+
+		lm.mu.Lock()
+		stopping := lm.stopping
+		lm.mu.Unlock()
+		if stopping {
+			return
 		}
-	}()
 
-	// Monitor stderr (llamafile often outputs to stderr)
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// Only show debug output if HACKARE_DEBUG is set
-			if os.Getenv("HACKARE_DEBUG") != "" {
-				fmt.Printf("[llamafile stderr] %s\n", line)
-			}
-			if checkReady(line) {
-				signalReady()
-				break
+		if time.Since(started) > llamafileStableAfter {
+			restarts = 0
+		}
+		if restarts >= llamafileMaxRestarts {
+			if log := logger.Get(); log != nil {
+				log.Error("[Llamafile] Server crashed %d times, giving up", restarts)
 			}
+			removePIDFile()
+			return
 		}
-	}()
 
-	// Wait for server to be ready or timeout
-	select {
-	case <-lm.Ready:
-		// Server is ready
-		time.Sleep(500 * time.Millisecond) // Give it a bit more time to fully initialize
-	case <-time.After(30 * time.Second):
-		// Timeout - but check if server is actually responding
-		if !lm.HealthCheck() {
-			lm.Stop()
-			return fmt.Errorf("llamafile server failed to start within 30 seconds")
+		backoff := time.Second << restarts
+		if backoff > llamafileMaxBackoff {
+			backoff = llamafileMaxBackoff
 		}
-	}
+		restarts++
+		if log := logger.Get(); log != nil {
+			log.Warn("[Llamafile] Server exited unexpectedly, restarting in %s (attempt %d)", backoff, restarts)
+		}
+		time.Sleep(backoff)
 
-	return nil
+		started = time.Now()
+		if err := lm.spawn(); err != nil {
+			if log := logger.Get(); log != nil {
+				log.Error("[Llamafile] Restart failed: %v", err)
+			}
+			return
+		}
+		if err := lm.waitReady(llamafileStartTimeout); err != nil {
+			if log := logger.Get(); log != nil {
+				log.Warn("[Llamafile] Restarted server is not ready: %v", err)
+			}
+		}
+	}
 }
 
-// Stop stops the llamafile server
+// Stop stops the llamafile server and the restarts
 func (lm *LlamafileManager) Stop() error {
-	if lm.Process == nil || lm.Process.Process == nil {
+	lm.mu.Lock()
+	lm.stopping = true
+	cmd, exited := lm.Process, lm.exited
+	lm.mu.Unlock()
+
+	if cmd == nil || cmd.Process == nil {
 		return nil
 	}
+	defer removePIDFile()
 
 	// Try graceful shutdown first
-	if err := lm.Process.Process.Signal(os.Interrupt); err != nil {
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
 		// If interrupt fails, force kill
-		return lm.Process.Process.Kill()
+		return cmd.Process.Kill()
 	}
 
-	// Wait for process to exit
-	done := make(chan error, 1)
-	go func() {
-		done <- lm.Process.Wait()
-	}()
-
 	select {
-	case <-done:
+	case <-exited:
 		return nil
 	case <-time.After(5 * time.Second):
 		// Force kill if graceful shutdown takes too long
-		return lm.Process.Process.Kill()
+		return cmd.Process.Kill()
+	}
+}
+
+// StopRunning stops a llamafile server started by another hacka.re process,
+// found through the PID file. It returns an error if none is running.
+func StopRunning() error {
+	data, err := os.ReadFile(pidFilePath())
+	if err != nil {
+		return fmt.Errorf("no llamafile server is running")
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		removePIDFile()
+		return fmt.Errorf("invalid PID file: %w", err)
 	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		removePIDFile()
+		return fmt.Errorf("no llamafile server is running")
+	}
+	if err := process.Signal(os.Interrupt); err != nil {
+		removePIDFile()
+		return fmt.Errorf("no llamafile server is running (stale PID %d)", pid)
+	}
+
+	// The owning process removes the PID file when the server exits;
+	// wait for that, then make sure
+	for i := 0; i < 50; i++ {
+		if _, err := os.Stat(pidFilePath()); os.IsNotExist(err) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	process.Kill()
+	removePIDFile()
+	return nil
+}
+
+// LlamafileOptionsFromConfig reads the llamafile options from the configuration file
+func LlamafileOptionsFromConfig() LlamafileOptions {
+	cfg, err := config.LoadFromFile(config.GetConfigPath())
+	if err != nil {
+		return LlamafileOptions{}
+	}
+	return LlamafileOptions{
+		ContextSize: cfg.LlamafileContextSize,
+		Threads:     cfg.LlamafileThreads,
+		GPULayers:   cfg.LlamafileGPULayers,
+		GPU:         cfg.LlamafileGPU,
+	}
+}
+
+// pidFilePath is where the PID of the running llamafile server is kept
+func pidFilePath() string {
+	return filepath.Join(filepath.Dir(config.GetConfigPath()), "llamafile.pid")
+}
+
+func writePIDFile(pid int) error {
+	path := pidFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strconv.Itoa(pid)), 0644)
+}
+
+func removePIDFile() {
+	os.Remove(pidFilePath())
 }
 
 // HealthCheck checks if the llamafile server is responding
@@ -35,6 +35,7 @@ func RunOfflineMode(originalSharedConfig *share.SharedConfig, originalPassword s
 		var err error
 		llamafilePath, err = AutoDetectLlamafile()
 		if err != nil {
+			ShowHardwareRecommendations()
 			return nil, nil, fmt.Errorf("llamafile not found: %w\nPlease set HACKARE_LLAMAFILE environment variable to the path of your llamafile", err)
 		}
 		fmt.Printf("Auto-detected llamafile: %s\n", llamafilePath)
@@ -46,6 +47,7 @@ func RunOfflineMode(originalSharedConfig *share.SharedConfig, originalPassword s
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create llamafile manager: %w", err)
 	}
+	manager.Options = LlamafileOptionsFromConfig()
 
 	fmt.Println("Starting llamafile server...")
 	if err := manager.Start(); err != nil {
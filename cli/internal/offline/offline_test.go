@@ -175,4 +175,27 @@ func TestModelsDiscovery(t *testing.T) {
 	if manager.ModelName != "llama-3.2-3b" {
 		t.Errorf("Manager ModelName = %q, want %q", manager.ModelName, "llama-3.2-3b")
 	}
-}
\ No newline at end of file
+}
+func TestLlamafileOptionsArgs(t *testing.T) {
+	if args := (LlamafileOptions{}).Args(); len(args) != 0 {
+		t.Errorf("expected no flags for default options, got %v", args)
+	}
+
+	args := LlamafileOptions{ContextSize: 8192, Threads: 4, GPULayers: 35, GPU: "nvidia"}.Args()
+	want := []string{"--ctx-size", "8192", "--threads", "4", "--n-gpu-layers", "35", "--gpu", "nvidia"}
+	if len(args) != len(want) {
+		t.Fatalf("expected %v, got %v", want, args)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, args)
+		}
+	}
+}
+
+func TestStopRunningWithoutServer(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := StopRunning(); err == nil {
+		t.Error("expected an error when no llamafile server is running")
+	}
+}
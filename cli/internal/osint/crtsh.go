@@ -0,0 +1,118 @@
+package osint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Certificate is a certificate logged for a domain
+type Certificate struct {
+	ID         int64  `json:"id"`
+	CommonName string `json:"commonName"`
+	Issuer     string `json:"issuer"`
+	NotBefore  string `json:"notBefore"`
+	NotAfter   string `json:"notAfter"`
+}
+
+// CertificateReport summarizes the certificate transparency logs of a domain
+type CertificateReport struct {
+	Domain       string         `json:"domain"`
+	Certificates int            `json:"certificates"`
+	Names        []string       `json:"names"`   // Every name the certificates cover, sorted
+	Issuers      map[string]int `json:"issuers"` // Certificates per issuer
+	Latest       []Certificate  `json:"latest"`  // The most recently issued first
+}
+
+// latestCertificates is how many certificates a report lists
+const latestCertificates = 10
+
+var validDomain = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}$`)
+
+// CertificateTransparency looks up the certificates crt.sh has seen for
+// domain and its subdomains; expired ones only if includeExpired is set
+func (c *Client) CertificateTransparency(ctx context.Context, domain string, includeExpired bool) (*CertificateReport, error) {
+	domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "*.")
+	if !validDomain.MatchString(domain) {
+		return nil, fmt.Errorf("invalid domain %q", domain)
+	}
+
+	query := url.Values{"q": {"%." + domain}, "output": {"json"}}
+	if !includeExpired {
+		query.Set("exclude", "expired")
+	}
+	resp, err := c.get(ctx, c.crtSh, c.CrtShURL+"/?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("crt.sh request failed: %s", resp.Status)
+	}
+
+	var entries []struct {
+		ID         int64  `json:"id"`
+		IssuerName string `json:"issuer_name"`
+		CommonName string `json:"common_name"`
+		NameValue  string `json:"name_value"` // The names of the certificate, one per line
+		NotBefore  string `json:"not_before"`
+		NotAfter   string `json:"not_after"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to parse crt.sh response: %w", err)
+	}
+
+	report := &CertificateReport{Domain: domain, Names: []string{}, Issuers: map[string]int{}, Latest: []Certificate{}}
+	seen := map[int64]bool{}
+	names := map[string]bool{}
+	var certificates []Certificate
+	for _, e := range entries {
+		// crt.sh lists a certificate once per log entry, often as precertificate and certificate
+		if seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+		for _, name := range strings.Split(e.NameValue, "\n") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				names[name] = true
+			}
+		}
+		report.Issuers[issuerName(e.IssuerName)]++
+		certificates = append(certificates, Certificate{e.ID, e.CommonName, issuerName(e.IssuerName), e.NotBefore, e.NotAfter})
+	}
+	report.Certificates = len(certificates)
+	for name := range names {
+		report.Names = append(report.Names, name)
+	}
+	sort.Strings(report.Names)
+	sort.Slice(certificates, func(i, j int) bool { return certificates[i].NotBefore > certificates[j].NotBefore })
+	if len(certificates) > latestCertificates {
+		certificates = certificates[:latestCertificates]
+	}
+	report.Latest = append(report.Latest, certificates...)
+	return report, nil
+}
+
+// issuerName shortens an issuer's distinguished name to its organization and
+// common name, e.g. "Let's Encrypt R3"
+func issuerName(dn string) string {
+	var org, cn string
+	for _, part := range strings.Split(dn, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "O":
+			org = strings.Trim(value, `"`)
+		case "CN":
+			cn = strings.Trim(value, `"`)
+		}
+	}
+	if org == "" && cn == "" {
+		return dn
+	}
+	return strings.TrimSpace(org + " " + cn)
+}
@@ -0,0 +1,95 @@
+package osint
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Breach is a data breach an account appeared in
+type Breach struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Domain      string   `json:"domain,omitempty"`
+	BreachDate  string   `json:"breachDate"`
+	PwnCount    int      `json:"pwnCount"`
+	DataClasses []string `json:"dataClasses"` // e.g. Email addresses, Passwords
+	IsVerified  bool     `json:"isVerified"`
+	IsSensitive bool     `json:"isSensitive,omitempty"`
+}
+
+// BreachedAccount returns the breaches an email address or username appears
+// in, with none if it isn't known to Have I Been Pwned
+func (c *Client) BreachedAccount(ctx context.Context, account string) ([]Breach, error) {
+	account = strings.TrimSpace(account)
+	if account == "" {
+		return nil, fmt.Errorf("account must not be empty")
+	}
+	if c.HIBPAPIKey == "" {
+		return nil, ErrNoAPIKey
+	}
+
+	endpoint := c.HIBPURL + "/breachedaccount/" + url.PathEscape(account) + "?truncateResponse=false"
+	resp, err := c.get(ctx, c.hibp, endpoint, http.Header{"Hibp-Api-Key": {c.HIBPAPIKey}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return []Breach{}, nil
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf("Have I Been Pwned rejected the API key in HACKARE_HIBP_API_KEY")
+	default:
+		return nil, fmt.Errorf("Have I Been Pwned request failed: %s", resp.Status)
+	}
+
+	var breaches []Breach
+	if err := json.NewDecoder(resp.Body).Decode(&breaches); err != nil {
+		return nil, fmt.Errorf("failed to parse Have I Been Pwned response: %w", err)
+	}
+	return breaches, nil
+}
+
+// PwnedPassword returns how many times password appears in the Pwned
+// Passwords corpus. Only the first five hex digits of its SHA-1 hash are
+// sent (k-anonymity), with padding so the response size gives nothing away.
+func (c *Client) PwnedPassword(ctx context.Context, password string) (int, error) {
+	if password == "" {
+		return 0, fmt.Errorf("password must not be empty")
+	}
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	// No rate limit applies to the range API
+	resp, err := c.get(ctx, &limiter{}, c.PwnedPasswordsURL+"/range/"+prefix, http.Header{"Add-Padding": {"true"}})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("Pwned Passwords request failed: %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if ok && candidate == suffix {
+			return strconv.Atoi(count) // Padding entries have a count of 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read Pwned Passwords response: %w", err)
+	}
+	return 0, nil
+}
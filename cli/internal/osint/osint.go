@@ -0,0 +1,175 @@
+// Package osint looks up public breach and certificate data: Have I Been
+// Pwned for breached accounts and passwords, and crt.sh for the certificates
+// logged for a domain. Each service has a rate limit, and offline mode makes
+// no requests at all.
+package osint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// Service URLs
+const (
+	DefaultHIBPURL           = "https://haveibeenpwned.com/api/v3"
+	DefaultPwnedPasswordsURL = "https://api.pwnedpasswords.com"
+	DefaultCrtShURL          = "https://crt.sh"
+)
+
+// userAgent is required by the HIBP API
+const userAgent = "hacka.re-cli"
+
+// RequestTimeout bounds a lookup, including any wait for the rate limit.
+// crt.sh is often slow for domains with many certificates.
+const RequestTimeout = 25 * time.Second
+
+// ErrOffline is returned for every lookup in offline mode
+var ErrOffline = errors.New("offline mode makes no requests to remote services")
+
+// ErrNoAPIKey is returned for account lookups without a HIBP API key
+var ErrNoAPIKey = errors.New("breach checks of accounts need a Have I Been Pwned API key in HACKARE_HIBP_API_KEY")
+
+// Client looks up breaches and certificates
+type Client struct {
+	HIBPURL           string
+	PwnedPasswordsURL string
+	CrtShURL          string
+	HIBPAPIKey        string
+	Offline           bool
+	HTTPClient        *http.Client
+
+	// The lowest HIBP plan allows 10 account lookups a minute; crt.sh asks
+	// clients to go easy on it
+	hibp  *limiter
+	crtSh *limiter
+}
+
+// NewClient returns a client of the public services
+func NewClient(hibpAPIKey string, offline bool) *Client {
+	return &Client{
+		HIBPURL:           DefaultHIBPURL,
+		PwnedPasswordsURL: DefaultPwnedPasswordsURL,
+		CrtShURL:          DefaultCrtShURL,
+		HIBPAPIKey:        hibpAPIKey,
+		Offline:           offline,
+		HTTPClient:        netguard.Client(RequestTimeout),
+		hibp:              &limiter{interval: 6 * time.Second},
+		crtSh:             &limiter{interval: 5 * time.Second},
+	}
+}
+
+var (
+	current   *Client
+	currentMu sync.Mutex
+)
+
+// Init sets up the client used by the OSINT functions, with the API key from
+// HACKARE_HIBP_API_KEY
+func Init(offline bool) {
+	SetDefault(NewClient(os.Getenv("HACKARE_HIBP_API_KEY"), offline))
+}
+
+// SetDefault replaces the client returned by Default
+func SetDefault(c *Client) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = c
+}
+
+// Default returns the client set up by Init, or an online client without an
+// API key if Init hasn't been called
+func Default() *Client {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current == nil {
+		current = NewClient("", false)
+	}
+	return current
+}
+
+// get requests url once the rate limit allows, with the headers given. The
+// caller closes the body of the response.
+func (c *Client) get(ctx context.Context, limit *limiter, url string, header http.Header) (*http.Response, error) {
+	if c.Offline {
+		return nil, ErrOffline
+	}
+	if err := limit.wait(ctx); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", req.URL.Host, err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		resp.Body.Close()
+		retry, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		if retry <= 0 {
+			retry = 60
+		}
+		limit.pause(time.Duration(retry) * time.Second)
+		return nil, fmt.Errorf("%s is rate limiting requests; try again in %d seconds", req.URL.Host, retry)
+	}
+	return resp, nil
+}
+
+// limiter spaces requests to a service at least interval apart
+type limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time // Earliest time of the next request
+}
+
+// wait blocks until a request may be made. If that is later than the
+// deadline of ctx, it returns an error at once instead.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	at := l.next
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	delay := time.Until(at)
+	if deadline, ok := ctx.Deadline(); ok && at.After(deadline) {
+		l.mu.Unlock()
+		return fmt.Errorf("rate limited; try again in %d seconds", int(delay.Seconds())+1)
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pause holds off requests for d, after the service asked to slow down
+func (l *limiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if next := time.Now().Add(d); next.After(l.next) {
+		l.next = next
+	}
+}
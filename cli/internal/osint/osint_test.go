@@ -0,0 +1,182 @@
+package osint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClient returns a client of a server that answers like the three
+// services, and records the requests made to it
+func testClient(t *testing.T, requests *[]*http.Request) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		switch {
+		case r.URL.Path == "/hibp/breachedaccount/alice@example.com":
+			if r.Header.Get("Hibp-Api-Key") != "key" || r.Header.Get("User-Agent") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`[{"Name": "Adobe", "Title": "Adobe", "Domain": "adobe.com", "BreachDate": "2013-10-04",
+				"PwnCount": 152445165, "DataClasses": ["Email addresses", "Password hints"], "IsVerified": true}]`))
+		case strings.HasPrefix(r.URL.Path, "/hibp/breachedaccount/"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/pwned/range/5BAA6": // SHA-1 of "password"
+			w.Write([]byte("003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9659365\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD9:0\r\n"))
+		case r.URL.Path == "/pwned/range/A94A8": // SHA-1 of "test", not listed
+			w.Write([]byte("00000000000000000000000000000000000:0\r\n"))
+		case r.URL.Path == "/crt/":
+			if r.URL.Query().Get("q") != "%.example.com" {
+				http.Error(w, "bad query", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`[
+				{"id": 1, "issuer_name": "C=US, O=Let's Encrypt, CN=R3", "common_name": "example.com", "name_value": "example.com\nwww.example.com", "not_before": "2024-01-01T00:00:00", "not_after": "2024-04-01T00:00:00"},
+				{"id": 1, "issuer_name": "C=US, O=Let's Encrypt, CN=R3", "common_name": "example.com", "name_value": "example.com\nwww.example.com", "not_before": "2024-01-01T00:00:00", "not_after": "2024-04-01T00:00:00"},
+				{"id": 2, "issuer_name": "C=US, O=DigiCert Inc, CN=DigiCert TLS RSA SHA256 2020 CA1", "common_name": "*.example.com", "name_value": "*.example.com\nMail.Example.com", "not_before": "2024-03-01T00:00:00", "not_after": "2025-03-01T00:00:00"}
+			]`))
+		default:
+			http.Error(w, "unexpected", http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c := NewClient("key", false)
+	c.HIBPURL = server.URL + "/hibp"
+	c.PwnedPasswordsURL = server.URL + "/pwned"
+	c.CrtShURL = server.URL + "/crt"
+	c.hibp.interval = 0
+	c.crtSh.interval = 0
+	return c
+}
+
+func TestBreachedAccount(t *testing.T) {
+	var requests []*http.Request
+	c := testClient(t, &requests)
+
+	breaches, err := c.BreachedAccount(context.Background(), "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(breaches) != 1 || breaches[0].Name != "Adobe" || breaches[0].PwnCount != 152445165 || len(breaches[0].DataClasses) != 2 {
+		t.Errorf("breaches = %+v", breaches)
+	}
+
+	breaches, err = c.BreachedAccount(context.Background(), "nobody@example.com")
+	if err != nil || breaches == nil || len(breaches) != 0 {
+		t.Errorf("unknown account = %v, %v; want an empty list", breaches, err)
+	}
+
+	c.HIBPAPIKey = ""
+	if _, err := c.BreachedAccount(context.Background(), "alice@example.com"); !errors.Is(err, ErrNoAPIKey) {
+		t.Errorf("without an API key: err = %v, want ErrNoAPIKey", err)
+	}
+}
+
+func TestPwnedPassword(t *testing.T) {
+	var requests []*http.Request
+	c := testClient(t, &requests)
+
+	count, err := c.PwnedPassword(context.Background(), "password")
+	if err != nil || count != 9659365 {
+		t.Errorf("PwnedPassword(password) = %d, %v", count, err)
+	}
+	count, err = c.PwnedPassword(context.Background(), "test")
+	if err != nil || count != 0 {
+		t.Errorf("PwnedPassword(test) = %d, %v", count, err)
+	}
+	for _, r := range requests {
+		if strings.Contains(r.URL.String(), "password") || r.Header.Get("Add-Padding") != "true" {
+			t.Errorf("request %s gives the password away", r.URL)
+		}
+	}
+}
+
+func TestCertificateTransparency(t *testing.T) {
+	var requests []*http.Request
+	c := testClient(t, &requests)
+
+	report, err := c.CertificateTransparency(context.Background(), "*.Example.com", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Certificates != 2 || strings.Join(report.Names, " ") != "*.example.com example.com mail.example.com www.example.com" {
+		t.Errorf("report = %+v", report)
+	}
+	if report.Issuers["Let's Encrypt R3"] != 1 || report.Latest[0].ID != 2 {
+		t.Errorf("issuers = %v, latest = %+v", report.Issuers, report.Latest)
+	}
+	if requests[0].URL.Query().Get("exclude") != "expired" {
+		t.Errorf("expired certificates weren't excluded: %s", requests[0].URL)
+	}
+
+	if _, err := c.CertificateTransparency(context.Background(), "example.com", true); err != nil {
+		t.Fatal(err)
+	}
+	if requests[1].URL.Query().Has("exclude") {
+		t.Errorf("expired certificates were excluded: %s", requests[1].URL)
+	}
+
+	for _, bad := range []string{"", "localhost", "exa mple.com", "example.com/%"} {
+		if _, err := c.CertificateTransparency(context.Background(), bad, false); err == nil {
+			t.Errorf("domain %q was accepted", bad)
+		}
+	}
+}
+
+func TestOffline(t *testing.T) {
+	var requests []*http.Request
+	c := testClient(t, &requests)
+	c.Offline = true
+
+	if _, err := c.BreachedAccount(context.Background(), "alice@example.com"); !errors.Is(err, ErrOffline) {
+		t.Errorf("BreachedAccount: err = %v, want ErrOffline", err)
+	}
+	if _, err := c.PwnedPassword(context.Background(), "password"); !errors.Is(err, ErrOffline) {
+		t.Errorf("PwnedPassword: err = %v, want ErrOffline", err)
+	}
+	if _, err := c.CertificateTransparency(context.Background(), "example.com", false); !errors.Is(err, ErrOffline) {
+		t.Errorf("CertificateTransparency: err = %v, want ErrOffline", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("%d requests made while offline", len(requests))
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	l := &limiter{interval: time.Hour}
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next request may only be made in an hour, past the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := l.wait(ctx); err == nil || !strings.Contains(err.Error(), "rate limited") {
+		t.Errorf("err = %v, want rate limited", err)
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Error("waited although the deadline was too close")
+	}
+
+	l = &limiter{interval: 50 * time.Millisecond}
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 requests took %v, want them 50ms apart", elapsed)
+	}
+
+	l.pause(time.Hour)
+	if err := l.wait(ctx); err == nil {
+		t.Error("a request was allowed during a pause")
+	}
+}
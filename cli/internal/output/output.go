@@ -0,0 +1,100 @@
+// Package output gives subcommands a consistent --json / --quiet mode.
+//
+// With --json every command writes exactly one JSON document to stdout:
+//
+//	{"kind": "models", "schemaVersion": 1, "data": ...}
+//
+// or, on failure,
+//
+//	{"kind": "error", "schemaVersion": 1, "error": {"message": ..., "exitCode": ...}}
+//
+// The data schemas are the types in schemas.go. Fields are only ever added
+// within a schema version; renames and removals bump SchemaVersion.
+package output
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hacka-re/cli/internal/failure"
+)
+
+// SchemaVersion is the version of the JSON schemas written with --json
+const SchemaVersion = 1
+
+// Options holds the output flags shared by all subcommands
+type Options struct {
+	JSON  bool
+	Quiet bool
+}
+
+// RegisterFlags adds --json, --quiet and -q to fs
+func RegisterFlags(fs *flag.FlagSet) *Options {
+	opts := &Options{}
+	fs.BoolVar(&opts.JSON, "json", false, "Write machine-readable JSON to stdout")
+	fs.BoolVar(&opts.Quiet, "quiet", false, "Suppress output; rely on the exit code")
+	fs.BoolVar(&opts.Quiet, "q", false, "Short for --quiet")
+	return opts
+}
+
+// envelope is the top-level JSON document
+type envelope struct {
+	Kind          string      `json:"kind"`
+	SchemaVersion int         `json:"schemaVersion"`
+	Data          interface{} `json:"data,omitempty"`
+	Error         *ErrorInfo  `json:"error,omitempty"`
+}
+
+// ErrorInfo is the JSON form of a failed command
+type ErrorInfo struct {
+	Message  string `json:"message"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// Write prints data as JSON, calls human for normal output, or prints nothing when quiet.
+// --json wins over --quiet, so scripts asking for JSON always get it.
+func (o *Options) Write(w io.Writer, kind string, data interface{}, human func(w io.Writer)) error {
+	switch {
+	case o.JSON:
+		return writeJSON(w, envelope{Kind: kind, SchemaVersion: SchemaVersion, Data: data})
+	case o.Quiet:
+		return nil
+	default:
+		human(w)
+		return nil
+	}
+}
+
+// Infof prints a progress or status line to stderr unless --quiet or --json is set
+func (o *Options) Infof(format string, args ...interface{}) {
+	if o.JSON || o.Quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// Fail reports err (as JSON on stdout with --json, otherwise on stderr) and returns its exit code
+func (o *Options) Fail(err error) int {
+	code := failure.ExitCode(err)
+	switch {
+	case o.JSON:
+		writeJSON(os.Stdout, envelope{
+			Kind:          "error",
+			SchemaVersion: SchemaVersion,
+			Error:         &ErrorInfo{Message: err.Error(), ExitCode: code},
+		})
+	case !o.Quiet:
+		fmt.Fprintf(os.Stderr, "Error: %s\n", failure.Message(err))
+	}
+	return code
+}
+
+// writeJSON writes v as indented JSON followed by a newline
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,132 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hacka-re/cli/internal/jsruntime"
+)
+
+func TestWriteModes(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts := RegisterFlags(fs)
+	if err := fs.Parse([]string{"--json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []Model{{ID: "gpt-4o", Provider: "openai", Capabilities: []string{"chat"}}}
+	human := func(w io.Writer) { io.WriteString(w, "gpt-4o\n") }
+
+	var buf bytes.Buffer
+	if err := opts.Write(&buf, "models", data, human); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Kind          string  `json:"kind"`
+		SchemaVersion int     `json:"schemaVersion"`
+		Data          []Model `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if doc.Kind != "models" || doc.SchemaVersion != SchemaVersion || len(doc.Data) != 1 || doc.Data[0].ID != "gpt-4o" {
+		t.Fatalf("unexpected document %+v", doc)
+	}
+
+	buf.Reset()
+	quiet := &Options{Quiet: true}
+	quiet.Write(&buf, "models", data, human)
+	if buf.Len() != 0 {
+		t.Fatalf("quiet mode wrote %q", buf.String())
+	}
+
+	buf.Reset()
+	plain := &Options{}
+	plain.Write(&buf, "models", data, human)
+	if buf.String() != "gpt-4o\n" {
+		t.Fatalf("human output = %q", buf.String())
+	}
+}
+
+func TestSyncFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answer.md")
+	f, err := CreateSyncFile(path, false, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	f.now = func() time.Time { return now }
+	f.lastSync = now
+
+	f.Write([]byte("Hello"))
+	if !f.dirty {
+		t.Error("a write within the interval should wait for the next flush")
+	}
+	now = now.Add(time.Second)
+	f.Write([]byte(", world"))
+	if f.dirty {
+		t.Error("a write after the interval should flush")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "Hello, world" {
+		t.Errorf("mid-stream content = %q", data)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err = CreateSyncFile(path, true, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("!"))
+	f.Close()
+	if data, _ := os.ReadFile(path); string(data) != "Hello, world!" {
+		t.Errorf("appended content = %q", data)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	registry := jsruntime.NewRegistry()
+	for _, code := range []string{
+		"/**\n * Look up a host\n * @tags recon, web\n * @param {string} host - Host name\n */\nfunction lookup(host) { return host; }",
+		"/**\n * Scan a host\n * @tags recon\n */\nfunction scan() { return 1; }",
+		"/** Add two numbers */\nfunction add(a, b) { return a + b; }",
+	} {
+		fn, err := jsruntime.ParseFunction(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.Add(fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all := Functions(registry, nil, map[string]bool{"scan": true})
+	if len(all) != 3 || all[0].Name != "add" || all[2].Name != "scan" {
+		t.Fatalf("unfiltered list = %+v", all)
+	}
+	if all[2].Enabled || !all[1].Enabled {
+		t.Errorf("enabled flags = %v, %v", all[1].Enabled, all[2].Enabled)
+	}
+	if all[1].Parameters["properties"] == nil {
+		t.Errorf("missing parameter schema: %+v", all[1])
+	}
+
+	recon := Functions(registry, []string{"recon"}, nil)
+	if len(recon) != 2 || recon[0].Name != "lookup" || recon[1].Name != "scan" {
+		t.Errorf("--tag recon = %+v", recon)
+	}
+	both := Functions(registry, []string{"recon", "web"}, nil)
+	if len(both) != 1 || both[0].Name != "lookup" {
+		t.Errorf("--tag recon,web = %+v", both)
+	}
+	if none := Functions(registry, []string{"missing"}, nil); len(none) != 0 {
+		t.Errorf("--tag missing = %+v", none)
+	}
+}
@@ -0,0 +1,140 @@
+package output
+
+import (
+	"time"
+
+	"github.com/hacka-re/cli/internal/jsruntime"
+	"github.com/hacka-re/cli/internal/models"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// Model is one entry of the "models" output
+type Model struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Provider      string   `json:"provider"`
+	Category      string   `json:"category"`
+	ContextWindow int      `json:"contextWindow"`
+	MaxTokens     int      `json:"maxTokens"`
+	Capabilities  []string `json:"capabilities"`
+	PricingInput  float64  `json:"pricingInput"`  // USD per 1M tokens
+	PricingOutput float64  `json:"pricingOutput"` // USD per 1M tokens
+}
+
+// Models converts registry metadata to the "models" schema
+func Models(metadata []*models.ModelMetadata) []Model {
+	result := make([]Model, 0, len(metadata))
+	for _, m := range metadata {
+		capabilities := m.Capabilities
+		if capabilities == nil {
+			capabilities = []string{}
+		}
+		result = append(result, Model{
+			ID:            m.ID,
+			Name:          m.Name,
+			Provider:      string(m.Provider),
+			Category:      m.Category,
+			ContextWindow: m.ContextWindow,
+			MaxTokens:     m.MaxTokens,
+			Capabilities:  capabilities,
+			PricingInput:  m.PricingInput,
+			PricingOutput: m.PricingOutput,
+		})
+	}
+	return result
+}
+
+// TokenUsage is a token and cost total
+type TokenUsage struct {
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	Cost             float64 `json:"cost"`         // USD
+	CachedTokens     int     `json:"cachedTokens"` // Prompt tokens served from the provider's cache
+	CacheSavings     float64 `json:"cacheSavings"` // USD saved by cache hits
+}
+
+// Usage is the "usage" output
+type Usage struct {
+	Date    string     `json:"date"` // YYYY-MM-DD, local time
+	Today   TokenUsage `json:"today"`
+	Session TokenUsage `json:"session"`
+}
+
+// UsageFrom reads the totals from a usage tracker
+func UsageFrom(tracker *usage.Tracker) Usage {
+	report := Usage{Date: time.Now().Format("2006-01-02")}
+	report.Today.PromptTokens, report.Today.CompletionTokens = tracker.DailyTokens()
+	report.Today.Cost = tracker.DailyCost()
+	report.Today.CachedTokens, report.Today.CacheSavings = tracker.DailyCache()
+	report.Session.PromptTokens, report.Session.CompletionTokens = tracker.SessionTokens()
+	report.Session.Cost = tracker.SessionCost()
+	report.Session.CachedTokens, report.Session.CacheSavings = tracker.SessionCache()
+	return report
+}
+
+// Answer is the "answer" output of ask
+type Answer struct {
+	Model            string `json:"model"`
+	Content          string `json:"content"`
+	FinishReason     string `json:"finishReason,omitempty"`
+	PromptTokens     int    `json:"promptTokens"`     // Estimated if the provider doesn't report it
+	CompletionTokens int    `json:"completionTokens"` // Estimated if the provider doesn't report it
+	File             string `json:"file,omitempty"`   // Where --out wrote the reply
+
+	// Seed sent with the request, and the backend fingerprint the provider
+	// reported; a rerun with the same seed matches while the fingerprint does
+	Seed              int    `json:"seed,omitempty"`
+	SystemFingerprint string `json:"systemFingerprint,omitempty"`
+}
+
+// Function is one entry of the "functions" output
+type Function struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Enabled     bool                   `json:"enabled"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"` // JSON schema
+}
+
+// Functions converts the registry's functions carrying every tag in want to
+// the "functions" schema, sorted by name. Functions named in disabled are
+// listed as not enabled.
+func Functions(registry *jsruntime.Registry, want []string, disabled map[string]bool) []Function {
+	names := registry.ListTagged(want)
+	result := make([]Function, 0, len(names))
+	for _, name := range names {
+		fn, err := registry.Get(name)
+		if err != nil {
+			continue
+		}
+		entry := Function{Name: fn.Name, Description: fn.Description, Enabled: !disabled[name], Tags: fn.Tags}
+		if definition, ok := fn.ToToolDefinition()["function"].(map[string]interface{}); ok {
+			entry.Parameters, _ = definition["parameters"].(map[string]interface{})
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// MCPServer is one entry of the "mcp-status" output
+type MCPServer struct {
+	Name      string   `json:"name"`
+	Transport string   `json:"transport"` // stdio, sse, http
+	Connected bool     `json:"connected"`
+	Tools     []string `json:"tools"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Check statuses for the "doctor" output
+const (
+	CheckOK   = "ok"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+// Check is one entry of the "doctor" output
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // CheckOK, CheckWarn or CheckFail
+	Detail string `json:"detail,omitempty"`
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultSyncInterval is how often a SyncFile flushes to disk while text streams in
+const DefaultSyncInterval = 500 * time.Millisecond
+
+// SyncFile is a file for a streamed reply. Writes are flushed to disk
+// (fsync) at most every interval, so if the terminal or the machine dies
+// mid-reply, the file holds everything received until shortly before.
+type SyncFile struct {
+	file     *os.File
+	interval time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	lastSync time.Time
+	dirty    bool
+}
+
+// CreateSyncFile creates or truncates path, or with appendTo adds to its end
+func CreateSyncFile(path string, appendTo bool, interval time.Duration) (*SyncFile, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendTo {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &SyncFile{file: file, interval: interval, now: time.Now, lastSync: time.Now()}, nil
+}
+
+// Name returns the path of the file
+func (f *SyncFile) Name() string {
+	return f.file.Name()
+}
+
+// Write appends p and flushes the file if the last flush is older than the interval
+func (f *SyncFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err := f.file.Write(p)
+	if n > 0 {
+		f.dirty = true
+	}
+	if err != nil {
+		return n, err
+	}
+	if f.now().Sub(f.lastSync) >= f.interval {
+		return n, f.sync()
+	}
+	return n, nil
+}
+
+// sync flushes pending writes
+func (f *SyncFile) sync() error {
+	f.lastSync = f.now()
+	if !f.dirty {
+		return nil
+	}
+	f.dirty = false
+	return f.file.Sync()
+}
+
+// Close flushes what is left and closes the file
+func (f *SyncFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	syncErr := f.sync()
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	return syncErr
+}
@@ -0,0 +1,325 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Link types of the frames decoded
+const (
+	linkNull     = 0   // BSD loopback
+	linkEthernet = 1   // Ethernet
+	linkRaw      = 101 // Raw IP
+	linkRawAlt   = 12  // Raw IP, as some systems number it
+	linkLoop     = 108 // OpenBSD loopback
+	linkSLL      = 113 // Linux cooked capture (tcpdump -i any)
+	linkIPv4     = 228
+	linkIPv6     = 229
+	linkSLL2     = 276 // Linux cooked capture v2
+)
+
+// IP protocol numbers
+const (
+	protoICMP   = 1
+	protoTCP    = 6
+	protoUDP    = 17
+	protoICMPv6 = 58
+)
+
+// TCP flags
+const (
+	tcpSYN = 0x02
+	tcpACK = 0x10
+)
+
+// frame is what is decoded from a packet: its addresses, transport protocol
+// and payload
+type frame struct {
+	protocol         string // "tcp", "udp", "icmp", "arp", or "" for anything else
+	src, dst         net.IP
+	srcPort, dstPort uint16
+	tcpFlags         byte
+	payload          []byte
+}
+
+// decode reads the headers of a packet down to its transport payload
+func decode(p packet) (frame, bool) {
+	data := p.data
+	var etherType uint16
+	switch p.linkType {
+	case linkEthernet:
+		if len(data) < 14 {
+			return frame{}, false
+		}
+		etherType, data = binary.BigEndian.Uint16(data[12:]), data[14:]
+		// Step over 802.1Q and 802.1ad VLAN tags
+		for (etherType == 0x8100 || etherType == 0x88A8) && len(data) >= 4 {
+			etherType, data = binary.BigEndian.Uint16(data[2:]), data[4:]
+		}
+	case linkSLL:
+		if len(data) < 16 {
+			return frame{}, false
+		}
+		etherType, data = binary.BigEndian.Uint16(data[14:]), data[16:]
+	case linkSLL2:
+		if len(data) < 20 {
+			return frame{}, false
+		}
+		etherType, data = binary.BigEndian.Uint16(data), data[20:]
+	case linkNull, linkLoop:
+		if len(data) < 4 {
+			return frame{}, false
+		}
+		data = data[4:] // The address family, in the capturing host's byte order
+	case linkRaw, linkRawAlt, linkIPv4, linkIPv6:
+	default:
+		return frame{}, false
+	}
+
+	if etherType == 0 && len(data) > 0 {
+		// No link header says what follows; the IP version does
+		switch data[0] >> 4 {
+		case 4:
+			etherType = 0x0800
+		case 6:
+			etherType = 0x86DD
+		}
+	}
+	switch etherType {
+	case 0x0800:
+		return decodeIPv4(data)
+	case 0x86DD:
+		return decodeIPv6(data)
+	case 0x0806:
+		return frame{protocol: "arp"}, true
+	}
+	return frame{}, false
+}
+
+func decodeIPv4(data []byte) (frame, bool) {
+	if len(data) < 20 || data[0]>>4 != 4 {
+		return frame{}, false
+	}
+	headerLength := int(data[0]&0x0F) * 4
+	total := int(binary.BigEndian.Uint16(data[2:]))
+	if headerLength < 20 || len(data) < headerLength {
+		return frame{}, false
+	}
+	if total >= headerLength && total < len(data) {
+		data = data[:total] // Drop Ethernet padding
+	}
+	f := frame{src: net.IP(data[12:16]), dst: net.IP(data[16:20])}
+	if binary.BigEndian.Uint16(data[6:])&0x1FFF != 0 {
+		return f, true // A later fragment has no transport header
+	}
+	return decodeTransport(f, data[9], data[headerLength:])
+}
+
+func decodeIPv6(data []byte) (frame, bool) {
+	if len(data) < 40 || data[0]>>4 != 6 {
+		return frame{}, false
+	}
+	if length := int(binary.BigEndian.Uint16(data[4:])); 40+length < len(data) {
+		data = data[:40+length]
+	}
+	f := frame{src: net.IP(data[8:24]), dst: net.IP(data[24:40])}
+	next, rest := data[6], data[40:]
+	for {
+		switch next {
+		case 0, 43, 60: // Hop-by-hop, routing and destination options
+			if len(rest) < 8 {
+				return f, true
+			}
+			next, rest = rest[0], rest[min(len(rest), (int(rest[1])+1)*8):]
+		case 44: // Fragment
+			if len(rest) < 8 || binary.BigEndian.Uint16(rest[2:])&0xFFF8 != 0 {
+				return f, true
+			}
+			next, rest = rest[0], rest[8:]
+		default:
+			return decodeTransport(f, next, rest)
+		}
+	}
+}
+
+func decodeTransport(f frame, protocol byte, data []byte) (frame, bool) {
+	switch protocol {
+	case protoTCP:
+		f.protocol = "tcp"
+		if len(data) < 20 {
+			return f, true
+		}
+		f.srcPort, f.dstPort = binary.BigEndian.Uint16(data), binary.BigEndian.Uint16(data[2:])
+		f.tcpFlags = data[13]
+		if offset := int(data[12]>>4) * 4; offset >= 20 && offset <= len(data) {
+			f.payload = data[offset:]
+		}
+	case protoUDP:
+		f.protocol = "udp"
+		if len(data) < 8 {
+			return f, true
+		}
+		f.srcPort, f.dstPort = binary.BigEndian.Uint16(data), binary.BigEndian.Uint16(data[2:])
+		f.payload = data[8:]
+	case protoICMP, protoICMPv6:
+		f.protocol = "icmp"
+	}
+	return f, true
+}
+
+// dnsMessage is the part of a DNS message that is kept
+type dnsMessage struct {
+	response bool
+	rcode    int
+	name     string
+	qtype    string
+	answers  []string
+}
+
+// DNS record types named in summaries
+var dnsTypes = map[uint16]string{
+	1: "A", 2: "NS", 5: "CNAME", 6: "SOA", 12: "PTR", 15: "MX", 16: "TXT",
+	28: "AAAA", 33: "SRV", 64: "SVCB", 65: "HTTPS", 255: "ANY",
+}
+
+// DNS response codes named in summaries
+var dnsRcodes = map[int]string{1: "FORMERR", 2: "SERVFAIL", 3: "NXDOMAIN", 4: "NOTIMP", 5: "REFUSED"}
+
+func dnsType(t uint16) string {
+	if name, ok := dnsTypes[t]; ok {
+		return name
+	}
+	return "TYPE" + strconv.Itoa(int(t))
+}
+
+// parseDNS reads the first question of a DNS message and, of a response, the
+// addresses and names it answers with
+func parseDNS(msg []byte) (dnsMessage, bool) {
+	if len(msg) < 12 {
+		return dnsMessage{}, false
+	}
+	flags := binary.BigEndian.Uint16(msg[2:])
+	questions, answers := binary.BigEndian.Uint16(msg[4:]), int(binary.BigEndian.Uint16(msg[6:]))
+	if questions == 0 {
+		return dnsMessage{}, false
+	}
+	m := dnsMessage{response: flags&0x8000 != 0, rcode: int(flags & 0x0F)}
+
+	name, offset, ok := readDNSName(msg, 12)
+	if !ok || offset+4 > len(msg) {
+		return dnsMessage{}, false
+	}
+	m.name, m.qtype = name, dnsType(binary.BigEndian.Uint16(msg[offset:]))
+	offset += 4
+	for i := 1; i < int(questions); i++ {
+		if _, offset, ok = readDNSName(msg, offset); !ok || offset+4 > len(msg) {
+			return m, true
+		}
+		offset += 4
+	}
+
+	for i := 0; i < answers && m.response; i++ {
+		if _, offset, ok = readDNSName(msg, offset); !ok || offset+10 > len(msg) {
+			break
+		}
+		rtype := binary.BigEndian.Uint16(msg[offset:])
+		length := int(binary.BigEndian.Uint16(msg[offset+8:]))
+		offset += 10
+		if offset+length > len(msg) {
+			break
+		}
+		rdata := msg[offset : offset+length]
+		switch {
+		case rtype == 1 && length == 4, rtype == 28 && length == 16:
+			m.answers = append(m.answers, net.IP(rdata).String())
+		case rtype == 5 || rtype == 12:
+			if target, _, ok := readDNSName(msg, offset); ok {
+				m.answers = append(m.answers, target)
+			}
+		}
+		offset += length
+	}
+	return m, true
+}
+
+// readDNSName reads the possibly compressed name at offset and returns it with
+// the offset just past it
+func readDNSName(msg []byte, offset int) (string, int, bool) {
+	var labels []string
+	end := -1
+	for jumps := 0; ; {
+		if offset >= len(msg) {
+			return "", 0, false
+		}
+		length := int(msg[offset])
+		switch {
+		case length == 0:
+			if end < 0 {
+				end = offset + 1
+			}
+			return strings.ToLower(strings.Join(labels, ".")), end, true
+		case length&0xC0 == 0xC0:
+			if offset+1 >= len(msg) || jumps > 10 {
+				return "", 0, false
+			}
+			if end < 0 {
+				end = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(msg[offset:]) & 0x3FFF)
+			jumps++
+		case length&0xC0 != 0 || offset+1+length > len(msg):
+			return "", 0, false
+		default:
+			labels = append(labels, string(msg[offset+1:offset+1+length]))
+			offset += 1 + length
+		}
+	}
+}
+
+// httpMethods are the request methods recognized at the start of a TCP payload
+var httpMethods = []string{"GET ", "POST ", "PUT ", "DELETE ", "HEAD ", "OPTIONS ", "PATCH ", "CONNECT "}
+
+// parseHTTPRequest reads the request line and the Host and User-Agent headers
+// of a plain HTTP request
+func parseHTTPRequest(payload []byte) (method, target, host, userAgent string, ok bool) {
+	head := string(payload[:min(len(payload), 4096)])
+	found := false
+	for _, m := range httpMethods {
+		if strings.HasPrefix(head, m) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", "", "", "", false
+	}
+	lines := strings.Split(head, "\r\n")
+	parts := strings.Fields(lines[0])
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "HTTP/1.") {
+		return "", "", "", "", false
+	}
+	for _, line := range lines[1:] {
+		if line == "" {
+			break
+		}
+		key, value, _ := strings.Cut(line, ":")
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "host":
+			host = strings.TrimSpace(value)
+		case "user-agent":
+			userAgent = strings.TrimSpace(value)
+		}
+	}
+	return parts[0], parts[1], host, userAgent, true
+}
+
+// parseHTTPStatus reads the status code of a plain HTTP response
+func parseHTTPStatus(payload []byte) (int, bool) {
+	if len(payload) < 12 || !strings.HasPrefix(string(payload[:8]), "HTTP/1.") {
+		return 0, false
+	}
+	code, err := strconv.Atoi(string(payload[9:12]))
+	return code, err == nil
+}
@@ -0,0 +1,476 @@
+// Package pcap summarizes packet captures, pcap or pcapng, into the flows,
+// DNS lookups and HTTP requests they contain. The summary is small enough for
+// a model to reason over even when the capture isn't, and is kept under Dir()
+// for the capture functions to read in a chat.
+package pcap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits on what a summary lists, so it stays small for any capture size.
+// The counts next to each list are always complete.
+const (
+	topHosts     = 20
+	topFlows     = 50
+	maxDNSNames  = 100
+	maxDNSAnswer = 10
+	maxHTTP      = 100
+)
+
+// Capture is the summary of a packet capture
+type Capture struct {
+	Name      string         `json:"name"`
+	File      string         `json:"file,omitempty"`
+	Ingested  time.Time      `json:"ingested"`
+	Format    string         `json:"format"` // "pcap" or "pcapng"
+	Truncated bool           `json:"truncated,omitempty"`
+	Packets   int            `json:"packets"`
+	Bytes     int64          `json:"bytes"`
+	Start     time.Time      `json:"start,omitempty"`
+	End       time.Time      `json:"end,omitempty"`
+	Protocols map[string]int `json:"protocols"` // Packets per protocol: tcp, udp, icmp, arp, other
+
+	Hosts     []HostStat `json:"hosts"` // The busiest addresses first
+	FlowCount int        `json:"flowCount"`
+	Flows     []Flow     `json:"flows"` // The largest flows first
+
+	DNSQueries int       `json:"dnsQueries"`
+	DNS        []DNSName `json:"dns"` // The most queried names first
+
+	HTTPCount  int            `json:"httpCount"`
+	HTTP       []HTTPRequest  `json:"http"` // In capture order
+	HTTPStatus map[string]int `json:"httpStatus,omitempty"`
+}
+
+// HostStat is the traffic of an address, sent and received
+type HostStat struct {
+	Address string `json:"address"`
+	Packets int    `json:"packets"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// Flow is the traffic between two endpoints over one transport protocol
+type Flow struct {
+	Protocol string    `json:"protocol"`
+	Client   string    `json:"client"` // The endpoint that started it, address:port
+	Server   string    `json:"server"`
+	Packets  int       `json:"packets"`
+	Bytes    int64     `json:"bytes"`
+	Start    time.Time `json:"start,omitempty"`
+	End      time.Time `json:"end,omitempty"`
+}
+
+// DNSName is a name that was looked up and what the answers were
+type DNSName struct {
+	Name    string   `json:"name"`
+	Types   []string `json:"types"`
+	Queries int      `json:"queries"`
+	Answers []string `json:"answers,omitempty"`
+	Error   string   `json:"error,omitempty"` // A failed lookup, e.g. NXDOMAIN
+}
+
+// HTTPRequest is a plain HTTP request
+type HTTPRequest struct {
+	Time      time.Time `json:"time,omitempty"`
+	Client    string    `json:"client"`
+	Server    string    `json:"server"`
+	Method    string    `json:"method"`
+	Host      string    `json:"host,omitempty"`
+	Path      string    `json:"path"`
+	UserAgent string    `json:"userAgent,omitempty"`
+}
+
+// flowKey identifies a flow in both directions
+type flowKey struct {
+	protocol string
+	a, b     string // The endpoints, in sorted order
+}
+
+// Summarize reads a pcap or pcapng capture. A capture cut off in the middle
+// of a packet is summarized up to there and marked truncated.
+func Summarize(r io.Reader) (*Capture, error) {
+	c := &Capture{Protocols: map[string]int{}, HTTPStatus: map[string]int{}, Hosts: []HostStat{}, Flows: []Flow{}, DNS: []DNSName{}, HTTP: []HTTPRequest{}}
+	hosts := map[string]*HostStat{}
+	flows := map[flowKey]*Flow{}
+	names := map[string]*DNSName{}
+
+	format, err := readPackets(r, func(p packet) {
+		c.Packets++
+		c.Bytes += int64(p.length)
+		if !p.time.IsZero() {
+			if c.Start.IsZero() || p.time.Before(c.Start) {
+				c.Start = p.time
+			}
+			if p.time.After(c.End) {
+				c.End = p.time
+			}
+		}
+
+		f, ok := decode(p)
+		if !ok || f.protocol == "" {
+			c.Protocols["other"]++
+		} else {
+			c.Protocols[f.protocol]++
+		}
+		if f.src == nil {
+			return
+		}
+		for _, ip := range []net.IP{f.src, f.dst} {
+			address := ip.String()
+			h := hosts[address]
+			if h == nil {
+				h = &HostStat{Address: address}
+				hosts[address] = h
+			}
+			h.Packets++
+			h.Bytes += int64(p.length)
+		}
+		if f.protocol != "tcp" && f.protocol != "udp" {
+			return
+		}
+
+		src := endpoint(f.src, f.srcPort)
+		dst := endpoint(f.dst, f.dstPort)
+		key := flowKey{f.protocol, src, dst}
+		if key.b < key.a {
+			key.a, key.b = key.b, key.a
+		}
+		flow := flows[key]
+		if flow == nil {
+			flow = &Flow{Protocol: f.protocol, Client: src, Server: dst, Start: p.time}
+			// Without its SYN, guess the side on a well-known port is the server
+			if f.tcpFlags&(tcpSYN|tcpACK) != tcpSYN && f.srcPort < f.dstPort && f.srcPort < 1024 {
+				flow.Client, flow.Server = dst, src
+			}
+			flows[key] = flow
+		}
+		flow.Packets++
+		flow.Bytes += int64(p.length)
+		flow.End = p.time
+
+		if f.protocol == "udp" && (f.srcPort == 53 || f.dstPort == 53) {
+			if m, ok := parseDNS(f.payload); ok {
+				c.addDNS(names, m)
+			}
+		}
+		if f.protocol == "tcp" && len(f.payload) > 0 {
+			if method, target, host, userAgent, ok := parseHTTPRequest(f.payload); ok {
+				c.HTTPCount++
+				if len(c.HTTP) < maxHTTP {
+					c.HTTP = append(c.HTTP, HTTPRequest{p.time, src, dst, method, host, target, userAgent})
+				}
+			} else if status, ok := parseHTTPStatus(f.payload); ok {
+				c.HTTPStatus[strconv.Itoa(status)]++
+			}
+		}
+	})
+	if errors.Is(err, errTruncated) && c.Packets > 0 {
+		c.Truncated, err = true, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Format = format
+
+	for _, h := range hosts {
+		c.Hosts = append(c.Hosts, *h)
+	}
+	sort.Slice(c.Hosts, func(i, j int) bool {
+		if c.Hosts[i].Bytes != c.Hosts[j].Bytes {
+			return c.Hosts[i].Bytes > c.Hosts[j].Bytes
+		}
+		return c.Hosts[i].Address < c.Hosts[j].Address
+	})
+	if len(c.Hosts) > topHosts {
+		c.Hosts = c.Hosts[:topHosts]
+	}
+
+	c.FlowCount = len(flows)
+	for _, f := range flows {
+		c.Flows = append(c.Flows, *f)
+	}
+	sort.Slice(c.Flows, func(i, j int) bool {
+		if c.Flows[i].Bytes != c.Flows[j].Bytes {
+			return c.Flows[i].Bytes > c.Flows[j].Bytes
+		}
+		return c.Flows[i].Client+c.Flows[i].Server < c.Flows[j].Client+c.Flows[j].Server
+	})
+	if len(c.Flows) > topFlows {
+		c.Flows = c.Flows[:topFlows]
+	}
+
+	for _, n := range names {
+		sort.Strings(n.Types)
+		c.DNS = append(c.DNS, *n)
+	}
+	sort.Slice(c.DNS, func(i, j int) bool {
+		if c.DNS[i].Queries != c.DNS[j].Queries {
+			return c.DNS[i].Queries > c.DNS[j].Queries
+		}
+		return c.DNS[i].Name < c.DNS[j].Name
+	})
+	if len(c.DNS) > maxDNSNames {
+		c.DNS = c.DNS[:maxDNSNames]
+	}
+	return c, nil
+}
+
+// addDNS counts a query, or notes the answers of a response
+func (c *Capture) addDNS(names map[string]*DNSName, m dnsMessage) {
+	n := names[m.name]
+	if n == nil {
+		n = &DNSName{Name: m.name, Types: []string{}}
+		names[m.name] = n
+	}
+	if !contains(n.Types, m.qtype) {
+		n.Types = append(n.Types, m.qtype)
+	}
+	if !m.response {
+		c.DNSQueries++
+		n.Queries++
+		return
+	}
+	if m.rcode != 0 {
+		n.Error = dnsRcodes[m.rcode]
+		if n.Error == "" {
+			n.Error = "RCODE" + strconv.Itoa(m.rcode)
+		}
+	}
+	for _, answer := range m.answers {
+		if len(n.Answers) < maxDNSAnswer && !contains(n.Answers, answer) {
+			n.Answers = append(n.Answers, answer)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func endpoint(ip net.IP, port uint16) string {
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+}
+
+// Overview describes a capture in a few numbers
+type Overview struct {
+	Name      string    `json:"name"`
+	File      string    `json:"file,omitempty"`
+	Ingested  time.Time `json:"ingested"`
+	Packets   int       `json:"packets"`
+	Bytes     int64     `json:"bytes"`
+	Start     time.Time `json:"start,omitempty"`
+	End       time.Time `json:"end,omitempty"`
+	Flows     int       `json:"flows"`
+	DNSNames  int       `json:"dnsNames"`
+	HTTP      int       `json:"httpRequests"`
+	Truncated bool      `json:"truncated,omitempty"`
+}
+
+// Overview returns the counts of the capture
+func (c *Capture) Overview() Overview {
+	return Overview{c.Name, c.File, c.Ingested, c.Packets, c.Bytes, c.Start, c.End, c.FlowCount, len(c.DNS), c.HTTPCount, c.Truncated}
+}
+
+// Markdown renders the summary as a document, for the RAG index
+func (c *Capture) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Packet capture %s\n\n", c.Name)
+	if c.File != "" {
+		fmt.Fprintf(&b, "File: %s (%s)\n", c.File, c.Format)
+	}
+	fmt.Fprintf(&b, "Packets: %d, %d bytes", c.Packets, c.Bytes)
+	if !c.Start.IsZero() {
+		fmt.Fprintf(&b, ", from %s to %s", c.Start.Format(time.RFC3339), c.End.Format(time.RFC3339))
+	}
+	b.WriteString("\n")
+	if c.Truncated {
+		b.WriteString("The capture file is truncated.\n")
+	}
+
+	fmt.Fprintf(&b, "\n## Hosts\n\n")
+	for _, h := range c.Hosts {
+		fmt.Fprintf(&b, "- %s: %d packets, %d bytes\n", h.Address, h.Packets, h.Bytes)
+	}
+	fmt.Fprintf(&b, "\n## Flows (%d largest of %d)\n\n", len(c.Flows), c.FlowCount)
+	for _, f := range c.Flows {
+		fmt.Fprintf(&b, "- %s %s -> %s: %d packets, %d bytes\n", f.Protocol, f.Client, f.Server, f.Packets, f.Bytes)
+	}
+	if len(c.DNS) > 0 {
+		fmt.Fprintf(&b, "\n## DNS lookups (%d queries)\n\n", c.DNSQueries)
+		for _, n := range c.DNS {
+			fmt.Fprintf(&b, "- %s %s, %d queries", n.Name, strings.Join(n.Types, "/"), n.Queries)
+			if n.Error != "" {
+				fmt.Fprintf(&b, ", %s", n.Error)
+			}
+			if len(n.Answers) > 0 {
+				fmt.Fprintf(&b, ": %s", strings.Join(n.Answers, ", "))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(c.HTTP) > 0 {
+		fmt.Fprintf(&b, "\n## HTTP requests (%d)\n\n", c.HTTPCount)
+		for _, r := range c.HTTP {
+			fmt.Fprintf(&b, "- %s %s %s%s from %s", r.Server, r.Method, r.Host, r.Path, r.Client)
+			if r.UserAgent != "" {
+				fmt.Fprintf(&b, " (%s)", r.UserAgent)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Dir returns the directory ingested captures are kept in.
+// HACKARE_CAPTURES_DIR overrides it.
+func Dir() string {
+	if dir := os.Getenv("HACKARE_CAPTURES_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hacka.re-captures")
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "captures")
+}
+
+// validName is what a capture name may look like
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._\-]*$`)
+
+// invalidNameChars are replaced by NameFor
+var invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._\-]+`)
+
+// NameFor returns a capture name made from the file it was read from
+func NameFor(file string) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), ".-_")
+	if name == "" {
+		return "capture"
+	}
+	return name
+}
+
+// DocumentPath returns where the Markdown summary of a capture is written for
+// the RAG index
+func DocumentPath(dir, name string) string {
+	return filepath.Join(dir, name+".md")
+}
+
+// Save keeps the capture under its name in dir, replacing a capture of that name
+func Save(dir string, c *Capture) error {
+	if !validName.MatchString(c.Name) {
+		return fmt.Errorf("invalid capture name %q: use letters, digits, '.', '-' and '_'", c.Name)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode capture: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create captures directory: %w", err)
+	}
+	path := filepath.Join(dir, c.Name+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write capture: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// SaveDocument writes the Markdown summary of the capture next to it and
+// returns its path
+func SaveDocument(dir string, c *Capture) (string, error) {
+	if !validName.MatchString(c.Name) {
+		return "", fmt.Errorf("invalid capture name %q", c.Name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create captures directory: %w", err)
+	}
+	path := DocumentPath(dir, c.Name)
+	if err := os.WriteFile(path, []byte(c.Markdown()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write capture document: %w", err)
+	}
+	return path, nil
+}
+
+// Load returns the capture called name from dir, or the latest ingested one
+// if name is empty
+func Load(dir, name string) (*Capture, error) {
+	if name == "" {
+		all, err := List(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(all) == 0 {
+			return nil, errors.New("no captures have been ingested; use hacka.re ingest pcap")
+		}
+		name = all[0].Name
+	}
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid capture name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no capture called %q", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capture: %w", err)
+	}
+	var c Capture
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse capture %s: %w", name, err)
+	}
+	return &c, nil
+}
+
+// List returns the overviews of the captures in dir, latest ingested first
+func List(dir string) ([]Overview, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var overviews []Overview
+	for _, path := range paths {
+		c, err := Load(dir, strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		overviews = append(overviews, c.Overview())
+	}
+	sort.SliceStable(overviews, func(i, j int) bool { return overviews[i].Ingested.After(overviews[j].Ingested) })
+	return overviews, nil
+}
+
+// Remove deletes the capture called name from dir, with its document
+func Remove(dir, name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid capture name %q", name)
+	}
+	err := os.Remove(filepath.Join(dir, name+".json"))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no capture called %q", name)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(DocumentPath(dir, name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
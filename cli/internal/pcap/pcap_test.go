@@ -0,0 +1,260 @@
+package pcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	client = net.ParseIP("10.0.0.5").To4()
+	server = net.ParseIP("93.184.216.34").To4()
+	dns    = net.ParseIP("10.0.0.1").To4()
+)
+
+// ipv4Packet returns an Ethernet frame carrying an IPv4 packet
+func ipv4Packet(src, dst net.IP, protocol byte, transport []byte) []byte {
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:], uint16(20+len(transport)))
+	ip[8], ip[9] = 64, protocol
+	copy(ip[12:], src)
+	copy(ip[16:], dst)
+	frame := append(make([]byte, 12), 0x08, 0x00)
+	return append(append(frame, ip...), transport...)
+}
+
+func udpPacket(src, dst net.IP, srcPort, dstPort uint16, payload []byte) []byte {
+	udp := make([]byte, 8)
+	binary.BigEndian.PutUint16(udp, srcPort)
+	binary.BigEndian.PutUint16(udp[2:], dstPort)
+	binary.BigEndian.PutUint16(udp[4:], uint16(8+len(payload)))
+	return ipv4Packet(src, dst, protoUDP, append(udp, payload...))
+}
+
+func tcpPacket(src, dst net.IP, srcPort, dstPort uint16, flags byte, payload string) []byte {
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp, srcPort)
+	binary.BigEndian.PutUint16(tcp[2:], dstPort)
+	tcp[12], tcp[13] = 5<<4, flags
+	return ipv4Packet(src, dst, protoTCP, append(tcp, payload...))
+}
+
+// dnsMessageBytes returns a DNS message for name, answered with address if
+// response is set
+func dnsMessageBytes(name string, response bool, address net.IP) []byte {
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[4:], 1)
+	if response {
+		binary.BigEndian.PutUint16(msg[2:], 0x8180)
+		binary.BigEndian.PutUint16(msg[6:], 1)
+	}
+	for _, label := range strings.Split(name, ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0, 0, 1, 0, 1)
+	if response {
+		// A compressed pointer to the question's name
+		msg = append(msg, 0xC0, 12, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4)
+		msg = append(msg, address...)
+	}
+	return msg
+}
+
+// testFrames is a DNS lookup followed by an HTTP exchange
+func testFrames() [][]byte {
+	return [][]byte{
+		udpPacket(client, dns, 40000, 53, dnsMessageBytes("Example.com", false, nil)),
+		udpPacket(dns, client, 53, 40000, dnsMessageBytes("example.com", true, server)),
+		tcpPacket(client, server, 50000, 80, tcpSYN, ""),
+		tcpPacket(server, client, 80, 50000, tcpSYN|tcpACK, ""),
+		tcpPacket(client, server, 50000, 80, tcpACK, "GET /login?next=/ HTTP/1.1\r\nHost: example.com\r\nUser-Agent: curl/8.5.0\r\n\r\n"),
+		tcpPacket(server, client, 80, 50000, tcpACK, "HTTP/1.1 302 Found\r\nLocation: /\r\n\r\n"),
+	}
+}
+
+var start = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func pcapFile(frames [][]byte) []byte {
+	var b bytes.Buffer
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header, 0xa1b2c3d4)
+	binary.LittleEndian.PutUint16(header[4:], 2)
+	binary.LittleEndian.PutUint16(header[6:], 4)
+	binary.LittleEndian.PutUint32(header[16:], 65535)
+	binary.LittleEndian.PutUint32(header[20:], linkEthernet)
+	b.Write(header)
+	for i, frame := range frames {
+		record := make([]byte, 16)
+		t := start.Add(time.Duration(i) * 100 * time.Millisecond)
+		binary.LittleEndian.PutUint32(record, uint32(t.Unix()))
+		binary.LittleEndian.PutUint32(record[4:], uint32(t.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(record[8:], uint32(len(frame)))
+		binary.LittleEndian.PutUint32(record[12:], uint32(len(frame)))
+		b.Write(record)
+		b.Write(frame)
+	}
+	return b.Bytes()
+}
+
+// pcapngBlock returns a big-endian pcapng block
+func pcapngBlock(blockType uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	block := make([]byte, 8, 12+len(body))
+	binary.BigEndian.PutUint32(block, blockType)
+	binary.BigEndian.PutUint32(block[4:], uint32(12+len(body)))
+	block = append(block, body...)
+	return binary.BigEndian.AppendUint32(block, uint32(12+len(body)))
+}
+
+func pcapngFile(frames [][]byte) []byte {
+	var b bytes.Buffer
+	section := []byte{0x1A, 0x2B, 0x3C, 0x4D, 0, 1, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	b.Write(pcapngBlock(blockSectionHeader, section))
+	// An Ethernet interface with nanosecond timestamps (if_tsresol 9)
+	b.Write(pcapngBlock(blockInterface, []byte{0, 1, 0, 0, 0, 0, 0xFF, 0xFF, 0, 9, 0, 1, 9, 0, 0, 0, 0, 0, 0, 0}))
+	for i, frame := range frames {
+		t := uint64(start.Add(time.Duration(i) * 100 * time.Millisecond).UnixNano())
+		body := binary.BigEndian.AppendUint32(nil, 0)
+		body = binary.BigEndian.AppendUint32(body, uint32(t>>32))
+		body = binary.BigEndian.AppendUint32(body, uint32(t))
+		body = binary.BigEndian.AppendUint32(body, uint32(len(frame)))
+		body = binary.BigEndian.AppendUint32(body, uint32(len(frame)))
+		b.Write(pcapngBlock(blockEnhancedPacket, append(body, frame...)))
+	}
+	return b.Bytes()
+}
+
+func checkSummary(t *testing.T, c *Capture) {
+	t.Helper()
+	if c.Packets != 6 || c.Protocols["udp"] != 2 || c.Protocols["tcp"] != 4 {
+		t.Errorf("packets = %d, protocols = %v", c.Packets, c.Protocols)
+	}
+	if !c.Start.Equal(start) || !c.End.Equal(start.Add(500*time.Millisecond)) {
+		t.Errorf("start, end = %v, %v", c.Start, c.End)
+	}
+	if c.FlowCount != 2 || c.Flows[0].Client != "10.0.0.5:50000" || c.Flows[0].Server != "93.184.216.34:80" || c.Flows[0].Packets != 4 {
+		t.Errorf("flows = %+v", c.Flows)
+	}
+	if c.DNSQueries != 1 || len(c.DNS) != 1 || c.DNS[0].Name != "example.com" || strings.Join(c.DNS[0].Answers, ",") != "93.184.216.34" {
+		t.Errorf("dns = %+v", c.DNS)
+	}
+	if c.HTTPCount != 1 || c.HTTP[0].Method != "GET" || c.HTTP[0].Host != "example.com" || c.HTTP[0].Path != "/login?next=/" || c.HTTP[0].UserAgent != "curl/8.5.0" {
+		t.Errorf("http = %+v", c.HTTP)
+	}
+	if c.HTTPStatus["302"] != 1 {
+		t.Errorf("http status = %v", c.HTTPStatus)
+	}
+	if c.Hosts[0].Address != "10.0.0.5" {
+		t.Errorf("hosts = %+v", c.Hosts)
+	}
+}
+
+func TestSummarizePcap(t *testing.T) {
+	c, err := Summarize(bytes.NewReader(pcapFile(testFrames())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Format != "pcap" {
+		t.Errorf("format = %q", c.Format)
+	}
+	checkSummary(t, c)
+}
+
+func TestSummarizePcapNG(t *testing.T) {
+	c, err := Summarize(bytes.NewReader(pcapngFile(testFrames())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Format != "pcapng" {
+		t.Errorf("format = %q", c.Format)
+	}
+	checkSummary(t, c)
+}
+
+func TestSummarizeBadInput(t *testing.T) {
+	data := pcapFile(testFrames())
+	c, err := Summarize(bytes.NewReader(data[:len(data)-10]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Truncated || c.Packets != 5 {
+		t.Errorf("truncated capture: truncated = %v, packets = %d", c.Truncated, c.Packets)
+	}
+
+	for _, bad := range [][]byte{nil, []byte("not a capture at all"), data[:20]} {
+		if _, err := Summarize(bytes.NewReader(bad)); err == nil {
+			t.Errorf("%q was accepted", bad)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	c, err := Summarize(bytes.NewReader(pcapFile(testFrames())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Name = NameFor("/tmp/incident 42.pcapng")
+	c.Ingested = time.Now()
+	if c.Name != "incident-42" {
+		t.Errorf("NameFor = %q", c.Name)
+	}
+	if err := Save(dir, c); err != nil {
+		t.Fatal(err)
+	}
+	path, err := SaveDocument(dir, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSummary(t, loaded)
+	list, err := List(dir)
+	if err != nil || len(list) != 1 || list[0].Flows != 2 || list[0].HTTP != 1 {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+
+	if err := Remove(dir, c.Name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, c.Name); err == nil {
+		t.Error("a removed capture was loaded")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the document of a removed capture is left: %v", err)
+	}
+	if _, err := Load(dir, ""); err == nil {
+		t.Error("Load of an empty directory succeeded")
+	}
+	if _, err := Load(dir, "../etc/passwd"); err == nil {
+		t.Error("an invalid name was accepted")
+	}
+	if _, err := SaveDocument(dir, &Capture{Name: "../x"}); err == nil {
+		t.Error("an invalid document name was accepted")
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	c, err := Summarize(bytes.NewReader(pcapFile(testFrames())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Name = "web"
+	doc := c.Markdown()
+	for _, want := range []string{"# Packet capture web", "tcp 10.0.0.5:50000 -> 93.184.216.34:80", "example.com A, 1 queries: 93.184.216.34", "GET example.com/login?next=/"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document lacks %q:\n%s", want, doc)
+		}
+	}
+}
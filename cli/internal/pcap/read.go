@@ -0,0 +1,221 @@
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// maxRecord bounds the size of a packet record or pcapng block, so a corrupt
+// length can't make the reader allocate gigabytes
+const maxRecord = 16 << 20
+
+// packet is one captured frame
+type packet struct {
+	time     time.Time
+	linkType uint32
+	data     []byte // The captured bytes, possibly fewer than the frame had
+	length   int    // The length of the frame on the wire
+}
+
+// readPackets calls fn for every packet of a pcap or pcapng capture and
+// returns the format it found
+func readPackets(r io.Reader, fn func(packet)) (string, error) {
+	br := bufio.NewReaderSize(r, 1<<16)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return "", errors.New("not a capture file: too short")
+	}
+	switch {
+	case binary.LittleEndian.Uint32(magic) == 0x0A0D0D0A:
+		return "pcapng", readPcapNG(br, fn)
+	case isPcapMagic(binary.LittleEndian.Uint32(magic)) || isPcapMagic(binary.BigEndian.Uint32(magic)):
+		return "pcap", readPcap(br, fn)
+	}
+	return "", errors.New("not a pcap or pcapng file")
+}
+
+func isPcapMagic(m uint32) bool {
+	return m == 0xa1b2c3d4 || m == 0xa1b23c4d
+}
+
+// readPcap reads the classic libpcap format
+func readPcap(r io.Reader, fn func(packet)) error {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("failed to read pcap header: %w", err)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if !isPcapMagic(order.Uint32(header)) {
+		order = binary.BigEndian
+	}
+	nanoseconds := order.Uint32(header) == 0xa1b23c4d
+	linkType := order.Uint32(header[20:]) & 0x0FFFFFFF // The upper bits carry FCS flags
+
+	record := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, record); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return truncated(err)
+		}
+		seconds, fraction := int64(order.Uint32(record)), int64(order.Uint32(record[4:]))
+		captured, length := order.Uint32(record[8:]), order.Uint32(record[12:])
+		if captured > maxRecord {
+			return fmt.Errorf("corrupt pcap record of %d bytes", captured)
+		}
+		data := make([]byte, captured)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return truncated(err)
+		}
+		if !nanoseconds {
+			fraction *= 1000
+		}
+		fn(packet{time.Unix(seconds, fraction).UTC(), linkType, data, int(length)})
+	}
+}
+
+// pcapng block types
+const (
+	blockSectionHeader   = 0x0A0D0D0A
+	blockInterface       = 0x00000001
+	blockSimplePacket    = 0x00000003
+	blockEnhancedPacket  = 0x00000006
+	byteOrderMagic       = 0x1A2B3C4D
+	optionEnd            = 0
+	optionTimeResolution = 9 // if_tsresol
+)
+
+// pcapngInterface is what packets of an interface need from its description
+type pcapngInterface struct {
+	linkType uint32
+	units    uint64 // Timestamp units per second
+}
+
+// readPcapNG reads the pcapng format. Only packets are read; statistics,
+// name resolution and custom blocks are skipped.
+func readPcapNG(r io.Reader, fn func(packet)) error {
+	var order binary.ByteOrder = binary.LittleEndian
+	var interfaces []pcapngInterface
+	head := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, head); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return truncated(err)
+		}
+		blockType := order.Uint32(head)
+		if blockType == blockSectionHeader {
+			// Every section declares its byte order, just after the block length
+			magic := make([]byte, 4)
+			if _, err := io.ReadFull(r, magic); err != nil {
+				return truncated(err)
+			}
+			switch {
+			case binary.LittleEndian.Uint32(magic) == byteOrderMagic:
+				order = binary.LittleEndian
+			case binary.BigEndian.Uint32(magic) == byteOrderMagic:
+				order = binary.BigEndian
+			default:
+				return errors.New("corrupt pcapng section header")
+			}
+			interfaces = nil
+			length := order.Uint32(head[4:])
+			if length < 16 || length > maxRecord {
+				return fmt.Errorf("corrupt pcapng block of %d bytes", length)
+			}
+			if _, err := io.CopyN(io.Discard, r, int64(length)-12); err != nil {
+				return truncated(err)
+			}
+			continue
+		}
+
+		length := order.Uint32(head[4:])
+		if length < 12 || length > maxRecord || length%4 != 0 {
+			return fmt.Errorf("corrupt pcapng block of %d bytes", length)
+		}
+		body := make([]byte, length-8)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return truncated(err)
+		}
+		body = body[:len(body)-4] // The trailing copy of the length
+
+		switch blockType {
+		case blockInterface:
+			if len(body) < 8 {
+				return errors.New("corrupt pcapng interface block")
+			}
+			iface := pcapngInterface{linkType: uint32(order.Uint16(body)), units: 1000000}
+			for options := body[8:]; len(options) >= 4; {
+				code, size := order.Uint16(options), int(order.Uint16(options[2:]))
+				if code == optionEnd || 4+size > len(options) {
+					break
+				}
+				if code == optionTimeResolution && size >= 1 {
+					if v := options[4]; v&0x80 == 0 && v <= 18 {
+						iface.units = uint64(math.Pow10(int(v)))
+					} else if v&0x80 != 0 && v&0x7F < 64 {
+						iface.units = 1 << (v & 0x7F)
+					}
+				}
+				options = options[4+(size+3)&^3:]
+			}
+			interfaces = append(interfaces, iface)
+		case blockEnhancedPacket:
+			if len(body) < 20 {
+				return errors.New("corrupt pcapng packet block")
+			}
+			id := int(order.Uint32(body))
+			if id >= len(interfaces) {
+				return fmt.Errorf("pcapng packet of undeclared interface %d", id)
+			}
+			iface := interfaces[id]
+			units := uint64(order.Uint32(body[4:]))<<32 | uint64(order.Uint32(body[8:]))
+			captured, length := int(order.Uint32(body[12:])), int(order.Uint32(body[16:]))
+			if captured > len(body)-20 {
+				return errors.New("corrupt pcapng packet block")
+			}
+			fn(packet{timestamp(units, iface.units), iface.linkType, body[20 : 20+captured], length})
+		case blockSimplePacket:
+			if len(body) < 4 || len(interfaces) == 0 {
+				return errors.New("corrupt pcapng simple packet block")
+			}
+			length := int(order.Uint32(body))
+			data := body[4:]
+			if length < len(data) {
+				data = data[:length]
+			}
+			fn(packet{time.Time{}, interfaces[0].linkType, data, length})
+		}
+	}
+}
+
+// timestamp converts a count of units since the epoch, perSecond of them a
+// second, to a time
+func timestamp(units, perSecond uint64) time.Time {
+	fraction := units % perSecond
+	var nanoseconds uint64
+	if perSecond <= 1e9 {
+		nanoseconds = fraction * 1e9 / perSecond
+	} else {
+		nanoseconds = fraction / (perSecond / 1e9)
+	}
+	return time.Unix(int64(units/perSecond), int64(nanoseconds)).UTC()
+}
+
+// errTruncated is returned when a capture ends in the middle of a record, as
+// it does when the capturing process is killed
+var errTruncated = errors.New("capture file is truncated")
+
+func truncated(err error) error {
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		return errTruncated
+	}
+	return fmt.Errorf("failed to read capture: %w", err)
+}
@@ -0,0 +1,281 @@
+// Package playground is a scratchpad for JavaScript functions. Cells of code
+// run in the function sandbox with the configured functions loaded, and are
+// kept between sessions, so a function can be tried out before the model is
+// allowed to call it.
+package playground
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/jsruntime"
+	"github.com/hacka-re/cli/internal/share"
+)
+
+// MaxCells is how many cells are kept; the oldest are dropped first
+const MaxCells = 200
+
+// RunTimeout bounds a cell, long enough for the functions that make network
+// requests
+const RunTimeout = 30 * time.Second
+
+// maxValue is the largest result kept for later cells, in bytes of JSON
+const maxValue = 64 * 1024
+
+// Cell is a snippet that was run and what it returned
+type Cell struct {
+	Number   int             `json:"number"`
+	Input    string          `json:"input"`
+	Output   string          `json:"output"`
+	Value    json.RawMessage `json:"value,omitempty"` // The result, offered to later cells as $<number>
+	Error    bool            `json:"error,omitempty"`
+	Duration time.Duration   `json:"duration"`
+	RanAt    time.Time       `json:"ranAt"`
+}
+
+// Playground runs cells against a registry of functions
+type Playground struct {
+	Registry *jsruntime.Registry
+	Cells    []Cell
+	path     string
+}
+
+// DefaultPath returns where the cells are kept. HACKARE_PLAYGROUND_FILE
+// overrides it.
+func DefaultPath() string {
+	if path := os.Getenv("HACKARE_PLAYGROUND_FILE"); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-playground.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "playground.json")
+}
+
+// Open returns a playground with the cells kept at path and no functions
+func Open(path string) (*Playground, error) {
+	p := &Playground{Registry: jsruntime.NewRegistry(), path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("failed to read playground: %w", err)
+	}
+	if err := json.Unmarshal(data, &p.Cells); err != nil {
+		return p, fmt.Errorf("failed to parse playground %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// SetFunctions replaces the functions cells can call: every function of the
+// configuration, also those not yet enabled for the model, and the enabled
+// default groups
+func (p *Playground) SetFunctions(functions []share.Function, defaults map[string]bool) error {
+	registry := jsruntime.NewRegistry()
+	all := make([]share.Function, len(functions))
+	for i, fn := range functions {
+		fn.Enabled = true
+		all[i] = fn
+	}
+	err := jsruntime.LoadSharedFunctions(registry, all)
+	if defaultsErr := jsruntime.LoadEnabledDefaults(registry, defaults); err == nil {
+		err = defaultsErr
+	}
+	p.Registry = registry
+	return err
+}
+
+// Run runs input as a new cell and keeps it. The returned error is about
+// keeping the cell; failures of the code are reported in the cell.
+func (p *Playground) Run(input string) (Cell, error) {
+	cell := p.Evaluate(input)
+	return cell, p.Add(cell)
+}
+
+// Evaluate runs input as the next cell without keeping it, so it can run in
+// the background while the cells are shown. Input is JavaScript, or one of the
+// commands:
+//
+//	:call NAME {"param": value}  call a function with tool arguments, as the model would
+//	:functions                   list the functions that are loaded
+func (p *Playground) Evaluate(input string) Cell {
+	cell := Cell{Number: p.nextNumber(), Input: input, RanAt: time.Now()}
+	start := time.Now()
+	var value interface{}
+	var err error
+	trimmed := strings.TrimSpace(input)
+	switch {
+	case trimmed == ":functions":
+		cell.Output = p.describeFunctions()
+	case strings.HasPrefix(trimmed, ":call"):
+		value, err = p.call(strings.TrimSpace(strings.TrimPrefix(trimmed, ":call")))
+	case strings.HasPrefix(trimmed, ":"):
+		err = fmt.Errorf("unknown command %s (use :call or :functions)", strings.Fields(trimmed)[0])
+	default:
+		value, err = p.eval(input)
+	}
+	cell.Duration = time.Since(start)
+
+	if err != nil {
+		cell.Output, cell.Error = err.Error(), true
+	} else if cell.Output == "" {
+		cell.Output = Format(value)
+		if data, err := json.Marshal(value); err == nil && value != nil && len(data) <= maxValue {
+			cell.Value = data
+		}
+	}
+	return cell
+}
+
+// Add keeps a cell returned by Evaluate
+func (p *Playground) Add(cell Cell) error {
+	p.Cells = append(p.Cells, cell)
+	if len(p.Cells) > MaxCells {
+		p.Cells = p.Cells[len(p.Cells)-MaxCells:]
+	}
+	return p.save()
+}
+
+// Clear forgets every cell
+func (p *Playground) Clear() error {
+	p.Cells = nil
+	return p.save()
+}
+
+// Inputs returns the inputs of the cells, oldest first, for recalling them
+func (p *Playground) Inputs() []string {
+	inputs := make([]string, len(p.Cells))
+	for i, cell := range p.Cells {
+		inputs[i] = cell.Input
+	}
+	return inputs
+}
+
+func (p *Playground) nextNumber() int {
+	if len(p.Cells) == 0 {
+		return 1
+	}
+	return p.Cells[len(p.Cells)-1].Number + 1
+}
+
+// eval runs code after the functions and the results of earlier cells
+func (p *Playground) eval(code string) (interface{}, error) {
+	var b strings.Builder
+	for _, cell := range p.Cells {
+		if len(cell.Value) > 0 {
+			fmt.Fprintf(&b, "var $%d = %s;\n", cell.Number, cell.Value)
+		}
+	}
+	// Default groups share one file of code among their functions
+	seen := map[string]bool{}
+	for _, fn := range p.functions() {
+		if !seen[fn.Code] {
+			seen[fn.Code] = true
+			b.WriteString(fn.Code)
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString(code)
+
+	engine := jsruntime.NewEngine()
+	engine.SetTimeout(RunTimeout)
+	return engine.Execute(b.String())
+}
+
+// call runs a function with arguments given as a JSON object
+func (p *Playground) call(command string) (interface{}, error) {
+	name, rest, _ := strings.Cut(command, " ")
+	if name == "" {
+		return nil, errors.New(`usage: :call NAME {"param": value}`)
+	}
+	fn, err := p.Registry.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	args := map[string]interface{}{}
+	if rest = strings.TrimSpace(rest); rest != "" {
+		if err := json.Unmarshal([]byte(rest), &args); err != nil {
+			return nil, fmt.Errorf("arguments must be a JSON object: %w", err)
+		}
+	}
+	for _, param := range fn.Parameters {
+		if _, ok := args[param.Name]; param.Required && !ok {
+			return nil, fmt.Errorf("missing required argument %q", param.Name)
+		}
+	}
+	return fn.Execute(args)
+}
+
+// functions returns the loaded functions, sorted by name
+func (p *Playground) functions() []*jsruntime.Function {
+	var functions []*jsruntime.Function
+	for _, fn := range p.Registry.GetAll() {
+		functions = append(functions, fn)
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+	return functions
+}
+
+// describeFunctions lists the signatures of the loaded functions
+func (p *Playground) describeFunctions() string {
+	functions := p.functions()
+	if len(functions) == 0 {
+		return "No functions loaded. Add functions to the configuration, or enable default groups."
+	}
+	lines := make([]string, len(functions))
+	for i, fn := range functions {
+		var params []string
+		for _, param := range fn.Parameters {
+			if param.Required {
+				params = append(params, param.Name)
+			} else {
+				params = append(params, param.Name+"?")
+			}
+		}
+		lines[i] = fmt.Sprintf("%s(%s)", fn.Name, strings.Join(params, ", "))
+		if fn.Description != "" {
+			lines[i] += "  " + fn.Description
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Format renders a result for display: strings as they are, anything else as
+// indented JSON
+func Format(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "undefined"
+	case string:
+		return v
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// save writes the cells to the playground's file
+func (p *Playground) save() error {
+	data, err := json.MarshalIndent(p.Cells, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode playground: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0700); err != nil {
+		return fmt.Errorf("failed to create playground directory: %w", err)
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write playground: %w", err)
+	}
+	return os.Rename(tmp, p.path)
+}
@@ -0,0 +1,103 @@
+package playground
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/share"
+)
+
+const greet = `/**
+ * Greet someone
+ * @param {string} name - Who to greet
+ * @param {string} greeting - What to say
+ * @callable
+ */
+function greet(name, greeting) {
+    return (greeting || "Hello") + ", " + name + "!";
+}`
+
+func testPlayground(t *testing.T) (*Playground, string) {
+	path := filepath.Join(t.TempDir(), "playground.json")
+	p, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A disabled function is still loaded, so it can be tried out first
+	functions := []share.Function{{Name: "greet", Code: greet, Enabled: false}}
+	if err := p.SetFunctions(functions, map[string]bool{"security-utilities": true}); err != nil {
+		t.Fatal(err)
+	}
+	return p, path
+}
+
+func TestRun(t *testing.T) {
+	p, path := testPlayground(t)
+
+	cell, err := p.Run(`greet("Ada")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cell.Number != 1 || cell.Output != "Hello, Ada!" || cell.Error {
+		t.Errorf("cell = %+v", cell)
+	}
+
+	// Default functions and the results of earlier cells are available
+	cell, _ = p.Run(`({digest: hash_text("abc", "md5").hex, previous: $1})`)
+	if cell.Error || !strings.Contains(cell.Output, `"digest": "900150983cd24fb0d6963f7d28e17f72"`) || !strings.Contains(cell.Output, `"previous": "Hello, Ada!"`) {
+		t.Errorf("cell = %+v", cell)
+	}
+
+	cell, _ = p.Run(`nope(`)
+	if !cell.Error || cell.Number != 3 {
+		t.Errorf("syntax error cell = %+v", cell)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reopened.Cells) != 3 || strings.Join(reopened.Inputs(), "|") != `greet("Ada")|({digest: hash_text("abc", "md5").hex, previous: $1})|nope(` {
+		t.Errorf("reopened cells = %+v", reopened.Cells)
+	}
+	if cell, _ := reopened.Run("$1.length"); cell.Number != 4 || cell.Output != "11" {
+		t.Errorf("cell after reopening = %+v", cell)
+	}
+
+	if err := reopened.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if reopened, _ = Open(path); len(reopened.Cells) != 0 {
+		t.Errorf("%d cells left after Clear", len(reopened.Cells))
+	}
+}
+
+func TestCommands(t *testing.T) {
+	p, _ := testPlayground(t)
+
+	cell, _ := p.Run(`:call greet {"greeting": "Hi", "name": "Grace"}`)
+	if cell.Error || cell.Output != "Hi, Grace!" {
+		t.Errorf(":call = %+v", cell)
+	}
+	for _, bad := range []string{`:call greet {"greeting": "Hi"}`, `:call greet [1]`, `:call missing`, `:call`, `:nope`} {
+		if cell, _ := p.Run(bad); !cell.Error {
+			t.Errorf("%s = %+v, want an error", bad, cell)
+		}
+	}
+
+	cell, _ = p.Run(":functions")
+	if !strings.Contains(cell.Output, "greet(name, greeting)") || !strings.Contains(cell.Output, "hash_text(") {
+		t.Errorf(":functions = %s", cell.Output)
+	}
+}
+
+func TestMaxCells(t *testing.T) {
+	p, _ := testPlayground(t)
+	for i := 0; i < MaxCells+5; i++ {
+		p.Run("1")
+	}
+	if len(p.Cells) != MaxCells || p.Cells[0].Number != 6 {
+		t.Errorf("%d cells, first #%d", len(p.Cells), p.Cells[0].Number)
+	}
+}
@@ -0,0 +1,157 @@
+// Package promptlint checks a prompt before it is sent for mistakes that are
+// easy to make while typing or pasting: a wall of text in one paragraph, a
+// code fence that is never closed, a doubled word, or a pasted secret.
+package promptlint
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/redact"
+)
+
+// Kinds of warnings
+const (
+	KindLongParagraph = "long-paragraph"
+	KindOpenFence     = "open-fence"
+	KindDoubledWord   = "doubled-word"
+	KindSecret        = "secret"
+)
+
+// MaxParagraphWords is the length above which a single paragraph is flagged
+const MaxParagraphWords = 300
+
+// Warning is one problem found in a prompt
+type Warning struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// secretKinds are the redact findings that must not leave the machine by
+// accident; personal data such as addresses is often meant to be in a prompt
+var secretKinds = map[string]bool{
+	redact.KindAPIKey:     true,
+	redact.KindPrivateKey: true,
+	redact.KindToken:      true,
+	redact.KindPassword:   true,
+	redact.KindShareLink:  true,
+}
+
+// Lint returns the problems found in prompt, secrets first
+func Lint(prompt string) []Warning {
+	var warnings []Warning
+	for _, finding := range Secrets(prompt) {
+		warnings = append(warnings, Warning{
+			Kind:    KindSecret,
+			Message: fmt.Sprintf("Looks like a pasted secret (%s): %s", finding.Kind, mask(finding.Text)),
+		})
+	}
+
+	prose, fences := splitFences(prompt)
+	if fences%2 == 1 {
+		warnings = append(warnings, Warning{
+			Kind:    KindOpenFence,
+			Message: "A ``` code fence is opened but never closed, so the rest of the prompt reads as code",
+		})
+	}
+	if words := longestParagraph(prose); words > MaxParagraphWords {
+		warnings = append(warnings, Warning{
+			Kind:    KindLongParagraph,
+			Message: fmt.Sprintf("A single paragraph of %d words; blank lines or a list make long instructions easier to follow", words),
+		})
+	}
+	for _, word := range doubledWords(prose) {
+		warnings = append(warnings, Warning{
+			Kind:    KindDoubledWord,
+			Message: fmt.Sprintf("Doubled word: %q", word+" "+word),
+		})
+	}
+	return warnings
+}
+
+// Secrets returns the secrets found in prompt
+func Secrets(prompt string) []redact.Finding {
+	var secrets []redact.Finding
+	for _, finding := range redact.Scan([]api.Message{{Role: "user", Content: prompt}}) {
+		if secretKinds[finding.Kind] {
+			secrets = append(secrets, finding)
+		}
+	}
+	return secrets
+}
+
+// Redact replaces the secrets in prompt with placeholders
+func Redact(prompt string) string {
+	messages := []api.Message{{Role: "user", Content: prompt}}
+	return redact.Apply(messages, Secrets(prompt))[0].Content
+}
+
+// splitFences returns the prompt without fenced code, which isn't prose, and
+// the number of fence lines
+func splitFences(prompt string) (string, int) {
+	var prose []string
+	fences := 0
+	for _, line := range strings.Split(prompt, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fences++
+			prose = append(prose, "")
+			continue
+		}
+		if fences%2 == 0 {
+			prose = append(prose, line)
+		}
+	}
+	return strings.Join(prose, "\n"), fences
+}
+
+// longestParagraph returns the word count of the longest paragraph
+func longestParagraph(text string) int {
+	longest, words := 0, 0
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			words = 0
+			continue
+		}
+		words += len(strings.Fields(line))
+		if words > longest {
+			longest = words
+		}
+	}
+	return longest
+}
+
+// correctDoubles are words that are often doubled on purpose
+var correctDoubles = map[string]bool{"had": true, "that": true}
+
+// doubledWords returns words repeated back to back, like "the the"
+func doubledWords(text string) []string {
+	var doubled []string
+	seen := map[string]bool{}
+	previous := ""
+	for _, field := range strings.Fields(text) {
+		word := strings.ToLower(strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r)
+		}))
+		if word != "" && word == previous && !correctDoubles[word] && !seen[word] {
+			doubled = append(doubled, word)
+			seen[word] = true
+		}
+		previous = word
+		// Punctuation between the words, as in "no. No", ends the run
+		if strings.TrimRightFunc(field, unicode.IsPunct) != field {
+			previous = ""
+		}
+	}
+	return doubled
+}
+
+// mask shows only the ends of a secret
+func mask(secret string) string {
+	secret = strings.Join(strings.Fields(secret), " ")
+	if len(secret) <= 12 {
+		return strings.Repeat("•", 6)
+	}
+	return secret[:4] + "…" + secret[len(secret)-4:]
+}
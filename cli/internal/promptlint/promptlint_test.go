@@ -0,0 +1,48 @@
+package promptlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func kinds(warnings []Warning) string {
+	var list []string
+	for _, w := range warnings {
+		list = append(list, w.Kind)
+	}
+	return strings.Join(list, ",")
+}
+
+func TestLint(t *testing.T) {
+	long := strings.Repeat("scan each port ", MaxParagraphWords/3+1)
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{"clean", "Summarize the log below.\n\n```\nerror: the the file\n```", ""},
+		{"long paragraph", long, KindLongParagraph},
+		{"split paragraphs", strings.Repeat("scan each port ", 70) + "\n\n" + strings.Repeat("scan each port ", 70), ""},
+		{"open fence", "Fix this:\n```go\nfunc main() {", KindOpenFence},
+		{"doubled word", "Check the the firewall rules.", KindDoubledWord},
+		{"doubled across a sentence", "Is it open? Open it.", ""},
+		{"secret", "Why does sk-proj-abcdefghijklmnopqrstuvwxyz123456 fail?", KindSecret},
+		{"email is fine", "Mail alice@example.com the report.", ""},
+	}
+	for _, tt := range tests {
+		if got := kinds(Lint(tt.prompt)); got != tt.want {
+			t.Errorf("%s: Lint = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	prompt := "Use password=hunter22x to log in"
+	got := Redact(prompt)
+	if strings.Contains(got, "hunter22x") || !strings.Contains(got, "[REDACTED:password]") {
+		t.Errorf("Redact = %q", got)
+	}
+	if w := Lint(prompt); len(w) != 1 || strings.Contains(w[0].Message, "hunter22x") {
+		t.Errorf("the warning shows the secret: %+v", w)
+	}
+}
@@ -0,0 +1,289 @@
+package qrcode
+
+// matrix is a code being built: the modules and which of them belong to
+// function patterns, which the data and the mask leave alone
+type matrix struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// build places the function patterns and codewords and applies mask
+func build(codewords []byte, version int, level Level, mask int) *matrix {
+	size := 4*version + 17
+	m := &matrix{version: version, size: size, modules: make([][]bool, size), isFunction: make([][]bool, size)}
+	for y := range m.modules {
+		m.modules[y] = make([]bool, size)
+		m.isFunction[y] = make([]bool, size)
+	}
+
+	m.drawFunctionPatterns()
+	m.drawFormatBits(level, mask)
+	m.drawCodewords(codewords)
+	m.applyMask(mask)
+	return m
+}
+
+// code returns the finished Code
+func (m *matrix) code() *Code {
+	return &Code{Version: m.version, Size: m.size, modules: m.modules}
+}
+
+func (m *matrix) setFunction(x, y int, dark bool) {
+	m.modules[y][x] = dark
+	m.isFunction[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder, alignment and version patterns
+func (m *matrix) drawFunctionPatterns() {
+	for i := 0; i < m.size; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	m.drawFinder(3, 3)
+	m.drawFinder(m.size-4, 3)
+	m.drawFinder(3, m.size-4)
+
+	positions := alignmentPositions(m.version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// The corners that overlap the finder patterns stay empty
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			m.drawAlignment(x, y)
+		}
+	}
+
+	// Reserve the format areas; drawFormatBits fills them in
+	m.drawFormatBits(0, 0)
+	m.drawVersion()
+}
+
+// drawFinder draws a finder pattern and its separator around center x, y
+func (m *matrix) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= m.size || yy >= m.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			m.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern around center x, y
+func (m *matrix) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions returns the centers of the alignment patterns along each axis
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	if version == 32 {
+		step = 26
+	}
+	positions := make([]int, numAlign)
+	positions[0] = 6
+	for i, pos := numAlign-1, 4*version+10; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// drawFormatBits draws both copies of the level and mask information
+func (m *matrix) drawFormatBits(level Level, mask int) {
+	data := formatLevelBits[level]<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(bits, i))
+	}
+	m.setFunction(8, 7, bit(bits, 6))
+	m.setFunction(8, 8, bit(bits, 7))
+	m.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.size-15+i, bit(bits, i))
+	}
+	m.setFunction(8, m.size-8, true) // The dark module
+}
+
+// drawVersion draws both copies of the version information, from version 7 on
+func (m *matrix) drawVersion() {
+	if m.version < 7 {
+		return
+	}
+	rem := m.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := m.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		a, b := m.size-11+i%3, i/3
+		m.setFunction(a, b, bit(bits, i))
+		m.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// drawCodewords fills the non-function modules in the zigzag order of the standard
+func (m *matrix) drawCodewords(codewords []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		for vert := 0; vert < m.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = m.size - 1 - vert // Upward column
+				}
+				if !m.isFunction[y][x] && i < len(codewords)*8 {
+					m.modules[y][x] = bit(int(codewords[i>>3]), 7-i&7)
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by the mask pattern
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip && !m.isFunction[y][x] {
+				m.modules[y][x] = !m.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code is to scan, lower is better
+func (m *matrix) penalty() int {
+	const (
+		penaltyRun     = 3  // For a run of 5 same-colored modules, plus 1 per extra module
+		penaltyBlock   = 3  // Per 2×2 block of one color
+		penaltyFinder  = 40 // Per pattern that looks like a finder
+		penaltyBalance = 10 // Per 5% of imbalance between dark and light
+	)
+	result := 0
+	at := func(x, y int, horizontal bool) bool {
+		if horizontal {
+			return m.modules[y][x]
+		}
+		return m.modules[x][y]
+	}
+
+	for _, horizontal := range []bool{true, false} {
+		for y := 0; y < m.size; y++ {
+			run := 1
+			for x := 1; x <= m.size; x++ {
+				if x < m.size && at(x, y, horizontal) == at(x-1, y, horizontal) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					result += penaltyRun + run - 5
+				}
+				run = 1
+			}
+			for x := 0; x+7 <= m.size; x++ {
+				if m.finderLike(x, y, horizontal, at) {
+					result += penaltyFinder
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if m.modules[y][x] {
+				dark++
+			}
+			if x+1 < m.size && y+1 < m.size {
+				c := m.modules[y][x]
+				if c == m.modules[y][x+1] && c == m.modules[y+1][x] && c == m.modules[y+1][x+1] {
+					result += penaltyBlock
+				}
+			}
+		}
+	}
+	total := m.size * m.size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return result + max(k, 0)*penaltyBalance
+}
+
+// finderLike reports whether the 1:1:3:1:1 pattern starts at x in line y,
+// with four light modules (or the edge) on one side
+func (m *matrix) finderLike(x, y int, horizontal bool, at func(x, y int, horizontal bool) bool) bool {
+	for i, dark := range []bool{true, false, true, true, true, false, true} {
+		if at(x+i, y, horizontal) != dark {
+			return false
+		}
+	}
+	light := func(from, to int) bool {
+		for i := from; i < to; i++ {
+			if i >= 0 && i < m.size && at(i, y, horizontal) {
+				return false
+			}
+		}
+		return true
+	}
+	return light(x-4, x) || light(x+7, x+11)
+}
+
+func bit(x, i int) bool {
+	return (x>>i)&1 != 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
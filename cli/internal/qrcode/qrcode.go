@@ -0,0 +1,238 @@
+// Package qrcode encodes bytes as a QR Code (ISO/IEC 18004, byte mode) so
+// share links can be shown as scannable codes in the terminal or on a web page.
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Level is the error correction level; higher levels survive more damage
+// but hold less data
+type Level int
+
+const (
+	Low      Level = iota // Recovers ~7% of the code; what the web app uses for share links
+	Medium                // ~15%
+	Quartile              // ~25%
+	High                  // ~30%
+)
+
+// MaxVersion is the largest QR Code version, 177×177 modules
+const MaxVersion = 40
+
+// ErrTooLong is returned for data that does not fit in a version 40 code
+var ErrTooLong = errors.New("data too long for a QR code")
+
+// Code is an encoded QR Code
+type Code struct {
+	Version int
+	Size    int      // Modules per side, 4*Version+17
+	modules [][]bool // [y][x], true is dark
+}
+
+// Dark reports whether the module at column x, row y is dark. Coordinates
+// outside the code are light, like the quiet zone around it.
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Size && y < c.Size && c.modules[y][x]
+}
+
+// VersionFor returns the smallest version that holds n bytes at level
+func VersionFor(n int, level Level) (int, bool) {
+	for version := 1; version <= MaxVersion; version++ {
+		if dataBits(n, version) <= numDataCodewords(version, level)*8 {
+			return version, true
+		}
+	}
+	return 0, false
+}
+
+// Encode encodes data in the smallest version that holds it at level,
+// choosing the mask with the lowest penalty
+func Encode(data []byte, level Level) (*Code, error) {
+	version, ok := VersionFor(len(data), level)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLong, len(data))
+	}
+	codewords := addECCAndInterleave(dataCodewords(data, version, level), version, level)
+
+	var best *matrix
+	bestPenalty := 0
+	for mask := 0; mask < 8; mask++ {
+		candidate := build(codewords, version, level, mask)
+		if penalty := candidate.penalty(); best == nil || penalty < bestPenalty {
+			best, bestPenalty = candidate, penalty
+		}
+	}
+	return best.code(), nil
+}
+
+// encodeAt encodes data in a given version with a fixed mask, for tests
+func encodeAt(data []byte, version int, level Level, mask int) *Code {
+	codewords := addECCAndInterleave(dataCodewords(data, version, level), version, level)
+	return build(codewords, version, level, mask).code()
+}
+
+// Error correction codewords per block and number of blocks, by level and version (index 0 unused)
+var (
+	eccPerBlock = [4][MaxVersion + 1]int{
+		{0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+		{0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	}
+	eccBlocks = [4][MaxVersion + 1]int{
+		{0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+		{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+		{0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+		{0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+	}
+	// formatLevelBits are the level's two bits in the format information
+	formatLevelBits = [4]int{1, 0, 3, 2}
+)
+
+// numRawDataModules counts the modules left for data and error correction
+// once the function patterns are placed
+func numRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+// numDataCodewords is the capacity of a version at a level, in bytes
+func numDataCodewords(version int, level Level) int {
+	return numRawDataModules(version)/8 - eccPerBlock[level][version]*eccBlocks[level][version]
+}
+
+// countBits is the size of the byte count field
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// dataBits is the size of n bytes encoded in byte mode
+func dataBits(n, version int) int {
+	if n >= 1<<countBits(version) {
+		return 1 << 30
+	}
+	return 4 + countBits(version) + 8*n
+}
+
+// dataCodewords encodes data in byte mode and pads it to the capacity of the version
+func dataCodewords(data []byte, version int, level Level) []byte {
+	var bits bitBuffer
+	bits.append(0x4, 4) // Byte mode
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := numDataCodewords(version, level) * 8
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+	return codewords
+}
+
+// addECCAndInterleave splits the data into blocks, appends Reed-Solomon
+// error correction to each and interleaves them
+func addECCAndInterleave(data []byte, version int, level Level) []byte {
+	numBlocks := eccBlocks[level][version]
+	eccLen := eccPerBlock[level][version]
+	rawCodewords := numRawDataModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		length := shortBlockLen - eccLen
+		if i >= numShortBlocks {
+			length++
+		}
+		block := append([]byte(nil), data[k:k+length]...)
+		k += length
+		ecc := reedSolomonRemainder(block, divisor)
+		if i < numShortBlocks {
+			block = append(block, 0) // Keeps the columns aligned; skipped below
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := 0; i < len(blocks[0]); i++ {
+		for j, block := range blocks {
+			if i != shortBlockLen-eccLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// reedSolomonDivisor returns the generator polynomial of the given degree
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords of data
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// bitBuffer is a sequence of bits, most significant first
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 != 0)
+	}
+}
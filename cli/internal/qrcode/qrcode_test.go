@@ -0,0 +1,142 @@
+package qrcode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testLink returns n bytes of a share link
+func testLink(n int) []byte {
+	return []byte(("https://hacka.re/#gpt=" + strings.Repeat("x", n))[:n])
+}
+
+// fingerprint hashes the modules row by row, # for dark and . for light
+func fingerprint(code *Code) string {
+	var rows []string
+	for y := 0; y < code.Size; y++ {
+		var row strings.Builder
+		for x := 0; x < code.Size; x++ {
+			if code.Dark(x, y) {
+				row.WriteByte('#')
+			} else {
+				row.WriteByte('.')
+			}
+		}
+		rows = append(rows, row.String())
+	}
+	sum := sha256.Sum256([]byte(strings.Join(rows, "\n")))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// TestMatchesWebApp compares codes with the ones lib/qrcode/qrcode.min.js
+// draws for the same data, level and mask. The web app sometimes picks a
+// larger version than needed, so the version is fixed too.
+func TestMatchesWebApp(t *testing.T) {
+	vectors := []struct {
+		level       Level
+		mask        int
+		length      int
+		version     int
+		fingerprint string
+	}{
+		{Low, 0, 5, 1, "e541f1fbed626e75"},
+		{Low, 1, 40, 3, "cb1a4f5bff32d96f"},
+		{Low, 2, 100, 5, "591139e8932168a0"},
+		{Low, 3, 200, 9, "08623ae8d90848e7"},
+		{Low, 4, 300, 11, "5d674b2c024850ef"},
+		{Low, 5, 400, 13, "f0d3bef2be50d484"},
+		{Low, 6, 500, 15, "2dbb7f7dc63fb168"},
+		{Low, 7, 700, 18, "9f0c8d80f0003094"},
+		{Low, 0, 900, 21, "4a54f55ee636ea8d"},
+		{Low, 1, 1100, 24, "9b91742fdf3ce74d"},
+		{Low, 2, 1300, 26, "70171655dc99c500"},
+		{Low, 3, 1600, 29, "007b2f5015d393b3"},
+		{Low, 4, 1900, 32, "fe2030f28e45cf3b"},
+		{Low, 5, 2300, 35, "9c5ea67bfd9db85c"},
+		{Low, 6, 2800, 39, "7ded24843ab98efa"},
+		{Medium, 0, 5, 1, "1d2a5c822bac3544"},
+		{Medium, 1, 60, 5, "b8f83991d4fc76c7"},
+		{Medium, 2, 150, 9, "986348486216feaa"},
+		{Medium, 3, 350, 14, "eced96eafcc2379c"},
+		{Medium, 4, 600, 19, "3349a80dff5fd39e"},
+		{Medium, 5, 1000, 26, "ac6ce95b60baadfe"},
+		{Medium, 6, 1500, 32, "f4b135b41f701026"},
+		{Medium, 7, 2200, 39, "3f3cbf499676e956"},
+		{Quartile, 0, 10, 2, "8bc4452eb8865277"},
+		{Quartile, 1, 80, 7, "4bea5054c864dbdf"},
+		{Quartile, 2, 250, 14, "f8e64581adc56503"},
+		{Quartile, 3, 500, 21, "af7098fa511b583e"},
+		{Quartile, 4, 900, 29, "d27ff2ede793898d"},
+		{Quartile, 5, 1600, 40, "33d23c89a06b0665"},
+		{High, 0, 10, 2, "37bc72830fdc2f12"},
+		{High, 1, 70, 8, "d32abad8e4f8609c"},
+		{High, 3, 400, 21, "872032b1b703833e"},
+		{High, 4, 800, 32, "7669f2aa369da1c5"},
+		{High, 5, 1200, 39, "f9847fc76926780a"},
+	}
+	for _, v := range vectors {
+		if version, ok := VersionFor(v.length, v.level); !ok || version > v.version {
+			t.Errorf("level %d, %d bytes: version %d, the web app fits it in %d", v.level, v.length, version, v.version)
+		}
+		code := encodeAt(testLink(v.length), v.version, v.level, v.mask)
+		if got := fingerprint(code); got != v.fingerprint {
+			t.Errorf("level %d, mask %d, %d bytes: modules differ from the web app", v.level, v.mask, v.length)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	code, err := Encode(testLink(500), Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Version != 15 || code.Dark(-1, 0) || code.Dark(code.Size, 0) || !code.Dark(0, 0) {
+		t.Fatalf("unexpected code: version %d, size %d", code.Version, code.Size)
+	}
+
+	if _, err := Encode(testLink(3000), Low); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("expected ErrTooLong, got %v", err)
+	}
+	if version, ok := VersionFor(2953, Low); !ok || version != MaxVersion {
+		t.Fatalf("VersionFor(2953) = %d, %t", version, ok)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	code, err := Encode(testLink(50), Low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := code.SVG(4)
+	size := code.Size + 8
+	if !strings.HasPrefix(svg, "<svg ") || !strings.Contains(svg, fmt.Sprintf(`viewBox="0 0 %d %d"`, size, size)) {
+		t.Fatalf("unexpected SVG header: %.120s", svg)
+	}
+
+	// Every dark module is covered by exactly one run
+	dark, covered := 0, 0
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Dark(x, y) {
+				dark++
+			}
+		}
+	}
+	for _, run := range strings.Split(svg, "M")[1:] {
+		var x, y, w int
+		if _, err := fmt.Sscanf(run, "%d %dh%d", &x, &y, &w); err != nil {
+			t.Fatalf("bad run %q: %v", run, err)
+		}
+		covered += w
+		if !code.Dark(x-4, y-4) || !code.Dark(x-4+w-1, y-4) {
+			t.Fatalf("run %q starts or ends on a light module", run)
+		}
+	}
+	if covered != dark {
+		t.Fatalf("runs cover %d modules, want %d", covered, dark)
+	}
+}
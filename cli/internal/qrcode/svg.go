@@ -0,0 +1,30 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SVG draws the code as an SVG image with quietZone light modules around it.
+// Each module is one user unit, so the image scales to any size without blur;
+// runs of dark modules in a row are one path segment.
+func (c *Code) SVG(quietZone int) string {
+	size := c.Size + 2*quietZone
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; {
+			if !c.Dark(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < c.Size && c.Dark(x, y) {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start+quietZone, y+quietZone, x-start, x-start)
+		}
+	}
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="%s"/></svg>`,
+		size, size, size, size, path.String())
+}
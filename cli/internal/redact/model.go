@@ -0,0 +1,98 @@
+package redact
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// Completer sends chat completions; *api.Client implements it
+type Completer interface {
+	SendChatCompletionContext(ctx context.Context, messages []api.Message, streamCallback api.StreamCallback) (*api.ChatResponse, error)
+}
+
+// modelPrompt asks for sensitive strings as JSON so they can be located exactly
+const modelPrompt = `You review chat transcripts for data that must be removed before sharing.
+List every secret or piece of personal data in the transcript below: credentials,
+API keys, tokens, personal names, email and postal addresses, phone numbers,
+account or ID numbers, internal host names and IP addresses.
+
+Reply with JSON only, no prose:
+[{"message": <message number>, "text": "<exact text as it appears>", "kind": "<short kind, e.g. name>"}]
+Reply [] if there is nothing to remove.`
+
+// modelFinding is one entry of the model's reply
+type modelFinding struct {
+	Message int    `json:"message"`
+	Text    string `json:"text"`
+	Kind    string `json:"kind"`
+}
+
+// ModelScan asks the model for sensitive data the patterns can't recognise,
+// such as names and addresses. Only text that occurs verbatim in the named
+// message is returned, so a confused reply can't redact arbitrary text.
+func ModelScan(ctx context.Context, client Completer, messages []api.Message) ([]Finding, error) {
+	var transcript strings.Builder
+	for i, msg := range messages {
+		fmt.Fprintf(&transcript, "--- message %d (%s) ---\n%s\n", i, msg.Role, msg.Content)
+	}
+
+	response, err := client.SendChatCompletionContext(ctx, []api.Message{
+		{Role: "system", Content: modelPrompt},
+		{Role: "user", Content: transcript.String()},
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("model scan failed: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("model scan failed: empty response")
+	}
+
+	entries, err := parseModelReply(response.Choices[0].Message.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, entry := range entries {
+		if entry.Message < 0 || entry.Message >= len(messages) || len(strings.TrimSpace(entry.Text)) < 2 {
+			continue
+		}
+		kind := strings.ToLower(strings.Join(strings.Fields(entry.Kind), "-"))
+		if kind == "" {
+			kind = "sensitive"
+		}
+		content := messages[entry.Message].Content
+		for offset := 0; ; {
+			i := strings.Index(content[offset:], entry.Text)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			findings = addFinding(findings, Finding{
+				Message: entry.Message, Start: start, End: start + len(entry.Text),
+				Kind: kind, Text: entry.Text, Source: "model",
+			})
+			offset = start + len(entry.Text)
+		}
+	}
+	sortFindings(findings)
+	return findings, nil
+}
+
+// parseModelReply extracts the JSON array from a reply that may be wrapped in a code fence
+func parseModelReply(reply string) ([]modelFinding, error) {
+	start := strings.Index(reply, "[")
+	end := strings.LastIndex(reply, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("model scan returned no JSON list")
+	}
+	var entries []modelFinding
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &entries); err != nil {
+		return nil, fmt.Errorf("model scan returned invalid JSON: %w", err)
+	}
+	return entries, nil
+}
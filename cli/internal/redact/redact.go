@@ -0,0 +1,196 @@
+// Package redact finds secrets and personal data in a conversation so they can
+// be reviewed and replaced before the conversation is exported or shared.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// Kinds of sensitive data
+const (
+	KindAPIKey     = "api-key"
+	KindPrivateKey = "private-key"
+	KindToken      = "token"
+	KindPassword   = "password"
+	KindShareLink  = "share-link"
+	KindEmail      = "email"
+	KindPhone      = "phone"
+	KindCreditCard = "credit-card"
+	KindIPAddress  = "ip-address"
+)
+
+// Finding is a piece of sensitive text in one message
+type Finding struct {
+	Message int // Index into the scanned messages
+	Start   int // Byte offsets into the message content
+	End     int
+	Kind    string
+	Text    string
+	Source  string // "pattern" or "model"
+}
+
+// Replacement is the text that replaces the finding
+func (f Finding) Replacement() string {
+	return "[REDACTED:" + f.Kind + "]"
+}
+
+// detector finds one kind of sensitive data. Group 1, if present, is the sensitive part.
+type detector struct {
+	kind    string
+	pattern *regexp.Regexp
+	valid   func(string) bool
+}
+
+// detectors are tried in order; earlier ones win where matches overlap
+var detectors = []detector{
+	{KindPrivateKey, regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), nil},
+	{KindShareLink, regexp.MustCompile(`\S*#gpt=[A-Za-z0-9_\-+/=]+`), nil},
+	{KindAPIKey, regexp.MustCompile(`\b(?:sk-(?:ant-|proj-)?[A-Za-z0-9_\-]{20,}|gsk_[A-Za-z0-9]{20,}|AIza[0-9A-Za-z_\-]{35}|AKIA[0-9A-Z]{16}|gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,}|xox[abposr]-[A-Za-z0-9-]{10,}|glpat-[A-Za-z0-9_\-]{20})\b`), nil},
+	{KindToken, regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`), nil},
+	{KindToken, regexp.MustCompile(`(?i)\bBearer\s+([A-Za-z0-9._~+/\-]{20,}=*)`), nil},
+	{KindPassword, regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|api[_-]?key|access[_-]?token)\b["']?\s*[:=]\s*["']?([^\s"',;]{4,})`), nil},
+	{KindPassword, regexp.MustCompile(`\b[a-z][a-z0-9+.\-]*://[^\s:/@]+:([^\s@/]+)@`), nil},
+	{KindEmail, regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`), nil},
+	{KindCreditCard, regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`), luhn},
+	{KindPhone, regexp.MustCompile(`(?:\+\d{1,3}[ \-.]?)?\(?\b\d{2,4}\)?[ \-.]\d{3,4}[ \-.]\d{3,4}\b`), nil},
+	{KindIPAddress, regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`), nil},
+}
+
+// Scan returns the sensitive data found by pattern in messages, ordered by
+// message and position, without overlaps
+func Scan(messages []api.Message) []Finding {
+	var findings []Finding
+	for i, msg := range messages {
+		findings = append(findings, scanText(i, msg.Content)...)
+	}
+	return findings
+}
+
+// scanText applies the detectors to one message
+func scanText(message int, text string) []Finding {
+	var findings []Finding
+	for _, d := range detectors {
+		for _, m := range d.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			if d.valid != nil && !d.valid(text[start:end]) {
+				continue
+			}
+			findings = addFinding(findings, Finding{
+				Message: message, Start: start, End: end,
+				Kind: d.kind, Text: text[start:end], Source: "pattern",
+			})
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
+
+// Merge adds findings (e.g. from the model pass) that don't overlap existing ones
+func Merge(findings []Finding, more []Finding) []Finding {
+	merged := append([]Finding(nil), findings...)
+	for _, f := range more {
+		merged = addFinding(merged, f)
+	}
+	sortFindings(merged)
+	return merged
+}
+
+// addFinding appends f unless it overlaps a finding already in the same message
+func addFinding(findings []Finding, f Finding) []Finding {
+	for _, existing := range findings {
+		if existing.Message == f.Message && f.Start < existing.End && existing.Start < f.End {
+			return findings
+		}
+	}
+	return append(findings, f)
+}
+
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Message != findings[j].Message {
+			return findings[i].Message < findings[j].Message
+		}
+		return findings[i].Start < findings[j].Start
+	})
+}
+
+// Apply returns a copy of messages with the findings replaced. Findings must
+// come from scanning the same messages.
+func Apply(messages []api.Message, findings []Finding) []api.Message {
+	redacted := make([]api.Message, len(messages))
+	copy(redacted, messages)
+
+	byMessage := make(map[int][]Finding)
+	for _, f := range findings {
+		byMessage[f.Message] = append(byMessage[f.Message], f)
+	}
+	for i, list := range byMessage {
+		if i < 0 || i >= len(redacted) {
+			continue
+		}
+		sortFindings(list)
+		content := redacted[i].Content
+		var b strings.Builder
+		last := 0
+		for _, f := range list {
+			if f.Start < last || f.End > len(content) {
+				continue
+			}
+			b.WriteString(content[last:f.Start])
+			b.WriteString(f.Replacement())
+			last = f.End
+		}
+		b.WriteString(content[last:])
+		redacted[i].Content = b.String()
+	}
+	return redacted
+}
+
+// Context returns the finding with a little surrounding text, for review
+func (f Finding) Context(messages []api.Message, width int) string {
+	content := messages[f.Message].Content
+	start := f.Start - width
+	if start < 0 {
+		start = 0
+	}
+	end := f.End + width
+	if end > len(content) {
+		end = len(content)
+	}
+	before := strings.ToValidUTF8(content[start:f.Start], "")
+	after := strings.ToValidUTF8(content[f.End:end], "")
+	return strings.Join(strings.Fields(fmt.Sprintf("%s«%s»%s", before, f.Text, after)), " ")
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum used by card numbers
+func luhn(s string) bool {
+	var digits []int
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if (len(digits)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
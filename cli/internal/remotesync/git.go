@@ -0,0 +1,147 @@
+package remotesync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// CloneDir returns where the git backend keeps its clone.
+// HACKARE_SYNC_GIT_DIR overrides it.
+func CloneDir() string {
+	if dir := os.Getenv("HACKARE_SYNC_GIT_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hacka.re-sync")
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "sync-git")
+}
+
+// Git stores snapshots as files in a git repository, one commit per push. It
+// runs the git command, so the user's SSH keys and credential helpers apply.
+type Git struct {
+	repo   string
+	branch string
+	dir    string
+
+	mu sync.Mutex
+}
+
+// NewGit returns a backend for branch of repo, cloned into dir
+func NewGit(repo, branch, dir string) *Git {
+	if branch == "" {
+		branch = "main"
+	}
+	return &Git{repo: repo, branch: branch, dir: dir}
+}
+
+// Name describes the repository
+func (g *Git) Name() string {
+	return "git repo " + g.repo
+}
+
+// git runs a git command in the clone. In offline mode fetch and push are
+// refused unless the repository is a local path, since git connects on its
+// own and netguard can't check where to.
+func (g *Git) git(ctx context.Context, args ...string) (string, error) {
+	if (args[0] == "fetch" || args[0] == "push") && netguard.Offline() && !localRepo(g.repo) {
+		netguard.RecordViolation(g.repo)
+		return "", fmt.Errorf("git %s: %w: %s is not a local repository", args[0], netguard.ErrBlocked, g.repo)
+	}
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", g.dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// localRepo reports whether repo is a path on this machine rather than a URL
+// or an scp-style host:path
+func localRepo(repo string) bool {
+	if strings.HasPrefix(repo, "file://") {
+		return true
+	}
+	if strings.Contains(repo, "://") {
+		return false
+	}
+	_, err := os.Stat(repo)
+	return err == nil
+}
+
+// update clones the repository, or brings the clone up to date with the
+// remote branch. A branch the remote doesn't have yet starts empty.
+func (g *Git) update(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(g.dir, ".git")); err != nil {
+		if err := os.MkdirAll(g.dir, 0700); err != nil {
+			return fmt.Errorf("failed to create sync clone: %w", err)
+		}
+		if _, err := g.git(ctx, "init", "-q"); err != nil {
+			return err
+		}
+		if _, err := g.git(ctx, "remote", "add", "origin", g.repo); err != nil {
+			return err
+		}
+	}
+	if _, err := g.git(ctx, "fetch", "-q", "origin"); err != nil {
+		return err
+	}
+	remote := "origin/" + g.branch
+	if _, err := g.git(ctx, "rev-parse", "-q", "--verify", remote); err != nil {
+		_, err := g.git(ctx, "checkout", "-q", "-B", g.branch)
+		return err
+	}
+	_, err := g.git(ctx, "checkout", "-q", "-f", "-B", g.branch, remote)
+	return err
+}
+
+// Push commits data as name and pushes the branch
+func (g *Git) Push(ctx context.Context, name string, data []byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.update(ctx); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(g.dir, name), data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := g.git(ctx, "add", "--", name); err != nil {
+		return err
+	}
+	if status, err := g.git(ctx, "status", "--porcelain", "--", name); err != nil || status == "" {
+		return err // Nothing changed
+	}
+	if _, err := g.git(ctx, "-c", "user.name=hacka.re", "-c", "user.email=sync@hacka.re", "-c", "commit.gpgsign=false",
+		"commit", "-q", "-m", "Update "+name, "--", name); err != nil {
+		return err
+	}
+	_, err := g.git(ctx, "push", "-q", "origin", g.branch)
+	return err
+}
+
+// Pull returns the latest pushed name
+func (g *Git) Pull(ctx context.Context, name string) ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err := g.update(ctx); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(g.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
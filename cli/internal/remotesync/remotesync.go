@@ -0,0 +1,159 @@
+// Package remotesync keeps a namespace the same on several machines by
+// pushing it to, and pulling it from, storage the user provides: a private
+// git repository, an S3 bucket or a WebDAV folder. What is stored is a
+// snapshot without secrets, encrypted before it leaves the machine, so the
+// storage never sees API keys or the contents in the clear.
+//
+// The backend is set in a local file that is itself never synced:
+//
+//	{"backend": "git", "repo": "git@github.com:me/hackare-sync.git"}
+//	{"backend": "s3", "bucket": "my-bucket", "region": "eu-north-1", "prefix": "hacka.re/"}
+//	{"backend": "webdav", "url": "https://dav.example.com/hacka.re/", "username": "me"}
+//
+// S3 credentials come from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN, the WebDAV password from HACKARE_WEBDAV_PASSWORD.
+package remotesync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hacka-re/cli/internal/memory"
+	"github.com/hacka-re/cli/internal/snapshot"
+)
+
+// ErrNotFound is returned when the storage has nothing under a name
+var ErrNotFound = errors.New("nothing synced under that name yet")
+
+// ErrNotConfigured is returned when there is no sync file
+var ErrNotConfigured = errors.New("sync is not set up")
+
+// Config is the contents of the sync file
+type Config struct {
+	Backend string `json:"backend"` // git, s3 or webdav
+
+	// git
+	Repo   string `json:"repo,omitempty"`   // Anything git clone accepts
+	Branch string `json:"branch,omitempty"` // Default: main
+
+	// s3
+	Bucket   string `json:"bucket,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"` // For S3-compatible storage; path-style URLs are used
+	Prefix   string `json:"prefix,omitempty"`   // Prepended to object names
+
+	// webdav
+	URL      string `json:"url,omitempty"` // Folder the snapshots are put in
+	Username string `json:"username,omitempty"`
+}
+
+// Backend stores and fetches sealed snapshots by name
+type Backend interface {
+	// Name describes the storage for messages, e.g. "git repo git@host:x.git"
+	Name() string
+	Push(ctx context.Context, name string, data []byte) error
+	// Pull returns ErrNotFound if nothing was pushed under name
+	Pull(ctx context.Context, name string) ([]byte, error)
+}
+
+// Path returns the sync file. HACKARE_SYNC_CONFIG overrides it.
+func Path() string {
+	if path := os.Getenv("HACKARE_SYNC_CONFIG"); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-sync.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "sync.json")
+}
+
+// Load reads the sync file
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: create %s", ErrNotConfigured, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse sync config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Open returns the backend the config describes
+func Open(cfg *Config) (Backend, error) {
+	switch cfg.Backend {
+	case "git":
+		if cfg.Repo == "" {
+			return nil, errors.New("the git sync backend needs a repo")
+		}
+		return NewGit(cfg.Repo, cfg.Branch, CloneDir()), nil
+	case "s3":
+		if cfg.Bucket == "" {
+			return nil, errors.New("the s3 sync backend needs a bucket")
+		}
+		credentials := Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+			return nil, errors.New("the s3 sync backend needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		return NewS3(cfg.Bucket, cfg.Region, cfg.Endpoint, cfg.Prefix, credentials), nil
+	case "webdav":
+		if cfg.URL == "" {
+			return nil, errors.New("the webdav sync backend needs a url")
+		}
+		return NewWebDAV(cfg.URL, cfg.Username, os.Getenv("HACKARE_WEBDAV_PASSWORD")), nil
+	case "":
+		return nil, errors.New("the sync config names no backend (git, s3 or webdav)")
+	default:
+		return nil, fmt.Errorf("unknown sync backend %q (want git, s3 or webdav)", cfg.Backend)
+	}
+}
+
+// ObjectName returns the name a namespace is stored under
+func ObjectName(namespace string) string {
+	return memory.Namespace(namespace) + snapshot.Extension
+}
+
+// Push stores the namespace in paths, without secrets, sealed with password
+func Push(ctx context.Context, backend Backend, paths snapshot.Paths, namespace, password string) (*snapshot.Snapshot, error) {
+	s, err := snapshot.Create(paths, namespace)
+	if err != nil {
+		return nil, err
+	}
+	s = s.WithoutSecrets()
+	data, err := s.Seal(password)
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.Push(ctx, ObjectName(s.Namespace), data); err != nil {
+		return nil, fmt.Errorf("failed to push to %s: %w", backend.Name(), err)
+	}
+	return s, nil
+}
+
+// Pull fetches the namespace and adds the local secrets from paths, ready
+// to be restored
+func Pull(ctx context.Context, backend Backend, paths snapshot.Paths, namespace, password string) (*snapshot.Snapshot, error) {
+	data, err := backend.Pull(ctx, ObjectName(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull from %s: %w", backend.Name(), err)
+	}
+	s, err := snapshot.Open(data, password)
+	if err != nil {
+		return nil, err
+	}
+	s.KeepSecrets(paths)
+	return s, nil
+}
@@ -0,0 +1,177 @@
+package remotesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// Credentials sign S3 requests
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Only for temporary credentials
+}
+
+// S3 stores snapshots as objects in a bucket, signed with AWS Signature
+// Version 4. An endpoint makes it work with S3-compatible storage such as
+// MinIO.
+type S3 struct {
+	bucket      string
+	region      string
+	endpoint    string
+	prefix      string
+	credentials Credentials
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewS3 returns a backend for bucket. Without an endpoint, AWS is used.
+func NewS3(bucket, region, endpoint, prefix string, credentials Credentials) *S3 {
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3{
+		bucket:      bucket,
+		region:      region,
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		prefix:      prefix,
+		credentials: credentials,
+		client:      netguard.Client(2 * time.Minute),
+		now:         time.Now,
+	}
+}
+
+// Name describes the bucket
+func (s *S3) Name() string {
+	return "s3://" + s.bucket + "/" + s.prefix
+}
+
+// objectURL returns the URL of an object: virtual-hosted on AWS, path-style
+// on a custom endpoint
+func (s *S3) objectURL(name string) string {
+	key := (&url.URL{Path: s.prefix + name}).EscapedPath()
+	if s.endpoint != "" {
+		return s.endpoint + "/" + s.bucket + "/" + key
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, key)
+}
+
+func (s *S3) do(ctx context.Context, method, name string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(name), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	sign(req, body, s.credentials, s.region, s.now())
+	return s.client.Do(req)
+}
+
+// Push uploads data as name
+func (s *S3) Push(ctx context.Context, name string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// Pull downloads name
+func (s *S3) Pull(ctx context.Context, name string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, s3Error(resp)
+	}
+}
+
+// s3Error describes a failed request with the code S3 sent, e.g. AccessDenied
+func s3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	code := ""
+	if start := bytes.Index(body, []byte("<Code>")); start >= 0 {
+		if end := bytes.Index(body[start:], []byte("</Code>")); end > 0 {
+			code = ": " + string(body[start+len("<Code>"):start+end])
+		}
+	}
+	return fmt.Errorf("%s%s", resp.Status, code)
+}
+
+// sign adds the AWS Signature Version 4 headers to req. The host and every
+// header already set are signed.
+func sign(req *http.Request, body []byte, credentials Credentials, region string, now time.Time) {
+	now = now.UTC()
+	date := now.Format("20060102")
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,79 @@
+package remotesync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// WebDAV stores snapshots as files in a WebDAV folder, such as one on
+// Nextcloud. The folder must exist.
+type WebDAV struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewWebDAV returns a backend for the folder at folderURL
+func NewWebDAV(folderURL, username, password string) *WebDAV {
+	return &WebDAV{
+		url:      strings.TrimSuffix(folderURL, "/") + "/",
+		username: username,
+		password: password,
+		client:   netguard.Client(2 * time.Minute),
+	}
+}
+
+// Name describes the folder
+func (w *WebDAV) Name() string {
+	return "WebDAV folder " + w.url
+}
+
+func (w *WebDAV) do(ctx context.Context, method, name string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, w.url+url.PathEscape(name), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if w.username != "" || w.password != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	return w.client.Do(req)
+}
+
+// Push uploads data as name
+func (w *WebDAV) Push(ctx context.Context, name string, data []byte) error {
+	resp, err := w.do(ctx, http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+	return nil
+}
+
+// Pull downloads name
+func (w *WebDAV) Pull(ctx context.Context, name string) ([]byte, error) {
+	resp, err := w.do(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+}
@@ -0,0 +1,266 @@
+// Package reputation asks threat intelligence services what they know about a
+// URL, domain or IP address, and turns their different answers into one
+// verdict. VirusTotal needs an API key; urlscan.io searches work without one.
+// Only earlier analyses are looked up: nothing is submitted for scanning, so
+// a checked URL isn't shared with the services' other users.
+package reputation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// RequestTimeout bounds a check by all backends
+const RequestTimeout = 20 * time.Second
+
+// ErrOffline is returned for every check in offline mode
+var ErrOffline = errors.New("offline mode makes no requests to reputation services")
+
+// Verdict is the normalized judgement of a backend, or of all of them
+type Verdict string
+
+// Verdicts, from least to most severe
+const (
+	Unknown    Verdict = "unknown"    // Never analyzed, or no backend answered
+	Harmless   Verdict = "harmless"   // Analyzed and nothing found
+	Suspicious Verdict = "suspicious" // Flagged by a single engine, or scored as doubtful
+	Malicious  Verdict = "malicious"
+)
+
+var severity = map[Verdict]int{Unknown: 0, Harmless: 1, Suspicious: 2, Malicious: 3}
+
+// AtLeast reports whether v is as severe as other
+func (v Verdict) AtLeast(other Verdict) bool {
+	return severity[v] >= severity[other]
+}
+
+// ParseVerdict reads a verdict name
+func ParseVerdict(s string) (Verdict, error) {
+	v := Verdict(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := severity[v]; !ok {
+		return "", fmt.Errorf("unknown verdict %q (use harmless, suspicious or malicious)", s)
+	}
+	return v, nil
+}
+
+// Kind is what a target is
+type Kind string
+
+// Target kinds
+const (
+	KindURL    Kind = "url"
+	KindDomain Kind = "domain"
+	KindIP     Kind = "ip"
+)
+
+// Target is a URL, domain or IP address to check
+type Target struct {
+	Value string `json:"value"`
+	Kind  Kind   `json:"kind"`
+}
+
+var validDomain = regexp.MustCompile(`^([a-z0-9_]([a-z0-9_-]*[a-z0-9])?\.)+[a-z]{2,}$`)
+
+// ParseTarget works out whether s is a URL, a domain or an IP address. A URL
+// needs its scheme, http or https.
+func ParseTarget(s string) (Target, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Target{}, fmt.Errorf("invalid URL %q: use an http or https URL", s)
+		}
+		return Target{Value: s, Kind: KindURL}, nil
+	}
+	if ip := net.ParseIP(s); ip != nil {
+		return Target{Value: ip.String(), Kind: KindIP}, nil
+	}
+	domain := strings.TrimSuffix(strings.ToLower(s), ".")
+	if !validDomain.MatchString(domain) {
+		return Target{}, fmt.Errorf("%q is not a URL, domain or IP address", s)
+	}
+	return Target{Value: domain, Kind: KindDomain}, nil
+}
+
+// Result is the answer of one backend
+type Result struct {
+	Backend    string   `json:"backend"`
+	Verdict    Verdict  `json:"verdict"`
+	Detections int      `json:"detections"`        // Engines flagging it as malicious or suspicious
+	Engines    int      `json:"engines,omitempty"` // Engines that gave an opinion
+	Categories []string `json:"categories,omitempty"`
+	Analyzed   string   `json:"analyzed,omitempty"` // When the service last analyzed it
+	Link       string   `json:"link,omitempty"`     // The report on the service's site
+	Error      string   `json:"error,omitempty"`
+}
+
+// Report is the combined answer of all backends
+type Report struct {
+	Target    Target    `json:"target"`
+	Verdict   Verdict   `json:"verdict"` // The most severe verdict of the backends
+	Results   []Result  `json:"results"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Backend is a reputation service
+type Backend interface {
+	Name() string
+	// Check returns what the service knows about target, with the verdict
+	// Unknown if it hasn't analyzed it
+	Check(ctx context.Context, target Target) (Result, error)
+}
+
+// Keys are the API keys of the services
+type Keys struct {
+	VirusTotal string
+	URLScan    string
+}
+
+// KeysFrom returns the API keys of cfg, overridden by HACKARE_VIRUSTOTAL_API_KEY
+// and HACKARE_URLSCAN_API_KEY
+func KeysFrom(cfg *config.Config) Keys {
+	var keys Keys
+	if cfg != nil {
+		keys = Keys{VirusTotal: cfg.VirusTotalAPIKey, URLScan: cfg.URLScanAPIKey}
+	}
+	if key := os.Getenv("HACKARE_VIRUSTOTAL_API_KEY"); key != "" {
+		keys.VirusTotal = key
+	}
+	if key := os.Getenv("HACKARE_URLSCAN_API_KEY"); key != "" {
+		keys.URLScan = key
+	}
+	return keys
+}
+
+// Checker asks every backend and combines their answers
+type Checker struct {
+	Backends []Backend
+	Offline  bool
+}
+
+// New returns a checker of the services keys allow: urlscan.io always, and
+// VirusTotal with a key
+func New(keys Keys, offline bool) *Checker {
+	client := netguard.Client(RequestTimeout)
+	backends := []Backend{}
+	if keys.VirusTotal != "" {
+		backends = append(backends, &VirusTotal{BaseURL: DefaultVirusTotalURL, APIKey: keys.VirusTotal, HTTPClient: client})
+	}
+	backends = append(backends, &URLScan{BaseURL: DefaultURLScanURL, APIKey: keys.URLScan, HTTPClient: client})
+	return &Checker{Backends: backends, Offline: offline}
+}
+
+var (
+	current   *Checker
+	currentMu sync.Mutex
+)
+
+// Init sets up the checker used by the url_reputation function, with the API
+// keys of the saved configuration and the environment
+func Init(offline bool) {
+	cfg, _ := config.LoadFromFile(config.GetConfigPath())
+	SetDefault(New(KeysFrom(cfg), offline))
+}
+
+// SetDefault replaces the checker returned by Default
+func SetDefault(c *Checker) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = c
+}
+
+// Default returns the checker set up by Init, or an online checker with the
+// keys of the environment if Init hasn't been called
+func Default() *Checker {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if current == nil {
+		current = New(KeysFrom(nil), false)
+	}
+	return current
+}
+
+// Select keeps only the backends named in names, such as "virustotal"
+func (c *Checker) Select(names []string) (*Checker, error) {
+	selected := &Checker{Offline: c.Offline}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, b := range c.Backends {
+			if b.Name() == name {
+				selected.Backends = append(selected.Backends, b)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("backend %q is not available (have: %s)", name, strings.Join(c.Names(), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// Names returns the names of the backends
+func (c *Checker) Names() []string {
+	var names []string
+	for _, b := range c.Backends {
+		names = append(names, b.Name())
+	}
+	return names
+}
+
+// Check asks all backends about target at once. A backend that fails is
+// listed with its error; the check fails only if all of them do.
+func (c *Checker) Check(ctx context.Context, target string) (*Report, error) {
+	t, err := ParseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if c.Offline {
+		return nil, ErrOffline
+	}
+	if len(c.Backends) == 0 {
+		return nil, errors.New("no reputation backends are configured")
+	}
+
+	report := &Report{Target: t, Verdict: Unknown, Results: make([]Result, len(c.Backends)), CheckedAt: time.Now()}
+	var wg sync.WaitGroup
+	for i, b := range c.Backends {
+		wg.Add(1)
+		go func(i int, b Backend) {
+			defer wg.Done()
+			result, err := b.Check(ctx, t)
+			if err != nil {
+				result = Result{Verdict: Unknown, Error: err.Error()}
+			}
+			result.Backend = b.Name()
+			report.Results[i] = result
+		}(i, b)
+	}
+	wg.Wait()
+
+	var failures []string
+	for _, result := range report.Results {
+		if result.Error != "" {
+			failures = append(failures, result.Backend+": "+result.Error)
+			continue
+		}
+		if !report.Verdict.AtLeast(result.Verdict) {
+			report.Verdict = result.Verdict
+		}
+	}
+	if len(failures) == len(report.Results) {
+		return nil, fmt.Errorf("every reputation backend failed: %s", strings.Join(failures, "; "))
+	}
+	return report, nil
+}
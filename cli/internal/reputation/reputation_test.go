@@ -0,0 +1,171 @@
+package reputation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Target
+	}{
+		{"https://evil.example/login?x=1", Target{"https://evil.example/login?x=1", KindURL}},
+		{" Example.COM. ", Target{"example.com", KindDomain}},
+		{"192.0.2.7", Target{"192.0.2.7", KindIP}},
+		{"2001:db8::1", Target{"2001:db8::1", KindIP}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTarget(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseTarget(%q) = %+v, %v; want %+v", tt.input, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "ftp://example.com", "https://", "not a domain", "localhost"} {
+		if _, err := ParseTarget(bad); err == nil {
+			t.Errorf("ParseTarget(%q) was accepted", bad)
+		}
+	}
+}
+
+// virusTotalServer answers like the VirusTotal API for three domains
+func virusTotalServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-apikey") != "vt-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/domains/evil.example":
+			w.Write([]byte(`{"data": {"id": "evil.example", "attributes": {"last_analysis_date": 1700000000,
+				"last_analysis_stats": {"malicious": 9, "suspicious": 1, "harmless": 60, "undetected": 20, "timeout": 0},
+				"categories": {"Forcepoint ThreatSeeker": "phishing", "Sophos": "phishing", "BitDefender": "malware"}}}}`))
+		case "/domains/odd.example":
+			w.Write([]byte(`{"data": {"attributes": {"last_analysis_stats": {"malicious": 1, "harmless": 70}}}}`))
+		case "/urls/aHR0cHM6Ly9leGFtcGxlLmNvbS8":
+			w.Write([]byte(`{"data": {"id": "0f115db062b7c0dd030b16878c99dea5c354b49dc37b38eb8846179c7783e9d7", "attributes": {"last_analysis_stats": {"harmless": 70, "undetected": 10}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// urlscanServer answers like urlscan.io: one scan, of evil.example
+func urlscanServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/search/" && r.URL.Query().Get("q") == "page.domain:evil.example":
+			w.Write([]byte(`{"results": [{"_id": "abc-123", "task": {"time": "2024-05-01T10:00:00.000Z"}}], "total": 1}`))
+		case r.URL.Path == "/api/v1/search/":
+			w.Write([]byte(`{"results": [], "total": 0}`))
+		case r.URL.Path == "/api/v1/result/abc-123/":
+			w.Write([]byte(`{"verdicts": {"overall": {"score": 100, "malicious": true, "categories": ["phishing"]},
+				"engines": {"maliciousTotal": 2, "enginesTotal": 5}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testChecker(t *testing.T, vtKey string) *Checker {
+	client := http.DefaultClient
+	return &Checker{Backends: []Backend{
+		&VirusTotal{BaseURL: virusTotalServer(t).URL, APIKey: vtKey, HTTPClient: client},
+		&URLScan{BaseURL: urlscanServer(t).URL, HTTPClient: client},
+	}}
+}
+
+func TestCheck(t *testing.T) {
+	c := testChecker(t, "vt-key")
+
+	report, err := c.Check(context.Background(), "evil.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Verdict != Malicious || len(report.Results) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	vt, us := report.Results[0], report.Results[1]
+	if vt.Backend != "virustotal" || vt.Detections != 10 || vt.Engines != 90 || strings.Join(vt.Categories, ",") != "malware,phishing" {
+		t.Errorf("VirusTotal result = %+v", vt)
+	}
+	if vt.Analyzed != "2023-11-14T22:13:20Z" || !strings.HasSuffix(vt.Link, "/domain/evil.example") {
+		t.Errorf("VirusTotal analyzed %q, link %q", vt.Analyzed, vt.Link)
+	}
+	if us.Backend != "urlscan" || us.Verdict != Malicious || us.Detections != 2 || !strings.HasSuffix(us.Link, "/result/abc-123/") {
+		t.Errorf("urlscan result = %+v", us)
+	}
+
+	tests := map[string]Verdict{
+		"odd.example":          Suspicious, // A single detection
+		"https://example.com/": Harmless,
+		"unseen.example":       Unknown,
+	}
+	for target, want := range tests {
+		report, err := c.Check(context.Background(), target)
+		if err != nil || report.Verdict != want {
+			t.Errorf("Check(%s) = %+v, %v; want %s", target, report, err, want)
+		}
+	}
+	if report, _ := c.Check(context.Background(), "https://example.com/"); !strings.HasSuffix(report.Results[0].Link, "/url/0f115db062b7c0dd030b16878c99dea5c354b49dc37b38eb8846179c7783e9d7") {
+		t.Errorf("VirusTotal URL link = %s", report.Results[0].Link)
+	}
+}
+
+func TestCheckFailures(t *testing.T) {
+	// One backend failing leaves the verdict to the others
+	c := testChecker(t, "wrong-key")
+	report, err := c.Check(context.Background(), "evil.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Verdict != Malicious || !strings.Contains(report.Results[0].Error, "API key") {
+		t.Errorf("report = %+v", report)
+	}
+
+	// All failing is an error
+	only, err := c.Select([]string{"virustotal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := only.Check(context.Background(), "evil.example"); err == nil || !strings.Contains(err.Error(), "virustotal: ") {
+		t.Errorf("err = %v, want the VirusTotal error", err)
+	}
+	if _, err := c.Select([]string{"shodan"}); err == nil {
+		t.Error("selected a backend that doesn't exist")
+	}
+
+	c.Offline = true
+	if _, err := c.Check(context.Background(), "evil.example"); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline: err = %v, want ErrOffline", err)
+	}
+}
+
+func TestNewAndVerdicts(t *testing.T) {
+	if names := New(Keys{}, false).Names(); strings.Join(names, ",") != "urlscan" {
+		t.Errorf("backends without keys = %v", names)
+	}
+	if names := New(Keys{VirusTotal: "k"}, false).Names(); strings.Join(names, ",") != "virustotal,urlscan" {
+		t.Errorf("backends with a VirusTotal key = %v", names)
+	}
+
+	t.Setenv("HACKARE_URLSCAN_API_KEY", "from-env")
+	if keys := KeysFrom(nil); keys.URLScan != "from-env" || keys.VirusTotal != "" {
+		t.Errorf("KeysFrom = %+v", keys)
+	}
+
+	if v, err := ParseVerdict(" Suspicious"); err != nil || v != Suspicious {
+		t.Errorf("ParseVerdict = %v, %v", v, err)
+	}
+	if !Malicious.AtLeast(Suspicious) || Harmless.AtLeast(Suspicious) {
+		t.Error("verdicts are ordered wrongly")
+	}
+}
@@ -0,0 +1,125 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultURLScanURL is urlscan.io
+const DefaultURLScanURL = "https://urlscan.io"
+
+// URLScan looks up the latest public urlscan.io scan of a target
+type URLScan struct {
+	BaseURL    string
+	APIKey     string // Optional; raises the search rate limit
+	HTTPClient *http.Client
+}
+
+// Name implements Backend
+func (u *URLScan) Name() string { return "urlscan" }
+
+// Check implements Backend. It searches for the most recent scan and then
+// reads that scan's verdicts.
+func (u *URLScan) Check(ctx context.Context, target Target) (Result, error) {
+	var query string
+	switch target.Kind {
+	case KindURL:
+		query = "page.url:" + quoteQuery(target.Value)
+	case KindDomain:
+		query = "page.domain:" + target.Value
+	case KindIP:
+		query = "page.ip:" + quoteQuery(target.Value)
+	}
+
+	var search struct {
+		Results []struct {
+			ID   string `json:"_id"`
+			Task struct {
+				Time string `json:"time"`
+			} `json:"task"`
+		} `json:"results"`
+	}
+	params := url.Values{"q": {query}, "size": {"1"}}
+	found, err := u.get(ctx, "/api/v1/search/?"+params.Encode(), &search)
+	if err != nil {
+		return Result{}, err
+	}
+	if !found || len(search.Results) == 0 {
+		return Result{Verdict: Unknown}, nil
+	}
+	scan := search.Results[0]
+	result := Result{Verdict: Unknown, Analyzed: scan.Task.Time, Link: u.BaseURL + "/result/" + scan.ID + "/"}
+
+	var details struct {
+		Verdicts struct {
+			Overall struct {
+				Score      int      `json:"score"` // -100 (legitimate) to 100 (malicious)
+				Malicious  bool     `json:"malicious"`
+				Categories []string `json:"categories"`
+			} `json:"overall"`
+			Engines struct {
+				MaliciousTotal int `json:"maliciousTotal"`
+				EnginesTotal   int `json:"enginesTotal"`
+			} `json:"engines"`
+		} `json:"verdicts"`
+	}
+	found, err = u.get(ctx, "/api/v1/result/"+url.PathEscape(scan.ID)+"/", &details)
+	if err != nil || !found {
+		return result, err // A scan can be deleted after it was found
+	}
+
+	verdicts := details.Verdicts
+	result.Detections = verdicts.Engines.MaliciousTotal
+	result.Engines = verdicts.Engines.EnginesTotal
+	result.Categories = verdicts.Overall.Categories
+	switch {
+	case verdicts.Overall.Malicious:
+		result.Verdict = Malicious
+	case verdicts.Overall.Score > 0 || verdicts.Engines.MaliciousTotal > 0:
+		result.Verdict = Suspicious
+	default:
+		result.Verdict = Harmless
+	}
+	return result, nil
+}
+
+// get decodes the JSON at path into v, and reports false if it doesn't exist
+func (u *URLScan) get(ctx context.Context, path string, v interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.BaseURL+path, nil)
+	if err != nil {
+		return false, err
+	}
+	if u.APIKey != "" {
+		req.Header.Set("API-Key", u.APIKey)
+	}
+	resp, err := u.HTTPClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("urlscan.io request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	case http.StatusUnauthorized:
+		return false, fmt.Errorf("urlscan.io rejected the API key")
+	case http.StatusTooManyRequests:
+		return false, fmt.Errorf("urlscan.io rate limit reached; an API key raises it")
+	default:
+		return false, fmt.Errorf("urlscan.io request failed: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("failed to parse urlscan.io response: %w", err)
+	}
+	return true, nil
+}
+
+// quoteQuery quotes s as a phrase of the search query language
+func quoteQuery(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
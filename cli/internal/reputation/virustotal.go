@@ -0,0 +1,124 @@
+package reputation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// DefaultVirusTotalURL is the VirusTotal API
+const DefaultVirusTotalURL = "https://www.virustotal.com/api/v3"
+
+// virusTotalGUI is where reports are linked to
+const virusTotalGUI = "https://www.virustotal.com/gui"
+
+// VirusTotal looks up the latest VirusTotal analysis of a target
+type VirusTotal struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// Name implements Backend
+func (v *VirusTotal) Name() string { return "virustotal" }
+
+// Check implements Backend
+func (v *VirusTotal) Check(ctx context.Context, target Target) (Result, error) {
+	var path, gui string
+	switch target.Kind {
+	case KindURL:
+		// URLs are identified by their unpadded base64url encoding
+		path = "/urls/" + base64.RawURLEncoding.EncodeToString([]byte(target.Value))
+		gui = virusTotalGUI + "/search/" + url.PathEscape(target.Value)
+	case KindDomain:
+		path = "/domains/" + target.Value
+		gui = virusTotalGUI + "/domain/" + target.Value
+	case KindIP:
+		path = "/ip_addresses/" + target.Value
+		gui = virusTotalGUI + "/ip-address/" + target.Value
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.BaseURL+path, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("x-apikey", v.APIKey)
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("VirusTotal request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return Result{Verdict: Unknown, Link: gui}, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return Result{}, fmt.Errorf("VirusTotal rejected the API key")
+	case http.StatusTooManyRequests:
+		return Result{}, fmt.Errorf("VirusTotal API quota exceeded; the free tier allows 4 lookups a minute")
+	default:
+		return Result{}, fmt.Errorf("VirusTotal request failed: %s", resp.Status)
+	}
+
+	var body struct {
+		Data struct {
+			ID         string `json:"id"`
+			Attributes struct {
+				LastAnalysisDate  int64             `json:"last_analysis_date"`
+				LastAnalysisStats map[string]int    `json:"last_analysis_stats"`
+				Categories        map[string]string `json:"categories"` // Category per vendor
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Result{}, fmt.Errorf("failed to parse VirusTotal response: %w", err)
+	}
+
+	attributes := body.Data.Attributes
+	stats := attributes.LastAnalysisStats
+	result := Result{
+		Detections: stats["malicious"] + stats["suspicious"],
+		Engines:    stats["malicious"] + stats["suspicious"] + stats["harmless"] + stats["undetected"],
+		Link:       gui,
+	}
+	if target.Kind == KindURL && body.Data.ID != "" {
+		result.Link = virusTotalGUI + "/url/" + body.Data.ID
+	}
+	if attributes.LastAnalysisDate > 0 {
+		result.Analyzed = time.Unix(attributes.LastAnalysisDate, 0).UTC().Format(time.RFC3339)
+	}
+	result.Categories = uniqueValues(attributes.Categories)
+
+	// A single detection is often a false positive
+	switch {
+	case stats["malicious"] >= 2:
+		result.Verdict = Malicious
+	case stats["malicious"] == 1 || stats["suspicious"] > 0:
+		result.Verdict = Suspicious
+	case result.Engines > 0:
+		result.Verdict = Harmless
+	default:
+		result.Verdict = Unknown
+	}
+	return result, nil
+}
+
+// uniqueValues returns the distinct values of m, sorted
+func uniqueValues(m map[string]string) []string {
+	seen := map[string]bool{}
+	var values []string
+	for _, value := range m {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
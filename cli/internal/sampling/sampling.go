@@ -0,0 +1,56 @@
+// Package sampling holds the sampling parameters a prompt template or an
+// agent sets for its own requests. They are merged over the configured ones
+// when each request is built, so the saved configuration stays as it is.
+package sampling
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Override replaces the configured temperature and top_p; nil keeps them
+type Override struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+}
+
+// Float returns a pointer to v, for writing overrides
+func Float(v float64) *float64 {
+	return &v
+}
+
+// IsZero reports whether the override changes nothing
+func (o Override) IsZero() bool {
+	return o.Temperature == nil && o.TopP == nil
+}
+
+// TemperatureOr returns the override's temperature, or configured when it has none
+func (o Override) TemperatureOr(configured float64) float64 {
+	if o.Temperature != nil {
+		return *o.Temperature
+	}
+	return configured
+}
+
+// Validate checks that the values are in the range providers accept
+func (o Override) Validate() error {
+	if o.Temperature != nil && (*o.Temperature < 0 || *o.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", *o.Temperature)
+	}
+	if o.TopP != nil && (*o.TopP <= 0 || *o.TopP > 1) {
+		return fmt.Errorf("top_p must be above 0 and at most 1, got %g", *o.TopP)
+	}
+	return nil
+}
+
+// String describes the override, e.g. "temperature 0.2, top_p 0.9"
+func (o Override) String() string {
+	var parts []string
+	if o.Temperature != nil {
+		parts = append(parts, fmt.Sprintf("temperature %g", *o.Temperature))
+	}
+	if o.TopP != nil {
+		parts = append(parts, fmt.Sprintf("top_p %g", *o.TopP))
+	}
+	return strings.Join(parts, ", ")
+}
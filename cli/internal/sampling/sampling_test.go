@@ -0,0 +1,59 @@
+package sampling
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOverride(t *testing.T) {
+	var none Override
+	if !none.IsZero() || none.String() != "" || none.TemperatureOr(0.7) != 0.7 {
+		t.Errorf("empty override should change nothing: %+v", none)
+	}
+
+	o := Override{Temperature: Float(0.2), TopP: Float(0.9)}
+	if o.IsZero() {
+		t.Error("override with values reported as zero")
+	}
+	if got := o.TemperatureOr(0.7); got != 0.2 {
+		t.Errorf("TemperatureOr() = %g, want 0.2", got)
+	}
+	if got := o.String(); got != "temperature 0.2, top_p 0.9" {
+		t.Errorf("String() = %q", got)
+	}
+	if err := o.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	// Zero is a temperature of its own, not "unset"
+	if got := (Override{Temperature: Float(0)}).TemperatureOr(0.7); got != 0 {
+		t.Errorf("TemperatureOr() with 0 = %g, want 0", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, o := range []Override{
+		{Temperature: Float(-0.1)},
+		{Temperature: Float(2.5)},
+		{TopP: Float(0)},
+		{TopP: Float(1.5)},
+	} {
+		if err := o.Validate(); err == nil {
+			t.Errorf("Validate(%s) accepted an out of range value", o)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	// Overrides are embedded in agent definitions, so the keys sit beside the others
+	var agent struct {
+		Name string `json:"name"`
+		Override
+	}
+	if err := json.Unmarshal([]byte(`{"name":"writer","temperature":0.2}`), &agent); err != nil {
+		t.Fatal(err)
+	}
+	if agent.Temperature == nil || *agent.Temperature != 0.2 || agent.TopP != nil {
+		t.Errorf("decoded %+v", agent.Override)
+	}
+}
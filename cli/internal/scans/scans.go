@@ -0,0 +1,502 @@
+// Package scans reads Nmap XML and masscan JSON output into one structure and
+// keeps it under Dir(), so the scan-data functions can answer questions about
+// it in a chat: which hosts run a service, what one host exposes, and so on.
+package scans
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scan is the result of one scan
+type Scan struct {
+	Name     string    `json:"name"`
+	Source   string    `json:"source"`            // "nmap" or "masscan"
+	File     string    `json:"file,omitempty"`    // The file it was ingested from
+	Command  string    `json:"command,omitempty"` // The nmap command line
+	Started  time.Time `json:"started,omitempty"`
+	Ingested time.Time `json:"ingested"`
+	Hosts    []Host    `json:"hosts"`
+}
+
+// Host is a scanned address and the ports found on it
+type Host struct {
+	Address   string   `json:"address"`
+	Hostnames []string `json:"hostnames,omitempty"`
+	Status    string   `json:"status,omitempty"` // up or down, when nmap reports it
+	OS        string   `json:"os,omitempty"`     // Best nmap OS match
+	Ports     []Port   `json:"ports,omitempty"`
+}
+
+// Port is a port of a host
+type Port struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	State    string `json:"state"`
+	Service  string `json:"service,omitempty"`
+	Product  string `json:"product,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Banner   string `json:"banner,omitempty"` // Script output or masscan banner
+}
+
+// Formats lists the formats Parse reads
+var Formats = []string{"nmap", "masscan"}
+
+// Parse reads scan output in format, "nmap" (XML, -oX) or "masscan" (JSON, -oJ)
+func Parse(format string, data []byte) (*Scan, error) {
+	switch format {
+	case "nmap":
+		return ParseNmap(data)
+	case "masscan":
+		return ParseMasscan(data)
+	}
+	return nil, fmt.Errorf("unknown scan format %q (use %s)", format, strings.Join(Formats, " or "))
+}
+
+// nmapRun is the part of Nmap's XML output that is kept
+type nmapRun struct {
+	Args  string `xml:"args,attr"`
+	Start int64  `xml:"start,attr"`
+	Hosts []struct {
+		Status struct {
+			State string `xml:"state,attr"`
+		} `xml:"status"`
+		Addresses []struct {
+			Addr string `xml:"addr,attr"`
+			Type string `xml:"addrtype,attr"`
+		} `xml:"address"`
+		Hostnames []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"hostnames>hostname"`
+		Ports []struct {
+			Protocol string `xml:"protocol,attr"`
+			PortID   int    `xml:"portid,attr"`
+			State    struct {
+				State string `xml:"state,attr"`
+			} `xml:"state"`
+			Service struct {
+				Name      string `xml:"name,attr"`
+				Product   string `xml:"product,attr"`
+				Version   string `xml:"version,attr"`
+				ExtraInfo string `xml:"extrainfo,attr"`
+				Tunnel    string `xml:"tunnel,attr"`
+			} `xml:"service"`
+			Scripts []struct {
+				ID     string `xml:"id,attr"`
+				Output string `xml:"output,attr"`
+			} `xml:"script"`
+		} `xml:"ports>port"`
+		OSMatches []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"os>osmatch"`
+	} `xml:"host"`
+}
+
+// ParseNmap reads Nmap XML output
+func ParseNmap(data []byte) (*Scan, error) {
+	var run nmapRun
+	if err := xml.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("not Nmap XML output: %w", err)
+	}
+
+	scan := &Scan{Source: "nmap", Command: run.Args}
+	if run.Start > 0 {
+		scan.Started = time.Unix(run.Start, 0).UTC()
+	}
+	for _, h := range run.Hosts {
+		host := Host{Status: h.Status.State}
+		for _, addr := range h.Addresses {
+			// The IP address names the host; a MAC address is only kept if there is none
+			if addr.Type != "mac" || host.Address == "" {
+				host.Address = addr.Addr
+			}
+		}
+		for _, name := range h.Hostnames {
+			host.Hostnames = appendUnique(host.Hostnames, name.Name)
+		}
+		if len(h.OSMatches) > 0 {
+			host.OS = h.OSMatches[0].Name
+		}
+		for _, p := range h.Ports {
+			port := Port{
+				Port:     p.PortID,
+				Protocol: p.Protocol,
+				State:    p.State.State,
+				Service:  p.Service.Name,
+				Product:  p.Service.Product,
+				Version:  strings.TrimSpace(p.Service.Version + " " + p.Service.ExtraInfo),
+			}
+			if p.Service.Tunnel != "" && port.Service != "" {
+				port.Service = p.Service.Tunnel + "/" + port.Service // e.g. ssl/http
+			}
+			var scripts []string
+			for _, script := range p.Scripts {
+				scripts = append(scripts, script.ID+": "+strings.TrimSpace(script.Output))
+			}
+			port.Banner = strings.Join(scripts, "\n")
+			host.Ports = append(host.Ports, port)
+		}
+		if host.Address != "" {
+			scan.Hosts = append(scan.Hosts, host)
+		}
+	}
+	if len(scan.Hosts) == 0 && !bytes.Contains(data, []byte("<nmaprun")) {
+		return nil, errors.New("not Nmap XML output: no <nmaprun> element")
+	}
+	return scan, nil
+}
+
+// masscanRecord is one line of masscan's JSON output
+type masscanRecord struct {
+	IP        string `json:"ip"`
+	Timestamp string `json:"timestamp"`
+	Ports     []struct {
+		Port    int    `json:"port"`
+		Proto   string `json:"proto"`
+		Status  string `json:"status"`
+		Service struct {
+			Name   string `json:"name"`
+			Banner string `json:"banner"`
+		} `json:"service"`
+	} `json:"ports"`
+}
+
+// trailingComma matches the comma older masscan versions leave before the
+// closing bracket
+var trailingComma = regexp.MustCompile(`,\s*\]\s*$`)
+
+// ParseMasscan reads masscan JSON output (-oJ), or one record per line (-oD).
+// masscan writes a record per port found, so records of one address are merged.
+func ParseMasscan(data []byte) (*Scan, error) {
+	data = bytes.TrimSpace(data)
+	var records []masscanRecord
+	if bytes.HasPrefix(data, []byte("[")) {
+		data = trailingComma.ReplaceAll(data, []byte("]"))
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("not masscan JSON output: %w", err)
+		}
+	} else {
+		for i, line := range bytes.Split(data, []byte("\n")) {
+			line = bytes.TrimSuffix(bytes.TrimSpace(line), []byte(","))
+			if len(line) == 0 {
+				continue
+			}
+			var record masscanRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				return nil, fmt.Errorf("not masscan JSON output: line %d: %w", i+1, err)
+			}
+			records = append(records, record)
+		}
+	}
+
+	scan := &Scan{Source: "masscan"}
+	index := map[string]int{}
+	for _, record := range records {
+		if record.IP == "" {
+			continue // Such as the {"finished": 1} record
+		}
+		if seconds, err := strconv.ParseInt(record.Timestamp, 10, 64); err == nil {
+			if t := time.Unix(seconds, 0).UTC(); scan.Started.IsZero() || t.Before(scan.Started) {
+				scan.Started = t
+			}
+		}
+		i, ok := index[record.IP]
+		if !ok {
+			i = len(scan.Hosts)
+			index[record.IP] = i
+			scan.Hosts = append(scan.Hosts, Host{Address: record.IP, Status: "up"})
+		}
+		host := &scan.Hosts[i]
+		for _, p := range record.Ports {
+			port := Port{Port: p.Port, Protocol: p.Proto, State: p.Status, Service: p.Service.Name, Banner: p.Service.Banner}
+			if port.State == "" {
+				port.State = "open" // Banner records leave the status out
+			}
+			host.mergePort(port)
+		}
+	}
+	return scan, nil
+}
+
+// mergePort adds port to the host, filling in what an earlier record of the
+// same port left out
+func (h *Host) mergePort(port Port) {
+	for i := range h.Ports {
+		p := &h.Ports[i]
+		if p.Port != port.Port || p.Protocol != port.Protocol {
+			continue
+		}
+		if p.Service == "" {
+			p.Service = port.Service
+		}
+		if port.Banner != "" {
+			p.Banner = strings.TrimSpace(p.Banner + "\n" + port.Banner)
+		}
+		return
+	}
+	h.Ports = append(h.Ports, port)
+}
+
+func appendUnique(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// Summary describes a scan in a few numbers
+type Summary struct {
+	Name      string      `json:"name"`
+	Source    string      `json:"source"`
+	File      string      `json:"file,omitempty"`
+	Started   time.Time   `json:"started,omitempty"`
+	Hosts     int         `json:"hosts"`
+	HostsUp   int         `json:"hostsUp"`
+	OpenPorts int         `json:"openPorts"`
+	TopPorts  []PortCount `json:"topPorts,omitempty"`
+}
+
+// PortCount is how many hosts have a port open
+type PortCount struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	Service  string `json:"service,omitempty"`
+	Hosts    int    `json:"hosts"`
+}
+
+// topPorts is how many of the most common open ports Summary lists
+const topPorts = 10
+
+// Summary counts the hosts and open ports of the scan
+func (s *Scan) Summary() Summary {
+	summary := Summary{Name: s.Name, Source: s.Source, File: s.File, Started: s.Started, Hosts: len(s.Hosts)}
+	counts := map[string]*PortCount{}
+	for _, host := range s.Hosts {
+		if host.Status != "down" {
+			summary.HostsUp++
+		}
+		for _, port := range host.Ports {
+			if port.State != "open" {
+				continue
+			}
+			summary.OpenPorts++
+			key := fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+			if counts[key] == nil {
+				counts[key] = &PortCount{Port: port.Port, Protocol: port.Protocol}
+			}
+			counts[key].Hosts++
+			if counts[key].Service == "" {
+				counts[key].Service = port.Service
+			}
+		}
+	}
+	for _, count := range counts {
+		summary.TopPorts = append(summary.TopPorts, *count)
+	}
+	sort.Slice(summary.TopPorts, func(i, j int) bool {
+		a, b := summary.TopPorts[i], summary.TopPorts[j]
+		if a.Hosts != b.Hosts {
+			return a.Hosts > b.Hosts
+		}
+		return a.Port < b.Port
+	})
+	if len(summary.TopPorts) > topPorts {
+		summary.TopPorts = summary.TopPorts[:topPorts]
+	}
+	return summary
+}
+
+// Query selects open ports. Empty fields match anything; Service and Product
+// match case-insensitively on part of the name, and Network is a CIDR block.
+type Query struct {
+	Port    int
+	Service string
+	Product string
+	Network string
+}
+
+// Find returns the hosts with open ports matching q, each with just those ports
+func (s *Scan) Find(q Query) ([]Host, error) {
+	var network *net.IPNet
+	if q.Network != "" {
+		var err error
+		if _, network, err = net.ParseCIDR(q.Network); err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", q.Network, err)
+		}
+	}
+
+	var found []Host
+	for _, host := range s.Hosts {
+		if network != nil {
+			if ip := net.ParseIP(host.Address); ip == nil || !network.Contains(ip) {
+				continue
+			}
+		}
+		match := host
+		match.Ports = nil
+		for _, port := range host.Ports {
+			if port.State == "open" && q.matches(port) {
+				match.Ports = append(match.Ports, port)
+			}
+		}
+		if len(match.Ports) > 0 {
+			found = append(found, match)
+		}
+	}
+	return found, nil
+}
+
+func (q Query) matches(port Port) bool {
+	contains := func(s, part string) bool {
+		return part == "" || strings.Contains(strings.ToLower(s), strings.ToLower(part))
+	}
+	return (q.Port == 0 || port.Port == q.Port) && contains(port.Service, q.Service) && contains(port.Product, q.Product)
+}
+
+// Host returns the host with the address or hostname
+func (s *Scan) Host(name string) (Host, bool) {
+	for _, host := range s.Hosts {
+		if strings.EqualFold(host.Address, name) {
+			return host, true
+		}
+		for _, hostname := range host.Hostnames {
+			if strings.EqualFold(hostname, name) {
+				return host, true
+			}
+		}
+	}
+	return Host{}, false
+}
+
+// Dir returns the directory ingested scans are kept in. HACKARE_SCANS_DIR
+// overrides it.
+func Dir() string {
+	if dir := os.Getenv("HACKARE_SCANS_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hacka.re-scans")
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "scans")
+}
+
+// validName is what a scan name may look like
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._\-]*$`)
+
+// invalidNameChars are replaced by NameFor
+var invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._\-]+`)
+
+// NameFor returns a scan name made from the file it was read from
+func NameFor(file string) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "-"), ".-_")
+	if name == "" {
+		return "scan"
+	}
+	return name
+}
+
+// Save keeps the scan under its name in dir, replacing a scan of that name
+func Save(dir string, scan *Scan) error {
+	if !validName.MatchString(scan.Name) {
+		return fmt.Errorf("invalid scan name %q: use letters, digits, '.', '-' and '_'", scan.Name)
+	}
+	data, err := json.MarshalIndent(scan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode scan: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create scans directory: %w", err)
+	}
+	path := filepath.Join(dir, scan.Name+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write scan: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load returns the scan called name from dir, or the latest ingested one if
+// name is empty
+func Load(dir, name string) (*Scan, error) {
+	if name == "" {
+		all, err := List(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(all) == 0 {
+			return nil, errors.New("no scans have been ingested; use hacka.re ingest")
+		}
+		name = all[0].Name
+	}
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid scan name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no scan called %q", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan: %w", err)
+	}
+	var scan Scan
+	if err := json.Unmarshal(data, &scan); err != nil {
+		return nil, fmt.Errorf("failed to parse scan %s: %w", name, err)
+	}
+	return &scan, nil
+}
+
+// List returns the summaries of the scans in dir, latest ingested first
+func List(dir string) ([]Summary, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	type entry struct {
+		summary  Summary
+		ingested time.Time
+	}
+	var entries []entry
+	for _, path := range paths {
+		scan, err := Load(dir, strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{scan.Summary(), scan.Ingested})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].ingested.After(entries[j].ingested) })
+	summaries := make([]Summary, len(entries))
+	for i, e := range entries {
+		summaries[i] = e.summary
+	}
+	return summaries, nil
+}
+
+// Remove deletes the scan called name from dir
+func Remove(dir, name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid scan name %q", name)
+	}
+	err := os.Remove(filepath.Join(dir, name+".json"))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no scan called %q", name)
+	}
+	return err
+}
@@ -0,0 +1,201 @@
+package scans
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const nmapXML = `<?xml version="1.0" encoding="UTF-8"?>
+<nmaprun scanner="nmap" args="nmap -sV -O -oX scan.xml 10.0.0.0/24" start="1700000000" version="7.94">
+<host><status state="up" reason="arp-response"/>
+<address addr="10.0.0.5" addrtype="ipv4"/>
+<address addr="00:11:22:33:44:55" addrtype="mac" vendor="Acme"/>
+<hostnames><hostname name="web.lab" type="PTR"/></hostnames>
+<ports>
+<port protocol="tcp" portid="22"><state state="open"/><service name="ssh" product="OpenSSH" version="8.9p1" extrainfo="Ubuntu"/></port>
+<port protocol="tcp" portid="443"><state state="open"/><service name="http" product="nginx" version="1.18.0" tunnel="ssl"/>
+<script id="http-title" output="Login"/></port>
+<port protocol="tcp" portid="8080"><state state="filtered"/><service name="http-proxy"/></port>
+</ports>
+<os><osmatch name="Linux 5.0 - 5.14" accuracy="98"/></os>
+</host>
+<host><status state="down"/><address addr="10.0.0.6" addrtype="ipv4"/></host>
+<host><status state="up"/><address addr="10.0.1.9" addrtype="ipv4"/>
+<ports><port protocol="tcp" portid="22"><state state="open"/><service name="ssh" product="Dropbear sshd"/></port></ports>
+</host>
+</nmaprun>`
+
+// masscanJSON is -oJ output of an older masscan, with its trailing comma
+const masscanJSON = `[
+{   "ip": "192.168.1.10",   "timestamp": "1700000100", "ports": [ {"port": 80, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 64} ] },
+{   "ip": "192.168.1.10",   "timestamp": "1700000101", "ports": [ {"port": 80, "proto": "tcp", "service": {"name": "http", "banner": "HTTP/1.1 200 OK"} } ] },
+{   "ip": "192.168.1.11",   "timestamp": "1700000050", "ports": [ {"port": 443, "proto": "tcp", "status": "open"} ] },
+{"finished": 1},
+]`
+
+func TestParseNmap(t *testing.T) {
+	scan, err := Parse("nmap", []byte(nmapXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scan.Command != "nmap -sV -O -oX scan.xml 10.0.0.0/24" || !scan.Started.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("command %q, started %v", scan.Command, scan.Started)
+	}
+	if len(scan.Hosts) != 3 {
+		t.Fatalf("got %d hosts, want 3", len(scan.Hosts))
+	}
+
+	web := scan.Hosts[0]
+	if web.Address != "10.0.0.5" || web.OS != "Linux 5.0 - 5.14" || len(web.Hostnames) != 1 || web.Hostnames[0] != "web.lab" {
+		t.Errorf("host = %+v", web)
+	}
+	ssh, https := web.Ports[0], web.Ports[1]
+	if ssh.Product != "OpenSSH" || ssh.Version != "8.9p1 Ubuntu" {
+		t.Errorf("ssh port = %+v", ssh)
+	}
+	if https.Service != "ssl/http" || https.Banner != "http-title: Login" {
+		t.Errorf("https port = %+v", https)
+	}
+
+	if _, err := ParseNmap([]byte(`{"ip": "1.2.3.4"}`)); err == nil {
+		t.Error("JSON was read as Nmap XML")
+	}
+}
+
+func TestParseMasscan(t *testing.T) {
+	scan, err := Parse("masscan", []byte(masscanJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scan.Hosts) != 2 || !scan.Started.Equal(time.Unix(1700000050, 0)) {
+		t.Fatalf("hosts = %+v, started %v", scan.Hosts, scan.Started)
+	}
+	ports := scan.Hosts[0].Ports
+	if len(ports) != 1 || ports[0].Service != "http" || ports[0].Banner != "HTTP/1.1 200 OK" || ports[0].State != "open" {
+		t.Errorf("merged ports = %+v", ports)
+	}
+
+	// One record per line, as written by -oD
+	lines := `{"ip": "10.1.1.1", "timestamp": "1", "ports": [{"port": 22, "proto": "tcp", "status": "open"}]}
+{"ip": "10.1.1.1", "timestamp": "2", "ports": [{"port": 25, "proto": "tcp", "status": "open"}]}`
+	scan, err = ParseMasscan([]byte(lines))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scan.Hosts) != 1 || len(scan.Hosts[0].Ports) != 2 {
+		t.Errorf("hosts = %+v", scan.Hosts)
+	}
+
+	if _, err := Parse("masscan", []byte("<nmaprun/>")); err == nil {
+		t.Error("XML was read as masscan JSON")
+	}
+	if _, err := Parse("zmap", nil); err == nil {
+		t.Error("unknown format was accepted")
+	}
+}
+
+func TestQueries(t *testing.T) {
+	scan, err := ParseNmap([]byte(nmapXML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary := scan.Summary()
+	if summary.Hosts != 3 || summary.HostsUp != 2 || summary.OpenPorts != 3 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if top := summary.TopPorts[0]; top.Port != 22 || top.Hosts != 2 || top.Service != "ssh" {
+		t.Errorf("top port = %+v", top)
+	}
+
+	tests := []struct {
+		query Query
+		want  []string // address:port of each match
+	}{
+		{Query{Port: 22}, []string{"10.0.0.5:22", "10.0.1.9:22"}},
+		{Query{Service: "HTTP"}, []string{"10.0.0.5:443"}}, // The filtered 8080 is left out
+		{Query{Product: "openssh"}, []string{"10.0.0.5:22"}},
+		{Query{Port: 22, Network: "10.0.1.0/24"}, []string{"10.0.1.9:22"}},
+		{Query{Port: 3389}, nil},
+	}
+	for _, tt := range tests {
+		hosts, err := scan.Find(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, host := range hosts {
+			for _, port := range host.Ports {
+				got = append(got, host.Address+":"+strconv.Itoa(port.Port))
+			}
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("Find(%+v) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+	if _, err := scan.Find(Query{Network: "10.0.0.0"}); err == nil {
+		t.Error("a network without a prefix length was accepted")
+	}
+
+	if host, ok := scan.Host("WEB.lab"); !ok || host.Address != "10.0.0.5" {
+		t.Errorf("Host(WEB.lab) = %+v, %v", host, ok)
+	}
+	if _, ok := scan.Host("10.9.9.9"); ok {
+		t.Error("found a host that wasn't scanned")
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(dir, ""); err == nil {
+		t.Error("loaded the latest scan of an empty directory")
+	}
+
+	for i, name := range []string{"first", "second"} {
+		scan, err := ParseMasscan([]byte(masscanJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scan.Name = name
+		scan.Ingested = time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.UTC)
+		if err := Save(dir, scan); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "second" || list[0].OpenPorts != 2 {
+		t.Errorf("List = %+v", list)
+	}
+	latest, err := Load(dir, "")
+	if err != nil || latest.Name != "second" || len(latest.Hosts) != 2 {
+		t.Errorf("Load latest = %+v, %v", latest, err)
+	}
+
+	if err := Remove(dir, "second"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, "second"); err == nil {
+		t.Error("loaded a removed scan")
+	}
+	if err := Save(dir, &Scan{Name: "../escape"}); err == nil {
+		t.Error("saved a scan outside the directory")
+	}
+}
+
+func TestNameFor(t *testing.T) {
+	for file, want := range map[string]string{
+		"/tmp/scan.xml":          "scan",
+		"office net (2024).json": "office-net-2024",
+		".xml":                   "scan",
+	} {
+		if got := NameFor(file); got != want {
+			t.Errorf("NameFor(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+)
+
+// DefaultJobTimeout bounds a run whose job doesn't set Timeout
+const DefaultJobTimeout = 30 * time.Minute
+
+// maxOutputTail is how much of a run's output is kept in its history
+const maxOutputTail = 4096
+
+// Runner executes due jobs; Run is the scheduler loop behind `hacka.re schedule run`
+type Runner struct {
+	Store *Store
+
+	// Executable is the hacka.re binary jobs run as (defaults to the current executable)
+	Executable string
+
+	// Notify is called with a title and message when a run fails, or succeeds
+	// for jobs with NotifyOnSuccess. It may be nil.
+	Notify func(title, message string)
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// NewRunner creates a runner for the jobs in store
+func NewRunner(store *Store) *Runner {
+	return &Runner{Store: store}
+}
+
+// Run checks the schedule at the start of every minute and starts due jobs
+// until ctx is cancelled. A job still running from its previous run is skipped.
+// The schedule file is re-read each minute, so jobs can be added while it runs.
+func (r *Runner) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(next.Sub(now)):
+		}
+
+		jobs, err := r.Store.Jobs()
+		if err != nil {
+			if log := logger.Get(); log != nil {
+				log.Error("Scheduler failed to load jobs: %v", err)
+			}
+			continue
+		}
+
+		for _, job := range Due(jobs, next) {
+			if !r.claim(job.Name) {
+				if log := logger.Get(); log != nil {
+					log.Info("Skipping job %s: previous run still in progress", job.Name)
+				}
+				continue
+			}
+			wg.Add(1)
+			go func(job Job) {
+				defer wg.Done()
+				defer r.release(job.Name)
+				r.RunJob(ctx, job)
+			}(job)
+		}
+	}
+}
+
+// Due returns the enabled jobs whose schedule matches the minute of t
+func Due(jobs []Job, t time.Time) []Job {
+	minute := t.Truncate(time.Minute)
+	var due []Job
+	for _, job := range jobs {
+		if job.Disabled {
+			continue
+		}
+		cron, err := ParseCron(job.Cron)
+		if err != nil {
+			continue
+		}
+		if cron.Next(minute.Add(-time.Minute)).Equal(minute) {
+			due = append(due, job)
+		}
+	}
+	return due
+}
+
+// RunJob executes a job once, records the run in its history and sends notifications
+func (r *Runner) RunJob(ctx context.Context, job Job) Run {
+	run := r.execute(ctx, job)
+
+	if err := r.Store.RecordRun(job.Name, run); err != nil {
+		if log := logger.Get(); log != nil {
+			log.Error("Failed to record run of job %s: %v", job.Name, err)
+		}
+	}
+
+	if log := logger.Get(); log != nil {
+		log.Info("Job %s finished in %.1fs with exit code %d", job.Name, run.Duration, run.ExitCode)
+	}
+
+	if r.Notify != nil {
+		switch {
+		case !run.Succeeded():
+			r.Notify("hacka.re: "+job.Name+" failed", failureSummary(run))
+		case job.NotifyOnSuccess:
+			r.Notify("hacka.re: "+job.Name+" finished", lastLine(run.Output))
+		}
+	}
+	return run
+}
+
+// execute runs the job's command with its overrides applied
+func (r *Runner) execute(ctx context.Context, job Job) Run {
+	run := Run{StartedAt: time.Now()}
+
+	executable := r.Executable
+	if executable == "" {
+		var err error
+		if executable, err = os.Executable(); err != nil {
+			run.ExitCode = -1
+			run.Error = fmt.Sprintf("cannot locate hacka.re executable: %v", err)
+			return run
+		}
+	}
+
+	timeout := DefaultJobTimeout
+	if job.Timeout > 0 {
+		timeout = time.Duration(job.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, executable, job.Command...)
+	cmd.Env = jobEnv(job)
+	output := &tailBuffer{limit: maxOutputTail}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err := cmd.Run()
+	run.Duration = time.Since(run.StartedAt).Seconds()
+	run.Output = output.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		run.ExitCode = -1
+		run.Error = fmt.Sprintf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		run.ExitCode = exitErr.ExitCode()
+	default:
+		run.ExitCode = -1
+		run.Error = err.Error()
+	}
+	return run
+}
+
+// jobEnv is the runner's environment plus the job's overrides
+func jobEnv(job Job) []string {
+	env := os.Environ()
+	if job.Session != "" {
+		env = append(env, "HACKARE_SESSION="+job.Session)
+	}
+	for key, value := range job.Env {
+		env = append(env, key+"="+value)
+	}
+	return env
+}
+
+func (r *Runner) claim(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running == nil {
+		r.running = make(map[string]bool)
+	}
+	if r.running[name] {
+		return false
+	}
+	r.running[name] = true
+	return true
+}
+
+func (r *Runner) release(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, name)
+}
+
+// failureSummary describes a failed run in one short message
+func failureSummary(run Run) string {
+	summary := fmt.Sprintf("exit code %d", run.ExitCode)
+	if run.Error != "" {
+		summary = run.Error
+	}
+	if line := lastLine(run.Output); line != "" {
+		summary += ": " + line
+	}
+	return summary
+}
+
+// lastLine returns the last non-empty line of s
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	data  []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = append([]byte(nil), b.data[len(b.data)-b.limit:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
+
+// SplitCommand splits a --command string into arguments, honouring single and
+// double quotes and backslash escapes. A leading "hacka.re" is dropped.
+func SplitCommand(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, c := range command {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in command %q", command)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	if len(args) > 0 && (args[0] == "hacka.re" || strings.HasSuffix(args[0], "/hacka.re")) {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil, errors.New("command is empty")
+	}
+	return args, nil
+}
@@ -0,0 +1,154 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// Thursday 2026-01-01 10:30
+	start := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 8 * * *", time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2026, 1, 4, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 10,12 * * *", time.Date(2026, 1, 1, 12, 30, 0, 0, time.UTC)},
+		{"0 0 15 * 1", time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)}, // Monday comes before the 15th
+		{"@hourly", time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := cron.Next(start); !got.Equal(tt.want) {
+			t.Errorf("%q: Next = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	got, err := SplitCommand(`hacka.re ask --model "gpt 4" 'it\'s' a\ b`)
+	if err == nil {
+		t.Fatalf("expected unterminated quote error, got %q", got)
+	}
+
+	got, err = SplitCommand(`hacka.re models --json --provider "open ai"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"models", "--json", "--provider", "open ai"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitCommand = %q, want %q", got, want)
+	}
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "schedule.json"))
+
+	job := Job{Name: "daily-summary", Cron: "0 8 * * *", Command: []string{"models"}}
+	if err := store.Add(job); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(job); err == nil {
+		t.Error("adding a duplicate job succeeded")
+	}
+	if err := store.Add(Job{Name: "bad", Cron: "daily", Command: []string{"x"}}); err == nil {
+		t.Error("adding a job with an invalid cron expression succeeded")
+	}
+
+	for i := 0; i < maxRunHistory+5; i++ {
+		if err := store.RecordRun("daily-summary", Run{ExitCode: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved, err := store.Get("daily-summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Runs) != maxRunHistory {
+		t.Errorf("kept %d runs, want %d", len(saved.Runs), maxRunHistory)
+	}
+	if last, _ := saved.LastRun(); last.ExitCode != maxRunHistory+4 {
+		t.Errorf("last run exit code = %d, want %d", last.ExitCode, maxRunHistory+4)
+	}
+
+	if err := store.Remove("daily-summary"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("daily-summary"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Get after Remove: err = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestDue(t *testing.T) {
+	at := time.Date(2026, 1, 1, 8, 0, 30, 0, time.UTC)
+	jobs := []Job{
+		{Name: "due", Cron: "0 8 * * *"},
+		{Name: "later", Cron: "0 9 * * *"},
+		{Name: "paused", Cron: "0 8 * * *", Disabled: true},
+	}
+	due := Due(jobs, at)
+	if len(due) != 1 || due[0].Name != "due" {
+		t.Errorf("Due = %v, want only the \"due\" job", due)
+	}
+}
+
+func TestRunJobRecordsFailure(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	store := NewStore(filepath.Join(t.TempDir(), "schedule.json"))
+	job := Job{
+		Name:    "failing",
+		Cron:    "@daily",
+		Command: []string{"-c", `echo "using $SCHEDULE_TEST"; exit 3`},
+		Env:     map[string]string{"SCHEDULE_TEST": "override"},
+	}
+	if err := store.Add(job); err != nil {
+		t.Fatal(err)
+	}
+
+	var notified string
+	runner := NewRunner(store)
+	runner.Executable = sh
+	runner.Notify = func(title, message string) { notified = title + ": " + message }
+
+	run := runner.RunJob(context.Background(), job)
+	if run.ExitCode != 3 || run.Succeeded() {
+		t.Errorf("run = %+v, want exit code 3", run)
+	}
+	if run.Output != "using override\n" {
+		t.Errorf("output = %q", run.Output)
+	}
+	if notified != "hacka.re: failing failed: exit code 3: using override" {
+		t.Errorf("notification = %q", notified)
+	}
+
+	saved, _ := store.Get("failing")
+	if len(saved.Runs) != 1 {
+		t.Errorf("recorded %d runs, want 1", len(saved.Runs))
+	}
+}
@@ -0,0 +1,230 @@
+// Package schedule runs hacka.re commands on cron schedules, such as a daily
+// recon summary, and keeps a history of their runs.
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// maxRunHistory is how many runs are kept per job
+const maxRunHistory = 20
+
+// ErrJobNotFound is returned for operations on an unknown job name
+var ErrJobNotFound = errors.New("no such scheduled job")
+
+// validJobName keeps names usable as file and log identifiers
+var validJobName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Job is a command run on a schedule
+type Job struct {
+	Name    string   `json:"name"`
+	Cron    string   `json:"cron"`
+	Command []string `json:"command"` // hacka.re arguments, e.g. ["chat", "--help"]
+
+	// Per-job overrides of the saved configuration
+	Session string            `json:"session,omitempty"` // Share link used instead of the saved config (as HACKARE_SESSION)
+	Env     map[string]string `json:"env,omitempty"`     // Extra environment variables
+
+	Timeout  int  `json:"timeout,omitempty"` // Seconds; 0 uses DefaultJobTimeout
+	Disabled bool `json:"disabled,omitempty"`
+
+	// NotifyOnSuccess also notifies when the run succeeds (failures always notify)
+	NotifyOnSuccess bool `json:"notifyOnSuccess,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	Runs      []Run     `json:"runs,omitempty"` // Most recent last
+}
+
+// Run is the record of one execution of a job
+type Run struct {
+	StartedAt time.Time `json:"startedAt"`
+	Duration  float64   `json:"duration"` // Seconds
+	ExitCode  int       `json:"exitCode"`
+	Error     string    `json:"error,omitempty"`  // Set when the command couldn't start or timed out
+	Output    string    `json:"output,omitempty"` // Tail of stdout and stderr
+}
+
+// Succeeded reports whether the run exited cleanly
+func (r Run) Succeeded() bool {
+	return r.ExitCode == 0 && r.Error == ""
+}
+
+// LastRun returns the most recent run, if any
+func (j *Job) LastRun() (Run, bool) {
+	if len(j.Runs) == 0 {
+		return Run{}, false
+	}
+	return j.Runs[len(j.Runs)-1], true
+}
+
+// Store persists scheduled jobs and their run history in a JSON file
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStore creates a store backed by path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the default location of the schedule file
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-schedule.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "schedule.json")
+}
+
+// Jobs returns all jobs in the order they were added
+func (s *Store) Jobs() ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Get returns the job with the given name
+func (s *Store) Get(name string) (Job, error) {
+	jobs, err := s.Jobs()
+	if err != nil {
+		return Job{}, err
+	}
+	for _, job := range jobs {
+		if job.Name == name {
+			return job, nil
+		}
+	}
+	return Job{}, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+}
+
+// Add validates and saves a new job; the name must be unused
+func (s *Store) Add(job Job) error {
+	if !validJobName.MatchString(job.Name) {
+		return fmt.Errorf("invalid job name %q: use letters, digits, '.', '_' and '-'", job.Name)
+	}
+	if _, err := ParseCron(job.Cron); err != nil {
+		return err
+	}
+	if len(job.Command) == 0 {
+		return errors.New("command is required")
+	}
+
+	return s.update(func(jobs []Job) ([]Job, error) {
+		for _, existing := range jobs {
+			if existing.Name == job.Name {
+				return nil, fmt.Errorf("job %q already exists; remove it first", job.Name)
+			}
+		}
+		if job.CreatedAt.IsZero() {
+			job.CreatedAt = time.Now()
+		}
+		job.Runs = nil
+		return append(jobs, job), nil
+	})
+}
+
+// Remove deletes a job and its history
+func (s *Store) Remove(name string) error {
+	return s.update(func(jobs []Job) ([]Job, error) {
+		for i, job := range jobs {
+			if job.Name == name {
+				return append(jobs[:i], jobs[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	})
+}
+
+// SetDisabled pauses or resumes a job
+func (s *Store) SetDisabled(name string, disabled bool) error {
+	return s.update(func(jobs []Job) ([]Job, error) {
+		for i := range jobs {
+			if jobs[i].Name == name {
+				jobs[i].Disabled = disabled
+				return jobs, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, name)
+	})
+}
+
+// RecordRun appends a run to the job's history, dropping the oldest runs
+func (s *Store) RecordRun(name string, run Run) error {
+	return s.update(func(jobs []Job) ([]Job, error) {
+		for i := range jobs {
+			if jobs[i].Name == name {
+				jobs[i].Runs = append(jobs[i].Runs, run)
+				if len(jobs[i].Runs) > maxRunHistory {
+					jobs[i].Runs = jobs[i].Runs[len(jobs[i].Runs)-maxRunHistory:]
+				}
+				return jobs, nil
+			}
+		}
+		// The job was removed while it ran
+		return jobs, nil
+	})
+}
+
+// update loads, modifies and saves the jobs under the lock
+func (s *Store) update(fn func([]Job) ([]Job, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs, err := s.load()
+	if err != nil {
+		return err
+	}
+	jobs, err = fn(jobs)
+	if err != nil {
+		return err
+	}
+	return s.save(jobs)
+}
+
+// scheduleFile is the on-disk format
+type scheduleFile struct {
+	Jobs []Job `json:"jobs"`
+}
+
+// load reads the jobs (must be called with mu held); a missing file means no jobs
+func (s *Store) load() ([]Job, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule: %w", err)
+	}
+
+	var file scheduleFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule %s: %w", s.path, err)
+	}
+	return file.Jobs, nil
+}
+
+// save writes the jobs atomically (must be called with mu held).
+// Sessions can carry API keys, so the file is only readable by the user.
+func (s *Store) save(jobs []Job) error {
+	data, err := json.MarshalIndent(scheduleFile{Jobs: jobs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schedule: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create schedule directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,100 @@
+package share
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrNoBase is returned by LoadBase for a name that was never saved
+var ErrNoBase = errors.New("no such base configuration")
+
+// BasesDir returns where named base configurations for delta links are kept.
+// HACKARE_BASES_DIR overrides it.
+func BasesDir() string {
+	if dir := os.Getenv("HACKARE_BASES_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hacka.re-bases")
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "bases")
+}
+
+// basePath returns the file of a base, refusing names that would leave the directory
+func basePath(dir, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid base name %q", name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// SaveBase stores cfg as the base called name, without its API key
+func SaveBase(dir, name string, cfg *SharedConfig) error {
+	path, err := basePath(dir, name)
+	if err != nil {
+		return err
+	}
+	base := *cfg
+	base.APIKey = ""
+	base.Delta = nil
+	data, err := json.MarshalIndent(&base, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode base: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create bases directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write base: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write base: %w", err)
+	}
+	return nil
+}
+
+// LoadBase reads the base called name
+func LoadBase(dir, name string) (*SharedConfig, error) {
+	path, err := basePath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoBase, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base: %w", err)
+	}
+	var cfg SharedConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse base %s: %w", name, err)
+	}
+	return &cfg, nil
+}
+
+// ListBases returns the names of the saved bases, sorted
+func ListBases(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bases: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,37 @@
+package share
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hacka-re/cli/pkg/sharelink"
+)
+
+// ConfirmContents lists on out what a link made from cfg carries. If cfg has
+// an API key it asks on in for sharelink.IncludeKeyPhrase, and removes the key
+// from cfg unless that is what was typed. It reports whether the key stayed.
+func ConfirmContents(cfg *SharedConfig, in io.Reader, out io.Writer) (bool, error) {
+	fmt.Fprintln(out, "The link will carry:")
+	for _, part := range sharelink.Contents(cfg) {
+		fmt.Fprintf(out, "  • %s\n", part)
+	}
+	if cfg.APIKey == "" {
+		return false, nil
+	}
+
+	fmt.Fprintln(out, "\nAnyone with the link and its password can use your API key.")
+	fmt.Fprintf(out, "Type %q to embed it, or press Enter to leave it out: ", sharelink.IncludeKeyPhrase)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	if strings.TrimSpace(answer) == sharelink.IncludeKeyPhrase {
+		fmt.Fprintln(out, "The API key is included.")
+		return true, nil
+	}
+	cfg.APIKey = ""
+	fmt.Fprintln(out, "The API key is left out.")
+	return false, nil
+}
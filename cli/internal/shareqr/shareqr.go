@@ -0,0 +1,99 @@
+// Package shareqr serves the current share link as a QR code page, so a
+// configuration can go to a phone by scanning the screen instead of typing
+// or pasting the link.
+//
+// The page is at Path and needs the token printed when serve starts, since
+// the link carries the whole configuration. It stays encrypted: whoever
+// scans it still needs the link's password.
+package shareqr
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"html/template"
+	"net/http"
+
+	"github.com/hacka-re/cli/internal/qrcode"
+)
+
+// Path is where the page is served
+const Path = "/share-qr"
+
+// quietZone is the light border around the code, in modules, as the standard asks
+const quietZone = 4
+
+// Page serves one share link as a QR code
+type Page struct {
+	link  string
+	token string
+}
+
+// New serves link, a full share URL, under a random token
+func New(link string) *Page {
+	random := make([]byte, 16)
+	rand.Read(random)
+	return &Page{link: link, token: hex.EncodeToString(random)}
+}
+
+// URL returns the address of the page on the server at baseURL, token included
+func (p *Page) URL(baseURL string) string {
+	return baseURL + Path + "?token=" + p.token
+}
+
+var pageTemplate = template.Must(template.New("share-qr").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="referrer" content="no-referrer">
+<title>hacka.re share link</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 2rem auto; padding: 0 1rem; text-align: center; }
+.qr { width: min(90vw, 28rem); margin: 1rem auto; }
+.qr svg { width: 100%; height: auto; }
+.link { word-break: break-all; font-family: monospace; font-size: 0.75rem; color: #555; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Scan to open hacka.re</h1>
+{{if .QR}}<div class="qr">{{.QR}}</div>
+<p>Scan with the phone's camera, then enter the link's password.</p>
+{{else}}<p>The link is too long for a QR code. Send it to the phone another way.</p>
+{{end}}<details><summary>Link ({{len .Link}} bytes)</summary><p class="link">{{.Link}}</p></details>
+</body>
+</html>
+`))
+
+// ServeHTTP serves the page to requests with the token and 404s the rest, so
+// the page can't be found without it
+func (p *Page) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if r.URL.Path != Path || r.Method != http.MethodGet ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(p.token)) != 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	data := struct {
+		Link string
+		QR   template.HTML
+	}{Link: p.link}
+	if code, err := qrcode.Encode([]byte(p.link), qrcode.Low); err == nil {
+		data.QR = template.HTML(code.SVG(quietZone))
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	pageTemplate.Execute(w, data)
+}
+
+// Wrap serves the page in front of next
+func (p *Page) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == Path {
+			p.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,48 @@
+package shareqr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	link := "https://hacka.re/#gpt=abc&x=<y>"
+	page := New(link)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("app"))
+	})
+	handler := page.Wrap(next)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	for _, target := range []string{Path, Path + "?token=wrong", Path + "?token="} {
+		if rec := get(target); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", target, rec.Code)
+		}
+	}
+
+	rec := get(strings.TrimPrefix(page.URL("http://localhost:8080"), "http://localhost:8080"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with the token, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "<svg ") || !strings.Contains(body, "gpt=abc&amp;x=&lt;y&gt;") {
+		t.Fatalf("expected the QR code and the escaped link, got %s", body)
+	}
+	if rec.Header().Get("Cache-Control") != "no-store" {
+		t.Error("the page must not be cached")
+	}
+
+	if rec := get("/index.html"); rec.Body.String() != "app" {
+		t.Error("expected other paths to reach the app")
+	}
+	if New(link).token == page.token {
+		t.Error("expected a new token per page")
+	}
+}
@@ -0,0 +1,100 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// Secret reports whether a configuration field holds a secret: API keys,
+// tokens, passwords, password hashes and extra HTTP headers. Names are
+// compared without case, underscores or dashes, so apiKey, api_key and
+// extra_api_keys all count.
+func Secret(field string) bool {
+	name := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(field))
+	for _, suffix := range []string{"key", "keys", "token", "hash", "headers"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return strings.Contains(name, "secret") || strings.Contains(name, "password")
+}
+
+// WithoutSecrets returns a copy of the snapshot whose configuration files
+// have no secret fields, for storage the user doesn't control alone
+func (s *Snapshot) WithoutSecrets() *Snapshot {
+	stripped := *s
+	stripped.Config = stripSecrets(s.Config)
+	stripped.TUIConfig = stripSecrets(s.TUIConfig)
+	return &stripped
+}
+
+// KeepSecrets copies the secret fields of the configuration files in paths
+// into the snapshot, so restoring one saved without secrets keeps them
+func (s *Snapshot) KeepSecrets(paths Paths) {
+	s.Config = keepSecrets(s.Config, paths.Config)
+	s.TUIConfig = keepSecrets(s.TUIConfig, paths.TUIConfig)
+}
+
+// stripSecrets removes secret fields at any depth of a JSON object
+func stripSecrets(data json.RawMessage) json.RawMessage {
+	var value interface{}
+	if len(data) == 0 || json.Unmarshal(data, &value) != nil {
+		return data
+	}
+	encoded, err := json.MarshalIndent(strip(value), "", "  ")
+	if err != nil {
+		return data
+	}
+	return encoded
+}
+
+func strip(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if Secret(name) {
+				delete(v, name)
+			} else {
+				v[name] = strip(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = strip(v[i])
+		}
+	}
+	return value
+}
+
+// keepSecrets adds the secret fields of the JSON file in path to data
+func keepSecrets(data json.RawMessage, path string) json.RawMessage {
+	local, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return data
+	}
+	var have, want map[string]interface{}
+	if json.Unmarshal(local, &have) != nil || json.Unmarshal(data, &want) != nil {
+		return data
+	}
+	keep(have, want)
+	encoded, err := json.MarshalIndent(want, "", "  ")
+	if err != nil {
+		return data
+	}
+	return encoded
+}
+
+// keep copies the secret fields of have into want, in objects both have
+func keep(have, want map[string]interface{}) {
+	for name, field := range have {
+		if Secret(name) {
+			want[name] = field
+			continue
+		}
+		haveObject, ok := field.(map[string]interface{})
+		if wantObject, ok2 := want[name].(map[string]interface{}); ok && ok2 {
+			keep(haveObject, wantObject)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+// Package snapshot saves everything hacka.re keeps for a namespace into one
+// password-encrypted file, for backups and for moving to another machine: the
+// configuration (with its RAG document list), the TUI settings and custom
+// prompts, the conversation the TUI would recover, the functions directory,
+// and the namespace's memory and input history.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/crypto"
+	"github.com/hacka-re/cli/internal/funcdir"
+	"github.com/hacka-re/cli/internal/inputhistory"
+	"github.com/hacka-re/cli/internal/memory"
+)
+
+// Format names the file format, and Version its revision
+const (
+	Format  = "hacka.re-snapshot"
+	Version = 1
+)
+
+// ErrBadPassword is returned when a snapshot doesn't decrypt
+var ErrBadPassword = errors.New("wrong password or corrupted snapshot")
+
+// Extension is added to snapshot names that aren't paths
+const Extension = ".snapshot"
+
+// Dir returns where named snapshots are kept. HACKARE_SNAPSHOT_DIR overrides it.
+func Dir() string {
+	if dir := os.Getenv("HACKARE_SNAPSHOT_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hacka.re-snapshots")
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "snapshots")
+}
+
+// File returns the file of a snapshot: name itself if it is a path, else
+// name.snapshot in Dir
+func File(name string) string {
+	if strings.ContainsRune(name, filepath.Separator) || strings.ContainsRune(name, '/') || strings.HasSuffix(name, Extension) {
+		return name
+	}
+	return filepath.Join(Dir(), name+Extension)
+}
+
+// Paths says where the parts of a snapshot are kept
+type Paths struct {
+	Config    string // hacka.re configuration
+	TUIConfig string // TUI settings and custom prompts
+	Recovery  string // Conversation the TUI offers to recover
+	Functions string // Functions directory
+	Memory    string // Memory file, of which one namespace is kept
+	History   string // Input history file, of which one namespace is kept
+}
+
+// DefaultPaths returns where hacka.re keeps them
+func DefaultPaths() Paths {
+	tuiDir := "hackare-tui"
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		tuiDir = filepath.Join(homeDir, ".config", "hackare-tui")
+	}
+	return Paths{
+		Config:    config.GetConfigPath(),
+		TUIConfig: filepath.Join(tuiDir, "config.json"),
+		Recovery:  filepath.Join(tuiDir, "recovery.json"),
+		Functions: funcdir.Dir(),
+		Memory:    memory.DefaultPath(),
+		History:   inputhistory.DefaultPath(),
+	}
+}
+
+// Snapshot is the decrypted contents of a snapshot file. File contents are
+// kept as they are; missing files are left empty.
+type Snapshot struct {
+	Version   int               `json:"version"`
+	Created   time.Time         `json:"created"`
+	Namespace string            `json:"namespace"`
+	Config    json.RawMessage   `json:"config,omitempty"`
+	TUIConfig json.RawMessage   `json:"tuiConfig,omitempty"`
+	Recovery  json.RawMessage   `json:"recovery,omitempty"`
+	Functions map[string]string `json:"functions,omitempty"` // By file name
+	Memory    []memory.Fact     `json:"memory,omitempty"`
+	History   []string          `json:"history,omitempty"`
+}
+
+// Summary counts what a snapshot holds
+type Summary struct {
+	Namespace    string    `json:"namespace"`
+	Created      time.Time `json:"created"`
+	Config       bool      `json:"config"`
+	RAGDocuments int       `json:"ragDocuments"`
+	TUIConfig    bool      `json:"tuiConfig"`
+	Prompts      int       `json:"prompts"` // Custom prompts in the TUI settings
+	Recovery     bool      `json:"recovery"`
+	Functions    int       `json:"functions"`
+	Memory       int       `json:"memory"`
+	History      int       `json:"history"`
+}
+
+// Create reads the parts of namespace from paths
+func Create(paths Paths, namespace string) (*Snapshot, error) {
+	s := &Snapshot{Version: Version, Created: time.Now().UTC(), Namespace: memory.Namespace(namespace)}
+
+	var err error
+	if s.Config, err = readJSON(paths.Config); err != nil {
+		return nil, err
+	}
+	if s.TUIConfig, err = readJSON(paths.TUIConfig); err != nil {
+		return nil, err
+	}
+	if s.Recovery, err = readJSON(paths.Recovery); err != nil {
+		return nil, err
+	}
+
+	files, err := os.ReadDir(paths.Functions)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read functions: %w", err)
+	}
+	for _, file := range files {
+		if !file.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(paths.Functions, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read function: %w", err)
+		}
+		if s.Functions == nil {
+			s.Functions = map[string]string{}
+		}
+		s.Functions[file.Name()] = string(data)
+	}
+
+	if s.Memory, err = memory.NewStore(paths.Memory).Facts(s.Namespace); err != nil {
+		return nil, err
+	}
+	if s.History, err = inputhistory.NewStore(paths.History, 0).Entries(s.Namespace); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// readJSON returns the contents of a JSON file, nil if there is none
+func readJSON(path string) (json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("%s is not valid JSON", path)
+	}
+	return data, nil
+}
+
+// Summary counts what the snapshot holds
+func (s *Snapshot) Summary() Summary {
+	summary := Summary{
+		Namespace: s.Namespace,
+		Created:   s.Created,
+		Config:    len(s.Config) > 0,
+		TUIConfig: len(s.TUIConfig) > 0,
+		Recovery:  len(s.Recovery) > 0,
+		Functions: len(s.Functions),
+		Memory:    len(s.Memory),
+		History:   len(s.History),
+	}
+	var cfg struct {
+		RAGDocuments []string `json:"ragDocuments"`
+	}
+	if json.Unmarshal(s.Config, &cfg) == nil {
+		summary.RAGDocuments = len(cfg.RAGDocuments)
+	}
+	var tui struct {
+		CustomPrompts []json.RawMessage `json:"custom_prompts"`
+	}
+	if json.Unmarshal(s.TUIConfig, &tui) == nil {
+		summary.Prompts = len(tui.CustomPrompts)
+	}
+	return summary
+}
+
+// String lists what the summary counts, for printing
+func (s Summary) String() string {
+	var parts []string
+	if s.Config {
+		parts = append(parts, fmt.Sprintf("config (%d RAG document(s))", s.RAGDocuments))
+	}
+	if s.TUIConfig {
+		parts = append(parts, fmt.Sprintf("TUI settings (%d custom prompt(s))", s.Prompts))
+	}
+	if s.Recovery {
+		parts = append(parts, "open conversation")
+	}
+	parts = append(parts,
+		fmt.Sprintf("%d function file(s)", s.Functions),
+		fmt.Sprintf("%d memory fact(s)", s.Memory),
+		fmt.Sprintf("%d history line(s)", s.History))
+	return strings.Join(parts, ", ")
+}
+
+// sealed is the file format: the snapshot as encrypted JSON
+type sealed struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	crypto.EncryptedData
+}
+
+// Seal encrypts the snapshot with password
+func (s *Snapshot) Seal(password string) ([]byte, error) {
+	if password == "" {
+		return nil, errors.New("a snapshot needs a password")
+	}
+	enc, err := crypto.EncryptJSON(s, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt snapshot: %w", err)
+	}
+	return json.MarshalIndent(sealed{Format: Format, Version: Version, EncryptedData: *enc}, "", "  ")
+}
+
+// Open decrypts a snapshot file's contents
+func Open(data []byte, password string) (*Snapshot, error) {
+	var file sealed
+	if err := json.Unmarshal(data, &file); err != nil || file.Format != Format {
+		return nil, errors.New("not a hacka.re snapshot")
+	}
+	if file.Version > Version {
+		return nil, fmt.Errorf("snapshot version %d is newer than this hacka.re supports (%d)", file.Version, Version)
+	}
+	data, err := crypto.Decrypt(&file.EncryptedData, password)
+	if err != nil {
+		return nil, ErrBadPassword
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// Save seals the snapshot into path, readable only by the user
+func (s *Snapshot) Save(path, password string) error {
+	data, err := s.Seal(password)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load reads and decrypts the snapshot in path
+func Load(path, password string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return Open(data, password)
+}
+
+// Conflicts lists what restoring would replace: existing files with other
+// contents, and a namespace that already has other memory or history
+func (s *Snapshot) Conflicts(paths Paths) ([]string, error) {
+	var conflicts []string
+	differs := func(path string, want []byte) {
+		if len(want) == 0 {
+			return
+		}
+		if have, err := os.ReadFile(path); err == nil && !bytes.Equal(have, want) && !sameJSON(have, want) {
+			conflicts = append(conflicts, path)
+		}
+	}
+	differs(paths.Config, s.Config)
+	differs(paths.TUIConfig, s.TUIConfig)
+	differs(paths.Recovery, s.Recovery)
+	for _, name := range s.functionNames() {
+		differs(filepath.Join(paths.Functions, name), []byte(s.Functions[name]))
+	}
+
+	facts, err := memory.NewStore(paths.Memory).Facts(s.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(facts) > 0 && !sameFacts(facts, s.Memory) {
+		conflicts = append(conflicts, fmt.Sprintf("%d memory fact(s) of namespace %s", len(facts), s.Namespace))
+	}
+	lines, err := inputhistory.NewStore(paths.History, 0).Entries(s.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) > 0 && strings.Join(lines, "\n") != strings.Join(s.History, "\n") {
+		conflicts = append(conflicts, fmt.Sprintf("%d history line(s) of namespace %s", len(lines), s.Namespace))
+	}
+	return conflicts, nil
+}
+
+// sameJSON reports whether a and b are the same JSON value, however formatted
+func sameJSON(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func sameFacts(a, b []memory.Fact) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].Text != b[i].Text {
+			return false
+		}
+	}
+	return true
+}
+
+// functionNames returns the function file names, sorted. Names that would
+// leave the functions directory are left out.
+func (s *Snapshot) functionNames() []string {
+	var names []string
+	for name := range s.Functions {
+		if name == filepath.Base(name) && name != "." && name != ".." {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Restore writes the snapshot into paths. Files and the namespace's memory and
+// history are replaced; other function files and namespaces are kept.
+func (s *Snapshot) Restore(paths Paths) error {
+	write := func(path string, data []byte) error {
+		if len(data) == 0 {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return os.Rename(tmp, path)
+	}
+
+	if err := write(paths.Config, s.Config); err != nil {
+		return err
+	}
+	if err := write(paths.TUIConfig, s.TUIConfig); err != nil {
+		return err
+	}
+	if err := write(paths.Recovery, s.Recovery); err != nil {
+		return err
+	}
+	for _, name := range s.functionNames() {
+		if err := write(filepath.Join(paths.Functions, name), []byte(s.Functions[name])); err != nil {
+			return err
+		}
+	}
+	if err := memory.NewStore(paths.Memory).Set(s.Namespace, s.Memory); err != nil {
+		return err
+	}
+	return inputhistory.NewStore(paths.History, 0).Set(s.Namespace, s.History)
+}
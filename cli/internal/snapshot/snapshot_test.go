@@ -0,0 +1,156 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/inputhistory"
+	"github.com/hacka-re/cli/internal/memory"
+)
+
+func testPaths(dir string) Paths {
+	return Paths{
+		Config:    filepath.Join(dir, "hacka.re", "config.json"),
+		TUIConfig: filepath.Join(dir, "hackare-tui", "config.json"),
+		Recovery:  filepath.Join(dir, "hackare-tui", "recovery.json"),
+		Functions: filepath.Join(dir, "hacka.re", "functions"),
+		Memory:    filepath.Join(dir, "hacka.re", "memory.json"),
+		History:   filepath.Join(dir, "hacka.re", "history.json"),
+	}
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	from := testPaths(t.TempDir())
+	os.MkdirAll(from.Functions, 0700)
+	os.WriteFile(from.Config, []byte(`{"namespace":"work","ragDocuments":["a.md","b.md"]}`), 0600)
+	os.MkdirAll(filepath.Dir(from.TUIConfig), 0700)
+	os.WriteFile(from.TUIConfig, []byte(`{"custom_prompts":[{"id":"x"}]}`), 0600)
+	os.WriteFile(filepath.Join(from.Functions, "lookup.js"), []byte("function lookup() {}"), 0600)
+	mem := memory.NewStore(from.Memory)
+	mem.Add("work", "Works on the red team.", "manual")
+	mem.Add("home", "Not part of the snapshot.", "manual")
+	inputhistory.NewStore(from.History, 0).Add("work", "scan 10.0.0.1")
+
+	s, err := Create(from, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary := s.Summary()
+	if !summary.Config || summary.RAGDocuments != 2 || summary.Prompts != 1 || summary.Recovery ||
+		summary.Functions != 1 || summary.Memory != 1 || summary.History != 1 {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	file := filepath.Join(t.TempDir(), "backup.snapshot")
+	if err := s.Save(file, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(file); strings.Contains(string(data), "red team") {
+		t.Error("the snapshot file is not encrypted")
+	}
+	if _, err := Load(file, "wrong"); !errors.Is(err, ErrBadPassword) {
+		t.Error("a wrong password opened the snapshot")
+	}
+	loaded, err := Load(file, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := testPaths(t.TempDir())
+	if conflicts, _ := loaded.Conflicts(to); len(conflicts) != 0 {
+		t.Errorf("conflicts on an empty machine: %v", conflicts)
+	}
+	if err := loaded.Restore(to); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(to.Functions, "lookup.js")); string(data) != "function lookup() {}" {
+		t.Errorf("function = %q", data)
+	}
+	if facts, _ := memory.NewStore(to.Memory).Facts("work"); len(facts) != 1 || facts[0].Text != "Works on the red team." {
+		t.Errorf("facts = %+v", facts)
+	}
+	if facts, _ := memory.NewStore(to.Memory).Facts("home"); len(facts) != 0 {
+		t.Errorf("another namespace was restored: %+v", facts)
+	}
+	if lines, _ := inputhistory.NewStore(to.History, 0).Entries("work"); !reflect.DeepEqual(lines, []string{"scan 10.0.0.1"}) {
+		t.Errorf("history = %q", lines)
+	}
+
+	// Restoring again changes nothing; changed state is reported
+	if conflicts, _ := loaded.Conflicts(to); len(conflicts) != 0 {
+		t.Errorf("conflicts after restoring: %v", conflicts)
+	}
+	os.WriteFile(to.Config, []byte(`{}`), 0600)
+	memory.NewStore(to.Memory).Add("work", "Something new.", "manual")
+	if conflicts, _ := loaded.Conflicts(to); len(conflicts) != 2 || conflicts[0] != to.Config {
+		t.Errorf("conflicts = %v", conflicts)
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	if _, err := Open([]byte(`{"enc":"x"}`), "secret"); err == nil {
+		t.Error("a file without the snapshot format was opened")
+	}
+	if _, err := Open([]byte(`{"format":"hacka.re-snapshot","version":99}`), "secret"); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("a newer version: %v", err)
+	}
+	if _, err := (&Snapshot{}).Seal(""); err == nil {
+		t.Error("a snapshot was sealed without a password")
+	}
+
+	// Function names can't point outside the functions directory
+	s := &Snapshot{Functions: map[string]string{"../evil.js": "x", "ok.js": "y"}}
+	if names := s.functionNames(); !reflect.DeepEqual(names, []string{"ok.js"}) {
+		t.Errorf("functionNames = %v", names)
+	}
+}
+
+func TestFile(t *testing.T) {
+	t.Setenv("HACKARE_SNAPSHOT_DIR", "/backups")
+	for name, want := range map[string]string{
+		"laptop":          "/backups/laptop.snapshot",
+		"./laptop":        "./laptop",
+		"old.snapshot":    "old.snapshot",
+		"/mnt/usb/laptop": "/mnt/usb/laptop",
+	} {
+		if got := File(name); got != want {
+			t.Errorf("File(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	for field, secret := range map[string]bool{
+		"apiKey": true, "api_key": true, "extra_api_keys": true, "shodanApiKey": true, "unlockHash": true,
+		"provider_headers": true, "botToken": true, "password": true,
+		"maxTokens": false, "key_rotation": false, "model": false, "ragDocuments": false,
+	} {
+		if Secret(field) != secret {
+			t.Errorf("Secret(%s) = %v", field, !secret)
+		}
+	}
+
+	s := &Snapshot{Config: []byte(`{"model":"gpt-4o","apiKey":"sk-1","protocol":{"headers":{"x":"y"},"path":"/v1"}}`)}
+	stripped := s.WithoutSecrets()
+	if strings.Contains(string(stripped.Config), "sk-1") || strings.Contains(string(stripped.Config), "headers") ||
+		!strings.Contains(string(stripped.Config), "/v1") {
+		t.Errorf("stripped config = %s", stripped.Config)
+	}
+	if !strings.Contains(string(s.Config), "sk-1") {
+		t.Error("WithoutSecrets changed the snapshot")
+	}
+
+	paths := testPaths(t.TempDir())
+	os.MkdirAll(filepath.Dir(paths.Config), 0700)
+	os.WriteFile(paths.Config, []byte(`{"model":"old","apiKey":"sk-local","protocol":{"headers":{"a":"b"}}}`), 0600)
+	stripped.KeepSecrets(paths)
+	for _, want := range []string{`"gpt-4o"`, `"sk-local"`, `"a": "b"`, `"/v1"`} {
+		if !strings.Contains(string(stripped.Config), want) {
+			t.Errorf("config with local secrets lacks %s: %s", want, stripped.Config)
+		}
+	}
+}
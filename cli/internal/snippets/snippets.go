@@ -0,0 +1,186 @@
+// Package snippets expands short names typed in the chat input, like ";rev",
+// into longer text such as a code-review template. Snippets are defined in
+// .hacka/snippets.yaml files in the current directory, its parents and the
+// home directory; the closest definition of a name wins.
+package snippets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/yaml"
+)
+
+// File is the path of a snippets file relative to the directory it applies to
+var File = filepath.Join(".hacka", "snippets.yaml")
+
+// Trigger starts a snippet name in the input
+const Trigger = ";"
+
+// validName is what a snippet name may look like
+var validName = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+
+// Paths returns the snippets files that apply in dir, closest first: one in
+// dir and in each parent, then the one in the home directory. Files that
+// don't exist are left out.
+func Paths(dir string) []string {
+	var paths []string
+	seen := map[string]bool{}
+	add := func(dir string) {
+		path := filepath.Join(dir, File)
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		for d := abs; ; d = filepath.Dir(d) {
+			add(d)
+			if filepath.Dir(d) == d {
+				break
+			}
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		add(home)
+	}
+	return paths
+}
+
+// EditPath returns the file to edit from dir: the closest existing one, or a
+// new one in the home directory so the snippets apply everywhere
+func EditPath(dir string) string {
+	if paths := Paths(dir); len(paths) > 0 {
+		return paths[0]
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, File)
+	}
+	return File
+}
+
+// Load reads the snippets that apply in dir
+func Load(dir string) (map[string]string, error) {
+	snippets := map[string]string{}
+	paths := Paths(dir)
+	for i := len(paths) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(paths[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snippets: %w", err)
+		}
+		defined, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", paths[i], err)
+		}
+		for name, text := range defined {
+			snippets[name] = text
+		}
+	}
+	return snippets, nil
+}
+
+// Parse reads a snippets file: a mapping of names to text
+func Parse(data []byte) (map[string]string, error) {
+	tree, err := yaml.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid snippets: %w", err)
+	}
+	mapping, ok := tree.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("snippets must be a mapping of names to text")
+	}
+	snippets := make(map[string]string, len(mapping))
+	for name, value := range mapping {
+		if !validName.MatchString(name) {
+			return nil, fmt.Errorf("invalid snippet name %q: use letters, digits, - and _", name)
+		}
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("snippet %q must be text", name)
+		case nil:
+			snippets[name] = ""
+		default:
+			snippets[name] = strings.TrimRight(fmt.Sprint(value), "\n")
+		}
+	}
+	return snippets, nil
+}
+
+// Save validates content and writes it to path
+func Save(path, content string) error {
+	if _, err := Parse([]byte(content)); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create snippets directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write snippets: %w", err)
+	}
+	return nil
+}
+
+// Expand replaces the ";name" that ends at cursor (a rune offset into input)
+// with its snippet. It returns the new input and cursor, and the name that
+// was typed, or "" if there was no ";name" before the cursor. ok is false if
+// no such snippet is defined.
+func Expand(input string, cursor int, snippets map[string]string) (expanded string, newCursor int, name string, ok bool) {
+	runes := []rune(input)
+	if cursor < 0 || cursor > len(runes) {
+		return input, cursor, "", false
+	}
+	start := cursor
+	for start > 0 && validName.MatchString(string(runes[start-1])) {
+		start--
+	}
+	if start == cursor || start == 0 || string(runes[start-1]) != Trigger {
+		return input, cursor, "", false
+	}
+	// ";name" must start a word, so that text like "a;b" is left alone
+	if start >= 2 && !isSpace(runes[start-2]) {
+		return input, cursor, "", false
+	}
+	name = string(runes[start:cursor])
+	text, ok := snippets[name]
+	if !ok {
+		return input, cursor, name, false
+	}
+	replacement := []rune(text)
+	result := append(append(append([]rune{}, runes[:start-1]...), replacement...), runes[cursor:]...)
+	return string(result), start - 1 + len(replacement), name, true
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+// Names returns the snippet names, sorted
+func Names(snippets map[string]string) []string {
+	names := make([]string, 0, len(snippets))
+	for name := range snippets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Example is the content of a new snippets file
+const Example = `# hacka.re snippets: type ;name and press Tab in the chat input to expand it.
+rev: |
+  Review the code below for bugs, security issues and unclear naming.
+  List the problems by severity, each with a suggested fix.
+
+tldr: Summarize the above in three bullet points.
+
+triage: |
+  Triage this finding: what is affected, how severe is it (CVSS if you can),
+  is it exploitable as described, and what should be done first?
+`
@@ -0,0 +1,78 @@
+package snippets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseExample(t *testing.T) {
+	snippets, err := Parse([]byte(Example))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snippets) != 3 || snippets["tldr"] != "Summarize the above in three bullet points." {
+		t.Errorf("snippets = %q", snippets)
+	}
+	if _, err := Parse([]byte("bad name: x")); err == nil {
+		t.Error("Parse accepted a name with a space")
+	}
+	if _, err := Parse([]byte("rev:\n  nested: x")); err == nil {
+		t.Error("Parse accepted a mapping as snippet text")
+	}
+}
+
+func TestExpand(t *testing.T) {
+	snippets := map[string]string{"rev": "Review this:", "n": "1\n2"}
+	tests := []struct {
+		input  string
+		cursor int
+		want   string
+		at     int
+		ok     bool
+	}{
+		{";rev", 4, "Review this:", 12, true},
+		{"Please ;rev the diff", 11, "Please Review this: the diff", 19, true},
+		{";n", 2, "1\n2", 3, true},
+		{";nope", 5, ";nope", 5, false},
+		{"a;rev", 5, "a;rev", 5, false},
+		{"rev", 3, "rev", 3, false},
+	}
+	for _, tt := range tests {
+		got, at, _, ok := Expand(tt.input, tt.cursor, snippets)
+		if got != tt.want || at != tt.at || ok != tt.ok {
+			t.Errorf("Expand(%q, %d) = %q, %d, %v; want %q, %d, %v", tt.input, tt.cursor, got, at, ok, tt.want, tt.at, tt.ok)
+		}
+	}
+}
+
+func TestLoadClosestWins(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	project := filepath.Join(root, "project")
+	for dir, content := range map[string]string{
+		root:    "rev: home review\ntldr: home summary\n",
+		project: "rev: project review\n",
+	} {
+		if err := Save(filepath.Join(dir, File), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(project, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	snippets, err := Load(filepath.Join(project, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snippets["rev"] != "project review" || snippets["tldr"] != "home summary" {
+		t.Errorf("snippets = %q", snippets)
+	}
+	if got := EditPath(filepath.Join(project, "src")); got != filepath.Join(project, File) {
+		t.Errorf("EditPath = %q", got)
+	}
+	if err := Save(filepath.Join(project, File), "rev: [unclosed"); err == nil {
+		t.Error("Save wrote invalid snippets")
+	}
+}
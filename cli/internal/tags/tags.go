@@ -0,0 +1,83 @@
+// Package tags normalises and matches the tags attached to prompts and
+// functions, so the CLI, the TUI pages and share links agree on them.
+package tags
+
+import (
+	"sort"
+	"strings"
+)
+
+// Parse splits user input like "recon, osint #web" into normalised tags
+func Parse(text string) []string {
+	return Normalize(strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	}))
+}
+
+// Normalize lowercases tags, strips a leading '#', drops empty ones and
+// duplicates, and keeps the first-seen order
+func Normalize(tags []string) []string {
+	var normalized []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimLeft(strings.TrimSpace(tag), "#"))
+		tag = strings.Join(strings.Fields(tag), "-")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// Match reports whether have carries every tag in want; an empty want matches everything
+func Match(have, want []string) bool {
+	have = Normalize(have)
+	for _, tag := range Normalize(want) {
+		found := false
+		for _, h := range have {
+			if h == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Collect returns the sorted set of tags used across lists
+func Collect(lists ...[]string) []string {
+	var all []string
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+	all = Normalize(all)
+	sort.Strings(all)
+	return all
+}
+
+// Next returns the tag after current in available, cycling back to "" (no filter)
+func Next(available []string, current string) string {
+	for i, tag := range available {
+		if tag == current && i+1 < len(available) {
+			return available[i+1]
+		}
+	}
+	if current == "" && len(available) > 0 {
+		return available[0]
+	}
+	return ""
+}
+
+// String formats tags for display, e.g. "#recon #web"
+func String(tags []string) string {
+	tags = Normalize(tags)
+	for i := range tags {
+		tags[i] = "#" + tags[i]
+	}
+	return strings.Join(tags, " ")
+}
@@ -0,0 +1,39 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	got := Parse(" Recon, #osint  web,recon\tRed Team")
+	want := []string{"recon", "osint", "web", "red", "team"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %q, want %q", got, want)
+	}
+	if got := Normalize([]string{"Red Team", "#red-team"}); !reflect.DeepEqual(got, []string{"red-team"}) {
+		t.Errorf("Normalize() = %q", got)
+	}
+}
+
+func TestMatchAndCycle(t *testing.T) {
+	have := []string{"Recon", "web"}
+	if !Match(have, nil) || !Match(have, []string{"#recon"}) || Match(have, []string{"recon", "osint"}) {
+		t.Error("Match() gave the wrong result")
+	}
+
+	available := Collect([]string{"web", "recon"}, []string{"Recon", "crypto"})
+	if !reflect.DeepEqual(available, []string{"crypto", "recon", "web"}) {
+		t.Fatalf("Collect() = %q", available)
+	}
+	var seen []string
+	for tag := Next(available, ""); tag != ""; tag = Next(available, tag) {
+		seen = append(seen, tag)
+	}
+	if !reflect.DeepEqual(seen, available) {
+		t.Errorf("cycling visited %q", seen)
+	}
+	if got := String([]string{"web", "Recon"}); got != "#web #recon" {
+		t.Errorf("String() = %q", got)
+	}
+}
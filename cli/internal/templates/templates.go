@@ -0,0 +1,107 @@
+// Package templates provides ready-made starts for common kinds of
+// conversation. A template sets the system prompt, names the default
+// function groups to enable, may run at its own temperature and opens the
+// chat with a message from the assistant asking for what it needs.
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/sampling"
+)
+
+// Template is a conversation to start from
+type Template struct {
+	Name         string   // Used on the command line, e.g. threat-model
+	Title        string   // Shown in menus
+	Description  string   // One line on what it is for
+	SystemPrompt string   // Replaces the configured system prompt
+	Functions    []string // Default function groups to enable, e.g. math-utilities
+	Opening      string   // First message of the conversation, from the assistant
+
+	// Sampling replaces the configured temperature and top_p for the
+	// conversation, e.g. a low temperature for reports that must stick to facts
+	Sampling sampling.Override
+}
+
+var builtin = []Template{
+	{
+		Name:        "code-review",
+		Title:       "Code review",
+		Description: "Review a diff or file for bugs, security issues and readability",
+		SystemPrompt: `You are a senior software engineer reviewing code. Look for bugs, security
+issues, unclear naming, missing error handling and missing tests, in that
+order of importance. Quote the lines you comment on, explain why each point
+matters and suggest a concrete fix. Say so when the code is fine; don't
+invent problems. Keep style remarks short and separate from real issues.`,
+		Opening: "Paste the code or diff you'd like reviewed. It helps to know the language, what the change is meant to do and anything you're unsure about.",
+	},
+	{
+		Name:        "threat-model",
+		Title:       "Threat model",
+		Description: "Walk through a system with STRIDE and rank what could go wrong",
+		SystemPrompt: `You are a security architect helping to threat model a system. First make
+sure you understand its components, data flows, trust boundaries and the
+assets worth protecting; ask when something is unclear. Then go through the
+STRIDE categories (spoofing, tampering, repudiation, information disclosure,
+denial of service, elevation of privilege) for each boundary. List each
+threat with its likelihood, impact and a mitigation, most serious first.
+For a public domain, look up its certificates to find exposed hostnames.`,
+		Functions: []string{"osint-lookups"},
+		Opening:   "Describe the system you want to threat model: what it does, its main components and how data moves between them, who its users are and where it runs. A rough diagram in text is fine.",
+	},
+	{
+		Name:        "incident-report",
+		Title:       "Incident report",
+		Description: "Turn notes and logs from an incident into a blameless report",
+		SystemPrompt: `You are an incident responder writing a blameless post-incident report. From
+the notes, logs and timestamps you are given, build a timeline in UTC and
+write these sections: summary, impact, timeline, root cause, detection,
+response, and action items with owners. Keep facts and assumptions apart,
+and ask for what is missing rather than guessing. Use the epoch conversion
+function for timestamps in logs, and the breach check for accounts involved.`,
+		Functions: []string{"security-utilities", "osint-lookups", "url-reputation"},
+		Opening:   "Share what you have about the incident: when it started and ended, what users saw, alerts and log excerpts, and what was done to fix it. Rough notes in any order are fine; I'll ask about the gaps.",
+		Sampling:  sampling.Override{Temperature: sampling.Float(0.2)},
+	},
+	{
+		Name:        "ctf",
+		Title:       "CTF helper",
+		Description: "Work through a capture-the-flag challenge step by step",
+		SystemPrompt: `You are an experienced CTF player helping with a capture-the-flag challenge.
+Work step by step: identify the category (crypto, web, pwn, reversing,
+forensics, misc), form hypotheses, and suggest the next concrete thing to try
+with the exact commands or code. Prefer hints that teach over handing out the
+flag. Use the available functions for hashing, encodings, JWTs, RC4 and
+arithmetic rather than working them out by hand.`,
+		Functions: []string{"security-utilities", "rc4-encryption", "math-utilities"},
+		Opening:   "What's the challenge? Paste its description, any files or output you have, and what you've tried so far.",
+	},
+}
+
+// All returns the built-in templates
+func All() []Template {
+	all := make([]Template, len(builtin))
+	copy(all, builtin)
+	return all
+}
+
+// Names returns the names of the built-in templates
+func Names() []string {
+	names := make([]string, len(builtin))
+	for i, t := range builtin {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Get returns the template called name
+func Get(name string) (Template, error) {
+	for _, t := range builtin {
+		if t.Name == strings.ToLower(strings.TrimSpace(name)) {
+			return t, nil
+		}
+	}
+	return Template{}, fmt.Errorf("unknown template %q (available: %s)", name, strings.Join(Names(), ", "))
+}
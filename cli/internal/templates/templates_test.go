@@ -0,0 +1,44 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/jsruntime"
+)
+
+func TestBuiltin(t *testing.T) {
+	groups := map[string]bool{}
+	for _, group := range jsruntime.GetDefaultFunctionGroups() {
+		groups[group.ID] = true
+	}
+	seen := map[string]bool{}
+	for _, tmpl := range All() {
+		if seen[tmpl.Name] {
+			t.Errorf("template %s is defined twice", tmpl.Name)
+		}
+		seen[tmpl.Name] = true
+		if tmpl.Title == "" || tmpl.SystemPrompt == "" || tmpl.Opening == "" {
+			t.Errorf("template %s is incomplete", tmpl.Name)
+		}
+		if err := tmpl.Sampling.Validate(); err != nil {
+			t.Errorf("template %s: %v", tmpl.Name, err)
+		}
+		for _, id := range tmpl.Functions {
+			if !groups[id] {
+				t.Errorf("template %s enables unknown function group %s", tmpl.Name, id)
+			}
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	tmpl, err := Get(" Threat-Model ")
+	if err != nil || tmpl.Name != "threat-model" {
+		t.Errorf("Get = %+v, %v", tmpl, err)
+	}
+	_, err = Get("nope")
+	if err == nil || !strings.Contains(err.Error(), "code-review") {
+		t.Errorf("unknown template: %v, want the available names", err)
+	}
+}
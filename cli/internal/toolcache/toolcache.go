@@ -0,0 +1,205 @@
+// Package toolcache keeps the results of expensive tool calls on disk, keyed
+// by the tool and its arguments, until they expire. Arguments that differ only
+// in key order or spacing share an entry.
+package toolcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dir returns the cache directory. HACKARE_TOOL_CACHE_DIR overrides it.
+func Dir() string {
+	if dir := os.Getenv("HACKARE_TOOL_CACHE_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "hacka.re-tool-cache")
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "tool-cache")
+}
+
+// Entry is a cached result
+type Entry struct {
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Stored    time.Time       `json:"stored"`
+	Expires   time.Time       `json:"expires"`
+	Result    json.RawMessage `json:"result"`
+}
+
+// Stats describes what the cache holds
+type Stats struct {
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// Cache is a directory of entries, one file each
+type Cache struct {
+	dir string
+	now func() time.Time
+
+	mu sync.Mutex
+}
+
+// New returns the cache in dir; the directory is created on the first Put
+func New(dir string) *Cache {
+	return &Cache{dir: dir, now: time.Now}
+}
+
+// Key returns the key of a call: a hash of the tool and its arguments in
+// canonical form
+func Key(tool string, arguments json.RawMessage) string {
+	h := sha256.New()
+	h.Write([]byte(tool))
+	h.Write([]byte{0})
+	h.Write(canonical(arguments))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonical re-encodes JSON with sorted keys and no spacing; arguments that
+// aren't valid JSON are used as they are
+func canonical(arguments json.RawMessage) []byte {
+	if len(bytes.TrimSpace(arguments)) == 0 {
+		return nil
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(arguments))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return arguments
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return arguments
+	}
+	return encoded
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// Get returns the live entry for a call. Expired entries are removed.
+func (c *Cache) Get(tool string, arguments json.RawMessage) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(Key(tool, arguments))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Tool != tool {
+		return nil, false
+	}
+	if !c.now().Before(entry.Expires) {
+		os.Remove(path)
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Put stores the result of a call for ttl
+func (c *Cache) Put(tool string, arguments json.RawMessage, result interface{}, ttl time.Duration) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode tool result: %w", err)
+	}
+	now := c.now()
+	entry := Entry{
+		Tool:      tool,
+		Arguments: json.RawMessage(canonical(arguments)),
+		Stored:    now,
+		Expires:   now.Add(ttl),
+		Result:    encoded,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create tool cache: %w", err)
+	}
+	path := c.path(Key(tool, arguments))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write tool cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write tool cache: %w", err)
+	}
+	return nil
+}
+
+// each calls fn with the path and entry of every readable entry file
+func (c *Cache) each(fn func(path string, size int64, entry Entry)) error {
+	files, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read tool cache: %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(c.dir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var entry Entry
+		json.Unmarshal(data, &entry)
+		fn(path, int64(len(data)), entry)
+	}
+	return nil
+}
+
+// Clear removes the entries of tool, or all entries if tool is "", and
+// returns how many were removed
+func (c *Cache) Clear(tool string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	err := c.each(func(path string, size int64, entry Entry) {
+		if tool == "" || entry.Tool == tool {
+			if os.Remove(path) == nil {
+				removed++
+			}
+		}
+	})
+	return removed, err
+}
+
+// Stats counts the live entries, removing expired ones on the way
+func (c *Cache) Stats() (Stats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var stats Stats
+	now := c.now()
+	err := c.each(func(path string, size int64, entry Entry) {
+		if !now.Before(entry.Expires) {
+			os.Remove(path)
+			return
+		}
+		stats.Entries++
+		stats.Bytes += size
+	})
+	return stats, err
+}
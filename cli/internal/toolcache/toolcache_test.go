@@ -0,0 +1,75 @@
+package toolcache
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	a := Key("shodan_host_info", json.RawMessage(`{"ip": "1.1.1.1", "history": true}`))
+	b := Key("shodan_host_info", json.RawMessage(`{"history":true,"ip":"1.1.1.1"}`))
+	if a != b {
+		t.Error("the same arguments in a different order have different keys")
+	}
+	if a == Key("shodan_search", json.RawMessage(`{"history":true,"ip":"1.1.1.1"}`)) {
+		t.Error("different tools share a key")
+	}
+	if a == Key("shodan_host_info", json.RawMessage(`{"ip":"8.8.8.8","history":true}`)) {
+		t.Error("different arguments share a key")
+	}
+	if Key("tool", nil) != Key("tool", json.RawMessage(" ")) {
+		t.Error("missing and blank arguments have different keys")
+	}
+}
+
+func TestCache(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cache := New(t.TempDir())
+	cache.now = func() time.Time { return now }
+
+	args := json.RawMessage(`{"ip":"1.1.1.1"}`)
+	if _, ok := cache.Get("lookup", args); ok {
+		t.Fatal("an empty cache has an entry")
+	}
+	if err := cache.Put("lookup", args, []string{"result"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	cache.Put("search", args, "other", time.Hour)
+
+	entry, ok := cache.Get("lookup", json.RawMessage(`{ "ip" : "1.1.1.1" }`))
+	if !ok || string(entry.Result) != `["result"]` || !entry.Stored.Equal(now) {
+		t.Fatalf("Get = %+v, %v", entry, ok)
+	}
+	if stats, _ := cache.Stats(); stats.Entries != 2 || stats.Bytes == 0 {
+		t.Errorf("Stats = %+v", stats)
+	}
+
+	now = now.Add(time.Hour)
+	if _, ok := cache.Get("lookup", args); ok {
+		t.Error("an expired entry was returned")
+	}
+	if stats, _ := cache.Stats(); stats.Entries != 0 {
+		t.Errorf("expired entries were counted: %+v", stats)
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := New(t.TempDir())
+	if removed, err := cache.Clear(""); removed != 0 || err != nil {
+		t.Errorf("Clear of a missing directory = %d, %v", removed, err)
+	}
+	cache.Put("lookup", json.RawMessage(`{"ip":"1.1.1.1"}`), "a", time.Hour)
+	cache.Put("lookup", json.RawMessage(`{"ip":"8.8.8.8"}`), "b", time.Hour)
+	cache.Put("search", json.RawMessage(`{"q":"nginx"}`), "c", time.Hour)
+
+	if removed, _ := cache.Clear("lookup"); removed != 2 {
+		t.Errorf("Clear(lookup) removed %d", removed)
+	}
+	if _, ok := cache.Get("search", json.RawMessage(`{"q":"nginx"}`)); !ok {
+		t.Error("Clear(lookup) removed another tool's entry")
+	}
+	if removed, _ := cache.Clear(""); removed != 1 {
+		t.Errorf("Clear() removed %d", removed)
+	}
+}
@@ -0,0 +1,294 @@
+// Package toolpolicy limits the tool calls of the model: how many of a tool
+// run at once, how many start per minute, how long one may take, how much of
+// its output reaches the conversation and how long MCP servers may reuse its
+// result. Limits come from a policy file:
+//
+//	{
+//	  "default": {"maxConcurrent": 4, "timeoutSeconds": 60},
+//	  "tools": {
+//	    "shodan_*": {"perMinute": 2, "maxOutputBytes": 8192, "cacheSeconds": 3600},
+//	    "cve_lookup": {"maxConcurrent": 1, "perMinute": 5, "maxWaitSeconds": 30}
+//	  }
+//	}
+//
+// A name ending in * matches every tool with that prefix, and the tools it
+// matches share one limit. Calls over a limit queue until a slot frees up, for
+// at most MaxWait, and are refused after that.
+package toolpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Defaults for Limit fields left zero
+const (
+	DefaultMaxWait        = 2 * time.Minute
+	DefaultMaxOutputBytes = 32 * 1024
+)
+
+// Limit bounds the calls of a tool; zero fields don't limit, except the
+// output, which is always capped
+type Limit struct {
+	MaxConcurrent  int `json:"maxConcurrent,omitempty"`
+	PerMinute      int `json:"perMinute,omitempty"`
+	MaxWaitSeconds int `json:"maxWaitSeconds,omitempty"` // How long a call queues before it is refused
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"` // Wall-clock limit of one call; the tool's own limit still applies
+	MaxOutputBytes int `json:"maxOutputBytes,omitempty"` // Result bytes passed to the model
+	CacheSeconds   int `json:"cacheSeconds,omitempty"`   // How long an MCP server reuses a result for the same arguments
+}
+
+// MaxWait returns how long a call may queue
+func (l Limit) MaxWait() time.Duration {
+	if l.MaxWaitSeconds > 0 {
+		return time.Duration(l.MaxWaitSeconds) * time.Second
+	}
+	return DefaultMaxWait
+}
+
+// Timeout returns the wall-clock limit of a call, zero for none
+func (l Limit) Timeout() time.Duration {
+	return time.Duration(l.TimeoutSeconds) * time.Second
+}
+
+// CacheTTL returns how long a result may be reused, zero for not at all
+func (l Limit) CacheTTL() time.Duration {
+	return time.Duration(l.CacheSeconds) * time.Second
+}
+
+// MaxOutput returns how many bytes of a result reach the model
+func (l Limit) MaxOutput() int {
+	if l.MaxOutputBytes > 0 {
+		return l.MaxOutputBytes
+	}
+	return DefaultMaxOutputBytes
+}
+
+// negative reports whether a field is below zero
+func (l Limit) negative() bool {
+	return l.MaxConcurrent < 0 || l.PerMinute < 0 || l.MaxWaitSeconds < 0 || l.TimeoutSeconds < 0 || l.MaxOutputBytes < 0 || l.CacheSeconds < 0
+}
+
+// Policy is the contents of the policy file
+type Policy struct {
+	Default Limit            `json:"default"`         // Applies to each tool without its own entry
+	Tools   map[string]Limit `json:"tools,omitempty"` // By tool name or prefix*
+}
+
+// Path returns the policy file. HACKARE_TOOL_POLICY overrides it.
+func Path() string {
+	if path := os.Getenv("HACKARE_TOOL_POLICY"); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-tool-policy.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "tool-policy.json")
+}
+
+// Load reads a policy file; without one nothing is limited
+func Load(path string) (*Policy, error) {
+	policy := &Policy{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return policy, nil
+	}
+	if err != nil {
+		return policy, fmt.Errorf("failed to read tool policy: %w", err)
+	}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return &Policy{}, fmt.Errorf("failed to parse tool policy %s: %w", path, err)
+	}
+	if policy.Default.negative() {
+		return &Policy{}, fmt.Errorf("tool policy %s: negative default limit", path)
+	}
+	for key, limit := range policy.Tools {
+		if limit.negative() {
+			return &Policy{}, fmt.Errorf("tool policy %s: negative limit for %s", path, key)
+		}
+	}
+	return policy, nil
+}
+
+// LimitFor returns the limit of a tool and the key calls are counted under:
+// the tool's own entry, else the longest matching prefix*, else the default,
+// counted per tool
+func (p *Policy) LimitFor(name string) (Limit, string) {
+	if limit, ok := p.Tools[name]; ok {
+		return limit, name
+	}
+	best := ""
+	for key := range p.Tools {
+		prefix := strings.TrimSuffix(key, "*")
+		if prefix != key && strings.HasPrefix(name, prefix) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best != "" {
+		return p.Tools[best], best
+	}
+	return p.Default, name
+}
+
+// counter is the calls under one key
+type counter struct {
+	running  int
+	started  []time.Time   // Starts within the last minute, oldest first
+	released chan struct{} // Closed when a running call finishes
+}
+
+// Limiter applies a policy to calls as they happen. A nil *Limiter allows
+// everything.
+type Limiter struct {
+	policy *Policy
+
+	mu       sync.Mutex
+	counters map[string]*counter
+	now      func() time.Time
+}
+
+// NewLimiter returns a limiter for policy
+func NewLimiter(policy *Policy) *Limiter {
+	return &Limiter{policy: policy, counters: map[string]*counter{}, now: time.Now}
+}
+
+// Limit returns the limit of a tool; a nil *Limiter returns the zero Limit
+func (l *Limiter) Limit(name string) Limit {
+	if l == nil {
+		return Limit{}
+	}
+	limit, _ := l.policy.LimitFor(name)
+	return limit
+}
+
+// Acquire waits until the tool may run, and returns a function to call once
+// it has finished. If the call has to queue, queued is called once with the
+// reason. Calls still queued after the limit's MaxWait are refused.
+func (l *Limiter) Acquire(ctx context.Context, name string, queued func(reason string)) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	limit, key := l.policy.LimitFor(name)
+	if limit.MaxConcurrent == 0 && limit.PerMinute == 0 {
+		return func() {}, nil
+	}
+
+	deadline := time.NewTimer(limit.MaxWait())
+	defer deadline.Stop()
+	notified := false
+	for {
+		l.mu.Lock()
+		c := l.counter(key)
+		now := l.now()
+		for len(c.started) > 0 && now.Sub(c.started[0]) >= time.Minute {
+			c.started = c.started[1:]
+		}
+
+		var reason string
+		var retry <-chan time.Time
+		switch {
+		case limit.MaxConcurrent > 0 && c.running >= limit.MaxConcurrent:
+			reason = fmt.Sprintf("%d %s call(s) already running, the most allowed at once", c.running, key)
+		case limit.PerMinute > 0 && len(c.started) >= limit.PerMinute:
+			wait := c.started[0].Add(time.Minute).Sub(now)
+			reason = fmt.Sprintf("%s is limited to %d call(s) per minute, next slot in %s", key, limit.PerMinute, wait.Round(time.Second))
+			retry = time.After(wait)
+		default:
+			c.running++
+			c.started = append(c.started, now)
+			l.mu.Unlock()
+			return l.releaser(c), nil
+		}
+		released := c.released
+		l.mu.Unlock()
+
+		if !notified && queued != nil {
+			queued(reason)
+			notified = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return nil, fmt.Errorf("tool %s was refused after queuing for %s: %s", name, limit.MaxWait(), reason)
+		case <-released:
+		case <-retry:
+		}
+	}
+}
+
+// counter returns the counter of a key; l.mu must be held
+func (l *Limiter) counter(key string) *counter {
+	c, ok := l.counters[key]
+	if !ok {
+		c = &counter{released: make(chan struct{})}
+		l.counters[key] = c
+	}
+	return c
+}
+
+// releaser returns the function that ends a running call, waking the calls
+// queued behind it
+func (l *Limiter) releaser(c *counter) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			c.running--
+			close(c.released)
+			c.released = make(chan struct{})
+			l.mu.Unlock()
+		})
+	}
+}
+
+// Run calls fn and waits for it for at most timeout, or without limit if
+// timeout is zero. A call that times out keeps running in the background, so
+// fn should release whatever it holds itself; its result is dropped.
+func Run(ctx context.Context, timeout time.Duration, fn func() (interface{}, error)) (interface{}, error) {
+	type outcome struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := fn()
+		done <- outcome{result, err}
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-expired:
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// Truncate cuts output to at most max bytes, on a character boundary, and
+// marks how much was left out so the model knows the result is incomplete
+func Truncate(output string, max int) string {
+	if max <= 0 || len(output) <= max {
+		return output
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s\n[output truncated: %d of %d bytes shown]", output[:cut], cut, len(output))
+}
@@ -0,0 +1,144 @@
+package toolpolicy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool-policy.json")
+	if policy, err := Load(path); err != nil || len(policy.Tools) != 0 {
+		t.Errorf("missing file = %+v, %v", policy, err)
+	}
+
+	os.WriteFile(path, []byte(`{"default": {"maxConcurrent": 4}, "tools": {"shodan_*": {"perMinute": 2}, "shodan_host": {"maxConcurrent": 1}, "s*": {"perMinute": 9}}}`), 0600)
+	policy, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		tool, key string
+		limit     Limit
+	}{
+		{"shodan_host", "shodan_host", Limit{MaxConcurrent: 1}},
+		{"shodan_search", "shodan_*", Limit{PerMinute: 2}},
+		{"scan_list", "s*", Limit{PerMinute: 9}},
+		{"cve_lookup", "cve_lookup", Limit{MaxConcurrent: 4}},
+	} {
+		if limit, key := policy.LimitFor(tc.tool); limit != tc.limit || key != tc.key {
+			t.Errorf("LimitFor(%s) = %+v, %s", tc.tool, limit, key)
+		}
+	}
+
+	for _, bad := range []string{`{"tools": {"x": {"perMinute": -1}}}`, `{"default": {"timeoutSeconds": -5}}`, `{"tools": {"x": {"cacheSeconds": -1}}}`} {
+		os.WriteFile(path, []byte(bad), 0600)
+		if _, err := Load(path); err == nil {
+			t.Errorf("a negative limit was accepted: %s", bad)
+		}
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	l := NewLimiter(&Policy{Tools: map[string]Limit{"scan": {MaxConcurrent: 1}}})
+	release, err := l.Acquire(context.Background(), "scan", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reasons := make(chan string, 1)
+	acquired := make(chan error)
+	go func() {
+		second, err := l.Acquire(context.Background(), "scan", func(reason string) { reasons <- reason })
+		if err == nil {
+			second()
+		}
+		acquired <- err
+	}()
+	if reason := <-reasons; !strings.Contains(reason, "already running") {
+		t.Errorf("reason = %q", reason)
+	}
+	release()
+	release() // A second release is ignored
+	if err := <-acquired; err != nil {
+		t.Errorf("queued call = %v", err)
+	}
+
+	// Other tools aren't limited
+	if _, err := NewLimiter(&Policy{}).Acquire(context.Background(), "other", nil); err != nil {
+		t.Error(err)
+	}
+	var none *Limiter
+	if _, err := none.Acquire(context.Background(), "scan", nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter(&Policy{Tools: map[string]Limit{"shodan_*": {PerMinute: 2, MaxWaitSeconds: 1}}})
+	l.now = func() time.Time { return now }
+
+	for _, tool := range []string{"shodan_host", "shodan_search"} {
+		release, err := l.Acquire(context.Background(), tool, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+
+	var reason string
+	_, err := l.Acquire(context.Background(), "shodan_host", func(r string) { reason = r })
+	if err == nil || !strings.Contains(reason, "2 call(s) per minute, next slot in 1m0s") {
+		t.Errorf("third call: %v, reason %q", err, reason)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := l.Acquire(context.Background(), "shodan_host", nil); err != nil {
+		t.Errorf("call a minute later = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.Acquire(ctx, "shodan_host", nil)
+	if _, err := l.Acquire(ctx, "shodan_host", nil); err != context.Canceled {
+		t.Errorf("cancelled call = %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if result, err := Run(context.Background(), time.Second, func() (interface{}, error) { return "done", nil }); result != "done" || err != nil {
+		t.Errorf("fast call = %v, %v", result, err)
+	}
+
+	finished := make(chan struct{})
+	_, err := Run(context.Background(), 10*time.Millisecond, func() (interface{}, error) {
+		defer close(finished)
+		time.Sleep(50 * time.Millisecond)
+		return "late", nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 10ms") {
+		t.Errorf("slow call = %v", err)
+	}
+	<-finished // The abandoned call still finishes
+
+	if l := (*Limiter)(nil).Limit("scan"); l.Timeout() != 0 || l.MaxOutput() != DefaultMaxOutputBytes {
+		t.Errorf("nil limiter limit = %+v", l)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("short", 10); got != "short" {
+		t.Errorf("short output = %q", got)
+	}
+	if got := Truncate("0123456789", 4); got != "0123\n[output truncated: 4 of 10 bytes shown]" {
+		t.Errorf("long output = %q", got)
+	}
+	// "é" is two bytes and isn't split
+	if got := Truncate("aé b", 2); !strings.HasPrefix(got, "a\n[output truncated: 1 of 5") {
+		t.Errorf("multibyte output = %q", got)
+	}
+}
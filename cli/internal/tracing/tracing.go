@@ -0,0 +1,293 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// maxBufferedSpans triggers an export even if no trace has finished yet
+const maxBufferedSpans = 256
+
+// exportTimeout bounds a single OTLP export
+const exportTimeout = 5 * time.Second
+
+// Span is a timed operation within a trace. A nil *Span is valid and does nothing,
+// so callers don't need to check whether tracing is enabled.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	isRoot   bool
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    map[string]interface{}
+	err      error
+	mu       sync.Mutex
+}
+
+// exporter buffers finished spans and sends them to an OTLP/HTTP collector
+type exporter struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	client      *http.Client
+
+	mu      sync.Mutex
+	pending []*Span
+	wg      sync.WaitGroup
+}
+
+// active is the configured exporter, or nil when tracing is disabled
+var active *exporter
+
+type spanKey struct{}
+
+// InitFromEnv enables tracing if an OTLP endpoint is set in the standard
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT variables
+func InitFromEnv() {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+	if endpoint == "" {
+		return
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "hacka.re-cli"
+	}
+
+	Init(endpoint, serviceName, parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")))
+}
+
+// Init enables tracing with spans exported to the given OTLP/HTTP traces endpoint
+func Init(endpoint, serviceName string, headers map[string]string) {
+	active = &exporter{
+		endpoint:    endpoint,
+		headers:     headers,
+		serviceName: serviceName,
+		client:      netguard.Client(exportTimeout),
+	}
+	logger.Get().Info("[Tracing] Exporting traces to %s as %s", endpoint, serviceName)
+}
+
+// Enabled reports whether tracing is configured
+func Enabled() bool {
+	return active != nil
+}
+
+// Shutdown exports any buffered spans and waits for pending exports
+func Shutdown() {
+	if active == nil {
+		return
+	}
+	active.flush()
+	active.wg.Wait()
+}
+
+// Start begins a span as a child of the span in ctx, if any
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	if active == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		name:  name,
+		start: time.Now(),
+		attrs: make(map[string]interface{}),
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok && parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		rand.Read(span.traceID[:])
+		span.isRoot = true
+	}
+	rand.Read(span.spanID[:])
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttribute records a string, bool, int or float64 attribute on the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs[key] = value
+	s.mu.Unlock()
+}
+
+// End finishes the span, marking it failed if err is non-nil
+func (s *Span) End(err error) {
+	if s == nil || active == nil {
+		return
+	}
+	s.mu.Lock()
+	s.end = time.Now()
+	s.err = err
+	s.mu.Unlock()
+
+	active.add(s)
+}
+
+// add buffers a finished span, exporting when a trace completes
+func (e *exporter) add(span *Span) {
+	e.mu.Lock()
+	e.pending = append(e.pending, span)
+	ready := span.isRoot || len(e.pending) >= maxBufferedSpans
+	e.mu.Unlock()
+
+	if ready {
+		e.flush()
+	}
+}
+
+// flush exports all buffered spans in the background
+func (e *exporter) flush() {
+	e.mu.Lock()
+	spans := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	if len(spans) == 0 {
+		return
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		if err := e.export(spans); err != nil {
+			logger.Get().Warn("[Tracing] Failed to export %d spans: %v", len(spans), err)
+		}
+	}()
+}
+
+// export posts spans to the collector using the OTLP/HTTP JSON encoding
+func (e *exporter) export(spans []*Span) error {
+	body, err := json.Marshal(e.encode(spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encode builds the ExportTraceServiceRequest JSON structure
+func (e *exporter) encode(spans []*Span) map[string]interface{} {
+	encoded := make([]map[string]interface{}, 0, len(spans))
+	for _, span := range spans {
+		encoded = append(encoded, encodeSpan(span))
+	}
+
+	return map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": encodeAttributes(map[string]interface{}{"service.name": e.serviceName}),
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "github.com/hacka-re/cli"},
+						"spans": encoded,
+					},
+				},
+			},
+		},
+	}
+}
+
+// encodeSpan converts a span to its OTLP JSON form
+func encodeSpan(span *Span) map[string]interface{} {
+	span.mu.Lock()
+	defer span.mu.Unlock()
+
+	result := map[string]interface{}{
+		"traceId":           hex.EncodeToString(span.traceID[:]),
+		"spanId":            hex.EncodeToString(span.spanID[:]),
+		"name":              span.name,
+		"kind":              1, // SPAN_KIND_INTERNAL
+		"startTimeUnixNano": strconv.FormatInt(span.start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(span.end.UnixNano(), 10),
+		"attributes":        encodeAttributes(span.attrs),
+	}
+	if !span.isRoot {
+		result["parentSpanId"] = hex.EncodeToString(span.parentID[:])
+	}
+	if span.err != nil {
+		result["status"] = map[string]interface{}{"code": 2, "message": span.err.Error()} // STATUS_CODE_ERROR
+	} else {
+		result["status"] = map[string]interface{}{"code": 1} // STATUS_CODE_OK
+	}
+	return result
+}
+
+// encodeAttributes converts attributes to OTLP KeyValue pairs
+func encodeAttributes(attrs map[string]interface{}) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(attrs))
+	for key, value := range attrs {
+		var encoded map[string]interface{}
+		switch v := value.(type) {
+		case string:
+			encoded = map[string]interface{}{"stringValue": v}
+		case bool:
+			encoded = map[string]interface{}{"boolValue": v}
+		case int:
+			encoded = map[string]interface{}{"intValue": strconv.Itoa(v)}
+		case float64:
+			encoded = map[string]interface{}{"doubleValue": v}
+		default:
+			encoded = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		result = append(result, map[string]interface{}{"key": key, "value": encoded})
+	}
+	return result
+}
+
+// parseHeaders parses the "key=value,key2=value2" format of OTEL_EXPORTER_OTLP_HEADERS
+func parseHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		name, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	}
+	return headers
+}
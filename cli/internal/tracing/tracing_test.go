@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestDisabledSpansAreNoops(t *testing.T) {
+	active = nil
+
+	ctx, span := Start(context.Background(), "noop")
+	if span != nil {
+		t.Fatal("expected nil span when tracing is disabled")
+	}
+	span.SetAttribute("key", "value")
+	span.End(nil)
+	if ctx == nil {
+		t.Fatal("expected context to be returned")
+	}
+}
+
+func TestExportNestedSpans(t *testing.T) {
+	var mu sync.Mutex
+	var received []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid JSON payload: %v", err)
+		}
+		if r.Header.Get("X-Token") != "secret" {
+			t.Errorf("expected custom header to be sent")
+		}
+		mu.Lock()
+		received = append(received, payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	Init(server.URL, "test-service", map[string]string{"X-Token": "secret"})
+	defer func() { active = nil }()
+
+	ctx, root := Start(context.Background(), "chat.completion")
+	_, child := Start(ctx, "provider.call")
+	child.SetAttribute("http.status_code", 200)
+	child.End(errors.New("boom"))
+	root.End(nil)
+	Shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("expected one export, got %d", len(received))
+	}
+
+	resourceSpans := received[0]["resourceSpans"].([]interface{})
+	scopeSpans := resourceSpans[0].(map[string]interface{})["scopeSpans"].([]interface{})
+	spans := scopeSpans[0].(map[string]interface{})["spans"].([]interface{})
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	childSpan := spans[0].(map[string]interface{})
+	rootSpan := spans[1].(map[string]interface{})
+	if childSpan["traceId"] != rootSpan["traceId"] {
+		t.Error("expected child to share the root trace ID")
+	}
+	if childSpan["parentSpanId"] != rootSpan["spanId"] {
+		t.Error("expected child parent to be the root span")
+	}
+	if status := childSpan["status"].(map[string]interface{}); status["code"].(float64) != 2 {
+		t.Errorf("expected error status on child, got %v", status)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers := parseHeaders("api-key=abc, x-tenant = demo,invalid")
+	if headers["api-key"] != "abc" || headers["x-tenant"] != "demo" || len(headers) != 2 {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+}
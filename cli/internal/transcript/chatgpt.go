@@ -0,0 +1,106 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// chatGPTConversation is one entry of conversations.json in a ChatGPT data export.
+// Messages form a tree (edits and regenerations branch); current_node is the
+// leaf of the branch the user last saw.
+type chatGPTConversation struct {
+	Title       string                 `json:"title"`
+	CreateTime  float64                `json:"create_time"`
+	CurrentNode string                 `json:"current_node"`
+	Mapping     map[string]chatGPTNode `json:"mapping"`
+}
+
+type chatGPTNode struct {
+	Parent  string          `json:"parent"`
+	Message *chatGPTMessage `json:"message"`
+}
+
+type chatGPTMessage struct {
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	Content struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+	} `json:"content"`
+	Metadata struct {
+		ModelSlug        string `json:"model_slug"`
+		IsVisuallyHidden bool   `json:"is_visually_hidden_from_conversation"`
+	} `json:"metadata"`
+}
+
+// importChatGPT reads the conversations of a ChatGPT export
+func importChatGPT(data []byte) ([]Conversation, error) {
+	var exported []chatGPTConversation
+	if err := json.Unmarshal(data, &exported); err != nil {
+		return nil, fmt.Errorf("failed to parse ChatGPT export: %w", err)
+	}
+
+	conversations := make([]Conversation, 0, len(exported))
+	for _, source := range exported {
+		conversation := Conversation{
+			Title:  source.Title,
+			Source: FormatChatGPT,
+		}
+		if source.CreateTime > 0 {
+			conversation.CreatedAt = time.Unix(int64(source.CreateTime), 0)
+		}
+
+		for _, message := range source.branch() {
+			role := normalizeRole(message.Author.Role)
+			content := message.text()
+			if role == "" || content == "" || message.Metadata.IsVisuallyHidden {
+				continue
+			}
+			if message.Metadata.ModelSlug != "" {
+				conversation.Model = message.Metadata.ModelSlug
+			}
+			conversation.Messages = append(conversation.Messages, api.Message{Role: role, Content: content})
+		}
+		conversations = append(conversations, conversation)
+	}
+	return conversations, nil
+}
+
+// branch returns the messages from the root to current_node, in order
+func (c chatGPTConversation) branch() []*chatGPTMessage {
+	var messages []*chatGPTMessage
+	seen := make(map[string]bool)
+	for id := c.CurrentNode; id != "" && !seen[id]; {
+		seen[id] = true
+		node, ok := c.Mapping[id]
+		if !ok {
+			break
+		}
+		if node.Message != nil {
+			messages = append(messages, node.Message)
+		}
+		id = node.Parent
+	}
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages
+}
+
+// text joins the text parts of a message; images and other attachments are skipped
+func (m *chatGPTMessage) text() string {
+	var texts []string
+	for _, part := range m.Content.Parts {
+		var text string
+		if err := json.Unmarshal(part, &text); err == nil && strings.TrimSpace(text) != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
@@ -0,0 +1,53 @@
+package transcript
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// EvalExample is one line of an eval dataset: an annotated assistant reply
+// and the conversation that led to it
+type EvalExample struct {
+	Model      string        `json:"model,omitempty"`
+	Messages   []EvalMessage `json:"messages"` // Everything before the reply
+	Completion string        `json:"completion"`
+	Rating     string        `json:"rating,omitempty"` // up or down
+	Note       string        `json:"note,omitempty"`
+}
+
+// EvalMessage is a message of an EvalExample's prompt
+type EvalMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// WriteEvalJSONL writes an EvalExample per annotated assistant message, one
+// JSON object per line, and returns how many were written
+func WriteEvalJSONL(w io.Writer, model string, messages []api.Message) (int, error) {
+	encoder := json.NewEncoder(w)
+	written := 0
+	for i, msg := range messages {
+		if msg.Role != "assistant" || msg.Annotation.IsEmpty() {
+			continue
+		}
+		example := EvalExample{
+			Model:      model,
+			Messages:   []EvalMessage{},
+			Completion: msg.Content,
+			Rating:     msg.Annotation.Rating,
+			Note:       msg.Annotation.Note,
+		}
+		for _, previous := range messages[:i] {
+			if previous.Content != "" {
+				example.Messages = append(example.Messages, EvalMessage{Role: previous.Role, Content: previous.Content})
+			}
+		}
+		if err := encoder.Encode(example); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
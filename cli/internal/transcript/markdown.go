@@ -0,0 +1,123 @@
+package transcript
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// Speaker markers recognised in markdown and plain text transcripts:
+//
+//	## User            (heading, message follows on the next lines)
+//	**Assistant:** hi  (bold prefix)
+//	User: hi           (plain prefix)
+var (
+	headingMarker = regexp.MustCompile(`^#{1,6}\s*([A-Za-z]+)\s*:?\s*$`)
+	boldMarker    = regexp.MustCompile(`^\*\*([A-Za-z]+)\s*:?\*\*\s*:?\s*(.*)$`)
+	prefixMarker  = regexp.MustCompile(`^([A-Za-z]+):\s*(.*)$`)
+	titleHeading  = regexp.MustCompile(`^#\s+(.+)$`)
+)
+
+// reviewMarker is the line WriteMarkdown adds after an annotated message:
+//
+//	> **Review:** 👍 clear and correct
+var reviewMarker = regexp.MustCompile(`^>\s*\*\*Review:\*\*\s*(👍|👎)?\s*(.*)$`)
+
+// importMarkdown reads a transcript where each message starts with a speaker marker
+func importMarkdown(name string, data []byte) ([]Conversation, error) {
+	conversation := Conversation{Title: strings.TrimSuffix(name, ".md"), Source: FormatMarkdown}
+
+	var role string
+	var body []string
+	var annotation *api.Annotation
+	flush := func() {
+		content := strings.TrimSpace(strings.Join(body, "\n"))
+		if role != "" && content != "" {
+			conversation.Messages = append(conversation.Messages, api.Message{Role: role, Content: content, Annotation: annotation})
+		}
+		body = nil
+		annotation = nil
+	}
+
+	inCode := false
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+		}
+
+		// Markers inside code blocks are message content
+		if !inCode {
+			if speaker, rest, ok := matchSpeaker(trimmed); ok {
+				flush()
+				role = speaker
+				if rest != "" {
+					body = append(body, rest)
+				}
+				continue
+			}
+			if m := reviewMarker.FindStringSubmatch(trimmed); m != nil && role != "" {
+				annotation = &api.Annotation{Note: strings.TrimSpace(m[2])}
+				annotation.Rating, _ = api.ParseRating(m[1])
+				continue
+			}
+			if role == "" {
+				if m := titleHeading.FindStringSubmatch(trimmed); m != nil {
+					conversation.Title = m[1]
+				}
+				continue
+			}
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	return []Conversation{conversation}, nil
+}
+
+// matchSpeaker returns the role and any text after the marker if line starts a message
+func matchSpeaker(line string) (role string, rest string, ok bool) {
+	if m := headingMarker.FindStringSubmatch(line); m != nil {
+		if role := normalizeRole(m[1]); role != "" {
+			return role, "", true
+		}
+	}
+	if m := boldMarker.FindStringSubmatch(line); m != nil {
+		if role := normalizeRole(m[1]); role != "" {
+			return role, m[2], true
+		}
+	}
+	if m := prefixMarker.FindStringSubmatch(line); m != nil {
+		if role := normalizeRole(m[1]); role != "" {
+			return role, m[2], true
+		}
+	}
+	return "", "", false
+}
+
+// WriteMarkdown writes messages as a markdown transcript that importMarkdown reads back
+func WriteMarkdown(w io.Writer, title string, messages []api.Message) error {
+	if title != "" {
+		if _, err := fmt.Fprintf(w, "# %s\n\n", title); err != nil {
+			return err
+		}
+	}
+	for _, msg := range messages {
+		if msg.Content == "" || msg.Role == "" {
+			continue
+		}
+		heading := strings.ToUpper(msg.Role[:1]) + msg.Role[1:]
+		if _, err := fmt.Fprintf(w, "## %s\n\n%s\n\n", heading, strings.TrimSpace(msg.Content)); err != nil {
+			return err
+		}
+		if !msg.Annotation.IsEmpty() {
+			if _, err := fmt.Fprintf(w, "> **Review:** %s\n\n", msg.Annotation); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
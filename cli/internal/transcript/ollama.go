@@ -0,0 +1,108 @@
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// isOllamaModelfile reports whether data looks like a Modelfile saved by ollama's /save
+func isOllamaModelfile(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword := strings.ToUpper(strings.Fields(line)[0])
+		return keyword == "FROM" || keyword == "MESSAGE"
+	}
+	return false
+}
+
+// importOllamaModelfile reads the SYSTEM and MESSAGE instructions of a Modelfile
+func importOllamaModelfile(name string, data []byte) ([]Conversation, error) {
+	conversation := Conversation{Title: name, Source: FormatOllama}
+
+	for _, instruction := range modelfileInstructions(data) {
+		switch instruction.keyword {
+		case "FROM":
+			conversation.Model = instruction.args
+		case "SYSTEM":
+			conversation.Messages = append(conversation.Messages, api.Message{Role: "system", Content: instruction.args})
+		case "MESSAGE":
+			roleName, content, _ := strings.Cut(instruction.args, " ")
+			role := normalizeRole(roleName)
+			content = strings.TrimSpace(content)
+			if role != "" && content != "" {
+				conversation.Messages = append(conversation.Messages, api.Message{Role: role, Content: content})
+			}
+		}
+	}
+	return []Conversation{conversation}, nil
+}
+
+// modelfileInstruction is one KEYWORD args instruction
+type modelfileInstruction struct {
+	keyword string
+	args    string
+}
+
+// modelfileInstructions splits a Modelfile into instructions, joining """-quoted multi-line arguments
+func modelfileInstructions(data []byte) []modelfileInstruction {
+	var instructions []modelfileInstruction
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keyword, args, _ := strings.Cut(line, " ")
+		args = strings.TrimSpace(args)
+
+		// MESSAGE user """ spans lines until the closing """
+		if start := strings.Index(args, `"""`); start >= 0 {
+			rest := args[start+3:]
+			if end := strings.Index(rest, `"""`); end >= 0 {
+				args = args[:start] + rest[:end]
+			} else {
+				body := []string{rest}
+				for i++; i < len(lines); i++ {
+					if end := strings.Index(lines[i], `"""`); end >= 0 {
+						body = append(body, lines[i][:end])
+						break
+					}
+					body = append(body, lines[i])
+				}
+				args = args[:start] + strings.Join(body, "\n")
+			}
+		} else {
+			args = strings.Trim(args, `"`)
+		}
+
+		instructions = append(instructions, modelfileInstruction{
+			keyword: strings.ToUpper(keyword),
+			args:    strings.TrimSpace(args),
+		})
+	}
+	return instructions
+}
+
+// importOllamaHistory reads ~/.ollama/history, which only records the prompts typed
+// into `ollama run`; they are imported as user messages without replies
+func importOllamaHistory(data []byte) ([]Conversation, error) {
+	conversation := Conversation{Title: "ollama history", Source: FormatOllama}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// Skip REPL commands such as /bye and /set
+		if line == "" || strings.HasPrefix(line, "/") {
+			continue
+		}
+		conversation.Messages = append(conversation.Messages, api.Message{Role: "user", Content: line})
+	}
+	return []Conversation{conversation}, scanner.Err()
+}
@@ -0,0 +1,234 @@
+// Package transcript imports conversations from other chat tools so they can
+// be continued in hacka.re.
+//
+// Supported formats:
+//   - ChatGPT data export: the .zip, or the conversations.json inside it
+//   - OpenAI-style message JSON: [{"role": ..., "content": ...}] or {"messages": [...]}
+//   - ollama: Modelfiles written by /save (MESSAGE lines) and the ~/.ollama/history prompt log
+//   - Markdown or plain text transcripts with User/Assistant headings or prefixes
+package transcript
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+// Format names the source format of an import
+type Format string
+
+const (
+	FormatChatGPT  Format = "chatgpt"
+	FormatMessages Format = "messages"
+	FormatOllama   Format = "ollama"
+	FormatMarkdown Format = "markdown"
+)
+
+// maxImportSize guards against loading a wrong, huge file into memory
+const maxImportSize = 200 << 20
+
+// ErrNoConversations is returned when a file contains nothing that can be imported
+var ErrNoConversations = errors.New("no conversations found")
+
+// Conversation is an imported chat, ready to be used as chat history
+type Conversation struct {
+	Title     string
+	Model     string    // Empty if the source doesn't record it
+	CreatedAt time.Time // Zero if the source doesn't record it
+	Source    Format
+	Messages  []api.Message
+}
+
+// ImportFile reads conversations from path, detecting the format from its name and contents.
+// Conversations are returned newest first when the source has timestamps.
+func ImportFile(path string) ([]Conversation, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxImportSize {
+		return nil, fmt.Errorf("%s is too large to import (%d MB)", path, info.Size()>>20)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		return importZip(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Import(filepath.Base(path), data)
+}
+
+// Import parses data, using name (a file name, may be empty) as a format hint
+func Import(name string, data []byte) ([]Conversation, error) {
+	var conversations []Conversation
+	var err error
+
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{'):
+		conversations, err = importJSON(trimmed)
+	case isOllamaModelfile(trimmed):
+		conversations, err = importOllamaModelfile(name, trimmed)
+	case strings.EqualFold(name, "history"):
+		conversations, err = importOllamaHistory(trimmed)
+	default:
+		conversations, err = importMarkdown(name, trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop conversations with nothing to continue
+	kept := conversations[:0]
+	for _, conversation := range conversations {
+		if len(conversation.Messages) > 0 {
+			kept = append(kept, conversation)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, ErrNoConversations
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].CreatedAt.After(kept[j].CreatedAt)
+	})
+	return kept, nil
+}
+
+// importZip reads conversations.json from a ChatGPT data export
+func importZip(path string) ([]Conversation, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if filepath.Base(file.Name) != "conversations.json" {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(reader, maxImportSize))
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		return Import(file.Name, data)
+	}
+	return nil, fmt.Errorf("%s has no conversations.json; is it a ChatGPT data export?", path)
+}
+
+// importJSON handles ChatGPT exports and OpenAI-style message lists
+func importJSON(data []byte) ([]Conversation, error) {
+	if data[0] == '[' {
+		var probe []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		if len(probe) > 0 {
+			if _, ok := probe[0]["mapping"]; ok {
+				return importChatGPT(data)
+			}
+		}
+		return importMessages(data)
+	}
+
+	var object struct {
+		Title    string          `json:"title"`
+		Model    string          `json:"model"`
+		Messages json.RawMessage `json:"messages"`
+		Mapping  json.RawMessage `json:"mapping"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if object.Mapping != nil {
+		// A single ChatGPT conversation
+		return importChatGPT(append(append([]byte("["), data...), ']'))
+	}
+	if object.Messages == nil {
+		return nil, ErrNoConversations
+	}
+
+	conversations, err := importMessages(object.Messages)
+	if err != nil {
+		return nil, err
+	}
+	conversations[0].Title = object.Title
+	conversations[0].Model = object.Model
+	return conversations, nil
+}
+
+// importMessages reads an OpenAI-style [{"role", "content"}] list as one conversation
+func importMessages(data []byte) ([]Conversation, error) {
+	var raw []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse messages: %w", err)
+	}
+
+	conversation := Conversation{Source: FormatMessages}
+	for _, msg := range raw {
+		role := normalizeRole(msg.Role)
+		content := contentText(msg.Content)
+		if role == "" || content == "" {
+			continue
+		}
+		conversation.Messages = append(conversation.Messages, api.Message{Role: role, Content: content})
+	}
+	return []Conversation{conversation}, nil
+}
+
+// contentText extracts text from a string content or a list of content parts
+func contentText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
+
+// normalizeRole maps the role names used by other tools to chat API roles ("" to skip)
+func normalizeRole(role string) string {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case "user", "human", "you":
+		return "user"
+	case "assistant", "ai", "chatgpt", "claude":
+		return "assistant"
+	case "system":
+		return "system"
+	}
+	return ""
+}
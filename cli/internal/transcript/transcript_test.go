@@ -0,0 +1,198 @@
+package transcript
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/api"
+)
+
+const chatGPTExport = `[{
+	"title": "Port scan help",
+	"create_time": 1700000000.5,
+	"current_node": "c",
+	"mapping": {
+		"root": {"parent": null, "message": null},
+		"s": {"parent": "root", "message": {"author": {"role": "system"}, "content": {"content_type": "text", "parts": [""]}, "metadata": {"is_visually_hidden_from_conversation": true}}},
+		"a": {"parent": "s", "message": {"author": {"role": "user"}, "content": {"content_type": "text", "parts": ["How do I scan a /24?"]}}},
+		"b-old": {"parent": "a", "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["discarded regeneration"]}}},
+		"b": {"parent": "a", "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["Use nmap -sn 10.0.0.0/24"]}, "metadata": {"model_slug": "gpt-4o"}}},
+		"c": {"parent": "b", "message": {"author": {"role": "user"}, "content": {"content_type": "multimodal_text", "parts": [{"asset_pointer": "file-1"}, "What about UDP?"]}}}
+	}
+}]`
+
+func TestImportChatGPT(t *testing.T) {
+	conversations, err := Import("conversations.json", []byte(chatGPTExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conversations) != 1 {
+		t.Fatalf("got %d conversations", len(conversations))
+	}
+	c := conversations[0]
+	if c.Title != "Port scan help" || c.Model != "gpt-4o" || c.Source != FormatChatGPT {
+		t.Fatalf("unexpected conversation %+v", c)
+	}
+	want := []string{"user:How do I scan a /24?", "assistant:Use nmap -sn 10.0.0.0/24", "user:What about UDP?"}
+	if len(c.Messages) != len(want) {
+		t.Fatalf("got %d messages: %+v", len(c.Messages), c.Messages)
+	}
+	for i, msg := range c.Messages {
+		if msg.Role+":"+msg.Content != want[i] {
+			t.Errorf("message %d = %s:%s, want %s", i, msg.Role, msg.Content, want[i])
+		}
+	}
+}
+
+func TestImportChatGPTZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(file)
+	w, _ := archive.Create("conversations.json")
+	w.Write([]byte(chatGPTExport))
+	archive.Close()
+	file.Close()
+
+	conversations, err := ImportFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conversations) != 1 || len(conversations[0].Messages) != 3 {
+		t.Fatalf("unexpected import %+v", conversations)
+	}
+}
+
+func TestImportMessages(t *testing.T) {
+	data := `{"model": "llama3", "messages": [
+		{"role": "system", "content": "Be brief."},
+		{"role": "user", "content": [{"type": "text", "text": "hi"}]},
+		{"role": "assistant", "content": "hello"},
+		{"role": "tool", "content": "ignored"}
+	]}`
+	conversations, err := Import("chat.json", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := conversations[0]
+	if c.Model != "llama3" || len(c.Messages) != 3 || c.Messages[1].Content != "hi" {
+		t.Fatalf("unexpected conversation %+v", c)
+	}
+}
+
+func TestImportOllamaModelfile(t *testing.T) {
+	data := "FROM llama3.1:latest\nSYSTEM \"\"\"You are a pentester.\"\"\"\nMESSAGE user what is SSRF?\nMESSAGE assistant \"\"\"Server-side request forgery.\n\nIt lets attackers...\"\"\"\n"
+	conversations, err := Import("mymodel", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := conversations[0]
+	if c.Model != "llama3.1:latest" || len(c.Messages) != 3 {
+		t.Fatalf("unexpected conversation %+v", c)
+	}
+	if c.Messages[2].Content != "Server-side request forgery.\n\nIt lets attackers..." {
+		t.Fatalf("multi-line message = %q", c.Messages[2].Content)
+	}
+}
+
+func TestImportOllamaHistory(t *testing.T) {
+	conversations, err := Import("history", []byte("first question\n/bye\nsecond question\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conversations[0].Messages) != 2 || conversations[0].Messages[1].Content != "second question" {
+		t.Fatalf("unexpected conversation %+v", conversations[0])
+	}
+}
+
+func TestImportMarkdown(t *testing.T) {
+	data := "# Recon notes\n\n## User\nList open ports\n\n## Assistant\nRun:\n\n```\nUser: not a marker\n```\n\n**User:** thanks\nAssistant: you're welcome\n"
+	conversations, err := Import("notes.md", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := conversations[0]
+	if c.Title != "Recon notes" || len(c.Messages) != 4 {
+		t.Fatalf("unexpected conversation %+v", c)
+	}
+	if c.Messages[1].Content != "Run:\n\n```\nUser: not a marker\n```" {
+		t.Fatalf("code block content = %q", c.Messages[1].Content)
+	}
+	if c.Messages[2].Content != "thanks" || c.Messages[3].Role != "assistant" {
+		t.Fatalf("unexpected messages %+v", c.Messages)
+	}
+}
+
+func TestWriteMarkdownRoundTrip(t *testing.T) {
+	messages := []api.Message{
+		{Role: "system", Content: "Be terse"},
+		{Role: "user", Content: "Scan 10.0.0.1"},
+		{Role: "assistant", Content: "Done:\n\n```\nUser: not a marker\n```"},
+	}
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, "Recon", messages); err != nil {
+		t.Fatal(err)
+	}
+	conversations, err := Import("export.md", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := conversations[0]; c.Title != "Recon" || !reflect.DeepEqual(c.Messages, messages) {
+		t.Fatalf("round trip = %+v", c)
+	}
+}
+
+func TestAnnotationsInExports(t *testing.T) {
+	messages := []api.Message{
+		{Role: "user", Content: "Is port 22 open?"},
+		{Role: "assistant", Content: "Yes", Annotation: &api.Annotation{Rating: api.RatingUp, Note: "checked with nmap"}},
+		{Role: "user", Content: "And 23?"},
+		{Role: "assistant", Content: "Maybe", Annotation: &api.Annotation{Rating: api.RatingDown}},
+		{Role: "user", Content: "ok"},
+		{Role: "assistant", Content: "Anything else?"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, "", messages); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "> **Review:** 👍 checked with nmap") {
+		t.Errorf("markdown has no review line:\n%s", buf.String())
+	}
+	conversations, err := Import("review.md", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(conversations[0].Messages, messages) {
+		t.Errorf("annotations did not survive the round trip: %+v", conversations[0].Messages)
+	}
+
+	buf.Reset()
+	n, err := WriteEvalJSONL(&buf, "gpt-4o", messages)
+	if err != nil || n != 2 {
+		t.Fatalf("WriteEvalJSONL = %d, %v", n, err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var second EvalExample
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if second.Completion != "Maybe" || second.Rating != api.RatingDown || len(second.Messages) != 3 || second.Model != "gpt-4o" {
+		t.Errorf("second example = %+v", second)
+	}
+}
+
+func TestImportNothing(t *testing.T) {
+	if _, err := Import("empty.md", []byte("just some text\n")); !errors.Is(err, ErrNoConversations) {
+		t.Fatalf("expected ErrNoConversations, got %v", err)
+	}
+}
@@ -10,9 +10,13 @@ import (
 	"github.com/hacka-re/cli/internal/tui/internal/core"
 	"github.com/hacka-re/cli/internal/tui/internal/modes/socket"
 	"github.com/hacka-re/cli/internal/tui/internal/transport"
+	"github.com/hacka-re/cli/internal/utils"
 )
 
 func main() {
+	// Never leave the terminal in raw or alternate-screen mode on a crash
+	defer utils.RecoverPanic()
+
 	// Parse command line flags
 	var (
 		mode       = flag.String("mode", "auto", "UI mode: rich, socket, or auto")
@@ -30,8 +34,10 @@ func main() {
 	// Initialize configuration
 	configManager, err := core.NewConfigManager()
 	if err != nil {
-		log.Fatalf("Failed to initialize config: %v", err)
+		utils.Fatalf("Failed to initialize config: %v", err)
 	}
+	// Config changes are written behind; make sure they reach disk on any exit path
+	defer utils.OnExit(func() { configManager.Flush() })()
 
 	// Load config from file if specified
 	if *configPath != "" {
@@ -74,7 +80,7 @@ func main() {
 			uiMode = core.ModeSocket // Safe default
 		}
 	default:
-		log.Fatalf("Unknown mode: %s", *mode)
+		utils.Fatalf("Unknown mode: %s", *mode)
 	}
 
 	// Show capabilities if in debug mode
@@ -98,7 +104,7 @@ func main() {
 			goto socketMode
 		}
 		if err := app.Run(); err != nil {
-			log.Fatalf("Rich mode error: %v", err)
+			utils.Fatalf("Rich mode error: %v", err)
 		}
 		return
 
@@ -107,10 +113,10 @@ func main() {
 	case core.ModeSocket:
 		handler := socket.NewHandler(configManager, appState, eventBus)
 		if err := handler.Start(); err != nil {
-			log.Fatalf("Socket mode error: %v", err)
+			utils.Fatalf("Socket mode error: %v", err)
 		}
 
 	default:
-		log.Fatalf("Unsupported mode: %s", uiMode)
+		utils.Fatalf("Unsupported mode: %s", uiMode)
 	}
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package adapters
 import (
 	"github.com/hacka-re/cli/internal/tui/internal/core"
 	"github.com/hacka-re/cli/internal/tui/pkg/interfaces"
+	"github.com/hacka-re/cli/pkg/sharelink"
 )
 
 // AdaptExternalConfig adapts external configuration to internal TUI config
@@ -32,34 +33,111 @@ func adaptFromInterface(cm *core.ConfigManager, extCfg interfaces.ExternalConfig
 		cfg.SystemPrompt = extCfg.GetSystemPrompt()
 		cfg.Namespace = extCfg.GetNamespace()
 
+		// Optional settings, for configs that carry them
+		if budget, ok := extCfg.(interfaces.BudgetConfig); ok {
+			cfg.MaxTokensPerRequest = budget.GetMaxTokensPerRequest()
+			cfg.MaxCostPerSession = budget.GetMaxCostPerSession()
+			cfg.MaxCostPerDay = budget.GetMaxCostPerDay()
+		}
+		if notify, ok := extCfg.(interfaces.NotificationConfig); ok {
+			cfg.NotifyOnComplete = notify.GetNotifyOnComplete()
+			cfg.NotifyAfterSeconds = notify.GetNotifyAfterSeconds()
+		}
+		if seed, ok := extCfg.(interfaces.SeedConfig); ok {
+			cfg.Seed = seed.GetSeed()
+		}
+		if lang, ok := extCfg.(interfaces.LanguageConfig); ok {
+			cfg.Language = lang.GetLanguage()
+		}
+		if lint, ok := extCfg.(interfaces.LintConfig); ok {
+			cfg.LintPrompts = lint.GetLintPrompts()
+		}
+		if cleanup, ok := extCfg.(interfaces.ArtifactsConfig); ok {
+			cfg.ArtifactsMaxAgeDays = cleanup.GetArtifactsMaxAgeDays()
+			cfg.ArtifactsMaxSizeMB = cleanup.GetArtifactsMaxSizeMB()
+		}
+		if offline, ok := extCfg.(interfaces.OfflineConfig); ok {
+			cfg.IsOfflineMode = offline.GetIsOfflineMode()
+			cfg.AllowRemoteMCP = offline.GetAllowRemoteMCP()
+			cfg.AllowRemoteEmbeddings = offline.GetAllowRemoteEmbeddings()
+		}
+		if lock, ok := extCfg.(interfaces.LinkLockConfig); ok {
+			cfg.LockedByLink = lock.GetLockedByLink()
+			cfg.UnlockHash = lock.GetUnlockHash()
+		}
+		if kiosk, ok := extCfg.(interfaces.KioskConfig); ok {
+			cfg.Kiosk = kiosk.GetKiosk()
+		}
+		if share, ok := extCfg.(interfaces.ShareConfig); ok {
+			cfg.ShareSource = share.GetShareConfig()
+		}
+
 		// Note: Functions and Prompts handling would need additional work
 		// as they have different structures in CLI vs TUI
 	})
 }
 
-// adaptFromStruct attempts to adapt config from a struct using reflection
+// adaptFromStruct adapts legacy configs that only implement CLIConfig
 func adaptFromStruct(cm *core.ConfigManager, externalConfig interface{}) error {
-	// This function would use reflection to map fields from CLI config
-	// to TUI config. For now, we'll implement a basic version that
-	// handles the most common case - CLI config struct.
-
-	// Type assertion for CLI config (we'll define this interface)
 	if cliCfg, ok := externalConfig.(interfaces.CLIConfig); ok {
-		return cm.Update(func(cfg *core.Config) {
-			cfg.Provider = cliCfg.GetProvider()
-			cfg.APIKey = cliCfg.GetAPIKey()
-			cfg.BaseURL = cliCfg.GetBaseURL()
-			cfg.Model = cliCfg.GetModel()
-			cfg.Temperature = cliCfg.GetTemperature()
-			cfg.MaxTokens = cliCfg.GetMaxTokens()
-			cfg.StreamMode = cliCfg.GetStreamResponse()
-			cfg.YoloMode = cliCfg.GetYoloMode()
-			cfg.VoiceControl = cliCfg.GetVoiceControl()
-			cfg.SystemPrompt = cliCfg.GetSystemPrompt()
-			cfg.Namespace = cliCfg.GetNamespace()
-		})
+		return adaptFromInterface(cm, cliConfigShim{cliCfg})
 	}
 
 	// If we can't adapt, just continue with existing config
 	return nil
-}
\ No newline at end of file
+}
+
+// cliConfigShim presents a CLIConfig as an ExternalConfig so both share one mapping
+type cliConfigShim struct {
+	interfaces.CLIConfig
+}
+
+func (c cliConfigShim) GetStreamMode() bool                    { return c.GetStreamResponse() }
+func (c cliConfigShim) GetFunctions() []interfaces.FunctionDef { return nil }
+func (c cliConfigShim) GetPrompts() []interfaces.PromptDef     { return nil }
+
+// ExportConfig returns the TUI configuration as an ExternalConfig, for handing changes back to the parent
+func ExportConfig(cm *core.ConfigManager) interfaces.ExternalConfig {
+	snapshot := *cm.Get()
+	return exportedConfig{cfg: &snapshot}
+}
+
+// SyncBack passes the TUI configuration to the external config if it accepts changes
+func SyncBack(cm *core.ConfigManager, externalConfig interface{}) {
+	if receiver, ok := externalConfig.(interfaces.ConfigReceiver); ok {
+		receiver.ApplyTUIConfig(ExportConfig(cm))
+	}
+}
+
+// exportedConfig exposes a core.Config through ExternalConfig and the optional interfaces
+type exportedConfig struct {
+	cfg *core.Config
+}
+
+func (e exportedConfig) GetProvider() string                    { return e.cfg.Provider }
+func (e exportedConfig) GetAPIKey() string                      { return e.cfg.APIKey }
+func (e exportedConfig) GetBaseURL() string                     { return e.cfg.BaseURL }
+func (e exportedConfig) GetModel() string                       { return e.cfg.Model }
+func (e exportedConfig) GetTemperature() float64                { return e.cfg.Temperature }
+func (e exportedConfig) GetMaxTokens() int                      { return e.cfg.MaxTokens }
+func (e exportedConfig) GetStreamMode() bool                    { return e.cfg.StreamMode }
+func (e exportedConfig) GetYoloMode() bool                      { return e.cfg.YoloMode }
+func (e exportedConfig) GetVoiceControl() bool                  { return e.cfg.VoiceControl }
+func (e exportedConfig) GetSystemPrompt() string                { return e.cfg.SystemPrompt }
+func (e exportedConfig) GetNamespace() string                   { return e.cfg.Namespace }
+func (e exportedConfig) GetFunctions() []interfaces.FunctionDef { return nil }
+func (e exportedConfig) GetPrompts() []interfaces.PromptDef     { return nil }
+func (e exportedConfig) GetMaxTokensPerRequest() int            { return e.cfg.MaxTokensPerRequest }
+func (e exportedConfig) GetMaxCostPerSession() float64          { return e.cfg.MaxCostPerSession }
+func (e exportedConfig) GetMaxCostPerDay() float64              { return e.cfg.MaxCostPerDay }
+func (e exportedConfig) GetNotifyOnComplete() bool              { return e.cfg.NotifyOnComplete }
+func (e exportedConfig) GetNotifyAfterSeconds() int             { return e.cfg.NotifyAfterSeconds }
+func (e exportedConfig) GetSeed() int                           { return e.cfg.Seed }
+func (e exportedConfig) GetLanguage() string                    { return e.cfg.Language }
+func (e exportedConfig) GetLintPrompts() bool                   { return e.cfg.LintPrompts }
+func (e exportedConfig) GetArtifactsMaxAgeDays() int            { return e.cfg.ArtifactsMaxAgeDays }
+func (e exportedConfig) GetArtifactsMaxSizeMB() int             { return e.cfg.ArtifactsMaxSizeMB }
+func (e exportedConfig) GetLockedByLink() bool                  { return e.cfg.LockedByLink }
+func (e exportedConfig) GetUnlockHash() string                  { return e.cfg.UnlockHash }
+func (e exportedConfig) GetKiosk() bool                         { return e.cfg.Kiosk }
+func (e exportedConfig) GetShareConfig() *sharelink.Config      { return e.cfg.ShareSource }
@@ -73,6 +73,8 @@ func createTestConfigManager(t *testing.T) *core.ConfigManager {
 	if err != nil {
 		t.Fatalf("Failed to create test config manager: %v", err)
 	}
+	// Write pending changes before the temp dir is removed
+	t.Cleanup(func() { cm.Flush() })
 
 	return cm
 }
@@ -295,3 +297,80 @@ func TestConfigPersistence(t *testing.T) {
 	}
 }
 
+
+// MockFullConfig implements ExternalConfig plus the optional interfaces
+type MockFullConfig struct {
+	MockExternalConfig
+	maxCostPerDay float64
+	notify        bool
+	offline       bool
+	received      interfaces.ExternalConfig
+}
+
+func (m *MockFullConfig) GetMaxTokensPerRequest() int     { return 0 }
+func (m *MockFullConfig) GetMaxCostPerSession() float64   { return 0 }
+func (m *MockFullConfig) GetMaxCostPerDay() float64       { return m.maxCostPerDay }
+func (m *MockFullConfig) GetNotifyOnComplete() bool       { return m.notify }
+func (m *MockFullConfig) GetNotifyAfterSeconds() int      { return 30 }
+func (m *MockFullConfig) GetIsOfflineMode() bool          { return m.offline }
+func (m *MockFullConfig) GetAllowRemoteMCP() bool         { return false }
+func (m *MockFullConfig) GetAllowRemoteEmbeddings() bool  { return false }
+func (m *MockFullConfig) ApplyTUIConfig(cfg interfaces.ExternalConfig) { m.received = cfg }
+
+// TestAdaptOptionalInterfaces tests that budget, notification and offline settings are adapted
+func TestAdaptOptionalInterfaces(t *testing.T) {
+	cm := createTestConfigManager(t)
+
+	mockConfig := &MockFullConfig{
+		MockExternalConfig: MockExternalConfig{provider: "ollama", model: "llama3"},
+		maxCostPerDay:      2.5,
+		notify:             true,
+		offline:            true,
+	}
+
+	if err := AdaptExternalConfig(cm, mockConfig); err != nil {
+		t.Fatalf("Failed to adapt external config: %v", err)
+	}
+
+	config := cm.Get()
+	if config.MaxCostPerDay != 2.5 {
+		t.Errorf("Expected daily budget 2.5, got %f", config.MaxCostPerDay)
+	}
+	if !config.NotifyOnComplete || config.NotifyAfterSeconds != 30 {
+		t.Errorf("Expected notifications after 30s, got %v/%d", config.NotifyOnComplete, config.NotifyAfterSeconds)
+	}
+	if !config.IsOfflineMode {
+		t.Error("Expected offline mode to be adapted")
+	}
+}
+
+// TestSyncBack tests that TUI changes are handed back to a ConfigReceiver
+func TestSyncBack(t *testing.T) {
+	cm := createTestConfigManager(t)
+
+	mockConfig := &MockFullConfig{
+		MockExternalConfig: MockExternalConfig{provider: "openai", model: "gpt-4o-mini"},
+	}
+	if err := AdaptExternalConfig(cm, mockConfig); err != nil {
+		t.Fatalf("Failed to adapt external config: %v", err)
+	}
+
+	// Simulate a change made in the settings modal
+	cm.Update(func(cfg *core.Config) {
+		cfg.Model = "gpt-4o"
+		cfg.MaxCostPerDay = 1
+	})
+
+	SyncBack(cm, mockConfig)
+
+	if mockConfig.received == nil {
+		t.Fatal("Expected the receiver to get the TUI config")
+	}
+	if got := mockConfig.received.GetModel(); got != "gpt-4o" {
+		t.Errorf("Expected model 'gpt-4o', got '%s'", got)
+	}
+	budget, ok := mockConfig.received.(interfaces.BudgetConfig)
+	if !ok || budget.GetMaxCostPerDay() != 1 {
+		t.Error("Expected exported config to carry budget settings")
+	}
+}
@@ -1,13 +1,24 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/funcdir"
+	"github.com/hacka-re/cli/internal/jsruntime"
+	"github.com/hacka-re/cli/internal/logger"
 	"github.com/hacka-re/cli/internal/tui/internal/components"
 	"github.com/hacka-re/cli/internal/tui/internal/core"
 	"github.com/hacka-re/cli/internal/tui/internal/pages"
+	"github.com/hacka-re/cli/internal/tui/internal/services"
+	"github.com/hacka-re/cli/internal/utils"
 )
 
 // App represents the rich TUI application
@@ -22,6 +33,14 @@ type App struct {
 	settingsModal  *pages.SettingsModal
 	chatPanel      *components.ChatPanel
 	confirmDialog  *components.ConfirmDialog
+	palette        *components.Palette // Ctrl+P fuzzy finder, nil when closed
+	help           *components.HelpOverlay // Key help for the current page, nil when closed
+	dashboard      *components.Dashboard   // Status tiles beside the main menu
+
+	// Dashboard state
+	dashboardUpdated   time.Time                 // When the tiles were last rebuilt
+	connection         *services.ConnectionResult // Last reachability check, nil until one finishes
+	connectionChecking bool
 
 	// Configuration view pages
 	promptsPage    *pages.PromptsPage
@@ -29,13 +48,43 @@ type App struct {
 	mcpServersPage *pages.MCPServersPage
 	ragPage        *pages.RAGPage
 	sharePage      *pages.SharePage
+	memoryPage     *pages.MemoryPage
+	playgroundPage *pages.PlaygroundPage
+
+	// Functions directory, reloaded as its files change
+	functionsDir   *funcdir.Watcher
+	functionErrors []funcdir.FileError // Files that don't load, shown above every page
 
 	showConfirmExit bool
 	currentPanel   Panel
 	running        bool
 	needsRedraw    bool
+	lastDraw       time.Time // When the last frame was drawn
+	redrawPending  bool      // A throttled redraw has been scheduled
+	width, height  int       // Screen size, to tell real resizes from redraw requests
+	finiScreen     func() // Restores the terminal (registered with utils.OnExit)
+
+	// Crash recovery
+	recovery          *core.RecoveryStore
+	pendingRecovery   *core.RecoverySnapshot // Snapshot offered for restore on launch
+	showRestorePrompt bool
 }
 
+// autosaveInterval is how often unsaved work is written to the recovery file
+const autosaveInterval = 15 * time.Second
+
+// autosaveTick is posted to the event loop to trigger an autosave
+type autosaveTick struct{}
+
+// frameInterval caps the redraw rate so bursts of events (e.g. streaming) don't repaint on every one
+const frameInterval = 33 * time.Millisecond
+
+// redrawTick is posted to the event loop when a throttled redraw is due
+type redrawTick struct{}
+
+// errKioskSharing is returned when the share page is opened in kiosk mode
+var errKioskSharing = errors.New("sharing is disabled in kiosk mode")
+
 // Panel represents different application panels
 type Panel int
 
@@ -48,6 +97,8 @@ const (
 	PanelMCP
 	PanelRAG
 	PanelShare
+	PanelMemory
+	PanelPlayground
 )
 
 // NewApp creates a new rich TUI application
@@ -66,9 +117,15 @@ func NewAppWithCallbacks(config *core.ConfigManager, state *core.AppState, event
 		return nil, err
 	}
 
+	// Leave the alternate screen on every exit path, not just when Run returns
+	finiScreen := utils.OnExit(screen.Fini)
+
 	// Enable mouse support for scrolling
 	screen.EnableMouse()
 
+	// Enable focus reporting so we know when to send desktop notifications
+	screen.EnableFocus()
+
 	// Set default style
 	screen.SetStyle(tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorReset))
 
@@ -80,6 +137,7 @@ func NewAppWithCallbacks(config *core.ConfigManager, state *core.AppState, event
 		mouseManager: core.NewMouseManager(),
 		currentPanel: PanelMainMenu,
 		needsRedraw:  true,
+		finiScreen:   finiScreen,
 	}
 
 	// Store callbacks in state if provided
@@ -87,12 +145,26 @@ func NewAppWithCallbacks(config *core.ConfigManager, state *core.AppState, event
 		state.SetCallbacks(callbacks)
 	}
 
+	// Restore the terminal and save unsaved work if a background goroutine panics
+	app.recovery = core.NewRecoveryStore(filepath.Dir(config.GetConfigPath()))
+	core.SetPanicHandler(app.handleCrash)
+	app.offerRecovery()
+
 	// Create main menu
 	app.createMainMenu()
+	app.dashboard = components.NewDashboard(screen)
 
 	// Subscribe to events
 	app.subscribeToEvents()
 
+	// Check provider reachability for the dashboard
+	app.checkConnection()
+
+	app.functionsDir = funcdir.NewWatcher(funcdir.Dir(), jsruntime.NewRegistry())
+	if _, err := app.functionsDir.Scan(); err == nil {
+		app.functionErrors = app.functionsDir.Errors()
+	}
+
 	return app, nil
 }
 
@@ -236,6 +308,25 @@ RAG allows the AI to reference your specific documents and data.`,
 
 	a.mainMenu.AddItem(&components.BasicMenuItem{
 		Number:      6,
+		Title:       "Memory",
+		Description: "Review long-term facts",
+		Info: `Facts and preferences remembered across conversations.
+
+• Review what the AI remembers about you
+• Add, edit or delete facts
+• Facts are stored per namespace
+• Use /remember in chat to suggest new facts
+
+Remembered facts are added to the system prompt of every chat.`,
+		Enabled: true,
+		Handler: func() error {
+			a.currentPanel = PanelMemory
+			return a.showMemory()
+		},
+	})
+
+	a.mainMenu.AddItem(&components.BasicMenuItem{
+		Number:      7,
 		Title:       "Share Configuration",
 		Description: "Generate a shareable configuration link",
 		Info: `Create an encrypted link to share your configuration.
@@ -247,12 +338,48 @@ RAG allows the AI to reference your specific documents and data.`,
 • Import shared configurations
 
 Share links allow you to transfer settings between devices securely.`,
-		Enabled: true,
+		Enabled: !a.config.Get().Kiosk,
 		Handler: func() error {
 			return a.generateShareLink()
 		},
 	})
 
+	a.mainMenu.AddItem(&components.BasicMenuItem{
+		Number:      10,
+		Title:       "Chat Templates",
+		Description: "Start a chat from a ready-made template",
+		Info: `Start a new conversation set up for a common task.
+
+• Code review
+• Threat model
+• Incident report
+• CTF helper
+
+A template sets the system prompt for the conversation and opens it with a message saying what to share.`,
+		Enabled: a.templatesAllowed(),
+		Handler: func() error {
+			return a.showTemplates()
+		},
+	})
+
+	a.mainMenu.AddItem(&components.BasicMenuItem{
+		Number:      11,
+		Title:       "Playground",
+		Description: "Try out JavaScript functions",
+		Info: `A scratchpad for developing functions before the model may call them.
+
+• Run JavaScript with your functions loaded, enabled or not
+• Call a function with tool arguments: :call name {"param": 1}
+• Use the result of an earlier cell as $1, $2, ...
+• Cells are kept between sessions
+
+Cells run in the same sandbox as the functions the model calls.`,
+		Enabled: true,
+		Handler: func() error {
+			return a.showPlayground()
+		},
+	})
+
 	/* ============================================================
 	   SOCKET MODE OPTION DISABLED - WORKING ON TUI ONLY
 	   ============================================================
@@ -279,7 +406,7 @@ Useful for SSH, telnet, or serial connections.`,
 	*/
 
 	a.mainMenu.AddItem(&components.BasicMenuItem{
-		Number:      7,
+		Number:      8,
 		Title:       "About",
 		Description: "About hacka.re Terminal UI",
 		Info: `hacka.re Terminal UI v2.0
@@ -301,7 +428,7 @@ Built with Go, tcell, and love.`,
 	})
 
 	a.mainMenu.AddItem(&components.BasicMenuItem{
-		Number:      8,
+		Number:      9,
 		Title:       "Exit",
 		Description: "Exit the application",
 		Info: `Exit the hacka.re Terminal UI.
@@ -335,6 +462,11 @@ func (a *App) SetInitialPanel(panelName string) {
 	case "share":
 		a.currentPanel = PanelShare
 		a.generateShareLink()
+	case "memory":
+		a.currentPanel = PanelMemory
+		a.showMemory()
+	case "playground":
+		a.showPlayground()
 	case "settings":
 		a.currentPanel = PanelSettings
 		a.showSettings()
@@ -348,8 +480,17 @@ func (a *App) SetInitialPanel(panelName string) {
 }
 
 // Run starts the application main loop
-func (a *App) Run() error {
-	defer a.screen.Fini()
+func (a *App) Run() (err error) {
+	defer a.finiScreen()
+	utils.HandleSignals()
+
+	// Save work and write a crash report instead of leaving a broken terminal
+	defer func() {
+		if r := recover(); r != nil {
+			a.handleCrash(r, debug.Stack())
+			err = fmt.Errorf("TUI crashed: %v", r)
+		}
+	}()
 
 	a.running = true
 
@@ -357,12 +498,42 @@ func (a *App) Run() error {
 	a.screen.Clear()
 	a.screen.Sync()
 
+	// Periodically autosave from the event loop so panel state is read safely
+	stopAutosave := make(chan struct{})
+	defer close(stopAutosave)
+	go func() {
+		ticker := time.NewTicker(autosaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopAutosave:
+				return
+			case <-ticker.C:
+				a.screen.PostEvent(tcell.NewEventInterrupt(autosaveTick{}))
+			}
+		}
+	}()
+
+	// Pick up edits to the functions directory
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	go a.functionsDir.Run(watchCtx, funcdir.PollInterval, func(change funcdir.Change) {
+		a.eventBus.PublishAsync(core.EventFunctionsReloaded, change)
+	})
+
+	a.width, a.height = a.screen.Size()
+
 	// Main event loop
 	for a.running {
-		// Redraw if needed
+		// Redraw if needed, at most once per frame interval
 		if a.needsRedraw {
-			a.draw()
-			a.needsRedraw = false
+			if wait := frameInterval - time.Since(a.lastDraw); wait > 0 {
+				a.scheduleRedraw(wait)
+			} else {
+				a.draw()
+				a.needsRedraw = false
+				a.lastDraw = time.Now()
+			}
 		}
 
 		// Poll for events
@@ -377,19 +548,158 @@ func (a *App) Run() error {
 			a.handleKeyEvent(ev)
 
 		case *tcell.EventResize:
-			a.screen.Sync()
+			// Only a real size change needs a full repaint
+			if w, h := ev.Size(); w != a.width || h != a.height {
+				a.width, a.height = w, h
+				a.screen.Sync()
+			}
 			a.needsRedraw = true
 
 		case *tcell.EventMouse:
 			a.handleMouseEvent(ev)
+
+		case *tcell.EventFocus:
+			a.state.SetTerminalFocused(ev.Focused)
+
+		case *tcell.EventInterrupt:
+			switch data := ev.Data().(type) {
+			case redrawTick:
+				a.redrawPending = false
+			case autosaveTick:
+				a.autosave()
+			case core.Event:
+				a.handleAsyncEvent(data)
+			}
+			a.needsRedraw = true
+		}
+	}
+
+	// Clean exit: nothing to recover unless the user left a restore undecided
+	if a.pendingRecovery == nil {
+		if err := a.recovery.Clear(); err != nil {
+			if log := logger.Get(); log != nil {
+				log.Warn("[App] Failed to remove recovery file: %v", err)
+			}
 		}
 	}
 
 	return nil
 }
 
+// scheduleRedraw wakes the event loop once the frame interval has passed
+func (a *App) scheduleRedraw(wait time.Duration) {
+	if a.redrawPending {
+		return
+	}
+	a.redrawPending = true
+	time.AfterFunc(wait, func() {
+		a.screen.PostEvent(tcell.NewEventInterrupt(redrawTick{}))
+	})
+}
+
+// autosave writes the chat and editor state to the recovery file
+func (a *App) autosave() {
+	// Don't overwrite a snapshot the user hasn't decided on yet
+	if a.pendingRecovery != nil {
+		return
+	}
+
+	if a.chatPanel != nil {
+		a.state.SetCurrentInput(a.chatPanel.GetInput())
+	}
+
+	if err := a.recovery.Save(a.state.Snapshot()); err != nil {
+		if log := logger.Get(); log != nil {
+			log.Warn("[App] Autosave failed: %v", err)
+		}
+	}
+}
+
+// handleCrash restores the terminal, saves unsaved work and writes a crash report
+func (a *App) handleCrash(panicValue interface{}, stack []byte) {
+	a.screen.Fini()
+
+	if log := logger.Get(); log != nil {
+		log.Error("[App] Panic: %v\n%s", panicValue, stack)
+	}
+
+	if a.pendingRecovery == nil {
+		if err := a.recovery.Save(a.state.Snapshot()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save unsaved work: %v\n", err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "hacka.re crashed: %v\n", panicValue)
+	if path, err := a.recovery.WriteCrashReport(panicValue, stack); err == nil {
+		fmt.Fprintf(os.Stderr, "Crash report written to %s\n", path)
+	}
+	fmt.Fprintln(os.Stderr, "Your conversation and drafts will be offered for restore on next launch.")
+}
+
+// offerRecovery asks whether to restore work left by a crashed session
+func (a *App) offerRecovery() {
+	snapshot, err := a.recovery.Load()
+	if err != nil {
+		if log := logger.Get(); log != nil {
+			log.Warn("[App] Ignoring unreadable recovery file: %v", err)
+		}
+		return
+	}
+	if snapshot == nil {
+		return
+	}
+
+	message := fmt.Sprintf("hacka.re did not exit cleanly (last autosave %s).\n\nRestore %d messages and %d unsaved drafts?",
+		snapshot.SavedAt.Format("2006-01-02 15:04"), len(snapshot.Messages), len(snapshot.EditorBuffers))
+	if snapshot.ChatInput != "" {
+		message += "\nYour unsent chat input will be restored too."
+	}
+
+	a.pendingRecovery = snapshot
+	a.confirmDialog = components.NewConfirmDialog(a.screen, "Restore unsaved work?", message)
+	a.confirmDialog.Center()
+	a.showRestorePrompt = true
+}
+
+// resolveRecovery restores or discards the pending snapshot
+func (a *App) resolveRecovery(restore bool) {
+	if restore {
+		a.state.Restore(a.pendingRecovery)
+
+		// Recreate panels that were opened before the restore so they pick up the state
+		a.chatPanel = nil
+		a.promptsPage = nil
+		switch a.currentPanel {
+		case PanelChat:
+			a.showChat()
+		case PanelPrompts:
+			a.showPrompts()
+		}
+	}
+
+	if err := a.recovery.Clear(); err != nil {
+		if log := logger.Get(); log != nil {
+			log.Warn("[App] Failed to remove recovery file: %v", err)
+		}
+	}
+
+	a.pendingRecovery = nil
+	a.showRestorePrompt = false
+	a.confirmDialog = nil
+	a.needsRedraw = true
+}
+
 // handleKeyEvent processes keyboard input
 func (a *App) handleKeyEvent(ev *tcell.EventKey) {
+	// Handle the restore prompt shown after a crash
+	if a.showRestorePrompt && a.confirmDialog != nil {
+		if confirmed, done := a.confirmDialog.HandleInput(ev); done {
+			a.resolveRecovery(confirmed)
+		}
+		a.needsRedraw = true
+		return
+	}
+
 	// Handle exit confirmation dialog first if it's showing
 	if a.showConfirmExit && a.confirmDialog != nil {
 		confirmed, done := a.confirmDialog.HandleInput(ev)
@@ -407,8 +717,49 @@ func (a *App) handleKeyEvent(ev *tcell.EventKey) {
 		return
 	}
 
+	// The palette and help overlay take all keys while they are open
+	if a.palette != nil {
+		if a.palette.HandleInput(ev) {
+			a.palette = nil
+		}
+		a.needsRedraw = true
+		return
+	}
+	if a.help != nil {
+		if a.help.HandleInput(ev) {
+			a.help = nil
+		}
+		a.needsRedraw = true
+		return
+	}
+
+	// Ctrl+P opens the palette anywhere; help opens with F1, or with ? where
+	// printable keys don't type text
+	if ev.Key() == tcell.KeyCtrlP {
+		a.openPalette()
+		return
+	}
+	keymap := a.currentKeymap()
+	if ev.Key() == tcell.KeyF1 || ev.Key() == tcell.KeyRune && ev.Rune() == '?' && keymap != nil && !keymap.TextEntry {
+		a.help = components.NewHelpOverlay(a.screen, keymap, core.GlobalKeymap)
+		a.needsRedraw = true
+		return
+	}
+
 	switch a.currentPanel {
 	case PanelMainMenu:
+		if a.dashboard.IsFocused() {
+			if a.dashboard.HandleInput(ev) {
+				a.dashboard.SetFocused(false)
+			}
+			a.needsRedraw = true
+			return
+		}
+		if ev.Key() == tcell.KeyTab && a.dashboardVisible() {
+			a.dashboard.SetFocused(true)
+			a.needsRedraw = true
+			return
+		}
 		item, exit := a.mainMenu.HandleInput(ev)
 		if exit {
 			// Show exit confirmation dialog
@@ -495,6 +846,24 @@ func (a *App) handleKeyEvent(ev *tcell.EventKey) {
 			a.needsRedraw = true
 		}
 
+	case PanelMemory:
+		if a.memoryPage != nil {
+			done := a.memoryPage.HandleInput(ev)
+			if done {
+				a.currentPanel = PanelMainMenu
+				a.memoryPage = nil
+			}
+			a.needsRedraw = true
+		}
+
+	case PanelPlayground:
+		if a.playgroundPage != nil {
+			if a.playgroundPage.HandleInput(ev) {
+				a.currentPanel = PanelMainMenu
+			}
+			a.needsRedraw = true
+		}
+
 	default:
 		// Handle other panels
 		if ev.Key() == tcell.KeyEscape {
@@ -504,13 +873,48 @@ func (a *App) handleKeyEvent(ev *tcell.EventKey) {
 	}
 }
 
-// draw renders the current view
+// currentKeymap returns the bindings of the panel that has focus, or nil
+func (a *App) currentKeymap() *core.Keymap {
+	switch {
+	case a.currentPanel == PanelMainMenu && a.dashboard.IsFocused():
+		return components.DashboardKeymap
+	case a.currentPanel == PanelMainMenu && a.dashboardVisible():
+		return mainMenuKeymap
+	case a.currentPanel == PanelMainMenu:
+		return components.MenuKeymap
+	case a.currentPanel == PanelChat && a.chatPanel != nil:
+		return a.chatPanel.Keymap()
+	case a.currentPanel == PanelChat:
+		return components.ChatKeymap
+	case a.currentPanel == PanelSettings && a.settingsModal != nil:
+		return a.settingsModal.Keymap()
+	case a.currentPanel == PanelPrompts && a.promptsPage != nil:
+		return a.promptsPage.Keymap()
+	case a.currentPanel == PanelFunctions && a.functionsPage != nil:
+		return a.functionsPage.Keymap()
+	case a.currentPanel == PanelMCP && a.mcpServersPage != nil:
+		return a.mcpServersPage.Keymap()
+	case a.currentPanel == PanelRAG && a.ragPage != nil:
+		return a.ragPage.Keymap()
+	case a.currentPanel == PanelShare && a.sharePage != nil:
+		return a.sharePage.Keymap()
+	case a.currentPanel == PanelMemory && a.memoryPage != nil:
+		return a.memoryPage.Keymap()
+	case a.currentPanel == PanelPlayground && a.playgroundPage != nil:
+		return a.playgroundPage.Keymap()
+	}
+	return nil
+}
+
+// draw renders the current view.
+// Clear only resets tcell's back buffer; Show then sends just the cells that changed
+// since the previous frame, so unchanged regions are never rewritten to the terminal.
 func (a *App) draw() {
 	a.screen.Clear()
 
 	switch a.currentPanel {
 	case PanelMainMenu:
-		a.mainMenu.Draw()
+		a.drawMainMenu()
 
 	case PanelSettings:
 		if a.settingsModal != nil {
@@ -556,16 +960,62 @@ func (a *App) draw() {
 		} else {
 			a.drawPlaceholder("Share Panel", "Loading...")
 		}
+
+	case PanelMemory:
+		if a.memoryPage != nil {
+			a.memoryPage.Draw()
+		} else {
+			a.drawPlaceholder("Memory Panel", "Loading...")
+		}
+
+	case PanelPlayground:
+		if a.playgroundPage != nil {
+			a.playgroundPage.Draw()
+		} else {
+			a.drawPlaceholder("Playground", "Loading...")
+		}
 	}
 
-	// Draw exit confirmation dialog on top if active
-	if a.showConfirmExit && a.confirmDialog != nil {
+	a.drawFunctionErrors()
+
+	if a.palette != nil {
+		a.palette.Draw()
+	}
+	if a.help != nil {
+		a.help.Draw()
+	}
+
+	// Draw exit confirmation or restore dialog on top if active
+	if (a.showConfirmExit || a.showRestorePrompt) && a.confirmDialog != nil {
 		a.confirmDialog.Draw()
 	}
 
 	a.screen.Show()
 }
 
+// drawFunctionErrors reports files of the functions directory that don't
+// load on the top line, until they are fixed
+func (a *App) drawFunctionErrors() {
+	if len(a.functionErrors) == 0 {
+		return
+	}
+	w, _ := a.screen.Size()
+	first := a.functionErrors[0]
+	text := fmt.Sprintf(" ⚠ %s: %s ", first.File, first.Error)
+	if len(a.functionErrors) > 1 {
+		text = fmt.Sprintf(" ⚠ %d function files don't load. %s: %s ", len(a.functionErrors), first.File, first.Error)
+	}
+	text = strings.ReplaceAll(text, "\n", " ")
+	if runes := []rune(text); len(runes) > w {
+		text = string(runes[:w-1]) + "…"
+	}
+	style := tcell.StyleDefault.Background(tcell.ColorDarkRed).Foreground(tcell.ColorWhite)
+	for x := 0; x < w; x++ {
+		a.screen.SetContent(x, 0, ' ', nil, style)
+	}
+	components.DrawText(a.screen, 0, 0, text, style)
+}
+
 // drawPlaceholder draws a placeholder screen
 func (a *App) drawPlaceholder(title, message string) {
 	w, h := a.screen.Size()
@@ -598,16 +1048,59 @@ func (a *App) drawPlaceholder(title, message string) {
 // subscribeToEvents sets up event handlers
 func (a *App) subscribeToEvents() {
 	// Handle config changes
-	a.eventBus.Subscribe(core.EventConfigChanged, func(e core.Event) {
-		a.needsRedraw = true
-	})
-
 	// Handle message events
 	a.eventBus.Subscribe(core.EventMessageAdded, func(e core.Event) {
 		if a.currentPanel == PanelChat {
 			a.needsRedraw = true
 		}
 	})
+
+	// Hand results of background requests to the event loop so panels update on the UI goroutine
+	forward := func(e core.Event) {
+		a.screen.PostEvent(tcell.NewEventInterrupt(e))
+	}
+	a.eventBus.Subscribe(core.EventConfigChanged, forward)
+	a.eventBus.Subscribe(core.EventModelsLoaded, forward)
+	a.eventBus.Subscribe(core.EventConnectionTested, forward)
+	a.eventBus.Subscribe(core.EventKeyValidated, forward)
+	a.eventBus.Subscribe(core.EventCellRun, forward)
+	a.eventBus.Subscribe(core.EventFunctionTestsRun, forward)
+	a.eventBus.Subscribe(core.EventFunctionsReloaded, forward)
+}
+
+// handleAsyncEvent delivers a background result to the panel that requested it
+func (a *App) handleAsyncEvent(e core.Event) {
+	switch e.Type {
+	case core.EventConfigChanged:
+		// Provider or key may have changed, so the dashboard checks again
+		a.checkConnection()
+	case core.EventConnectionTested:
+		if result, ok := e.Data.(services.ConnectionResult); ok && a.connectionChecking {
+			a.connection = &result
+			a.connectionChecking = false
+			a.dashboardUpdated = time.Time{}
+		}
+		if a.settingsModal != nil {
+			a.settingsModal.HandleAsyncEvent(e)
+		}
+	case core.EventModelsLoaded, core.EventKeyValidated:
+		if a.settingsModal != nil {
+			a.settingsModal.HandleAsyncEvent(e)
+		}
+	case core.EventCellRun:
+		if a.playgroundPage != nil {
+			a.playgroundPage.HandleAsyncEvent(e)
+		}
+	case core.EventFunctionTestsRun:
+		if a.functionsPage != nil {
+			a.functionsPage.HandleAsyncEvent(e)
+		}
+	case core.EventFunctionsReloaded:
+		a.functionErrors = a.functionsDir.Errors()
+		if a.functionsPage != nil {
+			a.functionsPage.HandleAsyncEvent(e)
+		}
+	}
 }
 
 // Panel handler methods
@@ -665,6 +1158,8 @@ func (a *App) showFunctions() error {
 	// Create functions page (read-only)
 	if a.functionsPage == nil {
 		a.functionsPage = pages.NewFunctionsPage(a.screen, a.config, a.state, a.eventBus)
+		a.functionsPage.SetFunctionsDir(a.functionsDir)
+		a.functionsPage.RunTests()
 	}
 	a.currentPanel = PanelFunctions
 	a.needsRedraw = true
@@ -692,6 +1187,12 @@ func (a *App) showRAG() error {
 }
 
 func (a *App) generateShareLink() error {
+	// Kiosks don't hand out their configuration
+	if a.config.Get().Kiosk {
+		a.currentPanel = PanelMainMenu
+		return errKioskSharing
+	}
+
 	// Create share configuration page (read-only)
 	if a.sharePage == nil {
 		a.sharePage = pages.NewSharePage(a.screen, a.config, a.state, a.eventBus)
@@ -701,6 +1202,28 @@ func (a *App) generateShareLink() error {
 	return nil
 }
 
+func (a *App) showMemory() error {
+	// Create the memory page; changes are saved as they are made
+	if a.memoryPage == nil {
+		a.memoryPage = pages.NewMemoryPage(a.screen, a.config, a.state, a.eventBus)
+	}
+	a.currentPanel = PanelMemory
+	a.needsRedraw = true
+	return nil
+}
+
+func (a *App) showPlayground() error {
+	// The page is kept when leaving it, so a running cell can finish
+	if a.playgroundPage == nil {
+		a.playgroundPage = pages.NewPlaygroundPage(a.screen, a.config, a.state, a.eventBus)
+	} else {
+		a.playgroundPage.OnActivate()
+	}
+	a.currentPanel = PanelPlayground
+	a.needsRedraw = true
+	return nil
+}
+
 func (a *App) showAbout() error {
 	// About panel
 	return nil
@@ -714,6 +1237,17 @@ func (a *App) handleMouseEvent(ev *tcell.EventMouse) {
 	// Publish mouse event to event bus for any interested components
 	a.eventBus.PublishAsync(core.EventType(fmt.Sprintf("mouse_%d", mouseEvent.Type)), mouseEvent)
 
+	// Handle the restore prompt shown after a crash
+	if a.showRestorePrompt && a.confirmDialog != nil {
+		if a.confirmDialog.HandleMouse(mouseEvent) {
+			if mouseEvent.Type == core.MouseEventClick {
+				a.resolveRecovery(a.confirmDialog.IsConfirmed())
+			}
+			a.needsRedraw = true
+		}
+		return
+	}
+
 	// Handle exit confirmation dialog if it's showing
 	if a.showConfirmExit && a.confirmDialog != nil {
 		if a.confirmDialog.HandleMouse(mouseEvent) {
@@ -734,9 +1268,28 @@ func (a *App) handleMouseEvent(ev *tcell.EventMouse) {
 		}
 	}
 
+	if a.palette != nil {
+		if a.palette.HandleMouse(mouseEvent) {
+			a.palette = nil
+		}
+		a.needsRedraw = true
+		return
+	}
+	if a.help != nil {
+		if a.help.HandleMouse(mouseEvent) {
+			a.help = nil
+		}
+		a.needsRedraw = true
+		return
+	}
+
 	// Route mouse events to the appropriate panel
 	switch a.currentPanel {
 	case PanelMainMenu:
+		if a.dashboardVisible() && a.dashboard.HandleMouse(mouseEvent) {
+			a.needsRedraw = true
+			return
+		}
 		if a.mainMenu != nil {
 			if a.mainMenu.HandleMouse(mouseEvent) {
 				a.needsRedraw = true
@@ -791,6 +1344,20 @@ func (a *App) handleMouseEvent(ev *tcell.EventMouse) {
 				a.needsRedraw = true
 			}
 		}
+
+	case PanelMemory:
+		if a.memoryPage != nil {
+			if a.memoryPage.HandleMouse(mouseEvent) {
+				a.needsRedraw = true
+			}
+		}
+
+	case PanelPlayground:
+		if a.playgroundPage != nil {
+			if a.playgroundPage.HandleMouse(mouseEvent) {
+				a.needsRedraw = true
+			}
+		}
 	}
 }
 
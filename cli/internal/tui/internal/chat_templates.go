@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"github.com/hacka-re/cli/internal/templates"
+	"github.com/hacka-re/cli/internal/tui/internal/components"
+)
+
+// showTemplates lists the conversation templates in the palette
+func (a *App) showTemplates() error {
+	a.palette = components.NewPalette(a.screen, a.templateEntries())
+	a.needsRedraw = true
+	return nil
+}
+
+// templateEntries are palette entries that start a chat from each template
+func (a *App) templateEntries() []components.PaletteEntry {
+	var entries []components.PaletteEntry
+	for _, tmpl := range templates.All() {
+		tmpl := tmpl
+		entries = append(entries, components.PaletteEntry{
+			Kind: "Template", Title: tmpl.Title, Detail: tmpl.Description,
+			Action: func() error { return a.startTemplate(tmpl) },
+		})
+	}
+	return entries
+}
+
+// startTemplate opens the chat with a new conversation from tmpl
+func (a *App) startTemplate(tmpl templates.Template) error {
+	if err := a.showChat(); err != nil {
+		return err
+	}
+	a.chatPanel.StartTemplate(tmpl)
+	return nil
+}
+
+// templatesAllowed reports whether templates may replace the system prompt,
+// which kiosks and configurations locked by a share link don't allow
+func (a *App) templatesAllowed() bool {
+	cfg := a.config.Get()
+	return !cfg.Kiosk && !cfg.LockedByLink
+}
@@ -0,0 +1,76 @@
+package components
+
+import (
+	"context"
+	"time"
+
+	"github.com/hacka-re/cli/internal/hooks"
+)
+
+// loadHooks sets up the user's hooks, saying so in the chat when the hooks
+// file can't be used
+func (cp *ChatPanel) loadHooks() {
+	loaded, err := hooks.Load(hooks.Path())
+	if err != nil {
+		cp.messages = append(cp.messages, ChatMessage{
+			Role:      "system",
+			Content:   "Hooks are off: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+	}
+	cp.hooks = loaded
+}
+
+// sendWithHooks runs the hooks on a message about to be sent, then sends
+// what they return. They run in the background so a slow hook doesn't freeze
+// the screen; a message a hook stopped stays in the input (must be called
+// with streamingMutex held).
+func (cp *ChatPanel) sendWithHooks(message string) {
+	cp.sendingHooks = true
+	model := cp.config.Get().Model
+	go func() {
+		sent, err := cp.hooks.Send(context.Background(), message, model)
+
+		cp.streamingMutex.Lock()
+		defer cp.streamingMutex.Unlock()
+		cp.sendingHooks = false
+		if err != nil {
+			cp.addSystemMessageLocked("Not sent, " + err.Error())
+		} else {
+			cp.dispatchMessage(message, sent)
+		}
+		cp.needsRedraw = true
+		cp.requestRedraw(true)
+	}()
+}
+
+// afterReceive runs the hooks on the complete reply at index and keeps what
+// they return. The lock is released while they run, so the chat stays
+// responsive; a reply that was replaced or cancelled meanwhile is left alone.
+func (cp *ChatPanel) afterReceive(gen, index int, model string) {
+	if cp.hooks.Empty() {
+		return
+	}
+	cp.streamingMutex.Lock()
+	if gen != cp.streamGen || index >= len(cp.messages) || cp.messages[index].Role != "assistant" {
+		cp.streamingMutex.Unlock()
+		return
+	}
+	reply := cp.messages[index].Content
+	cp.streamingMutex.Unlock()
+
+	kept, err := cp.hooks.Receive(context.Background(), reply, model)
+
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	if err != nil {
+		cp.addSystemMessageLocked("Hook failed: " + err.Error())
+	}
+	if kept != reply && gen == cp.streamGen && index < len(cp.messages) && cp.messages[index].Content == reply {
+		cp.messages[index].Content = kept
+		cp.state.ReviseMessage("assistant", reply, kept)
+		cp.addSystemMessageLocked("The reply above was changed by hooks.")
+	}
+	cp.needsRedraw = true
+	cp.requestRedraw(true)
+}
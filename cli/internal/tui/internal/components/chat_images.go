@@ -0,0 +1,211 @@
+package components
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/artifacts"
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/utils"
+)
+
+// maxImageSize is the largest image that can be attached to a message
+const maxImageSize = 20 << 20
+
+// imageTypes maps the extensions of images that can be attached to their media type
+var imageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// pasteImage saves the clipboard image with this session's artifacts and
+// attaches it to the next message. It reports whether the clipboard held an image.
+func (cp *ChatPanel) pasteImage() (bool, error) {
+	data, err := utils.GetClipboardImage()
+	if err != nil {
+		return false, err
+	}
+
+	path, err := artifacts.Current().Save("paste-"+time.Now().Format("20060102-150405.000")+".png", data)
+	if err != nil {
+		return true, err
+	}
+	return true, cp.attachImage(path)
+}
+
+// attachImage attaches an image file to the next message sent to a vision model
+func (cp *ChatPanel) attachImage(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > maxImageSize {
+		return fmt.Errorf("%s is larger than %d MB", filepath.Base(path), maxImageSize>>20)
+	}
+
+	cp.streamingMutex.Lock()
+	cp.pendingImages = append(cp.pendingImages, path)
+	status := cp.attachmentStatus()
+	cp.streamingMutex.Unlock()
+
+	notice := fmt.Sprintf("Attached %s to your next message (%s; /paste-image clear removes them).", filepath.Base(path), status)
+	if !cp.acceptsImages() {
+		notice += fmt.Sprintf(" %s doesn't take images; they are sent once you switch to a vision model.", cp.config.Get().Model)
+	}
+	cp.addSystemMessage(notice)
+	return nil
+}
+
+// handlePasteImageCommand attaches the clipboard image, or with "clear" drops the attached ones
+func (cp *ChatPanel) handlePasteImageCommand(arg string) {
+	if cp.config.Get().Kiosk {
+		cp.addSystemMessage("Attaching images is disabled in kiosk mode.")
+		return
+	}
+	if arg == "clear" {
+		cp.streamingMutex.Lock()
+		cp.pendingImages = nil
+		cp.streamingMutex.Unlock()
+		cp.addSystemMessage("Removed the attached images.")
+		return
+	}
+	if _, err := cp.pasteImage(); err != nil {
+		cp.addSystemMessage("Could not paste an image: " + err.Error())
+	}
+}
+
+// handleArtifactsCommand lists this session's artifacts, or with "clean"
+// removes old sessions according to the cleanup setting
+func (cp *ChatPanel) handleArtifactsCommand(arg string) {
+	if arg == "clean" {
+		if cp.config.Get().Kiosk {
+			cp.addSystemMessage("Removing artifacts is disabled in kiosk mode.")
+			return
+		}
+		removed, freed, err := artifacts.Cleanup(artifacts.Root(), cp.config.Get().ArtifactPolicy(), artifacts.Current().Dir())
+		if err != nil {
+			cp.addSystemMessage("Could not clean up artifacts: " + err.Error())
+			return
+		}
+		cp.addSystemMessage(fmt.Sprintf("Removed %d old sessions (%s).", removed, artifacts.FormatSize(freed)))
+		return
+	}
+	summary, err := artifacts.Summary(artifacts.Current(), artifacts.Root())
+	if err != nil {
+		cp.addSystemMessage("Could not list artifacts: " + err.Error())
+		return
+	}
+	cp.addSystemMessage(summary)
+}
+
+// pasteClipboard attaches the clipboard image if there is one, or else
+// inserts the clipboard text at the cursor (Ctrl+V). Kiosks only paste text.
+func (cp *ChatPanel) pasteClipboard() {
+	if !cp.config.Get().Kiosk {
+		isImage, err := cp.pasteImage()
+		if isImage || (err != nil && !errors.Is(err, utils.ErrNoClipboardImage)) {
+			if err != nil {
+				cp.addSystemMessage("Could not paste an image: " + err.Error())
+			}
+			return
+		}
+	}
+
+	text, err := utils.GetClipboardContent()
+	if err != nil || text == "" {
+		return
+	}
+	// The input is a single line
+	text = strings.Join(strings.Fields(text), " ")
+	cp.inputBuffer = cp.inputBuffer[:cp.cursorPos] + text + cp.inputBuffer[cp.cursorPos:]
+	cp.cursorPos += len(text)
+}
+
+// imagePath returns the image file the input names, e.g. after dragging a
+// file into the terminal, or "" when the input is anything else
+func imagePath(input string) string {
+	path := strings.TrimSpace(input)
+	if len(path) >= 2 && (path[0] == '\'' || path[0] == '"') && path[len(path)-1] == path[0] {
+		path = path[1 : len(path)-1]
+	}
+	path = strings.TrimPrefix(path, "file://")
+	path = strings.ReplaceAll(path, `\ `, " ")
+	if strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[2:])
+		}
+	}
+
+	if _, ok := imageTypes[strings.ToLower(filepath.Ext(path))]; !ok {
+		return ""
+	}
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	return path
+}
+
+// acceptsImages reports whether the current model takes images. Models that
+// aren't in the registry are assumed to.
+func (cp *ChatPanel) acceptsImages() bool {
+	meta, ok := cp.modelRegistry.GetModel(cp.config.Get().Model)
+	if !ok {
+		return true
+	}
+	for _, capability := range meta.Capabilities {
+		if capability == "vision" {
+			return true
+		}
+	}
+	return false
+}
+
+// takeImages returns the attached images for a message being sent and
+// clears them, unless the model doesn't take images
+// (must be called with streamingMutex held)
+func (cp *ChatPanel) takeImages() []string {
+	if len(cp.pendingImages) == 0 || !cp.acceptsImages() {
+		return nil
+	}
+	images := cp.pendingImages
+	cp.pendingImages = nil
+	return images
+}
+
+// imageDataURLs reads attached images as data: URLs for the API, skipping
+// files that can no longer be read
+func imageDataURLs(paths []string) []string {
+	var urls []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if log := logger.Get(); log != nil {
+				log.Error("Failed to read attached image: %v", err)
+			}
+			continue
+		}
+		mediaType := imageTypes[strings.ToLower(filepath.Ext(path))]
+		urls = append(urls, "data:"+mediaType+";base64,"+base64.StdEncoding.EncodeToString(data))
+	}
+	return urls
+}
+
+// attachmentStatus describes the images waiting for the next message
+func (cp *ChatPanel) attachmentStatus() string {
+	switch n := len(cp.pendingImages); n {
+	case 0:
+		return ""
+	case 1:
+		return "1 image attached"
+	default:
+		return fmt.Sprintf("%d images attached", n)
+	}
+}
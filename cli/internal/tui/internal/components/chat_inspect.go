@@ -0,0 +1,105 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// chatInspectKeymap lists the keys while the response panel is open
+var chatInspectKeymap = core.RegisterKeymap("chat.inspect", "Chat: last response",
+	core.Bind("↑↓ PgUp/PgDn", "Scroll"),
+	core.Bind("Home/End", "First/last line"),
+	core.Bind("F12 ESC", "Close"),
+)
+
+// toggleInspect opens or closes the panel with the raw response of the last request
+func (cp *ChatPanel) toggleInspect() {
+	cp.inspecting = !cp.inspecting
+	cp.inspectScroll = 0
+}
+
+func (cp *ChatPanel) handleInspectInput(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyF12:
+		cp.inspecting = false
+	case tcell.KeyUp:
+		cp.inspectScroll = max(0, cp.inspectScroll-1)
+	case tcell.KeyDown:
+		cp.inspectScroll++
+	case tcell.KeyPgUp:
+		cp.inspectScroll = max(0, cp.inspectScroll-cp.height/2)
+	case tcell.KeyPgDn:
+		cp.inspectScroll += cp.height / 2
+	case tcell.KeyHome:
+		cp.inspectScroll = 0
+	case tcell.KeyEnd:
+		cp.inspectScroll = 1 << 30 // Clamped when drawn
+	}
+}
+
+// drawInspect draws the status, finish reason, usage, rate-limit headers and
+// raw stream lines of the last response over the messages. It follows a
+// response that is still streaming in.
+func (cp *ChatPanel) drawInspect() {
+	cp.screen.HideCursor()
+	left, top := cp.x+1, cp.y+1
+	width, height := cp.width-2, cp.height-5
+	if width < 10 || height < 4 {
+		return
+	}
+	for y := top; y < top+height; y++ {
+		for x := left; x < left+width; x++ {
+			cp.screen.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+		}
+	}
+
+	title := " Last response - ↑↓ scroll, F12/ESC close "
+	titleStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+	for i, r := range []rune(title) {
+		if i < width-2 {
+			cp.screen.SetContent(left+1+i, top, r, nil, titleStyle)
+		}
+	}
+
+	response, ok := cp.chatClient.LastResponse()
+	source := []string{"No request has been sent yet."}
+	if ok {
+		source = response.Lines()
+	}
+
+	// Stream lines are gray, errors red; the summary above them is plain
+	type styledLine struct {
+		text  string
+		style tcell.Style
+	}
+	var lines []styledLine
+	inStream := false
+	for _, line := range source {
+		style := tcell.StyleDefault
+		switch {
+		case strings.HasPrefix(line, "Error: "):
+			style = style.Foreground(tcell.ColorRed)
+		case strings.HasPrefix(line, "Stream: "):
+			inStream = true
+			style = style.Bold(true)
+		case inStream:
+			style = style.Foreground(tcell.ColorGray)
+		}
+		for _, wrapped := range cp.wrapText(line, width-2) {
+			lines = append(lines, styledLine{wrapped, style})
+		}
+	}
+
+	bodyHeight := height - 2
+	cp.inspectScroll = min(cp.inspectScroll, max(0, len(lines)-bodyHeight))
+	for row := 0; row < bodyHeight && cp.inspectScroll+row < len(lines); row++ {
+		line := lines[cp.inspectScroll+row]
+		for j, r := range []rune(line.text) {
+			if j < width-2 {
+				cp.screen.SetContent(left+1+j, top+2+row, r, nil, line.style)
+			}
+		}
+	}
+}
@@ -1,17 +1,67 @@
 package components
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/auditlog"
+	"github.com/hacka-re/cli/internal/browser"
+	"github.com/hacka-re/cli/internal/contextwindow"
+	"github.com/hacka-re/cli/internal/hooks"
+	"github.com/hacka-re/cli/internal/language"
 	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/memory"
+	"github.com/hacka-re/cli/internal/models"
+	"github.com/hacka-re/cli/internal/netguard"
+	"github.com/hacka-re/cli/internal/promptlint"
+	"github.com/hacka-re/cli/internal/sampling"
+	"github.com/hacka-re/cli/internal/snippets"
+	"github.com/hacka-re/cli/internal/templates"
+	"github.com/hacka-re/cli/internal/toolcache"
 	"github.com/hacka-re/cli/internal/tui/internal/core"
 	"github.com/hacka-re/cli/internal/tui/internal/services"
+	"github.com/hacka-re/cli/internal/usage"
+	"github.com/hacka-re/cli/internal/utils"
+	"github.com/hacka-re/cli/internal/webopen"
+	"github.com/hacka-re/cli/pkg/sharelink"
 )
 
+// ChatKeymap lists the keys of the chat panel
+var ChatKeymap = core.RegisterKeymap("chat", "Chat",
+	core.Bind("Enter", "Send (/help lists commands)"),
+	core.Bind("←→ Home/End", "Move the cursor"),
+	core.Bind("↑↓", "Scroll one line"),
+	core.Bind("PgUp/PgDn", "Scroll half a page"),
+	core.Bind("Ctrl+U/Ctrl+D", "Scroll half a page"),
+	core.Bind("Ctrl+Home/End", "Scroll to top/bottom"),
+	core.Bind("o", "Expand/fold the last long message in view (empty input)"),
+	core.Bind("v", "Alternative replies to the last reply in view (empty input)"),
+	core.Bind("F12", "Raw response of the last request"),
+	core.Bind("Tab", "Expand the ;snippet before the cursor"),
+	core.Bind("Ctrl+V", "Paste; an image is attached to the next message"),
+	core.Bind("ESC", "Back to the menu"),
+).WithTextEntry()
+
+// chatSystemKeymap lists the keys while /system edit is open
+var chatSystemKeymap = core.RegisterKeymap("chat.system", "Chat: editing the system prompt",
+	core.Bind("Ctrl+S", "Use for this conversation"),
+	core.Bind("ESC", "Cancel"),
+).WithTextEntry()
+
+// chatSnippetsKeymap lists the keys while /snippets edit is open
+var chatSnippetsKeymap = core.RegisterKeymap("chat.snippets", "Chat: editing snippets",
+	core.Bind("Ctrl+S", "Save the snippets file"),
+	core.Bind("ESC", "Cancel"),
+).WithTextEntry()
+
 // ChatPanel represents the chat interface panel
 type ChatPanel struct {
 	screen   tcell.Screen
@@ -28,23 +78,96 @@ type ChatPanel struct {
 	inputBuffer    string
 	cursorPos      int
 	scrollOffset   int
+	scrolledUp     bool // The user scrolled away from the bottom, so new content doesn't move the view
+	newMessages    bool // Content arrived below the view while scrolled up
 	isStreaming    bool
 	streamingMsg   *ChatMessage
 	streamingMutex sync.Mutex
+	streamingIndex int                // Index of the message being streamed
+	streamGen      int                // Incremented when a stream is cancelled or replaced
+	cancelStream   context.CancelFunc // Cancels the in-flight request
+	queuedMessage  string             // Message waiting for the current response (queue mode)
+	sendingHooks   bool               // beforeSend hooks are running on a message
+
+	lastRedrawRequest time.Time // Throttles redraw requests while streaming
+	redrawScheduled   bool      // A throttled request is waiting to be posted
 
 	// API client
 	chatClient *services.ChatClient
 
+	// Budget tracking
+	usage          *usage.Tracker
+	budgetOverride string // Message the user may resend to bypass the budget
+	lintOverride   string // Message the user may resend despite prompt check warnings
+
+	// Images for the next message sent to a vision model, set with Ctrl+V, /paste-image or a pasted path
+	pendingImages []string
+	modelRegistry *models.ModelRegistry
+
+	// System prompt of this conversation only, set with /system; "" uses the saved one
+	systemOverride string
+	systemEditor   *Editor // Open during /system edit
+
+	// Snippets file being edited with /snippets edit, and why it couldn't be saved
+	snippetsEditor *Editor
+	snippetsPath   string
+	snippetsError  string
+
+	// Scripts run on each message and reply, nil when none are set up
+	hooks *hooks.Hooks
+
+	// Reply language of this session; an empty Setting uses the configured one until /lang sets another
+	language language.Session
+
+	// Alternatives to a reply, shown side by side after v or /variants
+	variants *variantSet
+
+	// The raw response of the last request is shown, toggled with F12 or /debug
+	inspecting    bool
+	inspectScroll int
+
 	// UI state
 	focused      bool
 	needsRedraw  bool
 }
 
+// streamRedrawInterval is the minimum time between redraw requests while streaming
+const streamRedrawInterval = 50 * time.Millisecond
+
 // ChatMessage represents a single chat message
 type ChatMessage struct {
-	Role      string    // user, assistant, system
+	Role      string // user, assistant, system, tool
 	Content   string
 	Timestamp time.Time
+	Model     string   // Model that wrote an assistant message
+	Images    []string // Image files attached to a user message
+	Toggled   bool     // Folded or unfolded by the user, the opposite of foldedByDefault
+
+	Annotation *api.Annotation // Set on assistant messages with /rate
+	Pinned     bool            // Set with /pin: always sent, however long the chat gets
+	LocalOnly  bool            // A notice that is only shown, never sent or saved, e.g. a password
+}
+
+// foldLines is how many lines of a long message are shown while it is folded
+const foldLines = 10
+
+// foldedByDefault reports whether long messages of a role start folded.
+// Tool results and notices such as /system output are rarely read in full.
+func foldedByDefault(role string) bool {
+	return role == "tool" || role == "system"
+}
+
+// folded reports whether the message is shown folded when it is long
+func (m ChatMessage) folded() bool {
+	return foldedByDefault(m.Role) != m.Toggled
+}
+
+// chatLine is one line of the message area
+type chatLine struct {
+	text  string
+	style tcell.Style
+	msg   int  // Index of the message the line belongs to, -1 for spacing
+	fold  bool // The fold marker line; clicking it or pressing o toggles the message
 }
 
 // NewChatPanel creates a new chat panel
@@ -68,11 +191,18 @@ func NewChatPanel(screen tcell.Screen, config *core.ConfigManager, state *core.A
 		messages:   make([]ChatMessage, 0),
 		focused:    true,
 		chatClient: services.NewChatClient(config),
+		usage:      usage.NewTracker(usage.DefaultPath()),
+
+		modelRegistry: models.NewModelRegistry(),
 	}
 
 	// Load existing messages from state if any
 	cp.loadMessagesFromState()
 
+	// Restore unsent input (e.g. after crash recovery)
+	cp.inputBuffer = state.GetCurrentInput()
+	cp.cursorPos = len(cp.inputBuffer)
+
 	// Add welcome message if no messages exist
 	if len(cp.messages) == 0 {
 		cp.messages = append(cp.messages, ChatMessage{
@@ -81,6 +211,7 @@ func NewChatPanel(screen tcell.Screen, config *core.ConfigManager, state *core.A
 			Timestamp: time.Now(),
 		})
 	}
+	cp.loadHooks()
 
 	return cp
 }
@@ -107,6 +238,9 @@ func (cp *ChatPanel) saveMessagesToState() {
 
 	// Add each message to state
 	for _, msg := range cp.messages {
+		if msg.LocalOnly {
+			continue
+		}
 		// Only add if not already in state
 		cp.state.AddMessage(msg.Role, msg.Content)
 	}
@@ -122,6 +256,17 @@ func (cp *ChatPanel) HandleMouse(ev *tcell.EventMouse) {
 		return
 	}
 
+	// The response panel scrolls on its own and takes no clicks
+	if cp.inspecting {
+		switch button {
+		case tcell.WheelUp:
+			cp.inspectScroll = max(0, cp.inspectScroll-3)
+		case tcell.WheelDown:
+			cp.inspectScroll += 3
+		}
+		return
+	}
+
 	// Handle scroll wheel
 	switch button {
 	case tcell.WheelUp:
@@ -130,6 +275,25 @@ func (cp *ChatPanel) HandleMouse(ev *tcell.EventMouse) {
 		cp.scrollDown(3) // Scroll down 3 lines per wheel notch
 	}
 
+	// Click on the new messages badge - jump to them
+	if button&tcell.Button1 != 0 && cp.newMessages {
+		if badgeX, badgeY, badge := cp.newMessagesBadge(); y == badgeY && x >= badgeX && x < badgeX+len([]rune(badge)) {
+			cp.scrollToBottom()
+			return
+		}
+	}
+
+	// Click on a fold marker - expand or fold its message
+	if button&tcell.Button1 != 0 && x < cp.x+cp.width-2 {
+		cp.streamingMutex.Lock()
+		lines := cp.messageLines(cp.messages)
+		cp.streamingMutex.Unlock()
+		if i := cp.scrollOffset + y - (cp.y + 2); i >= 0 && i < len(lines) && lines[i].fold {
+			cp.toggleFold(lines[i].msg, lines)
+			return
+		}
+	}
+
 	// Handle click on scroll bar
 	scrollBarX := cp.x + cp.width - 2
 	messageAreaHeight := cp.height - 5
@@ -149,13 +313,70 @@ func (cp *ChatPanel) HandleMouse(ev *tcell.EventMouse) {
 				if cp.scrollOffset < 0 {
 					cp.scrollOffset = 0
 				}
+				cp.noteScroll()
 			}
 		}
 	}
 }
 
+// Keymap returns the bindings of the current mode
+func (cp *ChatPanel) Keymap() *core.Keymap {
+	if cp.systemEditor != nil {
+		return chatSystemKeymap
+	}
+	if cp.snippetsEditor != nil {
+		return chatSnippetsKeymap
+	}
+	if cp.variants != nil {
+		return chatVariantsKeymap
+	}
+	if cp.inspecting {
+		return chatInspectKeymap
+	}
+	return ChatKeymap
+}
+
 // HandleInput processes keyboard input
 func (cp *ChatPanel) HandleInput(ev *tcell.EventKey) bool {
+	// The system prompt editor takes all keys while open
+	if cp.systemEditor != nil {
+		switch ev.Key() {
+		case tcell.KeyCtrlS:
+			cp.setSystemOverride(cp.systemEditor.GetText())
+			cp.systemEditor = nil
+		case tcell.KeyEscape:
+			cp.systemEditor = nil
+		default:
+			cp.systemEditor.HandleInput(ev)
+		}
+		return false
+	}
+
+	// So does the snippets editor
+	if cp.snippetsEditor != nil {
+		switch ev.Key() {
+		case tcell.KeyCtrlS:
+			cp.saveSnippets()
+		case tcell.KeyEscape:
+			cp.snippetsEditor = nil
+		default:
+			cp.snippetsEditor.HandleInput(ev)
+		}
+		return false
+	}
+
+	// And the alternative replies
+	if cp.variants != nil {
+		cp.handleVariantsInput(ev)
+		return false
+	}
+
+	// And the response panel
+	if cp.inspecting {
+		cp.handleInspectInput(ev)
+		return false
+	}
+
 	switch ev.Key() {
 	case tcell.KeyEscape:
 		// Save state and return to main menu
@@ -187,6 +408,18 @@ func (cp *ChatPanel) HandleInput(ev *tcell.EventKey) bool {
 		}
 		return false
 
+	case tcell.KeyCtrlV:
+		cp.pasteClipboard()
+		return false
+
+	case tcell.KeyF12:
+		cp.toggleInspect()
+		return false
+
+	case tcell.KeyTab:
+		cp.expandSnippet()
+		return false
+
 	case tcell.KeyUp:
 		// Scroll up one line
 		cp.scrollUp(1)
@@ -214,6 +447,7 @@ func (cp *ChatPanel) HandleInput(ev *tcell.EventKey) bool {
 		if ev.Modifiers()&tcell.ModCtrl != 0 {
 			// Ctrl+Home - scroll to top of messages
 			cp.scrollOffset = 0
+			cp.noteScroll()
 		} else {
 			// Home - move cursor to beginning of input
 			cp.cursorPos = 0
@@ -248,6 +482,18 @@ func (cp *ChatPanel) HandleInput(ev *tcell.EventKey) bool {
 				return false
 			}
 		}
+		// o with nothing typed expands or folds a long message instead of starting the input
+		if r == 'o' && cp.inputBuffer == "" {
+			if cp.toggleFoldInView() {
+				return false
+			}
+		}
+		// So does v, asking for alternatives to the last reply in view
+		if r == 'v' && cp.inputBuffer == "" {
+			if cp.openVariantsInView() {
+				return false
+			}
+		}
 		cp.inputBuffer = cp.inputBuffer[:cp.cursorPos] + string(r) + cp.inputBuffer[cp.cursorPos:]
 		cp.cursorPos++
 		return false
@@ -268,6 +514,17 @@ func (cp *ChatPanel) sendMessage() {
 		log.Info("[ChatPanel] Sending message: %s", message)
 	}
 
+	// A path to an image, e.g. a file dragged into the terminal, attaches it;
+	// kiosks don't read local files
+	if path := imagePath(message); path != "" && !cp.config.Get().Kiosk {
+		cp.inputBuffer = ""
+		cp.cursorPos = 0
+		if err := cp.attachImage(path); err != nil {
+			cp.addSystemMessage("Could not attach the image: " + err.Error())
+		}
+		return
+	}
+
 	// Handle commands
 	if strings.HasPrefix(message, "/") {
 		cp.handleCommand(message)
@@ -276,58 +533,725 @@ func (cp *ChatPanel) sendMessage() {
 		return
 	}
 
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+
+	// Check the input lock first, so a blocked Enter doesn't run the hooks
+	if cp.inputLocked() {
+		return
+	}
+
+	// Let the user's hooks change or stop the message; a stopped one stays in the input
+	if !cp.hooks.Empty() && len(cp.hooks.BeforeSend) > 0 {
+		cp.sendWithHooks(message)
+		return
+	}
+	cp.dispatchMessage(message, message)
+}
+
+// inputLocked reports whether Enter must leave the input alone: hooks are
+// still running on the last message, or a response is streaming and the lock
+// mode blocks or the queue is full (must be called with streamingMutex held)
+func (cp *ChatPanel) inputLocked() bool {
+	if cp.sendingHooks {
+		return true
+	}
+	if !cp.isStreaming {
+		return false
+	}
+	switch cp.config.Get().InputLockMode {
+	case core.InputLockQueue:
+		// Only one message can wait; keep further input in the buffer
+		return cp.queuedMessage != ""
+	case core.InputLockReplace:
+		return false
+	default:
+		// Block: keep the input until the response is complete
+		return true
+	}
+}
+
+// dispatchMessage sends message, typed as input, or queues it behind the
+// current response (must be called with streamingMutex held)
+func (cp *ChatPanel) dispatchMessage(input, message string) {
+	// The stream may have changed while hooks ran, so decide again
+	if cp.isStreaming {
+		switch cp.config.Get().InputLockMode {
+		case core.InputLockQueue:
+			if cp.queuedMessage != "" {
+				return
+			}
+			cp.queuedMessage = message
+			cp.clearInput(input)
+			return
+
+		case core.InputLockReplace:
+			cp.cancelCurrentStream()
+
+		default:
+			return
+		}
+	}
+
+	// Keep the input if the prompt check or the budget blocks it, so Enter again overrides
+	if !cp.checkPrompt(message) || !cp.checkBudget(message) {
+		return
+	}
+	cp.lintOverride = ""
+
+	cp.clearInput(input)
+	cp.startMessage(message)
+}
+
+// clearInput empties the input if it still holds text, which the user may
+// have edited while hooks ran (must be called with streamingMutex held)
+func (cp *ChatPanel) clearInput(text string) {
+	if strings.TrimSpace(cp.inputBuffer) == text {
+		cp.inputBuffer = ""
+		cp.cursorPos = 0
+	}
+}
+
+// startMessage adds a user message and streams the response (must be called with streamingMutex held)
+func (cp *ChatPanel) startMessage(message string) {
 	// Add user message
 	cp.messages = append(cp.messages, ChatMessage{
-		Role:      "user",
-		Content:   message,
+		Role:      "user",
+		Content:   message,
+		Timestamp: time.Now(),
+		Images:    cp.takeImages(),
+	})
+
+	// Save to state
+	cp.state.AddMessage("user", message)
+
+	// Auto-scroll to bottom
+	cp.scrollToBottom()
+
+	// Mark as streaming
+	cp.isStreaming = true
+
+	// Send to API in background
+	ctx, cancel := context.WithCancel(context.Background())
+	cp.cancelStream = cancel
+	go cp.streamResponse(ctx, cp.streamGen)
+}
+
+// cancelCurrentStream aborts the in-flight response, keeping any partial text (must be called with streamingMutex held)
+func (cp *ChatPanel) cancelCurrentStream() {
+	if cp.cancelStream != nil {
+		cp.cancelStream()
+		cp.cancelStream = nil
+	}
+	cp.streamGen++
+
+	// Keep what was received so far, or drop the placeholder if nothing arrived
+	if cp.streamingMsg != nil && cp.streamingIndex < len(cp.messages) {
+		if cp.messages[cp.streamingIndex].Content == "" {
+			cp.messages = append(cp.messages[:cp.streamingIndex], cp.messages[cp.streamingIndex+1:]...)
+		} else {
+			cp.messages[cp.streamingIndex].Content += " [cancelled]"
+			cp.state.AddMessage("assistant", cp.messages[cp.streamingIndex].Content)
+		}
+	}
+
+	cp.isStreaming = false
+	cp.streamingMsg = nil
+
+	if log := logger.Get(); log != nil {
+		log.Info("[ChatPanel] Cancelled current stream")
+	}
+}
+
+// finishStream sends a queued message once the response completes (must be called with streamingMutex held)
+func (cp *ChatPanel) finishStream(failed bool) {
+	cp.cancelStream = nil
+	if cp.queuedMessage == "" {
+		return
+	}
+
+	message := cp.queuedMessage
+	cp.queuedMessage = ""
+
+	// On failure, hand the queued text back to the user instead of sending it blindly
+	if failed {
+		if cp.inputBuffer == "" {
+			cp.inputBuffer = message
+			cp.cursorPos = len(message)
+		}
+		return
+	}
+
+	if !cp.checkBudget(message) {
+		if cp.inputBuffer == "" {
+			cp.inputBuffer = message
+			cp.cursorPos = len(message)
+		}
+		return
+	}
+
+	cp.startMessage(message)
+}
+
+// budget returns the configured budget limits
+func (cp *ChatPanel) budget() usage.Budget {
+	config := cp.config.Get()
+	return usage.Budget{
+		MaxTokensPerRequest: config.MaxTokensPerRequest,
+		MaxCostPerSession:   config.MaxCostPerSession,
+		MaxCostPerDay:       config.MaxCostPerDay,
+	}
+}
+
+// checkPrompt reports whether message may be sent when prompt checks are on;
+// a message with warnings is allowed when sent again (must be called with streamingMutex held)
+func (cp *ChatPanel) checkPrompt(message string) bool {
+	if !cp.config.Get().LintPrompts {
+		return true
+	}
+	warnings := promptlint.Lint(message)
+	if len(warnings) == 0 || cp.lintOverride == message {
+		return true
+	}
+
+	cp.lintOverride = message
+	lines := []string{"Before sending:"}
+	for _, w := range warnings {
+		lines = append(lines, "• "+w.Message)
+	}
+	lines = append(lines, "Press Enter again to send anyway, or edit the message.")
+	cp.messages = append(cp.messages, ChatMessage{
+		Role:      "system",
+		Content:   strings.Join(lines, "\n"),
+		Timestamp: time.Now(),
+	})
+	cp.scrollToBottom()
+	return false
+}
+
+// checkBudget reports whether message may be sent; a blocked message is allowed when sent again (must be called with streamingMutex held)
+func (cp *ChatPanel) checkBudget(message string) bool {
+	promptTokens := usage.EstimateTokens(cp.systemPrompt()) + usage.EstimateTokens(message)
+	for _, msg := range cp.messages {
+		if msg.Role != "system" {
+			promptTokens += usage.EstimateTokens(msg.Content)
+		}
+	}
+
+	err := cp.usage.Check(cp.config.Get().Model, promptTokens, cp.budget())
+	if err == nil {
+		cp.budgetOverride = ""
+		return true
+	}
+
+	if cp.budgetOverride == message {
+		if log := logger.Get(); log != nil {
+			log.Warn("[ChatPanel] Budget override: %v", err)
+		}
+		cp.budgetOverride = ""
+		return true
+	}
+
+	if log := logger.Get(); log != nil {
+		log.Warn("[ChatPanel] Request blocked: %v", err)
+	}
+	cp.budgetOverride = message
+	cp.messages = append(cp.messages, ChatMessage{
+		Role:      "system",
+		Content:   fmt.Sprintf("%v. Press Enter again to send anyway.", err),
+		Timestamp: time.Now(),
+	})
+	cp.scrollToBottom()
+	return false
+}
+
+// handleCommand processes chat commands
+func (cp *ChatPanel) handleCommand(cmd string) {
+	switch {
+	case strings.HasPrefix(cmd, "/clear"):
+		cp.streamingMutex.Lock()
+		cp.messages = []ChatMessage{}
+		cp.scrollOffset = 0
+		cp.streamingMutex.Unlock()
+		cp.setSystemOverride("")
+		cp.chatClient.SetSampling(sampling.Override{})
+
+	case cmd == "/paste-image" || strings.HasPrefix(cmd, "/paste-image "):
+		cp.handlePasteImageCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/paste-image")))
+
+	case cmd == "/artifacts" || strings.HasPrefix(cmd, "/artifacts "):
+		cp.handleArtifactsCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/artifacts")))
+
+	case cmd == "/rate" || strings.HasPrefix(cmd, "/rate "):
+		cp.handleRateCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/rate")))
+
+	case cmd == "/snippets" || strings.HasPrefix(cmd, "/snippets "):
+		cp.handleSnippetsCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/snippets")))
+
+	case cmd == "/lang" || strings.HasPrefix(cmd, "/lang "):
+		cp.handleLangCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/lang")))
+
+	case cmd == "/pin" || strings.HasPrefix(cmd, "/pin "):
+		cp.handlePinCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/pin")), true)
+
+	case cmd == "/unpin" || strings.HasPrefix(cmd, "/unpin "):
+		cp.handlePinCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/unpin")), false)
+
+	case cmd == "/variants" || strings.HasPrefix(cmd, "/variants "):
+		cp.handleVariantsCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/variants")))
+
+	case cmd == "/debug":
+		cp.toggleInspect()
+
+	case cmd == "/cache" || strings.HasPrefix(cmd, "/cache "):
+		cp.handleCacheCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/cache")))
+
+	case cmd == "/open-web":
+		cp.handleOpenWebCommand()
+
+	case cmd == "/system" || strings.HasPrefix(cmd, "/system "):
+		cp.handleSystemCommand(strings.TrimSpace(strings.TrimPrefix(cmd, "/system")))
+
+	case strings.HasPrefix(cmd, "/help"):
+		cp.addSystemMessage("Available commands:\n/clear - Clear chat history\n/system <text> - Use a different system prompt for this conversation\n/system edit - Edit the system prompt of this conversation\n/system reset - Go back to the saved system prompt\n/paste-image - Attach the clipboard image to the next message (clear removes attached images)\n/artifacts - List the files saved this session (clean removes old sessions)\n/rate up|down [note] - Review the last reply (clear removes the review)\n/snippets [edit] - List the ;snippets that Tab expands, or edit them\n/lang [code|auto|off] - Show or set the reply language for this session\n/pin [reply] - Always send your last message (or the last reply), however long the chat gets\n/unpin [all] - Remove the latest pin (or all of them)\n/variants [n] - Write n alternatives to the last reply and pick one to continue with\n/debug - Show the raw response of the last request (also F12)\n/cache [clear [tool]] - Show or empty the MCP tool result cache\n/open-web - Continue this chat in the web app on a local server\n/help - Show this help\no - Expand or fold a long message (with nothing typed)\nv - Alternatives to the last reply in view (with nothing typed)\nESC - Return to main menu")
+
+	default:
+		cp.addSystemMessage("Unknown command: " + cmd)
+	}
+}
+
+// handleSystemCommand shows, replaces or edits the system prompt of this conversation.
+// The saved configuration is left as it is.
+func (cp *ChatPanel) handleSystemCommand(arg string) {
+	if arg != "" && cp.config.Get().Kiosk {
+		cp.addSystemMessage("The system prompt can't be changed in kiosk mode.")
+		return
+	}
+	if arg != "" && cp.config.Get().LockedByLink {
+		cp.addSystemMessage("The system prompt is locked by the share link this configuration came from.")
+		return
+	}
+
+	// The prompt is read under the lock; setSystemOverride takes it itself
+	cp.streamingMutex.Lock()
+	override, prompt := cp.systemOverride, cp.systemPrompt()
+	cp.streamingMutex.Unlock()
+
+	switch arg {
+	case "":
+		content := "No system prompt is set. Use /system <text> to set one for this conversation."
+		if override != "" {
+			content = "System prompt for this conversation (/system reset restores the saved one):\n" + override
+		} else if saved := cp.config.Get().SystemPrompt; saved != "" {
+			content = "Saved system prompt:\n" + saved
+		}
+		cp.addSystemMessage(content)
+
+	case "edit":
+		cp.systemEditor = cp.newOverlayEditor(prompt)
+
+	case "reset":
+		if override == "" {
+			cp.addSystemMessage("The saved system prompt is already in use.")
+			return
+		}
+		cp.setSystemOverride("")
+		cp.addSystemMessage("Using the saved system prompt again.")
+
+	default:
+		cp.setSystemOverride(arg)
+		cp.addSystemMessage("System prompt replaced for this conversation. The saved one is unchanged; /system reset restores it.")
+	}
+}
+
+// handleRateCommand rates the last reply up or down with an optional note, or
+// with "clear" removes its rating
+func (cp *ChatPanel) handleRateCommand(arg string) {
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+
+	last := -1
+	for i := len(cp.messages) - 1; i >= 0; i-- {
+		if cp.messages[i].Role == "assistant" {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		cp.addSystemMessageLocked("There is no reply to rate yet.")
+		return
+	}
+
+	word, note, _ := strings.Cut(arg, " ")
+	if word == "clear" {
+		cp.messages[last].Annotation = nil
+		cp.addSystemMessageLocked("Removed the review of the last reply.")
+		return
+	}
+	rating, err := api.ParseRating(word)
+	if err != nil || rating == "" {
+		cp.addSystemMessageLocked("Usage: /rate up|down [note], or /rate clear")
+		return
+	}
+	cp.messages[last].Annotation = &api.Annotation{Rating: rating, Note: strings.TrimSpace(note)}
+	cp.addSystemMessageLocked("Reviewed the last reply: " + cp.messages[last].Annotation.String())
+}
+
+// newOverlayEditor returns an editor centered over the messages
+func (cp *ChatPanel) newOverlayEditor(text string) *Editor {
+	width, height := min(80, cp.width-4), min(20, cp.height-4)
+	editor := NewEditor(cp.screen)
+	editor.SetDimensions(width, height)
+	editor.SetPosition(cp.x+(cp.width-width)/2, cp.y+(cp.height-height)/2)
+	editor.SetText(text)
+	return editor
+}
+
+// handleSnippetsCommand lists the snippets, or opens the closest snippets file in an editor
+func (cp *ChatPanel) handleSnippetsCommand(arg string) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "."
+	}
+	switch arg {
+	case "":
+		defined, err := snippets.Load(cwd)
+		if err != nil {
+			cp.addSystemMessage(err.Error())
+			return
+		}
+		if len(defined) == 0 {
+			cp.addSystemMessage("No snippets yet. /snippets edit creates " + snippets.EditPath(cwd) + " with examples.")
+			return
+		}
+		lines := []string{"Snippets (type ;name and press Tab):"}
+		for _, name := range snippets.Names(defined) {
+			lines = append(lines, snippets.Trigger+name+" - "+utils.Truncate(strings.Join(strings.Fields(defined[name]), " "), 60))
+		}
+		for _, path := range snippets.Paths(cwd) {
+			lines = append(lines, "From "+path)
+		}
+		cp.addSystemMessage(strings.Join(lines, "\n"))
+
+	case "edit":
+		if cp.config.Get().Kiosk {
+			cp.addSystemMessage("Snippets can't be edited in kiosk mode.")
+			return
+		}
+		cp.snippetsPath = snippets.EditPath(cwd)
+		content := snippets.Example
+		if data, err := os.ReadFile(cp.snippetsPath); err == nil {
+			content = string(data)
+		} else if !os.IsNotExist(err) {
+			cp.addSystemMessage("Could not read the snippets: " + err.Error())
+			return
+		}
+		cp.snippetsError = ""
+		cp.snippetsEditor = cp.newOverlayEditor(content)
+
+	default:
+		cp.addSystemMessage("Usage: /snippets, or /snippets edit")
+	}
+}
+
+// saveSnippets writes the snippets editor's text, keeping the editor open if it isn't valid
+func (cp *ChatPanel) saveSnippets() {
+	if err := snippets.Save(cp.snippetsPath, cp.snippetsEditor.GetText()); err != nil {
+		cp.snippetsError = err.Error()
+		return
+	}
+	cp.snippetsEditor = nil
+	cp.addSystemMessage("Saved " + cp.snippetsPath)
+}
+
+// expandSnippet replaces the ;name before the cursor with its snippet
+func (cp *ChatPanel) expandSnippet() {
+	defined, err := snippets.Load(".")
+	if err != nil {
+		cp.addSystemMessage(err.Error())
+		return
+	}
+
+	// A hook goroutine may clear the input meanwhile, under the lock
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	cursor := utf8.RuneCountInString(cp.inputBuffer[:cp.cursorPos])
+	expanded, at, name, ok := snippets.Expand(cp.inputBuffer, cursor, defined)
+	if !ok {
+		if name != "" {
+			cp.addSystemMessageLocked("No snippet " + snippets.Trigger + name + "; /snippets lists them.")
+		}
+		return
+	}
+	cp.inputBuffer = expanded
+	cp.cursorPos = len(string([]rune(expanded)[:at]))
+}
+
+// handleLangCommand shows or sets the reply language of this session
+func (cp *ChatPanel) handleLangCommand(arg string) {
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+
+	if arg == "" {
+		session := cp.languageSession()
+		cp.addSystemMessageLocked("Reply language: " + session.String() + ". Use /lang <code> (e.g. /lang sv), /lang auto to follow your messages, or /lang off.")
+		return
+	}
+	setting, err := language.Parse(arg)
+	if err != nil {
+		cp.addSystemMessageLocked(err.Error())
+		return
+	}
+	cp.language = language.Session{Setting: setting}
+	switch setting {
+	case language.Off:
+		cp.addSystemMessageLocked("Reply language off: the model picks the language.")
+	case language.Auto:
+		cp.addSystemMessageLocked("Replies follow the language of your latest message.")
+	default:
+		cp.addSystemMessageLocked("Replies are in " + language.Name(setting) + " for the rest of the session.")
+	}
+}
+
+// languageSession returns the reply language of this session, falling back
+// to the configured one. The caller holds streamingMutex.
+func (cp *ChatPanel) languageSession() language.Session {
+	session := cp.language
+	if session.Setting == "" {
+		session.Setting, _ = language.Parse(cp.config.Get().Language)
+		if session.Setting == "" {
+			session.Setting = language.Off
+		}
+	}
+	return session
+}
+
+// handlePinCommand pins your last message, or the last reply with "reply", so
+// it is kept when older messages are left out to fit the context window.
+// Unpinning removes the latest pin, or every pin with "all".
+func (cp *ChatPanel) handlePinCommand(arg string, pin bool) {
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+
+	if !pin {
+		unpinned := 0
+		for i := len(cp.messages) - 1; i >= 0; i-- {
+			if cp.messages[i].Pinned {
+				cp.messages[i].Pinned = false
+				unpinned++
+				if arg != "all" {
+					break
+				}
+			}
+		}
+		switch unpinned {
+		case 0:
+			cp.addSystemMessageLocked("No messages are pinned.")
+		case 1:
+			cp.addSystemMessageLocked("Unpinned a message.")
+		default:
+			cp.addSystemMessageLocked(fmt.Sprintf("Unpinned %d messages.", unpinned))
+		}
+		return
+	}
+
+	role := "user"
+	switch arg {
+	case "":
+	case "reply":
+		role = "assistant"
+	default:
+		cp.addSystemMessageLocked("Usage: /pin, or /pin reply")
+		return
+	}
+	for i := len(cp.messages) - 1; i >= 0; i-- {
+		if cp.messages[i].Role == role {
+			cp.messages[i].Pinned = true
+			cp.addSystemMessageLocked("Pinned: it is kept whenever older messages are left out to fit the context window.")
+			return
+		}
+	}
+	cp.addSystemMessageLocked("There is no message to pin yet.")
+}
+
+// setSystemOverride sets the system prompt of this conversation, "" to use the saved one
+func (cp *ChatPanel) setSystemOverride(prompt string) {
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	cp.systemOverride = strings.TrimSpace(prompt)
+}
+
+// StartTemplate starts a new conversation from tmpl: its system prompt and
+// sampling are used for this conversation and its opening message comes first
+func (cp *ChatPanel) StartTemplate(tmpl templates.Template) {
+	cp.closeVariants()
+	cp.streamingMutex.Lock()
+	cp.messages = []ChatMessage{}
+	cp.scrollOffset = 0
+	cp.streamingMutex.Unlock()
+	cp.setSystemOverride(tmpl.SystemPrompt)
+	cp.chatClient.SetSampling(tmpl.Sampling)
+
+	notice := fmt.Sprintf("Started from the %s template. Its system prompt is used for this conversation; /system shows it.", tmpl.Title)
+	if !tmpl.Sampling.IsZero() {
+		notice += fmt.Sprintf(" Replies use %s.", tmpl.Sampling)
+	}
+	if len(tmpl.Functions) > 0 {
+		notice += fmt.Sprintf(" It is meant for the %s default functions, which chat --template enables.", strings.Join(tmpl.Functions, " and "))
+	}
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	cp.addSystemMessageLocked(notice)
+	cp.messages = append(cp.messages, ChatMessage{
+		Role:      "assistant",
+		Content:   tmpl.Opening,
+		Timestamp: time.Now(),
+	})
+	cp.state.AddMessage("assistant", tmpl.Opening)
+	cp.scrollToBottom()
+}
+
+// systemPrompt returns the system prompt requests of this conversation use
+func (cp *ChatPanel) systemPrompt() string {
+	if cp.systemOverride != "" {
+		return cp.systemOverride
+	}
+	return cp.config.Get().SystemPrompt
+}
+
+// lastUserMessage returns the content of the latest user message
+func lastUserMessage(messages []ChatMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// handleCacheCommand shows the size of the MCP tool result cache, or empties
+// it for one tool or all of them with "clear [tool]"
+func (cp *ChatPanel) handleCacheCommand(arg string) {
+	cache := toolcache.New(toolcache.Dir())
+	sub, tool, _ := strings.Cut(arg, " ")
+	switch sub {
+	case "":
+		stats, err := cache.Stats()
+		if err != nil {
+			cp.addSystemMessage(err.Error())
+			return
+		}
+		cp.addSystemMessage(fmt.Sprintf("%d cached tool result(s), %d bytes. Use /cache clear [tool] to empty the cache.", stats.Entries, stats.Bytes))
+	case "clear":
+		removed, err := cache.Clear(strings.TrimSpace(tool))
+		if err != nil {
+			cp.addSystemMessage(err.Error())
+			return
+		}
+		cp.addSystemMessage(fmt.Sprintf("Removed %d cached tool result(s).", removed))
+	default:
+		cp.addSystemMessage("Usage: /cache [clear [tool]]")
+	}
+}
+
+// handleOpenWebCommand opens the conversation and the configuration in the
+// web app on a local server, starting one if none is running. The password
+// of the temporary link is shown and copied to the clipboard.
+func (cp *ChatPanel) handleOpenWebCommand() {
+	cfg := cp.config.Get()
+	if cfg.Kiosk {
+		cp.addSystemMessage("The web app can't be opened in kiosk mode.")
+		return
+	}
+
+	shared := &sharelink.Config{}
+	if cfg.ShareSource != nil {
+		copied := *cfg.ShareSource
+		shared = &copied
+	}
+	shared.APIKey, shared.BaseURL, shared.Model = cfg.APIKey, cfg.BaseURL, cfg.Model
+	cp.streamingMutex.Lock()
+	shared.SystemPrompt = cfg.SystemPrompt
+	if cp.systemOverride != "" {
+		shared.SystemPrompt = cp.systemOverride
+	}
+	shared.Messages = nil
+	for _, msg := range cp.messages {
+		if (msg.Role == "user" || msg.Role == "assistant") && msg.Content != "" {
+			message := sharelink.Message{Role: msg.Role, Content: msg.Content}
+			if msg.Annotation != nil {
+				message.Rating, message.Note = msg.Annotation.Rating, msg.Annotation.Note
+			}
+			shared.Messages = append(shared.Messages, message)
+		}
+	}
+	cp.streamingMutex.Unlock()
+
+	result, err := webopen.Open(shared, browser.DefaultOpener())
+	if err != nil {
+		cp.addSystemMessage("Could not open the web app: " + err.Error())
+		return
+	}
+	notice := fmt.Sprintf("Opened %d messages in the browser.\nPassword: %s", len(shared.Messages), result.Password)
+	if utils.SetClipboardContent(result.Password) == nil {
+		notice += " (copied to the clipboard)"
+	}
+	if result.Started {
+		notice = "Started a local web server for this session.\n" + notice
+	}
+	// The password is only for the user, so the notice never reaches the model
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	cp.messages = append(cp.messages, ChatMessage{
+		Role:      "system",
+		Content:   notice,
 		Timestamp: time.Now(),
+		LocalOnly: true,
 	})
-
-	// Save to state
-	cp.state.AddMessage("user", message)
-
-	// Clear input
-	cp.inputBuffer = ""
-	cp.cursorPos = 0
-
-	// Auto-scroll to bottom
 	cp.scrollToBottom()
-
-	// Mark as streaming
-	cp.isStreaming = true
-
-	// Send to API in background
-	go cp.streamResponse()
 }
 
-// handleCommand processes chat commands
-func (cp *ChatPanel) handleCommand(cmd string) {
-	switch {
-	case strings.HasPrefix(cmd, "/clear"):
-		cp.messages = []ChatMessage{}
-		cp.scrollOffset = 0
-
-	case strings.HasPrefix(cmd, "/help"):
-		cp.messages = append(cp.messages, ChatMessage{
-			Role:    "system",
-			Content: "Available commands:\n/clear - Clear chat history\n/help - Show this help\nESC - Return to main menu",
-			Timestamp: time.Now(),
-		})
-		cp.scrollToBottom()
+// addSystemMessage shows a notice in the chat
+func (cp *ChatPanel) addSystemMessage(content string) {
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	cp.addSystemMessageLocked(content)
+}
 
-	default:
-		cp.messages = append(cp.messages, ChatMessage{
-			Role:      "system",
-			Content:   "Unknown command: " + cmd,
-			Timestamp: time.Now(),
-		})
-		cp.scrollToBottom()
-	}
+// addSystemMessageLocked shows a notice in the chat (must be called with
+// streamingMutex held)
+func (cp *ChatPanel) addSystemMessageLocked(content string) {
+	cp.messages = append(cp.messages, ChatMessage{
+		Role:      "system",
+		Content:   content,
+		Timestamp: time.Now(),
+	})
+	cp.scrollToBottom()
 }
 
 // scrollToBottom scrolls to the bottom of the message list
 func (cp *ChatPanel) scrollToBottom() {
 	cp.scrollOffset = cp.calculateMaxScroll()
+	cp.scrolledUp = false
+	cp.newMessages = false
+}
+
+// followOutput keeps streamed content in view. If the user scrolled up to
+// read, the view stays where it is and a badge says there is more below.
+func (cp *ChatPanel) followOutput() {
+	if cp.scrolledUp {
+		cp.newMessages = true
+		return
+	}
+	cp.scrollToBottom()
+}
+
+// noteScroll records whether the user's scrolling left the bottom of the list
+func (cp *ChatPanel) noteScroll() {
+	cp.scrolledUp = cp.scrollOffset < cp.calculateMaxScroll()
+	if !cp.scrolledUp {
+		cp.newMessages = false
+	}
 }
 
 // scrollUp scrolls up by the specified number of lines
@@ -336,6 +1260,7 @@ func (cp *ChatPanel) scrollUp(lines int) {
 	if cp.scrollOffset < 0 {
 		cp.scrollOffset = 0
 	}
+	cp.noteScroll()
 }
 
 // scrollDown scrolls down by the specified number of lines
@@ -345,17 +1270,138 @@ func (cp *ChatPanel) scrollDown(lines int) {
 	if cp.scrollOffset > maxScroll {
 		cp.scrollOffset = maxScroll
 	}
+	cp.noteScroll()
+}
+
+// toggleFoldInView expands or folds the last long message that is at least
+// partly visible, or the last long message if none is. It reports whether
+// there was one.
+func (cp *ChatPanel) toggleFoldInView() bool {
+	cp.streamingMutex.Lock()
+	lines := cp.messageLines(cp.messages)
+	cp.streamingMutex.Unlock()
+
+	visibleEnd := cp.scrollOffset + cp.height - 7
+	target, inView := -1, false
+	for i, line := range lines {
+		if !line.fold {
+			continue
+		}
+		visible := i >= cp.scrollOffset && cp.messageStart(lines, line.msg) < visibleEnd
+		if visible || !inView {
+			target, inView = line.msg, visible
+		}
+	}
+	if target == -1 {
+		return false
+	}
+	cp.toggleFold(target, lines)
+	return true
+}
+
+// toggleFold expands or folds message i, keeping its first line in view.
+// lines is the layout before the change.
+func (cp *ChatPanel) toggleFold(i int, lines []chatLine) {
+	cp.streamingMutex.Lock()
+	if i < len(cp.messages) {
+		cp.messages[i].Toggled = !cp.messages[i].Toggled
+	}
+	cp.streamingMutex.Unlock()
+
+	if start := cp.messageStart(lines, i); start < cp.scrollOffset {
+		cp.scrollOffset = start
+	}
+	if maxScroll := cp.calculateMaxScroll(); cp.scrollOffset > maxScroll {
+		cp.scrollOffset = maxScroll
+	}
+}
+
+// messageStart returns the index of the first line of message i
+func (cp *ChatPanel) messageStart(lines []chatLine, i int) int {
+	for j, line := range lines {
+		if line.msg == i {
+			return j
+		}
+	}
+	return len(lines)
+}
+
+// messageHeader returns the prefix of a message: its role, and the model and
+// time when the display settings ask for them
+func messageHeader(msg ChatMessage, config *core.Config) string {
+	parts := []string{msg.Role}
+	if config.ShowModelName && msg.Role == "assistant" && msg.Model != "" {
+		parts = append(parts, msg.Model)
+	}
+	if config.ShowTimestamps && !msg.Timestamp.IsZero() {
+		parts = append(parts, msg.Timestamp.Format("15:04"))
+	}
+	if !msg.Annotation.IsEmpty() {
+		parts = append(parts, msg.Annotation.String())
+	}
+	if msg.Pinned {
+		parts = append(parts, "📌")
+	}
+	return "[" + strings.Join(parts, " · ") + "] "
+}
+
+// messageLines lays the messages out as wrapped lines, folding long ones
+func (cp *ChatPanel) messageLines(messages []ChatMessage) []chatLine {
+	config := cp.config.Get()
+	var lines []chatLine
+	markerStyle := tcell.StyleDefault.Foreground(tcell.ColorGray).Italic(true)
+	for i, msg := range messages {
+		// Choose color based on role
+		var style tcell.Style
+		switch msg.Role {
+		case "user":
+			style = tcell.StyleDefault.Foreground(tcell.ColorBlue)
+		case "assistant":
+			style = tcell.StyleDefault.Foreground(tcell.ColorGreen)
+		case "system":
+			style = tcell.StyleDefault.Foreground(tcell.ColorYellow)
+		case "tool":
+			style = tcell.StyleDefault.Foreground(tcell.ColorDarkCyan)
+		default:
+			style = tcell.StyleDefault
+		}
+
+		// Format and wrap message
+		content := msg.Content
+		for _, image := range msg.Images {
+			content += "\n[image: " + filepath.Base(image) + "]"
+		}
+		wrapped := cp.wrapText(messageHeader(msg, config)+content, cp.width-4)
+
+		// Fold long messages, except the one still streaming in
+		long := len(wrapped) > foldLines+1 && !(cp.isStreaming && i == cp.streamingIndex)
+		shown := wrapped
+		if long && msg.folded() {
+			shown = wrapped[:foldLines]
+		}
+		for _, text := range shown {
+			lines = append(lines, chatLine{text: text, style: style, msg: i})
+		}
+		if long {
+			marker := "▲ press o to fold"
+			if msg.folded() {
+				marker = fmt.Sprintf("… %d more lines, press o to expand", len(wrapped)-foldLines)
+			}
+			lines = append(lines, chatLine{text: marker, style: markerStyle, msg: i, fold: true})
+		}
+
+		// Add spacing between messages
+		if config.MessageLayout != core.MessageLayoutCompact {
+			lines = append(lines, chatLine{style: tcell.StyleDefault, msg: -1})
+		}
+	}
+	return lines
 }
 
 // calculateMaxScroll calculates the maximum scroll offset
 func (cp *ChatPanel) calculateMaxScroll() int {
 	// Calculate total lines needed for all messages
-	totalLines := 0
-	for _, msg := range cp.messages {
-		prefix := fmt.Sprintf("[%s] ", msg.Role)
-		lines := cp.wrapText(prefix+msg.Content, cp.width-4)
-		totalLines += len(lines) + 1 // +1 for spacing between messages
-	}
+	totalLines := len(cp.messageLines(cp.messages))
 
 	// Calculate visible area (leave room for borders and input)
 	visibleLines := cp.height - 5
@@ -369,32 +1415,95 @@ func (cp *ChatPanel) calculateMaxScroll() int {
 	return maxScroll
 }
 
-// streamResponse handles streaming response from the API
-func (cp *ChatPanel) streamResponse() {
-	// Log streaming start
-	if log := logger.Get(); log != nil {
-		log.Info("[ChatPanel] Starting stream response")
-	}
-
-	// Convert messages to API format
+// requestMessages converts history to the messages of a request, with the
+// system prompt, remembered facts and reply language, leaving out the oldest
+// unpinned messages when they don't fit the context window
+func (cp *ChatPanel) requestMessages(history []ChatMessage, systemPrompt, replyLanguage string) []services.ChatMessage {
+	config := cp.config.Get()
 	apiMessages := make([]services.ChatMessage, 0)
-	for _, msg := range cp.messages {
+	var entries []contextwindow.Entry
+	for _, msg := range history {
 		// Skip system messages for API
-		if msg.Role == "system" && strings.Contains(msg.Content, "Welcome to hacka.re") {
+		if msg.LocalOnly || (msg.Role == "system" && strings.Contains(msg.Content, "Welcome to hacka.re")) {
 			continue
 		}
 		apiMessages = append(apiMessages, services.ChatMessage{
 			Role:    msg.Role,
 			Content: msg.Content,
+			Images:  imageDataURLs(msg.Images),
+		})
+		// Notices in the chat are system messages too, but may be left out
+		role := msg.Role
+		if role == "system" {
+			role = "notice"
+		}
+		entries = append(entries, contextwindow.Entry{
+			Role:   role,
+			Tokens: contextwindow.MessageTokens(msg.Content),
+			Pinned: msg.Pinned,
 		})
 	}
 
-	// Add system prompt from config if configured
-	config := cp.config.Get()
-	if config.SystemPrompt != "" {
+	// Add the system prompt of this conversation if there is one, with the remembered facts
+	if !config.DisableMemory {
+		if facts, err := memory.NewStore(memory.DefaultPath()).Facts(config.Namespace); err == nil {
+			systemPrompt = memory.WithBlock(systemPrompt, facts)
+		} else if log := logger.Get(); log != nil {
+			log.Error("Failed to load memory: %v", err)
+		}
+	}
+	systemPrompt = language.WithInstruction(systemPrompt, replyLanguage)
+	if systemPrompt != "" {
 		apiMessages = append([]services.ChatMessage{
-			{Role: "system", Content: config.SystemPrompt},
+			{Role: "system", Content: systemPrompt},
 		}, apiMessages...)
+		entries = append([]contextwindow.Entry{
+			{Role: "system", Tokens: contextwindow.MessageTokens(systemPrompt)},
+		}, entries...)
+	}
+
+	// Leave out the oldest unpinned messages when the chat outgrows the context window
+	keep := contextwindow.Fit(entries, contextwindow.Budget(config.Provider, config.Model, config.MaxTokens))
+	fitted := apiMessages[:0]
+	for i, msg := range apiMessages {
+		if keep[i] {
+			fitted = append(fitted, msg)
+		}
+	}
+	if dropped := len(apiMessages) - len(fitted); dropped > 0 {
+		if log := logger.Get(); log != nil {
+			log.Info("[ChatPanel] Left out %d older messages to fit the context window", dropped)
+		}
+	}
+	return fitted
+}
+
+// streamResponse handles streaming response from the API
+func (cp *ChatPanel) streamResponse(ctx context.Context, gen int) {
+	defer core.HandlePanic()
+
+	// Log streaming start
+	if log := logger.Get(); log != nil {
+		log.Info("[ChatPanel] Starting stream response")
+	}
+	startTime := time.Now()
+
+	// Convert messages to API format
+	cp.streamingMutex.Lock()
+	history := make([]ChatMessage, len(cp.messages))
+	copy(history, cp.messages)
+	systemPrompt := cp.systemPrompt()
+	session := cp.languageSession()
+	replyLanguage := session.Reply(lastUserMessage(history))
+	cp.language.Detected = session.Detected
+	cp.streamingMutex.Unlock()
+
+	config := cp.config.Get()
+	apiMessages := cp.requestMessages(history, systemPrompt, replyLanguage)
+
+	promptTokens := 0
+	for _, msg := range apiMessages {
+		promptTokens += usage.EstimateTokens(msg.Content)
 	}
 
 	// Create streaming message
@@ -403,16 +1512,23 @@ func (cp *ChatPanel) streamResponse() {
 		Role:      "assistant",
 		Content:   "",
 		Timestamp: time.Now(),
+		Model:     config.Model,
 	}
 	cp.messages = append(cp.messages, *cp.streamingMsg)
 	streamingIndex := len(cp.messages) - 1
+	cp.streamingIndex = streamingIndex
 	cp.streamingMutex.Unlock()
 
 	// Stream the response
-	err := cp.chatClient.StreamCompletion(apiMessages, func(chunk string, done bool) error {
+	err := cp.chatClient.StreamCompletionWithContext(ctx, apiMessages, func(chunk string, done bool) error {
 		cp.streamingMutex.Lock()
 		defer cp.streamingMutex.Unlock()
 
+		// Ignore chunks from a stream that has been cancelled or replaced
+		if gen != cp.streamGen {
+			return context.Canceled
+		}
+
 		if done {
 			// Log streaming completion
 			if log := logger.Get(); log != nil {
@@ -430,6 +1546,8 @@ func (cp *ChatPanel) streamResponse() {
 			// Save to state
 			if streamingIndex < len(cp.messages) && cp.messages[streamingIndex].Content != "" {
 				cp.state.AddMessage("assistant", cp.messages[streamingIndex].Content)
+				cp.usage.Record(config.Model, promptTokens, usage.EstimateTokens(cp.messages[streamingIndex].Content))
+				auditCompletion(config, startTime, promptTokens, cp.messages[streamingIndex].Content, nil)
 			} else if streamingIndex < len(cp.messages) {
 				// Remove empty message if no content was received
 				if log := logger.Get(); log != nil {
@@ -455,28 +1573,32 @@ func (cp *ChatPanel) streamResponse() {
 				}
 			}
 
-			// Auto-scroll to bottom
-			cp.scrollToBottom()
+			// Follow the reply unless the user scrolled up to read
+			cp.followOutput()
 		}
 
-		// Trigger redraw
+		// Trigger redraw, throttled while chunks are arriving
 		cp.needsRedraw = true
-		// Send a resize event to trigger screen update
-		if cp.screen != nil {
-			cp.screen.PostEvent(tcell.NewEventResize(0, 0))
-		}
+		cp.requestRedraw(done)
 
 		return nil
 	})
 
 	if err != nil {
+		cp.streamingMutex.Lock()
+		// A replaced stream has already been cleaned up by cancelCurrentStream
+		if gen != cp.streamGen || ctx.Err() != nil {
+			cp.streamingMutex.Unlock()
+			return
+		}
+
 		// Log the error
 		if log := logger.Get(); log != nil {
 			log.Error("[ChatPanel] Streaming error: %v", err)
 		}
+		auditCompletion(config, startTime, promptTokens, "", err)
 
 		// Add error message
-		cp.streamingMutex.Lock()
 		errorMsg := ChatMessage{
 			Role:      "system",
 			Content:   fmt.Sprintf("Error: %v", err),
@@ -485,26 +1607,104 @@ func (cp *ChatPanel) streamResponse() {
 		cp.messages = append(cp.messages, errorMsg)
 		cp.isStreaming = false
 		cp.streamingMsg = nil
-		cp.scrollToBottom()
+		cp.finishStream(true)
+		cp.followOutput()
 		cp.needsRedraw = true
+		cp.requestRedraw(true)
 		cp.streamingMutex.Unlock()
+		return
+	}
+
+	cp.afterReceive(gen, streamingIndex, config.Model)
+	cp.notifyIfSlow(startTime)
+
+	// Send any message queued while this response was streaming
+	cp.streamingMutex.Lock()
+	if gen == cp.streamGen {
+		cp.finishStream(false)
+	}
+	cp.streamingMutex.Unlock()
+}
+
+// requestRedraw wakes the event loop to repaint; unless forced, requests closer together
+// than streamRedrawInterval are folded into one trailing redraw at the end of the
+// interval, so the last chunk of a stream is never left unpainted (must be called
+// with streamingMutex held)
+func (cp *ChatPanel) requestRedraw(force bool) {
+	if cp.screen == nil {
+		return
+	}
+	wait := streamRedrawInterval - time.Since(cp.lastRedrawRequest)
+	if !force && wait > 0 {
+		if !cp.redrawScheduled {
+			cp.redrawScheduled = true
+			time.AfterFunc(wait, func() {
+				cp.streamingMutex.Lock()
+				defer cp.streamingMutex.Unlock()
+				cp.redrawScheduled = false
+				cp.lastRedrawRequest = time.Now()
+				cp.screen.PostEvent(tcell.NewEventInterrupt(nil))
+			})
+		}
+		return
+	}
+	cp.lastRedrawRequest = time.Now()
+	cp.screen.PostEvent(tcell.NewEventInterrupt(nil))
+}
+
+// notifyIfSlow sends a desktop notification when a slow response finishes while the terminal is unfocused
+func (cp *ChatPanel) notifyIfSlow(startTime time.Time) {
+	config := cp.config.Get()
+	if !config.NotifyOnComplete || cp.state.IsTerminalFocused() {
+		return
+	}
+
+	elapsed := time.Since(startTime)
+	if elapsed < config.NotifyThreshold() {
+		return
+	}
 
-		// Trigger redraw
-		if cp.screen != nil {
-			cp.screen.PostEvent(tcell.NewEventResize(0, 0))
+	message := fmt.Sprintf("Your answer is ready (took %s)", elapsed.Round(time.Second))
+	if err := utils.SendDesktopNotification("hacka.re", message); err != nil {
+		if log := logger.Get(); log != nil {
+			log.Warn("[ChatPanel] Failed to send desktop notification: %v", err)
 		}
 	}
 }
 
+// auditCompletion writes a finished or failed reply to the audit log; token
+// counts are estimates since the stream doesn't report usage
+func auditCompletion(config *core.Config, startTime time.Time, promptTokens int, reply string, err error) {
+	if !auditlog.Enabled() {
+		return
+	}
+	event := auditlog.Event{
+		Type:             auditlog.EventMessageCompleted,
+		Namespace:        config.Namespace,
+		Provider:         config.Provider,
+		Model:            config.Model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: usage.EstimateTokens(reply),
+		Status:           "ok",
+		DurationMs:       time.Since(startTime).Milliseconds(),
+		Content:          reply,
+	}
+	if err != nil {
+		event.Status = "error"
+		event.Error = err.Error()
+	}
+	auditlog.Emit(event)
+}
+
 // Draw renders the chat panel
 func (cp *ChatPanel) Draw() {
 	// Draw border
 	cp.drawBorder()
 
 	// Draw title with scroll instructions
-	title := "Chat Interface - ESC to return"
+	title := "Chat Interface - ESC to return - F1 for help"
 	if cp.isStreaming {
-		title = "Chat Interface - Streaming... - ESC to return"
+		title = "Chat Interface - Streaming... - ESC to return - F1 for help"
 	}
 	titleX := cp.x + (cp.width-len(title))/2
 	style := tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true)
@@ -529,11 +1729,71 @@ func (cp *ChatPanel) Draw() {
 		cp.screen.SetContent(infoX+i, cp.y, r, nil, infoStyle)
 	}
 
+	// Show that /system replaced the saved system prompt
+	labelX := infoX + len([]rune(apiInfo))
+	if cp.systemOverride != "" {
+		overrideStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+		label := " [system prompt overridden]"
+		for i, r := range label {
+			cp.screen.SetContent(labelX+i, cp.y, r, nil, overrideStyle)
+		}
+		labelX += len([]rune(label))
+	}
+
+	// Show the language replies are asked for
+	if session := cp.languageSession(); session.Setting != language.Off {
+		code := session.Setting
+		if code == language.Auto {
+			code = session.Detected
+		}
+		if code != "" {
+			for i, r := range " [" + code + "]" {
+				cp.screen.SetContent(labelX+i, cp.y, r, nil, infoStyle)
+			}
+		}
+	}
+
 	// Draw messages area
 	cp.drawMessages()
 
 	// Draw input area
 	cp.drawInputArea()
+
+	if cp.systemEditor != nil {
+		cp.drawEditor(cp.systemEditor, " System prompt for this conversation - Ctrl+S apply, ESC cancel ", tcell.ColorYellow)
+	}
+	if cp.snippetsEditor != nil {
+		title, color := " "+cp.snippetsPath+" - Ctrl+S save, ESC cancel ", tcell.ColorYellow
+		if cp.snippetsError != "" {
+			title, color = " "+cp.snippetsError+" ", tcell.ColorRed
+		}
+		cp.drawEditor(cp.snippetsEditor, title, color)
+	}
+	if cp.variants != nil {
+		cp.drawVariants()
+	}
+	if cp.inspecting {
+		cp.drawInspect()
+	}
+}
+
+// drawEditor draws an editor opened by a command over the messages
+func (cp *ChatPanel) drawEditor(editor *Editor, title string, color tcell.Color) {
+	cp.screen.HideCursor()
+	ex, ey := editor.x, editor.y
+	for y := ey; y < ey+editor.height; y++ {
+		for x := ex; x < ex+editor.width; x++ {
+			cp.screen.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+		}
+	}
+	editor.Draw()
+
+	titleStyle := tcell.StyleDefault.Foreground(color).Bold(true)
+	for i, r := range []rune(title) {
+		if i < editor.width-4 {
+			cp.screen.SetContent(ex+2+i, ey, r, nil, titleStyle)
+		}
+	}
 }
 
 // drawBorder draws the panel border
@@ -557,6 +1817,45 @@ func (cp *ChatPanel) drawBorder() {
 	cp.screen.SetContent(cp.x+cp.width-1, cp.y, '╗', nil, style)
 	cp.screen.SetContent(cp.x, cp.y+cp.height-1, '╚', nil, style)
 	cp.screen.SetContent(cp.x+cp.width-1, cp.y+cp.height-1, '╝', nil, style)
+
+	// Show remaining budget on the bottom border
+	if remaining := cp.usage.Remaining(cp.budget()); remaining != "" {
+		status := fmt.Sprintf(" Budget: %s ", remaining)
+		statusX := cp.x + cp.width - len(status) - 2
+		statusStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+		for i, r := range status {
+			cp.screen.SetContent(statusX+i, cp.y+cp.height-1, r, nil, statusStyle)
+		}
+	}
+
+	// Show offline mode, and whether it had to block anything, on the left of the bottom border
+	if status, blocked := cp.offlineStatus(); status != "" {
+		statusStyle := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+		if blocked {
+			statusStyle = tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+		}
+		for i, r := range []rune(status) {
+			cp.screen.SetContent(cp.x+2+i, cp.y+cp.height-1, r, nil, statusStyle)
+		}
+	}
+}
+
+// offlineStatus describes offline mode for the status bar and reports whether
+// the network guard has blocked a remote connection
+func (cp *ChatPanel) offlineStatus() (string, bool) {
+	cfg := cp.config.Get()
+	if !cfg.IsOfflineMode {
+		return "", false
+	}
+	status := " OFFLINE"
+	if cfg.AllowRemoteMCP || cfg.AllowRemoteEmbeddings {
+		status += " (remote exceptions)"
+	}
+	count, last := netguard.Violations()
+	if count == 0 {
+		return status + " ", false
+	}
+	return fmt.Sprintf("%s · %d blocked, last %s ", status, count, last.Target), true
 }
 
 // drawMessages draws the chat messages
@@ -571,42 +1870,7 @@ func (cp *ChatPanel) drawMessages() {
 	cp.streamingMutex.Unlock()
 
 	// Build all message lines first to handle scrolling properly
-	var allLines []struct {
-		text  string
-		style tcell.Style
-	}
-
-	for _, msg := range messagesCopy {
-		// Choose color based on role
-		var style tcell.Style
-		switch msg.Role {
-		case "user":
-			style = tcell.StyleDefault.Foreground(tcell.ColorBlue)
-		case "assistant":
-			style = tcell.StyleDefault.Foreground(tcell.ColorGreen)
-		case "system":
-			style = tcell.StyleDefault.Foreground(tcell.ColorYellow)
-		default:
-			style = tcell.StyleDefault
-		}
-
-		// Format and wrap message
-		prefix := fmt.Sprintf("[%s] ", msg.Role)
-		lines := cp.wrapText(prefix+msg.Content, cp.width-4)
-
-		for _, line := range lines {
-			allLines = append(allLines, struct {
-				text  string
-				style tcell.Style
-			}{line, style})
-		}
-
-		// Add spacing between messages
-		allLines = append(allLines, struct {
-			text  string
-			style tcell.Style
-		}{"", tcell.StyleDefault})
-	}
+	allLines := cp.messageLines(messagesCopy)
 
 	// Calculate visible range
 	visibleStart := cp.scrollOffset
@@ -630,7 +1894,7 @@ func (cp *ChatPanel) drawMessages() {
 		}
 
 		// Draw the text
-		for j, r := range line.text {
+		for j, r := range []rune(line.text) {
 			if cp.x+2+j < cp.x+cp.width-2 {
 				cp.screen.SetContent(cp.x+2+j, currentY, r, nil, line.style)
 			}
@@ -681,6 +1945,24 @@ func (cp *ChatPanel) drawMessages() {
 				tcell.StyleDefault.Foreground(tcell.ColorWhite))
 		}
 	}
+
+	// Say that a reply went on below the view
+	if cp.newMessages {
+		badgeX, badgeY, badge := cp.newMessagesBadge()
+		badgeStyle := tcell.StyleDefault.Background(tcell.ColorYellow).Foreground(tcell.ColorBlack)
+		for i, r := range []rune(badge) {
+			cp.screen.SetContent(badgeX+i, badgeY, r, nil, badgeStyle)
+		}
+	}
+}
+
+// newMessagesBadge returns the position and text of the badge shown while
+// new content is below the view; clicking it scrolls down
+func (cp *ChatPanel) newMessagesBadge() (int, int, string) {
+	badge := " ▼ new messages "
+	x := cp.x + cp.width - 3 - len([]rune(badge))
+	y := cp.y + 2 + cp.height - 5 - 3 // Last line of the message area
+	return x, y, badge
 }
 
 // drawInputArea draws the input area at the bottom
@@ -692,6 +1974,26 @@ func (cp *ChatPanel) drawInputArea() {
 		cp.screen.SetContent(x, inputY-1, '─', nil, tcell.StyleDefault)
 	}
 
+	// Show the input lock state on the separator while a response is streaming
+	if status := cp.inputLockStatus(); status != "" {
+		status = " " + status + " "
+		statusX := cp.x + cp.width - 2 - len([]rune(status))
+		statusStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+		for i, r := range []rune(status) {
+			if statusX+i > cp.x {
+				cp.screen.SetContent(statusX+i, inputY-1, r, nil, statusStyle)
+			}
+		}
+	}
+
+	// Show the images waiting for the next message on the left of the separator
+	if status := cp.attachmentStatus(); status != "" {
+		statusStyle := tcell.StyleDefault.Foreground(tcell.ColorTeal)
+		for i, r := range []rune(" " + status + " ") {
+			cp.screen.SetContent(cp.x+2+i, inputY-1, r, nil, statusStyle)
+		}
+	}
+
 	// Draw prompt
 	prompt := "> "
 	promptStyle := tcell.StyleDefault.Foreground(tcell.ColorBlue)
@@ -730,6 +2032,9 @@ func (cp *ChatPanel) drawInputArea() {
 	// Draw the input text
 	inputStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
 	for i, r := range visibleInput {
+		if r == '\n' {
+			r = ' ' // Line breaks, e.g. from a snippet, show as spaces on the one input line
+		}
 		if inputStartX+i < cp.x+cp.width-2 {
 			cp.screen.SetContent(inputStartX+i, inputY, r, nil, inputStyle)
 		}
@@ -749,6 +2054,25 @@ func (cp *ChatPanel) drawInputArea() {
 	}
 }
 
+// inputLockStatus describes what Enter will do while a response is streaming
+func (cp *ChatPanel) inputLockStatus() string {
+	if !cp.isStreaming {
+		return ""
+	}
+
+	switch cp.config.Get().InputLockMode {
+	case core.InputLockQueue:
+		if cp.queuedMessage != "" {
+			return "generating… 1 message queued"
+		}
+		return "generating… Enter queues message"
+	case core.InputLockReplace:
+		return "generating… Enter cancels and replaces"
+	default:
+		return "generating… input locked"
+	}
+}
+
 // wrapText wraps text to fit within the given width
 func (cp *ChatPanel) wrapText(text string, width int) []string {
 	if width <= 0 {
@@ -793,6 +2117,11 @@ func (cp *ChatPanel) wrapText(text string, width int) []string {
 	return lines
 }
 
+// GetInput returns the text currently in the input area
+func (cp *ChatPanel) GetInput() string {
+	return cp.inputBuffer
+}
+
 // SetDimensions sets the panel dimensions
 func (cp *ChatPanel) SetDimensions(width, height int) {
 	cp.width = width
@@ -0,0 +1,349 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+	"github.com/hacka-re/cli/internal/tui/internal/services"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// defaultVariants is how many alternatives v asks for
+const defaultVariants = 3
+
+// maxVariants keeps /variants from sending a burst of requests
+const maxVariants = 5
+
+// minVariantColumn is the narrowest column a reply is shown in
+const minVariantColumn = 24
+
+// chatVariantsKeymap lists the keys while alternatives to a reply are shown
+var chatVariantsKeymap = core.RegisterKeymap("chat.variants", "Chat: alternative replies",
+	core.Bind("←→ 1-9", "Pick a reply"),
+	core.Bind("↑↓ PgUp/PgDn", "Scroll the replies"),
+	core.Bind("Enter", "Continue the conversation with the picked reply"),
+	core.Bind("ESC", "Keep the current reply"),
+)
+
+// variantSet is a reply and the alternatives written for it, shown side by side
+type variantSet struct {
+	index    int // The reply in cp.messages
+	replies  []variantReply
+	selected int
+	scroll   int
+	cancel   context.CancelFunc
+}
+
+// variantReply is one reply of a variantSet; the first is the current one
+type variantReply struct {
+	label   string
+	content string
+	done    bool
+	err     error
+}
+
+// handleVariantsCommand asks for alternatives to the last reply
+func (cp *ChatPanel) handleVariantsCommand(arg string) {
+	n := defaultVariants
+	if arg != "" {
+		var err error
+		if n, err = strconv.Atoi(arg); err != nil || n < 1 || n > maxVariants {
+			cp.addSystemMessage(fmt.Sprintf("Usage: /variants [1-%d]", maxVariants))
+			return
+		}
+	}
+	for i := len(cp.messages) - 1; i >= 0; i-- {
+		if cp.messages[i].Role == "assistant" {
+			cp.openVariants(i, n)
+			return
+		}
+	}
+	cp.addSystemMessage("There is no reply to vary yet.")
+}
+
+// openVariantsInView asks for alternatives to the last reply that is at least
+// partly visible, or the last reply if none is. It reports whether there was one.
+func (cp *ChatPanel) openVariantsInView() bool {
+	cp.streamingMutex.Lock()
+	lines := cp.messageLines(cp.messages)
+	replies := make([]bool, len(cp.messages))
+	for i, msg := range cp.messages {
+		replies[i] = msg.Role == "assistant"
+	}
+	cp.streamingMutex.Unlock()
+
+	visibleEnd := cp.scrollOffset + cp.height - 7
+	target, inView := -1, false
+	for i, line := range lines {
+		if !replies[line.msg] || line.msg == target {
+			continue
+		}
+		visible := i >= cp.scrollOffset && i < visibleEnd
+		if visible || !inView {
+			target, inView = line.msg, visible
+		}
+	}
+	if target == -1 {
+		return false
+	}
+	cp.openVariants(target, defaultVariants)
+	return true
+}
+
+// openVariants shows reply index next to n alternatives, written with other
+// temperatures and seeds from the same conversation
+func (cp *ChatPanel) openVariants(index, n int) {
+	cp.streamingMutex.Lock()
+	if cp.isStreaming {
+		cp.streamingMutex.Unlock()
+		cp.addSystemMessage("Wait for the reply to finish before asking for alternatives.")
+		return
+	}
+	history := make([]ChatMessage, index)
+	copy(history, cp.messages[:index])
+	current := cp.messages[index].Content
+	systemPrompt := cp.systemPrompt()
+	session := cp.languageSession()
+	cp.streamingMutex.Unlock()
+
+	apiMessages := cp.requestMessages(history, systemPrompt, session.Reply(lastUserMessage(history)))
+	promptTokens := 0
+	for _, msg := range apiMessages {
+		promptTokens += usage.EstimateTokens(msg.Content)
+	}
+
+	config := cp.config.Get()
+	ctx, cancel := context.WithCancel(context.Background())
+	set := &variantSet{
+		index:   index,
+		replies: []variantReply{{label: "current", content: current, done: true}},
+		cancel:  cancel,
+	}
+
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	cp.variants = set
+	temperature := cp.chatClient.Sampling().TemperatureOr(config.Temperature)
+	for i, sampling := range services.Variants(n, temperature, config.Seed) {
+		set.replies = append(set.replies, variantReply{label: sampling.String()})
+		go cp.streamVariant(services.WithSampling(ctx, sampling), set, i+1, apiMessages, promptTokens)
+	}
+}
+
+// streamVariant writes alternative i of set
+func (cp *ChatPanel) streamVariant(ctx context.Context, set *variantSet, i int, messages []services.ChatMessage, promptTokens int) {
+	defer core.HandlePanic()
+	config := cp.config.Get()
+	startTime := time.Now()
+
+	err := cp.chatClient.StreamCompletionWithContext(ctx, messages, func(chunk string, done bool) error {
+		cp.streamingMutex.Lock()
+		defer cp.streamingMutex.Unlock()
+		if cp.variants != set {
+			return context.Canceled
+		}
+		set.replies[i].content += chunk
+		cp.requestRedraw(done)
+		return nil
+	})
+
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	reply := &set.replies[i]
+	reply.done = true
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		reply.err = err
+		if log := logger.Get(); log != nil {
+			log.Error("[ChatPanel] Alternative reply failed: %v", err)
+		}
+	} else {
+		cp.usage.Record(config.Model, promptTokens, usage.EstimateTokens(reply.content))
+	}
+	auditCompletion(config, startTime, promptTokens, reply.content, err)
+	if cp.variants == set {
+		cp.requestRedraw(true)
+	}
+}
+
+// closeVariants stops the alternatives still being written and hides them
+func (cp *ChatPanel) closeVariants() {
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	if cp.variants != nil {
+		cp.variants.cancel()
+		cp.variants = nil
+	}
+}
+
+// chooseVariant continues the conversation with the selected reply. Messages
+// after the reply belonged to the old one, so they are removed.
+func (cp *ChatPanel) chooseVariant() {
+	cp.streamingMutex.Lock()
+	set := cp.variants
+	reply := set.replies[set.selected]
+	cp.streamingMutex.Unlock()
+
+	switch {
+	case set.selected == 0:
+		cp.closeVariants()
+		return
+	case !reply.done:
+		return // Still being written
+	case reply.err != nil || reply.content == "":
+		cp.closeVariants()
+		cp.addSystemMessage("That alternative failed; the current reply is kept.")
+		return
+	}
+	cp.closeVariants()
+
+	cp.streamingMutex.Lock()
+	old := cp.messages[set.index].Content
+	later := len(cp.messages) - set.index - 1
+	cp.messages[set.index].Content = reply.content
+	cp.messages[set.index].Annotation = nil // The review was of the old reply
+	cp.messages = cp.messages[:set.index+1]
+	cp.streamingMutex.Unlock()
+	cp.state.ReviseMessage("assistant", old, reply.content)
+
+	notice := fmt.Sprintf("Continuing with the alternative reply (%s).", reply.label)
+	if later > 0 {
+		notice += fmt.Sprintf(" The %d later messages were removed.", later)
+	}
+	cp.addSystemMessage(notice)
+}
+
+// handleVariantsInput handles the keys while alternatives are shown
+func (cp *ChatPanel) handleVariantsInput(ev *tcell.EventKey) {
+	set := cp.variants
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		cp.closeVariants()
+	case tcell.KeyEnter:
+		cp.chooseVariant()
+	case tcell.KeyLeft, tcell.KeyBacktab:
+		if set.selected > 0 {
+			set.selected--
+		}
+	case tcell.KeyRight, tcell.KeyTab:
+		if set.selected < len(set.replies)-1 {
+			set.selected++
+		}
+	case tcell.KeyUp:
+		set.scroll = max(0, set.scroll-1)
+	case tcell.KeyDown:
+		set.scroll++
+	case tcell.KeyPgUp:
+		set.scroll = max(0, set.scroll-cp.height/2)
+	case tcell.KeyPgDn:
+		set.scroll += cp.height / 2
+	case tcell.KeyRune:
+		if n := int(ev.Rune() - '1'); n >= 0 && n < len(set.replies) {
+			set.selected = n
+		}
+	}
+}
+
+// drawVariants draws the replies side by side over the messages; when they
+// don't all fit, the columns around the selected one are shown
+func (cp *ChatPanel) drawVariants() {
+	cp.screen.HideCursor()
+	cp.streamingMutex.Lock()
+	defer cp.streamingMutex.Unlock()
+	set := cp.variants
+
+	left, top := cp.x+1, cp.y+1
+	width, height := cp.width-2, cp.height-5
+	if width < 10 || height < 4 {
+		return
+	}
+	for y := top; y < top+height; y++ {
+		for x := left; x < left+width; x++ {
+			cp.screen.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+		}
+	}
+
+	title := " Alternative replies - ←→ pick, Enter continue with it, ESC keep the current one "
+	if later := len(cp.messages) - set.index - 1; later > 0 {
+		title = fmt.Sprintf(" Alternative replies - ←→ pick, Enter continue with it (removes the %d later messages), ESC cancel ", later)
+	}
+	titleStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+	for i, r := range []rune(title) {
+		if i < width-2 {
+			cp.screen.SetContent(left+1+i, top, r, nil, titleStyle)
+		}
+	}
+
+	columns := min(len(set.replies), max(1, (width+1)/(minVariantColumn+1)))
+	first := min(max(0, set.selected-columns/2), len(set.replies)-columns)
+	columnWidth := (width - (columns - 1)) / columns
+
+	// Keep the scroll within the longest reply shown
+	longest := 0
+	wrapped := make([][]string, columns)
+	for c := 0; c < columns; c++ {
+		wrapped[c] = cp.wrapText(set.replies[first+c].content, columnWidth-2)
+		longest = max(longest, len(wrapped[c]))
+	}
+	bodyHeight := height - 3
+	set.scroll = min(set.scroll, max(0, longest-bodyHeight))
+
+	for c := 0; c < columns; c++ {
+		i := first + c
+		reply := set.replies[i]
+		x := left + c*(columnWidth+1)
+		if c > 0 {
+			for y := top + 2; y < top+height; y++ {
+				cp.screen.SetContent(x-1, y, '│', nil, tcell.StyleDefault.Foreground(tcell.ColorDarkGray))
+			}
+		}
+
+		header := fmt.Sprintf("%d %s", i+1, reply.label)
+		switch {
+		case reply.err != nil:
+			header += " - failed"
+		case !reply.done:
+			header += " …"
+		}
+		headerStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+		if i == set.selected {
+			headerStyle = tcell.StyleDefault.Background(tcell.ColorBlue).Foreground(tcell.ColorWhite).Bold(true)
+		}
+		for j, r := range []rune(header) {
+			if j < columnWidth-1 {
+				cp.screen.SetContent(x+1+j, top+1, r, nil, headerStyle)
+			}
+		}
+
+		lines := wrapped[c]
+		if reply.err != nil {
+			lines = cp.wrapText(reply.err.Error(), columnWidth-2)
+		}
+		textStyle := tcell.StyleDefault
+		if i != set.selected {
+			textStyle = textStyle.Foreground(tcell.ColorGray)
+		}
+		for row := 0; row < bodyHeight && set.scroll+row < len(lines); row++ {
+			for j, r := range []rune(lines[set.scroll+row]) {
+				if j < columnWidth-2 {
+					cp.screen.SetContent(x+1+j, top+3+row, r, nil, textStyle)
+				}
+			}
+		}
+	}
+
+	// Say when more replies are off to the side
+	if first > 0 {
+		cp.screen.SetContent(left, top+1, '◀', nil, titleStyle)
+	}
+	if first+columns < len(set.replies) {
+		cp.screen.SetContent(left+width-1, top+1, '▶', nil, titleStyle)
+	}
+}
@@ -0,0 +1,214 @@
+package components
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// TileStatus colors a dashboard tile
+type TileStatus int
+
+const (
+	TileNeutral TileStatus = iota
+	TileOK
+	TileWarning
+	TileError
+)
+
+// DashboardTile is one status box on the main menu dashboard
+type DashboardTile struct {
+	Title  string
+	Lines  []string
+	Status TileStatus
+	Action func() error // Runs when the tile is clicked or chosen with Enter
+}
+
+// DashboardKeymap lists the keys of the dashboard while it has focus
+var DashboardKeymap = core.RegisterKeymap("dashboard", "Dashboard",
+	core.Bind("←→↑↓", "Select a tile"),
+	core.Bind("Enter", "Open"),
+	core.Bind("Tab/ESC", "Back to the menu"),
+)
+
+// dashboardColumns is the number of tile columns
+const dashboardColumns = 2
+
+// Dashboard draws status tiles in a grid; tiles can be clicked, or selected
+// with the arrow keys once the dashboard has focus
+type Dashboard struct {
+	screen   tcell.Screen
+	tiles    []DashboardTile
+	x, y     int
+	width    int
+	height   int
+	focused  bool
+	selected int
+}
+
+// NewDashboard creates an empty dashboard
+func NewDashboard(screen tcell.Screen) *Dashboard {
+	return &Dashboard{screen: screen}
+}
+
+// SetTiles replaces the tiles, keeping the selection when possible
+func (d *Dashboard) SetTiles(tiles []DashboardTile) {
+	d.tiles = tiles
+	if d.selected >= len(tiles) {
+		d.selected = 0
+	}
+}
+
+// SetBounds sets the area the tiles are laid out in
+func (d *Dashboard) SetBounds(x, y, width, height int) {
+	d.x, d.y, d.width, d.height = x, y, width, height
+}
+
+// SetFocused gives or takes keyboard focus
+func (d *Dashboard) SetFocused(focused bool) {
+	d.focused = focused
+}
+
+// IsFocused reports whether the dashboard has keyboard focus
+func (d *Dashboard) IsFocused() bool {
+	return d.focused
+}
+
+// tileRect returns the position and size of tile i
+func (d *Dashboard) tileRect(i int) (x, y, w, h int) {
+	rows := (len(d.tiles) + dashboardColumns - 1) / dashboardColumns
+	if rows == 0 {
+		return 0, 0, 0, 0
+	}
+	w = (d.width - (dashboardColumns - 1)) / dashboardColumns
+	h = (d.height - (rows - 1)) / rows
+	if h > 7 {
+		h = 7
+	}
+	return d.x + (i%dashboardColumns)*(w+1), d.y + (i/dashboardColumns)*(h+1), w, h
+}
+
+// Draw renders the tiles
+func (d *Dashboard) Draw() {
+	for i, tile := range d.tiles {
+		x, y, w, h := d.tileRect(i)
+		if w < 12 || h < 3 {
+			return
+		}
+		d.drawTile(tile, x, y, w, h, d.focused && i == d.selected)
+	}
+}
+
+// drawTile draws one tile with a colored border
+func (d *Dashboard) drawTile(tile DashboardTile, x, y, w, h int, selected bool) {
+	border := tcell.StyleDefault.Foreground(tcell.ColorGray)
+	switch tile.Status {
+	case TileOK:
+		border = border.Foreground(tcell.ColorGreen)
+	case TileWarning:
+		border = border.Foreground(tcell.ColorYellow)
+	case TileError:
+		border = border.Foreground(tcell.ColorRed)
+	}
+	if selected {
+		border = border.Bold(true).Reverse(true)
+	}
+
+	for col := 1; col < w-1; col++ {
+		d.screen.SetContent(x+col, y, '─', nil, border)
+		d.screen.SetContent(x+col, y+h-1, '─', nil, border)
+	}
+	for row := 1; row < h-1; row++ {
+		d.screen.SetContent(x, y+row, '│', nil, border)
+		d.screen.SetContent(x+w-1, y+row, '│', nil, border)
+	}
+	d.screen.SetContent(x, y, '╭', nil, border)
+	d.screen.SetContent(x+w-1, y, '╮', nil, border)
+	d.screen.SetContent(x, y+h-1, '╰', nil, border)
+	d.screen.SetContent(x+w-1, y+h-1, '╯', nil, border)
+	d.text(x+2, y, " "+tile.Title+" ", border, w-4)
+
+	style := tcell.StyleDefault
+	for i, line := range tile.Lines {
+		if i >= h-2 {
+			break
+		}
+		if i > 0 {
+			style = tcell.StyleDefault.Foreground(tcell.ColorGray)
+		}
+		d.text(x+2, y+1+i, line, style, w-4)
+	}
+}
+
+// text draws s clipped to width, ending in … when cut
+func (d *Dashboard) text(x, y int, s string, style tcell.Style, width int) {
+	runes := []rune(s)
+	for i, r := range runes {
+		if i >= width {
+			return
+		}
+		if i == width-1 && len(runes) > width {
+			r = '…'
+		}
+		d.screen.SetContent(x+i, y, r, nil, style)
+	}
+}
+
+// HandleInput moves the selection and opens tiles; it returns true when focus
+// should go back to the menu
+func (d *Dashboard) HandleInput(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyTab, tcell.KeyBacktab, tcell.KeyEscape:
+		return true
+	case tcell.KeyLeft:
+		if d.selected%dashboardColumns > 0 {
+			d.selected--
+		}
+	case tcell.KeyRight:
+		if d.selected%dashboardColumns < dashboardColumns-1 && d.selected < len(d.tiles)-1 {
+			d.selected++
+		}
+	case tcell.KeyUp:
+		if d.selected >= dashboardColumns {
+			d.selected -= dashboardColumns
+		}
+	case tcell.KeyDown:
+		if d.selected+dashboardColumns < len(d.tiles) {
+			d.selected += dashboardColumns
+		}
+	case tcell.KeyEnter:
+		return d.open(d.selected)
+	}
+	return false
+}
+
+// HandleMouse opens a tile on click; it returns true if the event hit a tile
+func (d *Dashboard) HandleMouse(event *core.MouseEvent) bool {
+	for i := range d.tiles {
+		x, y, w, h := d.tileRect(i)
+		if event.X < x || event.X >= x+w || event.Y < y || event.Y >= y+h {
+			continue
+		}
+		switch event.Type {
+		case core.MouseEventClick:
+			d.selected = i
+			d.open(i)
+			return true
+		case core.MouseEventHover:
+			if d.focused {
+				d.selected = i
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// open runs the action of tile i and returns focus to the menu
+func (d *Dashboard) open(i int) bool {
+	if i < 0 || i >= len(d.tiles) || d.tiles[i].Action == nil {
+		return false
+	}
+	d.focused = false
+	d.tiles[i].Action()
+	return true
+}
@@ -22,6 +22,15 @@ func (d *DropdownItem) GetInfo() string        { return d.description }
 func (d *DropdownItem) GetCategory() string    { return "" }
 func (d *DropdownItem) IsEnabled() bool        { return true }
 
+// DropdownKeymap lists the keys of a dropdown selector
+var DropdownKeymap = core.RegisterKeymap("dropdown", "Choosing an option",
+	core.Bind("↑↓", "Navigate"),
+	core.Bind("0-9", "Quick select"),
+	core.Bind("Type", "Filter"),
+	core.Bind("Enter", "Choose"),
+	core.Bind("ESC", "Cancel"),
+).WithTextEntry()
+
 // DropdownSelector provides a filterable dropdown menu
 type DropdownSelector struct {
 	screen       tcell.Screen
@@ -109,6 +118,8 @@ func (ds *DropdownSelector) getDescription(option string) string {
 		return "Local models via Ollama"
 	case "custom":
 		return "Custom API endpoint"
+	case "custom_protocol":
+		return "Non-OpenAI endpoint mapped by a JSON template"
 
 	// Model descriptions
 	case "gpt-4-turbo-preview":
@@ -189,18 +200,8 @@ func (ds *DropdownSelector) drawCurrentValue() {
 func (ds *DropdownSelector) drawInstructions() {
 	x, y, w, h := ds.menu.x, ds.menu.y, ds.menu.width, ds.menu.height
 
-	instructions := " Type to filter | 0-9:Quick select | Enter:Choose | ESC:Cancel "
-
-	// Truncate if too long
-	if len(instructions) > w+30 {
-		instructions = " Filter | 0-9 | Enter | ESC "
-	}
-
-	instX := x + (w-len(instructions)+30)/2
-	instY := y + h
-
 	style := tcell.StyleDefault.Foreground(tcell.ColorGray)
-	ds.drawText(instX, instY, instructions, style)
+	DrawHint(ds.screen, x, y+h, w+30, DropdownKeymap, style)
 }
 
 // HandleInput processes keyboard input
@@ -23,6 +23,25 @@ type ExpandableItem struct {
 	Style       tcell.Style
 	IsCheckbox  bool
 	IsChecked   bool
+	IsFavorite  bool   // Marked with a heart after the checkbox
+	Status      string // Shown right-aligned, such as a test result
+	StatusStyle tcell.Style
+}
+
+// Label returns the item text with its checkbox and favorite marker
+func (item ExpandableItem) Label() string {
+	text := item.Text
+	if item.IsFavorite {
+		text = "♥ " + text
+	}
+	if item.IsCheckbox {
+		checkbox := "[ ]"
+		if item.IsChecked {
+			checkbox = "[x]"
+		}
+		text = checkbox + " " + text
+	}
+	return text
 }
 
 // NewExpandableGroup creates a new expandable group
@@ -113,22 +132,21 @@ func (eg *ExpandableGroup) Draw() int {
 					x += 2 // Additional indentation
 				}
 
-				text := item.Text
-				if item.IsCheckbox {
-					checkbox := "[ ]"
-					if item.IsChecked {
-						checkbox = "[x]"
-					}
-					text = checkbox + " " + text
-				}
+				text := item.Label()
 
-				// Truncate text if too long
+				// Truncate text if too long, leaving room for the status
 				maxLen := eg.width - (x - eg.X)
+				if item.Status != "" {
+					maxLen -= len([]rune(item.Status)) + 2
+				}
 				if len(text) > maxLen && maxLen > 3 {
 					text = text[:maxLen-3] + "..."
 				}
 
 				DrawText(eg.screen, x, currentY, text, item.Style)
+				if item.Status != "" {
+					DrawText(eg.screen, eg.X+eg.width-len([]rune(item.Status)), currentY, item.Status, item.StatusStyle)
+				}
 			}
 			currentY++
 		}
@@ -181,7 +199,7 @@ func (eg *ExpandableGroup) HandleMouse(event *core.MouseEvent) bool {
 					}
 
 					// Check if click is on this checkbox item
-					checkboxWidth := 3 + 1 + len(item.Text) // checkbox + space + text
+					checkboxWidth := len([]rune(item.Label())) // checkbox, marker and text
 					itemHitTest := core.NewComponentHitTest(itemX, currentY, checkboxWidth, 1)
 
 					if itemHitTest.ContainsEvent(event) {
@@ -0,0 +1,196 @@
+package components
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// DrawHint draws the keymap's hint line centered in the span [x, x+width)
+func DrawHint(screen tcell.Screen, x, y, width int, keymap *core.Keymap, style tcell.Style) {
+	hint := []rune(keymap.Hint(width - 2))
+	start := x + (width-len(hint))/2
+	for i, r := range hint {
+		screen.SetContent(start+i, y, r, nil, style)
+	}
+}
+
+// HelpOverlay lists the bindings of the current page, followed by the global
+// ones, in a scrollable box
+type HelpOverlay struct {
+	screen  tcell.Screen
+	keymaps []*core.Keymap
+	offset  int
+	rows    int // Visible rows, set by Draw
+	lines   int // Total rows, set by Draw
+}
+
+// NewHelpOverlay creates an overlay for the given keymaps; nil keymaps are skipped
+func NewHelpOverlay(screen tcell.Screen, keymaps ...*core.Keymap) *HelpOverlay {
+	h := &HelpOverlay{screen: screen}
+	for _, keymap := range keymaps {
+		if keymap != nil {
+			h.keymaps = append(h.keymaps, keymap)
+		}
+	}
+	return h
+}
+
+// helpLine is one row of the overlay; a row without keys is a section title
+type helpLine struct {
+	keys, action string
+}
+
+// content flattens the keymaps into rows
+func (h *HelpOverlay) content() ([]helpLine, int) {
+	var lines []helpLine
+	keysWidth := 0
+	for i, keymap := range h.keymaps {
+		if i > 0 {
+			lines = append(lines, helpLine{})
+		}
+		lines = append(lines, helpLine{action: keymap.Title})
+		for _, b := range keymap.Bindings {
+			lines = append(lines, helpLine{keys: b.Keys, action: b.Action})
+			if n := len([]rune(b.Keys)); n > keysWidth {
+				keysWidth = n
+			}
+		}
+	}
+	return lines, keysWidth
+}
+
+// Draw renders the overlay centered on screen
+func (h *HelpOverlay) Draw() {
+	w, sh := h.screen.Size()
+	lines, keysWidth := h.content()
+
+	width := 64
+	if width > w-4 {
+		width = w - 4
+	}
+	height := len(lines) + 4
+	if height > sh-2 {
+		height = sh - 2
+	}
+	h.rows = height - 4
+	h.lines = len(lines)
+	if h.offset > h.lines-h.rows {
+		h.offset = h.lines - h.rows
+	}
+	if h.offset < 0 {
+		h.offset = 0
+	}
+	x, y := (w-width)/2, (sh-height)/2
+
+	border := tcell.StyleDefault.Foreground(tcell.ColorTeal).Background(tcell.ColorBlack)
+	fill := tcell.StyleDefault.Background(tcell.ColorBlack)
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			r := ' '
+			switch {
+			case row == 0 && col == 0:
+				r = '╭'
+			case row == 0 && col == width-1:
+				r = '╮'
+			case row == height-1 && col == 0:
+				r = '╰'
+			case row == height-1 && col == width-1:
+				r = '╯'
+			case row == 0 || row == height-1:
+				r = '─'
+			case col == 0 || col == width-1:
+				r = '│'
+			}
+			style := fill
+			if r != ' ' {
+				style = border
+			}
+			h.screen.SetContent(x+col, y+row, r, nil, style)
+		}
+	}
+	h.text(x+2, y, " Keyboard shortcuts ", border.Bold(true), width-4)
+
+	keyStyle := fill.Foreground(tcell.ColorYellow)
+	actionStyle := fill.Foreground(tcell.ColorWhite)
+	titleStyle := fill.Foreground(tcell.ColorGreen).Bold(true)
+	for i := 0; i < h.rows && h.offset+i < len(lines); i++ {
+		line := lines[h.offset+i]
+		rowY := y + 2 + i
+		if line.keys == "" {
+			h.text(x+2, rowY, line.action, titleStyle, width-4)
+			continue
+		}
+		h.text(x+4, rowY, line.keys, keyStyle, keysWidth)
+		h.text(x+6+keysWidth, rowY, line.action, actionStyle, width-8-keysWidth)
+	}
+
+	if h.offset > 0 {
+		h.screen.SetContent(x+width-2, y+1, '↑', nil, keyStyle)
+	}
+	if h.offset+h.rows < len(lines) {
+		h.screen.SetContent(x+width-2, y+height-2, '↓', nil, keyStyle)
+	}
+	h.text(x+2, y+height-1, " ↑↓:Scroll | ESC/?:Close ", border, width-4)
+}
+
+// text draws s clipped to width
+func (h *HelpOverlay) text(x, y int, s string, style tcell.Style, width int) {
+	i := 0
+	for _, r := range s {
+		if i >= width {
+			return
+		}
+		h.screen.SetContent(x+i, y, r, nil, style)
+		i++
+	}
+}
+
+// scroll moves the visible rows by delta
+func (h *HelpOverlay) scroll(delta int) {
+	h.offset += delta
+	if h.offset > h.lines-h.rows {
+		h.offset = h.lines - h.rows
+	}
+	if h.offset < 0 {
+		h.offset = 0
+	}
+}
+
+// HandleInput scrolls the overlay; it returns true when it should close
+func (h *HelpOverlay) HandleInput(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyUp:
+		h.scroll(-1)
+	case tcell.KeyDown:
+		h.scroll(1)
+	case tcell.KeyPgUp:
+		h.scroll(-h.rows)
+	case tcell.KeyPgDn:
+		h.scroll(h.rows)
+	case tcell.KeyRune:
+		if ev.Rune() == ' ' {
+			h.scroll(h.rows)
+			return false
+		}
+		return ev.Rune() == '?' || ev.Rune() == 'q'
+	default:
+		// ESC, Enter, F1 and anything else close the overlay
+		return true
+	}
+	return false
+}
+
+// HandleMouse scrolls on wheel events; it returns true when a click should
+// close the overlay
+func (h *HelpOverlay) HandleMouse(event *core.MouseEvent) bool {
+	if event.Type == core.MouseEventScroll {
+		switch event.Button {
+		case core.MouseWheelUp:
+			h.scroll(-1)
+		case core.MouseWheelDown:
+			h.scroll(1)
+		}
+		return false
+	}
+	return event.Type == core.MouseEventClick
+}
@@ -9,6 +9,15 @@ import (
 	"github.com/hacka-re/cli/internal/tui/internal/core"
 )
 
+// MenuKeymap lists the keys of a filterable menu
+var MenuKeymap = core.RegisterKeymap("menu", "Menu",
+	core.Bind("↑↓", "Navigate"),
+	core.Bind("Enter", "Select"),
+	core.Bind("0-9", "Select by number"),
+	core.Bind("Type", "Filter"),
+	core.Bind("ESC", "Clear filter/Exit"),
+).WithTextEntry()
+
 // MenuItem represents a selectable menu item interface
 type MenuItem interface {
 	GetID() string
@@ -300,15 +309,7 @@ func (m *FilterableMenu) drawInfoPanel() {
 
 // drawInstructions draws the help text at the bottom
 func (m *FilterableMenu) drawInstructions() {
-	y := m.y + m.height - 2
-	instructions := " ↑↓:Navigate | Enter:Select | ESC:Clear/Exit | Type to filter "
-
-	if len(instructions) > m.width-2 {
-		instructions = " ↑↓ Enter ESC Type "
-	}
-
-	x := m.x + (m.width-len(instructions))/2
-	m.drawText(x, y, instructions, m.disabledStyle)
+	DrawHint(m.screen, m.x, m.y+m.height-2, m.width, MenuKeymap, m.disabledStyle)
 }
 
 // HandleInput processes keyboard input
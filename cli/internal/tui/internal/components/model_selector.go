@@ -2,6 +2,7 @@ package components
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
@@ -16,8 +17,68 @@ type ModelSelectorItem struct {
 	ContextSize  int
 	Provider     models.ModelProvider
 	IsDefault    bool
+	Category     string   // production or preview
+	Capabilities []string // e.g. chat, functions, vision
+	PricingInput  float64  // USD per 1M input tokens, 0 when unknown
+	PricingOutput float64  // USD per 1M output tokens, 0 when unknown
 }
 
+// ModelSelectorKeymap lists the keys of the model selector
+var ModelSelectorKeymap = core.RegisterKeymap("models", "Choosing a model",
+	core.Bind("Type", "Search"),
+	core.Bind("↑↓", "Navigate"),
+	core.Bind("Ctrl+U", "Clear search"),
+	core.Bind("Ctrl+F", "Favorite"),
+	core.Bind("Tab", "Filters"),
+	core.Bind("Ctrl+O", "Sort by cost"),
+	core.Bind("Enter", "Select"),
+	core.Bind("ESC", "Cancel"),
+).WithTextEntry()
+
+// modelFacetKeymap lists the keys while the filter bar has focus
+var modelFacetKeymap = core.RegisterKeymap("models.filters", "Choosing a model: filters",
+	core.Bind("←→", "Choose a filter"),
+	core.Bind("Space", "Toggle or cycle"),
+	core.Bind("⌫", "Clear filters"),
+	core.Bind("↑↓", "Navigate"),
+	core.Bind("Tab", "Search"),
+	core.Bind("Enter", "Select"),
+	core.Bind("ESC", "Cancel"),
+)
+
+// Facets of the filter bar, in display order
+const (
+	facetVision = iota
+	facetFunctions
+	facetReasoning
+	facetCategory
+	facetContext
+	facetPrice
+	facetCount
+)
+
+// facetCapabilities are the capabilities the capability facets require
+var facetCapabilities = map[int]string{
+	facetVision:    "vision",
+	facetFunctions: "functions",
+	facetReasoning: "reasoning",
+}
+
+// contextRanges are the context size ranges the context facet cycles through
+var contextRanges = []struct {
+	min, max int
+	label    string
+}{
+	{0, 0, "ctx any"},
+	{0, 32000, "ctx <32K"},
+	{32000, 128000, "ctx 32K-128K"},
+	{128000, 0, "ctx ≥128K"},
+	{1000000, 0, "ctx ≥1M"},
+}
+
+// priceCeilings are the input prices per 1M tokens the price facet cycles through
+var priceCeilings = []float64{0, 0.5, 2, 10}
+
 // ModelSelector provides a filterable model selection dropdown
 type ModelSelector struct {
 	screen        tcell.Screen
@@ -34,6 +95,23 @@ type ModelSelector struct {
 	// Current selection
 	currentValue  string
 
+	// Favorite models are listed first; toggleFavorite pins or unpins one and
+	// reports whether it is pinned now
+	favorites      map[string]bool
+	toggleFavorite func(id string) bool
+
+	// Facet filter, edited in the filter bar; onFilter remembers it for next time
+	filter        core.ModelFilter
+	onFilter      func(core.ModelFilter)
+	facetFocus    bool     // The filter bar has the keyboard instead of the search
+	selectedFacet int
+	facetSpans    [][2]int // Screen columns of each facet, for clicks
+
+	// Estimated tokens of replaying the current conversation, priced per model
+	replayPrompt     int
+	replayCompletion int
+	sortByCost       bool // Cheapest first, models without a price last
+
 	// Styles
 	normalStyle   tcell.Style
 	selectedStyle tcell.Style
@@ -56,8 +134,8 @@ func NewModelSelector(screen tcell.Screen, title string, modelRegistry *models.M
 		selectedIndex: 0,
 		filterText:    "",
 		currentValue:  currentValue,
-		width:         60,
-		height:        20,
+		width:         64,
+		height:        21,
 
 		// Styles
 		normalStyle:   tcell.StyleDefault.Foreground(tcell.ColorWhite),
@@ -95,11 +173,15 @@ func (ms *ModelSelector) loadModels(registry *models.ModelRegistry, provider mod
 		}
 
 		item := ModelSelectorItem{
-			ID:          model.ID,
-			Name:        model.Name,
-			ContextSize: model.ContextWindow,
-			Provider:    model.Provider,
-			IsDefault:   model.IsDefault,
+			ID:           model.ID,
+			Name:         model.Name,
+			ContextSize:  model.ContextWindow,
+			Provider:     model.Provider,
+			IsDefault:    model.IsDefault,
+			Category:     model.Category,
+			Capabilities: model.Capabilities,
+			PricingInput: model.PricingInput,
+			PricingOutput: model.PricingOutput,
 		}
 
 		// Add default models first
@@ -111,11 +193,72 @@ func (ms *ModelSelector) loadModels(registry *models.ModelRegistry, provider mod
 	}
 }
 
-// applyFilter applies the current filter text to the models
+// SetFavorites marks the favorite models, lists them first and lets Ctrl+F toggle them
+func (ms *ModelSelector) SetFavorites(ids []string, toggle func(id string) bool) {
+	ms.favorites = make(map[string]bool, len(ids))
+	for _, id := range ids {
+		ms.favorites[id] = true
+	}
+	ms.toggleFavorite = toggle
+	ms.sortFavorites()
+	ms.selectCurrentModel()
+}
+
+// sortFavorites moves the favorite models to the top, keeping the order otherwise
+func (ms *ModelSelector) sortFavorites() {
+	sort.SliceStable(ms.models, func(i, j int) bool {
+		return ms.favorites[ms.models[i].ID] && !ms.favorites[ms.models[j].ID]
+	})
+	ms.applyFilter()
+}
+
+// SetFilter applies the facet filter used last time; onChange is called with
+// the filter whenever it is changed in the filter bar
+func (ms *ModelSelector) SetFilter(filter core.ModelFilter, onChange func(core.ModelFilter)) {
+	ms.filter = filter
+	ms.onFilter = onChange
+	ms.applyFilter()
+	ms.selectCurrentModel()
+}
+
+// SetConversation shows next to each model what replaying the current
+// conversation would cost on it, given its estimated tokens
+func (ms *ModelSelector) SetConversation(promptTokens, completionTokens int) {
+	ms.replayPrompt = promptTokens
+	ms.replayCompletion = completionTokens
+	ms.applyFilter()
+}
+
+// cost returns the cost of replaying the conversation on a model, or its input
+// price per 1M tokens when there is no conversation; false when it has no price
+func (ms *ModelSelector) cost(model ModelSelectorItem) (float64, bool) {
+	if model.PricingInput == 0 && model.PricingOutput == 0 {
+		return 0, false
+	}
+	if ms.replayPrompt == 0 && ms.replayCompletion == 0 {
+		return model.PricingInput, true
+	}
+	return (float64(ms.replayPrompt)*model.PricingInput + float64(ms.replayCompletion)*model.PricingOutput) / 1_000_000, true
+}
+
+// sortCost orders the filtered models by cost, cheapest first, keeping the
+// order of models with the same or no price
+func (ms *ModelSelector) sortCost() {
+	sort.SliceStable(ms.filteredModels, func(i, j int) bool {
+		a, aKnown := ms.cost(ms.filteredModels[i])
+		b, bKnown := ms.cost(ms.filteredModels[j])
+		if aKnown != bKnown {
+			return aKnown
+		}
+		return a < b
+	})
+}
+
+// applyFilter applies the current filter text and facets to the models
 func (ms *ModelSelector) applyFilter() {
 	ms.filteredModels = make([]ModelSelectorItem, 0)
 
-	if ms.filterText == "" {
+	if ms.filterText == "" && ms.filter.IsZero() {
 		// No filter, show all
 		ms.filteredModels = ms.models
 	} else {
@@ -125,11 +268,18 @@ func (ms *ModelSelector) applyFilter() {
 			idLower := strings.ToLower(model.ID)
 			nameLower := strings.ToLower(model.Name)
 
-			if strings.Contains(idLower, filterLower) || strings.Contains(nameLower, filterLower) {
+			if !strings.Contains(idLower, filterLower) && !strings.Contains(nameLower, filterLower) {
+				continue
+			}
+			if matchesFilter(model, ms.filter) {
 				ms.filteredModels = append(ms.filteredModels, model)
 			}
 		}
 	}
+	if ms.sortByCost {
+		ms.filteredModels = append([]ModelSelectorItem(nil), ms.filteredModels...)
+		ms.sortCost()
+	}
 
 	// Reset selection if out of bounds
 	if ms.selectedIndex >= len(ms.filteredModels) {
@@ -137,6 +287,122 @@ func (ms *ModelSelector) applyFilter() {
 	}
 }
 
+// matchesFilter reports whether a model passes every facet of the filter
+func matchesFilter(model ModelSelectorItem, filter core.ModelFilter) bool {
+	for _, capability := range filter.Capabilities {
+		if !hasCapability(model, capability) {
+			return false
+		}
+	}
+	switch {
+	case filter.Category != "" && model.Category != filter.Category:
+		return false
+	case model.ContextSize < filter.MinContext:
+		return false
+	case filter.MaxContext > 0 && model.ContextSize >= filter.MaxContext:
+		return false
+	case filter.MaxPrice > 0 && model.PricingInput > filter.MaxPrice:
+		return false
+	}
+	return true
+}
+
+func hasCapability(model ModelSelectorItem, capability string) bool {
+	for _, c := range model.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleFacet toggles a capability facet or moves a cycling facet to its next value
+func (ms *ModelSelector) toggleFacet(facet int) {
+	f := &ms.filter
+	switch facet {
+	case facetVision, facetFunctions, facetReasoning:
+		capability := facetCapabilities[facet]
+		var rest []string
+		for _, c := range f.Capabilities {
+			if c != capability {
+				rest = append(rest, c)
+			}
+		}
+		if len(rest) == len(f.Capabilities) {
+			rest = append(rest, capability)
+		}
+		f.Capabilities = rest
+	case facetCategory:
+		switch f.Category {
+		case "":
+			f.Category = "production"
+		case "production":
+			f.Category = "preview"
+		default:
+			f.Category = ""
+		}
+	case facetContext:
+		next := contextRanges[(ms.contextRange()+1)%len(contextRanges)]
+		f.MinContext, f.MaxContext = next.min, next.max
+	case facetPrice:
+		next := 0
+		for i, ceiling := range priceCeilings {
+			if ceiling == f.MaxPrice {
+				next = (i + 1) % len(priceCeilings)
+			}
+		}
+		f.MaxPrice = priceCeilings[next]
+	}
+	ms.filterChanged()
+}
+
+// filterChanged refilters the list and remembers the filter
+func (ms *ModelSelector) filterChanged() {
+	ms.applyFilter()
+	if ms.onFilter != nil {
+		ms.onFilter(ms.filter)
+	}
+}
+
+// contextRange returns the index of the current context range, -1 for one set by hand in the config
+func (ms *ModelSelector) contextRange() int {
+	for i, r := range contextRanges {
+		if r.min == ms.filter.MinContext && r.max == ms.filter.MaxContext {
+			return i
+		}
+	}
+	return -1
+}
+
+// facetLabel returns the text of a facet in the filter bar and whether it filters
+func (ms *ModelSelector) facetLabel(facet int) (string, bool) {
+	f := ms.filter
+	switch facet {
+	case facetCategory:
+		if f.Category == "" {
+			return "all", false
+		}
+		return f.Category, true
+	case facetContext:
+		if i := ms.contextRange(); i >= 0 {
+			return contextRanges[i].label, i > 0
+		}
+		return fmt.Sprintf("ctx %s-%s", ms.formatContextSize(f.MinContext), ms.formatContextSize(f.MaxContext)), true
+	case facetPrice:
+		if f.MaxPrice == 0 {
+			return "price any", false
+		}
+		return fmt.Sprintf("≤$%g/M", f.MaxPrice), true
+	}
+	capability := facetCapabilities[facet]
+	for _, c := range f.Capabilities {
+		if c == capability {
+			return capability, true
+		}
+	}
+	return capability, false
+}
+
 // selectCurrentModel finds and selects the current model
 func (ms *ModelSelector) selectCurrentModel() {
 	for i, model := range ms.filteredModels {
@@ -152,6 +418,7 @@ func (ms *ModelSelector) Draw() {
 	ms.drawBorder()
 	ms.drawTitle()
 	ms.drawFilter()
+	ms.drawFacets()
 	ms.drawModels()
 	ms.drawInstructions()
 }
@@ -191,7 +458,10 @@ func (ms *ModelSelector) drawBorder() {
 // drawTitle draws the selector title
 func (ms *ModelSelector) drawTitle() {
 	title := fmt.Sprintf(" %s ", ms.title)
-	x := ms.x + (ms.width-len(title))/2
+	if tokens := ms.replayPrompt + ms.replayCompletion; tokens > 0 {
+		title = fmt.Sprintf(" %s · cost to replay ~%s tokens ", ms.title, ms.formatContextSize(tokens))
+	}
+	x := ms.x + (ms.width-len([]rune(title)))/2
 	ms.drawText(x, ms.y, title, ms.borderStyle)
 }
 
@@ -221,9 +491,30 @@ func (ms *ModelSelector) drawFilter() {
 
 	// Draw cursor
 	cursorX := filterX + len(displayText)
-	if cursorX < ms.x+ms.width-2 {
+	if cursorX < ms.x+ms.width-2 && !ms.facetFocus {
 		ms.screen.SetContent(cursorX, y, '█', nil, ms.filterStyle)
 	}
+}
+
+// drawFacets draws the filter bar below the search and the separator under it
+func (ms *ModelSelector) drawFacets() {
+	y := ms.y + 3
+	x := ms.x + 2
+	ms.facetSpans = ms.facetSpans[:0]
+	for facet := 0; facet < facetCount; facet++ {
+		text, active := ms.facetLabel(facet)
+		style := tcell.StyleDefault.Foreground(tcell.ColorGray)
+		if active {
+			style = tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true)
+		}
+		if ms.facetFocus && facet == ms.selectedFacet {
+			style = style.Reverse(true)
+		}
+		width := len([]rune(text))
+		ms.facetSpans = append(ms.facetSpans, [2]int{x, x + width})
+		ms.drawText(x, y, text, style)
+		x += width + 1
+	}
 
 	// Draw separator
 	for i := 1; i < ms.width-1; i++ {
@@ -233,8 +524,12 @@ func (ms *ModelSelector) drawFilter() {
 
 // drawModels draws the filtered model list
 func (ms *ModelSelector) drawModels() {
-	startY := ms.y + 4
-	maxItems := ms.height - 7 // Account for borders, title, filter, instructions
+	startY := ms.y + 5
+	maxItems := ms.height - 8 // Account for borders, title, filter, facets, instructions
+
+	if len(ms.filteredModels) == 0 && !ms.filter.IsZero() {
+		ms.drawText(ms.x+2, startY, "No models match the filters (Tab, then ⌫ clears them)", tcell.StyleDefault.Foreground(tcell.ColorGray))
+	}
 
 	// Calculate scroll position
 	scrollStart := 0
@@ -258,11 +553,21 @@ func (ms *ModelSelector) drawModels() {
 		// Format model display with context size
 		contextStr := ms.formatContextSize(model.ContextSize)
 		text := fmt.Sprintf("%s (%s)", model.ID, contextStr)
+		if cost, ok := ms.cost(model); ok {
+			if ms.replayPrompt == 0 && ms.replayCompletion == 0 {
+				text += fmt.Sprintf(" $%g/M", cost)
+			} else {
+				text += " ~" + formatCost(cost)
+			}
+		}
 
-		// Add star for default model
+		// Add star for default model and heart for favorites
 		if model.IsDefault {
 			text = "★ " + text
 		}
+		if ms.favorites[model.ID] {
+			text = "♥ " + text
+		}
 
 		// Truncate if too long
 		maxLen := ms.width - 4
@@ -290,6 +595,14 @@ func (ms *ModelSelector) drawModels() {
 	}
 }
 
+// formatCost formats a USD amount with enough digits for fractions of a cent
+func formatCost(cost float64) string {
+	if cost < 0.01 {
+		return fmt.Sprintf("$%.4f", cost)
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
+
 // formatContextSize formats the context window size for display
 func (ms *ModelSelector) formatContextSize(size int) string {
 	if size >= 1000000 {
@@ -302,27 +615,50 @@ func (ms *ModelSelector) formatContextSize(size int) string {
 
 // drawInstructions draws the bottom instructions
 func (ms *ModelSelector) drawInstructions() {
-	y := ms.y + ms.height - 2
-	instructions := " Type to search • ↑↓ Navigate • Enter Select • Esc Cancel "
+	DrawHint(ms.screen, ms.x, ms.y+ms.height-2, ms.width, ms.Keymap(), ms.borderStyle)
+}
 
-	// Center the instructions
-	x := ms.x + (ms.width-len(instructions))/2
-	if x < ms.x+1 {
-		x = ms.x + 1
+// Keymap returns the bindings of the part that has the keyboard
+func (ms *ModelSelector) Keymap() *core.Keymap {
+	if ms.facetFocus {
+		return modelFacetKeymap
 	}
-
-	ms.drawText(x, y, instructions, ms.borderStyle)
+	return ModelSelectorKeymap
 }
 
 // drawText draws text at the given position
 func (ms *ModelSelector) drawText(x, y int, text string, style tcell.Style) {
-	for i, r := range text {
+	for i, r := range []rune(text) {
 		ms.screen.SetContent(x+i, y, r, nil, style)
 	}
 }
 
 // HandleInput processes keyboard input
 func (ms *ModelSelector) HandleInput(ev *tcell.EventKey) (string, bool) {
+	if ev.Key() == tcell.KeyTab || ev.Key() == tcell.KeyBacktab {
+		ms.facetFocus = !ms.facetFocus
+		return "", false
+	}
+	if ms.facetFocus {
+		switch ev.Key() {
+		case tcell.KeyLeft:
+			ms.selectedFacet = (ms.selectedFacet + facetCount - 1) % facetCount
+			return "", false
+		case tcell.KeyRight:
+			ms.selectedFacet = (ms.selectedFacet + 1) % facetCount
+			return "", false
+		case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyDelete:
+			ms.filter = core.ModelFilter{}
+			ms.filterChanged()
+			return "", false
+		case tcell.KeyRune:
+			if ev.Rune() == ' ' {
+				ms.toggleFacet(ms.selectedFacet)
+			}
+			return "", false
+		}
+	}
+
 	switch ev.Key() {
 	case tcell.KeyEscape:
 		// Cancel selection
@@ -360,10 +696,38 @@ func (ms *ModelSelector) HandleInput(ev *tcell.EventKey) (string, bool) {
 		ms.filterText += string(r)
 		ms.applyFilter()
 
+	case tcell.KeyCtrlO:
+		// Sort by cost or go back to the usual order, keeping the selection
+		var id string
+		if ms.selectedIndex < len(ms.filteredModels) {
+			id = ms.filteredModels[ms.selectedIndex].ID
+		}
+		ms.sortByCost = !ms.sortByCost
+		ms.applyFilter()
+		for i, model := range ms.filteredModels {
+			if model.ID == id {
+				ms.selectedIndex = i
+			}
+		}
+
 	case tcell.KeyCtrlU:
 		// Clear filter
 		ms.filterText = ""
 		ms.applyFilter()
+
+	case tcell.KeyCtrlF:
+		// Pin or unpin the selected model, keeping it selected as it moves
+		if ms.toggleFavorite == nil || ms.selectedIndex >= len(ms.filteredModels) {
+			break
+		}
+		id := ms.filteredModels[ms.selectedIndex].ID
+		ms.favorites[id] = ms.toggleFavorite(id)
+		ms.sortFavorites()
+		for i, model := range ms.filteredModels {
+			if model.ID == id {
+				ms.selectedIndex = i
+			}
+		}
 	}
 
 	return "", false
@@ -404,9 +768,20 @@ func (ms *ModelSelector) HandleMouse(event *core.MouseEvent) (string, bool) {
 	case core.MouseEventClick:
 		// Check if click is on a model item
 		if event.Button == core.MouseButtonLeft {
+			// A click on a facet toggles it
+			if event.Y == ms.y+3 {
+				for facet, span := range ms.facetSpans {
+					if event.X >= span[0] && event.X < span[1] {
+						ms.selectedFacet = facet
+						ms.toggleFacet(facet)
+					}
+				}
+				return "", false
+			}
+
 			// Calculate which item was clicked
-			startY := ms.y + 4 // Account for border, title, filter
-			maxItems := ms.height - 7 // Account for borders, title, filter, instructions
+			startY := ms.y + 5 // Account for border, title, filter, facets
+			maxItems := ms.height - 8 // Account for borders, title, filter, facets, instructions
 
 			// Calculate scroll position
 			scrollStart := 0
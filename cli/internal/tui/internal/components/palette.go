@@ -0,0 +1,291 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// PaletteEntry is one item the palette can jump to
+type PaletteEntry struct {
+	Kind   string // "Page", "Setting", "Prompt", "Function", "Model", "Template" or "Session"
+	Title  string
+	Detail   string       // Gray text shown after the title; also searched
+	Favorite bool         // Pinned; listed first and marked with a heart
+	Action   func() error // Runs when the entry is chosen
+}
+
+// Palette is a Ctrl+P style overlay that fuzzy-searches entries and runs the
+// chosen one
+type Palette struct {
+	screen   tcell.Screen
+	entries  []PaletteEntry
+	matches  []PaletteEntry
+	query    []rune
+	selected int
+	offset   int
+	err      string
+	x, y     int
+	width    int
+	height   int
+}
+
+// maxPaletteRows is the number of matches shown at once
+const maxPaletteRows = 14
+
+// NewPalette creates a palette over entries
+func NewPalette(screen tcell.Screen, entries []PaletteEntry) *Palette {
+	p := &Palette{screen: screen, entries: entries}
+	p.filter()
+	return p
+}
+
+// FuzzyScore reports whether all runes of query appear in text in order,
+// ignoring case, and how good the match is. Consecutive runes and runes at
+// word starts score higher, so "set mod" ranks "Settings: Model" first.
+func FuzzyScore(query, text string) (int, bool) {
+	q := []rune(strings.ToLower(strings.Join(strings.Fields(query), "")))
+	if len(q) == 0 {
+		return 0, true
+	}
+	t := []rune(text)
+	score, qi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if unicode.ToLower(t[ti]) != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 5
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) ||
+			unicode.IsUpper(t[ti]) && unicode.IsLower(t[ti-1]) {
+			score += 3
+		}
+		prev = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	// Prefer shorter texts among equal matches
+	return score*100 - len(t), true
+}
+
+// filter recomputes the matches for the current query, best first
+func (p *Palette) filter() {
+	type scored struct {
+		entry PaletteEntry
+		score int
+	}
+	var found []scored
+	for _, entry := range p.entries {
+		// Match the title first; the kind and detail only break ties
+		if score, ok := FuzzyScore(string(p.query), entry.Title); ok {
+			found = append(found, scored{entry, score * 2})
+		} else if score, ok := FuzzyScore(string(p.query), entry.Kind+" "+entry.Title+" "+entry.Detail); ok {
+			found = append(found, scored{entry, score})
+		}
+	}
+	// Best matches first, favorites first among equals (and without a query)
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score > found[j].score
+		}
+		return found[i].entry.Favorite && !found[j].entry.Favorite
+	})
+
+	p.matches = p.matches[:0]
+	for _, f := range found {
+		p.matches = append(p.matches, f.entry)
+	}
+	p.selected, p.offset = 0, 0
+}
+
+// Draw renders the palette centered near the top of the screen
+func (p *Palette) Draw() {
+	w, h := p.screen.Size()
+	p.width = min(80, w-4)
+	rows := min(maxPaletteRows, max(1, h-10))
+	p.height = rows + 4
+	p.x = (w - p.width) / 2
+	p.y = max(1, h/6)
+
+	border := tcell.StyleDefault.Foreground(tcell.ColorTeal)
+	fill := tcell.StyleDefault.Background(tcell.ColorBlack)
+	for row := 0; row < p.height; row++ {
+		for col := 0; col < p.width; col++ {
+			r := ' '
+			switch {
+			case row == 0 && col == 0:
+				r = '╭'
+			case row == 0 && col == p.width-1:
+				r = '╮'
+			case row == p.height-1 && col == 0:
+				r = '╰'
+			case row == p.height-1 && col == p.width-1:
+				r = '╯'
+			case row == 0 || row == p.height-1:
+				r = '─'
+			case col == 0 || col == p.width-1:
+				r = '│'
+			}
+			style := fill
+			if r != ' ' {
+				style = border.Background(tcell.ColorBlack)
+			}
+			p.screen.SetContent(p.x+col, p.y+row, r, nil, style)
+		}
+	}
+	p.text(p.x+2, p.y, " Go to anything ", border.Background(tcell.ColorBlack).Bold(true), p.width-4)
+
+	// Query line
+	prompt := fill.Foreground(tcell.ColorYellow).Bold(true)
+	p.text(p.x+2, p.y+1, "> ", prompt, 2)
+	query := p.query
+	if len(query) > p.width-7 {
+		query = query[len(query)-(p.width-7):]
+	}
+	p.text(p.x+4, p.y+1, string(query), fill.Foreground(tcell.ColorWhite), p.width-6)
+	p.screen.SetContent(p.x+4+len(query), p.y+1, '█', nil, fill.Foreground(tcell.ColorYellow))
+
+	// Matches
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+	if p.selected >= p.offset+rows {
+		p.offset = p.selected - rows + 1
+	}
+	if len(p.matches) == 0 {
+		p.text(p.x+2, p.y+2, "No matches", fill.Foreground(tcell.ColorGray).Italic(true), p.width-4)
+	}
+	for i := p.offset; i < len(p.matches) && i-p.offset < rows; i++ {
+		entry := p.matches[i]
+		y := p.y + 2 + i - p.offset
+		style := fill.Foreground(tcell.ColorWhite)
+		gray := fill.Foreground(tcell.ColorGray)
+		if i == p.selected {
+			style = tcell.StyleDefault.Background(tcell.ColorDarkBlue).Foreground(tcell.ColorWhite)
+			gray = style.Foreground(tcell.ColorLightGray)
+			for col := 1; col < p.width-1; col++ {
+				p.screen.SetContent(p.x+col, y, ' ', nil, style)
+			}
+		}
+		kind := fmt.Sprintf("%-9s", entry.Kind)
+		p.text(p.x+2, y, kind, gray, 9)
+		if entry.Favorite {
+			p.text(p.x+11, y, "♥", style.Foreground(tcell.ColorRed), 1)
+		}
+		n := p.text(p.x+12, y, entry.Title, style.Bold(i == p.selected), p.width-14)
+		if entry.Detail != "" {
+			p.text(p.x+13+n, y, entry.Detail, gray, p.width-15-n)
+		}
+	}
+
+	// Footer
+	footer := fmt.Sprintf(" %d/%d · ↑↓:Select | Enter:Go | ESC:Close ", len(p.matches), len(p.entries))
+	if p.err != "" {
+		footer = " " + p.err + " "
+	}
+	p.text(p.x+2, p.y+p.height-1, footer, border.Background(tcell.ColorBlack), p.width-4)
+}
+
+// text draws s clipped to width and returns the number of cells used
+func (p *Palette) text(x, y int, s string, style tcell.Style, width int) int {
+	n := 0
+	for _, r := range s {
+		if n >= width {
+			break
+		}
+		if n == width-1 && len([]rune(s)) > width {
+			r = '…'
+		}
+		p.screen.SetContent(x+n, y, r, nil, style)
+		n++
+	}
+	return n
+}
+
+// HandleInput processes keyboard input; it returns true when the palette
+// should close, after running the chosen entry on Enter
+func (p *Palette) HandleInput(ev *tcell.EventKey) bool {
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlP:
+		return true
+	case tcell.KeyEnter:
+		return p.choose()
+	case tcell.KeyUp, tcell.KeyCtrlK:
+		if p.selected > 0 {
+			p.selected--
+		}
+	case tcell.KeyDown, tcell.KeyCtrlJ:
+		if p.selected < len(p.matches)-1 {
+			p.selected++
+		}
+	case tcell.KeyPgUp:
+		p.selected = max(0, p.selected-maxPaletteRows)
+	case tcell.KeyPgDn:
+		p.selected = max(0, min(len(p.matches)-1, p.selected+maxPaletteRows))
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.filter()
+		}
+	case tcell.KeyCtrlU:
+		p.query = nil
+		p.filter()
+	case tcell.KeyRune:
+		p.query = append(p.query, ev.Rune())
+		p.filter()
+	}
+	return false
+}
+
+// HandleMouse selects a match on hover or click, runs it on click and scrolls
+// on wheel events; it returns true when the palette should close
+func (p *Palette) HandleMouse(event *core.MouseEvent) bool {
+	switch event.Type {
+	case core.MouseEventScroll:
+		switch event.Button {
+		case core.MouseWheelUp:
+			if p.selected > 0 {
+				p.selected--
+			}
+		case core.MouseWheelDown:
+			if p.selected < len(p.matches)-1 {
+				p.selected++
+			}
+		}
+	case core.MouseEventClick, core.MouseEventHover:
+		inside := event.X >= p.x && event.X < p.x+p.width && event.Y >= p.y && event.Y < p.y+p.height
+		if !inside {
+			return event.Type == core.MouseEventClick
+		}
+		index := event.Y - p.y - 2 + p.offset
+		if event.Y >= p.y+2 && event.Y < p.y+p.height-2 && index < len(p.matches) {
+			p.selected = index
+			if event.Type == core.MouseEventClick {
+				return p.choose()
+			}
+		}
+	}
+	return false
+}
+
+// choose runs the selected entry; an error keeps the palette open
+func (p *Palette) choose() bool {
+	if len(p.matches) == 0 {
+		return false
+	}
+	if action := p.matches[p.selected].Action; action != nil {
+		if err := action(); err != nil {
+			p.err = "Error: " + err.Error()
+			return false
+		}
+	}
+	return true
+}
@@ -5,6 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/artifacts"
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/pkg/sharelink"
 )
 
 // Config represents the application configuration
@@ -15,24 +22,75 @@ type Config struct {
 	BaseURL      string `json:"base_url"`
 	Model        string `json:"model"`
 
+	// Further keys per provider, rotated with APIKey to spread rate limits
+	ExtraAPIKeys map[string][]string `json:"extra_api_keys,omitempty"`
+	KeyRotation  string              `json:"key_rotation"` // on_429, round_robin
+
+	// Extra HTTP headers per provider, e.g. routing keys for self-hosted gateways
+	ProviderHeaders map[string]map[string]string `json:"provider_headers,omitempty"`
+
+	// Request and response mapping of the custom_protocol provider
+	Protocol *ProtocolTemplate `json:"protocol,omitempty"`
+
 	// Model Parameters
 	Temperature      float64 `json:"temperature"`
 	MaxTokens        int     `json:"max_tokens"`
 	TopP             float64 `json:"top_p"`
 	FrequencyPenalty float64 `json:"frequency_penalty"`
 	PresencePenalty  float64 `json:"presence_penalty"`
+	Seed             int     `json:"seed,omitempty"` // 0 lets the provider pick
+
+	// Runtime options per model for local providers
+	LocalRuntime map[string]LocalRuntimeOptions `json:"local_runtime,omitempty"` // Model -> options
 
 	// Features
 	StreamMode    bool   `json:"stream_mode"`
 	YoloMode      bool   `json:"yolo_mode"`       // Auto-execute functions
 	VoiceControl  bool   `json:"voice_control"`
 	SystemPrompt  string `json:"system_prompt"`
+	Language      string `json:"language,omitempty"` // Reply language: a code like sv, auto or off
+
+	// Input behaviour while a response is streaming
+	InputLockMode string `json:"input_lock_mode"` // block, queue, replace
+
+	// Check prompts for pasted secrets and slips before sending
+	LintPrompts bool `json:"lint_prompts"`
+
+	// Budget limits (0 means unlimited)
+	MaxTokensPerRequest int     `json:"max_tokens_per_request"` // Estimated prompt tokens per request
+	MaxCostPerSession   float64 `json:"max_cost_per_session"`   // USD per chat session
+	MaxCostPerDay       float64 `json:"max_cost_per_day"`       // USD per calendar day
+
+	// Chat display
+	ShowTimestamps bool   `json:"show_timestamps"` // Time of each message in its header
+	ShowModelName  bool   `json:"show_model_name"` // Model that wrote each assistant message
+	MessageLayout  string `json:"message_layout"`  // spacious, compact
+
+	// Notifications
+	NotifyOnComplete   bool `json:"notify_on_complete"`   // Desktop notification when a slow response finishes
+	NotifyAfterSeconds int  `json:"notify_after_seconds"` // Minimum response time before notifying
+
+	// Artifact cleanup (0 uses the default, negative means no limit)
+	ArtifactsMaxAgeDays int `json:"artifacts_max_age_days"`
+	ArtifactsMaxSizeMB  int `json:"artifacts_max_size_mb"`
 
 	// Offline mode settings (not serialized)
 	IsOfflineMode         bool `json:"-"` // Offline mode flag
 	AllowRemoteMCP        bool `json:"-"` // Allow remote MCP in offline mode
 	AllowRemoteEmbeddings bool `json:"-"` // Allow remote embeddings in offline mode
 
+	// Share link lock (not serialized, comes from the CLI config)
+	LockedByLink bool   `json:"-"` // Prompts, functions and provider are read-only
+	UnlockHash   string `json:"-"` // Hash of the link's override password
+
+	// Kiosk mode (not serialized, set by --kiosk): settings, prompts and
+	// functions are read-only and sharing is disabled
+	Kiosk bool `json:"-"`
+
+	// Functions, MCP servers, RAG and prompts from the CLI config, offered by the
+	// share link builder (not serialized)
+	ShareSource *sharelink.Config `json:"-"`
+
 	// UI Preferences
 	Theme        string `json:"theme"`          // dark, light, auto
 	PanelLayout  string `json:"panel_layout"`   // horizontal, vertical
@@ -41,16 +99,84 @@ type Config struct {
 	// Session
 	Namespace    string `json:"namespace"`      // Storage namespace
 
+	// Pinned prompts, functions and models, listed first
+	Favorites map[string]map[string][]string `json:"favorites,omitempty"` // Namespace -> kind -> IDs
+
+	// Last filter of the model selector, applied again when it opens
+	ModelFilter ModelFilter `json:"model_filter"`
+
+	// Long-term memory
+	DisableMemory bool `json:"disable_memory"` // Don't add remembered facts to the system prompt
+
 	// Prompts
 	EnabledPrompts []string       `json:"enabled_prompts"` // IDs of enabled prompts
 	CustomPrompts  []CustomPrompt `json:"custom_prompts"`  // User-defined prompts
+
+	// Connection tests
+	ProviderChecks map[string]ProviderCheck `json:"provider_checks,omitempty"` // Last successful test per provider
+}
+
+// ProviderCheck records the last successful connection test of a provider
+type ProviderCheck struct {
+	LastSuccess  time.Time `json:"last_success"`
+	LatencyMS    int64     `json:"latency_ms"`
+	Capabilities []string  `json:"capabilities,omitempty"`
+}
+
+// Input lock modes control what Enter does while a response is streaming
+const (
+	InputLockBlock   = "block"   // Ignore Enter until the response is complete
+	InputLockQueue   = "queue"   // Queue one message and send it when the response completes
+	InputLockReplace = "replace" // Cancel the current response and send the new message
+)
+
+// Message layouts of the chat panel
+const (
+	MessageLayoutSpacious = "spacious" // A blank line between messages
+	MessageLayoutCompact  = "compact"  // Messages follow each other directly
+)
+
+// ProviderCustomProtocol talks to servers that are not OpenAI compatible using Config.Protocol
+const ProviderCustomProtocol = "custom_protocol"
+
+// ProtocolTemplate maps chat requests and streamed responses for the custom_protocol provider.
+// Body is JSON in which {{model}}, {{messages}}, {{prompt}}, {{last_message}}, {{temperature}}
+// and {{max_tokens}} are replaced with JSON values, e.g. {"model": {{model}}, "prompt": {{prompt}}}.
+type ProtocolTemplate struct {
+	Path            string `json:"path"`             // Appended to the base URL, e.g. "/generate"
+	Body            string `json:"body"`             // Request body template
+	StreamDelimiter string `json:"stream_delimiter"` // Separates streamed chunks, a newline by default
+	DataPrefix      string `json:"data_prefix"`      // Stripped from each chunk, e.g. "data: "
+	ContentPath     string `json:"content_path"`     // Dot path to the text of a chunk, e.g. "choices.0.text"
+	DoneMarker      string `json:"done_marker"`      // Chunk that ends the stream; without one the stream ends with the body
+}
+
+// Key rotation modes decide which API key a request uses when several are configured
+const (
+	KeyRotationOn429      = "on_429"      // Stay on one key and move to the next when it is rate limited
+	KeyRotationRoundRobin = "round_robin" // Use the keys in turn, one request each
+)
+
+// ModelFilter narrows the models offered by the model selector. Zero values don't filter.
+type ModelFilter struct {
+	Capabilities []string `json:"capabilities,omitempty"` // Required, e.g. vision, functions, reasoning
+	Category     string   `json:"category,omitempty"`     // production or preview
+	MinContext   int      `json:"min_context,omitempty"`  // Tokens, inclusive
+	MaxContext   int      `json:"max_context,omitempty"`  // Tokens, exclusive
+	MaxPrice     float64  `json:"max_price,omitempty"`    // USD per 1M input tokens; models without a price are kept
+}
+
+// IsZero reports whether the filter lets every model through
+func (f ModelFilter) IsZero() bool {
+	return len(f.Capabilities) == 0 && f.Category == "" && f.MinContext == 0 && f.MaxContext == 0 && f.MaxPrice == 0
 }
 
 // CustomPrompt represents a user-defined system prompt
 type CustomPrompt struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Content string `json:"content"`
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags,omitempty"`
 }
 
 // DefaultConfig returns a new config with default values
@@ -67,6 +193,10 @@ func DefaultConfig() *Config {
 		StreamMode:       true,
 		YoloMode:         false,
 		VoiceControl:     false,
+		NotifyOnComplete: false,
+		InputLockMode:    InputLockBlock,
+		MessageLayout:    MessageLayoutSpacious,
+		KeyRotation:      KeyRotationOn429,
 		Theme:            "dark",
 		PanelLayout:      "horizontal",
 		ShowStatus:       true,
@@ -76,10 +206,20 @@ func DefaultConfig() *Config {
 	}
 }
 
-// ConfigManager handles configuration persistence
+// configSaveDelay is how long Update waits for further changes before writing to disk
+const configSaveDelay = 500 * time.Millisecond
+
+// ConfigManager handles configuration persistence.
+// Update only marks the config dirty and writes it behind after a short delay,
+// so bursts of changes (e.g. from the settings modal) result in a single write.
 type ConfigManager struct {
 	config     *Config
 	configPath string
+
+	mu        sync.Mutex
+	dirty     bool
+	saveTimer *time.Timer
+	saveDelay time.Duration
 }
 
 // NewConfigManager creates a new configuration manager
@@ -110,6 +250,7 @@ func NewConfigManagerWithPath(configPath string) (*ConfigManager, error) {
 	cm := &ConfigManager{
 		configPath: configPath,
 		config:     DefaultConfig(),
+		saveDelay:  configSaveDelay,
 	}
 
 	// Load existing config if available
@@ -130,14 +271,47 @@ func (cm *ConfigManager) Load() error {
 	return json.Unmarshal(data, cm.config)
 }
 
-// Save writes configuration to disk
+// Save writes configuration to disk immediately, cancelling any pending write
 func (cm *ConfigManager) Save() error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.saveTimer != nil {
+		cm.saveTimer.Stop()
+		cm.saveTimer = nil
+	}
+
+	// A kiosk keeps the configuration it was set up with
+	if cm.config.Kiosk {
+		cm.dirty = false
+		return nil
+	}
+
 	data, err := json.MarshalIndent(cm.config, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(cm.configPath, data, 0644)
+	if err := os.WriteFile(cm.configPath, data, 0644); err != nil {
+		return err
+	}
+	cm.dirty = false
+	return nil
+}
+
+// Flush writes pending changes to disk, if there are any
+func (cm *ConfigManager) Flush() error {
+	if !cm.IsDirty() {
+		return nil
+	}
+	return cm.Save()
+}
+
+// IsDirty reports whether there are changes that haven't been written to disk yet
+func (cm *ConfigManager) IsDirty() bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.dirty
 }
 
 // Get returns the current configuration
@@ -145,10 +319,30 @@ func (cm *ConfigManager) Get() *Config {
 	return cm.config
 }
 
-// Update modifies the configuration and saves it
+// Update modifies the configuration and schedules a write-behind save.
+// Call Flush (or Save) before exiting to make sure the change is on disk.
 func (cm *ConfigManager) Update(updater func(*Config)) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
 	updater(cm.config)
-	return cm.Save()
+	cm.dirty = true
+
+	// Restart the delay so a burst of updates is written once
+	if cm.saveTimer != nil {
+		cm.saveTimer.Stop()
+	}
+	cm.saveTimer = time.AfterFunc(cm.saveDelay, cm.saveBehind)
+	return nil
+}
+
+// saveBehind runs when the write-behind delay expires
+func (cm *ConfigManager) saveBehind() {
+	if err := cm.Flush(); err != nil {
+		if log := logger.Get(); log != nil {
+			log.Error("[ConfigManager] Failed to save config: %v", err)
+		}
+	}
 }
 
 // GetConfigPath returns the path to the configuration file
@@ -156,6 +350,41 @@ func (cm *ConfigManager) GetConfigPath() string {
 	return cm.configPath
 }
 
+// RecordProviderCheck stores a successful connection test for provider
+func (c *Config) RecordProviderCheck(provider string, check ProviderCheck) {
+	if c.ProviderChecks == nil {
+		c.ProviderChecks = make(map[string]ProviderCheck)
+	}
+	c.ProviderChecks[provider] = check
+}
+
+// APIKeys returns the keys of the current provider, APIKey first, without duplicates
+func (c *Config) APIKeys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range append([]string{c.APIKey}, c.ExtraAPIKeys[c.Provider]...) {
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// NotifyThreshold returns how long a response must take before a notification is sent
+func (c *Config) NotifyThreshold() time.Duration {
+	if c.NotifyAfterSeconds <= 0 {
+		return config.DefaultNotifyAfterSeconds * time.Second
+	}
+	return time.Duration(c.NotifyAfterSeconds) * time.Second
+}
+
+// ArtifactPolicy returns the cleanup policy for session artifacts
+func (c *Config) ArtifactPolicy() artifacts.Policy {
+	return artifacts.Policy{MaxAgeDays: c.ArtifactsMaxAgeDays, MaxSizeMB: c.ArtifactsMaxSizeMB}
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
 	if c.Provider == "" {
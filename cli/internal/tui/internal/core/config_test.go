@@ -0,0 +1,215 @@
+package core
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hacka-re/cli/pkg/sharelink"
+)
+
+// readSavedModel returns the model stored in the config file, or "" if it doesn't exist
+func readSavedModel(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ""
+	}
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	return cfg.Model
+}
+
+func TestUpdateWritesBehind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cm, err := NewConfigManagerWithPath(path)
+	if err != nil {
+		t.Fatalf("NewConfigManagerWithPath failed: %v", err)
+	}
+	cm.saveDelay = 20 * time.Millisecond
+
+	for _, model := range []string{"gpt-4o", "gpt-4o-mini", "llama3"} {
+		model := model
+		cm.Update(func(cfg *Config) { cfg.Model = model })
+	}
+
+	if !cm.IsDirty() {
+		t.Error("expected config to be dirty after Update")
+	}
+	if got := readSavedModel(t, path); got != "" {
+		t.Errorf("expected no write before the delay, found model %q", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for cm.IsDirty() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if cm.IsDirty() {
+		t.Fatal("expected pending changes to be written")
+	}
+	if got := readSavedModel(t, path); got != "llama3" {
+		t.Errorf("expected last update to be saved, got %q", got)
+	}
+}
+
+func TestFlushWritesPendingChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cm, err := NewConfigManagerWithPath(path)
+	if err != nil {
+		t.Fatalf("NewConfigManagerWithPath failed: %v", err)
+	}
+	cm.saveDelay = time.Hour
+
+	cm.Update(func(cfg *Config) { cfg.Model = "mixtral" })
+	if err := cm.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if cm.IsDirty() {
+		t.Error("expected config to be clean after Flush")
+	}
+	if got := readSavedModel(t, path); got != "mixtral" {
+		t.Errorf("expected flushed model, got %q", got)
+	}
+}
+
+func TestChatDisplaySettingsPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	// Written before the display settings existed
+	if err := os.WriteFile(path, []byte(`{"model": "gpt-4o"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := NewConfigManagerWithPath(path)
+	if err != nil {
+		t.Fatalf("NewConfigManagerWithPath failed: %v", err)
+	}
+	if cfg := cm.Get(); cfg.MessageLayout != MessageLayoutSpacious || cfg.ShowTimestamps || cfg.ShowModelName {
+		t.Errorf("expected the default display settings, got %q %v %v", cfg.MessageLayout, cfg.ShowTimestamps, cfg.ShowModelName)
+	}
+
+	cm.Update(func(cfg *Config) {
+		cfg.ShowTimestamps = true
+		cfg.ShowModelName = true
+		cfg.MessageLayout = MessageLayoutCompact
+	})
+	if err := cm.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	reloaded, err := NewConfigManagerWithPath(path)
+	if err != nil {
+		t.Fatalf("NewConfigManagerWithPath failed: %v", err)
+	}
+	if cfg := reloaded.Get(); cfg.MessageLayout != MessageLayoutCompact || !cfg.ShowTimestamps || !cfg.ShowModelName {
+		t.Errorf("expected the saved display settings, got %q %v %v", cfg.MessageLayout, cfg.ShowTimestamps, cfg.ShowModelName)
+	}
+}
+
+func TestParseLocalRuntimeOptions(t *testing.T) {
+	options, err := ParseLocalRuntimeOptions("ctx=8192 gpu=0 temp=0.6")
+	if err != nil {
+		t.Fatalf("ParseLocalRuntimeOptions failed: %v", err)
+	}
+	if options.ContextSize != 8192 || options.GPULayers == nil || *options.GPULayers != 0 || *options.Temperature != 0.6 {
+		t.Errorf("unexpected options: %+v", options)
+	}
+	if got := options.String(); got != "ctx=8192 gpu=0 temp=0.6" {
+		t.Errorf("expected options to format as they were written, got %q", got)
+	}
+
+	if empty, err := ParseLocalRuntimeOptions(""); err != nil || !empty.IsZero() {
+		t.Errorf("expected an empty string to clear the options, got %+v, %v", empty, err)
+	}
+	for _, text := range []string{"ctx=big", "temp=3", "threads=4", "gpu"} {
+		if _, err := ParseLocalRuntimeOptions(text); err == nil {
+			t.Errorf("expected %q to be rejected", text)
+		}
+	}
+}
+
+func TestLocalRuntimeOptionsOnlyForLocalProviders(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Model = "llama3.2"
+	cfg.LocalRuntime = map[string]LocalRuntimeOptions{"llama3.2": {ContextSize: 4096}}
+
+	if _, ok := cfg.LocalRuntimeOptions(); ok {
+		t.Error("expected no runtime options for a remote provider")
+	}
+	cfg.Provider = "ollama"
+	if options, ok := cfg.LocalRuntimeOptions(); !ok || options.ContextSize != 4096 {
+		t.Errorf("expected the model's options for ollama, got %+v, %v", options, ok)
+	}
+}
+
+func TestToggleFavoriteIsPerNamespace(t *testing.T) {
+	cfg := DefaultConfig()
+	if !cfg.ToggleFavorite(FavoriteModel, "gpt-4o") || !cfg.ToggleFavorite(FavoriteModel, "llama3") {
+		t.Fatal("expected toggling to pin the models")
+	}
+	if got := cfg.FavoritesOf(FavoriteModel); len(got) != 2 || got[0] != "gpt-4o" {
+		t.Errorf("expected favorites in pinning order, got %v", got)
+	}
+
+	cfg.Namespace = "work"
+	if cfg.IsFavorite(FavoriteModel, "gpt-4o") {
+		t.Error("expected favorites of another namespace to be separate")
+	}
+
+	cfg.Namespace = "default"
+	if cfg.ToggleFavorite(FavoriteModel, "gpt-4o") || cfg.IsFavorite(FavoriteModel, "gpt-4o") {
+		t.Error("expected toggling again to unpin the model")
+	}
+	if !cfg.IsFavorite(FavoriteModel, "llama3") {
+		t.Error("expected other favorites to stay pinned")
+	}
+}
+
+func TestUnlockNeedsOverridePassword(t *testing.T) {
+	shared := &sharelink.Config{}
+	if err := shared.Lock("override"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.LockedByLink, cfg.UnlockHash = shared.Locked, shared.UnlockHash
+
+	if cfg.Unlock("wrong") || !cfg.LockedByLink {
+		t.Fatal("expected a wrong password to keep the lock")
+	}
+	if !cfg.Unlock("override") || cfg.LockedByLink {
+		t.Error("expected the override password to lift the lock")
+	}
+}
+
+func TestKioskIsReadOnlyAndNeverSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cm, err := NewConfigManagerWithPath(path)
+	if err != nil {
+		t.Fatalf("NewConfigManagerWithPath failed: %v", err)
+	}
+	cm.Update(func(cfg *Config) {
+		cfg.Kiosk = true
+		cfg.Model = "mixtral"
+	})
+	if err := cm.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if got := readSavedModel(t, path); got != "" {
+		t.Errorf("expected a kiosk not to write its config, got model %q", got)
+	}
+
+	cfg := cm.Get()
+	if !cfg.ReadOnly() {
+		t.Error("expected kiosk mode to be read-only")
+	}
+	if cfg.Unlock("") || !cfg.ReadOnly() {
+		t.Error("expected kiosk mode to stay locked")
+	}
+}
@@ -30,6 +30,9 @@ const (
 	EventConnected      EventType = "connected"
 	EventDisconnected   EventType = "disconnected"
 	EventReconnecting   EventType = "reconnecting"
+	EventModelsLoaded   EventType = "models_loaded"     // Data: services.ModelsResult
+	EventConnectionTested EventType = "connection_tested" // Data: services.ConnectionResult
+	EventKeyValidated   EventType = "key_validated"     // Data: services.KeyValidationResult
 
 	// Config Events
 	EventConfigChanged  EventType = "config_changed"
@@ -39,6 +42,9 @@ const (
 	EventFunctionAdded  EventType = "function_added"
 	EventFunctionRemoved EventType = "function_removed"
 	EventFunctionExecute EventType = "function_execute"
+	EventCellRun         EventType = "cell_run" // Data: playground.Cell
+	EventFunctionTestsRun EventType = "function_tests_run" // Data: []functest.Report
+	EventFunctionsReloaded EventType = "functions_reloaded" // Data: funcdir.Change
 
 	// Mouse Events
 	EventMouseClick      EventType = "mouse_click"
@@ -0,0 +1,43 @@
+package core
+
+// Kinds of items that can be pinned as favorites
+const (
+	FavoritePrompt   = "prompt"   // Prompt IDs
+	FavoriteFunction = "function" // Function names
+	FavoriteModel    = "model"    // Model IDs
+)
+
+// FavoritesOf returns the favorites of a kind in the current namespace, in the order they were pinned
+func (c *Config) FavoritesOf(kind string) []string {
+	return c.Favorites[c.Namespace][kind]
+}
+
+// IsFavorite reports whether id is pinned in the current namespace
+func (c *Config) IsFavorite(kind, id string) bool {
+	for _, favorite := range c.FavoritesOf(kind) {
+		if favorite == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleFavorite pins or unpins id in the current namespace and reports whether it is pinned now
+func (c *Config) ToggleFavorite(kind, id string) bool {
+	favorites := c.FavoritesOf(kind)
+	for i, favorite := range favorites {
+		if favorite == id {
+			c.Favorites[c.Namespace][kind] = append(favorites[:i:i], favorites[i+1:]...)
+			return false
+		}
+	}
+
+	if c.Favorites == nil {
+		c.Favorites = make(map[string]map[string][]string)
+	}
+	if c.Favorites[c.Namespace] == nil {
+		c.Favorites[c.Namespace] = make(map[string][]string)
+	}
+	c.Favorites[c.Namespace][kind] = append(favorites, id)
+	return true
+}
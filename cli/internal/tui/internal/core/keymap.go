@@ -0,0 +1,104 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Binding describes what a key does on a page, for hint lines and the help overlay
+type Binding struct {
+	Keys   string // How the keys are written, e.g. "↑↓" or "Ctrl+S"
+	Action string // What they do, e.g. "Navigate"
+}
+
+// Keymap is the set of bindings of one page or mode
+type Keymap struct {
+	ID        string
+	Title     string
+	Bindings  []Binding
+	TextEntry bool // Printable keys type text here, so help is on F1 only
+}
+
+// Bind creates a binding
+func Bind(keys, action string) Binding {
+	return Binding{Keys: keys, Action: action}
+}
+
+var (
+	keymapsMu sync.RWMutex
+	keymaps   = map[string]*Keymap{}
+)
+
+// GlobalKeymap lists the keys that work on every page
+var GlobalKeymap = RegisterKeymap("global", "Everywhere",
+	Bind("Ctrl+P", "Go to anything (pages, settings, prompts, models)"),
+	Bind("? / F1", "Show the keys of the current page (F1 while typing)"),
+)
+
+// RegisterKeymap adds a keymap to the registry, replacing one with the same ID
+func RegisterKeymap(id, title string, bindings ...Binding) *Keymap {
+	keymap := &Keymap{ID: id, Title: title, Bindings: bindings}
+	keymapsMu.Lock()
+	keymaps[id] = keymap
+	keymapsMu.Unlock()
+	return keymap
+}
+
+// LookupKeymap returns the keymap registered under id, or nil
+func LookupKeymap(id string) *Keymap {
+	keymapsMu.RLock()
+	defer keymapsMu.RUnlock()
+	return keymaps[id]
+}
+
+// Keymaps returns all registered keymaps ordered by ID
+func Keymaps() []*Keymap {
+	keymapsMu.RLock()
+	defer keymapsMu.RUnlock()
+	all := make([]*Keymap, 0, len(keymaps))
+	for _, keymap := range keymaps {
+		all = append(all, keymap)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all
+}
+
+// WithTextEntry marks the keymap as one where printable keys type text
+func (k *Keymap) WithTextEntry() *Keymap {
+	k.TextEntry = true
+	return k
+}
+
+// HelpKey is the key that opens the help overlay on this keymap's page
+func (k *Keymap) HelpKey() string {
+	if k.TextEntry {
+		return "F1"
+	}
+	return "?"
+}
+
+// Hint renders the bindings as a one-line footer no wider than width. When
+// they don't all fit, bindings before the last one are dropped from the end;
+// the last binding (usually ESC) and the help key are always kept.
+func (k *Keymap) Hint(width int) string {
+	help := k.HelpKey() + ":Help"
+	if len(k.Bindings) == 0 {
+		return " " + help + " "
+	}
+	parts := make([]string, len(k.Bindings))
+	for i, b := range k.Bindings {
+		parts[i] = b.Keys + ":" + b.Action
+	}
+	last := parts[len(parts)-1]
+	for n := len(parts) - 1; ; n-- {
+		items := append([]string{}, parts[:n]...)
+		if n < len(parts)-1 {
+			items = append(items, "…")
+		}
+		hint := " " + strings.Join(append(items, last, help), " | ") + " "
+		if n == 0 || len([]rune(hint)) <= width {
+			return hint
+		}
+	}
+}
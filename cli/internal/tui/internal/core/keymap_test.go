@@ -0,0 +1,30 @@
+package core
+
+import "testing"
+
+func TestKeymapHint(t *testing.T) {
+	keymap := RegisterKeymap("test.hint", "Test",
+		Bind("↑↓", "Navigate"),
+		Bind("Enter", "View"),
+		Bind("N", "New"),
+		Bind("ESC", "Back"),
+	)
+	if LookupKeymap("test.hint") != keymap {
+		t.Fatal("keymap not registered")
+	}
+
+	if got, want := keymap.Hint(80), " ↑↓:Navigate | Enter:View | N:New | ESC:Back | ?:Help "; got != want {
+		t.Errorf("Hint(80) = %q, want %q", got, want)
+	}
+	if got, want := keymap.Hint(40), " ↑↓:Navigate | … | ESC:Back | ?:Help "; got != want {
+		t.Errorf("Hint(40) = %q, want %q", got, want)
+	}
+	if got, want := keymap.Hint(5), " … | ESC:Back | ?:Help "; got != want {
+		t.Errorf("Hint(5) = %q, want %q", got, want)
+	}
+
+	keymap.WithTextEntry()
+	if got, want := keymap.Hint(80), " ↑↓:Navigate | Enter:View | N:New | ESC:Back | F1:Help "; got != want {
+		t.Errorf("text entry Hint(80) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package core
+
+import "github.com/hacka-re/cli/pkg/sharelink"
+
+// Unlock lifts the share link lock if password is the override password the
+// link's creator set, and reports whether the configuration is editable now
+func (c *Config) Unlock(password string) bool {
+	if c.Kiosk {
+		return false
+	}
+	if !c.LockedByLink {
+		return true
+	}
+	if !sharelink.CheckOverride(c.UnlockHash, password) {
+		return false
+	}
+	c.LockedByLink = false
+	return true
+}
+
+// ReadOnly reports whether prompts, functions and provider settings may not be
+// edited, because a share link is locked or hacka.re runs as a kiosk
+func (c *Config) ReadOnly() bool {
+	return c.LockedByLink || c.Kiosk
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LocalRuntimeOptions tune how a local provider runs a model.
+// Unset options leave the server's own defaults in place.
+type LocalRuntimeOptions struct {
+	ContextSize int      `json:"context_size,omitempty"` // Tokens of context (num_ctx in Ollama)
+	GPULayers   *int     `json:"gpu_layers,omitempty"`   // Layers offloaded to the GPU (num_gpu in Ollama), 0 runs on the CPU
+	Temperature *float64 `json:"temperature,omitempty"`  // Replaces the global temperature for this model
+}
+
+// IsLocalProvider reports whether the provider runs models on this machine
+func IsLocalProvider(provider string) bool {
+	switch provider {
+	case "ollama", "llamafile", "gpt4all", "lmstudio", "localai":
+		return true
+	}
+	return false
+}
+
+// LocalRuntimeOptions returns the runtime options of the current model when
+// a local provider is selected
+func (c *Config) LocalRuntimeOptions() (LocalRuntimeOptions, bool) {
+	if !IsLocalProvider(c.Provider) {
+		return LocalRuntimeOptions{}, false
+	}
+	options, ok := c.LocalRuntime[c.Model]
+	return options, ok
+}
+
+// IsZero reports whether no option is set
+func (o LocalRuntimeOptions) IsZero() bool {
+	return o.ContextSize == 0 && o.GPULayers == nil && o.Temperature == nil
+}
+
+// String formats the options as "ctx=8192 gpu=35 temp=0.6", the form ParseLocalRuntimeOptions reads
+func (o LocalRuntimeOptions) String() string {
+	var parts []string
+	if o.ContextSize > 0 {
+		parts = append(parts, fmt.Sprintf("ctx=%d", o.ContextSize))
+	}
+	if o.GPULayers != nil {
+		parts = append(parts, fmt.Sprintf("gpu=%d", *o.GPULayers))
+	}
+	if o.Temperature != nil {
+		parts = append(parts, "temp="+strconv.FormatFloat(*o.Temperature, 'f', -1, 64))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseLocalRuntimeOptions reads options written as "ctx=8192 gpu=35 temp=0.6".
+// Any of them may be left out; an empty string clears all.
+func ParseLocalRuntimeOptions(text string) (LocalRuntimeOptions, error) {
+	var options LocalRuntimeOptions
+	for _, field := range strings.Fields(strings.ReplaceAll(text, ",", " ")) {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return options, fmt.Errorf("expected name=value, got %q", field)
+		}
+		switch strings.ToLower(name) {
+		case "ctx":
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 0 {
+				return options, fmt.Errorf("ctx must be a number of tokens, got %q", value)
+			}
+			options.ContextSize = size
+		case "gpu":
+			layers, err := strconv.Atoi(value)
+			if err != nil || layers < 0 {
+				return options, fmt.Errorf("gpu must be a number of layers, got %q", value)
+			}
+			options.GPULayers = &layers
+		case "temp":
+			temperature, err := strconv.ParseFloat(value, 64)
+			if err != nil || temperature < 0 || temperature > 2 {
+				return options, fmt.Errorf("temp must be between 0 and 2, got %q", value)
+			}
+			options.Temperature = &temperature
+		default:
+			return options, fmt.Errorf("unknown option %q, use ctx, gpu or temp", name)
+		}
+	}
+	return options, nil
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/hacka-re/cli/internal/utils"
+)
+
+// RecoverySnapshot is the unsaved work written periodically and on crash
+type RecoverySnapshot struct {
+	SavedAt       time.Time         `json:"saved_at"`
+	Messages      []Message         `json:"messages,omitempty"`
+	ChatInput     string            `json:"chat_input,omitempty"`
+	EditorBuffers map[string]string `json:"editor_buffers,omitempty"`
+}
+
+// IsEmpty reports whether the snapshot has nothing worth restoring
+func (s *RecoverySnapshot) IsEmpty() bool {
+	if s == nil {
+		return true
+	}
+	for _, msg := range s.Messages {
+		if msg.Role != "system" {
+			return false
+		}
+	}
+	return s.ChatInput == "" && len(s.EditorBuffers) == 0
+}
+
+// RecoveryStore persists recovery snapshots and crash reports in a directory
+type RecoveryStore struct {
+	dir string
+}
+
+// NewRecoveryStore creates a store in dir (normally the TUI config directory)
+func NewRecoveryStore(dir string) *RecoveryStore {
+	return &RecoveryStore{dir: dir}
+}
+
+// Path returns the location of the recovery file
+func (rs *RecoveryStore) Path() string {
+	return filepath.Join(rs.dir, "recovery.json")
+}
+
+// Save writes the snapshot, removing the file if there is nothing to recover
+func (rs *RecoveryStore) Save(snapshot *RecoverySnapshot) error {
+	if snapshot.IsEmpty() {
+		return rs.Clear()
+	}
+
+	snapshot.SavedAt = time.Now()
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recovery snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(rs.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create recovery directory: %w", err)
+	}
+
+	// Write to a temp file first so a crash mid-write can't corrupt the last good snapshot
+	tmpPath := rs.Path() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write recovery file: %w", err)
+	}
+	return os.Rename(tmpPath, rs.Path())
+}
+
+// Load reads the recovery file, returning nil if there is none
+func (rs *RecoveryStore) Load() (*RecoverySnapshot, error) {
+	data, err := os.ReadFile(rs.Path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recovery file: %w", err)
+	}
+
+	var snapshot RecoverySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse recovery file: %w", err)
+	}
+	if snapshot.IsEmpty() {
+		return nil, nil
+	}
+	return &snapshot, nil
+}
+
+// Clear removes the recovery file
+func (rs *RecoveryStore) Clear() error {
+	if err := os.Remove(rs.Path()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// WriteCrashReport saves the panic value and stack trace, returning the report path
+func (rs *RecoveryStore) WriteCrashReport(panicValue interface{}, stack []byte) (string, error) {
+	if err := os.MkdirAll(rs.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %w", err)
+	}
+
+	now := time.Now()
+	path := filepath.Join(rs.dir, fmt.Sprintf("crash-%s.log", now.Format("20060102-150405")))
+	report := fmt.Sprintf("hacka.re TUI crash report\nTime: %s\nPanic: %v\n\n%s",
+		now.Format(time.RFC3339), panicValue, stack)
+
+	if err := os.WriteFile(path, []byte(report), 0600); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, nil
+}
+
+// panicHandler is called when a goroutine using HandlePanic panics
+var panicHandler func(panicValue interface{}, stack []byte)
+
+// SetPanicHandler registers the function that restores the terminal and saves state on a panic
+func SetPanicHandler(handler func(panicValue interface{}, stack []byte)) {
+	panicHandler = handler
+}
+
+// HandlePanic recovers a panic in a background goroutine, runs the panic handler and exits.
+// Use it as `defer core.HandlePanic()` at the top of goroutines.
+func HandlePanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if panicHandler != nil {
+		panicHandler(r, debug.Stack())
+	} else {
+		fmt.Fprintf(os.Stderr, "hacka.re crashed: %v\n\n%s\n", r, debug.Stack())
+	}
+	utils.Exit(2)
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecoveryStoreRoundTrip(t *testing.T) {
+	store := NewRecoveryStore(t.TempDir())
+
+	state := NewAppState()
+	state.AddMessage("user", "Explain CSRF")
+	state.AddMessage("assistant", "Cross-site request forgery is...")
+	state.SetCurrentInput("and how do I prevent")
+	state.SetEditorBuffer("prompt:Recon", "You are a recon assistant")
+
+	if err := store.Save(state.Snapshot()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	snapshot, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if snapshot == nil {
+		t.Fatal("expected a snapshot")
+	}
+
+	restored := NewAppState()
+	restored.Restore(snapshot)
+
+	if got := len(restored.GetMessages()); got != 2 {
+		t.Errorf("expected 2 messages, got %d", got)
+	}
+	if got := restored.GetCurrentInput(); got != "and how do I prevent" {
+		t.Errorf("unexpected chat input %q", got)
+	}
+
+	drafts := restored.TakeEditorBuffers("prompt:")
+	if drafts["Recon"] != "You are a recon assistant" {
+		t.Errorf("unexpected drafts %v", drafts)
+	}
+	if len(restored.TakeEditorBuffers("prompt:")) != 0 {
+		t.Error("expected drafts to be removed once taken")
+	}
+}
+
+func TestRecoveryStoreSkipsEmptySnapshot(t *testing.T) {
+	store := NewRecoveryStore(t.TempDir())
+
+	state := NewAppState()
+	state.AddMessage("system", "Welcome")
+	if err := store.Save(state.Snapshot()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if _, err := os.Stat(store.Path()); !os.IsNotExist(err) {
+		t.Error("expected no recovery file for an empty snapshot")
+	}
+
+	snapshot, err := store.Load()
+	if err != nil || snapshot != nil {
+		t.Errorf("expected nil snapshot and error, got %v, %v", snapshot, err)
+	}
+}
+
+func TestWriteCrashReport(t *testing.T) {
+	store := NewRecoveryStore(t.TempDir())
+
+	path, err := store.WriteCrashReport("index out of range", []byte("goroutine 1 [running]:"))
+	if err != nil {
+		t.Fatalf("WriteCrashReport failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read crash report: %v", err)
+	}
+	if !strings.Contains(string(data), "index out of range") || !strings.Contains(string(data), "goroutine 1") {
+		t.Errorf("crash report missing details:\n%s", data)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -50,6 +51,12 @@ type AppState struct {
 	ModalOpen     bool
 	ModalType     string
 
+	// Terminal state
+	TerminalFocused bool // Whether the terminal window has focus (if reported)
+
+	// Unsaved editor content by key (e.g. "prompt:<name>"), kept for crash recovery
+	EditorBuffers map[string]string
+
 	// Command state
 	CommandHistory []string
 	HistoryIndex   int
@@ -97,14 +104,16 @@ const (
 // NewAppState creates a new application state
 func NewAppState() *AppState {
 	return &AppState{
-		Messages:       make([]Message, 0),
-		CommandHistory: make([]string, 0),
-		Functions:      make([]Function, 0),
-		Prompts:        make([]Prompt, 0),
-		Mode:           ModeAuto,
-		ActivePanel:    PanelChat,
-		Connected:      true,
-		LastActivity:   time.Now(),
+		Messages:        make([]Message, 0),
+		CommandHistory:  make([]string, 0),
+		Functions:       make([]Function, 0),
+		Prompts:         make([]Prompt, 0),
+		EditorBuffers:   make(map[string]string),
+		Mode:            ModeAuto,
+		ActivePanel:     PanelChat,
+		Connected:       true,
+		LastActivity:    time.Now(),
+		TerminalFocused: true,
 	}
 }
 
@@ -134,6 +143,24 @@ func (s *AppState) GetMessages() []Message {
 	return msgs
 }
 
+// ReviseMessage replaces the content of the last message with role and
+// content old, and drops the messages after it, so the chat goes on from the
+// revised message. It reports whether the message was found.
+func (s *AppState) ReviseMessage(role, old, revised string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		if s.Messages[i].Role == role && s.Messages[i].Content == old {
+			s.Messages[i].Content = revised
+			s.Messages = s.Messages[:i+1]
+			s.LastActivity = time.Now()
+			return true
+		}
+	}
+	return false
+}
+
 // SetStreaming sets the streaming state
 func (s *AppState) SetStreaming(streaming bool) {
 	s.mu.Lock()
@@ -186,6 +213,91 @@ func (s *AppState) GetActivePanel() Panel {
 	return s.ActivePanel
 }
 
+// SetTerminalFocused records whether the terminal window has focus
+func (s *AppState) SetTerminalFocused(focused bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.TerminalFocused = focused
+}
+
+// IsTerminalFocused returns whether the terminal window has focus
+func (s *AppState) IsTerminalFocused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.TerminalFocused
+}
+
+// SetCurrentInput records the chat input being typed
+func (s *AppState) SetCurrentInput(input string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CurrentInput = input
+}
+
+// GetCurrentInput returns the chat input being typed
+func (s *AppState) GetCurrentInput() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.CurrentInput
+}
+
+// SetEditorBuffer records unsaved editor content; empty content removes the buffer
+func (s *AppState) SetEditorBuffer(key, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if content == "" {
+		delete(s.EditorBuffers, key)
+		return
+	}
+	s.EditorBuffers[key] = content
+}
+
+// TakeEditorBuffers removes and returns all editor buffers whose key starts with prefix
+func (s *AppState) TakeEditorBuffers(prefix string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	taken := make(map[string]string)
+	for key, content := range s.EditorBuffers {
+		if strings.HasPrefix(key, prefix) {
+			taken[strings.TrimPrefix(key, prefix)] = content
+			delete(s.EditorBuffers, key)
+		}
+	}
+	return taken
+}
+
+// Snapshot captures the unsaved work for crash recovery
+func (s *AppState) Snapshot() *RecoverySnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := &RecoverySnapshot{
+		Messages:      make([]Message, len(s.Messages)),
+		ChatInput:     s.CurrentInput,
+		EditorBuffers: make(map[string]string, len(s.EditorBuffers)),
+	}
+	copy(snapshot.Messages, s.Messages)
+	for key, content := range s.EditorBuffers {
+		snapshot.EditorBuffers[key] = content
+	}
+	return snapshot
+}
+
+// Restore replaces the chat and editor state with a recovered snapshot
+func (s *AppState) Restore(snapshot *RecoverySnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Messages = append([]Message(nil), snapshot.Messages...)
+	s.CurrentInput = snapshot.ChatInput
+	s.EditorBuffers = make(map[string]string, len(snapshot.EditorBuffers))
+	for key, content := range snapshot.EditorBuffers {
+		s.EditorBuffers[key] = content
+	}
+	s.LastActivity = time.Now()
+}
+
 // AddToHistory adds a command to the history
 func (s *AppState) AddToHistory(cmd string) {
 	s.mu.Lock()
@@ -0,0 +1,191 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/tui/internal/components"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+	"github.com/hacka-re/cli/internal/tui/internal/pages"
+	"github.com/hacka-re/cli/internal/tui/internal/services"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// dashboardRefreshInterval is how often the tiles re-read usage and page state
+const dashboardRefreshInterval = 5 * time.Second
+
+// connectionCheckTimeout bounds the background reachability check
+const connectionCheckTimeout = 15 * time.Second
+
+// dashboardMinWidth is the narrowest screen that shows the dashboard next to
+// the menu; narrower screens get the menu with its info panel
+const dashboardMinWidth = 110
+
+// mainMenuKeymap lists the keys of the main menu when the dashboard is shown
+var mainMenuKeymap = core.RegisterKeymap("main", "Main Menu",
+	core.Bind("↑↓", "Navigate"),
+	core.Bind("Enter", "Select"),
+	core.Bind("0-9", "Select by number"),
+	core.Bind("Type", "Filter"),
+	core.Bind("Tab", "Dashboard tiles (or click a tile)"),
+	core.Bind("ESC", "Clear filter/Exit"),
+).WithTextEntry()
+
+// dashboardVisible reports whether the screen is wide enough for the dashboard
+func (a *App) dashboardVisible() bool {
+	w, h := a.screen.Size()
+	return w >= dashboardMinWidth && h >= 24
+}
+
+// layoutMainMenu places the menu, and the dashboard when it fits, for the
+// current screen size
+func (a *App) layoutMainMenu() {
+	w, h := a.screen.Size()
+	menuWidth, menuHeight := 50, 20
+	if menuHeight > h-2 {
+		menuHeight = h - 2
+	}
+	a.mainMenu.SetDimensions(menuWidth, menuHeight)
+
+	if !a.dashboardVisible() {
+		infoWidth := 40
+		a.mainMenu.SetInfoPanel(true, infoWidth)
+		a.mainMenu.SetPosition((w-menuWidth-infoWidth-2)/2, (h-menuHeight)/2)
+		a.dashboard.SetFocused(false)
+		return
+	}
+
+	dashWidth := w - menuWidth - 8
+	if dashWidth > 80 {
+		dashWidth = 80
+	}
+	x, y := (w-menuWidth-2-dashWidth)/2, (h-menuHeight)/2
+	a.mainMenu.SetInfoPanel(false, 0)
+	a.mainMenu.SetPosition(x, y)
+	a.dashboard.SetBounds(x+menuWidth+2, y, dashWidth, menuHeight)
+}
+
+// drawMainMenu draws the menu and, when it fits, the dashboard
+func (a *App) drawMainMenu() {
+	a.layoutMainMenu()
+	a.mainMenu.Draw()
+	if a.dashboardVisible() {
+		if time.Since(a.dashboardUpdated) > dashboardRefreshInterval {
+			a.refreshDashboard()
+		}
+		a.dashboard.Draw()
+	}
+}
+
+// checkConnection tests provider reachability in the background; the result
+// arrives as core.EventConnectionTested
+func (a *App) checkConnection() {
+	a.connectionChecking = true
+	client := services.NewChatClient(a.config)
+	go func() {
+		defer core.HandlePanic()
+		ctx, cancel := context.WithTimeout(context.Background(), connectionCheckTimeout)
+		defer cancel()
+		a.eventBus.PublishAsync(core.EventConnectionTested, client.TestConnection(ctx))
+	}()
+}
+
+// refreshDashboard rebuilds the tiles from the configuration, the last
+// connection check, the pages and today's usage
+func (a *App) refreshDashboard() {
+	a.dashboardUpdated = time.Now()
+	cfg := a.config.Get()
+
+	model := components.DashboardTile{
+		Title:  "Model",
+		Lines:  []string{cfg.Provider + " · " + cfg.Model, "Namespace: " + cfg.Namespace},
+		Status: components.TileOK,
+		Action: func() error { return a.jumpToSetting("model") },
+	}
+	if cfg.Model == "" {
+		model.Lines[0] = cfg.Provider + " · no model selected"
+		model.Status = components.TileWarning
+	}
+
+	api := components.DashboardTile{
+		Title:  "API",
+		Lines:  []string{"Checking…"},
+		Action: func() error { return a.jumpToSetting("api_key") },
+	}
+	switch {
+	case a.connection != nil && a.connection.Err != nil:
+		api.Lines = []string{"✗ Unreachable", a.connection.Err.Error()}
+		api.Status = components.TileError
+	case a.connection != nil:
+		api.Lines = []string{"✓ Reachable", fmt.Sprintf("%d ms · %d models", a.connection.Latency.Milliseconds(), a.connection.Models)}
+		api.Status = components.TileOK
+	case !a.connectionChecking:
+		api.Lines = []string{"Not checked"}
+	}
+
+	mcpPage := a.mcpServersPage
+	if mcpPage == nil {
+		mcpPage = pages.NewMCPServersPage(a.screen, a.config, a.state, a.eventBus)
+	}
+	servers := mcpPage.ConnectedServers()
+	mcp := components.DashboardTile{
+		Title:  "MCP Servers",
+		Lines:  []string{fmt.Sprintf("%d connected", len(servers)), strings.Join(servers, ", ")},
+		Action: a.showMCP,
+	}
+	if len(servers) > 0 {
+		mcp.Status = components.TileOK
+	}
+
+	ragPage := a.ragPage
+	if ragPage == nil {
+		ragPage = pages.NewRAGPage(a.screen, a.config, a.state, a.eventBus)
+	}
+	docs, chunks, enabled := ragPage.IndexSummary()
+	rag := components.DashboardTile{
+		Title:  "RAG Index",
+		Lines:  []string{fmt.Sprintf("%d documents · %d chunks", docs, chunks), "Disabled"},
+		Action: a.showRAG,
+	}
+	if enabled {
+		rag.Lines[1], rag.Status = "Enabled", components.TileOK
+	}
+
+	tracker := usage.NewTracker(usage.DefaultPath())
+	in, out := tracker.DailyTokens()
+	spend := components.DashboardTile{
+		Title:  "Usage Today",
+		Lines:  []string{fmt.Sprintf("%d in · %d out tokens", in, out), fmt.Sprintf("$%.4f", tracker.DailyCost())},
+		Action: a.showChat,
+	}
+	if cfg.MaxCostPerDay > 0 {
+		left := cfg.MaxCostPerDay - tracker.DailyCost()
+		spend.Lines[1] += fmt.Sprintf(" · $%.2f left", left)
+		spend.Status = components.TileOK
+		if left < cfg.MaxCostPerDay/10 {
+			spend.Status = components.TileWarning
+		}
+		if left <= 0 {
+			spend.Status = components.TileError
+		}
+	}
+
+	// The TUI keeps one chat session per run
+	messages := a.state.GetMessages()
+	session := components.DashboardTile{
+		Title:  "Sessions",
+		Lines:  []string{fmt.Sprintf("Current chat: %d message(s)", len(messages))},
+		Action: a.showChat,
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			session.Lines = append(session.Lines, "Last: "+strings.Join(strings.Fields(messages[i].Content), " "))
+			session.Status = components.TileOK
+			break
+		}
+	}
+
+	a.dashboard.SetTiles([]components.DashboardTile{model, api, mcp, rag, spend, session})
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"github.com/hacka-re/cli/internal/tui/internal/core"
+	"github.com/hacka-re/cli/internal/utils"
 )
 
 // Handler manages the socket mode interface
@@ -475,7 +476,7 @@ func (c *Context) ShowStatus() error {
 func (c *Context) Exit() error {
 	c.Output("Goodbye!\n")
 	c.handler.Stop()
-	os.Exit(0)
+	utils.Exit(0)
 	return nil
 }
 
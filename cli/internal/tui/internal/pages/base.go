@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/tui/internal/components"
 	"github.com/hacka-re/cli/internal/tui/internal/core"
 )
 
@@ -18,6 +19,8 @@ const (
 	PageTypeMCP
 	PageTypeRAG
 	PageTypeShare
+	PageTypeMemory
+	PageTypePlayground
 )
 
 // Page defines the interface for all pages in the application
@@ -32,6 +35,7 @@ type Page interface {
 	// Metadata
 	GetTitle() string
 	GetType() PageType
+	Keymap() *core.Keymap // Bindings of the current mode, for the hint line and help overlay
 
 	// State management
 	IsDirty() bool
@@ -162,6 +166,17 @@ func (p *BasePage) DrawCenteredText(y int, text string, style tcell.Style) {
 	p.DrawText(x, y, text, style)
 }
 
+// DrawHint draws the keymap's hint line centered at y, fitted to the screen width
+func (p *BasePage) DrawHint(y int, keymap *core.Keymap, style tcell.Style) {
+	w, _ := p.screen.Size()
+	p.DrawHintIn(0, y, w, keymap, style)
+}
+
+// DrawHintIn draws the keymap's hint line centered in the span [x, x+width)
+func (p *BasePage) DrawHintIn(x, y, width int, keymap *core.Keymap, style tcell.Style) {
+	components.DrawHint(p.screen, x, y, width, keymap, style)
+}
+
 // ClearContent clears the entire screen content area
 func (p *BasePage) ClearContent() {
 	w, h := p.screen.Size()
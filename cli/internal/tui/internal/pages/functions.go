@@ -2,10 +2,14 @@ package pages
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/funcdir"
+	"github.com/hacka-re/cli/internal/functest"
 	"github.com/hacka-re/cli/internal/share"
+	"github.com/hacka-re/cli/internal/tags"
 	"github.com/hacka-re/cli/internal/tui/internal/components"
 	"github.com/hacka-re/cli/internal/tui/internal/core"
 )
@@ -25,8 +29,25 @@ type FunctionsPage struct {
 	selectedItemIndex int  // Index of selected item within the group (-1 = group header)
 	visibleHeight     int  // Height of the visible content area
 	totalLines        int  // Total number of lines in content
+	tagFilter         string   // Only functions with this tag are listed ("" shows all)
+	availableTags     []string // Tags used by the listed functions, for cycling the filter
+	lock              *linkLock // Read-only while the share link's creator locked the configuration
+	testReports       map[string]functest.Report // Test results of the custom functions, by name
+	testing           bool                       // The custom functions' tests are running
+	functionsDir      *funcdir.Watcher           // Functions loaded from files, nil if not watched
 }
 
+// functionsKeymap lists the keys of the Functions page
+var functionsKeymap = core.RegisterKeymap("functions", "Functions",
+	core.Bind("↑↓", "Navigate"),
+	core.Bind("Space/Enter", "Expand/Collapse"),
+	core.Bind("T", "Tag filter"),
+	core.Bind("F", "Favorite"),
+	core.Bind("R", "Run tests"),
+	core.Bind("I", "Info"),
+	core.Bind("ESC", "Back"),
+)
+
 // NewFunctionsPage creates a new function calling configuration page
 func NewFunctionsPage(screen tcell.Screen, config *core.ConfigManager, state *core.AppState, eventBus *core.EventBus) *FunctionsPage {
 	_, h := screen.Size()
@@ -37,6 +58,7 @@ func NewFunctionsPage(screen tcell.Screen, config *core.ConfigManager, state *co
 		selectedItemIndex: -1, // Start on group header
 		visibleHeight:     h - 12, // Account for header, footer, borders
 		totalLines:        0,
+		lock:              newLinkLock(screen, config),
 	}
 
 	w, _ := screen.Size()
@@ -72,31 +94,71 @@ func (fp *FunctionsPage) loadFunctions() {
 	// Clear existing items
 	fp.defaultFunctions.ClearItems()
 	fp.customFunctions.ClearItems()
+	fp.availableTags = nil
 
 	// Load default functions - organized by category
 	// Since this is read-only, we'll show example data
-	fp.loadDefaultFunctionGroup("RC4 Encryption", []defaultFunction{
+	fp.loadDefaultFunctionGroup("RC4 Encryption", []string{"crypto"}, []defaultFunction{
 		{"rc4Encrypt", "Encrypt text using RC4", true},
 		{"rc4Decrypt", "Decrypt RC4-encrypted text", true},
 		{"generateKey", "Generate random encryption key", false},
 	})
 
-	fp.loadDefaultFunctionGroup("Mathematical Functions", []defaultFunction{
+	fp.loadDefaultFunctionGroup("Mathematical Functions", []string{"math"}, []defaultFunction{
 		{"calculate", "Perform mathematical calculations", true},
 		{"fibonacci", "Calculate Fibonacci sequence", false},
 		{"factorial", "Calculate factorial", false},
 		{"isPrime", "Check if number is prime", false},
 	})
 
-	fp.loadDefaultFunctionGroup("MCP Example Functions", []defaultFunction{
+	fp.loadDefaultFunctionGroup("Security Utilities", []string{"security"}, []defaultFunction{
+		{"hash_text", "Hash text with md5, sha1 or sha256", false},
+		{"base64_encode", "Encode text as base64 or base64url", false},
+		{"base64_decode", "Decode base64 or base64url", false},
+		{"url_encode", "Percent-encode text for a URL", false},
+		{"url_decode", "Decode percent-encoded text", false},
+		{"jwt_decode", "Decode a JWT without verifying it", false},
+		{"cidr_info", "Calculate an IPv4 subnet", false},
+		{"epoch_convert", "Convert between epoch time and dates", false},
+	})
+
+	fp.loadDefaultFunctionGroup("Scan Data", []string{"security", "scan"}, []defaultFunction{
+		{"scan_list", "List ingested Nmap and masscan scans", false},
+		{"scan_summary", "Count the hosts and open ports of a scan", false},
+		{"scan_find", "Find hosts by port, service, product or network", false},
+		{"scan_host", "Show the ports and OS guess of one host", false},
+		{"capture_list", "List ingested packet captures", false},
+		{"capture_summary", "Show the flows, DNS lookups and HTTP requests of a capture", false},
+	})
+
+	fp.loadDefaultFunctionGroup("CVE Lookup", []string{"security", "vulnerability"}, []defaultFunction{
+		{"cve_lookup", "CVSS scores and references of a CVE from the NVD", false},
+	})
+
+	fp.loadDefaultFunctionGroup("OSINT Lookups", []string{"security", "osint"}, []defaultFunction{
+		{"breach_check", "Breaches an account appears in (Have I Been Pwned)", false},
+		{"pwned_password", "How often a password appears in breaches", false},
+		{"crtsh_lookup", "Certificates logged for a domain (crt.sh)", false},
+	})
+
+	fp.loadDefaultFunctionGroup("URL Reputation", []string{"security", "osint"}, []defaultFunction{
+		{"url_reputation", "Verdict on a URL, domain or IP (VirusTotal, urlscan.io)", false},
+	})
+
+	fp.loadDefaultFunctionGroup("MCP Example Functions", []string{"mcp", "example"}, []defaultFunction{
 		{"mcpListTools", "List available MCP tools", false},
 		{"mcpCallTool", "Call an MCP tool", false},
 		{"mcpGetStatus", "Get MCP server status", false},
 	})
 
-	// Load custom functions (mock data for read-only view)
-	// In a real implementation, this would load from actual config
-	hasCustomFunctions := false
+	hasCustomFunctions := fp.loadCustomFunctions()
+
+	if fp.tagFilter != "" && len(fp.defaultFunctions.GetItems()) == 0 {
+		fp.defaultFunctions.AddItem(components.ExpandableItem{
+			Text:  fmt.Sprintf("(No functions tagged #%s)", fp.tagFilter),
+			Style: tcell.StyleDefault.Foreground(tcell.ColorGray).Italic(true),
+		})
+	}
 
 	if !hasCustomFunctions {
 		// Show placeholder if no custom functions
@@ -112,6 +174,142 @@ func (fp *FunctionsPage) loadFunctions() {
 	fp.updateTokenUsage()
 }
 
+// SetFunctionsDir lists the functions of the functions directory with the
+// custom functions
+func (fp *FunctionsPage) SetFunctionsDir(w *funcdir.Watcher) {
+	fp.functionsDir = w
+	fp.loadFunctions()
+}
+
+// customFunctionList returns the functions of the CLI configuration, then
+// those of the functions directory
+func (fp *FunctionsPage) customFunctionList() []share.Function {
+	var functions []share.Function
+	if source := fp.config.Get().ShareSource; source != nil {
+		functions = append(functions, source.Functions...)
+	}
+	if fp.functionsDir != nil {
+		functions = append(functions, fp.functionsDir.Functions()...)
+	}
+	return functions
+}
+
+// loadCustomFunctions lists the custom functions with their test results; it
+// reports whether any is listed
+func (fp *FunctionsPage) loadCustomFunctions() bool {
+	listed := false
+	for _, fn := range fp.customFunctionList() {
+		fp.availableTags = tags.Collect(fp.availableTags, fn.Tags)
+		if !tags.Match(fn.Tags, []string{fp.tagFilter}) {
+			continue
+		}
+		listed = true
+
+		item := components.ExpandableItem{
+			Text:       fn.Name,
+			Indented:   true,
+			Style:      tcell.StyleDefault,
+			IsCheckbox: true,
+			IsChecked:  fn.Enabled,
+		}
+		report, tested := fp.testReports[fn.Name]
+		switch {
+		case fp.testing:
+			item.Status, item.StatusStyle = "testing…", tcell.StyleDefault.Foreground(tcell.ColorGray)
+		case !tested || len(report.Results) == 0 && report.Error == "":
+			item.Status, item.StatusStyle = "no tests", tcell.StyleDefault.Foreground(tcell.ColorGray)
+		case report.OK():
+			item.Status, item.StatusStyle = "✓ "+report.Status(), tcell.StyleDefault.Foreground(tcell.ColorGreen)
+		default:
+			item.Status, item.StatusStyle = "✗ "+report.Status(), tcell.StyleDefault.Foreground(tcell.ColorRed)
+		}
+		fp.customFunctions.AddItem(item)
+
+		if fn.Description != "" {
+			fp.customFunctions.AddItem(components.ExpandableItem{
+				Text:     "  " + fn.Description,
+				Indented: true,
+				Style:    tcell.StyleDefault.Foreground(tcell.ColorGray),
+			})
+		}
+		if problem := firstProblem(report); tested && !fp.testing && problem != "" {
+			fp.customFunctions.AddItem(components.ExpandableItem{
+				Text:     "  " + problem,
+				Indented: true,
+				Style:    tcell.StyleDefault.Foreground(tcell.ColorRed),
+			})
+		}
+	}
+
+	// Files of the functions directory that don't load
+	if fp.functionsDir == nil || fp.tagFilter != "" {
+		return listed
+	}
+	for _, e := range fp.functionsDir.Errors() {
+		listed = true
+		fp.customFunctions.AddItem(components.ExpandableItem{
+			Text:        e.File,
+			Indented:    true,
+			Style:       tcell.StyleDefault.Foreground(tcell.ColorRed),
+			Status:      "✗ doesn't load",
+			StatusStyle: tcell.StyleDefault.Foreground(tcell.ColorRed),
+		})
+		fp.customFunctions.AddItem(components.ExpandableItem{
+			Text:     "  " + strings.ReplaceAll(e.Error, "\n", " "),
+			Indented: true,
+			Style:    tcell.StyleDefault.Foreground(tcell.ColorRed),
+		})
+	}
+	return listed
+}
+
+// firstProblem describes why a function's tests failed
+func firstProblem(report functest.Report) string {
+	if report.Error != "" {
+		return report.Error
+	}
+	for _, result := range report.Results {
+		if !result.Passed {
+			return fmt.Sprintf("%s: %s, got %s", result.Name, result.Problem, result.Got)
+		}
+	}
+	return ""
+}
+
+// RunTests runs the custom functions' test cases in the background; the
+// reports arrive as core.EventFunctionTestsRun
+func (fp *FunctionsPage) RunTests() {
+	functions := fp.customFunctionList()
+	if fp.testing || len(functions) == 0 {
+		return
+	}
+	fp.testing = true
+	fp.loadFunctions()
+	go func() {
+		fp.eventBus.PublishAsync(core.EventFunctionTestsRun, functest.RunAll(functions))
+	}()
+}
+
+// HandleAsyncEvent shows the reports of a test run, and tests the functions
+// again when the functions directory changed
+func (fp *FunctionsPage) HandleAsyncEvent(e core.Event) {
+	if e.Type == core.EventFunctionsReloaded {
+		fp.loadFunctions()
+		fp.RunTests()
+		return
+	}
+	reports, ok := e.Data.([]functest.Report)
+	if e.Type != core.EventFunctionTestsRun || !ok {
+		return
+	}
+	fp.testing = false
+	fp.testReports = make(map[string]functest.Report, len(reports))
+	for _, report := range reports {
+		fp.testReports[report.Function] = report
+	}
+	fp.loadFunctions()
+}
+
 // defaultFunction represents a default function entry
 type defaultFunction struct {
 	name        string
@@ -119,17 +317,27 @@ type defaultFunction struct {
 	enabled     bool
 }
 
-// loadDefaultFunctionGroup loads a group of default functions
-func (fp *FunctionsPage) loadDefaultFunctionGroup(groupName string, functions []defaultFunction) {
+// loadDefaultFunctionGroup loads a group of default functions; the group's
+// tags apply to each of its functions and are matched against the tag filter
+func (fp *FunctionsPage) loadDefaultFunctionGroup(groupName string, groupTags []string, functions []defaultFunction) {
+	fp.availableTags = tags.Collect(fp.availableTags, groupTags)
+	if !tags.Match(groupTags, []string{fp.tagFilter}) {
+		return
+	}
+
 	// Add group header
 	groupItem := components.ExpandableItem{
-		Text:     groupName + ":",
+		Text:     groupName + ":  " + tags.String(groupTags),
 		Indented: false,
 		Style:    tcell.StyleDefault.Bold(true),
 	}
 	fp.defaultFunctions.AddItem(groupItem)
 
-	// Add functions
+	// Add functions, favorites first
+	cfg := fp.config.Get()
+	sort.SliceStable(functions, func(i, j int) bool {
+		return cfg.IsFavorite(core.FavoriteFunction, functions[i].name) && !cfg.IsFavorite(core.FavoriteFunction, functions[j].name)
+	})
 	for _, fn := range functions {
 		item := components.ExpandableItem{
 			Text:       fn.name,
@@ -137,6 +345,7 @@ func (fp *FunctionsPage) loadDefaultFunctionGroup(groupName string, functions []
 			Style:      tcell.StyleDefault,
 			IsCheckbox: true,
 			IsChecked:  fn.enabled,
+			IsFavorite: cfg.IsFavorite(core.FavoriteFunction, fn.name),
 		}
 		fp.defaultFunctions.AddItem(item)
 
@@ -152,6 +361,53 @@ func (fp *FunctionsPage) loadDefaultFunctionGroup(groupName string, functions []
 	}
 }
 
+// FunctionNames returns the names of the listed functions
+func (fp *FunctionsPage) FunctionNames() []string {
+	var names []string
+	for _, item := range fp.defaultFunctions.GetItems() {
+		if item.IsCheckbox {
+			names = append(names, item.Text)
+		}
+	}
+	return names
+}
+
+// SelectFunction clears the tag filter and selects the named function
+func (fp *FunctionsPage) SelectFunction(name string) bool {
+	if fp.tagFilter != "" {
+		fp.tagFilter = ""
+		fp.loadFunctions()
+	}
+	for i, item := range fp.defaultFunctions.GetItems() {
+		if item.IsCheckbox && item.Text == name {
+			fp.defaultFunctions.SetExpanded(true)
+			fp.selectedGroup, fp.selectedItemIndex = 0, i
+			fp.handleScrollForSelection()
+			return true
+		}
+	}
+	return false
+}
+
+// toggleFavorite pins or unpins the selected function, keeping it selected as it moves
+func (fp *FunctionsPage) toggleFavorite() {
+	items := fp.defaultFunctions.GetItems()
+	if fp.selectedGroup != 0 || fp.selectedItemIndex < 0 || fp.selectedItemIndex >= len(items) || !items[fp.selectedItemIndex].IsCheckbox {
+		return
+	}
+	name := items[fp.selectedItemIndex].Text
+	fp.config.Update(func(cfg *core.Config) {
+		cfg.ToggleFavorite(core.FavoriteFunction, name)
+	})
+	fp.loadFunctions()
+	for i, item := range fp.defaultFunctions.GetItems() {
+		if item.IsCheckbox && item.Text == name {
+			fp.selectedItemIndex = i
+			break
+		}
+	}
+}
+
 // updateTokenUsage calculates and updates token usage display
 func (fp *FunctionsPage) updateTokenUsage() {
 	// Mock token calculation for read-only view
@@ -178,6 +434,10 @@ func (fp *FunctionsPage) Draw() {
 
 	// Draw header with scroll indicator
 	fp.DrawHeader()
+	if fp.tagFilter != "" {
+		filterText := fmt.Sprintf(" Tag filter: #%s ", fp.tagFilter)
+		fp.DrawText(3, 3, filterText, tcell.StyleDefault.Foreground(tcell.ColorTeal).Bold(true))
+	}
 
 	// Draw scroll position indicator and selection info
 	if fp.maxScroll > 0 || fp.scrollOffset > 0 {
@@ -246,14 +506,7 @@ func (fp *FunctionsPage) Draw() {
 				if item.Indented {
 					x += 2 // Same additional indentation as component
 				}
-				text := item.Text
-				if item.IsCheckbox {
-					checkbox := "[ ]"
-					if item.IsChecked {
-						checkbox = "[x]"
-					}
-					text = checkbox + " " + text
-				}
+				text := item.Label()
 				for i, ch := range text {
 					if i < w-x-3 {
 						fp.screen.SetContent(x+i, actualY, ch, nil, selectionStyle)
@@ -296,14 +549,7 @@ func (fp *FunctionsPage) Draw() {
 				if item.Indented {
 					x += 2 // Same additional indentation as component
 				}
-				text := item.Text
-				if item.IsCheckbox {
-					checkbox := "[ ]"
-					if item.IsChecked {
-						checkbox = "[x]"
-					}
-					text = checkbox + " " + text
-				}
+				text := item.Label()
 				for i, ch := range text {
 					if i < w-x-3 {
 						fp.screen.SetContent(x+i, actualY, ch, nil, selectionStyle)
@@ -322,10 +568,19 @@ func (fp *FunctionsPage) Draw() {
 	// Draw token usage bar
 	fp.tokenUsageBar.Draw()
 
+	// Draw the banner of a locked share link
+	fp.lock.Draw(0, 4, w)
+
 	// Draw instructions
-	instructions := " ↑↓:Navigate | Space/Enter:Expand/Collapse | I:Info | ESC:Back "
-	instructionStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
-	fp.DrawCenteredText(h-2, instructions, instructionStyle)
+	fp.DrawHint(h-2, fp.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
+
+// Keymap returns the bindings of the page
+func (fp *FunctionsPage) Keymap() *core.Keymap {
+	if keymap := fp.lock.Keymap(); keymap != nil {
+		return keymap
+	}
+	return functionsKeymap
 }
 
 // drawContentBorder draws a border around the content area
@@ -533,6 +788,9 @@ func (fp *FunctionsPage) wrapText(text string, width int) []string {
 
 // HandleInput processes keyboard input
 func (fp *FunctionsPage) HandleInput(ev *tcell.EventKey) bool {
+	if fp.lock.HandleInput(ev) {
+		return false
+	}
 	switch ev.Key() {
 	case tcell.KeyEscape:
 		// Hide info tooltip if visible, otherwise exit
@@ -641,6 +899,25 @@ func (fp *FunctionsPage) HandleInput(ev *tcell.EventKey) bool {
 			fp.infoIcon.HandleInput(ev)
 			return false
 
+		case 'f', 'F':
+			// Pin the selected function to the top of its group
+			fp.toggleFavorite()
+			return false
+
+		case 'r', 'R':
+			// Run the custom functions' test cases again
+			fp.RunTests()
+			return false
+
+		case 't', 'T':
+			// Cycle the tag filter through the tags in use, then back to all
+			fp.tagFilter = tags.Next(fp.availableTags, fp.tagFilter)
+			fp.selectedGroup = 0
+			fp.selectedItemIndex = -1
+			fp.scrollOffset = 0
+			fp.loadFunctions()
+			return false
+
 		case ' ':
 			// Space toggles expansion when on header or checkbox when on item
 			if fp.selectedItemIndex == -1 {
@@ -660,7 +937,7 @@ func (fp *FunctionsPage) HandleInput(ev *tcell.EventKey) bool {
 				}
 				if fp.selectedItemIndex < len(items) {
 					item := &items[fp.selectedItemIndex]
-					if item.IsCheckbox {
+					if item.IsCheckbox && !fp.lock.Refuse() {
 						item.IsChecked = !item.IsChecked
 						// Update the item in the group
 						if fp.selectedGroup == 0 {
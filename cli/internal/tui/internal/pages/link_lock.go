@@ -0,0 +1,130 @@
+package pages
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// linkUnlockKeymap lists the keys of the override password prompt
+var linkUnlockKeymap = core.RegisterKeymap("lock.unlock", "Unlock: entering the override password",
+	core.Bind("Enter", "Unlock"),
+	core.Bind("ESC", "Cancel"),
+).WithTextEntry()
+
+// linkLock keeps prompts, functions and provider settings read-only while the
+// configuration comes from a share link its creator locked. It draws the
+// "locked by link" banner and asks for the override password on U. In kiosk
+// mode it locks the same way, with no way to unlock.
+type linkLock struct {
+	screen    tcell.Screen
+	config    *core.ConfigManager
+	prompting bool
+	password  []rune
+	message   string // Why the last edit was refused, or that the password was wrong
+}
+
+// newLinkLock creates the lock helper of a page
+func newLinkLock(screen tcell.Screen, config *core.ConfigManager) *linkLock {
+	return &linkLock{screen: screen, config: config}
+}
+
+// Locked reports whether the configuration is read-only
+func (l *linkLock) Locked() bool {
+	return l.config.Get().ReadOnly()
+}
+
+// kiosk reports whether the lock comes from kiosk mode rather than a share link
+func (l *linkLock) kiosk() bool {
+	return l.config.Get().Kiosk
+}
+
+// Refuse reports whether an edit must be refused, noting why on the banner
+func (l *linkLock) Refuse() bool {
+	if !l.Locked() {
+		return false
+	}
+	l.message = "Editing is disabled"
+	return true
+}
+
+// HandleInput handles the password prompt and the U key, and reports whether it took the key
+func (l *linkLock) HandleInput(ev *tcell.EventKey) bool {
+	if l.prompting {
+		switch ev.Key() {
+		case tcell.KeyEnter:
+			password := string(l.password)
+			unlocked := false
+			l.config.Update(func(cfg *core.Config) {
+				unlocked = cfg.Unlock(password)
+			})
+			l.password = nil
+			if unlocked {
+				l.prompting = false
+				l.message = ""
+			} else {
+				l.message = "Wrong override password"
+			}
+		case tcell.KeyEscape:
+			l.prompting = false
+			l.password = nil
+			l.message = ""
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			if len(l.password) > 0 {
+				l.password = l.password[:len(l.password)-1]
+			}
+		case tcell.KeyRune:
+			l.password = append(l.password, ev.Rune())
+		}
+		return true
+	}
+
+	if l.Locked() && !l.kiosk() && ev.Key() == tcell.KeyRune && (ev.Rune() == 'u' || ev.Rune() == 'U') {
+		l.prompting = true
+		l.message = ""
+		return true
+	}
+	return false
+}
+
+// Keymap returns the bindings of the password prompt while it is open, or nil
+func (l *linkLock) Keymap() *core.Keymap {
+	if l.prompting {
+		return linkUnlockKeymap
+	}
+	return nil
+}
+
+// Draw draws the banner across [x, x+width) at row y while locked
+func (l *linkLock) Draw(x, y, width int) {
+	if !l.Locked() {
+		return
+	}
+
+	text := " Locked by link: prompts, functions and provider are read-only · U to unlock "
+	switch {
+	case l.kiosk():
+		text = " Kiosk mode: settings, prompts and functions are read-only "
+		if l.message != "" {
+			text = " Kiosk mode: " + l.message + " "
+		}
+	case l.prompting:
+		text = " Override password: " + strings.Repeat("•", len(l.password)) + "█ "
+		if l.message != "" {
+			text += "· " + l.message + " "
+		}
+	case l.message != "":
+		text = " Locked by link: " + l.message + " · U to unlock "
+	}
+
+	runes := []rune(text)
+	if len(runes) > width {
+		runes = runes[:width]
+	}
+	style := tcell.StyleDefault.Background(tcell.ColorDarkRed).Foreground(tcell.ColorWhite).Bold(true)
+	start := x + (width-len(runes))/2
+	for i, r := range runes {
+		l.screen.SetContent(start+i, y, r, nil, style)
+	}
+}
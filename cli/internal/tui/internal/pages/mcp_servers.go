@@ -29,6 +29,14 @@ type MCPServerInfo struct {
 	IsBuiltIn bool
 }
 
+// mcpServersKeymap lists the keys of the MCP Servers page
+var mcpServersKeymap = core.RegisterKeymap("mcp", "MCP Servers",
+	core.Bind("↑↓", "Scroll"),
+	core.Bind("Space", "Expand/Collapse"),
+	core.Bind("I", "Info"),
+	core.Bind("ESC", "Back"),
+)
+
 // NewMCPServersPage creates a new MCP servers configuration page
 func NewMCPServersPage(screen tcell.Screen, config *core.ConfigManager, state *core.AppState, eventBus *core.EventBus) *MCPServersPage {
 	page := &MCPServersPage{
@@ -118,6 +126,15 @@ func (mp *MCPServersPage) loadMCPServers() {
 	}
 }
 
+// ConnectedServers returns the names of the connected servers
+func (mp *MCPServersPage) ConnectedServers() []string {
+	names := make([]string, 0, len(mp.connectedServers))
+	for _, server := range mp.connectedServers {
+		names = append(names, server.Name)
+	}
+	return names
+}
+
 // loadQuickConnector loads a quick connector configuration
 func (mp *MCPServersPage) loadQuickConnector(name, authType string, connected bool, tools []string) {
 	// Use the passed connected parameter directly
@@ -257,9 +274,12 @@ func (mp *MCPServersPage) Draw() {
 	mp.drawConnectedSummary()
 
 	// Draw instructions
-	instructions := " I:Info | Space:Expand/Collapse | ↑↓:Scroll | ESC:Back "
-	instructionStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
-	mp.DrawCenteredText(h-2, instructions, instructionStyle)
+	mp.DrawHint(h-2, mp.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
+
+// Keymap returns the bindings of the page
+func (mp *MCPServersPage) Keymap() *core.Keymap {
+	return mcpServersKeymap
 }
 
 // drawContentBorder draws a border around the content area
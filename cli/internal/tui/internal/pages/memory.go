@@ -0,0 +1,306 @@
+package pages
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/memory"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// MemoryPage lists the long-term facts of the current namespace and lets the
+// user add, edit and delete them
+type MemoryPage struct {
+	*BasePage
+	store        *memory.Store
+	facts        []memory.Fact
+	selected     int
+	scrollOffset int
+	editing      bool   // The input line is active
+	editID       string // Fact being edited, "" when adding
+	input        []rune
+	confirmClear bool
+	status       string
+}
+
+// Keymaps of the memory page
+var (
+	memoryKeymap = core.RegisterKeymap("memory", "Memory",
+		core.Bind("↑↓", "Select"),
+		core.Bind("A", "Add"),
+		core.Bind("E/Enter", "Edit"),
+		core.Bind("D/Del", "Delete"),
+		core.Bind("C", "Clear all"),
+		core.Bind("ESC", "Back"),
+	)
+	memoryEditKeymap = core.RegisterKeymap("memory.edit", "Memory: editing a fact",
+		core.Bind("Enter", "Save"),
+		core.Bind("ESC", "Cancel"),
+	).WithTextEntry()
+)
+
+// NewMemoryPage creates the memory management page
+func NewMemoryPage(screen tcell.Screen, config *core.ConfigManager, state *core.AppState, eventBus *core.EventBus) *MemoryPage {
+	page := &MemoryPage{
+		BasePage: NewBasePage(screen, config, state, eventBus, "Memory", PageTypeMemory),
+		store:    memory.NewStore(memory.DefaultPath()),
+	}
+	page.load()
+	return page
+}
+
+// namespace returns the namespace facts are stored under
+func (mp *MemoryPage) namespace() string {
+	return memory.Namespace(mp.config.Get().Namespace)
+}
+
+// load reads the facts of the current namespace
+func (mp *MemoryPage) load() {
+	facts, err := mp.store.Facts(mp.namespace())
+	if err != nil {
+		mp.status = err.Error()
+	}
+	mp.facts = facts
+	if mp.selected >= len(mp.facts) {
+		mp.selected = len(mp.facts) - 1
+	}
+	if mp.selected < 0 {
+		mp.selected = 0
+	}
+}
+
+// Draw renders the memory page
+func (mp *MemoryPage) Draw() {
+	w, h := mp.screen.Size()
+	mp.ClearContent()
+	mp.DrawHeader()
+
+	grayStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+	summary := fmt.Sprintf("Namespace: %s · %d fact(s)", mp.namespace(), len(mp.facts))
+	if mp.config.Get().DisableMemory {
+		summary += " · not added to the system prompt (disable_memory)"
+	}
+	mp.DrawText(3, 3, summary, grayStyle)
+
+	listY := 5
+	listHeight := h - listY - 5
+	if len(mp.facts) == 0 {
+		mp.DrawText(3, listY, "Nothing remembered yet. Press 'a' to add a fact, or use /remember in chat.",
+			grayStyle.Italic(true))
+	}
+
+	// Keep the selection visible
+	if mp.selected < mp.scrollOffset {
+		mp.scrollOffset = mp.selected
+	}
+	if listHeight > 0 && mp.selected >= mp.scrollOffset+listHeight {
+		mp.scrollOffset = mp.selected - listHeight + 1
+	}
+
+	for i := mp.scrollOffset; i < len(mp.facts) && i-mp.scrollOffset < listHeight; i++ {
+		fact := mp.facts[i]
+		line := fmt.Sprintf("%2d. %s", i+1, fact.Text)
+		meta := fmt.Sprintf(" (%s, %s)", fact.Source, fact.CreatedAt.Local().Format("2006-01-02"))
+		maxWidth := w - 6 - len(meta)
+		if maxWidth > 3 && len([]rune(line)) > maxWidth {
+			line = string([]rune(line)[:maxWidth-1]) + "…"
+		}
+
+		style := tcell.StyleDefault
+		if i == mp.selected {
+			style = style.Background(tcell.ColorDarkBlue).Foreground(tcell.ColorWhite)
+		}
+		y := listY + i - mp.scrollOffset
+		mp.DrawText(3, y, line, style)
+		mp.DrawText(3+len([]rune(line)), y, meta, grayStyle)
+	}
+
+	if mp.editing {
+		mp.drawInput(h - 4)
+	} else if mp.confirmClear {
+		mp.DrawText(3, h-4, fmt.Sprintf("Forget all %d fact(s) in %s? (y/N)", len(mp.facts), mp.namespace()),
+			tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true))
+	} else if mp.status != "" {
+		mp.DrawText(3, h-4, mp.status, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	}
+
+	mp.DrawHint(h-2, mp.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
+
+// Keymap returns the bindings of the current mode
+func (mp *MemoryPage) Keymap() *core.Keymap {
+	if mp.editing {
+		return memoryEditKeymap
+	}
+	return memoryKeymap
+}
+
+// drawInput draws the single-line fact editor
+func (mp *MemoryPage) drawInput(y int) {
+	w, _ := mp.screen.Size()
+	label := "New fact: "
+	if mp.editID != "" {
+		label = "Edit fact: "
+	}
+	mp.DrawText(3, y, label, tcell.StyleDefault.Bold(true))
+
+	boxX := 3 + len(label)
+	boxWidth := w - boxX - 3
+	boxStyle := tcell.StyleDefault.Background(tcell.ColorDarkBlue)
+	for i := 0; i < boxWidth; i++ {
+		mp.screen.SetContent(boxX+i, y, ' ', nil, boxStyle)
+	}
+
+	text := mp.input
+	if len(text) > boxWidth-2 {
+		text = text[len(text)-boxWidth+2:]
+	}
+	mp.DrawText(boxX+1, y, string(text), boxStyle.Foreground(tcell.ColorWhite))
+	mp.screen.SetContent(boxX+1+len(text), y, '█', nil, boxStyle.Foreground(tcell.ColorYellow))
+}
+
+// HandleInput processes keyboard input; it returns true to leave the page
+func (mp *MemoryPage) HandleInput(ev *tcell.EventKey) bool {
+	if mp.editing {
+		mp.handleEditInput(ev)
+		return false
+	}
+	if mp.confirmClear {
+		mp.confirmClear = false
+		if ev.Key() == tcell.KeyRune && (ev.Rune() == 'y' || ev.Rune() == 'Y') {
+			mp.apply(mp.store.Clear(mp.namespace()), "Memory cleared")
+		}
+		return false
+	}
+
+	mp.status = ""
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		return true
+	case tcell.KeyUp:
+		if mp.selected > 0 {
+			mp.selected--
+		}
+	case tcell.KeyDown:
+		if mp.selected < len(mp.facts)-1 {
+			mp.selected++
+		}
+	case tcell.KeyEnter:
+		mp.startEdit()
+	case tcell.KeyDelete:
+		mp.deleteSelected()
+	case tcell.KeyRune:
+		switch ev.Rune() {
+		case 'a', 'A':
+			if !mp.readOnly() {
+				mp.editing, mp.editID, mp.input = true, "", nil
+			}
+		case 'e', 'E':
+			mp.startEdit()
+		case 'd', 'D':
+			mp.deleteSelected()
+		case 'c', 'C':
+			mp.confirmClear = len(mp.facts) > 0 && !mp.readOnly()
+		}
+	}
+	return false
+}
+
+// handleEditInput edits the input line
+func (mp *MemoryPage) handleEditInput(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		mp.editing = false
+	case tcell.KeyEnter:
+		mp.editing = false
+		text := string(mp.input)
+		if mp.editID == "" {
+			_, err := mp.store.Add(mp.namespace(), text, "manual")
+			mp.apply(err, "Fact added")
+			if err == nil {
+				mp.selected = len(mp.facts) - 1
+			}
+		} else {
+			mp.apply(mp.store.Update(mp.namespace(), mp.editID, text), "Fact updated")
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(mp.input) > 0 {
+			mp.input = mp.input[:len(mp.input)-1]
+		}
+	case tcell.KeyRune:
+		if len(mp.input) < memory.MaxFactLength {
+			mp.input = append(mp.input, ev.Rune())
+		}
+	}
+}
+
+// startEdit opens the selected fact in the input line
+func (mp *MemoryPage) startEdit() {
+	if len(mp.facts) == 0 || mp.readOnly() {
+		return
+	}
+	fact := mp.facts[mp.selected]
+	mp.editing, mp.editID, mp.input = true, fact.ID, []rune(fact.Text)
+}
+
+// deleteSelected removes the selected fact
+func (mp *MemoryPage) deleteSelected() {
+	if len(mp.facts) == 0 || mp.readOnly() {
+		return
+	}
+	mp.apply(mp.store.Remove(mp.namespace(), mp.facts[mp.selected].ID), "Fact deleted")
+}
+
+// readOnly reports whether facts can't be changed, as in kiosk mode, noting why
+func (mp *MemoryPage) readOnly() bool {
+	if !mp.config.Get().Kiosk {
+		return false
+	}
+	mp.status = "Memory is read-only in kiosk mode"
+	return true
+}
+
+// apply reloads after a change and reports the outcome
+func (mp *MemoryPage) apply(err error, done string) {
+	mp.load()
+	if err != nil {
+		mp.status = "Error: " + err.Error()
+		return
+	}
+	mp.status = done
+}
+
+// OnActivate reloads facts that /remember may have added meanwhile
+func (mp *MemoryPage) OnActivate() {
+	mp.load()
+}
+
+// Save is a no-op; every change is written immediately
+func (mp *MemoryPage) Save() error {
+	return nil
+}
+
+// HandleMouse selects a fact on click and scrolls on wheel events
+func (mp *MemoryPage) HandleMouse(event *core.MouseEvent) bool {
+	switch event.Type {
+	case core.MouseEventScroll:
+		switch event.Button {
+		case core.MouseWheelUp:
+			if mp.selected > 0 {
+				mp.selected--
+			}
+		case core.MouseWheelDown:
+			if mp.selected < len(mp.facts)-1 {
+				mp.selected++
+			}
+		}
+		return true
+	case core.MouseEventClick:
+		index := event.Y - 5 + mp.scrollOffset
+		if !mp.editing && index >= 0 && index < len(mp.facts) && event.Y >= 5 {
+			mp.selected = index
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package pages
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/playground"
+	"github.com/hacka-re/cli/internal/tui/internal/components"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// PlaygroundPage runs JavaScript cells against the configured functions, with
+// the cells kept between sessions
+type PlaygroundPage struct {
+	*BasePage
+	playground   *playground.Playground
+	editor       *components.Editor
+	scrollOffset int  // Lines scrolled up from the latest cell
+	recall       int  // Index of the recalled input, len(inputs) when editing a new one
+	running      bool // A cell is running in the background
+	confirmClear bool
+	status       string
+}
+
+// editorHeight is the height of the cell editor, borders included
+const editorHeight = 7
+
+// playgroundKeymap lists the keys of the playground
+var playgroundKeymap = core.RegisterKeymap("playground", "Playground",
+	core.Bind("Ctrl+R/F5", "Run cell"),
+	core.Bind("Ctrl+↑↓", "Recall earlier cells"),
+	core.Bind("PgUp/PgDn", "Scroll output"),
+	core.Bind("Ctrl+K", "Clear history"),
+	core.Bind("ESC", "Back"),
+).WithTextEntry()
+
+// NewPlaygroundPage creates the playground page
+func NewPlaygroundPage(screen tcell.Screen, config *core.ConfigManager, state *core.AppState, eventBus *core.EventBus) *PlaygroundPage {
+	page := &PlaygroundPage{
+		BasePage: NewBasePage(screen, config, state, eventBus, "Playground", PageTypePlayground),
+		editor:   components.NewEditor(screen),
+	}
+	p, err := playground.Open(playground.DefaultPath())
+	if err != nil {
+		page.status = "Error: " + err.Error()
+	}
+	page.playground = p
+	page.loadFunctions()
+	page.recall = len(p.Cells)
+	return page
+}
+
+// loadFunctions loads the functions of the CLI configuration, also those not
+// enabled yet
+func (pp *PlaygroundPage) loadFunctions() {
+	source := pp.config.Get().ShareSource
+	if source == nil {
+		return
+	}
+	if err := pp.playground.SetFunctions(source.Functions, source.DefaultFunctions); err != nil {
+		pp.status = "Error: " + err.Error()
+	}
+}
+
+// Draw renders the cells, the editor and the hint line
+func (pp *PlaygroundPage) Draw() {
+	w, h := pp.screen.Size()
+	pp.ClearContent()
+	pp.DrawHeader()
+
+	grayStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+	pp.DrawText(3, 3, fmt.Sprintf("%d function(s) loaded · :functions lists them · :call NAME {json} calls one as the model would · $N is the result of cell N",
+		pp.playground.Registry.Size()), grayStyle)
+
+	editorY := h - editorHeight - 3
+	listY, listHeight := 5, editorY-6
+	lines, styles := pp.cellLines(w - 6)
+	if len(lines) == 0 {
+		pp.DrawText(3, listY, "Type JavaScript below and press Ctrl+R to run it. Cells are kept between sessions.", grayStyle.Italic(true))
+	}
+
+	// Show the latest lines, or earlier ones when scrolled up
+	maxScroll := len(lines) - listHeight
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	if pp.scrollOffset > maxScroll {
+		pp.scrollOffset = maxScroll
+	}
+	first := len(lines) - listHeight - pp.scrollOffset
+	if first < 0 {
+		first = 0
+	}
+	for i := first; i < len(lines) && i-first < listHeight; i++ {
+		pp.DrawText(3, listY+i-first, lines[i], styles[i])
+	}
+
+	pp.editor.SetPosition(3, editorY)
+	pp.editor.SetDimensions(w-6, editorHeight)
+	pp.editor.Draw()
+
+	if pp.confirmClear {
+		pp.DrawText(3, h-3, fmt.Sprintf("Forget all %d cell(s)? (y/N)", len(pp.playground.Cells)),
+			tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true))
+	} else if pp.status != "" {
+		pp.DrawText(3, h-3, pp.status, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	}
+	pp.DrawHint(h-2, pp.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
+
+// cellLines lays out the cells as lines of at most width runes
+func (pp *PlaygroundPage) cellLines(width int) ([]string, []tcell.Style) {
+	var lines []string
+	var styles []tcell.Style
+	add := func(prefix, text string, style tcell.Style) {
+		indent := strings.Repeat(" ", len(prefix))
+		for i, line := range strings.Split(text, "\n") {
+			if i == 0 {
+				line = prefix + line
+			} else {
+				line = indent + line
+			}
+			if width > 1 && len([]rune(line)) > width {
+				line = string([]rune(line)[:width-1]) + "…"
+			}
+			lines = append(lines, line)
+			styles = append(styles, style)
+		}
+	}
+
+	for _, cell := range pp.playground.Cells {
+		add(fmt.Sprintf("In  [%d]: ", cell.Number), cell.Input, tcell.StyleDefault.Foreground(tcell.ColorLightBlue))
+		outStyle := tcell.StyleDefault
+		if cell.Error {
+			outStyle = outStyle.Foreground(tcell.ColorRed)
+		}
+		add(fmt.Sprintf("Out [%d]: ", cell.Number), cell.Output, outStyle)
+		lines = append(lines, fmt.Sprintf("         %s", cell.Duration.Round(time.Millisecond)))
+		styles = append(styles, tcell.StyleDefault.Foreground(tcell.ColorGray))
+	}
+	return lines, styles
+}
+
+// Keymap returns the bindings of the playground
+func (pp *PlaygroundPage) Keymap() *core.Keymap {
+	return playgroundKeymap
+}
+
+// HandleInput processes keyboard input; it returns true to leave the page
+func (pp *PlaygroundPage) HandleInput(ev *tcell.EventKey) bool {
+	if pp.confirmClear {
+		pp.confirmClear = false
+		if ev.Key() == tcell.KeyRune && (ev.Rune() == 'y' || ev.Rune() == 'Y') {
+			pp.apply(pp.playground.Clear(), "History cleared")
+			pp.recall = 0
+		}
+		return false
+	}
+
+	pp.status = ""
+	ctrl := ev.Modifiers()&tcell.ModCtrl != 0
+	switch {
+	case ev.Key() == tcell.KeyEscape:
+		return true
+	case ev.Key() == tcell.KeyCtrlR || ev.Key() == tcell.KeyF5:
+		pp.run()
+	case ev.Key() == tcell.KeyCtrlK:
+		pp.confirmClear = len(pp.playground.Cells) > 0 && !pp.running
+	case ev.Key() == tcell.KeyUp && ctrl:
+		pp.recallInput(-1)
+	case ev.Key() == tcell.KeyDown && ctrl:
+		pp.recallInput(1)
+	case ev.Key() == tcell.KeyPgUp:
+		pp.scrollOffset += 10
+	case ev.Key() == tcell.KeyPgDn:
+		pp.scrollOffset -= 10
+		if pp.scrollOffset < 0 {
+			pp.scrollOffset = 0
+		}
+	default:
+		pp.editor.HandleInput(ev)
+	}
+	return false
+}
+
+// run starts the editor's text as a new cell; its result arrives as
+// core.EventCellRun
+func (pp *PlaygroundPage) run() {
+	input := pp.editor.GetText()
+	if strings.TrimSpace(input) == "" || pp.running {
+		return
+	}
+	pp.running = true
+	pp.status = "Running…"
+	go func() {
+		pp.eventBus.PublishAsync(core.EventCellRun, pp.playground.Evaluate(input))
+	}()
+}
+
+// HandleAsyncEvent keeps a cell that finished running
+func (pp *PlaygroundPage) HandleAsyncEvent(e core.Event) {
+	cell, ok := e.Data.(playground.Cell)
+	if e.Type != core.EventCellRun || !ok || !pp.running {
+		return
+	}
+	pp.running = false
+	pp.editor.SetText("")
+	pp.scrollOffset = 0
+	if err := pp.playground.Add(cell); err != nil {
+		pp.status = "Error: " + err.Error()
+	} else {
+		pp.status = fmt.Sprintf("Ran cell %d in %s", cell.Number, cell.Duration.Round(time.Millisecond))
+	}
+	pp.recall = len(pp.playground.Cells)
+}
+
+// recallInput puts an earlier cell's input in the editor
+func (pp *PlaygroundPage) recallInput(delta int) {
+	inputs := pp.playground.Inputs()
+	pp.recall += delta
+	if pp.recall < 0 {
+		pp.recall = 0
+	}
+	if pp.recall >= len(inputs) {
+		pp.recall = len(inputs)
+		pp.editor.SetText("")
+		return
+	}
+	pp.editor.SetText(inputs[pp.recall])
+}
+
+// apply reports the outcome of a change
+func (pp *PlaygroundPage) apply(err error, done string) {
+	if err != nil {
+		pp.status = "Error: " + err.Error()
+		return
+	}
+	pp.status = done
+}
+
+// OnActivate reloads the functions, which may have changed meanwhile
+func (pp *PlaygroundPage) OnActivate() {
+	if !pp.running {
+		pp.loadFunctions()
+	}
+}
+
+// Save is a no-op; cells are written as they run
+func (pp *PlaygroundPage) Save() error {
+	return nil
+}
+
+// HandleMouse scrolls the cells on wheel events
+func (pp *PlaygroundPage) HandleMouse(event *core.MouseEvent) bool {
+	if event.Type != core.MouseEventScroll {
+		return false
+	}
+	switch event.Button {
+	case core.MouseWheelUp:
+		pp.scrollOffset += 3
+	case core.MouseWheelDown:
+		pp.scrollOffset -= 3
+		if pp.scrollOffset < 0 {
+			pp.scrollOffset = 0
+		}
+	}
+	return true
+}
@@ -2,30 +2,24 @@ package pages
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/tags"
 	"github.com/hacka-re/cli/internal/tui/internal/components"
 	"github.com/hacka-re/cli/internal/tui/internal/core"
-	"github.com/hacka-re/cli/internal/tui/internal/prompts"
+	"github.com/hacka-re/cli/internal/tui/internal/services"
 )
 
 // Prompt represents a system prompt
-type Prompt struct {
-	ID          string
-	Name        string
-	Content     string
-	Description string
-	IsDefault   bool
-	IsMCP       bool // Whether this is an MCP prompt
-	IsActive    bool
-	IsEnabled   bool // Whether the prompt is enabled (checkbox)
-}
+type Prompt = services.Prompt
 
 // PromptsPage manages system prompts
 type PromptsPage struct {
 	*BasePage
+	service        *services.PromptsService
 	defaultPrompts []Prompt
 	customPrompts  []Prompt
 	mcpPrompts     []Prompt
@@ -47,6 +41,13 @@ type PromptsPage struct {
 	nameInput        string
 	editingName      bool
 	cursorPos        int
+
+	// Tag filter and tag editing in list mode
+	tagFilter        string  // Only prompts with this tag are listed ("" shows all)
+	editingTags      bool    // The tag input line is active
+	tagInput         []rune
+
+	lock *linkLock // Read-only while the share link's creator locked the configuration
 }
 
 // PromptMode represents the current view mode
@@ -59,6 +60,53 @@ const (
 	PromptModeCreate
 )
 
+// Keymaps of the prompts page, one per mode
+var (
+	promptsKeymap = core.RegisterKeymap("prompts", "System Prompts",
+		core.Bind("↑↓", "Navigate"),
+		core.Bind("Enter/1-9", "View"),
+		core.Bind("Space", "Toggle"),
+		core.Bind("N", "New"),
+		core.Bind("E", "Edit (custom prompts)"),
+		core.Bind("D/⌫", "Delete (custom prompts)"),
+		core.Bind("T", "Tag filter"),
+		core.Bind("G", "Edit tags (custom prompts)"),
+		core.Bind("F", "Favorite"),
+		core.Bind("ESC", "Back"),
+	)
+	promptsTagsKeymap = core.RegisterKeymap("prompts.tags", "System Prompts: editing tags",
+		core.Bind("Enter", "Save"),
+		core.Bind("ESC", "Cancel"),
+	).WithTextEntry()
+	promptsViewKeymap = core.RegisterKeymap("prompts.view", "System Prompts: viewing a custom prompt",
+		core.Bind("↑↓", "Scroll"),
+		core.Bind("PgUp/PgDn", "Page"),
+		core.Bind("Home/End", "Top/Bottom"),
+		core.Bind("M", "Markdown/Raw"),
+		core.Bind("E", "Edit"),
+		core.Bind("D", "Delete"),
+		core.Bind("Space", "Toggle"),
+		core.Bind("ESC", "Back"),
+	)
+	promptsViewBuiltinKeymap = core.RegisterKeymap("prompts.view.builtin", "System Prompts: viewing a built-in prompt",
+		core.Bind("↑↓", "Scroll"),
+		core.Bind("PgUp/PgDn", "Page"),
+		core.Bind("Home/End", "Top/Bottom"),
+		core.Bind("M", "Markdown/Raw"),
+		core.Bind("Space", "Toggle"),
+		core.Bind("ESC", "Back"),
+	)
+	promptsEditKeymap = core.RegisterKeymap("prompts.edit", "System Prompts: editing",
+		core.Bind("Ctrl+S", "Save"),
+		core.Bind("ESC", "Cancel"),
+	).WithTextEntry()
+	promptsCreateKeymap = core.RegisterKeymap("prompts.create", "System Prompts: new prompt",
+		core.Bind("Tab", "Name/Content"),
+		core.Bind("Ctrl+S", "Save"),
+		core.Bind("ESC", "Cancel"),
+	).WithTextEntry()
+)
+
 // PromptMenuItem implements MenuItem for prompts
 type PromptMenuItem struct {
 	prompt *Prompt
@@ -108,6 +156,7 @@ func (p *PromptMenuItem) getContentPreview() string {
 func NewPromptsPage(screen tcell.Screen, config *core.ConfigManager, state *core.AppState, eventBus *core.EventBus) *PromptsPage {
 	page := &PromptsPage{
 		BasePage:      NewBasePage(screen, config, state, eventBus, "System Prompts", PageTypePrompts),
+		service:       services.NewPromptsService(config),
 		currentMode:   PromptModeList,
 		defaultPrompts: []Prompt{},
 		customPrompts:  []Prompt{},
@@ -115,6 +164,7 @@ func NewPromptsPage(screen tcell.Screen, config *core.ConfigManager, state *core
 		mcpConnected:   false,
 		showMarkdown:   true,  // Default to markdown view
 		viewScrollOffset: 0,
+		lock:          newLinkLock(screen, config),
 	}
 
 	// Initialize components
@@ -129,69 +179,47 @@ func NewPromptsPage(screen tcell.Screen, config *core.ConfigManager, state *core
 
 	// Load prompts
 	page.loadPrompts()
+	page.restoreRecoveredDrafts()
 
 	return page
 }
 
-// loadPrompts loads available prompts
-func (p *PromptsPage) loadPrompts() {
-	cfg := p.config.Get()
-	enabledMap := make(map[string]bool)
-	for _, id := range cfg.EnabledPrompts {
-		enabledMap[id] = true
-	}
-
-	// Load default prompts from the prompts package
-	defaultPrompts := prompts.GetDefaultPrompts()
-	p.defaultPrompts = make([]Prompt, 0, len(defaultPrompts))
-	for _, dp := range defaultPrompts {
-		p.defaultPrompts = append(p.defaultPrompts, Prompt{
-			ID:          dp.ID,
-			Name:        dp.Name,
-			Content:     dp.Content,
-			Description: dp.Description,
-			IsDefault:   true,
-			IsMCP:       false,
-			IsActive:    false,
-			IsEnabled:   enabledMap[dp.ID], // Restore enabled state from config
-		})
+// promptBufferPrefix marks prompt editor buffers kept for crash recovery
+const promptBufferPrefix = "prompt:"
+
+// restoreRecoveredDrafts adds prompt drafts recovered after a crash as disabled custom prompts
+func (p *PromptsPage) restoreRecoveredDrafts() {
+	drafts := p.state.TakeEditorBuffers(promptBufferPrefix)
+	if len(drafts) == 0 {
+		return
 	}
 
-	// Load MCP prompts if MCP is connected
-	if p.mcpConnected {
-		mcpPrompts := prompts.GetMCPPrompts()
-		p.mcpPrompts = make([]Prompt, 0, len(mcpPrompts))
-		for _, mp := range mcpPrompts {
-			p.mcpPrompts = append(p.mcpPrompts, Prompt{
-				ID:          mp.ID,
-				Name:        mp.Name,
-				Content:     mp.Content,
-				Description: mp.Description,
-				IsDefault:   false,
-				IsMCP:       true,
-				IsActive:    false,
-				IsEnabled:   enabledMap[mp.ID], // Restore enabled state from config
-			})
-		}
-	} else {
-		p.mcpPrompts = []Prompt{}
-	}
-
-	// Load custom prompts from config (user-created)
-	p.customPrompts = make([]Prompt, 0, len(cfg.CustomPrompts))
-	for _, cp := range cfg.CustomPrompts {
-		p.customPrompts = append(p.customPrompts, Prompt{
-			ID:          cp.ID,
-			Name:        cp.Name,
-			Content:     cp.Content,
-			Description: "", // No description for custom prompts
-			IsDefault:   false,
-			IsMCP:       false,
-			IsActive:    false,
-			IsEnabled:   enabledMap[cp.ID], // Restore enabled state from config
+	for name, content := range drafts {
+		p.service.SaveCustom(Prompt{
+			ID:        fmt.Sprintf("custom-%d-%d", len(p.customPrompts), time.Now().UnixNano()),
+			Name:      fmt.Sprintf("%s (recovered)", name),
+			Content:   content,
+			IsEnabled: false, // Let the user review before it reaches the system prompt
 		})
 	}
 
+	p.refreshPrompts()
+}
+
+// loadPrompts reloads prompts from the config
+func (p *PromptsPage) loadPrompts() {
+	p.service.SetMCPConnected(p.mcpConnected)
+	p.service.Load()
+	p.refreshPrompts()
+}
+
+// refreshPrompts copies the service's prompt lists for display, keeping only
+// prompts with the selected tag and listing favorites first in each section
+func (p *PromptsPage) refreshPrompts() {
+	p.defaultPrompts = p.favoritesFirst(p.filterByTag(p.service.Defaults()))
+	p.customPrompts = p.favoritesFirst(p.filterByTag(p.service.Custom()))
+	p.mcpPrompts = p.favoritesFirst(p.filterByTag(p.service.MCP()))
+
 	// Update menu items
 	p.updateMenuItems()
 }
@@ -205,13 +233,49 @@ func (p *PromptsPage) updateMenuItems() {
 	}
 }
 
-// getAllPrompts returns all prompts in order
+// filterByTag returns the prompts that carry the tag filter
+func (p *PromptsPage) filterByTag(list []Prompt) []Prompt {
+	if p.tagFilter == "" {
+		return list
+	}
+	filtered := []Prompt{}
+	for _, prompt := range list {
+		if tags.Match(prompt.Tags, []string{p.tagFilter}) {
+			filtered = append(filtered, prompt)
+		}
+	}
+	return filtered
+}
+
+// favoritesFirst moves the favorite prompts to the top, keeping the order otherwise
+func (p *PromptsPage) favoritesFirst(list []Prompt) []Prompt {
+	cfg := p.config.Get()
+	sort.SliceStable(list, func(i, j int) bool {
+		return cfg.IsFavorite(core.FavoritePrompt, list[i].ID) && !cfg.IsFavorite(core.FavoritePrompt, list[j].ID)
+	})
+	return list
+}
+
+// toggleFavorite pins or unpins a prompt, keeping it selected as it moves
+func (p *PromptsPage) toggleFavorite(prompt *Prompt) {
+	id := prompt.ID
+	p.config.Update(func(cfg *core.Config) {
+		cfg.ToggleFavorite(core.FavoritePrompt, id)
+	})
+	p.refreshPrompts()
+	for i, listed := range p.getAllPrompts() {
+		if listed.ID == id {
+			p.selectedPromptIndex = i
+			break
+		}
+	}
+}
+
+// getAllPrompts returns the listed prompts in display order
 func (p *PromptsPage) getAllPrompts() []Prompt {
-	var allPrompts []Prompt
-	allPrompts = append(allPrompts, p.defaultPrompts...)
-	allPrompts = append(allPrompts, p.customPrompts...)
-	allPrompts = append(allPrompts, p.mcpPrompts...)
-	return allPrompts
+	all := append([]Prompt{}, p.defaultPrompts...)
+	all = append(all, p.customPrompts...)
+	return append(all, p.mcpPrompts...)
 }
 
 // getPromptAtIndex returns the prompt at the given index
@@ -223,6 +287,24 @@ func (p *PromptsPage) getPromptAtIndex(index int) *Prompt {
 	return nil
 }
 
+// OpenPrompt shows the prompt with the given ID in view mode, clearing the
+// tag filter if it hides the prompt
+func (p *PromptsPage) OpenPrompt(id string) bool {
+	p.tagFilter = ""
+	p.refreshPrompts()
+	for i, prompt := range p.getAllPrompts() {
+		if prompt.ID == id {
+			p.selectedPromptIndex = i
+			p.selectedPrompt = p.getPromptAtIndex(i)
+			p.editor.SetText(p.selectedPrompt.Content)
+			p.viewScrollOffset = 0
+			p.currentMode = PromptModeView
+			return true
+		}
+	}
+	return false
+}
+
 // Draw renders the prompts page
 func (p *PromptsPage) Draw() {
 	switch p.currentMode {
@@ -235,41 +317,14 @@ func (p *PromptsPage) Draw() {
 	case PromptModeCreate:
 		p.drawCreateMode()
 	}
-}
 
-// estimateTokens provides a rough estimation of token count
-// Generally, 1 token ~= 4 characters in English text
-func (p *PromptsPage) estimateTokens(text string) int {
-	// Simple estimation: ~4 characters per token
-	return len(text) / 4
+	w, _ := p.screen.Size()
+	p.lock.Draw(0, 2, w)
 }
 
 // getTotalTokenCount returns estimated token count for all enabled prompts
 func (p *PromptsPage) getTotalTokenCount() int {
-	totalTokens := 0
-
-	// Count enabled default prompts
-	for _, prompt := range p.defaultPrompts {
-		if prompt.IsEnabled {
-			totalTokens += p.estimateTokens(prompt.Content)
-		}
-	}
-
-	// Count enabled custom prompts
-	for _, prompt := range p.customPrompts {
-		if prompt.IsEnabled {
-			totalTokens += p.estimateTokens(prompt.Content)
-		}
-	}
-
-	// Count enabled MCP prompts
-	for _, prompt := range p.mcpPrompts {
-		if prompt.IsEnabled {
-			totalTokens += p.estimateTokens(prompt.Content)
-		}
-	}
-
-	return totalTokens
+	return p.service.TokenCount()
 }
 
 // drawTokenBar draws a visual token counter bar
@@ -340,6 +395,9 @@ func (p *PromptsPage) drawListMode() {
 
 	// Draw title
 	title := " System Prompts "
+	if p.tagFilter != "" {
+		title = fmt.Sprintf(" System Prompts #%s ", p.tagFilter)
+	}
 	titleX := listX + (listWidth-len(title))/2
 	p.DrawText(titleX, listY, title, tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true))
 
@@ -437,14 +495,28 @@ func (p *PromptsPage) drawListMode() {
 	}
 
 	// Draw instructions at the bottom
-	instructions := " ↑↓:Navigate | Enter:View | Space:Toggle | N:New | D/Backspace:Delete | ESC:Back "
-	instructionsX := listX + (listWidth-len(instructions))/2
-	if instructionsX < listX + 2 {
-		// If instructions are too long, use shorter version
-		instructions = " ↑↓ Enter Space N D/⌫ ESC "
-		instructionsX = listX + (listWidth-len(instructions))/2
-	}
-	p.DrawText(instructionsX, listY+listHeight-1, instructions, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	p.DrawHintIn(listX+1, listY+listHeight-1, listWidth-2, p.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+
+	if p.editingTags {
+		p.drawTagInput(listX+2, listY+listHeight-2, listWidth-4)
+	}
+}
+
+// drawTagInput draws the tag editor line for the selected custom prompt
+func (p *PromptsPage) drawTagInput(x, y, width int) {
+	label := "Tags: "
+	p.DrawText(x, y, label, tcell.StyleDefault.Bold(true))
+	boxStyle := tcell.StyleDefault.Background(tcell.ColorDarkBlue).Foreground(tcell.ColorWhite)
+	boxWidth := width - len(label)
+	for i := 0; i < boxWidth; i++ {
+		p.screen.SetContent(x+len(label)+i, y, ' ', nil, boxStyle)
+	}
+	text := p.tagInput
+	if len(text) > boxWidth-2 {
+		text = text[len(text)-boxWidth+2:]
+	}
+	p.DrawText(x+len(label)+1, y, string(text), boxStyle)
+	p.screen.SetContent(x+len(label)+1+len(text), y, '█', nil, boxStyle.Foreground(tcell.ColorYellow))
 }
 
 // drawListBorder draws the border for the prompt list
@@ -499,19 +571,38 @@ func (p *PromptsPage) drawPromptItem(x, y, width, index int, prompt *Prompt, sel
 	}
 	p.DrawText(x, y, checkbox, style)
 
-	// Draw number and name
-	text := fmt.Sprintf(" %d. %s", index+1, prompt.Name)
+	// Draw number and name, marking favorites
+	name := prompt.Name
+	if p.config.Get().IsFavorite(core.FavoritePrompt, prompt.ID) {
+		name = "♥ " + name
+	}
+	text := fmt.Sprintf(" %d. %s", index+1, name)
 	if len(text) > width-3 {
 		text = text[:width-6] + "..."
 	}
 	p.DrawText(x+2, y, text, style)
 
 	// Add type indicator
+	indicatorWidth := 0
 	if prompt.IsDefault {
+		indicatorWidth = 10
 		p.DrawText(x+width-10, y, "[default]", style)
 	} else if prompt.IsMCP {
+		indicatorWidth = 6
 		p.DrawText(x+width-6, y, "[mcp]", style)
 	}
+
+	// Show tags between the name and the type indicator when they fit
+	if tagText := tags.String(prompt.Tags); tagText != "" {
+		tagX := x + 2 + len([]rune(text)) + 2
+		if tagX+len(tagText) < x+width-indicatorWidth {
+			tagStyle := style.Foreground(tcell.ColorGray)
+			if selected {
+				tagStyle = style.Foreground(tcell.ColorLightGray)
+			}
+			p.DrawText(tagX, y, tagText, tagStyle)
+		}
+	}
 }
 
 // drawViewMode renders the prompt viewer
@@ -567,19 +658,29 @@ func (p *PromptsPage) drawViewMode() {
 	}
 
 	// Draw instructions based on prompt type
-	var instructions string
-	if p.selectedPrompt.IsDefault || p.selectedPrompt.IsMCP {
-		instructions = " M:Toggle View | Space:Toggle | ↑↓:Scroll | ESC:Back "
-	} else {
-		instructions = " M:Toggle View | E:Edit | D:Delete | Space:Toggle | ↑↓:Scroll | ESC:Back "
+	p.DrawHintIn(modalX+1, modalY+modalHeight-2, modalWidth-2, p.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
+
+// Keymap returns the bindings of the current mode
+func (p *PromptsPage) Keymap() *core.Keymap {
+	if keymap := p.lock.Keymap(); keymap != nil {
+		return keymap
+	}
+	switch p.currentMode {
+	case PromptModeView:
+		if p.selectedPrompt != nil && (p.selectedPrompt.IsDefault || p.selectedPrompt.IsMCP) {
+			return promptsViewBuiltinKeymap
+		}
+		return promptsViewKeymap
+	case PromptModeEdit:
+		return promptsEditKeymap
+	case PromptModeCreate:
+		return promptsCreateKeymap
 	}
-	instructionsX := modalX + (modalWidth-len(instructions))/2
-	if instructionsX < modalX + 1 {
-		// Shorter version if too long
-		instructions = " M:View E:Edit D:Del Space ↑↓ ESC "
-		instructionsX = modalX + (modalWidth-len(instructions))/2
+	if p.editingTags {
+		return promptsTagsKeymap
 	}
-	p.DrawText(instructionsX, modalY+modalHeight-2, instructions, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	return promptsKeymap
 }
 
 // drawModalBorder draws a border for the view modal
@@ -711,8 +812,7 @@ func (p *PromptsPage) drawEditMode() {
 	p.editor.Draw()
 
 	// Draw instructions
-	instructions := " Ctrl-S:Save | ESC:Cancel "
-	p.DrawCenteredText(h-2, instructions, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	p.DrawHint(h-2, p.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
 
 	// Show if dirty
 	if p.IsDirty() {
@@ -776,12 +876,14 @@ func (p *PromptsPage) drawCreateMode() {
 	p.editor.Draw()
 
 	// Draw instructions
-	instructions := " Tab:Toggle Name/Content | Ctrl-S:Save | ESC:Cancel "
-	p.DrawCenteredText(h-2, instructions, tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	p.DrawHint(h-2, p.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
 }
 
 // HandleInput processes keyboard input
 func (p *PromptsPage) HandleInput(ev *tcell.EventKey) bool {
+	if (p.currentMode == PromptModeList || p.currentMode == PromptModeView) && p.lock.HandleInput(ev) {
+		return false
+	}
 	switch p.currentMode {
 	case PromptModeList:
 		return p.handleListInput(ev)
@@ -988,8 +1090,40 @@ func (p *PromptsPage) getPromptIndexAtDisplayRow(displayRow int) int {
 	return -1
 }
 
+// handleTagInput edits the tags of the selected custom prompt
+func (p *PromptsPage) handleTagInput(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		p.editingTags = false
+	case tcell.KeyEnter:
+		p.editingTags = false
+		if prompt := p.getPromptAtIndex(p.selectedPromptIndex); prompt != nil {
+			p.service.SetTags(prompt.ID, tags.Parse(string(p.tagInput)))
+			p.refreshPrompts()
+		}
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(p.tagInput) > 0 {
+			p.tagInput = p.tagInput[:len(p.tagInput)-1]
+		}
+	case tcell.KeyRune:
+		p.tagInput = append(p.tagInput, ev.Rune())
+	}
+}
+
+// cycleTagFilter steps the tag filter through all tags in use, then back to showing everything
+func (p *PromptsPage) cycleTagFilter() {
+	p.tagFilter = tags.Next(p.service.Tags(), p.tagFilter)
+	p.selectedPromptIndex = 0
+	p.scrollOffset = 0
+	p.refreshPrompts()
+}
+
 // handleListInput handles input in list mode
 func (p *PromptsPage) handleListInput(ev *tcell.EventKey) bool {
+	if p.editingTags {
+		p.handleTagInput(ev)
+		return false
+	}
 	totalPrompts := len(p.defaultPrompts) + len(p.customPrompts) + len(p.mcpPrompts)
 
 	switch ev.Key() {
@@ -1020,7 +1154,7 @@ func (p *PromptsPage) handleListInput(ev *tcell.EventKey) bool {
 	case tcell.KeyBackspace, tcell.KeyBackspace2:
 		// Delete selected prompt (only custom, non-MCP prompts)
 		prompt := p.getPromptAtIndex(p.selectedPromptIndex)
-		if prompt != nil && !prompt.IsDefault && !prompt.IsMCP {
+		if prompt != nil && !prompt.IsDefault && !prompt.IsMCP && !p.lock.Refuse() {
 			p.deletePrompt(prompt)
 			// Adjust selection after deletion
 			if p.selectedPromptIndex >= totalPrompts - 1 && p.selectedPromptIndex > 0 {
@@ -1052,10 +1186,31 @@ func (p *PromptsPage) handleListInput(ev *tcell.EventKey) bool {
 			p.startCreate()
 			return false
 
+		case 't', 'T':
+			// Filter the list by tag
+			p.cycleTagFilter()
+			return false
+
+		case 'f', 'F':
+			// Pin the selected prompt to the top of its section
+			if prompt := p.getPromptAtIndex(p.selectedPromptIndex); prompt != nil {
+				p.toggleFavorite(prompt)
+			}
+			return false
+
+		case 'g', 'G':
+			// Edit the tags of the selected prompt (only custom prompts)
+			prompt := p.getPromptAtIndex(p.selectedPromptIndex)
+			if prompt != nil && !prompt.IsDefault && !prompt.IsMCP && !p.lock.Refuse() {
+				p.tagInput = []rune(strings.Join(prompt.Tags, ", "))
+				p.editingTags = true
+			}
+			return false
+
 		case 'd', 'D':
 			// Delete selected prompt (only custom, non-MCP prompts)
 			prompt := p.getPromptAtIndex(p.selectedPromptIndex)
-			if prompt != nil && !prompt.IsDefault && !prompt.IsMCP {
+			if prompt != nil && !prompt.IsDefault && !prompt.IsMCP && !p.lock.Refuse() {
 				p.deletePrompt(prompt)
 				// Adjust selection after deletion
 				if p.selectedPromptIndex >= totalPrompts - 1 && p.selectedPromptIndex > 0 {
@@ -1151,7 +1306,7 @@ func (p *PromptsPage) handleViewInput(ev *tcell.EventKey) bool {
 			return false
 
 		case 'd', 'D':
-			if !p.selectedPrompt.IsDefault && !p.selectedPrompt.IsMCP {
+			if !p.selectedPrompt.IsDefault && !p.selectedPrompt.IsMCP && !p.lock.Refuse() {
 				p.deletePrompt(p.selectedPrompt)
 				p.currentMode = PromptModeList
 				p.viewScrollOffset = 0
@@ -1216,12 +1371,14 @@ func (p *PromptsPage) handleEditInput(ev *tcell.EventKey) bool {
 	case tcell.KeyEscape:
 		// Cancel editing
 		p.SetDirty(false)
+		p.state.TakeEditorBuffers(promptBufferPrefix)
 		p.currentMode = PromptModeList
 		return false
 
 	case tcell.KeyCtrlS:
 		// Save changes
 		p.savePrompt()
+		p.state.TakeEditorBuffers(promptBufferPrefix)
 		return false
 
 	default:
@@ -1229,6 +1386,10 @@ func (p *PromptsPage) handleEditInput(ev *tcell.EventKey) bool {
 		if !p.editingName {
 			if p.editor.HandleInput(ev) {
 				p.SetDirty(true)
+
+				// Keep the draft for crash recovery
+				p.state.TakeEditorBuffers(promptBufferPrefix)
+				p.state.SetEditorBuffer(promptBufferPrefix+p.editingDraftName(), p.editor.GetText())
 			}
 		}
 	}
@@ -1236,8 +1397,22 @@ func (p *PromptsPage) handleEditInput(ev *tcell.EventKey) bool {
 	return false
 }
 
+// editingDraftName returns the name of the prompt being edited or created
+func (p *PromptsPage) editingDraftName() string {
+	if p.currentMode == PromptModeCreate {
+		return p.nameInput
+	}
+	if p.editingPrompt != nil {
+		return p.editingPrompt.Name
+	}
+	return "Untitled"
+}
+
 // startEdit starts editing a prompt
 func (p *PromptsPage) startEdit(prompt *Prompt) {
+	if p.lock.Refuse() {
+		return
+	}
 	p.editingPrompt = &Prompt{
 		ID:          prompt.ID,
 		Name:        prompt.Name,
@@ -1245,6 +1420,7 @@ func (p *PromptsPage) startEdit(prompt *Prompt) {
 		Description: prompt.Description,
 		IsDefault:   prompt.IsDefault,
 		IsActive:    prompt.IsActive,
+		Tags:        prompt.Tags,
 	}
 
 	// If editing a default prompt, create a copy
@@ -1260,6 +1436,9 @@ func (p *PromptsPage) startEdit(prompt *Prompt) {
 
 // startCreate starts creating a new prompt
 func (p *PromptsPage) startCreate() {
+	if p.lock.Refuse() {
+		return
+	}
 	p.editingPrompt = &Prompt{
 		ID:          fmt.Sprintf("custom-%d-%d", len(p.customPrompts), time.Now().Unix()),
 		Name:        "New Prompt",
@@ -1269,6 +1448,10 @@ func (p *PromptsPage) startCreate() {
 		IsMCP:       false,
 		IsEnabled:   true, // Enable by default so it's added to system prompt
 	}
+	if p.tagFilter != "" {
+		// Keep the new prompt visible under the active filter
+		p.editingPrompt.Tags = []string{p.tagFilter}
+	}
 
 	// Initialize input fields
 	p.nameInput = "New Prompt"
@@ -1291,50 +1474,14 @@ func (p *PromptsPage) savePrompt() {
 	}
 	p.editingPrompt.Content = p.editor.GetText()
 
-	// Check if this is a new prompt or update
-	found := false
-	for i, prompt := range p.customPrompts {
-		if prompt.ID == p.editingPrompt.ID {
-			p.customPrompts[i] = *p.editingPrompt
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		// Add new prompt to custom prompts
-		p.customPrompts = append(p.customPrompts, *p.editingPrompt)
-	}
-
-	// Save custom prompts to config
-	p.saveCustomPromptsToConfig()
-
-	// Update system prompt if needed
-	p.updateSystemPrompt()
+	// Add or update the custom prompt (also refreshes the combined system prompt)
+	p.service.SaveCustom(*p.editingPrompt)
 
 	p.SetDirty(false)
-	p.updateMenuItems()
+	p.refreshPrompts()
 	p.currentMode = PromptModeList
 }
 
-// saveCustomPromptsToConfig persists custom prompts to configuration
-func (p *PromptsPage) saveCustomPromptsToConfig() {
-	// Convert custom prompts to config format
-	configPrompts := make([]core.CustomPrompt, 0, len(p.customPrompts))
-	for _, prompt := range p.customPrompts {
-		configPrompts = append(configPrompts, core.CustomPrompt{
-			ID:      prompt.ID,
-			Name:    prompt.Name,
-			Content: prompt.Content,
-		})
-	}
-
-	// Update config
-	p.config.Update(func(cfg *core.Config) {
-		cfg.CustomPrompts = configPrompts
-	})
-}
-
 // deletePrompt deletes a prompt
 func (p *PromptsPage) deletePrompt(prompt *Prompt) {
 	if prompt.IsDefault || prompt.IsMCP {
@@ -1342,89 +1489,17 @@ func (p *PromptsPage) deletePrompt(prompt *Prompt) {
 		return
 	}
 
-	// Remove from custom prompts list
-	newPrompts := []Prompt{}
-	for _, pr := range p.customPrompts {
-		if pr.ID != prompt.ID {
-			newPrompts = append(newPrompts, pr)
-		}
-	}
-	p.customPrompts = newPrompts
-
-	// Save updated custom prompts to config
-	p.saveCustomPromptsToConfig()
-
-	p.updateMenuItems()
+	p.service.DeleteCustom(prompt.ID)
+	p.refreshPrompts()
 }
 
 // togglePrompt toggles the enabled state of a prompt
 func (p *PromptsPage) togglePrompt(prompt *Prompt) {
-	// Find and toggle the prompt in the appropriate list
-	if prompt.IsDefault {
-		for i := range p.defaultPrompts {
-			if p.defaultPrompts[i].ID == prompt.ID {
-				p.defaultPrompts[i].IsEnabled = !p.defaultPrompts[i].IsEnabled
-				break
-			}
-		}
-	} else if prompt.IsMCP {
-		for i := range p.mcpPrompts {
-			if p.mcpPrompts[i].ID == prompt.ID {
-				p.mcpPrompts[i].IsEnabled = !p.mcpPrompts[i].IsEnabled
-				break
-			}
-		}
-	} else {
-		for i := range p.customPrompts {
-			if p.customPrompts[i].ID == prompt.ID {
-				p.customPrompts[i].IsEnabled = !p.customPrompts[i].IsEnabled
-				break
-			}
-		}
-	}
-
-	// Update the system prompt in config with all enabled prompts
-	p.updateSystemPrompt()
-	p.updateMenuItems()
-}
-
-// updateSystemPrompt combines all enabled prompts into the system prompt
-func (p *PromptsPage) updateSystemPrompt() {
-	var enabledPromptContents []string
-	var enabledPromptIDs []string
-
-	// Collect enabled default prompts
-	for _, prompt := range p.defaultPrompts {
-		if prompt.IsEnabled {
-			enabledPromptContents = append(enabledPromptContents, prompt.Content)
-			enabledPromptIDs = append(enabledPromptIDs, prompt.ID)
-		}
-	}
-
-	// Collect enabled custom prompts
-	for _, prompt := range p.customPrompts {
-		if prompt.IsEnabled {
-			enabledPromptContents = append(enabledPromptContents, prompt.Content)
-			enabledPromptIDs = append(enabledPromptIDs, prompt.ID)
-		}
-	}
-
-	// Collect enabled MCP prompts
-	for _, prompt := range p.mcpPrompts {
-		if prompt.IsEnabled {
-			enabledPromptContents = append(enabledPromptContents, prompt.Content)
-			enabledPromptIDs = append(enabledPromptIDs, prompt.ID)
-		}
+	if p.lock.Refuse() {
+		return
 	}
-
-	// Combine all enabled prompts
-	combinedPrompt := strings.Join(enabledPromptContents, "\n\n")
-
-	// Update config - save both the combined prompt and the list of enabled IDs
-	p.config.Update(func(cfg *core.Config) {
-		cfg.SystemPrompt = combinedPrompt
-		cfg.EnabledPrompts = enabledPromptIDs
-	})
+	p.service.Toggle(prompt.ID)
+	p.refreshPrompts()
 }
 
 // OnActivate is called when the page becomes active
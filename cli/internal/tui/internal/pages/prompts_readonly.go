@@ -6,9 +6,9 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/hacka-re/cli/internal/models"
-	"github.com/hacka-re/cli/internal/tui/internal/prompts"
 	"github.com/hacka-re/cli/internal/tui/internal/components"
 	"github.com/hacka-re/cli/internal/tui/internal/core"
+	"github.com/hacka-re/cli/internal/tui/internal/services"
 )
 
 // PromptsReadOnlyPage displays system prompts configuration (read-only)
@@ -24,12 +24,20 @@ type PromptsReadOnlyPage struct {
 	selectedGroup       int  // 0 = default prompts, 1 = custom prompts
 	selectedItemIndex   int  // Index of selected item within the group (-1 = group header)
 	visibleHeight       int  // Height of the visible content area
-	enabledPrompts      map[string]bool // Track which prompts are enabled
-	promptContents      map[string]string // Store prompt contents for token calculation
+	service             *services.PromptsService // Shared load/toggle/persist logic
 	defaultPromptIDs    []string // Track prompt IDs in order for default prompts
-	mcpPromptIDs        []string // Track prompt IDs in order for MCP prompts
+	customPromptIDs     []string // Track prompt IDs in order for custom and MCP prompts
 }
 
+// promptsReadOnlyKeymap lists the keys of the System Prompts page
+var promptsReadOnlyKeymap = core.RegisterKeymap("prompts.readonly", "System Prompts",
+	core.Bind("↑↓", "Navigate"),
+	core.Bind("Space/Enter", "Expand/Toggle"),
+	core.Bind("S", "System prompt"),
+	core.Bind("I", "Info"),
+	core.Bind("ESC", "Back"),
+)
+
 // NewPromptsReadOnlyPage creates a new read-only prompts configuration page
 func NewPromptsReadOnlyPage(screen tcell.Screen, config *core.ConfigManager, state *core.AppState, eventBus *core.EventBus) *PromptsReadOnlyPage {
 	_, h := screen.Size()
@@ -39,10 +47,9 @@ func NewPromptsReadOnlyPage(screen tcell.Screen, config *core.ConfigManager, sta
 		selectedGroup:     0,  // Start with default prompts selected
 		selectedItemIndex: -1, // Start on group header
 		visibleHeight:     h - 12, // Account for header, footer, borders
-		enabledPrompts:    make(map[string]bool),
-		promptContents:    make(map[string]string),
+		service:           services.NewPromptsService(config),
 		defaultPromptIDs:  []string{},
-		mcpPromptIDs:      []string{},
+		customPromptIDs:   []string{},
 	}
 
 	w, h := screen.Size()
@@ -75,30 +82,47 @@ func NewPromptsReadOnlyPage(screen tcell.Screen, config *core.ConfigManager, sta
 
 // loadPrompts loads prompt configuration from config and embedded defaults
 func (pp *PromptsReadOnlyPage) loadPrompts() {
-	// cfg := pp.config.Get() // TODO: Use for loading enabled prompts
+	pp.service.Load()
 
 	// Clear existing items
 	pp.defaultPromptsGroup.ClearItems()
 	pp.customPromptsGroup.ClearItems()
 
-	// Load default prompts from our new prompts package
-	defaultPrompts := prompts.GetDefaultPrompts()
-	pp.defaultPromptIDs = []string{} // Reset the list
-
 	// Add default prompts to the group (no categories, flat list)
-	for _, prompt := range defaultPrompts {
-		// Store prompt content for token calculation
-		pp.promptContents[prompt.ID] = prompt.Content
-		pp.defaultPromptIDs = append(pp.defaultPromptIDs, prompt.ID)
+	pp.defaultPromptIDs = pp.addPromptItems(pp.defaultPromptsGroup, pp.service.Defaults())
+
+	// Custom prompts, plus MCP prompts when MCP is connected
+	customPrompts := append(pp.service.Custom(), pp.service.MCP()...)
+	pp.customPromptIDs = pp.addPromptItems(pp.customPromptsGroup, customPrompts)
+	if len(customPrompts) == 0 {
+		item := components.ExpandableItem{
+			Text:  "(No custom prompts defined)",
+			Style: tcell.StyleDefault.Foreground(tcell.ColorGray).Italic(true),
+		}
+		pp.customPromptsGroup.AddItem(item)
+	}
+
+	// Build system prompt preview
+	pp.buildSystemPromptPreview()
+
+	// Update token usage
+	pp.updateTokenUsage()
+}
+
+// addPromptItems adds a checkbox, description and token count per prompt and returns the prompt IDs in order
+func (pp *PromptsReadOnlyPage) addPromptItems(group *components.ExpandableGroup, promptList []services.Prompt) []string {
+	ids := make([]string, 0, len(promptList))
+	for _, prompt := range promptList {
+		ids = append(ids, prompt.ID)
 
 		item := components.ExpandableItem{
 			Text:       prompt.Name,
 			Indented:   false,
 			Style:      tcell.StyleDefault,
 			IsCheckbox: true,
-			IsChecked:  pp.enabledPrompts[prompt.ID],
+			IsChecked:  prompt.IsEnabled,
 		}
-		pp.defaultPromptsGroup.AddItem(item)
+		group.AddItem(item)
 
 		// Add description
 		if prompt.Description != "" {
@@ -107,98 +131,60 @@ func (pp *PromptsReadOnlyPage) loadPrompts() {
 				Indented: true,
 				Style:    tcell.StyleDefault.Foreground(tcell.ColorGray),
 			}
-			pp.defaultPromptsGroup.AddItem(descItem)
+			group.AddItem(descItem)
 		}
 
 		// Add token count (rough estimate)
 		tokenItem := components.ExpandableItem{
-			Text:     fmt.Sprintf("  ~%d tokens", len(prompt.Content)/4),
+			Text:     fmt.Sprintf("  ~%d tokens", services.EstimateTokens(prompt.Content)),
 			Indented: true,
 			Style:    tcell.StyleDefault.Foreground(tcell.ColorBlue),
 		}
-		pp.defaultPromptsGroup.AddItem(tokenItem)
-	}
-
-	// Check if MCP is connected (simple check - improve later)
-	mcpConnected := false // MCP prompts only show when actually connected
-
-	// Load MCP prompts if connected
-	if mcpConnected {
-		mcpPrompts := prompts.GetMCPPrompts()
-		pp.mcpPromptIDs = []string{} // Reset the list
-		for _, prompt := range mcpPrompts {
-			// Store prompt content for token calculation
-			pp.promptContents[prompt.ID] = prompt.Content
-			pp.mcpPromptIDs = append(pp.mcpPromptIDs, prompt.ID)
-
-			item := components.ExpandableItem{
-				Text:       prompt.Name,
-				Indented:   false,
-				Style:      tcell.StyleDefault,
-				IsCheckbox: true,
-				IsChecked:  pp.enabledPrompts[prompt.ID],
-			}
-			pp.customPromptsGroup.AddItem(item)
-
-			// Add description
-			if prompt.Description != "" {
-				descItem := components.ExpandableItem{
-					Text:     "  " + prompt.Description,
-					Indented: true,
-					Style:    tcell.StyleDefault.Foreground(tcell.ColorGray),
-				}
-				pp.customPromptsGroup.AddItem(descItem)
-			}
-
-			// Add token count
-			tokenItem := components.ExpandableItem{
-				Text:     fmt.Sprintf("  ~%d tokens", len(prompt.Content)/4),
-				Indented: true,
-				Style:    tcell.StyleDefault.Foreground(tcell.ColorBlue),
-			}
-			pp.customPromptsGroup.AddItem(tokenItem)
-		}
-	} else {
-		// No MCP prompts or custom prompts
-		pp.mcpPromptIDs = []string{} // Clear MCP prompt IDs
-		item := components.ExpandableItem{
-			Text:  "(No custom prompts defined)",
-			Style: tcell.StyleDefault.Foreground(tcell.ColorGray).Italic(true),
-		}
-		pp.customPromptsGroup.AddItem(item)
+		group.AddItem(tokenItem)
 	}
-
-	// Build system prompt preview
-	pp.buildSystemPromptPreview()
-
-	// Update token usage
-	pp.updateTokenUsage()
+	return ids
 }
 
 // buildSystemPromptPreview builds the complete system prompt from enabled components
 func (pp *PromptsReadOnlyPage) buildSystemPromptPreview() {
-	cfg := pp.config.Get()
-	var promptParts []string
-
-	// Add main system prompt if set
-	if cfg.SystemPrompt != "" {
-		promptParts = append(promptParts, cfg.SystemPrompt)
+	fullPrompt := pp.service.Combined()
+	if fullPrompt == "" {
+		fullPrompt = "(No prompts enabled)"
 	}
 
-	// For read-only view, add some example prompt content
-	promptParts = append(promptParts, "You are a helpful AI assistant.")
-
-	// Combine all parts
-	fullPrompt := strings.Join(promptParts, "\n\n")
-
 	// Split into lines for preview
 	pp.systemPromptPreview = strings.Split(fullPrompt, "\n")
 }
 
-// estimateTokens roughly estimates token count
-func (pp *PromptsReadOnlyPage) estimateTokens(text string) int {
-	// Rough estimation: ~4 characters per token
-	return len(text) / 4
+// togglePromptItem toggles the checkbox at itemIndex in a group and persists the prompt's enabled state
+func (pp *PromptsReadOnlyPage) togglePromptItem(groupIndex, itemIndex int) {
+	group, ids := pp.defaultPromptsGroup, pp.defaultPromptIDs
+	if groupIndex == 1 {
+		group, ids = pp.customPromptsGroup, pp.customPromptIDs
+	}
+
+	items := group.GetItems()
+	if itemIndex < 0 || itemIndex >= len(items) || !items[itemIndex].IsCheckbox {
+		return
+	}
+
+	// The prompt index is the number of checkboxes before this item
+	promptIndex := 0
+	for i := 0; i < itemIndex; i++ {
+		if items[i].IsCheckbox {
+			promptIndex++
+		}
+	}
+	if promptIndex >= len(ids) {
+		return
+	}
+
+	item := items[itemIndex]
+	item.IsChecked = pp.service.Toggle(ids[promptIndex])
+	group.UpdateItem(itemIndex, item)
+
+	pp.buildSystemPromptPreview()
+	pp.updateTokenUsage()
 }
 
 // getContentPreview returns a truncated preview of content
@@ -215,16 +201,8 @@ func (pp *PromptsReadOnlyPage) getContentPreview(content string, maxLen int) str
 
 // updateTokenUsage calculates and updates token usage display
 func (pp *PromptsReadOnlyPage) updateTokenUsage() {
-	totalTokens := 0
-
 	// Calculate tokens from all enabled prompts
-	for promptID, isEnabled := range pp.enabledPrompts {
-		if isEnabled {
-			if content, exists := pp.promptContents[promptID]; exists {
-				totalTokens += pp.estimateTokens(content)
-			}
-		}
-	}
+	totalTokens := pp.service.TokenCount()
 
 	// Get the actual model's token limit
 	cfg := pp.config.Get()
@@ -385,9 +363,12 @@ func (pp *PromptsReadOnlyPage) Draw() {
 	pp.tokenUsageBar.Draw()
 
 	// Draw instructions
-	instructions := " ↑↓:Navigate | Space/Enter:Expand/Toggle | S:System Prompt | I:Info | ESC:Back "
-	instructionStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
-	pp.DrawCenteredText(h-2, instructions, instructionStyle)
+	pp.DrawHint(h-2, pp.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
+
+// Keymap returns the bindings of the page
+func (pp *PromptsReadOnlyPage) Keymap() *core.Keymap {
+	return promptsReadOnlyKeymap
 }
 
 // drawContentBorder draws a border around the content area
@@ -580,53 +561,7 @@ func (pp *PromptsReadOnlyPage) HandleInput(ev *tcell.EventKey) bool {
 				}
 			} else {
 				// On an item - toggle checkbox if it has one
-				var items []components.ExpandableItem
-				if pp.selectedGroup == 0 {
-					items = pp.defaultPromptsGroup.GetItems()
-				} else {
-					items = pp.customPromptsGroup.GetItems()
-				}
-				if pp.selectedItemIndex < len(items) {
-					item := &items[pp.selectedItemIndex]
-					if item.IsCheckbox {
-						item.IsChecked = !item.IsChecked
-
-						// Find the actual prompt item index (skip non-checkbox items)
-						checkboxIndex := 0
-						for i := 0; i <= pp.selectedItemIndex; i++ {
-							if items[i].IsCheckbox {
-								if i == pp.selectedItemIndex {
-									break
-								}
-								checkboxIndex++
-							}
-						}
-
-						// Update enabledPrompts map
-						var promptID string
-						if pp.selectedGroup == 0 {
-							// Default prompts
-							if checkboxIndex < len(pp.defaultPromptIDs) {
-								promptID = pp.defaultPromptIDs[checkboxIndex]
-							}
-							pp.defaultPromptsGroup.UpdateItem(pp.selectedItemIndex, *item)
-						} else {
-							// MCP prompts in custom group
-							if checkboxIndex < len(pp.mcpPromptIDs) {
-								promptID = pp.mcpPromptIDs[checkboxIndex]
-							}
-							pp.customPromptsGroup.UpdateItem(pp.selectedItemIndex, *item)
-						}
-
-						// Update the enabled state
-						if promptID != "" {
-							pp.enabledPrompts[promptID] = item.IsChecked
-						}
-
-						// Update token usage when toggling prompts
-						pp.updateTokenUsage()
-					}
-				}
+				pp.togglePromptItem(pp.selectedGroup, pp.selectedItemIndex)
 			}
 			return false
 		}
@@ -770,14 +705,7 @@ func (pp *PromptsReadOnlyPage) HandleMouse(event *core.MouseEvent) bool {
 				item := items[itemIndex]
 				if item.IsCheckbox && event.Type == core.MouseEventClick {
 					// Toggle checkbox state
-					items[itemIndex].IsChecked = !items[itemIndex].IsChecked
-					pp.defaultPromptsGroup.UpdateItem(itemIndex, items[itemIndex])
-
-					// Update the enabled prompts tracking
-					if itemIndex/3 < len(pp.defaultPromptIDs) {
-						promptID := pp.defaultPromptIDs[itemIndex/3]
-						pp.enabledPrompts[promptID] = items[itemIndex].IsChecked
-					}
+					pp.togglePromptItem(0, itemIndex)
 
 					// Update selection
 					pp.selectedGroup = 0
@@ -825,14 +753,7 @@ func (pp *PromptsReadOnlyPage) HandleMouse(event *core.MouseEvent) bool {
 				item := items[itemIndex]
 				if item.IsCheckbox && event.Type == core.MouseEventClick {
 					// Toggle checkbox state
-					items[itemIndex].IsChecked = !items[itemIndex].IsChecked
-					pp.customPromptsGroup.UpdateItem(itemIndex, items[itemIndex])
-
-					// Update the enabled prompts tracking
-					if itemIndex/3 < len(pp.mcpPromptIDs) {
-						promptID := pp.mcpPromptIDs[itemIndex/3]
-						pp.enabledPrompts[promptID] = items[itemIndex].IsChecked
-					}
+					pp.togglePromptItem(1, itemIndex)
 
 					// Update selection
 					pp.selectedGroup = 1
@@ -36,6 +36,13 @@ type RAGDocument struct {
 	Enabled     bool
 }
 
+// ragKeymap lists the keys of the RAG Configuration page
+var ragKeymap = core.RegisterKeymap("rag", "RAG Configuration",
+	core.Bind("Space", "Expand/Collapse"),
+	core.Bind("I", "Info"),
+	core.Bind("ESC", "Back"),
+)
+
 // NewRAGPage creates a new RAG configuration page
 func NewRAGPage(screen tcell.Screen, config *core.ConfigManager, state *core.AppState, eventBus *core.EventBus) *RAGPage {
 	page := &RAGPage{
@@ -156,6 +163,18 @@ func (rp *RAGPage) loadRAGConfig() {
 	rp.updateTokenUsage()
 }
 
+// IndexSummary returns the number of enabled documents, their chunks, and
+// whether RAG is enabled for the current provider
+func (rp *RAGPage) IndexSummary() (docs, chunks int, enabled bool) {
+	for _, doc := range rp.documents {
+		if doc.Enabled {
+			docs++
+			chunks += doc.Chunks
+		}
+	}
+	return docs, chunks, rp.ragEnabled && !rp.providerWarning
+}
+
 // addDocumentToGroup adds a document to an expandable group
 func (rp *RAGPage) addDocumentToGroup(doc *RAGDocument, group *components.ExpandableGroup) {
 	// Document header with checkbox
@@ -272,9 +291,12 @@ func (rp *RAGPage) Draw() {
 	rp.tokenUsageBar.Draw()
 
 	// Draw instructions
-	instructions := " I:Info | Space:Expand/Collapse | ↑↓:Scroll | ESC:Back "
-	instructionStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
-	rp.DrawCenteredText(h-2, instructions, instructionStyle)
+	rp.DrawHint(h-2, rp.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
+
+// Keymap returns the bindings of the page
+func (rp *RAGPage) Keymap() *core.Keymap {
+	return ragKeymap
 }
 
 // drawContentBorder draws a border around the content area
@@ -1,14 +1,19 @@
 package pages
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/artifacts"
+	"github.com/hacka-re/cli/internal/language"
 	"github.com/hacka-re/cli/internal/models"
 	"github.com/hacka-re/cli/internal/tui/internal/components"
 	"github.com/hacka-re/cli/internal/tui/internal/core"
+	"github.com/hacka-re/cli/internal/tui/internal/services"
+	"github.com/hacka-re/cli/internal/usage"
 )
 
 // SettingsModal provides a streamlined settings interface matching the web app
@@ -25,8 +30,17 @@ type SettingsModal struct {
 	editBuffer       string
 	dropdownSelector *components.DropdownSelector
 	modelSelector    *components.ModelSelector
+	confirmDialog    *components.ConfirmDialog
+	suggestedBaseURL string // Offered in confirmDialog after a connection test
 	isLoadingModels  bool
+	isTesting        bool
+	isValidatingKey  bool
 	errorMessage     string
+	lock             *linkLock // Provider fields are read-only while the share link is locked
+
+	// Network requests run in the background; results arrive via the EventBus
+	chatClient       *services.ChatClient
+	cancelRequest    context.CancelFunc
 
 	// Original values for restore
 	originalConfig   *core.Config
@@ -41,6 +55,24 @@ type SettingsModal struct {
 	OnOpenNamespaceManager func()
 }
 
+// Keymaps of the settings modal
+var (
+	settingsKeymap = core.RegisterKeymap("settings", "Settings",
+		core.Bind("↑↓", "Navigate"),
+		core.Bind("Enter", "Edit"),
+		core.Bind("Space", "Toggle"),
+		core.Bind("R", "Refresh models"),
+		core.Bind("T", "Test connection"),
+		core.Bind("Ctrl+S", "Save"),
+		core.Bind("ESC", "Close"),
+	)
+	settingsEditKeymap = core.RegisterKeymap("settings.edit", "Settings: editing a field",
+		core.Bind("Enter", "Apply"),
+		core.Bind("⌫", "Delete (clears an API key)"),
+		core.Bind("ESC", "Cancel"),
+	).WithTextEntry()
+)
+
 // SettingsItem represents a single settings item
 type SettingsItem struct {
 	Type        SettingsItemType
@@ -60,6 +92,7 @@ const (
 	ItemTypeCheckbox
 	ItemTypeLink
 	ItemTypeAction
+	ItemTypeText
 )
 
 // NewSettingsModal creates a new streamlined settings modal
@@ -70,6 +103,8 @@ func NewSettingsModal(screen tcell.Screen, config *core.ConfigManager, state *co
 		state:        state,
 		eventBus:     eventBus,
 		modelRegistry: models.NewModelRegistry(),
+		chatClient:    services.NewChatClient(config),
+		lock:          newLinkLock(screen, config),
 	}
 
 	// Save original config for restore
@@ -80,6 +115,24 @@ func NewSettingsModal(screen tcell.Screen, config *core.ConfigManager, state *co
 		Model: cfg.Model,
 		YoloMode: cfg.YoloMode,
 		VoiceControl: cfg.VoiceControl,
+		NotifyOnComplete: cfg.NotifyOnComplete,
+		LintPrompts: cfg.LintPrompts,
+		InputLockMode: cfg.InputLockMode,
+		ShowTimestamps: cfg.ShowTimestamps,
+		ShowModelName: cfg.ShowModelName,
+		MessageLayout: cfg.MessageLayout,
+		Language: cfg.Language,
+		KeyRotation: cfg.KeyRotation,
+		ArtifactsMaxAgeDays: cfg.ArtifactsMaxAgeDays,
+		ArtifactsMaxSizeMB: cfg.ArtifactsMaxSizeMB,
+		ExtraAPIKeys: make(map[string][]string, len(cfg.ExtraAPIKeys)),
+		LocalRuntime: make(map[string]core.LocalRuntimeOptions, len(cfg.LocalRuntime)),
+	}
+	for provider, keys := range cfg.ExtraAPIKeys {
+		sm.originalConfig.ExtraAPIKeys[provider] = keys
+	}
+	for model, options := range cfg.LocalRuntime {
+		sm.originalConfig.LocalRuntime[model] = options
 	}
 
 	sm.initializeItems()
@@ -107,6 +160,23 @@ func (sm *SettingsModal) initializeItems() {
 			Value:      cfg.APIKey,
 			StatusText: sm.getAPIKeyStatus(cfg.APIKey),
 		},
+		// Further keys of the provider, comma separated
+		{
+			Type:       ItemTypePassword,
+			Label:      "Extra API keys",
+			Key:        "extra_api_keys",
+			Value:      strings.Join(cfg.ExtraAPIKeys[cfg.Provider], ","),
+			StatusText: sm.getExtraKeysStatus(),
+		},
+		// Key rotation dropdown
+		{
+			Type:       ItemTypeDropdown,
+			Label:      "Key rotation",
+			Key:        "key_rotation",
+			Value:      cfg.KeyRotation,
+			Options:    []string{core.KeyRotationOn429, core.KeyRotationRoundRobin},
+			StatusText: sm.getKeyRotationStatus(cfg.KeyRotation),
+		},
 		// Model dropdown with refresh
 		{
 			Type:       ItemTypeDropdown,
@@ -124,6 +194,15 @@ func (sm *SettingsModal) initializeItems() {
 			Value:   "Open System Prompt Menu →",
 			Handler: sm.openSystemPrompts,
 		},
+		// Reply language dropdown
+		{
+			Type:       ItemTypeDropdown,
+			Label:      "Reply language",
+			Key:        "language",
+			Value:      sm.languageValue(cfg.Language),
+			Options:    append([]string{language.Off, language.Auto}, language.Codes()...),
+			StatusText: sm.getLanguageStatus(sm.languageValue(cfg.Language)),
+		},
 		// YOLO Mode checkbox
 		{
 			Type:       ItemTypeCheckbox,
@@ -140,6 +219,62 @@ func (sm *SettingsModal) initializeItems() {
 			Value:      cfg.VoiceControl,
 			StatusText: sm.getVoiceControlStatus(cfg.VoiceControl, cfg.Provider),
 		},
+		// Input lock mode dropdown
+		{
+			Type:       ItemTypeDropdown,
+			Label:      "Enter while generating",
+			Key:        "input_lock_mode",
+			Value:      cfg.InputLockMode,
+			Options:    []string{core.InputLockBlock, core.InputLockQueue, core.InputLockReplace},
+			StatusText: sm.getInputLockStatus(cfg.InputLockMode),
+		},
+		// Prompt check checkbox
+		{
+			Type:       ItemTypeCheckbox,
+			Label:      "Check prompts before sending",
+			Key:        "lint_prompts",
+			Value:      cfg.LintPrompts,
+			StatusText: sm.getLintStatus(cfg.LintPrompts),
+		},
+		// Desktop notification checkbox
+		{
+			Type:       ItemTypeCheckbox,
+			Label:      "Desktop notifications",
+			Key:        "notify_on_complete",
+			Value:      cfg.NotifyOnComplete,
+			StatusText: sm.getNotifyStatus(cfg.NotifyOnComplete),
+		},
+		// Chat display checkboxes and layout dropdown
+		{
+			Type:       ItemTypeCheckbox,
+			Label:      "Message timestamps",
+			Key:        "show_timestamps",
+			Value:      cfg.ShowTimestamps,
+			StatusText: sm.getShowTimestampsStatus(cfg.ShowTimestamps),
+		},
+		{
+			Type:       ItemTypeCheckbox,
+			Label:      "Model name on replies",
+			Key:        "show_model_name",
+			Value:      cfg.ShowModelName,
+			StatusText: sm.getShowModelNameStatus(cfg.ShowModelName),
+		},
+		{
+			Type:       ItemTypeDropdown,
+			Label:      "Message layout",
+			Key:        "message_layout",
+			Value:      cfg.MessageLayout,
+			Options:    []string{core.MessageLayoutSpacious, core.MessageLayoutCompact},
+			StatusText: sm.getMessageLayoutStatus(cfg.MessageLayout),
+		},
+		// Cleanup of old session artifacts
+		{
+			Type:       ItemTypeText,
+			Label:      "Artifact cleanup",
+			Key:        "artifact_cleanup",
+			Value:      cfg.ArtifactPolicy().String(),
+			StatusText: sm.getArtifactCleanupStatus(),
+		},
 		// Delete namespace action
 		{
 			Type:    ItemTypeAction,
@@ -148,6 +283,59 @@ func (sm *SettingsModal) initializeItems() {
 			Handler: sm.deleteNamespace,
 		},
 	}
+	sm.syncLocalRuntimeItem()
+}
+
+// syncLocalRuntimeItem shows the runtime options of the model below it while a
+// local provider is selected, and hides them for remote providers
+func (sm *SettingsModal) syncLocalRuntimeItem() {
+	local := core.IsLocalProvider(sm.items[0].Value.(string))
+	modelIndex, runtimeIndex := -1, -1
+	for i, item := range sm.items {
+		switch item.Key {
+		case "model":
+			modelIndex = i
+		case "local_runtime":
+			runtimeIndex = i
+		}
+	}
+
+	switch {
+	case local && runtimeIndex < 0:
+		cfg := sm.config.Get()
+		item := SettingsItem{
+			Type:  ItemTypeText,
+			Label: "Local runtime",
+			Key:   "local_runtime",
+			Value: cfg.LocalRuntime[cfg.Model].String(),
+		}
+		sm.items = append(sm.items[:modelIndex+1], append([]SettingsItem{item}, sm.items[modelIndex+1:]...)...)
+		if sm.selectedIndex > modelIndex {
+			sm.selectedIndex++
+		}
+		sm.updateStatusText()
+	case !local && runtimeIndex >= 0:
+		sm.items = append(sm.items[:runtimeIndex], sm.items[runtimeIndex+1:]...)
+		if sm.selectedIndex > runtimeIndex {
+			sm.selectedIndex--
+		}
+	}
+}
+
+// Fields returns the settings items in display order
+func (sm *SettingsModal) Fields() []SettingsItem {
+	return sm.items
+}
+
+// SelectKey moves the selection to the item with the given key
+func (sm *SettingsModal) SelectKey(key string) bool {
+	for i, item := range sm.items {
+		if item.Key == key {
+			sm.selectedIndex = i
+			return true
+		}
+	}
+	return false
 }
 
 // getProviderOptions returns the list of available providers
@@ -162,6 +350,31 @@ func (sm *SettingsModal) getProviderOptions() []string {
 		"lmstudio",
 		"localai",
 		"custom",
+		core.ProviderCustomProtocol,
+	}
+}
+
+// getProviderCheckDescriptions describes when each provider last passed a
+// connection test, for the provider dropdown
+func (sm *SettingsModal) getProviderCheckDescriptions() map[string]string {
+	descriptions := make(map[string]string)
+	for provider, check := range sm.config.Get().ProviderChecks {
+		descriptions[provider] = fmt.Sprintf("Last connected %s (%dms)", formatAge(time.Since(check.LastSuccess)), check.LatencyMS)
+	}
+	return descriptions
+}
+
+// formatAge renders a duration as a short "5m ago" style age
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
 	}
 }
 
@@ -259,6 +472,36 @@ func (sm *SettingsModal) getAPIKeyStatus(apiKey string) string {
 	return "(Key configured)"
 }
 
+// getExtraKeysStatus summarizes the keys of the provider and how much each was used
+func (sm *SettingsModal) getExtraKeysStatus() string {
+	usage := services.KeyUsageFor(sm.config.Get())
+	if len(usage) < 2 {
+		return "(Comma separated, rotated with the API key)"
+	}
+	requests := make([]string, len(usage))
+	limited := 0
+	for i, key := range usage {
+		requests[i] = fmt.Sprintf("%d", key.Requests)
+		limited += key.RateLimited
+	}
+	return fmt.Sprintf("(%d keys, requests %s, %d× 429)", len(usage), strings.Join(requests, "/"), limited)
+}
+
+func (sm *SettingsModal) getKeyRotationStatus(mode string) string {
+	if mode == core.KeyRotationRoundRobin {
+		return "(Use the keys in turn)"
+	}
+	return "(Switch key when rate limited)"
+}
+
+// getLocalRuntimeStatus explains which runtime options the local provider takes per request
+func (sm *SettingsModal) getLocalRuntimeStatus(provider string) string {
+	if provider == "ollama" {
+		return "(ctx= gpu= temp= for this model)"
+	}
+	return "(temp= for this model)"
+}
+
 func (sm *SettingsModal) getYoloModeStatus(enabled bool) string {
 	if enabled {
 		return "(Enabled: User is NOT prompted for every function call!)"
@@ -280,6 +523,80 @@ func (sm *SettingsModal) getVoiceControlStatus(enabled bool, provider string) st
 	return fmt.Sprintf("(Enabled, auto-detected: %s)", providerName)
 }
 
+func (sm *SettingsModal) getInputLockStatus(mode string) string {
+	switch mode {
+	case core.InputLockQueue:
+		return "(Queue one message)"
+	case core.InputLockReplace:
+		return "(Cancel and send new message)"
+	default:
+		return "(Wait for response)"
+	}
+}
+
+func (sm *SettingsModal) getLintStatus(enabled bool) string {
+	if enabled {
+		return "(Secrets, open code fences, doubled words, walls of text)"
+	}
+	return "(Disabled)"
+}
+
+func (sm *SettingsModal) getNotifyStatus(enabled bool) string {
+	if !enabled {
+		return "(Disabled)"
+	}
+	threshold := sm.config.Get().NotifyThreshold()
+	return fmt.Sprintf("(Enabled: after %s while unfocused)", threshold)
+}
+
+func (sm *SettingsModal) getShowTimestampsStatus(enabled bool) string {
+	if enabled {
+		return "(Time of each message in its header)"
+	}
+	return "(Hidden)"
+}
+
+func (sm *SettingsModal) getShowModelNameStatus(enabled bool) string {
+	if enabled {
+		return "(Model that wrote each reply)"
+	}
+	return "(Hidden)"
+}
+
+func (sm *SettingsModal) getMessageLayoutStatus(layout string) string {
+	if layout == core.MessageLayoutCompact {
+		return "(No space between messages)"
+	}
+	return "(Blank line between messages)"
+}
+
+// languageValue returns the reply language setting as the dropdown lists it
+func (sm *SettingsModal) languageValue(setting string) string {
+	if value, err := language.Parse(setting); err == nil {
+		return value
+	}
+	return language.Off
+}
+
+func (sm *SettingsModal) getLanguageStatus(value string) string {
+	switch value {
+	case language.Off:
+		return "(The model picks; /lang changes it per chat)"
+	case language.Auto:
+		return "(Follows the language of your messages)"
+	}
+	return "(" + language.Name(value) + ")"
+}
+
+// getArtifactCleanupStatus shows how much space saved artifacts take
+func (sm *SettingsModal) getArtifactCleanupStatus() string {
+	sessions, size, err := artifacts.Usage(artifacts.Root())
+	if err != nil || sessions == 0 {
+		return "(days=N mb=N or off; no artifacts saved yet)"
+	}
+	return fmt.Sprintf("(days=N mb=N or off; %d sessions, %s)", sessions, artifacts.FormatSize(size))
+}
+
 // Action handlers
 func (sm *SettingsModal) openSystemPrompts() error {
 	if sm.OnOpenPrompts != nil {
@@ -311,7 +628,7 @@ func (sm *SettingsModal) Draw() {
 
 	// Calculate modal dimensions
 	modalWidth := 70
-	modalHeight := 25
+	modalHeight := 29
 	modalX := (w - modalWidth) / 2
 	modalY := (h - modalHeight) / 2
 
@@ -327,6 +644,11 @@ func (sm *SettingsModal) Draw() {
 	// Draw footer
 	sm.drawFooter(modalX, modalY+modalHeight-2, modalWidth)
 
+	// Draw the last error above the footer
+	if sm.errorMessage != "" {
+		sm.drawError(modalX, modalY+modalHeight-3, modalWidth)
+	}
+
 	// Draw dropdown if active
 	if sm.dropdownSelector != nil {
 		sm.dropdownSelector.Draw()
@@ -337,9 +659,18 @@ func (sm *SettingsModal) Draw() {
 		sm.modelSelector.Draw()
 	}
 
-	// Draw loading spinner if loading models
+	// Draw the base URL correction prompt
+	if sm.confirmDialog != nil {
+		sm.confirmDialog.Draw()
+	}
+
+	// Draw loading spinner while a request is running
 	if sm.isLoadingModels {
-		sm.drawLoadingSpinner(modalX+modalWidth/2, modalY+modalHeight/2)
+		sm.drawLoadingSpinner(modalX+modalWidth/2, modalY+modalHeight/2, "Loading models... (ESC to cancel)")
+	} else if sm.isTesting {
+		sm.drawLoadingSpinner(modalX+modalWidth/2, modalY+modalHeight/2, "Testing connection... (ESC to cancel)")
+	} else if sm.isValidatingKey {
+		sm.drawLoadingSpinner(modalX+modalWidth/2, modalY+modalHeight/2, "Validating API key... (ESC to cancel)")
 	}
 }
 
@@ -387,6 +718,18 @@ func (sm *SettingsModal) drawHeader(x, y, w int) {
 		sm.screen.SetContent(titleX+i, y, r, nil, titleStyle)
 	}
 
+	// Changes are written behind; show when some haven't reached disk yet
+	if sm.config.IsDirty() {
+		dirty := "● unsaved (Ctrl+S) "
+		dirtyStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+		for i, r := range []rune(dirty) {
+			sm.screen.SetContent(x+w-1-len([]rune(dirty))+i, y, r, nil, dirtyStyle)
+		}
+	}
+
+	// Draw the banner of a locked share link
+	sm.lock.Draw(x+1, y+1, w-2)
+
 	// Draw separator
 	sepStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
 	for i := 1; i < w-1; i++ {
@@ -398,7 +741,11 @@ func (sm *SettingsModal) drawHeader(x, y, w int) {
 func (sm *SettingsModal) drawItems(x, y, w, h int) {
 	currentY := y
 
+	first := sm.firstVisibleItem(h)
 	for i, item := range sm.items {
+		if i < first {
+			continue
+		}
 		if currentY >= y+h {
 			break // Don't draw beyond bounds
 		}
@@ -409,6 +756,15 @@ func (sm *SettingsModal) drawItems(x, y, w, h int) {
 	}
 }
 
+// firstVisibleItem scrolls the items so the selected one stays visible when
+// not all of them fit in h rows
+func (sm *SettingsModal) firstVisibleItem(h int) int {
+	if visible := h / 2; sm.selectedIndex >= visible {
+		return sm.selectedIndex - visible + 1
+	}
+	return 0
+}
+
 // drawItem draws a single settings item
 func (sm *SettingsModal) drawItem(x, y, w int, item SettingsItem, isSelected, isEditing bool) {
 	labelStyle := tcell.StyleDefault
@@ -449,6 +805,15 @@ func (sm *SettingsModal) drawItem(x, y, w int, item SettingsItem, isSelected, is
 			valueText = sm.editBuffer
 		}
 
+	case ItemTypeText:
+		valueText = item.Value.(string)
+		if valueText == "" {
+			valueText = "(server defaults)"
+		}
+		if isEditing {
+			valueText = sm.editBuffer
+		}
+
 	case ItemTypeCheckbox:
 		if item.Value.(bool) {
 			valueText = "[x]"
@@ -495,23 +860,42 @@ func (sm *SettingsModal) drawItem(x, y, w int, item SettingsItem, isSelected, is
 
 // drawFooter draws the modal footer
 func (sm *SettingsModal) drawFooter(x, y, w int) {
-	instructions := " ↑↓:Navigate | Enter:Edit | Space:Toggle | R:Restore | ESC:Close | Ctrl+S:Save "
-	instrX := x + (w-len(instructions))/2
-	instrStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+	components.DrawHint(sm.screen, x, y, w, settingsKeymap, tcell.StyleDefault.Foreground(tcell.ColorGray))
+}
 
-	for i, r := range instructions {
-		if i < w-2 {
-			sm.screen.SetContent(instrX+i, y, r, nil, instrStyle)
-		}
+// Keymap returns the bindings of the current mode
+func (sm *SettingsModal) Keymap() *core.Keymap {
+	switch {
+	case sm.lock.Keymap() != nil:
+		return sm.lock.Keymap()
+	case sm.modelSelector != nil:
+		return sm.modelSelector.Keymap()
+	case sm.dropdownSelector != nil:
+		return components.DropdownKeymap
+	case sm.editingField:
+		return settingsEditKeymap
 	}
+	return settingsKeymap
 }
 
-// drawLoadingSpinner draws a loading spinner for model refresh
-func (sm *SettingsModal) drawLoadingSpinner(x, y int) {
+// drawError draws the error message, truncated to the modal width
+func (sm *SettingsModal) drawError(x, y, w int) {
+	text := sm.errorMessage
+	if len(text) > w-4 {
+		text = text[:w-7] + "..."
+	}
+	style := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	for i, r := range []rune(text) {
+		sm.screen.SetContent(x+2+i, y, r, nil, style)
+	}
+}
+
+// drawLoadingSpinner draws a loading spinner for a background request
+func (sm *SettingsModal) drawLoadingSpinner(x, y int, label string) {
 	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 	frame := int(time.Now().UnixMilli()/100) % len(spinner)
 
-	text := spinner[frame] + " Loading models..."
+	text := spinner[frame] + " " + label
 	style := tcell.StyleDefault.Foreground(tcell.ColorYellow)
 
 	for i, r := range text {
@@ -521,12 +905,21 @@ func (sm *SettingsModal) drawLoadingSpinner(x, y int) {
 
 // HandleInput processes keyboard input
 func (sm *SettingsModal) HandleInput(ev *tcell.EventKey) bool {
+	// The base URL correction prompt takes all keys while open
+	if sm.confirmDialog != nil {
+		if confirmed, done := sm.confirmDialog.HandleInput(ev); done {
+			sm.resolveBaseURLFix(confirmed)
+		}
+		return false
+	}
+
 	// Handle model selector if active
 	if sm.modelSelector != nil {
 		value, done := sm.modelSelector.HandleInput(ev)
 		if done {
 			if value != "" {
 				sm.items[sm.selectedIndex].Value = value
+				sm.loadLocalRuntime(value)
 				sm.updateConfig()
 			}
 			sm.modelSelector = nil
@@ -542,7 +935,12 @@ func (sm *SettingsModal) HandleInput(ev *tcell.EventKey) bool {
 		if done {
 			if value != "" {
 				sm.items[sm.selectedIndex].Value = value
+				if sm.items[sm.selectedIndex].Key == "provider" {
+					sm.loadExtraKeys(value)
+				}
 				sm.updateConfig()
+				sm.syncLocalRuntimeItem()
+				sm.updateStatusText()
 			}
 			sm.dropdownSelector = nil
 			sm.editingField = false
@@ -556,6 +954,11 @@ func (sm *SettingsModal) HandleInput(ev *tcell.EventKey) bool {
 		return sm.handleEditMode(ev)
 	}
 
+	// The override password prompt of a locked share link
+	if sm.lock.HandleInput(ev) {
+		return false
+	}
+
 	// Handle navigation
 	switch ev.Key() {
 	case tcell.KeyUp:
@@ -583,6 +986,8 @@ func (sm *SettingsModal) HandleInput(ev *tcell.EventKey) bool {
 			if sm.items[sm.selectedIndex].Key == "model" {
 				sm.refreshModels()
 			}
+		case 't', 'T':
+			sm.testConnection()
 		}
 		return false
 
@@ -591,8 +996,17 @@ func (sm *SettingsModal) HandleInput(ev *tcell.EventKey) bool {
 		return false
 
 	case tcell.KeyEscape:
-		// Save current values and close
+		// Cancel a running request before closing
+		if sm.cancelRequest != nil {
+			sm.cancelPending()
+			return false
+		}
+
+		// Save current values and write them out before closing
 		sm.updateConfig()
+		if err := sm.config.Flush(); err != nil {
+			sm.errorMessage = fmt.Sprintf("Error saving: %v", err)
+		}
 		if sm.OnSave != nil {
 			cfg := sm.config.Get()
 			sm.OnSave(cfg)
@@ -606,9 +1020,29 @@ func (sm *SettingsModal) HandleInput(ev *tcell.EventKey) bool {
 	return false
 }
 
+// linkLockedSettings are the items a locked share link makes read-only
+var linkLockedSettings = map[string]bool{
+	"provider":       true,
+	"api_key":        true,
+	"extra_api_keys": true,
+	"model":          true,
+}
+
+// refuseEdit reports whether item can't be changed: the provider fields of a
+// locked share link, or any setting in kiosk mode
+func (sm *SettingsModal) refuseEdit(item SettingsItem) bool {
+	if !linkLockedSettings[item.Key] && !sm.config.Get().Kiosk {
+		return false
+	}
+	return sm.lock.Refuse()
+}
+
 // handleEnter handles Enter key press
 func (sm *SettingsModal) handleEnter() {
 	item := sm.items[sm.selectedIndex]
+	if sm.refuseEdit(item) {
+		return
+	}
 
 	switch item.Type {
 	case ItemTypeDropdown:
@@ -647,6 +1081,11 @@ func (sm *SettingsModal) handleEnter() {
 				provider,
 				fmt.Sprintf("%v", item.Value),
 			)
+			sm.modelSelector.SetFavorites(sm.config.Get().FavoritesOf(core.FavoriteModel), sm.toggleFavoriteModel)
+			sm.modelSelector.SetFilter(sm.config.Get().ModelFilter, func(filter core.ModelFilter) {
+				sm.config.Update(func(cfg *core.Config) { cfg.ModelFilter = filter })
+			})
+			sm.modelSelector.SetConversation(sm.replayTokens())
 		} else {
 			// Use regular dropdown for other fields
 			sm.editingField = true
@@ -656,9 +1095,12 @@ func (sm *SettingsModal) handleEnter() {
 				item.Options,
 				fmt.Sprintf("%v", item.Value),
 			)
+			if item.Key == "provider" {
+				sm.dropdownSelector.SetDescriptions(sm.getProviderCheckDescriptions())
+			}
 		}
 
-	case ItemTypePassword:
+	case ItemTypePassword, ItemTypeText:
 		// Start editing
 		sm.editingField = true
 		sm.editBuffer = item.Value.(string)
@@ -680,7 +1122,7 @@ func (sm *SettingsModal) handleEnter() {
 // handleSpace handles spacebar press (for checkboxes)
 func (sm *SettingsModal) handleSpace() {
 	item := sm.items[sm.selectedIndex]
-	if item.Type == ItemTypeCheckbox {
+	if item.Type == ItemTypeCheckbox && !sm.refuseEdit(item) {
 		sm.items[sm.selectedIndex].Value = !item.Value.(bool)
 		sm.updateStatusText()
 		sm.updateConfig()
@@ -691,6 +1133,22 @@ func (sm *SettingsModal) handleSpace() {
 func (sm *SettingsModal) handleEditMode(ev *tcell.EventKey) bool {
 	switch ev.Key() {
 	case tcell.KeyEnter:
+		// Keep editing until the runtime options parse
+		if sm.items[sm.selectedIndex].Key == "local_runtime" {
+			if _, err := core.ParseLocalRuntimeOptions(sm.editBuffer); err != nil {
+				sm.errorMessage = fmt.Sprintf("Local runtime: %v", err)
+				return false
+			}
+			sm.errorMessage = ""
+		}
+		if sm.items[sm.selectedIndex].Key == "artifact_cleanup" {
+			if _, err := artifacts.ParsePolicy(sm.editBuffer); err != nil {
+				sm.errorMessage = fmt.Sprintf("Artifact cleanup: %v", err)
+				return false
+			}
+			sm.errorMessage = ""
+		}
+
 		// Save the edited value
 		sm.items[sm.selectedIndex].Value = sm.editBuffer
 
@@ -699,8 +1157,11 @@ func (sm *SettingsModal) handleEditMode(ev *tcell.EventKey) bool {
 			sm.detectAPIKeyProvider(sm.editBuffer)
 		}
 
-		sm.updateStatusText()
 		sm.updateConfig()
+		sm.updateStatusText()
+		if sm.items[sm.selectedIndex].Key == "api_key" && sm.editBuffer != "" {
+			sm.validateAPIKey()
+		}
 		sm.editingField = false
 		sm.editBuffer = ""
 
@@ -724,22 +1185,218 @@ func (sm *SettingsModal) handleEditMode(ev *tcell.EventKey) bool {
 	return false
 }
 
-// refreshModels refreshes the model list from the API
+// refreshModels fetches the model list from the API in the background
 func (sm *SettingsModal) refreshModels() {
+	// Make sure the request uses the provider and key shown in the modal
+	sm.updateConfig()
+	ctx, done := sm.startRequest()
 	sm.isLoadingModels = true
+	provider := sm.config.Get().Provider
 
-	// In a real implementation, this would call the API
-	// For now, just simulate a delay
 	go func() {
-		time.Sleep(2 * time.Second)
-		sm.isLoadingModels = false
+		defer core.HandlePanic()
+		defer done()
+		modelIDs, err := sm.chatClient.ListModels(ctx)
+		if ctx.Err() != nil {
+			return // Cancelled or superseded
+		}
+		sm.eventBus.PublishAsync(core.EventModelsLoaded, services.ModelsResult{
+			Provider: provider,
+			Models:   modelIDs,
+			Err:      err,
+		})
+	}()
+}
 
-		// Update model options
-		provider := sm.items[0].Value.(string)
-		sm.items[2].Options = sm.getModelOptions(provider)
+// testConnection checks the provider and API key in the background
+func (sm *SettingsModal) testConnection() {
+	sm.updateConfig()
+	ctx, done := sm.startRequest()
+	sm.isTesting = true
+
+	go func() {
+		defer core.HandlePanic()
+		defer done()
+		result := sm.chatClient.TestConnection(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		sm.eventBus.PublishAsync(core.EventConnectionTested, result)
 	}()
 }
 
+// validateAPIKey checks a newly entered key against the detected provider in the background
+func (sm *SettingsModal) validateAPIKey() {
+	ctx, done := sm.startRequest()
+	sm.isValidatingKey = true
+
+	go func() {
+		defer core.HandlePanic()
+		defer done()
+		result := sm.chatClient.ValidateAPIKey(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		sm.eventBus.PublishAsync(core.EventKeyValidated, result)
+	}()
+}
+
+// offerBaseURLFix asks whether to switch to a base URL the connection test found working
+func (sm *SettingsModal) offerBaseURLFix(baseURL string) {
+	if sm.lock.Locked() {
+		return
+	}
+	message := fmt.Sprintf("The models endpoint was not found under\n%s\nbut %s works.\nUse it as the Base URL?", sm.config.Get().BaseURL, baseURL)
+	sm.suggestedBaseURL = baseURL
+	sm.confirmDialog = components.NewConfirmDialog(sm.screen, "Fix Base URL?", message)
+	sm.confirmDialog.Center()
+}
+
+// resolveBaseURLFix applies or dismisses the offered base URL and tests it again
+func (sm *SettingsModal) resolveBaseURLFix(apply bool) {
+	if apply {
+		baseURL := sm.suggestedBaseURL
+		sm.config.Update(func(cfg *core.Config) {
+			cfg.BaseURL = baseURL
+		})
+		sm.testConnection()
+	}
+	sm.confirmDialog = nil
+	sm.suggestedBaseURL = ""
+}
+
+// startRequest cancels any running request and returns a context for a new one,
+// plus a function the worker calls when finished. While the request runs, the
+// screen is woken periodically to animate the spinner.
+func (sm *SettingsModal) startRequest() (context.Context, context.CancelFunc) {
+	sm.cancelPending()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sm.cancelRequest = cancel
+
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sm.screen.PostEvent(tcell.NewEventInterrupt(nil))
+			}
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// cancelPending aborts the running request, if any
+func (sm *SettingsModal) cancelPending() {
+	if sm.cancelRequest != nil {
+		sm.cancelRequest()
+		sm.cancelRequest = nil
+	}
+	sm.isLoadingModels = false
+	sm.isTesting = false
+	sm.isValidatingKey = false
+}
+
+// HandleAsyncEvent applies the result of a background request (call from the UI goroutine)
+func (sm *SettingsModal) HandleAsyncEvent(event core.Event) {
+	switch result := event.Data.(type) {
+	case services.ModelsResult:
+		if !sm.isLoadingModels {
+			return // Cancelled meanwhile
+		}
+		sm.cancelPending()
+
+		for i := range sm.items {
+			if sm.items[i].Key != "model" {
+				continue
+			}
+			if result.Err != nil {
+				sm.items[i].StatusText = "Refresh failed - [R] to retry"
+				sm.errorMessage = fmt.Sprintf("Model refresh failed: %v", result.Err)
+			} else if len(result.Models) > 0 {
+				sm.items[i].Options = result.Models
+				sm.items[i].StatusText = fmt.Sprintf("%d models - [R] to refresh", len(result.Models))
+				sm.errorMessage = ""
+			}
+		}
+
+	case services.ConnectionResult:
+		if !sm.isTesting {
+			return
+		}
+		sm.cancelPending()
+
+		for i := range sm.items {
+			if sm.items[i].Key != "api_key" {
+				continue
+			}
+			if result.Err != nil {
+				sm.items[i].StatusText = "✗ Connection failed"
+				sm.errorMessage = fmt.Sprintf("Connection test failed: %v", result.Err)
+				if result.SuggestedBaseURL != "" {
+					sm.offerBaseURLFix(result.SuggestedBaseURL)
+				}
+			} else if result.CompletionLatency > 0 {
+				sm.items[i].StatusText = fmt.Sprintf("✓ Connected (list %dms, reply %dms, %d models) %s",
+					result.ModelsLatency.Milliseconds(), result.CompletionLatency.Milliseconds(), result.Models, strings.Join(result.Capabilities, ", "))
+				sm.errorMessage = ""
+			} else {
+				sm.items[i].StatusText = fmt.Sprintf("✓ Connected (%dms, %d models)", result.Latency.Milliseconds(), result.Models)
+				sm.errorMessage = ""
+			}
+		}
+
+	case services.KeyValidationResult:
+		if !sm.isValidatingKey {
+			return
+		}
+		sm.cancelPending()
+
+		for i := range sm.items {
+			if sm.items[i].Key != "api_key" {
+				continue
+			}
+			switch {
+			case result.Err != nil:
+				sm.items[i].StatusText = "✗ Key rejected"
+				sm.errorMessage = fmt.Sprintf("API key validation failed: %v", result.Err)
+			case result.Warning != "":
+				sm.items[i].StatusText = "⚠ " + keyAccountText(result)
+				sm.errorMessage = fmt.Sprintf("Warning: %s", result.Warning)
+			default:
+				sm.items[i].StatusText = "✓ " + keyAccountText(result)
+				sm.errorMessage = ""
+			}
+		}
+	}
+}
+
+// replayTokens estimates the tokens of running the current conversation again,
+// so the model selector can price it per model
+func (sm *SettingsModal) replayTokens() (int, int) {
+	var turns []usage.Turn
+	for _, msg := range sm.state.GetMessages() {
+		turns = append(turns, usage.Turn{Role: msg.Role, Content: msg.Content})
+	}
+	return usage.ReplayTokens(sm.config.Get().SystemPrompt, turns)
+}
+
+// keyAccountText describes the account behind a validated key
+func keyAccountText(result services.KeyValidationResult) string {
+	text := fmt.Sprintf("Valid %s key", result.Provider)
+	if result.Organization != "" {
+		text += " · org " + result.Organization
+	}
+	if result.Project != "" {
+		text += " · " + result.Project
+	}
+	return text
+}
+
 // updateStatusText updates status text for items that need it
 func (sm *SettingsModal) updateStatusText() {
 	for i := range sm.items {
@@ -751,6 +1408,28 @@ func (sm *SettingsModal) updateStatusText() {
 		case "voice_control":
 			provider := sm.items[0].Value.(string)
 			sm.items[i].StatusText = sm.getVoiceControlStatus(sm.items[i].Value.(bool), provider)
+		case "input_lock_mode":
+			sm.items[i].StatusText = sm.getInputLockStatus(sm.items[i].Value.(string))
+		case "extra_api_keys":
+			sm.items[i].StatusText = sm.getExtraKeysStatus()
+		case "key_rotation":
+			sm.items[i].StatusText = sm.getKeyRotationStatus(sm.items[i].Value.(string))
+		case "local_runtime":
+			sm.items[i].StatusText = sm.getLocalRuntimeStatus(sm.items[0].Value.(string))
+		case "notify_on_complete":
+			sm.items[i].StatusText = sm.getNotifyStatus(sm.items[i].Value.(bool))
+		case "lint_prompts":
+			sm.items[i].StatusText = sm.getLintStatus(sm.items[i].Value.(bool))
+		case "show_timestamps":
+			sm.items[i].StatusText = sm.getShowTimestampsStatus(sm.items[i].Value.(bool))
+		case "show_model_name":
+			sm.items[i].StatusText = sm.getShowModelNameStatus(sm.items[i].Value.(bool))
+		case "message_layout":
+			sm.items[i].StatusText = sm.getMessageLayoutStatus(sm.items[i].Value.(string))
+		case "language":
+			sm.items[i].StatusText = sm.getLanguageStatus(sm.items[i].Value.(string))
+		case "artifact_cleanup":
+			sm.items[i].StatusText = sm.getArtifactCleanupStatus()
 		}
 	}
 }
@@ -764,17 +1443,93 @@ func (sm *SettingsModal) updateConfig() {
 				cfg.Provider = item.Value.(string)
 			case "api_key":
 				cfg.APIKey = item.Value.(string)
+			case "extra_api_keys":
+				if cfg.ExtraAPIKeys == nil {
+					cfg.ExtraAPIKeys = make(map[string][]string)
+				}
+				cfg.ExtraAPIKeys[cfg.Provider] = splitKeys(item.Value.(string))
+			case "key_rotation":
+				cfg.KeyRotation = item.Value.(string)
 			case "model":
 				cfg.Model = item.Value.(string)
+			case "local_runtime":
+				// Listed after the model, so this is stored for the model above
+				options, _ := core.ParseLocalRuntimeOptions(item.Value.(string))
+				if options.IsZero() {
+					delete(cfg.LocalRuntime, cfg.Model)
+					continue
+				}
+				if cfg.LocalRuntime == nil {
+					cfg.LocalRuntime = make(map[string]core.LocalRuntimeOptions)
+				}
+				cfg.LocalRuntime[cfg.Model] = options
 			case "yolo_mode":
 				cfg.YoloMode = item.Value.(bool)
 			case "voice_control":
 				cfg.VoiceControl = item.Value.(bool)
+			case "input_lock_mode":
+				cfg.InputLockMode = item.Value.(string)
+			case "notify_on_complete":
+				cfg.NotifyOnComplete = item.Value.(bool)
+			case "lint_prompts":
+				cfg.LintPrompts = item.Value.(bool)
+			case "show_timestamps":
+				cfg.ShowTimestamps = item.Value.(bool)
+			case "show_model_name":
+				cfg.ShowModelName = item.Value.(bool)
+			case "message_layout":
+				cfg.MessageLayout = item.Value.(string)
+			case "language":
+				cfg.Language = item.Value.(string)
+			case "artifact_cleanup":
+				policy, _ := artifacts.ParsePolicy(item.Value.(string))
+				cfg.ArtifactsMaxAgeDays = policy.MaxAgeDays
+				cfg.ArtifactsMaxSizeMB = policy.MaxSizeMB
 			}
 		}
 	})
 }
 
+// splitKeys parses a comma separated list of API keys
+func splitKeys(value string) []string {
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// loadExtraKeys shows the extra keys of a newly selected provider
+func (sm *SettingsModal) loadExtraKeys(provider string) {
+	keys := strings.Join(sm.config.Get().ExtraAPIKeys[provider], ",")
+	for i := range sm.items {
+		if sm.items[i].Key == "extra_api_keys" {
+			sm.items[i].Value = keys
+		}
+	}
+}
+
+// toggleFavoriteModel pins or unpins a model and reports whether it is pinned now
+func (sm *SettingsModal) toggleFavoriteModel(id string) bool {
+	var favorite bool
+	sm.config.Update(func(cfg *core.Config) {
+		favorite = cfg.ToggleFavorite(core.FavoriteModel, id)
+	})
+	return favorite
+}
+
+// loadLocalRuntime shows the runtime options of a newly selected model
+func (sm *SettingsModal) loadLocalRuntime(model string) {
+	options := sm.config.Get().LocalRuntime[model].String()
+	for i := range sm.items {
+		if sm.items[i].Key == "local_runtime" {
+			sm.items[i].Value = options
+		}
+	}
+}
+
 // save saves the configuration
 func (sm *SettingsModal) save() {
 	sm.updateConfig()
@@ -790,6 +1545,12 @@ func (sm *SettingsModal) save() {
 
 // HandleMouse processes mouse events for the settings modal
 func (sm *SettingsModal) HandleMouse(event *core.MouseEvent) bool {
+	// The correction prompt is answered with the keyboard; keep clicks from reaching the items
+	if sm.confirmDialog != nil {
+		sm.confirmDialog.HandleMouse(event)
+		return true
+	}
+
 	// If model selector is active, forward the event to it
 	if sm.modelSelector != nil {
 		value, done := sm.modelSelector.HandleMouse(event)
@@ -811,6 +1572,7 @@ func (sm *SettingsModal) HandleMouse(event *core.MouseEvent) bool {
 		value, done := sm.dropdownSelector.HandleMouse(event)
 		if done && value != "" {
 			sm.items[sm.selectedIndex].Value = value
+			sm.updateStatusText()
 			sm.updateConfig()
 			sm.dropdownSelector = nil
 			sm.editingField = false
@@ -824,7 +1586,7 @@ func (sm *SettingsModal) HandleMouse(event *core.MouseEvent) bool {
 	// Calculate modal bounds
 	w, h := sm.screen.Size()
 	modalWidth := 70
-	modalHeight := 25
+	modalHeight := 29
 	modalX := (w - modalWidth) / 2
 	modalY := (h - modalHeight) / 2
 
@@ -847,10 +1609,17 @@ func (sm *SettingsModal) HandleMouse(event *core.MouseEvent) bool {
 
 	// Calculate which item was clicked
 	itemY := modalY + 3
+	first := sm.firstVisibleItem(modalHeight - 6)
 	for i, item := range sm.items {
+		if i < first {
+			continue
+		}
 		if event.Y == itemY {
 			if event.Type == core.MouseEventClick {
 				sm.selectedIndex = i
+				if sm.refuseEdit(item) {
+					return true
+				}
 
 				// Handle different item types
 				switch item.Type {
@@ -899,6 +1668,7 @@ func (sm *SettingsModal) detectAPIKeyProvider(apiKey string) {
 				break
 			}
 		}
+		sm.loadExtraKeys(detectedProvider)
 
 		// Update model options for the new provider
 		modelOptions := sm.getModelOptions(detectedProvider)
@@ -912,6 +1682,7 @@ func (sm *SettingsModal) detectAPIKeyProvider(apiKey string) {
 				break
 			}
 		}
+		sm.syncLocalRuntimeItem()
 
 		sm.updateConfig()
 	}
@@ -930,6 +1701,18 @@ func (sm *SettingsModal) restoreOriginal() {
 		cfg.Model = sm.originalConfig.Model
 		cfg.YoloMode = sm.originalConfig.YoloMode
 		cfg.VoiceControl = sm.originalConfig.VoiceControl
+		cfg.NotifyOnComplete = sm.originalConfig.NotifyOnComplete
+		cfg.LintPrompts = sm.originalConfig.LintPrompts
+		cfg.InputLockMode = sm.originalConfig.InputLockMode
+		cfg.ShowTimestamps = sm.originalConfig.ShowTimestamps
+		cfg.ShowModelName = sm.originalConfig.ShowModelName
+		cfg.MessageLayout = sm.originalConfig.MessageLayout
+		cfg.Language = sm.originalConfig.Language
+		cfg.KeyRotation = sm.originalConfig.KeyRotation
+		cfg.ArtifactsMaxAgeDays = sm.originalConfig.ArtifactsMaxAgeDays
+		cfg.ArtifactsMaxSizeMB = sm.originalConfig.ArtifactsMaxSizeMB
+		cfg.ExtraAPIKeys = sm.originalConfig.ExtraAPIKeys
+		cfg.LocalRuntime = sm.originalConfig.LocalRuntime
 	})
 
 	// Reinitialize items to reflect restored values
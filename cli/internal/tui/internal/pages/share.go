@@ -2,48 +2,123 @@ package pages
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/hacka-re/cli/internal/qrcode"
 	"github.com/hacka-re/cli/internal/tui/internal/components"
 	"github.com/hacka-re/cli/internal/tui/internal/core"
+	"github.com/hacka-re/cli/internal/utils"
+	"github.com/hacka-re/cli/pkg/sharelink"
 )
 
-// SharePage displays share link configuration (read-only)
+// Steps of the share link builder
+type shareStep int
+
+const (
+	shareStepChoose   shareStep = iota // Pick what the link carries
+	shareStepReview                    // Check what it carries; an API key must be confirmed
+	shareStepPassword                  // Enter and confirm the link password
+	shareStepResult                    // Show the link, its QR code and the copy button
+)
+
+// Components a link can carry, in the order listed (keys 1-6)
+const (
+	shareAPIKey = iota
+	shareModel
+	sharePrompts
+	shareFunctions
+	shareMCP
+	shareRAG
+	shareComponentCount
+)
+
+var shareComponentLabels = [shareComponentCount]string{
+	"API key", "Provider and model", "Prompts", "Functions", "MCP servers", "RAG",
+}
+
+// shareQuietZone is the light border around the QR code, in modules. The
+// standard asks for 4; 2 scans fine from a screen and saves terminal space.
+const shareQuietZone = 2
+
+// Layout of the choose step
+const (
+	shareListY = 6 // First component row
+	shareBarY  = 14
+)
+
+// SharePage builds an encrypted share link step by step: what to share,
+// the password, then the link with its QR code
 type SharePage struct {
 	*BasePage
-	checkboxGroup    *components.CheckboxGroup
-	linkLengthBar    *components.LinkLengthBar
-	infoIcon         *components.InfoIcon
-	shareOptions     map[string]bool
-	encryptedLink    string
-	linkBytes        int
-	qrCodePlaceholder []string
+	linkLengthBar *components.LinkLengthBar
+	infoIcon      *components.InfoIcon
+
+	step     shareStep
+	selected [shareComponentCount]bool
+	cursor   int
+
+	linkBytes  int // Size of the link with the current selection
+	qrVersion  int // QR version the link needs, 0 when it is too long for one
+	estimateOK bool
+
+	includeKey  []rune // The typed confirmation to put the API key in the link
+	reviewError string
+
+	password      []rune
+	confirm       []rune
+	confirmFocus  bool
+	passwordError string
+
+	link    string
+	qr      *qrcode.Code
+	message string // Result of the last copy
+	copyY   int    // Row of the copy button as last drawn
 }
 
+// shareCopyButton is the label of the copy button, drawn at column 5
+const shareCopyButton = " Copy link "
+
+// shareKeymap lists the keys of the Share Configuration page
+var shareKeymap = core.RegisterKeymap("share", "Share Configuration",
+	core.Bind("↑↓", "Navigate"),
+	core.Bind("Space/1-6", "Toggle what is shared"),
+	core.Bind("Enter", "Review"),
+	core.Bind("I", "Info"),
+	core.Bind("ESC", "Back"),
+)
+
+// shareReviewKeymap lists the keys of the review step
+var shareReviewKeymap = core.RegisterKeymap("share.review", "Share Configuration: review",
+	core.Bind("Enter", "Set password"),
+	core.Bind("ESC", "Back"),
+).WithTextEntry()
+
+// sharePasswordKeymap lists the keys of the password step
+var sharePasswordKeymap = core.RegisterKeymap("share.password", "Share Configuration: password",
+	core.Bind("Tab", "Switch field"),
+	core.Bind("Enter", "Create link"),
+	core.Bind("ESC", "Back"),
+).WithTextEntry()
+
+// shareResultKeymap lists the keys of the finished link
+var shareResultKeymap = core.RegisterKeymap("share.result", "Share Configuration: link",
+	core.Bind("C", "Copy link"),
+	core.Bind("ESC", "Back"),
+)
+
 // NewSharePage creates a new share configuration page
 func NewSharePage(screen tcell.Screen, config *core.ConfigManager, state *core.AppState, eventBus *core.EventBus) *SharePage {
 	page := &SharePage{
 		BasePage: NewBasePage(screen, config, state, eventBus, "Share Configuration", PageTypeShare),
-		shareOptions: map[string]bool{
-			"Base URL":        false,
-			"API Key":         true,
-			"Model":           false,
-			"System Prompts":  false,
-			"Functions":       false,
-			"Chat History":    false,
-			"RAG Settings":    false,
-			"MCP Servers":     false,
-		},
 	}
+	page.selected[shareModel] = true // The API key is left out unless chosen and confirmed
 
 	w, _ := screen.Size()
 
-	// Initialize components
-	page.checkboxGroup = components.NewCheckboxGroup(screen, 5, 9, "What to share:")
-
 	// Link length bar
-	page.linkLengthBar = components.NewLinkLengthBar(screen, 5, 7, w-10)
+	page.linkLengthBar = components.NewLinkLengthBar(screen, 5, shareBarY, w-10)
 
 	// Info icon with tooltip
 	page.infoIcon = components.NewInfoIcon(screen, w-30, 3, 60, 25)
@@ -55,83 +130,150 @@ func NewSharePage(screen tcell.Screen, config *core.ConfigManager, state *core.A
 			"• Mobile devices: Keep under 1000 bytes for best compatibility\n"+
 			"• Email sharing: Under 2000 bytes to avoid truncation\n"+
 			"• SMS/messaging: Under 500 bytes recommended\n\n"+
-			"The link length bar shows the estimated size relative to browser limits.",
+			"The link length bar shows the size of the link with the current selection.",
 	)
 
-	// QR code placeholder
-	page.qrCodePlaceholder = []string{
-		"╔═══════════════╗",
-		"║ ▄▄▄▄▄ █▀█ ▄▄▄▄▄ ║",
-		"║ █   █ ███ █   █ ║",
-		"║ █▄▄▄█ █▄█ █▄▄▄█ ║",
-		"║ ▄▄▄▄▄▄▄▄▄▄▄▄▄▄▄ ║",
-		"║ █▄█▄▄▄▄▄▄▄▄█▄█ ║",
-		"║ ▄▄▄█▄▄█▄▄█▄▄▄▄▄ ║",
-		"║ ▄▄▄▄▄ █▄█▄ ▄   ║",
-		"║ █   █ ▄▄▄█▄▄▄▄▄ ║",
-		"║ █▄▄▄█ ▄█▄▄█▄▄▄▄ ║",
-		"╚═══════════════╝",
-		"  QR Code Preview  ",
-	}
-
-	// Load share configuration
-	page.loadShareConfig()
-
+	page.estimate()
 	return page
 }
 
-// loadShareConfig loads current share configuration
-func (sp *SharePage) loadShareConfig() {
-	// Clear existing checkboxes
-	sp.checkboxGroup.Clear()
+// shareConfig builds the configuration the link carries with the current selection
+func (sp *SharePage) shareConfig() *sharelink.Config {
+	cfg := sp.config.Get()
+	source := cfg.ShareSource
+	if source == nil {
+		source = &sharelink.Config{}
+	}
 
-	// Add checkboxes for each share option
-	for option, checked := range sp.shareOptions {
-		sp.checkboxGroup.AddCheckbox(option, checked)
+	// A configuration that came locked is shared on locked
+	shared := &sharelink.Config{Locked: cfg.LockedByLink, UnlockHash: cfg.UnlockHash}
+	if sp.selected[shareAPIKey] {
+		shared.APIKey = cfg.APIKey
+	}
+	if sp.selected[shareModel] {
+		shared.BaseURL = cfg.BaseURL
+		shared.Model = cfg.Model
+	}
+	if sp.selected[sharePrompts] {
+		shared.SystemPrompt = cfg.SystemPrompt
+		shared.Prompts = sharedPrompts(cfg, source)
 	}
+	if sp.selected[shareFunctions] {
+		shared.Functions = source.Functions
+		shared.DefaultFunctions = source.DefaultFunctions
+	}
+	if sp.selected[shareMCP] {
+		shared.MCPServers = source.MCPServers
+	}
+	if sp.selected[shareRAG] {
+		shared.RAGEnabled = source.RAGEnabled
+		shared.RAGDocuments = source.RAGDocuments
+	}
+	return shared
+}
 
-	// Simulate an encrypted link
-	sp.encryptedLink = "eyJlbmMiOiJVMmFsdGVkX18rK0FBQUFBQUFBQUFBQUFBQUFBQUFBQUFBQUFBQUFBQUFBQUFBQUFBQUFBQUFB..."
+// sharedPrompts merges the CLI prompt library with the prompts created in the TUI
+func sharedPrompts(cfg *core.Config, source *sharelink.Config) []sharelink.Prompt {
+	prompts := append([]sharelink.Prompt(nil), source.Prompts...)
+	seen := make(map[string]bool, len(prompts))
+	for _, prompt := range prompts {
+		seen[prompt.ID] = true
+	}
+	enabled := make(map[string]bool, len(cfg.EnabledPrompts))
+	for _, id := range cfg.EnabledPrompts {
+		enabled[id] = true
+	}
+	for _, prompt := range cfg.CustomPrompts {
+		if seen[prompt.ID] {
+			continue
+		}
+		prompts = append(prompts, sharelink.Prompt{
+			ID:      prompt.ID,
+			Name:    prompt.Name,
+			Content: prompt.Content,
+			Enabled: enabled[prompt.ID],
+			Tags:    prompt.Tags,
+		})
+	}
+	return prompts
+}
 
-	// Calculate link size based on selected options
-	sp.calculateLinkSize()
+// componentDetail describes what a component would put in the link, or "" when there is nothing
+func (sp *SharePage) componentDetail(component int) string {
+	cfg := sp.config.Get()
+	source := cfg.ShareSource
+	if source == nil {
+		source = &sharelink.Config{}
+	}
+
+	switch component {
+	case shareAPIKey:
+		if cfg.APIKey == "" {
+			return ""
+		}
+		if len(cfg.APIKey) > 12 {
+			return cfg.APIKey[:3] + "..." + cfg.APIKey[len(cfg.APIKey)-4:]
+		}
+		return "set"
+	case shareModel:
+		if cfg.Model == "" && cfg.BaseURL == "" {
+			return ""
+		}
+		host := cfg.BaseURL
+		if parsed, err := url.Parse(cfg.BaseURL); err == nil && parsed.Host != "" {
+			host = parsed.Host
+		}
+		return strings.TrimSpace(cfg.Model + " @ " + host)
+	case sharePrompts:
+		count := len(sharedPrompts(cfg, source))
+		switch {
+		case cfg.SystemPrompt != "" && count > 0:
+			return "system prompt + " + countOf(count, "prompt")
+		case cfg.SystemPrompt != "":
+			return "system prompt"
+		case count > 0:
+			return countOf(count, "prompt")
+		}
+	case shareFunctions:
+		if len(source.Functions) > 0 {
+			return countOf(len(source.Functions), "function")
+		}
+	case shareMCP:
+		if len(source.MCPServers) > 0 {
+			return countOf(len(source.MCPServers), "server")
+		}
+	case shareRAG:
+		if source.RAGEnabled {
+			return "enabled, " + countOf(len(source.RAGDocuments), "document")
+		}
+	}
+	return ""
 }
 
-// calculateLinkSize calculates the estimated link size
-func (sp *SharePage) calculateLinkSize() {
-	baseSize := 100 // Base encrypted wrapper size
-
-	// Add sizes for each selected option
-	for option, checked := range sp.shareOptions {
-		if checked {
-			switch option {
-			case "API Key":
-				baseSize += 150
-			case "Base URL":
-				baseSize += 50
-			case "Model":
-				baseSize += 30
-			case "System Prompts":
-				baseSize += 500
-			case "Functions":
-				baseSize += 800
-			case "Chat History":
-				baseSize += 1200
-			case "RAG Settings":
-				baseSize += 200
-			case "MCP Servers":
-				baseSize += 300
-			}
-		}
-	}
-
-	sp.linkBytes = baseSize
+// countOf formats a count with the noun in singular or plural
+func countOf(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// estimate measures the link the current selection makes and the QR code it needs.
+// The size doesn't depend on the password, so a placeholder one is used.
+func (sp *SharePage) estimate() {
+	link, err := sharelink.Create(sp.shareConfig(), "size-estimate", "")
+	sp.estimateOK = err == nil
+	if err != nil {
+		return
+	}
+	sp.linkBytes = len(link)
 	sp.linkLengthBar.SetBytes(sp.linkBytes)
+	sp.qrVersion, _ = qrcode.VersionFor(sp.linkBytes, qrcode.Low)
 }
 
 // Draw renders the share page
 func (sp *SharePage) Draw() {
-	w, h := sp.screen.Size()
+	_, h := sp.screen.Size()
 
 	// Clear screen
 	sp.ClearContent()
@@ -139,121 +281,234 @@ func (sp *SharePage) Draw() {
 	// Draw header
 	sp.DrawHeader()
 
-	// Draw info icon
-	sp.infoIcon.Draw()
+	switch sp.step {
+	case shareStepChoose:
+		sp.drawChoose()
+	case shareStepReview:
+		sp.drawReview()
+	case shareStepPassword:
+		sp.drawPassword()
+	case shareStepResult:
+		sp.drawResult()
+	}
 
-	// Draw main content area border
-	sp.drawContentBorder(2, 5, w-4, h-8)
+	// Draw instructions
+	sp.DrawHint(h-2, sp.Keymap(), tcell.StyleDefault.Foreground(tcell.ColorYellow))
+}
 
-	// Draw link length bar
-	sp.linkLengthBar.Draw()
+// Keymap returns the bindings of the current step
+func (sp *SharePage) Keymap() *core.Keymap {
+	switch sp.step {
+	case shareStepReview:
+		return shareReviewKeymap
+	case shareStepPassword:
+		return sharePasswordKeymap
+	case shareStepResult:
+		return shareResultKeymap
+	}
+	return shareKeymap
+}
 
-	// Draw checkbox group
-	sp.checkboxGroup.Draw()
+// drawChoose draws the component checkboxes and the size estimate
+func (sp *SharePage) drawChoose() {
+	w, _ := sp.screen.Size()
+	sp.drawText(5, 4, "1. Choose what to share", tcell.StyleDefault.Bold(true))
+	sp.infoIcon.Draw()
 
-	// Draw encrypted link preview
-	sp.drawLinkPreview()
+	for i, label := range shareComponentLabels {
+		y := shareListY + i
+		box := "[ ]"
+		if sp.selected[i] {
+			box = "[x]"
+		}
+		style := tcell.StyleDefault
+		if i == sp.cursor {
+			style = style.Reverse(true)
+		}
+		sp.drawText(5, y, fmt.Sprintf(" %d %s %-20s ", i+1, box, label), style)
 
-	// Draw QR code placeholder
-	sp.drawQRCode()
+		detail := sp.componentDetail(i)
+		detailStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+		if detail == "" {
+			detail = "nothing configured"
+			detailStyle = detailStyle.Italic(true)
+		}
+		sp.drawText(35, y, truncate(detail, w-40), detailStyle)
+	}
 
-	// Draw platform recommendations
-	sp.drawRecommendations()
+	if sp.config.Get().LockedByLink {
+		sp.drawText(5, shareListY+shareComponentCount+1, "This configuration is locked by link; the new link stays locked.",
+			tcell.StyleDefault.Foreground(tcell.ColorYellow))
+	}
 
-	// Draw instructions
-	instructions := " I:Info | ↑↓:Scroll | ESC:Back "
-	instructionStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
-	sp.DrawCenteredText(h-2, instructions, instructionStyle)
+	if !sp.estimateOK {
+		sp.drawText(5, shareBarY, "Could not estimate the link size", tcell.StyleDefault.Foreground(tcell.ColorRed))
+		return
+	}
+	sp.linkLengthBar.Draw()
+
+	qrStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+	qrText := "QR code: too long for a QR code; share the link as text"
+	if sp.qrVersion > 0 {
+		size := 4*sp.qrVersion + 17
+		qrText = fmt.Sprintf("QR code: version %d, %d×%d modules, drawn in %d×%d terminal cells",
+			sp.qrVersion, size, size, size+2*shareQuietZone, (size+2*shareQuietZone+1)/2)
+	}
+	sp.drawText(5, shareBarY+3, qrText, qrStyle)
+
+	sp.drawRecommendations(shareBarY + 5)
 }
 
-// drawContentBorder draws a border around the content area
-func (sp *SharePage) drawContentBorder(x, y, width, height int) {
-	borderStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+// drawReview lists what the link carries and what it leaves out, and asks
+// for the typed confirmation when it would carry the API key
+func (sp *SharePage) drawReview() {
+	w, _ := sp.screen.Size()
+	sp.drawText(5, 4, "2. Check what the link carries", tcell.StyleDefault.Bold(true))
 
-	// Top border
-	sp.screen.SetContent(x, y, '╭', nil, borderStyle)
-	for i := 1; i < width-1; i++ {
-		sp.screen.SetContent(x+i, y, '─', nil, borderStyle)
+	y := 6
+	contents := sharelink.Contents(sp.shareConfig())
+	if len(contents) == 0 {
+		sp.drawText(7, y, "Nothing but the password-protected envelope", tcell.StyleDefault.Italic(true))
+		y++
+	}
+	for _, part := range contents {
+		sp.drawText(7, y, truncate("• "+part, w-12), tcell.StyleDefault)
+		y++
 	}
-	sp.screen.SetContent(x+width-1, y, '╮', nil, borderStyle)
 
-	// Side borders
-	for i := 1; i < height-1; i++ {
-		sp.screen.SetContent(x, y+i, '│', nil, borderStyle)
-		sp.screen.SetContent(x+width-1, y+i, '│', nil, borderStyle)
+	var left []string
+	for i, label := range shareComponentLabels {
+		if !sp.selected[i] && sp.componentDetail(i) != "" {
+			left = append(left, label)
+		}
+	}
+	if len(left) > 0 {
+		y++
+		sp.drawText(5, y, truncate("Left out: "+strings.Join(left, ", "), w-10), tcell.StyleDefault.Foreground(tcell.ColorGray))
+		y++
 	}
 
-	// Bottom border
-	sp.screen.SetContent(x, y+height-1, '╰', nil, borderStyle)
-	for i := 1; i < width-1; i++ {
-		sp.screen.SetContent(x+i, y+height-1, '─', nil, borderStyle)
+	if sp.carriesAPIKey() {
+		y++
+		warning := tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+		sp.drawText(5, y, "⚠ The link will contain your API key.", warning)
+		sp.drawText(5, y+1, "Anyone with the link and its password can use it, and run up your bill.", tcell.StyleDefault.Foreground(tcell.ColorRed))
+		prompt := fmt.Sprintf("Type %q to embed it: ", sharelink.IncludeKeyPhrase)
+		sp.drawText(5, y+3, prompt, tcell.StyleDefault.Bold(true))
+		sp.drawText(5+len([]rune(prompt)), y+3, string(sp.includeKey)+"█", tcell.StyleDefault)
+		sp.drawText(5, y+4, "Or press ESC and unselect the API key to leave it out.", tcell.StyleDefault.Foreground(tcell.ColorGray))
+		y += 5
+	}
+
+	if sp.reviewError != "" {
+		sp.drawText(5, y+1, sp.reviewError, tcell.StyleDefault.Foreground(tcell.ColorRed))
 	}
-	sp.screen.SetContent(x+width-1, y+height-1, '╯', nil, borderStyle)
 }
 
-// drawLinkPreview draws the encrypted link preview
-func (sp *SharePage) drawLinkPreview() {
-	w, h := sp.screen.Size()
-	previewY := h/2 + 2
+// carriesAPIKey reports whether the link with the current selection would contain the API key
+func (sp *SharePage) carriesAPIKey() bool {
+	return sp.selected[shareAPIKey] && sp.config.Get().APIKey != ""
+}
 
-	// Draw label
-	label := "Encrypted Link Preview:"
-	labelStyle := tcell.StyleDefault.Bold(true)
-	for i, ch := range label {
-		sp.screen.SetContent(5+i, previewY, ch, nil, labelStyle)
+// drawPassword draws the password and confirmation fields
+func (sp *SharePage) drawPassword() {
+	sp.drawText(5, 4, "3. Choose a password", tcell.StyleDefault.Bold(true))
+	sp.drawText(5, 5, "Whoever opens the link needs it; send it separately from the link.",
+		tcell.StyleDefault.Foreground(tcell.ColorGray))
+
+	fields := []struct {
+		label   string
+		value   []rune
+		focused bool
+	}{
+		{"Password:", sp.password, !sp.confirmFocus},
+		{"Confirm: ", sp.confirm, sp.confirmFocus},
+	}
+	for i, field := range fields {
+		y := 7 + 2*i
+		labelStyle := tcell.StyleDefault
+		if field.focused {
+			labelStyle = labelStyle.Bold(true).Foreground(tcell.ColorGreen)
+		}
+		sp.drawText(5, y, field.label, labelStyle)
+		value := strings.Repeat("•", len(field.value))
+		if field.focused {
+			value += "█"
+		}
+		sp.drawText(16, y, value, tcell.StyleDefault)
 	}
 
-	// Draw link preview (truncated)
-	linkPreview := sp.encryptedLink
-	maxLen := w - 10
-	if len(linkPreview) > maxLen {
-		linkPreview = linkPreview[:maxLen-10] + "..."
+	if sp.passwordError != "" {
+		sp.drawText(5, 12, sp.passwordError, tcell.StyleDefault.Foreground(tcell.ColorRed))
 	}
+	sp.drawText(5, 14, fmt.Sprintf("The link will be about %d bytes.", sp.linkBytes),
+		tcell.StyleDefault.Foreground(tcell.ColorGray))
+}
 
+// drawResult draws the link, the copy button and the QR code
+func (sp *SharePage) drawResult() {
+	w, h := sp.screen.Size()
+	sp.drawText(5, 4, fmt.Sprintf("4. Share link (%d bytes)", len(sp.link)), tcell.StyleDefault.Bold(true))
+
+	// The start of the link; the copy button gets all of it
+	const previewLines = 3
+	width := max(w-10, 20)
 	linkStyle := tcell.StyleDefault.Foreground(tcell.ColorBlue)
-	for i, ch := range linkPreview {
-		sp.screen.SetContent(5+i, previewY+1, ch, nil, linkStyle)
+	y := 6
+	for i := 0; i < previewLines && i*width < len(sp.link); i++ {
+		line := sp.link[i*width : min((i+1)*width, len(sp.link))]
+		if i == previewLines-1 && (i+1)*width < len(sp.link) {
+			line = truncate(sp.link[i*width:], width)
+		}
+		sp.drawText(5, y, line, linkStyle)
+		y++
 	}
 
-	// Draw full URL example
-	fullURL := "https://hacka.re/#gpt=" + linkPreview
-	if len(fullURL) > maxLen {
-		fullURL = fullURL[:maxLen-10] + "..."
+	y++
+	sp.copyY = y
+	sp.drawText(5, y, shareCopyButton, tcell.StyleDefault.Background(tcell.ColorGreen).Foreground(tcell.ColorBlack).Bold(true))
+	if sp.message != "" {
+		sp.drawText(5+len(shareCopyButton)+2, y, sp.message, tcell.StyleDefault.Foreground(tcell.ColorGray))
 	}
+	y += 2
 
-	urlLabel := "Full URL:"
-	for i, ch := range urlLabel {
-		sp.screen.SetContent(5+i, previewY+3, ch, nil, labelStyle)
+	if sp.qr == nil {
+		sp.drawText(5, y, "The link is too long for a QR code; share it as text.", tcell.StyleDefault.Foreground(tcell.ColorYellow))
+		return
 	}
-
-	for i, ch := range fullURL {
-		sp.screen.SetContent(5+i, previewY+4, ch, nil, linkStyle)
+	size := sp.qr.Size + 2*shareQuietZone
+	rows := (size + 1) / 2
+	if size > w || y+rows > h-3 {
+		sp.drawText(5, y, fmt.Sprintf("Enlarge the terminal to %d×%d to show the QR code.", size, y+rows+3),
+			tcell.StyleDefault.Foreground(tcell.ColorYellow))
+		return
 	}
+	sp.drawQRCode((w-size)/2, y)
 }
 
-// drawQRCode draws the QR code placeholder
-func (sp *SharePage) drawQRCode() {
-	w, _ := sp.screen.Size()
-	qrX := w - 25
-	qrY := 10
-
-	qrStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
-
-	for i, line := range sp.qrCodePlaceholder {
-		for j, ch := range line {
-			sp.screen.SetContent(qrX+j, qrY+i, ch, nil, qrStyle)
+// drawQRCode draws the QR code with its quiet zone at x, y, two modules per
+// cell using the upper half block, in fixed colors so it scans on any theme
+func (sp *SharePage) drawQRCode(x, y int) {
+	size := sp.qr.Size + 2*shareQuietZone
+	color := func(mx, my int) tcell.Color {
+		if sp.qr.Dark(mx-shareQuietZone, my-shareQuietZone) {
+			return tcell.ColorBlack
+		}
+		return tcell.ColorWhite
+	}
+	for row := 0; row*2 < size; row++ {
+		for col := 0; col < size; col++ {
+			style := tcell.StyleDefault.Foreground(color(col, row*2)).Background(color(col, row*2+1))
+			sp.screen.SetContent(x+col, y+row, '▀', nil, style)
 		}
 	}
 }
 
-// drawRecommendations draws platform-specific recommendations
-func (sp *SharePage) drawRecommendations() {
-	_, h := sp.screen.Size()
-	recY := h - 12
-
+// drawRecommendations draws platform-specific recommendations at row recY
+func (sp *SharePage) drawRecommendations(recY int) {
 	// Draw recommendations based on link size
 	var recommendations []string
-	recStyle := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	var recStyle tcell.Style
 
 	if sp.linkBytes < 500 {
 		recommendations = []string{
@@ -286,29 +541,40 @@ func (sp *SharePage) drawRecommendations() {
 	}
 
 	// Draw recommendations title
-	title := "Platform Compatibility:"
-	titleStyle := tcell.StyleDefault.Bold(true)
-	for i, ch := range title {
-		sp.screen.SetContent(5+i, recY, ch, nil, titleStyle)
-	}
+	sp.drawText(5, recY, "Platform Compatibility:", tcell.StyleDefault.Bold(true))
 
 	// Draw recommendation items
 	for i, rec := range recommendations {
-		for j, ch := range rec {
-			sp.screen.SetContent(7+j, recY+i+1, ch, nil, recStyle)
-		}
+		sp.drawText(7, recY+i+1, rec, recStyle)
 	}
+}
 
-	// Draw size breakdown
-	breakdown := fmt.Sprintf("Total size: %d bytes", sp.linkBytes)
-	breakdownStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
-	for i, ch := range breakdown {
-		sp.screen.SetContent(5+i, recY+len(recommendations)+2, ch, nil, breakdownStyle)
+// drawText draws text rune by rune, so multi-byte characters take one cell
+func (sp *SharePage) drawText(x, y int, text string, style tcell.Style) {
+	for i, r := range []rune(text) {
+		sp.screen.SetContent(x+i, y, r, nil, style)
 	}
 }
 
 // HandleInput processes keyboard input
 func (sp *SharePage) HandleInput(ev *tcell.EventKey) bool {
+	switch sp.step {
+	case shareStepReview:
+		sp.handleReviewInput(ev)
+		return false
+	case shareStepPassword:
+		sp.handlePasswordInput(ev)
+		return false
+	case shareStepResult:
+		switch {
+		case ev.Key() == tcell.KeyEscape:
+			sp.reset()
+		case ev.Key() == tcell.KeyRune && (ev.Rune() == 'c' || ev.Rune() == 'C'):
+			sp.copyLink()
+		}
+		return false
+	}
+
 	switch ev.Key() {
 	case tcell.KeyEscape:
 		// Hide info tooltip if visible, otherwise exit
@@ -318,43 +584,164 @@ func (sp *SharePage) HandleInput(ev *tcell.EventKey) bool {
 		}
 		return true // Exit the page
 
-	case tcell.KeyUp, tcell.KeyDown:
-		// Scroll through content
-		return false
+	case tcell.KeyUp:
+		if sp.cursor > 0 {
+			sp.cursor--
+		}
+	case tcell.KeyDown:
+		if sp.cursor < shareComponentCount-1 {
+			sp.cursor++
+		}
+	case tcell.KeyEnter:
+		if sp.estimateOK {
+			sp.step = shareStepReview
+		}
 
 	case tcell.KeyRune:
-		switch ev.Rune() {
-		case 'i', 'I':
+		switch r := ev.Rune(); {
+		case r == 'i' || r == 'I':
 			// Toggle info tooltip
 			sp.infoIcon.HandleInput(ev)
-			return false
+		case r == ' ':
+			sp.toggle(sp.cursor)
+		case r >= '1' && r < '1'+shareComponentCount:
+			sp.cursor = int(r - '1')
+			sp.toggle(sp.cursor)
+		}
+	}
+	return false
+}
 
-		case '1', '2', '3', '4', '5', '6', '7', '8':
-			// Simulate toggling checkboxes (read-only, so just for show)
-			idx := int(ev.Rune() - '1')
-			options := []string{"Base URL", "API Key", "Model", "System Prompts",
-							  "Functions", "Chat History", "RAG Settings", "MCP Servers"}
-			if idx < len(options) {
-				option := options[idx]
-				sp.shareOptions[option] = !sp.shareOptions[option]
-				sp.loadShareConfig()
-				sp.calculateLinkSize()
-			}
-			return false
+// handleReviewInput edits the API key confirmation and moves on to the
+// password on Enter, once the key is confirmed or not in the link
+func (sp *SharePage) handleReviewInput(ev *tcell.EventKey) {
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		sp.clearReview()
+		sp.step = shareStepChoose
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(sp.includeKey) > 0 {
+			sp.includeKey = sp.includeKey[:len(sp.includeKey)-1]
+		}
+	case tcell.KeyRune:
+		if sp.carriesAPIKey() {
+			sp.includeKey = append(sp.includeKey, ev.Rune())
+			sp.reviewError = ""
+		}
+	case tcell.KeyEnter:
+		if sp.carriesAPIKey() && strings.TrimSpace(string(sp.includeKey)) != sharelink.IncludeKeyPhrase {
+			sp.reviewError = fmt.Sprintf("Type %q exactly to embed the API key", sharelink.IncludeKeyPhrase)
+			sp.includeKey = nil
+			return
 		}
+		sp.step = shareStepPassword
 	}
+}
 
-	return false
+// clearReview forgets the typed confirmation
+func (sp *SharePage) clearReview() {
+	sp.includeKey = nil
+	sp.reviewError = ""
+}
+
+// handlePasswordInput edits the password fields and creates the link on Enter
+func (sp *SharePage) handlePasswordInput(ev *tcell.EventKey) {
+	field := &sp.password
+	if sp.confirmFocus {
+		field = &sp.confirm
+	}
+
+	switch ev.Key() {
+	case tcell.KeyEscape:
+		sp.clearPassword()
+		sp.step = shareStepReview
+	case tcell.KeyTab, tcell.KeyBacktab, tcell.KeyUp, tcell.KeyDown:
+		sp.confirmFocus = !sp.confirmFocus
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(*field) > 0 {
+			*field = (*field)[:len(*field)-1]
+		}
+	case tcell.KeyRune:
+		*field = append(*field, ev.Rune())
+		sp.passwordError = ""
+	case tcell.KeyEnter:
+		if !sp.confirmFocus {
+			sp.confirmFocus = true
+			return
+		}
+		sp.createLink()
+	}
+}
+
+// createLink checks the password and encrypts the link
+func (sp *SharePage) createLink() {
+	switch {
+	case len(sp.password) == 0:
+		sp.passwordError = "The password can't be empty"
+		sp.confirmFocus = false
+		return
+	case string(sp.password) != string(sp.confirm):
+		sp.passwordError = "The passwords don't match"
+		sp.confirm = nil
+		return
+	}
+
+	shared := sp.shareConfig()
+	if strings.TrimSpace(string(sp.includeKey)) != sharelink.IncludeKeyPhrase {
+		shared.APIKey = "" // Never without the confirmation, whatever led here
+	}
+	link, err := sharelink.Create(shared, string(sp.password), "")
+	if err != nil {
+		sp.passwordError = "Failed to create the link: " + err.Error()
+		return
+	}
+	sp.link = link
+	sp.qr, _ = qrcode.Encode([]byte(link), qrcode.Low) // nil when too long; the link is still shown
+	sp.message = ""
+	sp.clearPassword()
+	sp.clearReview()
+	sp.step = shareStepResult
+}
+
+// copyLink puts the link on the system clipboard
+func (sp *SharePage) copyLink() {
+	if err := utils.SetClipboardContent(sp.link); err != nil {
+		sp.message = "Copy failed: " + err.Error()
+		return
+	}
+	sp.message = "Copied to the clipboard"
+}
+
+// toggle flips a component and updates the estimate
+func (sp *SharePage) toggle(component int) {
+	sp.selected[component] = !sp.selected[component]
+	sp.estimate()
+}
+
+// clearPassword forgets the typed passwords
+func (sp *SharePage) clearPassword() {
+	sp.password = nil
+	sp.confirm = nil
+	sp.confirmFocus = false
+	sp.passwordError = ""
+}
+
+// reset goes back to the first step, keeping the selection
+func (sp *SharePage) reset() {
+	sp.link = ""
+	sp.qr = nil
+	sp.message = ""
+	sp.step = shareStepChoose
+	sp.estimate()
 }
 
 // OnActivate is called when the page becomes active
 func (sp *SharePage) OnActivate() {
-	sp.loadShareConfig()
+	sp.estimate()
 }
 
-// Save saves any changes (no-op for read-only page)
+// Save saves any changes (no-op, the page only creates links)
 func (sp *SharePage) Save() error {
-	// Read-only page, nothing to save
 	return nil
 }
 
@@ -368,9 +755,24 @@ func truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
-// HandleMouse processes mouse events for the page
-func (p *SharePage) HandleMouse(event *core.MouseEvent) bool {
-	// TODO: Implement mouse support for interactive elements
-	// For now, just return false to indicate event not handled
+
+// HandleMouse toggles components on click and copies the link from the button
+func (sp *SharePage) HandleMouse(event *core.MouseEvent) bool {
+	if event.Type != core.MouseEventClick {
+		return false
+	}
+	switch sp.step {
+	case shareStepChoose:
+		if row := event.Y - shareListY; row >= 0 && row < shareComponentCount && event.X >= 5 {
+			sp.cursor = row
+			sp.toggle(row)
+			return true
+		}
+	case shareStepResult:
+		if event.Y == sp.copyY && event.X >= 5 && event.X < 5+len(shareCopyButton) {
+			sp.copyLink()
+			return true
+		}
+	}
 	return false
 }
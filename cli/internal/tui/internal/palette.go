@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/hacka-re/cli/internal/tags"
+	"github.com/hacka-re/cli/internal/tui/internal/components"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+	"github.com/hacka-re/cli/internal/tui/internal/pages"
+	"github.com/hacka-re/cli/internal/tui/internal/services"
+)
+
+// openPalette shows the Ctrl+P palette over the current panel
+func (a *App) openPalette() {
+	a.palette = components.NewPalette(a.screen, a.paletteEntries())
+	a.needsRedraw = true
+}
+
+// paletteEntries lists everything the palette can jump to: pages, settings
+// fields, prompts, functions, models of the current provider and the chat
+// session. Favorites are listed first.
+func (a *App) paletteEntries() []components.PaletteEntry {
+	page := func(title, detail string, panel Panel, show func() error) components.PaletteEntry {
+		return components.PaletteEntry{Kind: "Page", Title: title, Detail: detail, Action: func() error {
+			a.currentPanel = panel
+			return show()
+		}}
+	}
+	entries := []components.PaletteEntry{
+		page("Main Menu", "", PanelMainMenu, func() error { return nil }),
+		page("Chat", "interactive chat session", PanelChat, a.showChat),
+		page("Settings", "provider, API key, model", PanelSettings, a.showSettings),
+		page("System Prompts", "create and manage prompts", PanelPrompts, a.showPrompts),
+		page("Functions", "callable functions", PanelFunctions, a.showFunctions),
+		page("MCP Servers", "server connections", PanelMCP, a.showMCP),
+		page("RAG Configuration", "retrieval settings", PanelRAG, a.showRAG),
+		page("Memory", "long-term facts", PanelMemory, a.showMemory),
+		page("Playground", "try out JavaScript functions", PanelPlayground, a.showPlayground),
+	}
+	if !a.config.Get().Kiosk {
+		entries = append(entries, page("Share Configuration", "encrypted share link", PanelShare, a.generateShareLink))
+	}
+	if a.templatesAllowed() {
+		entries = append(entries, a.templateEntries()...)
+	}
+
+	// Settings fields, and the models offered by the model field
+	settings := pages.NewSettingsModal(a.screen, a.config, a.state, a.eventBus)
+	var modelOptions []string
+	for _, field := range settings.Fields() {
+		key := field.Key
+		entries = append(entries, components.PaletteEntry{
+			Kind: "Setting", Title: field.Label, Detail: key,
+			Action: func() error { return a.jumpToSetting(key) },
+		})
+		if key == "model" {
+			modelOptions = field.Options
+		}
+	}
+
+	cfg := a.config.Get()
+	for _, model := range modelOptions {
+		model := model
+		detail := cfg.Provider
+		if model == cfg.Model {
+			detail += " (current)"
+		}
+		entries = append(entries, components.PaletteEntry{
+			Kind: "Model", Title: model, Detail: detail,
+			Favorite: cfg.IsFavorite(core.FavoriteModel, model),
+			Action:   func() error { return a.selectModel(model) },
+		})
+	}
+
+	for _, prompt := range services.NewPromptsService(a.config).All() {
+		id := prompt.ID
+		entries = append(entries, components.PaletteEntry{
+			Kind: "Prompt", Title: prompt.Name, Detail: tags.String(prompt.Tags),
+			Favorite: cfg.IsFavorite(core.FavoritePrompt, id),
+			Action: func() error {
+				a.currentPanel = PanelPrompts
+				if err := a.showPrompts(); err != nil {
+					return err
+				}
+				a.promptsPage.OpenPrompt(id)
+				return nil
+			},
+		})
+	}
+
+	functions := a.functionsPage
+	if functions == nil {
+		functions = pages.NewFunctionsPage(a.screen, a.config, a.state, a.eventBus)
+	}
+	for _, name := range functions.FunctionNames() {
+		name := name
+		entries = append(entries, components.PaletteEntry{
+			Kind: "Function", Title: name,
+			Favorite: cfg.IsFavorite(core.FavoriteFunction, name),
+			Action: func() error {
+				a.currentPanel = PanelFunctions
+				if err := a.showFunctions(); err != nil {
+					return err
+				}
+				a.functionsPage.SelectFunction(name)
+				return nil
+			},
+		})
+	}
+
+	// The TUI keeps one chat session per run, stored under the namespace
+	entries = append(entries, components.PaletteEntry{
+		Kind:   "Session",
+		Title:  "Current chat",
+		Detail: fmt.Sprintf("%s · %d message(s)", cfg.Namespace, len(a.state.GetMessages())),
+		Action: func() error {
+			a.currentPanel = PanelChat
+			return a.showChat()
+		},
+	})
+	return entries
+}
+
+// jumpToSetting opens the settings with the field of the given key selected
+func (a *App) jumpToSetting(key string) error {
+	a.currentPanel = PanelSettings
+	if err := a.showSettings(); err != nil {
+		return err
+	}
+	a.settingsModal.SelectKey(key)
+	return nil
+}
+
+// selectModel makes model the active model and shows it in the settings
+func (a *App) selectModel(model string) error {
+	a.config.Update(func(cfg *core.Config) {
+		cfg.Model = model
+	})
+	if err := a.config.Save(); err != nil {
+		return fmt.Errorf("failed to save model: %w", err)
+	}
+	a.eventBus.PublishAsync(core.EventConfigChanged, a.config.Get())
+	return a.jumpToSetting("model")
+}
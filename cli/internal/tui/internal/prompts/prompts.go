@@ -6,6 +6,7 @@ type DefaultPrompt struct {
 	Name        string
 	Content     string
 	Description string
+	Tags        []string
 }
 
 // MCPPrompt represents an MCP server-specific prompt
@@ -15,6 +16,7 @@ type MCPPrompt struct {
 	Content     string
 	Description string
 	MCPServer   string // Which MCP server this prompt is for
+	Tags        []string
 }
 
 // GetDefaultPrompts returns all built-in default prompts
@@ -25,18 +27,21 @@ func GetDefaultPrompts() []DefaultPrompt {
 			Name:        "README.md",
 			Content:     ReadmePromptContent,
 			Description: "The hacka.re project documentation and guide",
+			Tags:        []string{"docs", "hacka-re"},
 		},
 		{
 			ID:          "owasp-llm-top10",
 			Name:        "OWASP Top 10 for LLM Applications",
 			Content:     OWASPPromptContent,
 			Description: "OWASP Top 10 security risks for Large Language Model applications",
+			Tags:        []string{"security", "owasp"},
 		},
 		{
 			ID:          "llm-security-literacy",
 			Name:        "LLM Security Literacy",
 			Content:     SecurityLiteracyContent,
 			Description: "LLM Security Literacy insights from Sec-T conference 2025",
+			Tags:        []string{"security", "awareness"},
 		},
 	}
 }
@@ -50,6 +55,7 @@ func GetMCPPrompts() []MCPPrompt {
 			Content:     GmailMCPContent,
 			Description: "Gmail Integration Assistant for email management",
 			MCPServer:   "gmail",
+			Tags:        []string{"mcp", "email"},
 		},
 		{
 			ID:          "github-integration-guide",
@@ -57,6 +63,7 @@ func GetMCPPrompts() []MCPPrompt {
 			Content:     GitHubMCPContent,
 			Description: "GitHub Development Assistant for repository management",
 			MCPServer:   "github",
+			Tags:        []string{"mcp", "dev"},
 		},
 		{
 			ID:          "shodan-integration-guide",
@@ -64,6 +71,7 @@ func GetMCPPrompts() []MCPPrompt {
 			Content:     ShodanMCPContent,
 			Description: "Shodan OSINT & Cybersecurity Assistant",
 			MCPServer:   "shodan",
+			Tags:        []string{"mcp", "osint", "security"},
 		},
 		{
 			ID:          "share-link-mcp-guide",
@@ -71,6 +79,7 @@ func GetMCPPrompts() []MCPPrompt {
 			Content:     ShareLinkMCPContent,
 			Description: "Share Link MCP functionality guide",
 			MCPServer:   "share-link",
+			Tags:        []string{"mcp", "sharing"},
 		},
 		{
 			ID:          "introspection-mcp-guide",
@@ -78,6 +87,7 @@ func GetMCPPrompts() []MCPPrompt {
 			Content:     IntrospectionMCPContent,
 			Description: "Introspection MCP tools for code exploration",
 			MCPServer:   "introspection",
+			Tags:        []string{"mcp", "dev"},
 		},
 	}
 }
\ No newline at end of file
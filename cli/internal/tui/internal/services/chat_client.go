@@ -3,22 +3,34 @@ package services
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/inspect"
 	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/netguard"
+	"github.com/hacka-re/cli/internal/sampling"
+	"github.com/hacka-re/cli/internal/tracing"
 	"github.com/hacka-re/cli/internal/tui/internal/core"
 )
 
 // ChatClient handles API communication for chat
 type ChatClient struct {
-	config *core.ConfigManager
-	client *http.Client
+	config    *core.ConfigManager
+	client    *http.Client
+	inspector inspect.Recorder // The last response, for the response panel
+
+	samplingMu sync.Mutex
+	sampling   sampling.Override // Of the conversation, e.g. from a template
 }
 
 // NewChatClient creates a new chat client
@@ -26,7 +38,8 @@ func NewChatClient(config *core.ConfigManager) *ChatClient {
 	return &ChatClient{
 		config: config,
 		client: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   60 * time.Second,
+			Transport: newOfflineGuard(config),
 		},
 	}
 }
@@ -45,17 +58,108 @@ type ChatRequest struct {
 	TopP               float32       `json:"top_p,omitempty"`
 	FrequencyPenalty   float32       `json:"frequency_penalty,omitempty"`
 	PresencePenalty    float32       `json:"presence_penalty,omitempty"`
+	Seed               int           `json:"seed,omitempty"`
+	Options            map[string]interface{} `json:"options,omitempty"` // Ollama runtime options
 }
 
 // ChatMessage represents a message in the chat
 type ChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"-"` // data: URLs, sent as image parts for vision models
+}
+
+// contentPart is one part of a message with images, in the OpenAI format
+type contentPart struct {
+	Type     string    `json:"type"` // text or image_url
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+// MarshalJSON sends a message with images as a list of content parts, and
+// other messages with plain text content
+func (m ChatMessage) MarshalJSON() ([]byte, error) {
+	if len(m.Images) == 0 {
+		type plain ChatMessage
+		return json.Marshal(plain(m))
+	}
+	parts := []contentPart{{Type: "text", Text: m.Content}}
+	for _, url := range m.Images {
+		parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: url}})
+	}
+	return json.Marshal(struct {
+		Role    string        `json:"role"`
+		Content []contentPart `json:"content"`
+	}{m.Role, parts})
 }
 
 // StreamCompletion sends a streaming chat completion request
 func (c *ChatClient) StreamCompletion(messages []ChatMessage, callback StreamingCallback) error {
+	return c.StreamCompletionWithContext(context.Background(), messages, callback)
+}
+
+// StreamCompletionWithContext sends a streaming chat completion request that can be cancelled via ctx.
+// If the connection drops mid-response, it reconnects and asks the model to continue from the received prefix.
+func (c *ChatClient) StreamCompletionWithContext(ctx context.Context, messages []ChatMessage, callback StreamingCallback) (err error) {
+	var received strings.Builder
+
 	config := c.config.Get()
+	ctx, span := tracing.Start(ctx, "chat.completion")
+	span.SetAttribute("llm.provider", config.Provider)
+	span.SetAttribute("llm.model", config.Model)
+	span.SetAttribute("llm.messages", len(messages))
+	defer func() { span.End(err) }()
+
+	for attempt := 0; ; attempt++ {
+		requestMessages := messages
+		if received.Len() > 0 {
+			requestMessages = continuationMessages(messages, received.String())
+		}
+
+		span.SetAttribute("stream.attempts", attempt+1)
+		partial, err := c.streamOnce(ctx, requestMessages, callback)
+		received.WriteString(partial)
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			if suggestion := c.ProbeBaseURL(ctx); suggestion != "" {
+				return fmt.Errorf("%w (the Base URL should probably be %s; test the connection in Settings to fix it)", err, suggestion)
+			}
+		}
+		if err == nil || !errors.Is(err, api.ErrStreamInterrupted) || ctx.Err() != nil {
+			return err
+		}
+		if attempt >= api.MaxStreamResumeAttempts {
+			return fmt.Errorf("%w after %d reconnect attempts", err, attempt)
+		}
+
+		if log := logger.Get(); log != nil {
+			log.Warn("[ChatClient] Stream interrupted after %d chars, reconnecting (attempt %d)", received.Len(), attempt+1)
+		}
+
+		// Back off a little before reconnecting
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * time.Second):
+		}
+	}
+}
+
+// streamOnce performs a single streaming request and returns the content received.
+// It returns api.ErrStreamInterrupted if the stream ends before the completion marker.
+func (c *ChatClient) streamOnce(ctx context.Context, messages []ChatMessage, callback StreamingCallback) (content string, err error) {
+	config := c.config.Get()
+
+	_, span := tracing.Start(ctx, "provider.stream")
+	defer func() {
+		span.SetAttribute("stream.chars", len(content))
+		span.End(err)
+	}()
 
 	// Log start of streaming
 	if log := logger.Get(); log != nil {
@@ -63,51 +167,35 @@ func (c *ChatClient) StreamCompletion(messages []ChatMessage, callback Streaming
 		log.Debug("[ChatClient] Provider: %s, Model: %s, Messages: %d", config.Provider, config.Model, len(messages))
 	}
 
+	if config.Provider == core.ProviderCustomProtocol {
+		return c.streamProtocol(ctx, config, messages, callback)
+	}
+
 	// Build the request with model-specific compatibility
 	reqBody := c.buildCompatibleRequest(config, messages)
+	applySampling(ctx, &reqBody)
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
 		if log := logger.Get(); log != nil {
 			log.Error("[ChatClient] Failed to marshal request: %v", err)
 		}
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Determine the API endpoint
 	apiURL := c.getAPIEndpoint(config)
 
+	// Keep what comes back for the response panel
+	c.inspector.Begin(apiURL)
+	defer func() { c.inspector.End(err) }()
+
 	// Validate offline mode - check based on request type and allowances
 	if err := validateOfflineRequest(apiURL, config); err != nil {
 		if log := logger.Get(); log != nil {
 			log.Error("[ChatClient] Offline mode violation: %v", err)
 		}
-		return fmt.Errorf("offline mode violation: %w", err)
-	}
-
-	// Create the request
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-
-	// Set authentication based on provider
-	switch config.Provider {
-	case "openai", "groq":
-		req.Header.Set("Authorization", "Bearer "+config.APIKey)
-	case "anthropic":
-		req.Header.Set("x-api-key", config.APIKey)
-		req.Header.Set("anthropic-version", "2023-06-01")
-	case "ollama":
-		// Ollama doesn't require authentication
-	default:
-		// Custom provider - use Bearer token if API key is provided
-		if config.APIKey != "" {
-			req.Header.Set("Authorization", "Bearer "+config.APIKey)
-		}
+		return "", fmt.Errorf("offline mode violation: %w", err)
 	}
 
 	// Log request details
@@ -115,22 +203,46 @@ func (c *ChatClient) StreamCompletion(messages []ChatMessage, callback Streaming
 		log.Debug("[ChatClient] Sending request to: %s", apiURL)
 	}
 
-	// Send the request
-	resp, err := c.client.Do(req)
-	if err != nil {
+	// With several API keys, a rate-limited request is retried once per other key
+	var resp *http.Response
+	for attempt := 0; ; attempt++ {
+		key := sharedKeyRing.pick(config)
+		req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(jsonBody))
+		if err != nil {
+			return "", fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		setAuthHeaders(req, config.Provider, key)
+		setProviderHeaders(req, config)
+
+		resp, err = c.client.Do(req)
+		if err != nil {
+			if log := logger.Get(); log != nil {
+				log.Error("[ChatClient] Failed to send request: %v", err)
+			}
+			return "", fmt.Errorf("failed to send request: %w", err)
+		}
+		c.inspector.Status(resp.StatusCode, resp.Header)
+		if resp.StatusCode != http.StatusTooManyRequests {
+			break
+		}
+		if !sharedKeyRing.rateLimited(config, key) || attempt+1 >= len(config.APIKeys()) {
+			break
+		}
+		resp.Body.Close()
 		if log := logger.Get(); log != nil {
-			log.Error("[ChatClient] Failed to send request: %v", err)
+			log.Warn("[ChatClient] API key %s is rate limited, retrying with the next key", maskKey(key))
 		}
-		return fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		c.inspector.Chunk(string(body))
 		if log := logger.Get(); log != nil {
 			log.Error("[ChatClient] API error (status %d): %s", resp.StatusCode, string(body))
 		}
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	if log := logger.Get(); log != nil {
@@ -141,6 +253,8 @@ func (c *ChatClient) StreamCompletion(messages []ChatMessage, callback Streaming
 	scanner := bufio.NewScanner(resp.Body)
 	chunkCount := 0
 	totalContent := 0
+	completed := false
+	var received strings.Builder
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -149,6 +263,7 @@ func (c *ChatClient) StreamCompletion(messages []ChatMessage, callback Streaming
 		if line == "" {
 			continue
 		}
+		c.inspector.Chunk(line)
 
 		// Check for error response in body
 		// Some providers (e.g., Berget) return errors with 200 status code
@@ -166,7 +281,7 @@ func (c *ChatClient) StreamCompletion(messages []ChatMessage, callback Streaming
 				if log := logger.Get(); log != nil {
 					log.Error("[ChatClient] %s", errMsg)
 				}
-				return fmt.Errorf(errMsg)
+				return "", fmt.Errorf(errMsg)
 			}
 		}
 
@@ -179,6 +294,7 @@ func (c *ChatClient) StreamCompletion(messages []ChatMessage, callback Streaming
 				if log := logger.Get(); log != nil {
 					log.Info("[ChatClient] Stream complete - chunks: %d, total chars: %d", chunkCount, totalContent)
 				}
+				completed = true
 				callback("", true)
 				break
 			}
@@ -197,11 +313,12 @@ func (c *ChatClient) StreamCompletion(messages []ChatMessage, callback Streaming
 			if content != "" {
 				chunkCount++
 				totalContent += len(content)
+				received.WriteString(content)
 				if err := callback(content, false); err != nil {
 					if log := logger.Get(); log != nil {
 						log.Error("[ChatClient] Callback error: %v", err)
 					}
-					return err
+					return received.String(), err
 				}
 			}
 		}
@@ -211,14 +328,68 @@ func (c *ChatClient) StreamCompletion(messages []ChatMessage, callback Streaming
 		if log := logger.Get(); log != nil {
 			log.Error("[ChatClient] Error reading stream: %v", err)
 		}
-		return fmt.Errorf("error reading stream: %w", err)
+		if ctx.Err() != nil {
+			return received.String(), fmt.Errorf("error reading stream: %w", err)
+		}
+		return received.String(), fmt.Errorf("%w: %v", api.ErrStreamInterrupted, err)
+	}
+
+	if !completed {
+		if log := logger.Get(); log != nil {
+			log.Warn("[ChatClient] Stream ended without [DONE] after %d chars", received.Len())
+		}
+		return received.String(), api.ErrStreamInterrupted
 	}
 
 	if log := logger.Get(); log != nil {
 		log.Info("[ChatClient] Streaming completed successfully")
 	}
 
-	return nil
+	return received.String(), nil
+}
+
+// LastResponse returns what came back for the last request; false if none was sent
+func (c *ChatClient) LastResponse() (inspect.Response, bool) {
+	return c.inspector.Last()
+}
+
+// setAuthHeaders authenticates a request with key the way the provider expects
+func setAuthHeaders(req *http.Request, provider, key string) {
+	switch provider {
+	case "openai", "groq":
+		req.Header.Set("Authorization", "Bearer "+key)
+	case "anthropic":
+		req.Header.Set("x-api-key", key)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	case "ollama":
+		// Ollama doesn't require authentication
+	default:
+		// Custom provider - use Bearer token if API key is provided
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+	}
+}
+
+// setProviderHeaders adds the custom headers configured for the provider.
+// They are set after authentication so a gateway can replace the defaults.
+func setProviderHeaders(req *http.Request, config *core.Config) {
+	for name, value := range config.ProviderHeaders[config.Provider] {
+		if name != "" {
+			req.Header.Set(name, value)
+		}
+	}
+}
+
+// continuationMessages appends the partial reply and a request to continue it
+func continuationMessages(messages []ChatMessage, prefix string) []ChatMessage {
+	result := make([]ChatMessage, 0, len(messages)+2)
+	result = append(result, messages...)
+	result = append(result,
+		ChatMessage{Role: "assistant", Content: prefix},
+		ChatMessage{Role: "user", Content: api.ContinuationPrompt},
+	)
+	return result
 }
 
 // extractContent extracts content from a streaming chunk based on provider
@@ -320,9 +491,70 @@ func (c *ChatClient) buildCompatibleRequest(config *core.Config, messages []Chat
 		req.Temperature = float32(config.Temperature)
 	}
 
+	req.Seed = config.Seed
+	if options, ok := config.LocalRuntimeOptions(); ok {
+		applyLocalRuntime(&req, config.Provider, options)
+	}
+	if !fixedTemperature(modelName) {
+		c.applySessionSampling(&req)
+	}
+
 	return req
 }
 
+// SetSampling replaces the configured temperature and top_p in the requests
+// that follow, until it is called with an empty override
+func (c *ChatClient) SetSampling(override sampling.Override) {
+	c.samplingMu.Lock()
+	defer c.samplingMu.Unlock()
+	c.sampling = override
+}
+
+// Sampling returns the override set with SetSampling
+func (c *ChatClient) Sampling() sampling.Override {
+	c.samplingMu.Lock()
+	defer c.samplingMu.Unlock()
+	return c.sampling
+}
+
+// applySessionSampling puts the conversation's sampling overrides in a
+// request. Those of a single request, set with WithSampling, come after.
+func (c *ChatClient) applySessionSampling(req *ChatRequest) {
+	override := c.Sampling()
+	if override.Temperature != nil {
+		req.Temperature = float32(*override.Temperature)
+		if req.Options != nil {
+			req.Options["temperature"] = *override.Temperature
+		}
+	}
+	if override.TopP != nil {
+		req.TopP = float32(*override.TopP)
+	}
+}
+
+// applyLocalRuntime adds the runtime options of a local model to a request.
+// Ollama takes them per request; llama.cpp based servers such as llamafile fix
+// the context size and GPU layers at startup, so they only get the temperature.
+func applyLocalRuntime(req *ChatRequest, provider string, options core.LocalRuntimeOptions) {
+	if options.Temperature != nil {
+		req.Temperature = float32(*options.Temperature)
+	}
+	if provider != "ollama" || options.IsZero() {
+		return
+	}
+
+	req.Options = make(map[string]interface{})
+	if options.ContextSize > 0 {
+		req.Options["num_ctx"] = options.ContextSize
+	}
+	if options.GPULayers != nil {
+		req.Options["num_gpu"] = *options.GPULayers
+	}
+	if options.Temperature != nil {
+		req.Options["temperature"] = *options.Temperature
+	}
+}
+
 // getAPIEndpoint returns the appropriate API endpoint based on provider
 func (c *ChatClient) getAPIEndpoint(config *core.Config) string {
 	// Use the same logic as our working API client
@@ -344,6 +576,12 @@ func (c *ChatClient) getAPIEndpoint(config *core.Config) string {
 		}
 		return baseURL + "/api/chat"
 
+	case core.ProviderCustomProtocol:
+		if config.Protocol != nil {
+			return baseURL + config.Protocol.Path
+		}
+		return baseURL
+
 	default:
 		// Custom provider - assume OpenAI-compatible
 		return baseURL + "/chat/completions"
@@ -425,7 +663,7 @@ func validateOfflineURL(urlStr string) error {
 // validateOfflineRequest validates a request based on offline mode and allowances
 func validateOfflineRequest(urlStr string, config *core.Config) error {
 	// Not in offline mode, allow all
-	if !config.IsOfflineMode {
+	if !config.IsOfflineMode && !netguard.Offline() {
 		return nil
 	}
 
@@ -453,5 +691,9 @@ func validateOfflineRequest(urlStr string, config *core.Config) error {
 	}
 
 	// Validate that URL is localhost
-	return validateOfflineURL(urlStr)
+	if err := validateOfflineURL(urlStr); err != nil {
+		netguard.RecordViolation(urlStr)
+		return err
+	}
+	return nil
 }
\ No newline at end of file
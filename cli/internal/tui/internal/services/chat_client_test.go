@@ -0,0 +1,25 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChatMessageImagesAreContentParts(t *testing.T) {
+	data, err := json.Marshal(ChatMessage{Role: "user", Content: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"role":"user","content":"hi"}` {
+		t.Errorf("expected plain text content, got %s", data)
+	}
+
+	data, err = json.Marshal(ChatMessage{Role: "user", Content: "what is this?", Images: []string{"data:image/png;base64,AAAA"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}`
+	if string(data) != want {
+		t.Errorf("expected content parts\n got %s\nwant %s", data, want)
+	}
+}
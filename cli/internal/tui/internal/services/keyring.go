@@ -0,0 +1,104 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// KeyUsage is the usage of one API key since the TUI started
+type KeyUsage struct {
+	Key         string // Masked, only the last characters are shown
+	Requests    int
+	RateLimited int // Requests answered with 429
+	LastUsed    time.Time
+}
+
+// keyRing picks the API key of each chat request and counts how each key is used.
+// It is shared by all chat clients so rotation and accounting span the whole app.
+type keyRing struct {
+	mu      sync.Mutex
+	current map[string]int       // Provider -> index of the key to use next
+	usage   map[string]*KeyUsage // Provider + key -> usage
+}
+
+var sharedKeyRing = &keyRing{
+	current: make(map[string]int),
+	usage:   make(map[string]*KeyUsage),
+}
+
+// pick returns the key the next request of the configured provider should use
+func (r *keyRing) pick(config *core.Config) string {
+	keys := config.APIKeys()
+	if len(keys) == 0 {
+		return ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := r.current[config.Provider] % len(keys)
+	if config.KeyRotation == core.KeyRotationRoundRobin {
+		r.current[config.Provider] = index + 1
+	}
+	key := keys[index]
+
+	usage := r.usageOf(config.Provider, key)
+	usage.Requests++
+	usage.LastUsed = time.Now()
+	return key
+}
+
+// rateLimited records a 429 for key and moves the provider on to the next key.
+// It reports whether another key is available to retry with.
+func (r *keyRing) rateLimited(config *core.Config, key string) bool {
+	keys := config.APIKeys()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.usageOf(config.Provider, key).RateLimited++
+	if len(keys) < 2 {
+		return false
+	}
+	for i, k := range keys {
+		if k == key {
+			r.current[config.Provider] = i + 1
+			break
+		}
+	}
+	return true
+}
+
+// usageOf returns the usage entry of a key, creating it (call with mu held)
+func (r *keyRing) usageOf(provider, key string) *KeyUsage {
+	id := provider + "\x00" + key
+	usage, ok := r.usage[id]
+	if !ok {
+		usage = &KeyUsage{Key: maskKey(key)}
+		r.usage[id] = usage
+	}
+	return usage
+}
+
+// KeyUsageFor returns the usage of each key of the configured provider, in key order
+func KeyUsageFor(config *core.Config) []KeyUsage {
+	sharedKeyRing.mu.Lock()
+	defer sharedKeyRing.mu.Unlock()
+
+	keys := config.APIKeys()
+	usage := make([]KeyUsage, 0, len(keys))
+	for _, key := range keys {
+		usage = append(usage, *sharedKeyRing.usageOf(config.Provider, key))
+	}
+	return usage
+}
+
+// maskKey hides all but the last four characters of an API key
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return "••••"
+	}
+	return "••••" + key[len(key)-4:]
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+func TestStreamRotatesKeyOnRateLimit(t *testing.T) {
+	var used []string
+	client, cm := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		used = append(used, key)
+		if key == "key-one" {
+			http.Error(w, `{"error":"rate limited"}`, http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n"))
+	})
+	cm.Update(func(cfg *core.Config) {
+		cfg.Provider = "rotation-test"
+		cfg.APIKey = "key-one"
+		cfg.ExtraAPIKeys = map[string][]string{"rotation-test": {"key-two"}}
+		cfg.KeyRotation = core.KeyRotationOn429
+	})
+
+	reply, err := client.SendMessage([]ChatMessage{{Role: "user", Content: "hello"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reply != "hi" {
+		t.Errorf("expected reply %q, got %q", "hi", reply)
+	}
+	if len(used) != 2 || used[0] != "key-one" || used[1] != "key-two" {
+		t.Errorf("expected a retry with the second key, got %v", used)
+	}
+
+	// The rate-limited key is skipped from now on
+	if _, err := client.SendMessage([]ChatMessage{{Role: "user", Content: "again"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if used[2] != "key-two" {
+		t.Errorf("expected the second key to stay in use, got %v", used)
+	}
+
+	usage := KeyUsageFor(cm.Get())
+	if len(usage) != 2 || usage[0].RateLimited != 1 || usage[1].Requests != 2 {
+		t.Errorf("unexpected key usage: %+v", usage)
+	}
+}
+
+func TestRoundRobinUsesKeysInTurn(t *testing.T) {
+	config := &core.Config{
+		Provider:     "round-robin-test",
+		APIKey:       "a",
+		ExtraAPIKeys: map[string][]string{"round-robin-test": {"b", "a", "c"}},
+		KeyRotation:  core.KeyRotationRoundRobin,
+	}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, sharedKeyRing.pick(config))
+	}
+	if strings.Join(got, "") != "abca" {
+		t.Errorf("expected keys in turn without duplicates, got %v", got)
+	}
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// ModelsResult is published with core.EventModelsLoaded when a model refresh finishes
+type ModelsResult struct {
+	Provider string
+	Models   []string
+	Err      error
+}
+
+// ConnectionResult is published with core.EventConnectionTested when a connection test finishes
+type ConnectionResult struct {
+	Provider          string
+	Latency           time.Duration // Whole check
+	ModelsLatency     time.Duration // Listing models
+	CompletionLatency time.Duration // The 1-token completion, zero when skipped
+	Models            int
+	Capabilities      []string // Detected by the probe, e.g. "chat", "usage"
+	SuggestedBaseURL  string   // A path variant of the base URL that works when the configured one 404s
+	Err               error
+}
+
+// KeyValidationResult is published with core.EventKeyValidated when a newly entered API key has been checked
+type KeyValidationResult struct {
+	Provider     string
+	Organization string // From the provider's response headers, when it sends them
+	Project      string
+	Models       int
+	Warning      string // The key works but lacks access the settings need
+	Err          error
+}
+
+// ListModels fetches the model IDs available from the configured provider
+func (c *ChatClient) ListModels(ctx context.Context) ([]string, error) {
+	modelIDs, _, err := c.fetchModels(ctx, c.config.Get())
+	return modelIDs, err
+}
+
+// fetchModels lists the models and also returns the response headers, which
+// some providers use to identify the organization and project of the key
+func (c *ChatClient) fetchModels(ctx context.Context, config *core.Config) ([]string, http.Header, error) {
+	apiURL := c.getModelsEndpoint(config)
+
+	if err := validateOfflineRequest(apiURL, config); err != nil {
+		return nil, nil, fmt.Errorf("offline mode violation: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+config.APIKey)
+	}
+	setProviderHeaders(req, config)
+
+	if log := logger.Get(); log != nil {
+		log.Info("[ChatClient] Fetching models from %s", apiURL)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, resp.Header, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	// OpenAI-compatible servers return {"data":[{"id":...}]}, Ollama returns {"models":[{"name":...}]}
+	var payload struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, resp.Header, fmt.Errorf("failed to decode models: %w", err)
+	}
+
+	modelIDs := make([]string, 0, len(payload.Data)+len(payload.Models))
+	for _, model := range payload.Data {
+		modelIDs = append(modelIDs, model.ID)
+	}
+	for _, model := range payload.Models {
+		modelIDs = append(modelIDs, model.Name)
+	}
+	sort.Strings(modelIDs)
+
+	return modelIDs, resp.Header, nil
+}
+
+// StatusError is returned when the provider answers with a non-200 status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// ValidateAPIKey checks the configured API key against the configured provider.
+// A rejected key is an error; a key that is accepted but cannot list models or
+// use the selected model gets a warning instead.
+func (c *ChatClient) ValidateAPIKey(ctx context.Context) KeyValidationResult {
+	config := c.config.Get()
+	result := KeyValidationResult{Provider: config.Provider}
+
+	modelIDs, header, err := c.fetchModels(ctx, config)
+	if header != nil {
+		// OpenAI reports these on every response; other providers leave them out
+		result.Organization = header.Get("openai-organization")
+		result.Project = header.Get("openai-project")
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusForbidden {
+		// Restricted keys are valid but may be missing the model read scope
+		result.Warning = "key lacks permission to list models"
+		return result
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Models = len(modelIDs)
+	if config.Model != "" && len(modelIDs) > 0 {
+		for _, id := range modelIDs {
+			if id == config.Model {
+				return result
+			}
+		}
+		result.Warning = fmt.Sprintf("key has no access to %s", config.Model)
+	}
+	return result
+}
+
+// TestConnection runs a small end-to-end check: it lists the models, then asks
+// the selected model for a 1-token completion. A success is recorded in the
+// configuration so the provider dropdown can show when it last worked.
+func (c *ChatClient) TestConnection(ctx context.Context) ConnectionResult {
+	config := c.config.Get()
+	result := ConnectionResult{Provider: config.Provider}
+
+	start := time.Now()
+	modelIDs, err := c.ListModels(ctx)
+	result.ModelsLatency = time.Since(start)
+	result.Models = len(modelIDs)
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			result.SuggestedBaseURL = c.ProbeBaseURL(ctx)
+		}
+		result.Latency = time.Since(start)
+		result.Err = err
+		return result
+	}
+	result.Capabilities = append(result.Capabilities, "models")
+	for _, id := range modelIDs {
+		if id == config.Model {
+			result.Capabilities = append(result.Capabilities, "model listed")
+			break
+		}
+	}
+
+	if config.Model != "" {
+		probeStart := time.Now()
+		capabilities, err := c.ProbeCompletion(ctx)
+		result.CompletionLatency = time.Since(probeStart)
+		result.Capabilities = append(result.Capabilities, capabilities...)
+		if err != nil {
+			result.Err = fmt.Errorf("models listed, but completion failed: %w", err)
+		}
+	}
+	result.Latency = time.Since(start)
+
+	if result.Err == nil {
+		c.config.Update(func(cfg *core.Config) {
+			cfg.RecordProviderCheck(result.Provider, core.ProviderCheck{
+				LastSuccess:  time.Now(),
+				LatencyMS:    result.Latency.Milliseconds(),
+				Capabilities: result.Capabilities,
+			})
+		})
+	}
+	return result
+}
+
+// ProbeCompletion asks the selected model for a single token without streaming
+// and returns what the response shows the endpoint supports
+func (c *ChatClient) ProbeCompletion(ctx context.Context) ([]string, error) {
+	config := c.config.Get()
+	apiURL := c.getAPIEndpoint(config)
+
+	if err := validateOfflineRequest(apiURL, config); err != nil {
+		return nil, fmt.Errorf("offline mode violation: %w", err)
+	}
+
+	// Reuse the model-specific request so the probe hits the same quirks as a chat
+	chat := c.buildCompatibleRequest(config, []ChatMessage{{Role: "user", Content: "ping"}})
+	body := map[string]interface{}{
+		"model":    chat.Model,
+		"messages": chat.Messages,
+		"stream":   false,
+	}
+	switch {
+	case config.Provider == "ollama":
+		body["options"] = map[string]int{"num_predict": 1}
+	case chat.MaxCompletionTokens > 0:
+		body["max_completion_tokens"] = 1
+	default:
+		body["max_tokens"] = 1
+	}
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if config.Provider != "ollama" && config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+config.APIKey)
+	}
+	setProviderHeaders(req, config)
+
+	if log := logger.Get(); log != nil {
+		log.Info("[ChatClient] Probing completion at %s", apiURL)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	// OpenAI-compatible servers return choices, Ollama returns a single message
+	var payload struct {
+		Choices         []json.RawMessage `json:"choices"`
+		Message         *json.RawMessage  `json:"message"`
+		Usage           *json.RawMessage  `json:"usage"`
+		PromptEvalCount int               `json:"prompt_eval_count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode completion: %w", err)
+	}
+	if len(payload.Choices) == 0 && payload.Message == nil {
+		return nil, fmt.Errorf("completion returned no choices")
+	}
+
+	capabilities := []string{"chat"}
+	if payload.Usage != nil || payload.PromptEvalCount > 0 {
+		capabilities = append(capabilities, "usage")
+	}
+	return capabilities, nil
+}
+
+// ProbeBaseURL tries the usual path variants of the base URL, such as with and
+// without /v1, and returns the first one that lists models, or "" if none does
+func (c *ChatClient) ProbeBaseURL(ctx context.Context) string {
+	config := c.config.Get()
+	if config.Provider == "ollama" {
+		return "" // The models endpoint of Ollama doesn't depend on the path
+	}
+
+	for _, candidate := range baseURLVariants(config.BaseURL) {
+		probe := *config
+		probe.BaseURL = candidate
+		if _, _, err := c.fetchModels(ctx, &probe); err == nil {
+			if log := logger.Get(); log != nil {
+				log.Info("[ChatClient] Base URL %s works instead of %s", candidate, config.BaseURL)
+			}
+			return candidate
+		}
+		if ctx.Err() != nil {
+			return ""
+		}
+	}
+	return ""
+}
+
+// baseURLVariants returns the common API roots of a base URL other than itself
+func baseURLVariants(baseURL string) []string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	root := baseURL
+	for _, suffix := range []string{"/api/v1", "/v1"} {
+		if strings.HasSuffix(root, suffix) {
+			root = strings.TrimSuffix(root, suffix)
+			break
+		}
+	}
+
+	var variants []string
+	for _, candidate := range []string{root + "/v1", root, root + "/api/v1"} {
+		if candidate != baseURL && candidate != "" {
+			variants = append(variants, candidate)
+		}
+	}
+	return variants
+}
+
+// getModelsEndpoint returns the model listing endpoint for the provider
+func (c *ChatClient) getModelsEndpoint(config *core.Config) string {
+	baseURL := strings.TrimSuffix(config.BaseURL, "/")
+
+	if config.Provider == "ollama" {
+		if baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+		return strings.TrimSuffix(baseURL, "/v1") + "/api/tags"
+	}
+	return baseURL + "/models"
+}
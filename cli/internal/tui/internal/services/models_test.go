@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/sampling"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+func newTestChatClient(t *testing.T, handler http.HandlerFunc) (*ChatClient, *core.ConfigManager) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cm, err := core.NewConfigManagerWithPath(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("failed to create config manager: %v", err)
+	}
+	t.Cleanup(func() { cm.Flush() })
+	cm.Update(func(cfg *core.Config) {
+		cfg.Provider = "custom"
+		cfg.BaseURL = server.URL
+		cfg.Model = "test-model"
+	})
+	return NewChatClient(cm), cm
+}
+
+func TestConnectionProbesCompletion(t *testing.T) {
+	var maxTokens float64
+	client, cm := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/models":
+			w.Write([]byte(`{"data":[{"id":"other"},{"id":"test-model"}]}`))
+		case "/chat/completions":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			maxTokens, _ = body["max_tokens"].(float64)
+			w.Write([]byte(`{"choices":[{"message":{"content":"p"}}],"usage":{"total_tokens":2}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	result := client.TestConnection(context.Background())
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if result.Models != 2 {
+		t.Errorf("expected 2 models, got %d", result.Models)
+	}
+	if maxTokens != 1 {
+		t.Errorf("expected a 1-token probe, got max_tokens %v", maxTokens)
+	}
+	want := []string{"models", "model listed", "chat", "usage"}
+	if len(result.Capabilities) != len(want) {
+		t.Fatalf("expected capabilities %v, got %v", want, result.Capabilities)
+	}
+	for i := range want {
+		if result.Capabilities[i] != want[i] {
+			t.Errorf("expected capabilities %v, got %v", want, result.Capabilities)
+		}
+	}
+
+	check, ok := cm.Get().ProviderChecks["custom"]
+	if !ok || check.LastSuccess.IsZero() {
+		t.Error("expected the success to be recorded for the provider")
+	}
+}
+
+func TestConnectionReportsCompletionFailure(t *testing.T) {
+	client, cm := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/models" {
+			w.Write([]byte(`{"data":[{"id":"test-model"}]}`))
+			return
+		}
+		http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+	})
+
+	result := client.TestConnection(context.Background())
+	if result.Err == nil {
+		t.Fatal("expected the failed completion to fail the test")
+	}
+	if _, ok := cm.Get().ProviderChecks["custom"]; ok {
+		t.Error("expected no success to be recorded")
+	}
+}
+
+func TestValidateAPIKeyReportsAccount(t *testing.T) {
+	client, _ := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("openai-organization", "org-acme")
+		w.Header().Set("openai-project", "proj_123")
+		w.Write([]byte(`{"data":[{"id":"test-model"}]}`))
+	})
+
+	result := client.ValidateAPIKey(context.Background())
+	if result.Err != nil || result.Warning != "" {
+		t.Fatalf("expected a valid key, got err %v, warning %q", result.Err, result.Warning)
+	}
+	if result.Organization != "org-acme" || result.Project != "proj_123" {
+		t.Errorf("expected org and project from headers, got %q and %q", result.Organization, result.Project)
+	}
+}
+
+func TestValidateAPIKeyWarnsAboutMissingAccess(t *testing.T) {
+	client, _ := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"id":"other"}]}`))
+	})
+
+	result := client.ValidateAPIKey(context.Background())
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if result.Warning == "" {
+		t.Error("expected a warning when the selected model is not listed")
+	}
+
+	restricted, _ := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"Missing scopes: api.model.read"}`, http.StatusForbidden)
+	})
+	result = restricted.ValidateAPIKey(context.Background())
+	if result.Err != nil || result.Warning == "" {
+		t.Errorf("expected a scope warning for a restricted key, got err %v, warning %q", result.Err, result.Warning)
+	}
+}
+
+func TestValidateAPIKeyRejectsInvalidKey(t *testing.T) {
+	client, _ := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+	})
+
+	result := client.ValidateAPIKey(context.Background())
+	var statusErr *StatusError
+	if !errors.As(result.Err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 status error, got %v", result.Err)
+	}
+}
+
+func TestProviderHeadersAreSent(t *testing.T) {
+	var routing []string
+	client, cm := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		routing = append(routing, r.Header.Get("x-routing-key"))
+		if r.URL.Path == "/models" {
+			w.Write([]byte(`{"data":[{"id":"test-model"}]}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"content":"p"}}]}`))
+	})
+	cm.Update(func(cfg *core.Config) {
+		cfg.ProviderHeaders = map[string]map[string]string{
+			"custom": {"x-routing-key": "team-a"},
+			"openai": {"x-routing-key": "wrong"},
+		}
+	})
+
+	if result := client.TestConnection(context.Background()); result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if len(routing) != 2 || routing[0] != "team-a" || routing[1] != "team-a" {
+		t.Errorf("expected the provider's header on both requests, got %v", routing)
+	}
+}
+
+func TestBaseURLVariants(t *testing.T) {
+	tests := map[string][]string{
+		"http://localhost:1234":        {"http://localhost:1234/v1", "http://localhost:1234/api/v1"},
+		"http://localhost:1234/v1/":    {"http://localhost:1234", "http://localhost:1234/api/v1"},
+		"https://openrouter.ai/api/v1": {"https://openrouter.ai/v1", "https://openrouter.ai"},
+	}
+	for baseURL, want := range tests {
+		got := baseURLVariants(baseURL)
+		if len(got) != len(want) {
+			t.Errorf("%s: expected %v, got %v", baseURL, want, got)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: expected %v, got %v", baseURL, want, got)
+			}
+		}
+	}
+}
+
+func TestConnectionSuggestsWorkingBaseURL(t *testing.T) {
+	client, cm := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/models" {
+			w.Write([]byte(`{"data":[{"id":"test-model"}]}`))
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	result := client.TestConnection(context.Background())
+	if result.Err == nil {
+		t.Fatal("expected the test to fail without /v1")
+	}
+	if want := cm.Get().BaseURL + "/v1"; result.SuggestedBaseURL != want {
+		t.Errorf("expected suggestion %q, got %q", want, result.SuggestedBaseURL)
+	}
+}
+
+func TestLocalRuntimeOptionsInRequest(t *testing.T) {
+	layers, temperature := 20, 0.2
+	cfg := core.DefaultConfig()
+	cfg.Provider = "ollama"
+	cfg.Model = "llama3.2"
+	cfg.LocalRuntime = map[string]core.LocalRuntimeOptions{
+		"llama3.2": {ContextSize: 8192, GPULayers: &layers, Temperature: &temperature},
+	}
+
+	client := &ChatClient{}
+	req := client.buildCompatibleRequest(cfg, nil)
+	if req.Temperature != 0.2 {
+		t.Errorf("expected the model temperature, got %v", req.Temperature)
+	}
+	if req.Options["num_ctx"] != 8192 || req.Options["num_gpu"] != 20 {
+		t.Errorf("expected Ollama options, got %v", req.Options)
+	}
+
+	cfg.Provider = "llamafile"
+	req = client.buildCompatibleRequest(cfg, nil)
+	if req.Options != nil || req.Temperature != 0.2 {
+		t.Errorf("expected only the temperature for llamafile, got %+v", req)
+	}
+}
+
+func TestSamplingOverrideInRequest(t *testing.T) {
+	cfg := core.DefaultConfig()
+	cfg.Model = "gpt-4o"
+	cfg.Temperature = 0.7
+
+	client := &ChatClient{}
+	client.SetSampling(sampling.Override{Temperature: sampling.Float(0.2), TopP: sampling.Float(0.9)})
+	req := client.buildCompatibleRequest(cfg, nil)
+	if req.Temperature != 0.2 || req.TopP != 0.9 {
+		t.Errorf("expected the override, got temperature %v and top_p %v", req.Temperature, req.TopP)
+	}
+
+	cfg.Model = "gpt-5-mini"
+	req = client.buildCompatibleRequest(cfg, nil)
+	if req.Temperature != 0 || req.TopP != 0 {
+		t.Errorf("expected no sampling parameters for a fixed-temperature model, got %+v", req)
+	}
+
+	cfg.Model = "gpt-4o"
+	client.SetSampling(sampling.Override{})
+	if req = client.buildCompatibleRequest(cfg, nil); req.Temperature != 0.7 || req.TopP != 0 {
+		t.Errorf("expected the configured temperature after clearing, got %+v", req)
+	}
+}
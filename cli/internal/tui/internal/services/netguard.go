@@ -0,0 +1,30 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/hacka-re/cli/internal/netguard"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// newOfflineGuard creates the transport of every ChatClient. In offline mode,
+// from the config or the process, it only reaches loopback and private
+// addresses; requests covered by --allow-remote-mcp or
+// --allow-remote-embeddings are exempt.
+func newOfflineGuard(config *core.ConfigManager) http.RoundTripper {
+	return netguard.NewTransport(http.DefaultTransport.(*http.Transport), func(req *http.Request) bool {
+		cfg := config.Get()
+		return (cfg.IsOfflineMode || netguard.Offline()) && !offlineExempt(req.URL.String(), cfg)
+	})
+}
+
+// offlineExempt reports whether an --allow-remote-* flag covers the request
+func offlineExempt(urlStr string, config *core.Config) bool {
+	switch detectRequestType(urlStr) {
+	case RequestTypeMCP:
+		return config.AllowRemoteMCP
+	case RequestTypeEmbeddings:
+		return config.AllowRemoteEmbeddings
+	}
+	return false
+}
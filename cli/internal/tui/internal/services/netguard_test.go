@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/netguard"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+func TestOfflineGuardBlocksRedirectToRemote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://8.8.8.8/models", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client, cm := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	cm.Update(func(cfg *core.Config) {
+		cfg.BaseURL = server.URL
+		cfg.IsOfflineMode = true
+	})
+
+	before, _ := netguard.Violations()
+	_, err := client.ListModels(context.Background())
+	if !errors.Is(err, netguard.ErrBlocked) {
+		t.Fatalf("expected the redirect to be blocked, got %v", err)
+	}
+	if after, last := netguard.Violations(); after != before+1 || last.Target != "8.8.8.8:80" {
+		t.Errorf("expected the violation to be recorded, got %d (%+v)", after, last)
+	}
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/hacka-re/cli/internal/tags"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+	"github.com/hacka-re/cli/internal/tui/internal/prompts"
+	"github.com/hacka-re/cli/internal/usage"
+)
+
+// Prompt is a system prompt component that can be enabled for the chat
+type Prompt struct {
+	ID          string
+	Name        string
+	Content     string
+	Description string
+	IsDefault   bool
+	IsMCP       bool // Whether this is an MCP prompt
+	IsActive    bool
+	IsEnabled   bool // Whether the prompt is enabled (checkbox)
+	Tags        []string
+}
+
+// PromptsService loads, toggles, combines and persists system prompts.
+// The config (EnabledPrompts, CustomPrompts, SystemPrompt) is the source of truth,
+// so every view built on the same ConfigManager sees the same state.
+type PromptsService struct {
+	config       *core.ConfigManager
+	defaults     []Prompt
+	custom       []Prompt
+	mcp          []Prompt
+	mcpConnected bool
+}
+
+// NewPromptsService creates a prompts service and loads the current state
+func NewPromptsService(config *core.ConfigManager) *PromptsService {
+	s := &PromptsService{config: config}
+	s.Load()
+	return s
+}
+
+// SetMCPConnected controls whether MCP prompts are offered; call Load afterwards
+func (s *PromptsService) SetMCPConnected(connected bool) {
+	s.mcpConnected = connected
+}
+
+// Load rebuilds the prompt lists from the embedded defaults and the config
+func (s *PromptsService) Load() {
+	cfg := s.config.Get()
+	enabled := make(map[string]bool, len(cfg.EnabledPrompts))
+	for _, id := range cfg.EnabledPrompts {
+		enabled[id] = true
+	}
+
+	defaultPrompts := prompts.GetDefaultPrompts()
+	s.defaults = make([]Prompt, 0, len(defaultPrompts))
+	for _, dp := range defaultPrompts {
+		s.defaults = append(s.defaults, Prompt{
+			ID:          dp.ID,
+			Name:        dp.Name,
+			Content:     dp.Content,
+			Description: dp.Description,
+			Tags:        dp.Tags,
+			IsDefault:   true,
+			IsEnabled:   enabled[dp.ID],
+		})
+	}
+
+	s.mcp = []Prompt{}
+	if s.mcpConnected {
+		for _, mp := range prompts.GetMCPPrompts() {
+			s.mcp = append(s.mcp, Prompt{
+				ID:          mp.ID,
+				Name:        mp.Name,
+				Content:     mp.Content,
+				Description: mp.Description,
+				Tags:        mp.Tags,
+				IsMCP:       true,
+				IsEnabled:   enabled[mp.ID],
+			})
+		}
+	}
+
+	s.custom = make([]Prompt, 0, len(cfg.CustomPrompts))
+	for _, cp := range cfg.CustomPrompts {
+		s.custom = append(s.custom, Prompt{
+			ID:        cp.ID,
+			Name:      cp.Name,
+			Content:   cp.Content,
+			Tags:      cp.Tags,
+			IsEnabled: enabled[cp.ID],
+		})
+	}
+}
+
+// Defaults returns the built-in prompts
+func (s *PromptsService) Defaults() []Prompt {
+	return append([]Prompt(nil), s.defaults...)
+}
+
+// Custom returns the user-created prompts
+func (s *PromptsService) Custom() []Prompt {
+	return append([]Prompt(nil), s.custom...)
+}
+
+// MCP returns the MCP server prompts (empty unless MCP is connected)
+func (s *PromptsService) MCP() []Prompt {
+	return append([]Prompt(nil), s.mcp...)
+}
+
+// All returns every prompt in display order: defaults, custom, MCP
+func (s *PromptsService) All() []Prompt {
+	all := make([]Prompt, 0, len(s.defaults)+len(s.custom)+len(s.mcp))
+	all = append(all, s.defaults...)
+	all = append(all, s.custom...)
+	all = append(all, s.mcp...)
+	return all
+}
+
+// find returns a pointer to the stored prompt with the given ID
+func (s *PromptsService) find(id string) *Prompt {
+	for _, list := range [][]Prompt{s.defaults, s.custom, s.mcp} {
+		for i := range list {
+			if list[i].ID == id {
+				return &list[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Get returns a copy of the prompt with the given ID, or nil
+func (s *PromptsService) Get(id string) *Prompt {
+	if prompt := s.find(id); prompt != nil {
+		copied := *prompt
+		return &copied
+	}
+	return nil
+}
+
+// SetEnabled enables or disables a prompt and persists the new selection
+func (s *PromptsService) SetEnabled(id string, enabled bool) {
+	prompt := s.find(id)
+	if prompt == nil {
+		return
+	}
+	prompt.IsEnabled = enabled
+	s.persist()
+}
+
+// Toggle flips the enabled state of a prompt and returns the new state
+func (s *PromptsService) Toggle(id string) bool {
+	prompt := s.find(id)
+	if prompt == nil {
+		return false
+	}
+	s.SetEnabled(id, !prompt.IsEnabled)
+	return prompt.IsEnabled
+}
+
+// SaveCustom adds a custom prompt, or replaces the one with the same ID
+func (s *PromptsService) SaveCustom(prompt Prompt) {
+	prompt.IsDefault = false
+	prompt.IsMCP = false
+
+	for i := range s.custom {
+		if s.custom[i].ID == prompt.ID {
+			s.custom[i] = prompt
+			s.persist()
+			return
+		}
+	}
+	s.custom = append(s.custom, prompt)
+	s.persist()
+}
+
+// SetTags replaces the tags of a custom prompt; built-in prompts keep their own
+func (s *PromptsService) SetTags(id string, promptTags []string) {
+	for i := range s.custom {
+		if s.custom[i].ID == id {
+			s.custom[i].Tags = tags.Normalize(promptTags)
+			s.persist()
+			return
+		}
+	}
+}
+
+// Tags returns the sorted set of tags used by all prompts
+func (s *PromptsService) Tags() []string {
+	var lists [][]string
+	for _, prompt := range s.All() {
+		lists = append(lists, prompt.Tags)
+	}
+	return tags.Collect(lists...)
+}
+
+// DeleteCustom removes a custom prompt; default and MCP prompts can't be deleted
+func (s *PromptsService) DeleteCustom(id string) {
+	for i := range s.custom {
+		if s.custom[i].ID == id {
+			s.custom = append(s.custom[:i], s.custom[i+1:]...)
+			s.persist()
+			return
+		}
+	}
+}
+
+// Combined returns the system prompt built from all enabled prompts
+func (s *PromptsService) Combined() string {
+	var parts []string
+	for _, prompt := range s.All() {
+		if prompt.IsEnabled {
+			parts = append(parts, prompt.Content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// TokenCount estimates the tokens used by all enabled prompts
+func (s *PromptsService) TokenCount() int {
+	total := 0
+	for _, prompt := range s.All() {
+		if prompt.IsEnabled {
+			total += EstimateTokens(prompt.Content)
+		}
+	}
+	return total
+}
+
+// EstimateTokens roughly estimates the token count of a prompt
+func EstimateTokens(text string) int {
+	return usage.EstimateTokens(text)
+}
+
+// persist writes custom prompts, enabled IDs and the combined system prompt to the config
+func (s *PromptsService) persist() {
+	customPrompts := make([]core.CustomPrompt, 0, len(s.custom))
+	for _, prompt := range s.custom {
+		customPrompts = append(customPrompts, core.CustomPrompt{
+			ID:      prompt.ID,
+			Name:    prompt.Name,
+			Content: prompt.Content,
+			Tags:    prompt.Tags,
+		})
+	}
+
+	enabledIDs := []string{}
+	for _, prompt := range s.All() {
+		if prompt.IsEnabled {
+			enabledIDs = append(enabledIDs, prompt.ID)
+		}
+	}
+
+	combined := s.Combined()
+	s.config.Update(func(cfg *core.Config) {
+		cfg.CustomPrompts = customPrompts
+		cfg.EnabledPrompts = enabledIDs
+		cfg.SystemPrompt = combined
+	})
+}
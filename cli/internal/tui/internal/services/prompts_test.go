@@ -0,0 +1,101 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+func newTestPromptsService(t *testing.T) (*PromptsService, *core.ConfigManager) {
+	t.Helper()
+	cm, err := core.NewConfigManagerWithPath(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("failed to create config manager: %v", err)
+	}
+	t.Cleanup(func() { cm.Flush() })
+	return NewPromptsService(cm), cm
+}
+
+func TestPromptsServiceToggleUpdatesConfig(t *testing.T) {
+	service, cm := newTestPromptsService(t)
+
+	defaults := service.Defaults()
+	if len(defaults) == 0 {
+		t.Fatal("expected built-in prompts")
+	}
+
+	if !service.Toggle(defaults[0].ID) {
+		t.Fatal("expected prompt to be enabled after toggle")
+	}
+
+	cfg := cm.Get()
+	if len(cfg.EnabledPrompts) != 1 || cfg.EnabledPrompts[0] != defaults[0].ID {
+		t.Errorf("unexpected enabled prompts %v", cfg.EnabledPrompts)
+	}
+	if cfg.SystemPrompt != defaults[0].Content {
+		t.Error("expected system prompt to be the enabled prompt's content")
+	}
+
+	// A second view on the same config sees the same state
+	other := NewPromptsService(cm)
+	if prompt := other.Get(defaults[0].ID); prompt == nil || !prompt.IsEnabled {
+		t.Error("expected enabled state to be shared through the config")
+	}
+
+	if service.Toggle(defaults[0].ID) {
+		t.Fatal("expected prompt to be disabled after second toggle")
+	}
+	if len(cfg.EnabledPrompts) != 0 || cfg.SystemPrompt != "" {
+		t.Errorf("expected no enabled prompts, got %v", cfg.EnabledPrompts)
+	}
+}
+
+func TestPromptsServiceCustomPrompts(t *testing.T) {
+	service, cm := newTestPromptsService(t)
+
+	service.SaveCustom(Prompt{ID: "custom-1", Name: "Recon", Content: "Enumerate subdomains", IsEnabled: true})
+	service.SaveCustom(Prompt{ID: "custom-1", Name: "Recon", Content: "Enumerate hosts", IsEnabled: true})
+
+	cfg := cm.Get()
+	if len(cfg.CustomPrompts) != 1 || cfg.CustomPrompts[0].Content != "Enumerate hosts" {
+		t.Fatalf("unexpected custom prompts %+v", cfg.CustomPrompts)
+	}
+	if service.Combined() != "Enumerate hosts" {
+		t.Errorf("unexpected combined prompt %q", service.Combined())
+	}
+	if service.TokenCount() != EstimateTokens("Enumerate hosts") {
+		t.Errorf("unexpected token count %d", service.TokenCount())
+	}
+
+	// Deleting an enabled prompt also removes it from the system prompt
+	service.DeleteCustom("custom-1")
+	if len(cfg.CustomPrompts) != 0 || len(cfg.EnabledPrompts) != 0 || cfg.SystemPrompt != "" {
+		t.Errorf("expected custom prompt to be fully removed, got %+v", cfg)
+	}
+}
+
+func TestPromptsServiceTags(t *testing.T) {
+	service, cm := newTestPromptsService(t)
+
+	service.SaveCustom(Prompt{ID: "custom-1", Name: "Recon", Content: "Enumerate hosts"})
+	service.SetTags("custom-1", []string{"Recon", "#web", "recon"})
+	service.SetTags(service.Defaults()[0].ID, []string{"ignored"})
+
+	if tags := cm.Get().CustomPrompts[0].Tags; len(tags) != 2 || tags[0] != "recon" || tags[1] != "web" {
+		t.Errorf("unexpected persisted tags %q", tags)
+	}
+	if other := NewPromptsService(cm).Get("custom-1"); other == nil || len(other.Tags) != 2 {
+		t.Error("expected tags to be loaded from the config")
+	}
+	all := service.Tags()
+	for _, tag := range []string{"recon", "security", "web"} {
+		found := false
+		for _, have := range all {
+			found = found || have == tag
+		}
+		if !found {
+			t.Errorf("Tags() = %q, missing %q", all, tag)
+		}
+	}
+}
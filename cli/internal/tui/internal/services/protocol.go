@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+// streamProtocol performs a streaming request for the custom_protocol provider,
+// building the body and reading the chunks as described by config.Protocol
+func (c *ChatClient) streamProtocol(ctx context.Context, config *core.Config, messages []ChatMessage, callback StreamingCallback) (string, error) {
+	protocol := config.Protocol
+	if protocol == nil || protocol.Body == "" {
+		return "", fmt.Errorf("the %s provider needs a protocol template in the configuration", core.ProviderCustomProtocol)
+	}
+
+	body, err := renderProtocolBody(protocol.Body, config, messages)
+	if err != nil {
+		return "", err
+	}
+
+	apiURL := c.getAPIEndpoint(config)
+	if err := validateOfflineRequest(apiURL, config); err != nil {
+		return "", fmt.Errorf("offline mode violation: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setAuthHeaders(req, config.Provider, sharedKeyRing.pick(config))
+	setProviderHeaders(req, config)
+
+	if log := logger.Get(); log != nil {
+		log.Debug("[ChatClient] Sending templated request to: %s", apiURL)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	delimiter := protocol.StreamDelimiter
+	if delimiter == "" {
+		delimiter = "\n"
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Split(splitOn(delimiter))
+
+	var received strings.Builder
+	for scanner.Scan() {
+		chunk := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), protocol.DataPrefix))
+		if chunk == "" {
+			continue
+		}
+		if protocol.DoneMarker != "" && chunk == protocol.DoneMarker {
+			callback("", true)
+			return received.String(), nil
+		}
+
+		var data interface{}
+		if err := json.Unmarshal([]byte(chunk), &data); err != nil {
+			continue // Skip keep-alives and other non-JSON chunks
+		}
+		text, _ := lookupPath(data, protocol.ContentPath).(string)
+		if text == "" {
+			continue
+		}
+		received.WriteString(text)
+		if err := callback(text, false); err != nil {
+			return received.String(), err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return received.String(), fmt.Errorf("error reading stream: %w", err)
+		}
+		return received.String(), fmt.Errorf("%w: %v", api.ErrStreamInterrupted, err)
+	}
+	if protocol.DoneMarker != "" {
+		return received.String(), api.ErrStreamInterrupted
+	}
+	callback("", true)
+	return received.String(), nil
+}
+
+// renderProtocolBody fills the placeholders of a body template with JSON values
+// and checks that the result is valid JSON
+func renderProtocolBody(template string, config *core.Config, messages []ChatMessage) ([]byte, error) {
+	var prompt strings.Builder
+	lastMessage := ""
+	for _, message := range messages {
+		fmt.Fprintf(&prompt, "%s: %s\n\n", message.Role, message.Content)
+		lastMessage = message.Content
+	}
+	prompt.WriteString("assistant:")
+
+	values := map[string]interface{}{
+		"model":        config.Model,
+		"messages":     messages,
+		"prompt":       prompt.String(),
+		"last_message": lastMessage,
+		"temperature":  config.Temperature,
+		"max_tokens":   config.MaxTokens,
+	}
+
+	// Replace in a single pass so placeholders inside message text stay as they are
+	var replacements []string
+	for name, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		replacements = append(replacements, "{{"+name+"}}", string(encoded))
+	}
+	body := strings.NewReplacer(replacements...).Replace(template)
+
+	if !json.Valid([]byte(body)) {
+		return nil, fmt.Errorf("protocol body template does not produce valid JSON")
+	}
+	return []byte(body), nil
+}
+
+// lookupPath follows a dot path such as "choices.0.delta.content" through decoded JSON
+func lookupPath(data interface{}, path string) interface{} {
+	if path == "" {
+		return data
+	}
+	for _, part := range strings.Split(path, ".") {
+		switch node := data.(type) {
+		case map[string]interface{}:
+			data = node[part]
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil
+			}
+			data = node[index]
+		default:
+			return nil
+		}
+	}
+	return data
+}
+
+// splitOn returns a bufio.SplitFunc that splits the stream at delimiter
+func splitOn(delimiter string) bufio.SplitFunc {
+	sep := []byte(delimiter)
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/tui/internal/core"
+)
+
+func TestCustomProtocolStream(t *testing.T) {
+	var body map[string]interface{}
+	client, cm := newTestChatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/generate" {
+			http.NotFound(w, r)
+			return
+		}
+		raw, _ := io.ReadAll(r.Body)
+		json.Unmarshal(raw, &body)
+		w.Write([]byte(`{"token":{"text":"Hel"}}|{"token":{"text":"lo"}}|keep-alive|END|{"token":{"text":"ignored"}}`))
+	})
+	cm.Update(func(cfg *core.Config) {
+		cfg.Provider = core.ProviderCustomProtocol
+		cfg.Protocol = &core.ProtocolTemplate{
+			Path:            "/generate",
+			Body:            `{"model": {{model}}, "inputs": {{last_message}}, "stream": true}`,
+			StreamDelimiter: "|",
+			ContentPath:     "token.text",
+			DoneMarker:      "END",
+		}
+	})
+
+	reply, err := client.SendMessage([]ChatMessage{{Role: "user", Content: `say "{{model}}"`}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reply != "Hello" {
+		t.Errorf("expected %q, got %q", "Hello", reply)
+	}
+	if body["model"] != "test-model" || body["inputs"] != `say "{{model}}"` {
+		t.Errorf("unexpected request body: %v", body)
+	}
+}
+
+func TestRenderProtocolBodyRejectsInvalidJSON(t *testing.T) {
+	config := &core.Config{Model: "m"}
+	if _, err := renderProtocolBody(`{"prompt": "{{prompt}}"}`, config, nil); err == nil {
+		t.Error("expected an error for a placeholder inside quotes")
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	var data interface{}
+	json.Unmarshal([]byte(`{"choices":[{"delta":{"content":"x"}}]}`), &data)
+	if got := lookupPath(data, "choices.0.delta.content"); got != "x" {
+		t.Errorf("expected %q, got %v", "x", got)
+	}
+	if got := lookupPath(data, "choices.1.delta"); got != nil {
+		t.Errorf("expected nil for a missing index, got %v", got)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sampling replaces the configured temperature and seed for one request
+type Sampling struct {
+	Temperature float64
+	Seed        int
+}
+
+// String describes the sampling, e.g. "temp 0.9, seed 1043"
+func (s Sampling) String() string {
+	return fmt.Sprintf("temp %.1f, seed %d", s.Temperature, s.Seed)
+}
+
+type samplingKey struct{}
+
+// WithSampling returns a context whose completion requests use sampling
+// instead of the configured temperature and seed
+func WithSampling(ctx context.Context, sampling Sampling) context.Context {
+	return context.WithValue(ctx, samplingKey{}, sampling)
+}
+
+// maxVariantTemperature keeps alternative replies coherent
+const maxVariantTemperature = 1.5
+
+// Variants returns the sampling of n alternatives to a reply written with
+// temperature and seed. Each alternative gets its own seed and a temperature
+// 0.2 higher than the one before, up to 1.5, so they differ more than a retry.
+func Variants(n int, temperature float64, seed int) []Sampling {
+	if seed == 0 {
+		seed = int(time.Now().UnixNano()%1000000) + 1
+	}
+	variants := make([]Sampling, n)
+	for i := range variants {
+		t := temperature + 0.2*float64(i+1)
+		if t > maxVariantTemperature {
+			t = maxVariantTemperature
+		}
+		if t < temperature {
+			t = temperature
+		}
+		variants[i] = Sampling{Temperature: t, Seed: seed + i + 1}
+	}
+	return variants
+}
+
+// applySampling sets the sampling of ctx, if any, on a request. Models that
+// only accept their default temperature get just the seed.
+func applySampling(ctx context.Context, req *ChatRequest) {
+	sampling, ok := ctx.Value(samplingKey{}).(Sampling)
+	if !ok {
+		return
+	}
+	req.Seed = sampling.Seed
+	if !fixedTemperature(req.Model) {
+		req.Temperature = float32(sampling.Temperature)
+	}
+}
+
+// fixedTemperature reports whether a model rejects a temperature other than
+// its default
+func fixedTemperature(model string) bool {
+	model = strings.ToLower(model)
+	return strings.Contains(model, "gpt-5-nano") || strings.Contains(model, "gpt-4.1-nano") || strings.Contains(model, "gpt-5-mini")
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestVariants(t *testing.T) {
+	variants := Variants(3, 1.2, 7)
+	want := []Sampling{{1.4, 8}, {1.5, 9}, {1.5, 10}}
+	for i, v := range variants {
+		if v.Seed != want[i].Seed || v.Temperature < want[i].Temperature-1e-9 || v.Temperature > want[i].Temperature+1e-9 {
+			t.Errorf("variant %d = %+v, want %+v", i, v, want[i])
+		}
+	}
+
+	// Without a seed each alternative still gets a different one
+	variants = Variants(2, 0, 0)
+	if variants[0].Seed == 0 || variants[0].Seed == variants[1].Seed {
+		t.Errorf("seeds %d and %d", variants[0].Seed, variants[1].Seed)
+	}
+	// A temperature above the cap is kept
+	if v := Variants(1, 1.8, 1)[0]; v.Temperature != 1.8 {
+		t.Errorf("temperature %v, want 1.8", v.Temperature)
+	}
+}
+
+func TestApplySampling(t *testing.T) {
+	ctx := WithSampling(context.Background(), Sampling{Temperature: 0.9, Seed: 42})
+
+	req := ChatRequest{Model: "gpt-4o", Temperature: 0.7}
+	applySampling(ctx, &req)
+	if req.Temperature != 0.9 || req.Seed != 42 {
+		t.Errorf("request = %+v", req)
+	}
+
+	req = ChatRequest{Model: "gpt-5-nano"}
+	applySampling(ctx, &req)
+	if req.Temperature != 0 || req.Seed != 42 {
+		t.Errorf("fixed-temperature request = %+v", req)
+	}
+
+	req = ChatRequest{Model: "gpt-4o", Temperature: 0.7, Seed: 1}
+	applySampling(context.Background(), &req)
+	if req.Temperature != 0.7 || req.Seed != 1 {
+		t.Errorf("request without sampling changed: %+v", req)
+	}
+}
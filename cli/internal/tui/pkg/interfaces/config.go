@@ -1,5 +1,7 @@
 package interfaces
 
+import "github.com/hacka-re/cli/pkg/sharelink"
+
 // ExternalConfig defines the interface for external configuration
 // This allows the parent application to provide its own config structure
 type ExternalConfig interface {
@@ -49,6 +51,7 @@ type FunctionDef struct {
 	Code        string
 	Description string
 	Enabled     bool
+	Tags        []string
 }
 
 // PromptDef represents a prompt definition
@@ -58,9 +61,82 @@ type PromptDef struct {
 	Description string
 	Category    string
 	Enabled     bool
+	Tags        []string
+}
+
+// BudgetConfig is optionally implemented by an ExternalConfig to share budget limits
+type BudgetConfig interface {
+	GetMaxTokensPerRequest() int
+	GetMaxCostPerSession() float64
+	GetMaxCostPerDay() float64
+}
+
+// NotificationConfig is optionally implemented by an ExternalConfig to share notification settings
+type NotificationConfig interface {
+	GetNotifyOnComplete() bool
+	GetNotifyAfterSeconds() int
+}
+
+// LintConfig is optionally implemented by an ExternalConfig to share whether
+// prompts are checked before sending
+type LintConfig interface {
+	GetLintPrompts() bool
+}
+
+// LanguageConfig is optionally implemented by an ExternalConfig to share the
+// reply language: a language code, auto or off
+type LanguageConfig interface {
+	GetLanguage() string
+}
+
+// SeedConfig is optionally implemented by an ExternalConfig to share the sampling seed
+type SeedConfig interface {
+	GetSeed() int
+}
+
+// ArtifactsConfig is optionally implemented by an ExternalConfig to share the
+// cleanup policy of session artifacts
+type ArtifactsConfig interface {
+	GetArtifactsMaxAgeDays() int
+	GetArtifactsMaxSizeMB() int
+}
+
+// OfflineConfig is optionally implemented by an ExternalConfig to run the TUI in offline mode
+type OfflineConfig interface {
+	GetIsOfflineMode() bool
+	GetAllowRemoteMCP() bool
+	GetAllowRemoteEmbeddings() bool
+}
+
+// LinkLockConfig is optionally implemented by an ExternalConfig loaded from a
+// share link whose creator made prompts, functions and provider read-only
+type LinkLockConfig interface {
+	GetLockedByLink() bool
+	GetUnlockHash() string
+}
+
+// KioskConfig is optionally implemented by an ExternalConfig started in kiosk
+// mode, where settings, prompts, functions and sharing are read-only
+type KioskConfig interface {
+	GetKiosk() bool
+}
+
+// ShareConfig is optionally implemented by an ExternalConfig to offer the parts
+// of a share link the TUI doesn't manage itself: functions, MCP servers, RAG
+// and the prompt library
+type ShareConfig interface {
+	GetShareConfig() *sharelink.Config
+}
+
+// ConfigReceiver is optionally implemented by an ExternalConfig to receive the
+// settings changed in the TUI when it exits, so the parent application stays in sync
+type ConfigReceiver interface {
+	ApplyTUIConfig(cfg ExternalConfig)
 }
 
-// CLIConfig interface for CLI config compatibility
+// CLIConfig interface for CLI config compatibility.
+//
+// Deprecated: implement ExternalConfig instead; CLIConfig values are adapted through it.
 type CLIConfig interface {
 	GetProvider() string
 	GetAPIKey() string
@@ -8,6 +8,7 @@ import (
 	"github.com/hacka-re/cli/internal/tui/internal/core"
 	// "github.com/hacka-re/cli/internal/tui/internal/modes/socket" // DISABLED - Socket mode disabled
 	"github.com/hacka-re/cli/internal/tui/internal/transport"
+	"github.com/hacka-re/cli/internal/utils"
 )
 
 // LaunchOptions contains options for launching the TUI
@@ -82,6 +83,8 @@ func LaunchTUI(options *LaunchOptions) error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize config: %w", err)
 	}
+	// Config changes are written behind; make sure they reach disk on any exit path
+	defer utils.OnExit(func() { configManager.Flush() })()
 
 	// Load external config if provided
 	if options.Config != nil {
@@ -213,6 +216,11 @@ func LaunchTUI(options *LaunchOptions) error {
 	}
 
 exitNormally:
+	// Hand settings changed in the TUI back to the parent application
+	if options.Config != nil {
+		adapters.SyncBack(configManager, options.Config)
+	}
+
 	// Call exit callback if provided
 	if options.Callbacks != nil && options.Callbacks.OnExit != nil {
 		options.Callbacks.OnExit()
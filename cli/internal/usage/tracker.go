@@ -0,0 +1,360 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/models"
+)
+
+// Budget holds the configured spending limits (zero means unlimited)
+type Budget struct {
+	MaxTokensPerRequest int
+	MaxCostPerSession   float64
+	MaxCostPerDay       float64
+}
+
+// IsSet reports whether any limit is configured
+func (b Budget) IsSet() bool {
+	return b.MaxTokensPerRequest > 0 || b.MaxCostPerSession > 0 || b.MaxCostPerDay > 0
+}
+
+// BudgetExceededError is returned when a request would exceed a budget limit
+type BudgetExceededError struct {
+	Limit string  // "request tokens", "session cost" or "daily cost"
+	Max   float64 // Configured limit
+	Value float64 // Value the request would reach
+}
+
+// Error implements the error interface
+func (e *BudgetExceededError) Error() string {
+	if e.Limit == "request tokens" {
+		return fmt.Sprintf("budget exceeded: request needs ~%.0f tokens, limit is %.0f", e.Value, e.Max)
+	}
+	return fmt.Sprintf("budget exceeded: %s would reach $%.4f, limit is $%.4f", e.Limit, e.Value, e.Max)
+}
+
+// dailyUsage is the persisted per-day spend
+type dailyUsage struct {
+	Date             string  `json:"date"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"`
+	Cost             float64 `json:"cost"`
+	CachedTokens     int     `json:"cachedTokens,omitempty"`
+	CacheSavings     float64 `json:"cacheSavings,omitempty"`
+}
+
+// Tracker records token usage and cost for the session and the current day
+type Tracker struct {
+	mu       sync.Mutex
+	path     string
+	registry *models.ModelRegistry
+
+	sessionPromptTokens     int
+	sessionCompletionTokens int
+	sessionCost             float64
+	sessionCachedTokens     int
+	sessionCacheSavings     float64
+
+	daily dailyUsage
+}
+
+// NewTracker creates a tracker that persists daily usage to path
+func NewTracker(path string) *Tracker {
+	t := &Tracker{
+		path:     path,
+		registry: models.NewModelRegistry(),
+	}
+	t.load()
+	return t
+}
+
+// DefaultPath returns the default location of the daily usage file
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-usage.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "usage.json")
+}
+
+// EstimateTokens gives a rough token count for text (about 4 characters per token)
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+}
+
+// Turn is one message of a conversation
+type Turn struct {
+	Role    string // system, user, assistant, tool
+	Content string
+}
+
+// ReplayTokens estimates the tokens of running a conversation again request by
+// request: each assistant reply is a completion whose prompt is the system
+// prompt and everything before it. A trailing prompt without a reply counts as
+// one more request.
+func ReplayTokens(systemPrompt string, turns []Turn) (promptTokens, completionTokens int) {
+	context := EstimateTokens(systemPrompt)
+	pending := false
+	for _, turn := range turns {
+		tokens := EstimateTokens(turn.Content)
+		if turn.Role == "assistant" {
+			promptTokens += context
+			completionTokens += tokens
+			pending = false
+		} else {
+			pending = true
+		}
+		context += tokens
+	}
+	if pending {
+		promptTokens += context
+	}
+	return promptTokens, completionTokens
+}
+
+// Cost returns the price in USD for the given token counts, or 0 if the model has no pricing
+func (t *Tracker) Cost(model string, promptTokens, completionTokens int) float64 {
+	meta, ok := t.registry.GetModel(model)
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*meta.PricingInput + float64(completionTokens)*meta.PricingOutput) / 1_000_000
+}
+
+// CachedInputDiscount is the share of the input price saved on cached prompt
+// tokens when the model has no cached price of its own
+const CachedInputDiscount = 0.5
+
+// CacheSavings returns the USD saved by cachedTokens being served from the provider's prompt cache
+func (t *Tracker) CacheSavings(model string, cachedTokens int) float64 {
+	meta, ok := t.registry.GetModel(model)
+	if !ok || cachedTokens <= 0 {
+		return 0
+	}
+	saved := meta.PricingInput * CachedInputDiscount
+	if meta.PricingCachedInput > 0 {
+		saved = meta.PricingInput - meta.PricingCachedInput
+	}
+	return float64(cachedTokens) * saved / 1_000_000
+}
+
+// Check returns a *BudgetExceededError if sending promptTokens to model would break the budget
+func (t *Tracker) Check(model string, promptTokens int, budget Budget) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.load() // Include what other sessions spent today
+	t.rollover()
+
+	if budget.MaxTokensPerRequest > 0 && promptTokens > budget.MaxTokensPerRequest {
+		return &BudgetExceededError{Limit: "request tokens", Max: float64(budget.MaxTokensPerRequest), Value: float64(promptTokens)}
+	}
+
+	estimate := t.Cost(model, promptTokens, 0)
+	if budget.MaxCostPerSession > 0 && t.sessionCost+estimate > budget.MaxCostPerSession {
+		return &BudgetExceededError{Limit: "session cost", Max: budget.MaxCostPerSession, Value: t.sessionCost + estimate}
+	}
+	if budget.MaxCostPerDay > 0 && t.daily.Cost+estimate > budget.MaxCostPerDay {
+		return &BudgetExceededError{Limit: "daily cost", Max: budget.MaxCostPerDay, Value: t.daily.Cost + estimate}
+	}
+
+	return nil
+}
+
+// Record adds a completed request to the session and daily totals
+func (t *Tracker) Record(model string, promptTokens, completionTokens int) {
+	t.RecordCached(model, promptTokens, 0, completionTokens)
+}
+
+// RecordCached is Record for a request where cachedTokens of the prompt tokens
+// were prompt-cache hits; the cost is reduced by the savings
+func (t *Tracker) RecordCached(model string, promptTokens, cachedTokens, completionTokens int) {
+	if cachedTokens > promptTokens {
+		cachedTokens = promptTokens
+	}
+	savings := t.CacheSavings(model, cachedTokens)
+	cost := t.Cost(model, promptTokens, completionTokens) - savings
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	t.sessionPromptTokens += promptTokens
+	t.sessionCompletionTokens += completionTokens
+	t.sessionCost += cost
+	t.sessionCachedTokens += cachedTokens
+	t.sessionCacheSavings += savings
+
+	// Other sessions write the same file, so add to what is on disk now
+	unlock := t.lockFile()
+	defer unlock()
+	t.load()
+	t.rollover()
+
+	t.daily.PromptTokens += promptTokens
+	t.daily.CompletionTokens += completionTokens
+	t.daily.Cost += cost
+	t.daily.CachedTokens += cachedTokens
+	t.daily.CacheSavings += savings
+
+	t.save()
+}
+
+// SessionCost returns the cost accumulated in this session
+func (t *Tracker) SessionCost() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionCost
+}
+
+// DailyCost returns the cost accumulated today
+func (t *Tracker) DailyCost() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+	return t.daily.Cost
+}
+
+// SessionTokens returns the prompt and completion tokens used in this session
+func (t *Tracker) SessionTokens() (int, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionPromptTokens, t.sessionCompletionTokens
+}
+
+// DailyTokens returns the prompt and completion tokens used today
+func (t *Tracker) DailyTokens() (int, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+	return t.daily.PromptTokens, t.daily.CompletionTokens
+}
+
+// SessionCache returns the prompt-cache hits and USD saved in this session
+func (t *Tracker) SessionCache() (int, float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessionCachedTokens, t.sessionCacheSavings
+}
+
+// DailyCache returns the prompt-cache hits and USD saved today
+func (t *Tracker) DailyCache() (int, float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+	return t.daily.CachedTokens, t.daily.CacheSavings
+}
+
+// Remaining returns a short status line with the remaining budget, or "" if no cost limit is set
+func (t *Tracker) Remaining(budget Budget) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rollover()
+
+	status := ""
+	if budget.MaxCostPerSession > 0 {
+		status = fmt.Sprintf("session $%.2f left", budget.MaxCostPerSession-t.sessionCost)
+	}
+	if budget.MaxCostPerDay > 0 {
+		if status != "" {
+			status += ", "
+		}
+		status += fmt.Sprintf("today $%.2f left", budget.MaxCostPerDay-t.daily.Cost)
+	}
+	return status
+}
+
+// rollover resets the daily totals when the date changes (must be called with lock held)
+func (t *Tracker) rollover() {
+	today := time.Now().Format("2006-01-02")
+	if t.daily.Date != today {
+		t.daily = dailyUsage{Date: today}
+	}
+}
+
+// load reads the daily usage file, ignoring a missing or corrupt file
+func (t *Tracker) load() {
+	if t.path == "" {
+		return
+	}
+	data, err := os.ReadFile(t.path)
+	if err != nil {
+		return
+	}
+	var daily dailyUsage
+	if json.Unmarshal(data, &daily) == nil {
+		t.daily = daily
+	}
+}
+
+// Lock file timing: how long Record waits for another session, and the age
+// after which a lock is taken to be left over from a crash
+const (
+	lockWait  = 2 * time.Second
+	lockStale = 10 * time.Second
+)
+
+// lockFile serializes writes to the usage file between processes with a
+// lock file next to it, and returns the function that releases it. After
+// lockWait it goes ahead without the lock rather than lose the record.
+func (t *Tracker) lockFile() func() {
+	if t.path == "" {
+		return func() {}
+	}
+	lock := t.path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lock), 0755); err != nil {
+		return func() {}
+	}
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }
+		}
+		if info, statErr := os.Stat(lock); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lock)
+			continue
+		}
+		if !os.IsExist(err) || time.Now().After(deadline) {
+			return func() {}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// save writes the daily usage file (must be called with lock held). It
+// writes a temporary file and renames it, so a reader never sees half a file.
+func (t *Tracker) save() {
+	if t.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(t.daily, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.path), ".usage-*.json")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), t.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
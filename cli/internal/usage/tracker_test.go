@@ -0,0 +1,127 @@
+package usage
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCheckRequestTokens(t *testing.T) {
+	tracker := NewTracker("")
+	budget := Budget{MaxTokensPerRequest: 100}
+
+	if err := tracker.Check("gpt-4o", 50, budget); err != nil {
+		t.Fatalf("expected request under limit to pass, got %v", err)
+	}
+
+	err := tracker.Check("gpt-4o", 150, budget)
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError, got %v", err)
+	}
+	if budgetErr.Limit != "request tokens" {
+		t.Errorf("expected request tokens limit, got %q", budgetErr.Limit)
+	}
+}
+
+func TestUnsetBudgetNeverBlocks(t *testing.T) {
+	tracker := NewTracker("")
+	tracker.Record("gpt-4o", 1_000_000, 1_000_000)
+
+	if err := tracker.Check("gpt-4o", 1_000_000, Budget{}); err != nil {
+		t.Errorf("expected no error without a budget, got %v", err)
+	}
+	if status := tracker.Remaining(Budget{}); status != "" {
+		t.Errorf("expected empty status without a budget, got %q", status)
+	}
+}
+
+func TestDailyUsagePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+
+	tracker := NewTracker(path)
+	tracker.daily.Cost = 0 // Start from a clean day
+	tracker.Record("unknown-model", 10, 20)
+	tracker.mu.Lock()
+	tracker.daily.Cost = 4.5
+	tracker.save()
+	tracker.mu.Unlock()
+
+	reloaded := NewTracker(path)
+	if got := reloaded.DailyCost(); got != 4.5 {
+		t.Errorf("expected daily cost 4.5 after reload, got %v", got)
+	}
+	if got := reloaded.SessionCost(); got != 0 {
+		t.Errorf("expected a fresh session cost, got %v", got)
+	}
+
+	err := reloaded.Check("unknown-model", 10, Budget{MaxCostPerDay: 4})
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Limit != "daily cost" {
+		t.Errorf("expected daily cost error, got %v", err)
+	}
+}
+
+func TestConcurrentSessionsAddUp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+
+	// Both sessions start before either records anything
+	first := NewTracker(path)
+	second := NewTracker(path)
+
+	var wg sync.WaitGroup
+	for _, tracker := range []*Tracker{first, second} {
+		wg.Add(1)
+		go func(tracker *Tracker) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				tracker.Record("unknown-model", 10, 5)
+			}
+		}(tracker)
+	}
+	wg.Wait()
+
+	prompt, completion := NewTracker(path).DailyTokens()
+	if prompt != 400 || completion != 200 {
+		t.Errorf("expected 400 prompt and 200 completion tokens from both sessions, got %d and %d", prompt, completion)
+	}
+	if prompt, _ := first.SessionTokens(); prompt != 200 {
+		t.Errorf("expected the session to count only its own tokens, got %d", prompt)
+	}
+}
+
+func TestRecordCachedSavings(t *testing.T) {
+	tracker := NewTracker("")
+	tracker.RecordCached("gpt-4o", 1_000_000, 800_000, 0)
+
+	// 1M prompt tokens at $2.50, of which 800k at the cached $1.25
+	if got := tracker.SessionCost(); got < 1.4999 || got > 1.5001 {
+		t.Errorf("expected session cost $1.50, got %v", got)
+	}
+	cached, saved := tracker.SessionCache()
+	if cached != 800_000 || saved < 0.9999 || saved > 1.0001 {
+		t.Errorf("expected 800000 cached tokens saving $1.00, got %d and %v", cached, saved)
+	}
+	if cached, _ := tracker.DailyCache(); cached != 800_000 {
+		t.Errorf("expected daily cached tokens 800000, got %d", cached)
+	}
+}
+
+func TestReplayTokens(t *testing.T) {
+	turns := []Turn{
+		{Role: "user", Content: strings.Repeat("u", 40)},      // 10 tokens
+		{Role: "assistant", Content: strings.Repeat("a", 80)}, // 20 tokens
+		{Role: "user", Content: strings.Repeat("u", 20)},      // 5 tokens
+	}
+	prompt, completion := ReplayTokens(strings.Repeat("s", 8), turns) // 2 tokens
+
+	// First request: system + user; second: everything, still waiting for a reply
+	if prompt != (2+10)+(2+10+20+5) || completion != 20 {
+		t.Errorf("expected 49 prompt and 20 completion tokens, got %d and %d", prompt, completion)
+	}
+	if prompt, completion := ReplayTokens("", nil); prompt != 0 || completion != 0 {
+		t.Errorf("expected nothing to replay, got %d and %d", prompt, completion)
+	}
+}
@@ -0,0 +1,330 @@
+package users
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+)
+
+const (
+	// ProxyPrefix is where the LLM proxy is mounted; users set their base URL to it
+	ProxyPrefix = "/llm/"
+
+	sessionCookie   = "hackare_session"
+	sessionLifetime = 12 * time.Hour
+	maxRequestBody  = 10 << 20
+	usageTailSize   = 64 << 10
+)
+
+// contextKey is the type of values this package stores in request contexts
+type contextKey struct{}
+
+// FromContext returns the signed-in user of a request handled by Server
+func FromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(contextKey{}).(User)
+	return user, ok
+}
+
+// session is a signed-in browser
+type session struct {
+	name    string
+	expires time.Time
+}
+
+// Server puts serve mode behind sign-in and proxies LLM requests with per-user quotas
+type Server struct {
+	store       *Store
+	upstream    *url.URL
+	upstreamKey string
+
+	mu       sync.Mutex
+	sessions map[string]session
+}
+
+// NewServer creates a server for store. Requests under ProxyPrefix are sent to
+// upstream (e.g. http://localhost:8081/v1) with upstreamKey as bearer token,
+// if set; an empty upstream disables the proxy.
+func NewServer(store *Store, upstream, upstreamKey string) (*Server, error) {
+	s := &Server{store: store, upstreamKey: upstreamKey, sessions: make(map[string]session)}
+	if upstream != "" {
+		u, err := url.Parse(upstream)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid upstream URL %q", upstream)
+		}
+		s.upstream = u
+	}
+	return s, nil
+}
+
+// Wrap requires sign-in for every request to next and serves the user endpoints:
+// ProxyPrefix (the LLM proxy), /api/whoami and /logout.
+func (s *Server) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := s.authenticate(w, r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="hacka.re", charset="UTF-8"`)
+			http.Error(w, "Sign in required", http.StatusUnauthorized)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, user))
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, ProxyPrefix):
+			s.serveProxy(w, r, user)
+		case r.URL.Path == "/api/whoami":
+			s.serveWhoami(w, user)
+		case r.URL.Path == "/logout":
+			s.logout(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// authenticate accepts a session cookie or basic auth credentials, starting a
+// session for the latter so the password isn't checked on every request
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (User, bool) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		s.mu.Lock()
+		sess, ok := s.sessions[cookie.Value]
+		s.mu.Unlock()
+		if ok && time.Now().Before(sess.expires) {
+			// Re-read the user so removals, disabling and quota changes apply immediately
+			if user, err := s.store.Get(sess.name); err == nil && !user.Disabled {
+				return user, true
+			}
+		}
+	}
+
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return User{}, false
+	}
+	user, err := s.store.Authenticate(NormalizeName(name), password)
+	if err != nil {
+		logger.Get().Warn("[Users] Failed sign-in for %q from %s", name, r.RemoteAddr)
+		return User{}, false
+	}
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return User{}, false
+	}
+	value := hex.EncodeToString(token)
+	s.mu.Lock()
+	s.pruneSessions()
+	s.sessions[value] = session{name: user.Name, expires: time.Now().Add(sessionLifetime)}
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(sessionLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return user, true
+}
+
+// pruneSessions drops expired sessions (must be called with mu held)
+func (s *Server) pruneSessions() {
+	now := time.Now()
+	for token, sess := range s.sessions {
+		if now.After(sess.expires) {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+// logout ends the browser's session
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, cookie.Value)
+		s.mu.Unlock()
+	}
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1})
+	// Browsers keep basic auth credentials until they get a 401
+	w.Header().Set("WWW-Authenticate", `Basic realm="hacka.re", charset="UTF-8"`)
+	http.Error(w, "Signed out", http.StatusUnauthorized)
+}
+
+// whoami is the response of /api/whoami
+type whoami struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Admin     bool   `json:"admin"`
+	Quota     Quota  `json:"quota"`
+	Usage     Usage  `json:"usage"`
+	Proxy     string `json:"proxy,omitempty"` // Base URL to use for the LLM
+}
+
+// serveWhoami reports who is signed in, their upstream namespace and quota
+func (s *Server) serveWhoami(w http.ResponseWriter, user User) {
+	resp := whoami{Name: user.Name, Namespace: user.Namespace, Admin: user.Admin, Quota: user.Quota, Usage: user.Today()}
+	if s.upstream != nil {
+		resp.Proxy = strings.TrimSuffix(ProxyPrefix, "/")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serveProxy forwards an LLM request upstream after checking the user's quota
+func (s *Server) serveProxy(w http.ResponseWriter, r *http.Request, user User) {
+	if s.upstream == nil {
+		http.Error(w, "No LLM upstream configured", http.StatusNotFound)
+		return
+	}
+
+	body, err := tagRequest(r, user.Namespace)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.Allow(user.Name); err != nil {
+		var quotaErr *QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			writeOpenAIError(w, http.StatusTooManyRequests, quotaErr.Error())
+			return
+		}
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Completions can stream for longer than the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(s.upstream)
+			pr.Out.URL.Path = strings.TrimSuffix(s.upstream.Path, "/") + "/" + strings.TrimPrefix(r.URL.Path, ProxyPrefix)
+			pr.Out.URL.RawPath = ""
+			pr.Out.Host = s.upstream.Host
+			pr.Out.Header.Del("Cookie")
+			pr.Out.Header.Del("Authorization")
+			if s.upstreamKey != "" {
+				pr.Out.Header.Set("Authorization", "Bearer "+s.upstreamKey)
+			}
+			pr.Out.Header.Set("X-Hackare-Namespace", user.Namespace)
+			pr.Out.Body = io.NopCloser(bytes.NewReader(body))
+			pr.Out.ContentLength = int64(len(body))
+			pr.Out.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			if resp.StatusCode >= 300 {
+				return nil
+			}
+			resp.Body = &usageCounter{ReadCloser: resp.Body, done: func(tokens int) {
+				if err := s.store.RecordTokens(user.Name, tokens); err != nil {
+					logger.Get().Error("[Users] Failed to record usage for %s: %v", user.Name, err)
+				}
+			}}
+			return nil
+		},
+		FlushInterval: -1, // Stream tokens as they arrive
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// tagRequest reads the request body and, for JSON completions, sets the
+// OpenAI "user" field to the namespace and asks streams to report usage
+func tagRequest(r *http.Request, namespace string) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %w", err)
+	}
+	if len(body) > maxRequestBody {
+		return nil, errors.New("request too large")
+	}
+	if r.Method != http.MethodPost || !strings.Contains(r.Header.Get("Content-Type"), "json") {
+		return body, nil
+	}
+
+	var fields map[string]interface{}
+	if json.Unmarshal(body, &fields) != nil {
+		return body, nil
+	}
+	fields["user"] = namespace
+	if stream, _ := fields["stream"].(bool); stream {
+		options, _ := fields["stream_options"].(map[string]interface{})
+		if options == nil {
+			options = map[string]interface{}{}
+		}
+		options["include_usage"] = true
+		fields["stream_options"] = options
+	}
+	return json.Marshal(fields)
+}
+
+// writeOpenAIError writes an error in the OpenAI format so the web client shows it
+func writeOpenAIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{"message": message, "type": "quota_exceeded"},
+	})
+}
+
+// totalTokens finds usage totals in JSON responses and SSE chunks
+var totalTokens = regexp.MustCompile(`"total_tokens"\s*:\s*(\d+)`)
+
+// usageCounter passes a response through and reports the tokens it used on Close.
+// It takes the last "total_tokens" in the response, or estimates from its size.
+type usageCounter struct {
+	io.ReadCloser
+	tail   []byte
+	read   int
+	closed bool
+	done   func(tokens int)
+}
+
+// Read reads from the response, keeping its tail
+func (c *usageCounter) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += n
+	c.tail = append(c.tail, p[:n]...)
+	if len(c.tail) > usageTailSize {
+		c.tail = append(c.tail[:0], c.tail[len(c.tail)-usageTailSize:]...)
+	}
+	return n, err
+}
+
+// Close closes the response and reports the usage once
+func (c *usageCounter) Close() error {
+	err := c.ReadCloser.Close()
+	if !c.closed {
+		c.closed = true
+		c.done(c.tokens())
+	}
+	return err
+}
+
+// tokens returns the reported or estimated token count
+func (c *usageCounter) tokens() int {
+	matches := totalTokens.FindAllSubmatch(c.tail, -1)
+	if len(matches) > 0 {
+		if n, err := strconv.Atoi(string(matches[len(matches)-1][1])); err == nil {
+			return n
+		}
+	}
+	return (c.read + 3) / 4
+}
@@ -0,0 +1,345 @@
+// Package users adds local accounts to serve mode so a small team can share
+// one LLM host: each user signs in, gets their own namespace and is held to
+// a daily quota enforced by the server's LLM proxy.
+package users
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the shortest password accepted for an account
+const MinPasswordLength = 8
+
+var (
+	// ErrUserNotFound is returned for operations on an unknown user
+	ErrUserNotFound = errors.New("no such user")
+	// ErrInvalidCredentials is returned when a name or password is wrong or the account is disabled
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+// validName keeps user names and namespaces usable as identifiers and in paths
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// Quota limits a user's daily use of the LLM proxy (zero means unlimited)
+type Quota struct {
+	DailyRequests int `json:"dailyRequests,omitempty"`
+	DailyTokens   int `json:"dailyTokens,omitempty"`
+}
+
+// Usage is a user's proxy use on one day
+type Usage struct {
+	Date     string `json:"date"`
+	Requests int    `json:"requests"`
+	Tokens   int    `json:"tokens"`
+}
+
+// User is a serve-mode account
+type User struct {
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"passwordHash"` // bcrypt
+	Namespace    string    `json:"namespace"`    // Separates the user's data; defaults to the name
+	Admin        bool      `json:"admin,omitempty"`
+	Disabled     bool      `json:"disabled,omitempty"`
+	Quota        Quota     `json:"quota"`
+	Usage        Usage     `json:"usage"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// QuotaExceededError is returned when a request would go over a user's quota
+type QuotaExceededError struct {
+	Limit string // "requests" or "tokens"
+	Max   int
+}
+
+// Error implements the error interface
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("daily quota of %d %s reached", e.Max, e.Limit)
+}
+
+// Store persists users in a JSON file
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewStore creates a store backed by path
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the default location of the users file
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-users.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "users.json")
+}
+
+// Users returns all users in the order they were added
+func (s *Store) Users() ([]User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Get returns the user with the given name
+func (s *Store) Get(name string) (User, error) {
+	users, err := s.Users()
+	if err != nil {
+		return User{}, err
+	}
+	for _, user := range users {
+		if user.Name == name {
+			return user, nil
+		}
+	}
+	return User{}, fmt.Errorf("%w: %s", ErrUserNotFound, name)
+}
+
+// Add creates a user with the given password; the name and namespace must be unused
+func (s *Store) Add(user User, password string) error {
+	if !validName.MatchString(user.Name) {
+		return fmt.Errorf("invalid user name %q: use lowercase letters, digits, '.', '_' and '-'", user.Name)
+	}
+	if user.Namespace == "" {
+		user.Namespace = user.Name
+	}
+	if !validName.MatchString(user.Namespace) {
+		return fmt.Errorf("invalid namespace %q: use lowercase letters, digits, '.', '_' and '-'", user.Namespace)
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = hash
+	user.Usage = Usage{}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+
+	return s.update(func(users []User) ([]User, error) {
+		for _, existing := range users {
+			if existing.Name == user.Name {
+				return nil, fmt.Errorf("user %q already exists", user.Name)
+			}
+			if existing.Namespace == user.Namespace {
+				return nil, fmt.Errorf("namespace %q is already used by %s", user.Namespace, existing.Name)
+			}
+		}
+		return append(users, user), nil
+	})
+}
+
+// Remove deletes a user
+func (s *Store) Remove(name string) error {
+	return s.update(func(users []User) ([]User, error) {
+		for i, user := range users {
+			if user.Name == name {
+				return append(users[:i], users[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, name)
+	})
+}
+
+// SetPassword replaces a user's password
+func (s *Store) SetPassword(name, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.modify(name, func(user *User) { user.PasswordHash = hash })
+}
+
+// SetQuota replaces a user's daily quota
+func (s *Store) SetQuota(name string, quota Quota) error {
+	return s.modify(name, func(user *User) { user.Quota = quota })
+}
+
+// SetDisabled blocks or unblocks sign-in for a user
+func (s *Store) SetDisabled(name string, disabled bool) error {
+	return s.modify(name, func(user *User) { user.Disabled = disabled })
+}
+
+// SetAdmin grants or revokes admin rights
+func (s *Store) SetAdmin(name string, admin bool) error {
+	return s.modify(name, func(user *User) { user.Admin = admin })
+}
+
+// ResetUsage clears a user's usage for today
+func (s *Store) ResetUsage(name string) error {
+	return s.modify(name, func(user *User) { user.Usage = Usage{} })
+}
+
+// Authenticate checks a name and password and returns the enabled user
+func (s *Store) Authenticate(name, password string) (User, error) {
+	user, err := s.Get(name)
+	if err != nil {
+		// Compare anyway so unknown names take as long as wrong passwords
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return User{}, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil || user.Disabled {
+		return User{}, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// Allow returns a *QuotaExceededError if the user has used up today's quota,
+// otherwise it counts the request
+func (s *Store) Allow(name string) error {
+	return s.update(func(users []User) ([]User, error) {
+		user := find(users, name)
+		if user == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, name)
+		}
+		user.rollover()
+		if user.Quota.DailyRequests > 0 && user.Usage.Requests >= user.Quota.DailyRequests {
+			return nil, &QuotaExceededError{Limit: "requests", Max: user.Quota.DailyRequests}
+		}
+		if user.Quota.DailyTokens > 0 && user.Usage.Tokens >= user.Quota.DailyTokens {
+			return nil, &QuotaExceededError{Limit: "tokens", Max: user.Quota.DailyTokens}
+		}
+		user.Usage.Requests++
+		return users, nil
+	})
+}
+
+// RecordTokens adds the tokens a completed request used to today's usage
+func (s *Store) RecordTokens(name string, tokens int) error {
+	if tokens <= 0 {
+		return nil
+	}
+	return s.update(func(users []User) ([]User, error) {
+		if user := find(users, name); user != nil {
+			user.rollover()
+			user.Usage.Tokens += tokens
+		}
+		// The user was removed while the request ran
+		return users, nil
+	})
+}
+
+// Today returns the user's usage for the current day
+func (u User) Today() Usage {
+	u.rollover()
+	return u.Usage
+}
+
+// rollover resets the usage when the date changes
+func (u *User) rollover() {
+	today := time.Now().Format("2006-01-02")
+	if u.Usage.Date != today {
+		u.Usage = Usage{Date: today}
+	}
+}
+
+// dummyHash is compared against for unknown users, at the cost of real hashes
+// so that a sign-in takes as long whether or not the user exists
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("hacka.re"), bcrypt.DefaultCost)
+
+// hashPassword checks the password length and returns its bcrypt hash
+func hashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// find returns a pointer to the named user in users, or nil
+func find(users []User, name string) *User {
+	for i := range users {
+		if users[i].Name == name {
+			return &users[i]
+		}
+	}
+	return nil
+}
+
+// modify applies fn to the named user and saves
+func (s *Store) modify(name string, fn func(*User)) error {
+	return s.update(func(users []User) ([]User, error) {
+		user := find(users, name)
+		if user == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, name)
+		}
+		fn(user)
+		return users, nil
+	})
+}
+
+// update loads, modifies and saves the users under the lock
+func (s *Store) update(fn func([]User) ([]User, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, err := s.load()
+	if err != nil {
+		return err
+	}
+	users, err = fn(users)
+	if err != nil {
+		return err
+	}
+	return s.save(users)
+}
+
+// usersFile is the on-disk format
+type usersFile struct {
+	Users []User `json:"users"`
+}
+
+// load reads the users (must be called with mu held); a missing file means no users
+func (s *Store) load() ([]User, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users: %w", err)
+	}
+
+	var file usersFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse users %s: %w", s.path, err)
+	}
+	return file.Users, nil
+}
+
+// save writes the users atomically (must be called with mu held).
+// The file holds password hashes, so it is only readable by the owner.
+func (s *Store) save(users []User) error {
+	data, err := json.MarshalIndent(usersFile{Users: users}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode users: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create users directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write users: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// NormalizeName lowercases and trims a user name as typed on the command line
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,146 @@
+package users
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *Store {
+	store := NewStore(filepath.Join(t.TempDir(), "users.json"))
+	if err := store.Add(User{Name: "alice", Quota: Quota{DailyRequests: 2}}, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestStoreAuthenticate(t *testing.T) {
+	store := newTestStore(t)
+
+	user, err := store.Authenticate("alice", "correct horse")
+	if err != nil || user.Namespace != "alice" {
+		t.Fatalf("Authenticate = %+v, %v; want alice in namespace alice", user, err)
+	}
+	if _, err := store.Authenticate("alice", "wrong"); err != ErrInvalidCredentials {
+		t.Errorf("wrong password: err = %v", err)
+	}
+	if _, err := store.Authenticate("bob", "correct horse"); err != ErrInvalidCredentials {
+		t.Errorf("unknown user: err = %v", err)
+	}
+
+	store.SetDisabled("alice", true)
+	if _, err := store.Authenticate("alice", "correct horse"); err != ErrInvalidCredentials {
+		t.Errorf("disabled user: err = %v", err)
+	}
+
+	if err := store.Add(User{Name: "bob", Namespace: "alice"}, "long enough"); err == nil {
+		t.Error("duplicate namespace accepted")
+	}
+	if err := store.Add(User{Name: "Bob"}, "long enough"); err == nil {
+		t.Error("uppercase name accepted")
+	}
+	if err := store.Add(User{Name: "bob"}, "short"); err == nil {
+		t.Error("short password accepted")
+	}
+}
+
+func TestStoreQuota(t *testing.T) {
+	store := newTestStore(t)
+
+	for i := 0; i < 2; i++ {
+		if err := store.Allow("alice"); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	var quotaErr *QuotaExceededError
+	if err := store.Allow("alice"); !errors.As(err, &quotaErr) || quotaErr.Limit != "requests" {
+		t.Fatalf("third request: err = %v, want requests quota", err)
+	}
+
+	store.SetQuota("alice", Quota{DailyTokens: 100})
+	store.RecordTokens("alice", 150)
+	if err := store.Allow("alice"); !errors.As(err, &quotaErr) || quotaErr.Limit != "tokens" {
+		t.Fatalf("err = %v, want tokens quota", err)
+	}
+
+	store.ResetUsage("alice")
+	if err := store.Allow("alice"); err != nil {
+		t.Errorf("after reset: %v", err)
+	}
+}
+
+func TestServerProxy(t *testing.T) {
+	var upstreamBody map[string]interface{}
+	var upstreamAuth, upstreamPath string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamPath = r.URL.Path
+		upstreamAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&upstreamBody)
+		io.WriteString(w, `{"choices":[{"message":{"content":"hi"}}],"usage":{"total_tokens":42}}`)
+	}))
+	defer upstream.Close()
+
+	store := newTestStore(t)
+	server, err := NewServer(store, upstream.URL+"/v1", "sk-upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := httptest.NewServer(server.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "index")
+	})))
+	defer site.Close()
+
+	resp, _ := http.Get(site.URL + "/")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Fatalf("anonymous request: status %d", resp.StatusCode)
+	}
+
+	// Sign in with basic auth and continue with the session cookie, as a browser would
+	req, _ := http.NewRequest("GET", site.URL+"/", nil)
+	req.SetBasicAuth("alice", "correct horse")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	cookies := resp.Cookies()
+	if resp.StatusCode != http.StatusOK || len(cookies) != 1 {
+		t.Fatalf("sign-in: status %d, cookies %v", resp.StatusCode, cookies)
+	}
+
+	completion := func() *http.Response {
+		req, _ := http.NewRequest("POST", site.URL+"/llm/chat/completions",
+			strings.NewReader(`{"model":"local","messages":[]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer user-key")
+		req.AddCookie(cookies[0])
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := completion(); resp.StatusCode != http.StatusOK {
+		t.Fatalf("completion: status %d", resp.StatusCode)
+	}
+	if upstreamPath != "/v1/chat/completions" || upstreamAuth != "Bearer sk-upstream" || upstreamBody["user"] != "alice" {
+		t.Errorf("upstream got path %q, auth %q, user %v", upstreamPath, upstreamAuth, upstreamBody["user"])
+	}
+	if user, _ := store.Get("alice"); user.Today().Tokens != 42 || user.Today().Requests != 1 {
+		t.Errorf("usage = %+v, want 1 request and 42 tokens", user.Today())
+	}
+
+	completion()
+	if resp := completion(); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("over quota: status %d, want 429", resp.StatusCode)
+	}
+}
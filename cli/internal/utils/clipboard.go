@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -79,4 +83,55 @@ func SetClipboardContent(text string) error {
 	}
 
 	return cmd.Wait()
-}
\ No newline at end of file
+}
+
+// ErrNoClipboardImage is returned when the clipboard holds no image, or no
+// tool to read images from it is installed
+var ErrNoClipboardImage = errors.New("no image in the clipboard")
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// GetClipboardImage returns the image in the system clipboard as PNG, using
+// pngpaste on macOS, xclip or wl-paste on Linux and PowerShell on Windows
+func GetClipboardImage() ([]byte, error) {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin": // macOS
+		if _, err := exec.LookPath("pngpaste"); err != nil {
+			return nil, fmt.Errorf("%w (install pngpaste to paste images)", ErrNoClipboardImage)
+		}
+		cmd = exec.Command("pngpaste", "-")
+	case "linux":
+		if _, err := exec.LookPath("xclip"); err == nil {
+			cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "image/png", "-o")
+		} else if _, err := exec.LookPath("wl-paste"); err == nil {
+			cmd = exec.Command("wl-paste", "--type", "image/png")
+		} else {
+			return nil, fmt.Errorf("%w (install xclip or wl-clipboard to paste images)", ErrNoClipboardImage)
+		}
+	case "windows":
+		// PowerShell writes text, so the PNG comes back base64 encoded
+		script := `Add-Type -AssemblyName System.Windows.Forms; $img = [Windows.Forms.Clipboard]::GetImage(); ` +
+			`if ($img) { $ms = New-Object IO.MemoryStream; $img.Save($ms, [Drawing.Imaging.ImageFormat]::Png); [Convert]::ToBase64String($ms.ToArray()) }`
+		output, err := exec.Command("powershell", "-sta", "-command", script).Output()
+		if err != nil {
+			return nil, err
+		}
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(output)))
+		if err != nil || !bytes.HasPrefix(data, pngSignature) {
+			return nil, ErrNoClipboardImage
+		}
+		return data, nil
+	default:
+		return nil, ErrNoClipboardImage
+	}
+
+	// The tools fail or print something else when the clipboard holds text
+	output, err := cmd.Output()
+	if err != nil || !bytes.HasPrefix(output, pngSignature) {
+		return nil, ErrNoClipboardImage
+	}
+	return output, nil
+}
@@ -45,6 +45,10 @@ func DisplayConfig(cfg *config.Config) {
 		fmt.Printf("│ RAG:          Enabled (%d docs)\n", len(cfg.RAGDocuments))
 	}
 
+	if cfg.Namespace != "" {
+		fmt.Printf("│ Namespace:    %s\n", cfg.Namespace)
+	}
+
 	fmt.Println("└─────────────────────────────────────────────┘")
 }
 
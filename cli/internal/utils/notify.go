@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// SendDesktopNotification shows a desktop notification using the platform notifier
+func SendDesktopNotification(title, message string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin": // macOS
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux":
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return fmt.Errorf("notify-send not found: %w", err)
+		}
+		cmd = exec.Command("notify-send", "--app-name=hacka.re", title, message)
+	case "windows":
+		// Use a balloon tip from Windows Forms, available without extra modules
+		script := strings.Join([]string{
+			"Add-Type -AssemblyName System.Windows.Forms",
+			"$n = New-Object System.Windows.Forms.NotifyIcon",
+			"$n.Icon = [System.Drawing.SystemIcons]::Information",
+			"$n.Visible = $true",
+			fmt.Sprintf("$n.ShowBalloonTip(5000, '%s', '%s', 'Info')", escapePowerShell(title), escapePowerShell(message)),
+			"Start-Sleep -Seconds 5",
+			"$n.Dispose()",
+		}, "; ")
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+		// Don't wait for the balloon to expire
+		return cmd.Start()
+	default:
+		return nil // Silent fail on unsupported platforms
+	}
+
+	return cmd.Run()
+}
+
+// escapePowerShell escapes a string for use inside single quotes in PowerShell
+func escapePowerShell(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"sync"
+	"syscall"
+)
+
+// teardownEntry is a registered cleanup function
+type teardownEntry struct {
+	id int
+	fn func()
+}
+
+// teardown holds cleanup functions that must run before the process exits,
+// such as restoring the terminal from raw or alternate-screen mode
+var teardown struct {
+	mu      sync.Mutex
+	nextID  int
+	entries []teardownEntry
+}
+
+// OnExit registers fn to run on any exit path (Exit, Fatalf, signals, panics).
+// The returned function runs fn immediately and unregisters it; defer it for normal returns.
+func OnExit(fn func()) func() {
+	teardown.mu.Lock()
+	teardown.nextID++
+	id := teardown.nextID
+	teardown.entries = append(teardown.entries, teardownEntry{id: id, fn: fn})
+	teardown.mu.Unlock()
+
+	return func() {
+		if entry, ok := takeTeardown(id); ok {
+			entry.fn()
+		}
+	}
+}
+
+// takeTeardown removes and returns the entry with the given id
+func takeTeardown(id int) (teardownEntry, bool) {
+	teardown.mu.Lock()
+	defer teardown.mu.Unlock()
+
+	for i, entry := range teardown.entries {
+		if entry.id == id {
+			teardown.entries = append(teardown.entries[:i], teardown.entries[i+1:]...)
+			return entry, true
+		}
+	}
+	return teardownEntry{}, false
+}
+
+// RunTeardown runs all registered cleanup functions, most recent first
+func RunTeardown() {
+	teardown.mu.Lock()
+	entries := teardown.entries
+	teardown.entries = nil
+	teardown.mu.Unlock()
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entries[i].fn()
+	}
+}
+
+// Exit restores the terminal and exits with the given code
+func Exit(code int) {
+	RunTeardown()
+	os.Exit(code)
+}
+
+// Fatalf restores the terminal, prints the error to stderr and exits with code 1
+func Fatalf(format string, args ...interface{}) {
+	RunTeardown()
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+// signalsOnce ensures the signal handler is only installed once
+var signalsOnce sync.Once
+
+// HandleSignals restores the terminal before exiting on SIGINT, SIGTERM or SIGHUP.
+// Call it when entering raw or alternate-screen mode; repeated calls are no-ops.
+func HandleSignals() {
+	signalsOnce.Do(installSignalHandler)
+}
+
+// installSignalHandler runs the teardown and exits when a termination signal arrives
+func installSignalHandler() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	go func() {
+		sig := <-sigChan
+		RunTeardown()
+
+		// Conventional 128+n exit codes
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+		os.Exit(code)
+	}()
+}
+
+// RecoverPanic restores the terminal and reports a panic instead of leaving the
+// shell broken. Use it as `defer utils.RecoverPanic()` at the top of main and goroutines.
+func RecoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	RunTeardown()
+	fmt.Fprintf(os.Stderr, "hacka.re crashed: %v\n\n%s\n", r, debug.Stack())
+	os.Exit(70) // failure.ExitInternal; exit code 2 is reserved for configuration errors
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRunTeardownOrder(t *testing.T) {
+	var calls []string
+	OnExit(func() { calls = append(calls, "first") })
+	OnExit(func() { calls = append(calls, "second") })
+
+	RunTeardown()
+	RunTeardown() // Already run, must not repeat
+
+	if want := []string{"second", "first"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected %v, got %v", want, calls)
+	}
+}
+
+func TestOnExitReleaseRunsOnce(t *testing.T) {
+	count := 0
+	release := OnExit(func() { count++ })
+
+	release()
+	release()
+	RunTeardown()
+
+	if count != 1 {
+		t.Errorf("expected cleanup to run once, ran %d times", count)
+	}
+}
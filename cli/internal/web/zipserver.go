@@ -7,11 +7,20 @@ import (
 	_ "embed"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/hacka-re/cli/internal/accesslog"
+	"github.com/hacka-re/cli/internal/classroom"
+	"github.com/hacka-re/cli/internal/csp"
+	"github.com/hacka-re/cli/internal/metrics"
+	"github.com/hacka-re/cli/internal/shareqr"
+	"github.com/hacka-re/cli/internal/users"
 )
 
 // Embed the release ZIP file at compile time
@@ -19,12 +28,27 @@ import (
 //go:embed hacka.re-release.zip
 var embeddedZip []byte
 
+// ServerName is sent in the Server header, so a running instance can be
+// recognized and reused
+const ServerName = "hacka.re"
+
 // Server represents the base web server
 type Server struct {
 	port    int
 	host    string
 	server  *http.Server
 	verbose int
+	quiet   bool          // Don't announce the server on stdout
+	metrics bool          // Expose /metrics and count requests
+	users   *users.Server // Require sign-in and proxy LLM requests per user
+
+	classroom *classroom.Publisher // Serve a share link to `hacka.re join`
+	shareQR   *shareqr.Page        // Serve the session link as a QR code page
+	listener  net.Listener         // Serve on this instead of host:port
+	security  csp.Options          // Security headers, strict unless relaxed
+
+	accessLog   *accesslog.Logger      // Log every request
+	rateLimiter *accesslog.RateLimiter // Limit requests per client
 }
 
 // ZipServer serves files from an embedded ZIP archive
@@ -148,19 +172,172 @@ func (s *ZipServer) Start() error {
 	
 	// Use the base server's start logic
 	s.Server.server = &http.Server{
-		Handler:      handler,
+		Handler:      withServerName(s.withAccessLog(s.withRateLimit(csp.Wrap(s.withClassroom(s.withUsers(s.withShareQR(s.withMetrics(handler)))), s.security)))),
 		Addr:        fmt.Sprintf("%s:%d", s.host, s.port),
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 	
-	fmt.Printf("Starting web server on http://%s:%d\n", s.host, s.port)
-	fmt.Println("Press Ctrl+C to stop the server")
-	
+	if !s.quiet {
+		fmt.Printf("Starting web server on %s\n", s.GetURL())
+		fmt.Println("Press Ctrl+C to stop the server")
+	}
+
+	if s.listener != nil {
+		return s.Server.server.Serve(s.listener)
+	}
 	return s.Server.server.ListenAndServe()
 }
 
+// SetQuiet keeps Start from printing, for a server started behind the scenes
+// such as from the chat (call before Start)
+func (s *ZipServer) SetQuiet() {
+	s.quiet = true
+}
+
+// withServerName sets the Server header on every response
+func withServerName(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", ServerName)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UseListener serves on listener, such as a unix or systemd-activated socket,
+// instead of host:port (call before Start)
+func (s *ZipServer) UseListener(listener net.Listener) {
+	s.listener = listener
+}
+
+// SetSecurityHeaders relaxes the default security headers, e.g. to allow
+// embedding (call before Start)
+func (s *ZipServer) SetSecurityHeaders(opts csp.Options) {
+	s.security = opts
+}
+
+// EnableAccessLog logs every request, including refused ones (call before Start)
+func (s *ZipServer) EnableAccessLog(logger *accesslog.Logger) {
+	s.accessLog = logger
+}
+
+// EnableRateLimit refuses requests from clients over their allowance (call before Start)
+func (s *ZipServer) EnableRateLimit(limiter *accesslog.RateLimiter) {
+	s.rateLimiter = limiter
+}
+
+// withAccessLog logs requests when an access log is enabled
+func (s *ZipServer) withAccessLog(next http.Handler) http.Handler {
+	if s.accessLog == nil {
+		return next
+	}
+	return s.accessLog.Wrap(next)
+}
+
+// withRateLimit limits requests per client when a rate limit is enabled
+func (s *ZipServer) withRateLimit(next http.Handler) http.Handler {
+	if s.rateLimiter == nil {
+		return next
+	}
+	return s.rateLimiter.Wrap(next)
+}
+
+// EnableMetrics exposes Prometheus metrics at /metrics (call before Start)
+func (s *ZipServer) EnableMetrics() {
+	s.metrics = true
+}
+
+// EnableUsers requires sign-in with the accounts of server (call before Start)
+func (s *ZipServer) EnableUsers(server *users.Server) {
+	s.users = server
+}
+
+// EnableClassroom serves publisher's link to students joining with its code
+// (call before Start)
+func (s *ZipServer) EnableClassroom(publisher *classroom.Publisher) {
+	s.classroom = publisher
+}
+
+// withClassroom serves the classroom link ahead of sign-in, since students
+// fetch it with the CLI and it is encrypted with the instructor's passphrase
+func (s *ZipServer) withClassroom(next http.Handler) http.Handler {
+	if s.classroom == nil {
+		return next
+	}
+	return s.classroom.Wrap(next)
+}
+
+// EnableShareQR serves page at shareqr.Path (call before Start)
+func (s *ZipServer) EnableShareQR(page *shareqr.Page) {
+	s.shareQR = page
+}
+
+// withShareQR serves the QR code page, behind sign-in when users are enabled
+func (s *ZipServer) withShareQR(next http.Handler) http.Handler {
+	if s.shareQR == nil {
+		return next
+	}
+	return s.shareQR.Wrap(next)
+}
+
+// withUsers puts everything, including /metrics, behind sign-in when users are enabled
+func (s *ZipServer) withUsers(next http.Handler) http.Handler {
+	if s.users == nil {
+		return next
+	}
+	return s.users.Wrap(next)
+}
+
+// withMetrics serves /metrics and counts requests when metrics are enabled
+func (s *ZipServer) withMetrics(next http.Handler) http.Handler {
+	if !s.metrics {
+		return next
+	}
+
+	metricsHandler := metrics.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metrics" {
+			metricsHandler.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		metrics.HTTPRequests.Inc(r.Method, strconv.Itoa(recorder.status))
+		metrics.HTTPLatency.ObserveSince(start, r.Method)
+		metrics.HTTPResponseBytes.Add(float64(recorder.bytes), r.Method)
+		if recorder.status >= 500 {
+			metrics.Errors.Inc("web")
+		}
+	})
+}
+
+// statusRecorder captures the status code and body size written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the status code before writing it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the body bytes
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController flush streamed responses
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // Stop gracefully stops the web server
 func (s *ZipServer) Stop() error {
 	if s.Server.server == nil {
@@ -173,8 +350,15 @@ func (s *ZipServer) Stop() error {
 	return s.Server.server.Shutdown(ctx)
 }
 
-// GetURL returns the server URL
+// GetURL returns the server URL, or unix:PATH for a unix socket
 func (s *ZipServer) GetURL() string {
+	if s.listener != nil {
+		addr := s.listener.Addr()
+		if addr.Network() == "unix" {
+			return "unix:" + addr.String()
+		}
+		return "http://" + addr.String()
+	}
 	return fmt.Sprintf("http://%s:%d", s.host, s.port)
 }
 
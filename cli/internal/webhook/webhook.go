@@ -0,0 +1,394 @@
+// Package webhook posts signed JSON events to user-configured URLs when a
+// chat completion finishes, a tool runs, or a request breaks offline policy.
+//
+// Each delivery is a POST of {"id", "type", "time", "data"} with headers:
+//
+//	X-Hackare-Event:     the event type, e.g. "tool.executed"
+//	X-Hackare-Delivery:  the event id (the same across retries)
+//	X-Hackare-Timestamp: Unix seconds when the delivery was signed
+//	X-Hackare-Signature: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+//
+// Receivers should recompute the signature (see Verify) and reject stale
+// timestamps. Deliveries failing with a network error, 429 or 5xx are retried.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/logger"
+	"github.com/hacka-re/cli/internal/netguard"
+)
+
+// Event types
+const (
+	EventMessageCompleted = "message.completed"
+	EventToolExecuted     = "tool.executed"
+	EventPolicyViolation  = "policy.violation"
+)
+
+// EventTypes lists every event type that can be subscribed to
+var EventTypes = []string{EventMessageCompleted, EventToolExecuted, EventPolicyViolation}
+
+const (
+	maxAttempts     = 4
+	deliveryTimeout = 10 * time.Second
+)
+
+// retryBackoff is the wait before each retry, and shutdownTimeout how long
+// Shutdown waits for pending deliveries; vars so tests can shorten them
+var (
+	retryBackoff    = time.Second
+	shutdownTimeout = 5 * time.Second
+)
+
+// Hook is one configured webhook endpoint
+type Hook struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"` // Types or "prefix.*"; empty means all
+
+	// IncludeContent adds message text and tool arguments to event data.
+	// Off by default so chat content doesn't leave the machine unintentionally.
+	IncludeContent bool `json:"includeContent,omitempty"`
+}
+
+// Wants reports whether the hook subscribes to eventType
+func (h Hook) Wants(eventType string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, filter := range h.Events {
+		if filter == "*" || filter == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(filter, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the JSON body posted to a hook
+type Event struct {
+	ID   string                 `json:"id"`
+	Type string                 `json:"type"`
+	Time time.Time              `json:"time"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// dispatcher delivers events to the configured hooks in the background
+type dispatcher struct {
+	hooks  []Hook
+	client *http.Client
+	wg     sync.WaitGroup
+
+	ctx    context.Context // Cancelled by Shutdown to stop pending deliveries
+	cancel context.CancelFunc
+}
+
+// active is the configured dispatcher, or nil when no webhooks are set up
+var active *dispatcher
+
+// DefaultPath returns the default location of the webhook configuration
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "hacka.re-webhooks.json"
+	}
+	return filepath.Join(homeDir, ".config", "hacka.re", "webhooks.json")
+}
+
+// InitFromFile enables the webhooks configured in path, if any
+func InitFromFile(path string) error {
+	hooks, err := Load(path)
+	if err != nil {
+		return err
+	}
+	Init(hooks)
+	return nil
+}
+
+// Init enables delivery to hooks; an empty list disables webhooks
+func Init(hooks []Hook) {
+	if len(hooks) == 0 {
+		active = nil
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	active = &dispatcher{
+		hooks:  hooks,
+		client: netguard.Client(deliveryTimeout),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	logger.Get().Info("[Webhook] Delivering events to %d webhook(s)", len(hooks))
+}
+
+// Enabled reports whether any webhooks are configured
+func Enabled() bool {
+	return active != nil
+}
+
+// Emit sends an event to every hook subscribed to eventType. content holds
+// chat text or tool arguments and is only sent to hooks with IncludeContent.
+// It never blocks on delivery.
+func Emit(eventType string, data, content map[string]interface{}) {
+	d := active
+	if d == nil {
+		return
+	}
+
+	event := Event{
+		ID:   newID(),
+		Type: eventType,
+		Time: time.Now().UTC(),
+		Data: data,
+	}
+	for _, hook := range d.hooks {
+		if !hook.Wants(eventType) {
+			continue
+		}
+		payload := event
+		if hook.IncludeContent && len(content) > 0 {
+			payload.Data = merge(data, content)
+		}
+
+		d.wg.Add(1)
+		go func(hook Hook, payload Event) {
+			defer d.wg.Done()
+			if err := d.deliver(d.ctx, hook, payload); err != nil {
+				logger.Get().Warn("[Webhook] Failed to deliver %s to %s: %v", payload.Type, hook.URL, err)
+			}
+		}(hook, payload)
+	}
+}
+
+// EmitToolExecuted fires tool.executed for a JS function or MCP tool run.
+// Arguments are content: only hooks with IncludeContent receive them.
+func EmitToolExecuted(name, runtime string, start time.Time, args interface{}, err error) {
+	if active == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"name":       name,
+		"runtime":    runtime,
+		"status":     "ok",
+		"durationMs": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		data["status"] = "error"
+		data["error"] = err.Error()
+	}
+	Emit(EventToolExecuted, data, map[string]interface{}{"arguments": args})
+}
+
+// Shutdown waits briefly for in-flight deliveries so short-lived commands don't
+// drop them, then cancels the ones still running
+func Shutdown() {
+	d := active
+	if d == nil {
+		return
+	}
+	defer d.cancel()
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		logger.Get().Warn("[Webhook] Gave up waiting for pending deliveries")
+	}
+}
+
+// Send delivers one event to hook synchronously, with retries. It is used by
+// `webhook test` and ignores the hook's event filter.
+func Send(hook Hook, eventType string, data map[string]interface{}) error {
+	d := &dispatcher{client: netguard.Client(deliveryTimeout)}
+	return d.deliver(context.Background(), hook, Event{ID: newID(), Type: eventType, Time: time.Now().UTC(), Data: data})
+}
+
+// errPermanent marks a response that retrying won't fix
+var errPermanent = errors.New("permanent failure")
+
+// deliver posts the event, retrying transient failures with exponential
+// backoff until ctx is cancelled
+func (d *dispatcher) deliver(ctx context.Context, hook Hook, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		err = d.post(ctx, hook, event, body)
+		if err == nil || errors.Is(err, errPermanent) || attempt == maxAttempts {
+			return err
+		}
+		logger.Get().Debug("[Webhook] Attempt %d for %s failed: %v", attempt, hook.URL, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last attempt: %v)", ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one delivery attempt, signing it with the current time
+func (d *dispatcher) post(ctx context.Context, hook Hook, event Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errPermanent, err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hacka.re-cli")
+	req.Header.Set("X-Hackare-Event", event.Type)
+	req.Header.Set("X-Hackare-Delivery", event.ID)
+	req.Header.Set("X-Hackare-Timestamp", timestamp)
+	if hook.Secret != "" {
+		req.Header.Set("X-Hackare-Signature", Sign(hook.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("%w: endpoint returned status %d", errPermanent, resp.StatusCode)
+	}
+}
+
+// Sign returns the X-Hackare-Signature value for a body sent at timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature in constant time; receivers written in Go can use it directly
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Load reads the hooks in path; a missing file means none are configured
+func Load(path string) ([]Hook, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhooks: %w", err)
+	}
+
+	var file struct {
+		Hooks []Hook `json:"hooks"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse webhooks %s: %w", path, err)
+	}
+	return file.Hooks, nil
+}
+
+// Save writes hooks to path, readable only by the user since it holds secrets
+func Save(path string, hooks []Hook) error {
+	data, err := json.MarshalIndent(struct {
+		Hooks []Hook `json:"hooks"`
+	}{hooks}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode webhooks: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Validate checks a hook before it is saved
+func Validate(hook Hook) error {
+	if !strings.HasPrefix(hook.URL, "https://") && !strings.HasPrefix(hook.URL, "http://") {
+		return fmt.Errorf("webhook URL must start with http:// or https://: %q", hook.URL)
+	}
+	for _, filter := range hook.Events {
+		if filter == "*" || strings.HasSuffix(filter, "*") {
+			continue
+		}
+		known := false
+		for _, eventType := range EventTypes {
+			known = known || filter == eventType
+		}
+		if !known {
+			return fmt.Errorf("unknown event %q (known: %s)", filter, strings.Join(EventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// LocalOnly returns the hooks whose URL is on this machine or a private network,
+// for offline mode where nothing may leave the LAN
+func LocalOnly(hooks []Hook) []Hook {
+	var local []Hook
+	for _, hook := range hooks {
+		parsed, err := url.Parse(hook.URL)
+		if err != nil {
+			continue
+		}
+		host := parsed.Hostname()
+		if host == "localhost" {
+			local = append(local, hook)
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsPrivate()) {
+			local = append(local, hook)
+		}
+	}
+	return local
+}
+
+// merge returns a copy of data with extra added
+func merge(data, extra map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(data)+len(extra))
+	for k, v := range data {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
+
+// newID returns a random delivery id
+func newID() string {
+	var id [12]byte
+	rand.Read(id[:])
+	return "evt_" + hex.EncodeToString(id[:])
+}
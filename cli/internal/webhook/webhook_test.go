@@ -0,0 +1,156 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHookWants(t *testing.T) {
+	tests := []struct {
+		events []string
+		event  string
+		want   bool
+	}{
+		{nil, EventToolExecuted, true},
+		{[]string{EventToolExecuted}, EventToolExecuted, true},
+		{[]string{EventToolExecuted}, EventMessageCompleted, false},
+		{[]string{"policy.*"}, EventPolicyViolation, true},
+		{[]string{"policy.*"}, EventToolExecuted, false},
+		{[]string{"*"}, EventMessageCompleted, true},
+	}
+	for _, tt := range tests {
+		if got := (Hook{Events: tt.events}).Wants(tt.event); got != tt.want {
+			t.Errorf("Hook{Events: %v}.Wants(%q) = %v, want %v", tt.events, tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestEmitSignsAndRetries(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = time.Second }()
+
+	var mu sync.Mutex
+	var attempts int
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if !Verify("s3cret", r.Header.Get("X-Hackare-Timestamp"), body, r.Header.Get("X-Hackare-Signature")) {
+			t.Errorf("bad signature %q", r.Header.Get("X-Hackare-Signature"))
+		}
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("bad body: %v", err)
+		}
+		received = append(received, event)
+	}))
+	defer server.Close()
+
+	Init([]Hook{
+		{URL: server.URL, Secret: "s3cret", Events: []string{"tool.*"}},
+	})
+	defer Init(nil)
+
+	Emit(EventMessageCompleted, map[string]interface{}{"model": "m"}, nil)
+	Emit(EventToolExecuted, map[string]interface{}{"name": "lookup"}, map[string]interface{}{"arguments": "secret"})
+	Shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2 (one 503, one success)", attempts)
+	}
+	if len(received) != 1 || received[0].Type != EventToolExecuted {
+		t.Fatalf("received %+v, want one tool.executed event", received)
+	}
+	if _, ok := received[0].Data["arguments"]; ok {
+		t.Error("content was sent to a hook without IncludeContent")
+	}
+}
+
+func TestShutdownCancelsRetries(t *testing.T) {
+	retryBackoff, shutdownTimeout = time.Hour, 10*time.Millisecond
+	defer func() { retryBackoff, shutdownTimeout = time.Second, 5*time.Second }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	Init([]Hook{{URL: server.URL}})
+	defer Init(nil)
+	d := active
+
+	Emit(EventMessageCompleted, map[string]interface{}{"model": "m"}, nil)
+	Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the delivery kept waiting to retry after Shutdown")
+	}
+}
+
+func TestSendDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	if err := Send(Hook{URL: server.URL}, EventToolExecuted, nil); err == nil {
+		t.Error("Send succeeded against a 410 endpoint")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	hooks := []Hook{{URL: "https://example.com/hook", Secret: "x", Events: []string{EventPolicyViolation}}}
+	if err := Save(path, hooks); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0].URL != hooks[0].URL || loaded[0].Events[0] != EventPolicyViolation {
+		t.Errorf("Load = %+v", loaded)
+	}
+
+	if err := Validate(Hook{URL: "https://example.com", Events: []string{"message.sent"}}); err == nil {
+		t.Error("Validate accepted an unknown event")
+	}
+}
+
+func TestLocalOnly(t *testing.T) {
+	hooks := []Hook{
+		{URL: "http://localhost:9000/hook"},
+		{URL: "http://192.168.1.5/hook"},
+		{URL: "https://hooks.example.com/x"},
+	}
+	if local := LocalOnly(hooks); len(local) != 2 {
+		t.Errorf("LocalOnly kept %d hooks, want 2", len(local))
+	}
+}
@@ -0,0 +1,104 @@
+// Package webopen opens a chat's conversation in the web app served on this
+// machine, so work started in the terminal can go on in the browser.
+//
+// A `hacka.re serve` already running on the default port is reused. Otherwise
+// a server is started in the process on a free loopback port, and later calls
+// reuse it until the process exits. The settings and conversation go to the
+// browser in an encrypted link to that server, under a password made for the
+// occasion, which the web app asks for.
+package webopen
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hacka-re/cli/internal/browser"
+	"github.com/hacka-re/cli/internal/crypto"
+	"github.com/hacka-re/cli/internal/web"
+	"github.com/hacka-re/cli/pkg/sharelink"
+)
+
+// Result is what Open did
+type Result struct {
+	URL      string // Opened in the browser
+	Password string // Needed by the web app to open the link
+	Started  bool   // A server was started for this
+}
+
+var (
+	mu         sync.Mutex
+	startedURL string // Base URL of the server started in this process, "" until then
+)
+
+// DefaultURL returns where `hacka.re serve` listens by default
+func DefaultURL() string {
+	return fmt.Sprintf("http://localhost:%d", web.GetPortFromEnv(8080))
+}
+
+// Running reports whether a hacka.re server answers at baseURL
+func Running(baseURL string) bool {
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Head(baseURL + "/")
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.Header.Get("Server") == web.ServerName
+}
+
+// Server returns the base URL of a hacka.re server on this machine, starting
+// one if there is none, and whether it did
+func Server() (string, bool, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if startedURL != "" {
+		return startedURL, false, nil
+	}
+	if url := DefaultURL(); Running(url) {
+		return url, false, nil
+	}
+
+	server, err := web.NewZipServer("127.0.0.1", 0, 0)
+	if err != nil {
+		return "", false, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to start web server: %w", err)
+	}
+	server.SetQuiet()
+	server.UseListener(listener)
+	go server.Start()
+	startedURL = server.GetURL()
+	return startedURL, true, nil
+}
+
+// Link encrypts cfg into a link to the server at baseURL under a new password
+func Link(baseURL string, cfg *sharelink.Config) (link, password string, err error) {
+	if password, err = crypto.GenerateSecurePassword(); err != nil {
+		return "", "", err
+	}
+	if link, err = sharelink.Create(cfg, password, baseURL+"/"); err != nil {
+		return "", "", fmt.Errorf("failed to create link: %w", err)
+	}
+	return link, password, nil
+}
+
+// Open opens cfg in the web app on a local server with opener
+func Open(cfg *sharelink.Config, opener browser.Opener) (*Result, error) {
+	baseURL, started, err := Server()
+	if err != nil {
+		return nil, err
+	}
+	link, password, err := Link(baseURL, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := opener.Open(link); err != nil {
+		return nil, fmt.Errorf("failed to open the browser: %w", err)
+	}
+	return &Result{URL: link, Password: password, Started: started}, nil
+}
@@ -0,0 +1,75 @@
+package webopen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hacka-re/cli/internal/web"
+	"github.com/hacka-re/cli/pkg/sharelink"
+)
+
+func TestRunning(t *testing.T) {
+	ours := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", web.ServerName)
+	}))
+	defer ours.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer other.Close()
+
+	if !Running(ours.URL) {
+		t.Error("expected a hacka.re server to be recognized")
+	}
+	if Running(other.URL) {
+		t.Error("expected another server not to be reused")
+	}
+	other.Close()
+	if Running(other.URL) {
+		t.Error("expected nothing running at a closed server")
+	}
+}
+
+func TestLink(t *testing.T) {
+	cfg := &sharelink.Config{Model: "gpt-4o", Messages: []sharelink.Message{{Role: "user", Content: "hi"}}}
+	link, password, err := Link("http://127.0.0.1:41234", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(link, "http://127.0.0.1:41234/#gpt=") || password == "" {
+		t.Fatalf("unexpected link %q", link)
+	}
+	parsed, err := sharelink.Parse(link, password)
+	if err != nil || parsed.Model != "gpt-4o" || len(parsed.Messages) != 1 {
+		t.Fatalf("link doesn't carry the conversation: %+v, %v", parsed, err)
+	}
+	if _, again, _ := Link("http://127.0.0.1:41234", cfg); again == password {
+		t.Error("expected a new password per link")
+	}
+}
+
+// openerFunc opens URLs with a function, for tests
+type openerFunc func(url string) error
+
+func (f openerFunc) Open(url string) error { return f(url) }
+
+func TestOpenReusesStartedServer(t *testing.T) {
+	ours := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ours.Close()
+	mu.Lock()
+	startedURL = ours.URL
+	mu.Unlock()
+	defer func() { startedURL = "" }()
+
+	var opened string
+	result, err := Open(&sharelink.Config{Model: "m"}, openerFunc(func(url string) error {
+		opened = url
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Started || !strings.HasPrefix(opened, ours.URL+"/#gpt=") || opened != result.URL {
+		t.Fatalf("unexpected result %+v, opened %q", result, opened)
+	}
+}
@@ -0,0 +1,329 @@
+// Package yaml reads the YAML subset used by hacka.re's definition files
+// (crew agents, eval suites, snippets). It covers what those files need without a
+// third-party dependency.
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal parses data and stores the result in v, which is decoded as JSON
+// so that struct tags apply
+func Unmarshal(data []byte, v interface{}) error {
+	tree, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, v)
+}
+
+// Parse reads the YAML subset: nested mappings,
+// block and flow ("[a, b]") sequences, quoted and plain scalars, "|" and ">"
+// block scalars, and comments. Anchors, tags and multi-document files are not
+// supported. Plain scalars that look like numbers or booleans are typed.
+func Parse(data string) (interface{}, error) {
+	p := &yamlParser{lines: strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")}
+	i := p.skip(0)
+	if i >= len(p.lines) {
+		return map[string]interface{}{}, nil
+	}
+	value, next, err := p.block(i, indentOf(p.lines[i]))
+	if err != nil {
+		return nil, err
+	}
+	if next = p.skip(next); next < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", next+1)
+	}
+	return value, nil
+}
+
+type yamlParser struct {
+	lines []string
+}
+
+// skip returns the index of the next line with content
+func (p *yamlParser) skip(i int) int {
+	for i < len(p.lines) {
+		text := strings.TrimSpace(p.lines[i])
+		if text != "" && !strings.HasPrefix(text, "#") && text != "---" {
+			break
+		}
+		i++
+	}
+	return i
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// block parses the mapping or sequence starting at line i
+func (p *yamlParser) block(i, indent int) (interface{}, int, error) {
+	if strings.Contains(p.lines[i][:indent], "\t") || strings.HasPrefix(strings.TrimLeft(p.lines[i], " "), "\t") {
+		return nil, i, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+	}
+	if isSeqItem(strings.TrimSpace(p.lines[i])) {
+		return p.sequence(i, indent)
+	}
+	return p.mapping(i, indent)
+}
+
+func (p *yamlParser) sequence(i, indent int) (interface{}, int, error) {
+	items := []interface{}{}
+	for i = p.skip(i); i < len(p.lines) && indentOf(p.lines[i]) == indent; i = p.skip(i) {
+		text := strings.TrimSpace(p.lines[i])
+		if !isSeqItem(text) {
+			break // A list may sit at its parent key's indentation
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(text, "-"))
+
+		switch {
+		case rest == "":
+			next := p.skip(i + 1)
+			if next >= len(p.lines) || indentOf(p.lines[next]) <= indent {
+				items = append(items, nil)
+				i++
+				continue
+			}
+			value, n, err := p.block(next, indentOf(p.lines[next]))
+			if err != nil {
+				return nil, n, err
+			}
+			items, i = append(items, value), n
+		case mappingKey(rest) != "":
+			// "- key: value" starts a mapping indented to the key
+			afterDash := strings.TrimPrefix(text, "-")
+			keyIndent := indent + 1 + len(afterDash) - len(strings.TrimLeft(afterDash, " "))
+			p.lines[i] = strings.Repeat(" ", keyIndent) + rest
+			value, n, err := p.mapping(i, keyIndent)
+			if err != nil {
+				return nil, n, err
+			}
+			items, i = append(items, value), n
+		default:
+			value, err := scalar(rest)
+			if err != nil {
+				return nil, i, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			items, i = append(items, value), i+1
+		}
+	}
+	return items, i, nil
+}
+
+func (p *yamlParser) mapping(i, indent int) (interface{}, int, error) {
+	values := map[string]interface{}{}
+	for i = p.skip(i); i < len(p.lines) && indentOf(p.lines[i]) == indent; i = p.skip(i) {
+		text := strings.TrimSpace(p.lines[i])
+		key := mappingKey(text)
+		if key == "" || isSeqItem(text) {
+			return nil, i, fmt.Errorf("line %d: expected \"key: value\"", i+1)
+		}
+		if _, dup := values[unquote(key)]; dup {
+			return nil, i, fmt.Errorf("line %d: duplicate key %q", i+1, unquote(key))
+		}
+		rest := strings.TrimSpace(text[len(key)+1:])
+
+		switch {
+		case rest == "" || strings.HasPrefix(rest, "#"):
+			next := p.skip(i + 1)
+			if next < len(p.lines) && (indentOf(p.lines[next]) > indent ||
+				(indentOf(p.lines[next]) == indent && isSeqItem(strings.TrimSpace(p.lines[next])))) {
+				value, n, err := p.block(next, indentOf(p.lines[next]))
+				if err != nil {
+					return nil, n, err
+				}
+				values[unquote(key)], i = value, n
+				continue
+			}
+			values[unquote(key)], i = nil, i+1
+		case strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">"):
+			value, n := p.blockScalar(i, indent, rest)
+			values[unquote(key)], i = value, n
+		default:
+			value, err := scalar(rest)
+			if err != nil {
+				return nil, i, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			values[unquote(key)], i = value, i+1
+		}
+	}
+	return values, i, nil
+}
+
+// blockScalar reads the indented lines after a "|" or ">" indicator
+func (p *yamlParser) blockScalar(i, indent int, indicator string) (string, int) {
+	var lines []string
+	blockIndent := -1
+	i++
+	for ; i < len(p.lines); i++ {
+		line := p.lines[i]
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if indentOf(line) <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = indentOf(line)
+		}
+		if indentOf(line) < blockIndent {
+			break
+		}
+		lines = append(lines, line[blockIndent:])
+	}
+	// Trailing blank lines are not part of the text
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	text := strings.Join(lines, "\n")
+	if strings.HasPrefix(indicator, ">") {
+		text = foldLines(lines)
+	}
+	if !strings.Contains(indicator, "-") {
+		text += "\n"
+	}
+	return text, i
+}
+
+// foldLines joins lines with spaces, keeping blank lines as paragraph breaks
+func foldLines(lines []string) string {
+	var b strings.Builder
+	for n, line := range lines {
+		switch {
+		case line == "":
+			b.WriteString("\n")
+		case n > 0 && lines[n-1] != "":
+			b.WriteString(" " + line)
+		default:
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// mappingKey returns the key if text is "key:" or "key: value", else ""
+func mappingKey(text string) string {
+	if strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'") {
+		end := strings.Index(text[1:], text[:1])
+		if end < 0 || !strings.HasPrefix(text[end+2:], ":") {
+			return ""
+		}
+		return text[:end+2]
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return text[:i]
+		}
+		if text[i] == '#' || text[i] == '[' || text[i] == '{' {
+			return ""
+		}
+	}
+	return ""
+}
+
+// scalar parses a value on the same line as its key or list marker
+func scalar(text string) (interface{}, error) {
+	text = stripComment(text)
+	switch {
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("unterminated list %q", text)
+		}
+		items := []interface{}{}
+		for _, part := range splitFlow(text[1 : len(text)-1]) {
+			if part = strings.TrimSpace(part); part != "" {
+				value, err := scalar(part)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, value)
+			}
+		}
+		return items, nil
+	case strings.HasPrefix(text, "{"):
+		return nil, fmt.Errorf("inline mappings are not supported")
+	case strings.HasPrefix(text, "\"") || strings.HasPrefix(text, "'"):
+		if len(text) < 2 || text[len(text)-1] != text[0] {
+			return nil, fmt.Errorf("unterminated string %s", text)
+		}
+		return unquote(text), nil
+	}
+
+	switch text {
+	case "true", "True", "yes":
+		return true, nil
+	case "false", "False", "no":
+		return false, nil
+	case "null", "~":
+		return nil, nil
+	}
+	if n, err := strconv.ParseFloat(text, 64); err == nil {
+		return n, nil
+	}
+	return text, nil
+}
+
+// stripComment removes a trailing " # comment" outside quotes
+func stripComment(text string) string {
+	quote := byte(0)
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && i > 0 && text[i-1] == ' ':
+			return strings.TrimSpace(text[:i])
+		}
+	}
+	return strings.TrimSpace(text)
+}
+
+// splitFlow splits the inside of a flow list on commas outside quotes
+func splitFlow(text string) []string {
+	var parts []string
+	quote, start := byte(0), 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+func unquote(text string) string {
+	if len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"' {
+		if s, err := strconv.Unquote(text); err == nil {
+			return s
+		}
+		return text[1 : len(text)-1]
+	}
+	if len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'' {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	return text
+}
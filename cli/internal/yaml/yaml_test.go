@@ -0,0 +1,54 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAMLSubset(t *testing.T) {
+	got, err := Parse(`
+# comment
+name: "quoted # not a comment"
+tools: [nmap, 'who''s', "x,y"]
+list:
+- plain
+-   nested: true
+    count: 2
+folded: >-
+  one
+  two
+
+  three
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name":   "quoted # not a comment",
+		"tools":  []interface{}{"nmap", "who's", "x,y"},
+		"list":   []interface{}{"plain", map[string]interface{}{"nested": true, "count": 2.0}},
+		"folded": "one two\nthree",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %#v\nwant %#v", got, want)
+	}
+
+	for _, bad := range []string{"a: 1\na: 2", "agents:\n  - name: x\n flow: y", "a: [1, 2"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var v struct {
+		Name  string   `json:"name"`
+		Items []string `json:"items"`
+	}
+	if err := Unmarshal([]byte("name: x\nitems:\n  - a\n  - b\n"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "x" || !reflect.DeepEqual(v.Items, []string{"a", "b"}) {
+		t.Errorf("Unmarshal() = %+v", v)
+	}
+}
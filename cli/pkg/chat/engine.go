@@ -0,0 +1,392 @@
+// Package chat embeds the hacka.re chat pipeline in other Go programs.
+//
+// An Engine talks to any OpenAI-compatible provider, keeps the conversation
+// history, streams responses and runs registered tools when the model calls them:
+//
+//	engine, err := chat.NewEngine(chat.Config{
+//		Provider: "openai",
+//		APIKey:   os.Getenv("OPENAI_API_KEY"),
+//		Model:    "gpt-4o-mini",
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	engine.RegisterTool(chat.Tool{
+//		Name:        "lookup_port",
+//		Description: "Returns the service usually running on a TCP port",
+//		Parameters: map[string]interface{}{
+//			"type":       "object",
+//			"properties": map[string]interface{}{"port": map[string]interface{}{"type": "integer"}},
+//		},
+//		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+//			return lookupPort(args["port"]), nil
+//		},
+//	})
+//
+//	reply, err := engine.Send(ctx, "What usually runs on port 5432?")
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hacka-re/cli/internal/api"
+	"github.com/hacka-re/cli/internal/config"
+	"github.com/hacka-re/cli/internal/jsruntime"
+)
+
+// DefaultMaxToolRounds limits how many times the model may call tools before answering
+const DefaultMaxToolRounds = 8
+
+// ErrTooManyToolRounds is returned when the model keeps calling tools past Config.MaxToolRounds
+var ErrTooManyToolRounds = errors.New("model kept calling tools without answering")
+
+// Config configures an Engine. Only Model is required when BaseURL or Provider is set.
+type Config struct {
+	Provider     string  // openai, groq, ollama, ... (used to pick a default BaseURL)
+	BaseURL      string  // OpenAI-compatible endpoint, e.g. http://localhost:11434/v1
+	APIKey       string
+	Model        string
+	MaxTokens    int     // 0 uses the hacka.re default
+	Temperature  float64 // 0 leaves the provider default
+	SystemPrompt string
+
+	// MaxToolRounds caps model/tool round trips per message (0 uses DefaultMaxToolRounds)
+	MaxToolRounds int
+
+	// Offline restricts requests to local endpoints, as with the CLI's --offline flag
+	Offline bool
+}
+
+// Message is one entry in the conversation history
+type Message struct {
+	Role    string // system, user, assistant or tool
+	Content string
+}
+
+// ToolFunc runs a tool with the arguments chosen by the model.
+// The result is JSON-encoded (strings are passed as-is) and sent back to the model.
+type ToolFunc func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+
+// Tool is a function the model may call
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON schema of the arguments
+	Handler     ToolFunc
+}
+
+// ToolCall records a tool invocation made while answering a message
+type ToolCall struct {
+	Name      string
+	Arguments map[string]interface{}
+	Result    string
+	Err       error
+}
+
+// Usage is the token usage reported by the provider, summed over tool rounds
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// Response is the final answer to a message
+type Response struct {
+	Content   string
+	ToolCalls []ToolCall
+	Usage     Usage
+}
+
+// StreamFunc receives response text as it arrives; returning an error aborts the request
+type StreamFunc func(chunk string) error
+
+// Engine is a chat session with history and tools. It is safe for concurrent use,
+// but messages are answered one at a time.
+type Engine struct {
+	mu            sync.Mutex
+	cfg           *config.Config
+	client        *api.Client
+	history       []api.Message
+	tools         map[string]Tool
+	toolOrder     []string
+	maxToolRounds int
+}
+
+// NewEngine creates an engine for the configured provider
+func NewEngine(cfg Config) (*Engine, error) {
+	internalCfg := config.NewConfig()
+	if cfg.Provider != "" {
+		internalCfg.Provider = config.Provider(cfg.Provider)
+		internalCfg.BaseURL = config.GetProviderBaseURL(internalCfg.Provider)
+	}
+	if cfg.BaseURL != "" {
+		internalCfg.BaseURL = cfg.BaseURL
+	}
+	if internalCfg.BaseURL == "" {
+		return nil, fmt.Errorf("no base URL for provider %q", cfg.Provider)
+	}
+	if cfg.Model == "" {
+		return nil, errors.New("model is required")
+	}
+	internalCfg.Model = cfg.Model
+	internalCfg.APIKey = cfg.APIKey
+	if cfg.MaxTokens > 0 {
+		internalCfg.MaxTokens = cfg.MaxTokens
+	}
+	internalCfg.Temperature = cfg.Temperature
+	internalCfg.SystemPrompt = cfg.SystemPrompt
+	internalCfg.IsOfflineMode = cfg.Offline
+
+	maxToolRounds := cfg.MaxToolRounds
+	if maxToolRounds <= 0 {
+		maxToolRounds = DefaultMaxToolRounds
+	}
+
+	engine := &Engine{
+		cfg:           internalCfg,
+		client:        api.NewClient(internalCfg),
+		tools:         make(map[string]Tool),
+		maxToolRounds: maxToolRounds,
+	}
+	engine.resetHistory()
+	return engine, nil
+}
+
+// RegisterTool makes a tool available to the model, replacing any tool with the same name
+func (e *Engine) RegisterTool(tool Tool) error {
+	if tool.Name == "" {
+		return errors.New("tool name is required")
+	}
+	if tool.Handler == nil {
+		return fmt.Errorf("tool %s has no handler", tool.Name)
+	}
+	if tool.Parameters == nil {
+		tool.Parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.tools[tool.Name]; !exists {
+		e.toolOrder = append(e.toolOrder, tool.Name)
+	}
+	e.tools[tool.Name] = tool
+	e.syncTools()
+	return nil
+}
+
+// RegisterJSFunction registers a hacka.re JavaScript function (the same format as
+// the web UI's function calling) as a tool, run in the sandboxed JS runtime
+func (e *Engine) RegisterJSFunction(code string) error {
+	fn, err := jsruntime.ParseFunction(code)
+	if err != nil {
+		return fmt.Errorf("failed to parse function: %w", err)
+	}
+
+	var parameters map[string]interface{}
+	if function, ok := fn.ToToolDefinition()["function"].(map[string]interface{}); ok {
+		parameters, _ = function["parameters"].(map[string]interface{})
+	}
+
+	return e.RegisterTool(Tool{
+		Name:        fn.Name,
+		Description: fn.Description,
+		Parameters:  parameters,
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return fn.Execute(args)
+		},
+	})
+}
+
+// UnregisterTool removes a tool
+func (e *Engine) UnregisterTool(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.tools[name]; !exists {
+		return
+	}
+	delete(e.tools, name)
+	for i, toolName := range e.toolOrder {
+		if toolName == name {
+			e.toolOrder = append(e.toolOrder[:i], e.toolOrder[i+1:]...)
+			break
+		}
+	}
+	e.syncTools()
+}
+
+// syncTools passes the registered tools to the API client (must be called with mu held)
+func (e *Engine) syncTools() {
+	tools := make([]api.Tool, 0, len(e.toolOrder))
+	for _, name := range e.toolOrder {
+		tool := e.tools[name]
+		tools = append(tools, api.Tool{
+			Type: "function",
+			Function: api.ToolFunction{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+	e.client.SetTools(tools)
+}
+
+// Send sends a user message and waits for the complete answer
+func (e *Engine) Send(ctx context.Context, message string) (*Response, error) {
+	return e.run(ctx, message, nil)
+}
+
+// Stream sends a user message and calls onChunk with the answer as it arrives
+func (e *Engine) Stream(ctx context.Context, message string, onChunk StreamFunc) (*Response, error) {
+	if onChunk == nil {
+		return nil, errors.New("stream callback is required")
+	}
+	return e.run(ctx, message, onChunk)
+}
+
+// run appends the message and loops model calls and tool calls until the model answers.
+// On error the history is left as it was before the message.
+func (e *Engine) run(ctx context.Context, message string, onChunk StreamFunc) (*Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	history := append(append([]api.Message(nil), e.history...), api.Message{Role: "user", Content: message})
+	response := &Response{}
+
+	var callback api.StreamCallback
+	if onChunk != nil {
+		callback = api.StreamCallback(onChunk)
+	}
+	e.cfg.StreamResponse = onChunk != nil
+
+	for round := 0; round <= e.maxToolRounds; round++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		reply, err := e.client.SendChatCompletionContext(ctx, history, callback)
+		if err != nil {
+			return nil, err
+		}
+		if len(reply.Choices) == 0 {
+			return nil, errors.New("empty response from provider")
+		}
+		response.Usage.PromptTokens += reply.Usage.PromptTokens
+		response.Usage.CompletionTokens += reply.Usage.CompletionTokens
+
+		assistant := reply.Choices[0].Message
+		assistant.Role = "assistant"
+		history = append(history, assistant)
+
+		if len(assistant.ToolCalls) == 0 {
+			response.Content = assistant.Content
+			e.history = history
+			return response, nil
+		}
+
+		for _, call := range assistant.ToolCalls {
+			record := e.callTool(ctx, call)
+			response.ToolCalls = append(response.ToolCalls, record)
+			history = append(history, api.Message{
+				Role:       "tool",
+				ToolCallID: call.ID,
+				Content:    record.Result,
+			})
+		}
+	}
+
+	return nil, ErrTooManyToolRounds
+}
+
+// callTool runs one tool call; errors are reported to the model rather than aborting the loop
+func (e *Engine) callTool(ctx context.Context, call api.ToolCall) ToolCall {
+	record := ToolCall{Name: call.Function.Name, Arguments: map[string]interface{}{}}
+
+	if call.Function.Arguments != "" {
+		if err := json.Unmarshal([]byte(call.Function.Arguments), &record.Arguments); err != nil {
+			record.Err = fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	tool, exists := e.tools[call.Function.Name]
+	if !exists && record.Err == nil {
+		record.Err = fmt.Errorf("unknown tool %q", call.Function.Name)
+	}
+
+	if record.Err == nil {
+		result, err := tool.Handler(ctx, record.Arguments)
+		if err != nil {
+			record.Err = err
+		} else {
+			record.Result = encodeToolResult(result)
+		}
+	}
+
+	if record.Err != nil {
+		record.Result = fmt.Sprintf(`{"error": %q}`, record.Err.Error())
+	}
+	return record
+}
+
+// encodeToolResult turns a tool's return value into the text sent back to the model
+func encodeToolResult(result interface{}) string {
+	if text, ok := result.(string); ok {
+		return text
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("%v", result)
+	}
+	return string(data)
+}
+
+// History returns the conversation so far, excluding tool plumbing
+func (e *Engine) History() []Message {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	messages := make([]Message, 0, len(e.history))
+	for _, msg := range e.history {
+		if msg.Role == "tool" || (msg.Role == "assistant" && len(msg.ToolCalls) > 0 && msg.Content == "") {
+			continue
+		}
+		messages = append(messages, Message{Role: msg.Role, Content: msg.Content})
+	}
+	return messages
+}
+
+// SetHistory replaces the conversation, e.g. to resume a saved chat.
+// The system prompt from Config is kept unless messages start with their own.
+func (e *Engine) SetHistory(messages []Message) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.resetHistory()
+	if len(messages) > 0 && messages[0].Role == "system" {
+		e.history = nil
+	}
+	for _, msg := range messages {
+		e.history = append(e.history, api.Message{Role: msg.Role, Content: msg.Content})
+	}
+}
+
+// Reset clears the conversation, keeping the system prompt and tools
+func (e *Engine) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.resetHistory()
+}
+
+// resetHistory starts a new conversation (must be called with mu held or before the engine is shared)
+func (e *Engine) resetHistory() {
+	e.history = nil
+	if e.cfg.SystemPrompt != "" {
+		e.history = append(e.history, api.Message{Role: "system", Content: e.cfg.SystemPrompt})
+	}
+}
@@ -0,0 +1,157 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// toolServer answers the first request with a tool call and the second with text
+func toolServer(t *testing.T, stream bool) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Messages []struct {
+				Role       string `json:"role"`
+				Content    string `json:"content"`
+				ToolCallID string `json:"tool_call_id"`
+			} `json:"messages"`
+			Tools []json.RawMessage `json:"tools"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if len(request.Tools) != 1 {
+			t.Errorf("expected 1 tool in request, got %d", len(request.Tools))
+		}
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			if stream {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup_port","arguments":"{\"po"}}]}}]}`+"\n\n")
+				fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"rt\": 5432}"}}]},"finish_reason":"tool_calls"}]}`+"\n\n")
+				fmt.Fprint(w, "data: [DONE]\n\n")
+				return
+			}
+			fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup_port","arguments":"{\"port\": 5432}"}}]},"finish_reason":"tool_calls"}]}`)
+			return
+		}
+
+		last := request.Messages[len(request.Messages)-1]
+		if last.Role != "tool" || last.ToolCallID != "call_1" || last.Content != "postgresql" {
+			t.Errorf("expected tool result message, got %+v", last)
+		}
+
+		if stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"PostgreSQL "}}]}`+"\n\n")
+			fmt.Fprint(w, `data: {"choices":[{"index":0,"delta":{"content":"usually."}}]}`+"\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"PostgreSQL usually."},"finish_reason":"stop"}],"usage":{"prompt_tokens":12,"completion_tokens":4}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newTestEngine(t *testing.T, url string) *Engine {
+	t.Helper()
+	engine, err := NewEngine(Config{BaseURL: url + "/v1", Model: "test-model", SystemPrompt: "Be brief"})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	err = engine.RegisterTool(Tool{
+		Name:        "lookup_port",
+		Description: "Returns the service usually running on a TCP port",
+		Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			if args["port"] != float64(5432) {
+				return nil, fmt.Errorf("unexpected port %v", args["port"])
+			}
+			return "postgresql", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterTool() error = %v", err)
+	}
+	return engine
+}
+
+func TestEngineSendRunsTools(t *testing.T) {
+	server, calls := toolServer(t, false)
+	engine := newTestEngine(t, server.URL)
+
+	response, err := engine.Send(context.Background(), "What runs on 5432?")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if response.Content != "PostgreSQL usually." {
+		t.Errorf("Content = %q", response.Content)
+	}
+	if len(response.ToolCalls) != 1 || response.ToolCalls[0].Result != "postgresql" {
+		t.Errorf("ToolCalls = %+v", response.ToolCalls)
+	}
+	if response.Usage.PromptTokens != 12 {
+		t.Errorf("PromptTokens = %d, want 12", response.Usage.PromptTokens)
+	}
+	if *calls != 2 {
+		t.Errorf("expected 2 requests, got %d", *calls)
+	}
+
+	history := engine.History()
+	if len(history) != 3 || history[0].Role != "system" || history[2].Content != "PostgreSQL usually." {
+		t.Errorf("History() = %+v", history)
+	}
+}
+
+func TestEngineStreamRunsTools(t *testing.T) {
+	server, _ := toolServer(t, true)
+	engine := newTestEngine(t, server.URL)
+
+	var streamed strings.Builder
+	response, err := engine.Stream(context.Background(), "What runs on 5432?", func(chunk string) error {
+		streamed.WriteString(chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	if response.Content != "PostgreSQL usually." || streamed.String() != "PostgreSQL usually." {
+		t.Errorf("Content = %q, streamed = %q", response.Content, streamed.String())
+	}
+}
+
+func TestEngineKeepsHistoryOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"invalid key"}}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	engine, err := NewEngine(Config{BaseURL: server.URL + "/v1", Model: "test-model"})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	if _, err := engine.Send(context.Background(), "Hi"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(engine.History()) != 0 {
+		t.Errorf("expected history to be unchanged, got %+v", engine.History())
+	}
+}
+
+func TestNewEngineRequiresModel(t *testing.T) {
+	if _, err := NewEngine(Config{Provider: "openai"}); err == nil {
+		t.Error("expected an error without a model")
+	}
+}
@@ -0,0 +1,78 @@
+package sharelink
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// IncludeKeyPhrase is what a user types to put their API key in a link they
+// create. Any other answer leaves the key out.
+const IncludeKeyPhrase = "include key"
+
+// Contents lists what a link made from c carries, one part per line, for a
+// last look before the link is created. The API key is shown masked.
+func Contents(c *Config) []string {
+	var parts []string
+	add := func(format string, args ...interface{}) {
+		parts = append(parts, fmt.Sprintf(format, args...))
+	}
+
+	if c.APIKey != "" {
+		add("API key: %s", MaskKey(c.APIKey))
+	}
+	if c.BaseURL != "" || c.Model != "" {
+		host := c.BaseURL
+		if parsed, err := url.Parse(c.BaseURL); err == nil && parsed.Host != "" {
+			host = parsed.Host
+		}
+		switch {
+		case c.Model == "":
+			add("Provider: %s", host)
+		case host == "":
+			add("Model: %s", c.Model)
+		default:
+			add("Provider and model: %s @ %s", c.Model, host)
+		}
+	}
+	if c.SystemPrompt != "" {
+		add("System prompt: %s", summarize(c.SystemPrompt))
+	}
+	if c.WelcomeMessage != "" {
+		add("Welcome message: %s", summarize(c.WelcomeMessage))
+	}
+	if len(c.Prompts) > 0 {
+		add("Prompts: %d", len(c.Prompts))
+	}
+	if len(c.Functions) > 0 {
+		names := make([]string, len(c.Functions))
+		for i, f := range c.Functions {
+			names[i] = f.Name
+		}
+		add("Functions: %s", strings.Join(names, ", "))
+	}
+	if len(c.MCPServers) > 0 {
+		add("MCP servers: %s", mcpText(c.MCPServers))
+	}
+	if c.RAGEnabled || len(c.RAGDocuments) > 0 {
+		add("RAG: %d documents", len(c.RAGDocuments))
+	}
+	if len(c.Messages) > 0 {
+		add("Conversation: %d messages", len(c.Messages))
+	}
+	if c.Locked {
+		add("Locked: prompts, functions and provider open read-only")
+	}
+	if c.ExpiresAt > 0 {
+		add("Expires: %s", timeText(c.ExpiresAt))
+	}
+	return parts
+}
+
+// MaskKey shows the start and end of a key, enough to recognize it
+func MaskKey(key string) string {
+	if len(key) > 12 {
+		return key[:3] + "..." + key[len(key)-4:]
+	}
+	return "set"
+}
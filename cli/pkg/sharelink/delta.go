@@ -0,0 +1,265 @@
+package sharelink
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Delta is what changed from a named base configuration. A delta link is a
+// share link whose configuration only has Delta set; it is applied on top of
+// the configuration the recipient already has instead of replacing it.
+//
+// Settings are kept by their JSON name, with null for one the change clears.
+// Prompts and functions are kept one by one, by ID and by name. The API key
+// and the expiry are never part of a delta.
+type Delta struct {
+	Base             string                     `json:"base"`     // Name of the base, e.g. team-v3
+	BaseHash         string                     `json:"baseHash"` // Fingerprint of the base
+	Fields           map[string]json.RawMessage `json:"fields,omitempty"`
+	Prompts          []Prompt                   `json:"prompts,omitempty"` // Added or changed
+	RemovedPrompts   []string                   `json:"removedPrompts,omitempty"`
+	Functions        []Function                 `json:"functions,omitempty"` // Added or changed
+	RemovedFunctions []string                   `json:"removedFunctions,omitempty"`
+}
+
+// notInDelta are the fields a delta leaves alone: the recipient's own key and
+// expiry, the lists compared item by item, and the delta itself
+var notInDelta = map[string]bool{
+	"apiKey": true, "expiresAt": true, "prompts": true, "functions": true, "delta": true,
+}
+
+// fields returns the JSON fields of c that a delta can set
+func fields(c *Config) map[string]json.RawMessage {
+	data, _ := json.Marshal(c)
+	var m map[string]json.RawMessage
+	json.Unmarshal(data, &m)
+	for name := range m {
+		if notInDelta[name] {
+			delete(m, name)
+		}
+	}
+	return m
+}
+
+// Fingerprint identifies a base configuration by the parts a delta can change,
+// so two copies of a base match whatever their API keys
+func Fingerprint(c *Config) string {
+	m := fields(c)
+	prompts, _ := json.Marshal(c.Prompts)
+	functions, _ := json.Marshal(c.Functions)
+	m["prompts"], m["functions"] = prompts, functions
+	data, _ := json.Marshal(m) // Map keys are sorted
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
+
+// MakeDelta returns what changes base, saved as baseName, into target
+func MakeDelta(baseName string, base, target *Config) *Delta {
+	d := &Delta{Base: baseName, BaseHash: Fingerprint(base), Fields: map[string]json.RawMessage{}}
+
+	before, after := fields(base), fields(target)
+	for name, value := range after {
+		if !bytes.Equal(before[name], value) {
+			d.Fields[name] = value
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			d.Fields[name] = json.RawMessage("null")
+		}
+	}
+
+	oldPrompts := map[string]Prompt{}
+	for _, p := range base.Prompts {
+		oldPrompts[p.ID] = p
+	}
+	newPrompts := map[string]bool{}
+	for _, p := range target.Prompts {
+		newPrompts[p.ID] = true
+		if old, ok := oldPrompts[p.ID]; !ok || !sameJSON(old, p) {
+			d.Prompts = append(d.Prompts, p)
+		}
+	}
+	for _, p := range base.Prompts {
+		if !newPrompts[p.ID] {
+			d.RemovedPrompts = append(d.RemovedPrompts, p.ID)
+		}
+	}
+
+	oldFunctions := map[string]Function{}
+	for _, f := range base.Functions {
+		oldFunctions[f.Name] = f
+	}
+	newFunctions := map[string]bool{}
+	for _, f := range target.Functions {
+		newFunctions[f.Name] = true
+		if old, ok := oldFunctions[f.Name]; !ok || !sameJSON(old, f) {
+			d.Functions = append(d.Functions, f)
+		}
+	}
+	for _, f := range base.Functions {
+		if !newFunctions[f.Name] {
+			d.RemovedFunctions = append(d.RemovedFunctions, f.Name)
+		}
+	}
+	return d
+}
+
+func sameJSON(a, b interface{}) bool {
+	da, _ := json.Marshal(a)
+	db, _ := json.Marshal(b)
+	return bytes.Equal(da, db)
+}
+
+// Empty reports whether the delta changes nothing
+func (d *Delta) Empty() bool {
+	return len(d.Fields) == 0 && len(d.Prompts) == 0 && len(d.RemovedPrompts) == 0 &&
+		len(d.Functions) == 0 && len(d.RemovedFunctions) == 0
+}
+
+// Apply returns c with the delta's changes; c is left as it was. Prompts and
+// functions keep their order, with new ones added at the end.
+func (d *Delta) Apply(c *Config) (*Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for name, value := range d.Fields {
+		if notInDelta[name] {
+			continue
+		}
+		if string(value) == "null" {
+			delete(m, name)
+		} else {
+			m[name] = value
+		}
+	}
+	if data, err = json.Marshal(m); err != nil {
+		return nil, err
+	}
+	var merged Config
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, fmt.Errorf("failed to apply delta: %w", err)
+	}
+	merged.Namespace = c.Namespace
+
+	removed := map[string]bool{}
+	for _, id := range d.RemovedPrompts {
+		removed[id] = true
+	}
+	changed := map[string]Prompt{}
+	for _, p := range d.Prompts {
+		changed[p.ID] = p
+	}
+	merged.Prompts = nil
+	for _, p := range c.Prompts {
+		if removed[p.ID] {
+			continue
+		}
+		if p2, ok := changed[p.ID]; ok {
+			p = p2
+			delete(changed, p.ID)
+		}
+		merged.Prompts = append(merged.Prompts, p)
+	}
+	for _, p := range d.Prompts {
+		if _, ok := changed[p.ID]; ok {
+			merged.Prompts = append(merged.Prompts, p)
+		}
+	}
+
+	removed = map[string]bool{}
+	for _, name := range d.RemovedFunctions {
+		removed[name] = true
+	}
+	changedFunctions := map[string]Function{}
+	for _, f := range d.Functions {
+		changedFunctions[f.Name] = f
+	}
+	merged.Functions = nil
+	for _, f := range c.Functions {
+		if removed[f.Name] {
+			continue
+		}
+		if f2, ok := changedFunctions[f.Name]; ok {
+			f = f2
+			delete(changedFunctions, f.Name)
+		}
+		merged.Functions = append(merged.Functions, f)
+	}
+	for _, f := range d.Functions {
+		if _, ok := changedFunctions[f.Name]; ok {
+			merged.Functions = append(merged.Functions, f)
+		}
+	}
+	return &merged, nil
+}
+
+// ErrBaseMismatch is returned by Overrides when the base isn't the one the
+// delta was made from
+var ErrBaseMismatch = errors.New("the base differs from the one the delta was made from")
+
+// Overrides lists what applying the delta to current would overwrite that
+// the recipient changed since base: setting names, "prompt ID" and
+// "function NAME", sorted
+func (d *Delta) Overrides(base, current *Config) ([]string, error) {
+	if Fingerprint(base) != d.BaseHash {
+		return nil, ErrBaseMismatch
+	}
+	var overrides []string
+	before, now := fields(base), fields(current)
+	for name, value := range d.Fields {
+		if !bytes.Equal(before[name], now[name]) && !bytes.Equal(now[name], value) {
+			overrides = append(overrides, name)
+		}
+	}
+
+	prompt := func(prompts []Prompt, id string) *Prompt {
+		for i := range prompts {
+			if prompts[i].ID == id {
+				return &prompts[i]
+			}
+		}
+		return nil
+	}
+	for _, p := range d.Prompts {
+		if old, have := prompt(base.Prompts, p.ID), prompt(current.Prompts, p.ID); !sameJSON(old, have) && !sameJSON(have, &p) {
+			overrides = append(overrides, "prompt "+p.ID)
+		}
+	}
+	for _, id := range d.RemovedPrompts {
+		if !sameJSON(prompt(base.Prompts, id), prompt(current.Prompts, id)) {
+			overrides = append(overrides, "prompt "+id)
+		}
+	}
+
+	function := func(functions []Function, name string) *Function {
+		for i := range functions {
+			if functions[i].Name == name {
+				return &functions[i]
+			}
+		}
+		return nil
+	}
+	for _, f := range d.Functions {
+		if old, have := function(base.Functions, f.Name), function(current.Functions, f.Name); !sameJSON(old, have) && !sameJSON(have, &f) {
+			overrides = append(overrides, "function "+f.Name)
+		}
+	}
+	for _, name := range d.RemovedFunctions {
+		if !sameJSON(function(base.Functions, name), function(current.Functions, name)) {
+			overrides = append(overrides, "function "+name)
+		}
+	}
+	sort.Strings(overrides)
+	return overrides, nil
+}
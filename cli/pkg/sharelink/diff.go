@@ -0,0 +1,283 @@
+package sharelink
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sections of a Diff, in the order they are listed
+const (
+	SectionProvider  = "provider"
+	SectionPrompts   = "prompts"
+	SectionFunctions = "functions"
+	SectionSettings  = "settings"
+)
+
+// Kinds of Change
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change is one difference between two configurations
+type Change struct {
+	Section string `json:"section"`
+	Item    string `json:"item"` // Setting name, prompt ID or function name
+	Kind    string `json:"kind"` // ChangeAdded, ChangeRemoved or ChangeChanged
+	Old     string `json:"old,omitempty"`
+	New     string `json:"new,omitempty"`
+}
+
+// String describes the change on one line
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %s", c.Item, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %s", c.Item, c.Old)
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.Item, c.Old, c.New)
+}
+
+// Diff lists what changed from a to b: provider, prompts, functions and the
+// other settings, in that order. API keys are compared but never shown.
+func Diff(a, b *Config) []Change {
+	var changes []Change
+	field := func(section, item, old, new string) {
+		switch {
+		case old == new:
+		case old == "":
+			changes = append(changes, Change{Section: section, Item: item, Kind: ChangeAdded, New: new})
+		case new == "":
+			changes = append(changes, Change{Section: section, Item: item, Kind: ChangeRemoved, Old: old})
+		default:
+			changes = append(changes, Change{Section: section, Item: item, Kind: ChangeChanged, Old: old, New: new})
+		}
+	}
+
+	field(SectionProvider, "baseUrl", a.BaseURL, b.BaseURL)
+	field(SectionProvider, "apiKey", maskedKey(a.APIKey, b.APIKey), maskedKey(b.APIKey, a.APIKey))
+	field(SectionProvider, "model", a.Model, b.Model)
+
+	field(SectionPrompts, "systemPrompt", summarize(a.SystemPrompt), summarize(b.SystemPrompt))
+	changes = append(changes, diffPrompts(a.Prompts, b.Prompts)...)
+
+	changes = append(changes, diffFunctions(a.Functions, b.Functions)...)
+	for _, name := range unionKeys(a.DefaultFunctions, b.DefaultFunctions) {
+		field(SectionFunctions, "default "+name, enabledText(a.DefaultFunctions, name), enabledText(b.DefaultFunctions, name))
+	}
+
+	field(SectionSettings, "maxTokens", intText(a.MaxTokens), intText(b.MaxTokens))
+	field(SectionSettings, "temperature", floatText(a.Temperature), floatText(b.Temperature))
+	field(SectionSettings, "theme", a.Theme, b.Theme)
+	field(SectionSettings, "welcomeMessage", summarize(a.WelcomeMessage), summarize(b.WelcomeMessage))
+	field(SectionSettings, "ragEnabled", boolText(a.RAGEnabled), boolText(b.RAGEnabled))
+	field(SectionSettings, "ragDocuments", strings.Join(a.RAGDocuments, ", "), strings.Join(b.RAGDocuments, ", "))
+	field(SectionSettings, "mcpServers", mcpText(a.MCPServers), mcpText(b.MCPServers))
+	field(SectionSettings, "messages", countText(len(a.Messages)), countText(len(b.Messages)))
+	field(SectionSettings, "expiresAt", timeText(a.ExpiresAt), timeText(b.ExpiresAt))
+	field(SectionSettings, "locked", boolText(a.Locked), boolText(b.Locked))
+	if !reflect.DeepEqual(a.CustomData, b.CustomData) {
+		field(SectionSettings, "customData", countText(len(a.CustomData))+" keys", countText(len(b.CustomData))+" keys")
+	}
+	return changes
+}
+
+// diffPrompts compares prompts by ID
+func diffPrompts(a, b []Prompt) []Change {
+	before := make(map[string]Prompt, len(a))
+	for _, prompt := range a {
+		before[prompt.ID] = prompt
+	}
+	after := make(map[string]Prompt, len(b))
+	for _, prompt := range b {
+		after[prompt.ID] = prompt
+	}
+
+	var changes []Change
+	for _, prompt := range a {
+		if _, ok := after[prompt.ID]; !ok {
+			changes = append(changes, Change{Section: SectionPrompts, Item: prompt.ID, Kind: ChangeRemoved, Old: prompt.Name})
+		}
+	}
+	for _, prompt := range b {
+		old, ok := before[prompt.ID]
+		if !ok {
+			changes = append(changes, Change{Section: SectionPrompts, Item: prompt.ID, Kind: ChangeAdded, New: prompt.Name})
+			continue
+		}
+		var parts []string
+		if old.Name != prompt.Name {
+			parts = append(parts, fmt.Sprintf("name %q -> %q", old.Name, prompt.Name))
+		}
+		if old.Content != prompt.Content {
+			parts = append(parts, fmt.Sprintf("content %s -> %s", summarize(old.Content), summarize(prompt.Content)))
+		}
+		if old.Enabled != prompt.Enabled {
+			parts = append(parts, fmt.Sprintf("enabled %t -> %t", old.Enabled, prompt.Enabled))
+		}
+		if !reflect.DeepEqual(old.Tags, prompt.Tags) {
+			parts = append(parts, fmt.Sprintf("tags [%s] -> [%s]", strings.Join(old.Tags, ", "), strings.Join(prompt.Tags, ", ")))
+		}
+		if len(parts) > 0 {
+			changes = append(changes, Change{Section: SectionPrompts, Item: prompt.ID, Kind: ChangeChanged, Old: old.Name, New: strings.Join(parts, "; ")})
+		}
+	}
+	return changes
+}
+
+// diffFunctions compares functions by name
+func diffFunctions(a, b []Function) []Change {
+	before := make(map[string]Function, len(a))
+	for _, fn := range a {
+		before[fn.Name] = fn
+	}
+	after := make(map[string]Function, len(b))
+	for _, fn := range b {
+		after[fn.Name] = fn
+	}
+
+	var changes []Change
+	for _, fn := range a {
+		if _, ok := after[fn.Name]; !ok {
+			changes = append(changes, Change{Section: SectionFunctions, Item: fn.Name, Kind: ChangeRemoved, Old: summarize(fn.Description)})
+		}
+	}
+	for _, fn := range b {
+		old, ok := before[fn.Name]
+		if !ok {
+			changes = append(changes, Change{Section: SectionFunctions, Item: fn.Name, Kind: ChangeAdded, New: summarize(fn.Description)})
+			continue
+		}
+		var parts []string
+		if old.Code != fn.Code {
+			parts = append(parts, fmt.Sprintf("code changed (%d -> %d lines)", lineCount(old.Code), lineCount(fn.Code)))
+		}
+		if old.Description != fn.Description {
+			parts = append(parts, fmt.Sprintf("description %s -> %s", summarize(old.Description), summarize(fn.Description)))
+		}
+		if old.Enabled != fn.Enabled {
+			parts = append(parts, fmt.Sprintf("enabled %t -> %t", old.Enabled, fn.Enabled))
+		}
+		if !reflect.DeepEqual(old.Tags, fn.Tags) {
+			parts = append(parts, fmt.Sprintf("tags [%s] -> [%s]", strings.Join(old.Tags, ", "), strings.Join(fn.Tags, ", ")))
+		}
+		if !reflect.DeepEqual(old.Tests, fn.Tests) {
+			parts = append(parts, fmt.Sprintf("tests %d -> %d", len(old.Tests), len(fn.Tests)))
+		}
+		if len(parts) > 0 {
+			changes = append(changes, Change{Section: SectionFunctions, Item: fn.Name, Kind: ChangeChanged, Old: old.Name, New: strings.Join(parts, "; ")})
+		}
+	}
+	return changes
+}
+
+// maskedKey shows that a key is set, and whether it differs from other, without revealing it
+func maskedKey(key, other string) string {
+	switch {
+	case key == "":
+		return ""
+	case other == "" || other == key:
+		return "set"
+	case len(key) > 8:
+		// The last characters are enough to tell keys apart
+		return "set (…" + key[len(key)-4:] + ")"
+	}
+	return "set (different)"
+}
+
+// summarize shortens text to one line for display
+func summarize(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > 60 {
+		return strconv.Quote(text[:57] + "...")
+	}
+	if text == "" {
+		return ""
+	}
+	return strconv.Quote(text)
+}
+
+// unionKeys returns the keys of both maps, sorted
+func unionKeys(a, b map[string]bool) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for _, m := range []map[string]bool{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// enabledText describes a default function's state, "" when it isn't listed
+func enabledText(m map[string]bool, name string) string {
+	enabled, ok := m[name]
+	if !ok {
+		return ""
+	}
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// mcpText lists MCP servers as name=url
+func mcpText(servers []MCPServer) string {
+	parts := make([]string, len(servers))
+	for i, server := range servers {
+		parts[i] = server.Name + "=" + server.URL
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lineCount counts the lines of code
+func lineCount(code string) int {
+	return strings.Count(strings.TrimRight(code, "\n"), "\n") + 1
+}
+
+// Display forms of setting values; zero values are "" so they read as unset
+
+func intText(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func floatText(f float64) string {
+	if f == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func boolText(b bool) string {
+	if !b {
+		return ""
+	}
+	return "true"
+}
+
+func countText(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func timeText(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format("2006-01-02 15:04 UTC")
+}
@@ -0,0 +1,390 @@
+// Package sharelink creates and reads hacka.re share links.
+//
+// A share link carries an encrypted configuration (API key, model, prompts,
+// functions, ...) in the URL fragment, so it never reaches a server:
+//
+//	https://hacka.re/#gpt=<payload>
+//
+// # Envelope format (version 1, current)
+//
+// payload is unpadded URL-safe base64 of
+//
+//	salt (10 bytes) || nonce (10 bytes) || secretbox ciphertext
+//
+// where
+//
+//   - key = SHA-512 applied 8192 times to password||salt (see crypto.DeriveKey), first 32 bytes
+//   - the 24-byte secretbox nonce is the first 24 bytes of SHA-512(nonce)
+//   - the plaintext is the configuration as a JSON object, or a JSON string
+//     holding a compressed payload (as written by the web UI for long links)
+//
+// # Envelope format (version 0, legacy)
+//
+// payload is base64 of a JSON object {"enc": ..., "salt": ..., "nonce": ...}.
+// Version 0 links are still read, but Create always writes version 1.
+//
+// The format is shared with the browser implementation in js/, so links
+// created here open in hacka.re and vice versa.
+package sharelink
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hacka-re/cli/internal/compression"
+	"github.com/hacka-re/cli/internal/crypto"
+)
+
+// Version identifies the envelope format of a link
+type Version int
+
+const (
+	// VersionLegacy is the original JSON envelope with separate enc, salt and nonce fields
+	VersionLegacy Version = 0
+	// Version1 is the compact binary envelope written by current hacka.re releases
+	Version1 Version = 1
+
+	// CurrentVersion is the format written by Create
+	CurrentVersion = Version1
+)
+
+// DefaultBaseURL is used when Create is given an empty base URL
+const DefaultBaseURL = "https://hacka.re/"
+
+// fragmentPrefix introduces the payload in the URL fragment
+const fragmentPrefix = "gpt="
+
+// Errors returned by Parse and friends; test with errors.Is
+var (
+	// ErrNoPayload is returned when the input has no #gpt= fragment
+	ErrNoPayload = errors.New("no share link payload found")
+	// ErrCorruptLink is returned when the payload isn't a valid envelope, e.g. a truncated copy-paste
+	ErrCorruptLink = errors.New("share link is corrupted or incomplete")
+	// ErrBadPassword is returned when the payload doesn't decrypt with the password.
+	// Authenticated encryption can't tell a wrong password from tampered ciphertext.
+	ErrBadPassword = errors.New("decryption failed - incorrect password or corrupted data")
+	// ErrExpiredLink is returned when the configuration's ExpiresAt has passed
+	ErrExpiredLink = errors.New("share link has expired")
+)
+
+// Config is the configuration carried by a share link
+type Config struct {
+	APIKey           string                 `json:"apiKey,omitempty"`
+	BaseURL          string                 `json:"baseUrl,omitempty"`
+	Model            string                 `json:"model,omitempty"`
+	MaxTokens        int                    `json:"maxTokens,omitempty"`
+	Temperature      float64                `json:"temperature,omitempty"`
+	SystemPrompt     string                 `json:"systemPrompt,omitempty"`
+	WelcomeMessage   string                 `json:"welcomeMessage,omitempty"`
+	Theme            string                 `json:"theme,omitempty"`
+	Functions        []Function             `json:"functions,omitempty"`
+	DefaultFunctions map[string]bool        `json:"defaultFunctions,omitempty"`
+	Prompts          []Prompt               `json:"prompts,omitempty"`
+	Messages         []Message              `json:"messages,omitempty"` // Conversation shared along with the settings
+	RAGEnabled       bool                   `json:"ragEnabled,omitempty"`
+	RAGDocuments     []string               `json:"ragDocuments,omitempty"`
+	MCPServers       []MCPServer            `json:"mcpServers,omitempty"` // Only read by the CLI
+	CustomData       map[string]interface{} `json:"customData,omitempty"`
+	ExpiresAt        int64                  `json:"expiresAt,omitempty"`  // Unix seconds; 0 never expires
+	Locked           bool                   `json:"locked,omitempty"`     // Prompts, functions and provider open read-only
+	UnlockHash       string                 `json:"unlockHash,omitempty"` // Salted hash of the override password, see Lock
+	Delta            *Delta                 `json:"delta,omitempty"`      // Set in a delta link, see MakeDelta; only read by the CLI
+
+	// Namespace is the storage namespace of the link, set by Parse (see DeriveNamespace)
+	Namespace string `json:"-"`
+}
+
+// overrideSaltLength is the salt length of the override password hash
+const overrideSaltLength = 16
+
+// Lock marks the configuration read-only for whoever opens the link. With an
+// override password, entering it lifts the lock; only a salted hash of it is
+// stored. The lock is honoured by the clients rather than enforced by the
+// encryption: anyone with the link password can still read the configuration.
+func (c *Config) Lock(overridePassword string) error {
+	c.Locked = true
+	c.UnlockHash = ""
+	if overridePassword == "" {
+		return nil
+	}
+	salt, err := crypto.GenerateRandomBytes(overrideSaltLength)
+	if err != nil {
+		return fmt.Errorf("failed to lock configuration: %w", err)
+	}
+	c.UnlockHash = crypto.EncodeBase64URLSafe(salt) + ":" + hex.EncodeToString(crypto.DeriveKey(overridePassword, salt))
+	return nil
+}
+
+// CheckOverride reports whether password is the override password behind unlockHash.
+// A configuration locked without an override password can't be unlocked.
+func CheckOverride(unlockHash, password string) bool {
+	encodedSalt, hash, ok := strings.Cut(unlockHash, ":")
+	if !ok || password == "" {
+		return false
+	}
+	salt, err := crypto.DecodeBase64URLSafe(encodedSalt)
+	if err != nil {
+		return false
+	}
+	derived := hex.EncodeToString(crypto.DeriveKey(password, salt))
+	return subtle.ConstantTimeCompare([]byte(derived), []byte(hash)) == 1
+}
+
+// Function represents a callable function configuration
+type Function struct {
+	Name        string   `json:"name"`
+	Code        string   `json:"code"`
+	Description string   `json:"description,omitempty"`
+	Enabled     bool     `json:"enabled"`
+	Tags        []string `json:"tags,omitempty"`
+
+	// Tests are calls the function must answer as expected
+	Tests []FunctionTest `json:"tests,omitempty"`
+}
+
+// FunctionTest is a test case of a function: the arguments of a call and what
+// it must return. Without Expect, Match or Throws the call only has to succeed.
+type FunctionTest struct {
+	Name   string                 `json:"name,omitempty"`
+	Args   map[string]interface{} `json:"args"`
+	Expect interface{}            `json:"expect,omitempty"` // The result, compared as JSON
+	Match  string                 `json:"match,omitempty"`  // A regular expression the result, as text, must match
+	Throws bool                   `json:"throws,omitempty"` // The call must fail
+}
+
+// Prompt represents a system prompt configuration
+type Prompt struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Content  string   `json:"content"`
+	Enabled  bool     `json:"enabled"`
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// MCPServer is a Model Context Protocol server to connect to
+type MCPServer struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Message is one message of a shared conversation
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	Rating  string `json:"rating,omitempty"` // The user's review of an assistant reply: up or down
+	Note    string `json:"note,omitempty"`
+}
+
+// Envelope describes a link's payload without decrypting it
+type Envelope struct {
+	Version Version
+	Payload string // The encoded payload after #gpt=
+	Salt    []byte // Only set for Version1
+	Nonce   []byte // Only set for Version1
+}
+
+// Create encrypts cfg with password and returns a share link on baseURL
+func Create(cfg *Config, password string, baseURL string) (string, error) {
+	if cfg == nil {
+		return "", errors.New("configuration is nil")
+	}
+	jsonData, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+
+	payload, err := Encrypt(jsonData, password)
+	if err != nil {
+		return "", err
+	}
+
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return fmt.Sprintf("%s#%s%s", baseURL, fragmentPrefix, payload), nil
+}
+
+// Encrypt encrypts raw plaintext into a version 1 payload
+func Encrypt(plaintext []byte, password string) (string, error) {
+	payload, err := crypto.EncryptShareLink(plaintext, password)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt configuration: %w", err)
+	}
+	return payload, nil
+}
+
+// Decrypt decrypts a version 1 payload into its raw plaintext
+func Decrypt(payload string, password string) ([]byte, error) {
+	data, err := crypto.DecodeBase64URLSafe(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptLink, err)
+	}
+	if len(data) < crypto.SaltLength+crypto.NonceLength+secretboxOverhead {
+		return nil, fmt.Errorf("%w: payload too short", ErrCorruptLink)
+	}
+
+	plaintext, err := crypto.DecryptShareLink(payload, password)
+	if err != nil {
+		return nil, ErrBadPassword
+	}
+	return plaintext, nil
+}
+
+// secretboxOverhead is the authenticator length added to every ciphertext
+const secretboxOverhead = 16
+
+// Parse decrypts a share link and returns its configuration.
+// input may be a full URL, a "gpt=..." fragment or just the payload.
+func Parse(input string, password string) (*Config, error) {
+	envelope, err := Inspect(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if envelope.Version == VersionLegacy {
+		encData, err := crypto.ParseShareableURL(Normalize(input))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse URL: %w", err)
+		}
+		var cfg Config
+		if err := crypto.DecryptJSON(encData, password, &cfg); err != nil {
+			return nil, fmt.Errorf("failed to decrypt configuration: %w", ErrBadPassword)
+		}
+		return checkExpiry(&cfg)
+	}
+
+	plaintext, err := Decrypt(envelope.Payload, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt configuration: %w", err)
+	}
+	cfg, err := decodeConfig(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptLink, err)
+	}
+	cfg.Namespace, _ = DeriveNamespace(password, envelope.Salt, envelope.Nonce)
+	return checkExpiry(cfg)
+}
+
+// NamespaceLength is the length of a link's namespace ID
+const NamespaceLength = 8
+
+// DeriveNamespace returns the namespace ID and master key of a version 1 link,
+// the way the web app does, so the same link and password land in the same
+// storage in both: the master key is crypto.DeriveMasterKey of the password,
+// salt and nonce, and the namespace the first 8 hex digits of
+// SHA-512(decryption key || master key || nonce). Legacy links have no namespace.
+func DeriveNamespace(password string, salt, nonce []byte) (namespace, masterKey string) {
+	decryptionKey := crypto.DeriveKey(password, salt)
+	masterKey = crypto.DeriveMasterKey(password, salt, nonce)
+	hash := crypto.DeriveNamespaceHash(decryptionKey, masterKey, nonce)
+	if len(hash) > NamespaceLength {
+		hash = hash[:NamespaceLength]
+	}
+	return hash, masterKey
+}
+
+// checkExpiry rejects configurations past their ExpiresAt
+func checkExpiry(cfg *Config) (*Config, error) {
+	if cfg.ExpiresAt > 0 && time.Now().Unix() > cfg.ExpiresAt {
+		return nil, fmt.Errorf("%w on %s", ErrExpiredLink, time.Unix(cfg.ExpiresAt, 0).Format("2006-01-02 15:04"))
+	}
+	return cfg, nil
+}
+
+// decodeConfig parses decrypted plaintext, which is JSON or a (JSON-quoted) compressed payload
+func decodeConfig(plaintext []byte) (*Config, error) {
+	// A JSON string holds compressed data
+	if len(plaintext) > 0 && plaintext[0] == '"' {
+		var compressed string
+		if err := json.Unmarshal(plaintext, &compressed); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal compressed data: %w", err)
+		}
+		return decompressConfig(compressed)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(plaintext, &cfg); err != nil {
+		// Maybe it's a bare compressed string
+		if decompressed, decompErr := decompressConfig(string(plaintext)); decompErr == nil {
+			return decompressed, nil
+		}
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	return &cfg, nil
+}
+
+// decompressConfig expands a compressed payload into a Config
+func decompressConfig(compressed string) (*Config, error) {
+	decompressed, err := compression.DecompressPayload(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress payload: %w", err)
+	}
+
+	jsonBytes, err := json.Marshal(decompressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal decompressed data: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(jsonBytes, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Inspect extracts the payload and detects the envelope version without a password
+func Inspect(input string) (*Envelope, error) {
+	payload, err := ExtractPayload(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Legacy payloads decode to a JSON object; check them first since any
+	// long enough base64 string also passes as a version 1 salt and nonce
+	if _, err := crypto.ParseShareableURL(Normalize(input)); err == nil {
+		return &Envelope{Version: VersionLegacy, Payload: payload}, nil
+	}
+
+	if salt, nonce, err := crypto.ExtractSaltAndNonce(payload); err == nil {
+		return &Envelope{Version: Version1, Payload: payload, Salt: salt, Nonce: nonce}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unrecognized payload", ErrCorruptLink)
+}
+
+// ExtractPayload returns the encoded payload after #gpt=
+func ExtractPayload(input string) (string, error) {
+	payload, err := crypto.ParseShareLinkURL(Normalize(input))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNoPayload, err)
+	}
+	if payload == "" {
+		return "", ErrNoPayload
+	}
+	return payload, nil
+}
+
+// Normalize converts a URL, "gpt=..." fragment or bare payload to a full share link URL
+func Normalize(input string) string {
+	input = strings.TrimSpace(input)
+
+	// Check if it's a full URL (starts with http:// or https://)
+	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		return input
+	}
+
+	// If it starts with gpt=, add the full URL with hash
+	if strings.HasPrefix(input, fragmentPrefix) {
+		return DefaultBaseURL + "#" + input
+	}
+
+	// Otherwise, assume it's just the encrypted data part
+	return DefaultBaseURL + "#" + fragmentPrefix + input
+}
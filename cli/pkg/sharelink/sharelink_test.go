@@ -0,0 +1,339 @@
+package sharelink
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hacka-re/cli/internal/crypto"
+)
+
+func TestCreateParseRoundTrip(t *testing.T) {
+	cfg := &Config{
+		APIKey:       "sk-test",
+		BaseURL:      "https://api.openai.com/v1",
+		Model:        "gpt-4o-mini",
+		SystemPrompt: "You are terse.",
+		Functions:    []Function{{Name: "add", Code: "function add(a, b) { return a + b }", Enabled: true}},
+	}
+
+	link, err := Create(cfg, "secret", "")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(link, DefaultBaseURL+"#gpt=") {
+		t.Fatalf("unexpected link %q", link)
+	}
+
+	payload := strings.TrimPrefix(link, DefaultBaseURL+"#gpt=")
+	for _, input := range []string{link, "gpt=" + payload, payload, "  " + link + "\n"} {
+		got, err := Parse(input, "secret")
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", input, err)
+		}
+		if got.APIKey != cfg.APIKey || got.Model != cfg.Model || len(got.Functions) != 1 {
+			t.Fatalf("Parse(%q) = %+v", input, got)
+		}
+	}
+}
+
+func TestParseWrongPassword(t *testing.T) {
+	link, err := Create(&Config{Model: "m"}, "right", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(link, "wrong"); !errors.Is(err, ErrBadPassword) {
+		t.Fatalf("expected ErrBadPassword, got %v", err)
+	}
+}
+
+func TestParseErrorClasses(t *testing.T) {
+	link, err := Create(&Config{Model: "m", ExpiresAt: time.Now().Add(-time.Hour).Unix()}, "pw", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(link, "pw"); !errors.Is(err, ErrExpiredLink) {
+		t.Fatalf("expected ErrExpiredLink, got %v", err)
+	}
+
+	// A link cut short while copying
+	if _, err := Parse(link[:len(DefaultBaseURL)+10], "pw"); !errors.Is(err, ErrCorruptLink) {
+		t.Fatalf("expected ErrCorruptLink, got %v", err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	link, err := Create(&Config{Model: "m"}, "pw", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := Inspect(link)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if envelope.Version != Version1 || len(envelope.Salt) != crypto.SaltLength || len(envelope.Nonce) != crypto.NonceLength {
+		t.Fatalf("unexpected envelope %+v", envelope)
+	}
+
+	if _, err := Inspect("https://hacka.re/"); !errors.Is(err, ErrNoPayload) {
+		t.Fatalf("expected ErrNoPayload, got %v", err)
+	}
+}
+
+func TestParseLegacy(t *testing.T) {
+	encData, err := crypto.EncryptJSON(&Config{Model: "legacy-model"}, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	link, err := crypto.GenerateShareableURL(DefaultBaseURL, encData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, err := Inspect(link)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if envelope.Version != VersionLegacy {
+		t.Fatalf("expected legacy envelope, got version %d", envelope.Version)
+	}
+
+	cfg, err := Parse(link, "pw")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if cfg.Model != "legacy-model" {
+		t.Fatalf("unexpected model %q", cfg.Model)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	link, err := Create(&Config{Model: "m"}, "pw", "")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(link, "pw")
+	f.Add("gpt=", "")
+	f.Add("https://hacka.re/#gpt=eyJlbmMiOiIifQ", "pw")
+	f.Add("#gpt=####", "x")
+	f.Add("", "")
+
+	f.Fuzz(func(t *testing.T, input, password string) {
+		// Must never panic; errors are expected for almost every input
+		cfg, err := Parse(input, password)
+		if err == nil && cfg == nil {
+			t.Fatalf("Parse(%q) returned neither config nor error", input)
+		}
+		Inspect(input)
+	})
+}
+
+func TestLockOverride(t *testing.T) {
+	cfg := &Config{Model: "m"}
+	if err := cfg.Lock("let me in"); err != nil {
+		t.Fatal(err)
+	}
+	link, err := Create(cfg, "pw", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(link, "pw")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !got.Locked || !CheckOverride(got.UnlockHash, "let me in") {
+		t.Fatalf("lock did not survive the round trip: %+v", got)
+	}
+	if CheckOverride(got.UnlockHash, "guess") {
+		t.Fatal("wrong override password accepted")
+	}
+
+	// Locked without an override password, nothing unlocks it
+	if err := cfg.Lock(""); err != nil {
+		t.Fatal(err)
+	}
+	if CheckOverride(cfg.UnlockHash, "") || CheckOverride(cfg.UnlockHash, "let me in") {
+		t.Fatal("configuration without override password was unlocked")
+	}
+}
+
+func TestDeriveNamespaceMatchesWebApp(t *testing.T) {
+	// Computed with deriveDecryptionKey, deriveMasterKey and deriveNamespaceHash of js/utils/crypto-utils.js
+	salt := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	nonce := []byte{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	namespace, masterKey := DeriveNamespace("testPassword123", salt, nonce)
+	if namespace != "3fdfaa5e" {
+		t.Errorf("namespace = %q, want 3fdfaa5e", namespace)
+	}
+	if masterKey != "8d73bc553f46bdcebb80c51f6d514368a107d76b64d8083203dfe8f853bf7386" {
+		t.Errorf("unexpected master key %q", masterKey)
+	}
+
+	// Parse puts every link in its own namespace, the same each time it is opened
+	link, err := Create(&Config{Model: "m"}, "pw", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := Parse(link, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Parse(link, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Namespace) != NamespaceLength || first.Namespace != again.Namespace {
+		t.Errorf("expected a stable namespace, got %q and %q", first.Namespace, again.Namespace)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := &Config{
+		APIKey:    "sk-aaaaaaaaaaaa1111",
+		BaseURL:   "https://api.openai.com/v1",
+		Model:     "gpt-4o-mini",
+		Prompts:   []Prompt{{ID: "p1", Name: "Terse", Content: "Be terse.", Enabled: true}, {ID: "p2", Name: "Old"}},
+		Functions: []Function{{Name: "add", Code: "function add(a, b) { return a + b }", Enabled: true}},
+	}
+	b := &Config{
+		APIKey:      "sk-bbbbbbbbbbbb2222",
+		BaseURL:     "https://api.openai.com/v1",
+		Model:       "gpt-4o",
+		Temperature: 0.2,
+		Prompts:     []Prompt{{ID: "p1", Name: "Terse", Content: "Be very terse.", Enabled: true}, {ID: "p3", Name: "New"}},
+		Functions:   []Function{{Name: "add", Code: "function add(a, b) {\n  return a + b\n}", Enabled: true}},
+	}
+
+	got := map[string]Change{}
+	for _, change := range Diff(a, b) {
+		got[change.Section+"/"+change.Item] = change
+	}
+	if len(got) != 7 {
+		t.Fatalf("expected 7 changes, got %+v", got)
+	}
+	if c := got["provider/apiKey"]; c.Kind != ChangeChanged || strings.Contains(c.Old+c.New, "aaaaaaaa") || !strings.Contains(c.New, "2222") {
+		t.Fatalf("API key change leaks or is missing: %+v", c)
+	}
+	if c := got["provider/model"]; c.Old != "gpt-4o-mini" || c.New != "gpt-4o" {
+		t.Fatalf("unexpected model change %+v", c)
+	}
+	if got["prompts/p1"].Kind != ChangeChanged || got["prompts/p2"].Kind != ChangeRemoved || got["prompts/p3"].Kind != ChangeAdded {
+		t.Fatalf("unexpected prompt changes %+v", got)
+	}
+	if c := got["functions/add"]; !strings.Contains(c.New, "1 -> 3 lines") {
+		t.Fatalf("unexpected function change %+v", c)
+	}
+	if c := got["settings/temperature"]; c.Kind != ChangeAdded || c.New != "0.2" {
+		t.Fatalf("unexpected temperature change %+v", c)
+	}
+
+	if changes := Diff(a, a); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	base := &Config{
+		APIKey:       "sk-team",
+		BaseURL:      "https://api.openai.com/v1",
+		Model:        "gpt-4o-mini",
+		SystemPrompt: "You help the team.",
+		Prompts:      []Prompt{{ID: "p1", Name: "Terse", Content: "Be terse."}, {ID: "p2", Name: "Old"}},
+		Functions:    []Function{{Name: "add", Code: "function add(a, b) { return a + b }"}},
+	}
+	target := &Config{
+		APIKey:    "sk-author",
+		BaseURL:   "https://api.openai.com/v1",
+		Model:     "gpt-4o",
+		Prompts:   []Prompt{{ID: "p1", Name: "Terse", Content: "Be very terse."}, {ID: "p3", Name: "New"}},
+		Functions: []Function{{Name: "add", Code: "function add(a, b) { return a + b }"}, {Name: "sub"}},
+	}
+
+	d := MakeDelta("team", base, target)
+	if d.BaseHash != Fingerprint(&Config{APIKey: "other", BaseURL: base.BaseURL, Model: base.Model,
+		SystemPrompt: base.SystemPrompt, Prompts: base.Prompts, Functions: base.Functions}) {
+		t.Fatal("expected the fingerprint to ignore the API key")
+	}
+	if _, ok := d.Fields["apiKey"]; ok {
+		t.Fatal("the API key must not be part of a delta")
+	}
+	if len(d.Fields) != 2 || string(d.Fields["model"]) != `"gpt-4o"` || string(d.Fields["systemPrompt"]) != "null" {
+		t.Fatalf("unexpected fields %v", d.Fields)
+	}
+	if len(d.Prompts) != 2 || len(d.RemovedPrompts) != 1 || d.RemovedPrompts[0] != "p2" {
+		t.Fatalf("unexpected prompts %+v removed %v", d.Prompts, d.RemovedPrompts)
+	}
+	if len(d.Functions) != 1 || d.Functions[0].Name != "sub" || len(d.RemovedFunctions) != 0 {
+		t.Fatalf("unexpected functions %+v", d.Functions)
+	}
+
+	// The recipient has their own key, temperature and prompt
+	current := *base
+	current.APIKey = "sk-recipient"
+	current.Temperature = 0.3
+	current.Prompts = append(append([]Prompt{}, base.Prompts...), Prompt{ID: "mine", Name: "Mine"})
+	merged, err := d.Apply(&current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.APIKey != "sk-recipient" || merged.Model != "gpt-4o" || merged.SystemPrompt != "" || merged.Temperature != 0.3 {
+		t.Fatalf("unexpected merge %+v", merged)
+	}
+	var ids []string
+	for _, p := range merged.Prompts {
+		ids = append(ids, p.ID)
+	}
+	if strings.Join(ids, ",") != "p1,mine,p3" || merged.Prompts[0].Content != "Be very terse." {
+		t.Fatalf("unexpected merged prompts %+v", merged.Prompts)
+	}
+	if len(merged.Functions) != 2 {
+		t.Fatalf("unexpected merged functions %+v", merged.Functions)
+	}
+
+	if overrides, err := d.Overrides(base, &current); err != nil || len(overrides) != 0 {
+		t.Fatalf("expected no overrides, got %v, %v", overrides, err)
+	}
+	current.Model = "o3"
+	current.Prompts[1].Content = "Edited"
+	if overrides, _ := d.Overrides(base, &current); strings.Join(overrides, ",") != "model,prompt p2" {
+		t.Fatalf("unexpected overrides %v", overrides)
+	}
+	if _, err := d.Overrides(target, &current); err != ErrBaseMismatch {
+		t.Fatalf("expected ErrBaseMismatch, got %v", err)
+	}
+
+	if !MakeDelta("team", base, base).Empty() {
+		t.Fatal("expected an empty delta between equal configurations")
+	}
+
+	// A delta survives a link
+	link, err := Create(&Config{Delta: d}, "pw", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(link, "pw")
+	if err != nil || parsed.Delta == nil || parsed.Delta.Base != "team" || len(parsed.Delta.Fields) != 2 {
+		t.Fatalf("delta lost in link: %+v, %v", parsed, err)
+	}
+}
+
+func TestContents(t *testing.T) {
+	c := &Config{
+		APIKey:    "sk-proj-abcdefghijklmnop1234",
+		BaseURL:   "https://api.openai.com/v1",
+		Model:     "gpt-4o",
+		Functions: []Function{{Name: "add"}, {Name: "sub"}},
+	}
+	got := strings.Join(Contents(c), "\n")
+	want := "API key: sk-...1234\nProvider and model: gpt-4o @ api.openai.com\nFunctions: add, sub"
+	if got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(got, "abcdefgh") {
+		t.Fatal("the key must be masked")
+	}
+	if parts := Contents(&Config{}); len(parts) != 0 {
+		t.Fatalf("expected nothing for an empty configuration, got %v", parts)
+	}
+}